@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/health"
 	"go.opentelemetry.io/otel/attribute"
 )
 
@@ -107,6 +108,81 @@ func ExtractBlockReferenceFromRequest(ctx context.Context, r *common.NormalizedR
 	return blockRef, blockNumber, nil
 }
 
+// ExtractRequestBlock resolves the block context of a request for selection, considering all of
+// its block parameters: the highest block number and the most recent tag it reads, e.g. both ends
+// of an eth_getLogs range. Omitted block parameters read the latest block, as per JSON-RPC.
+func ExtractRequestBlock(ctx context.Context, r *common.NormalizedRequest) health.RequestBlock {
+	rpcReq, err := r.JsonRpcRequest(ctx)
+	if err != nil {
+		return health.RequestBlock{Unknown: true}
+	}
+	methodConfig := getMethodConfig(r.CacheDal(), rpcReq.Method)
+	if methodConfig == nil {
+		return health.RequestBlock{}
+	}
+	if methodConfig.Finalized {
+		return health.RequestBlock{Number: 1}
+	} else if methodConfig.Realtime {
+		return health.RequestBlock{Tag: "latest"}
+	}
+
+	rpcReq.RLockWithTrace(ctx)
+	defer rpcReq.RUnlock()
+
+	var block health.RequestBlock
+	for _, ref := range methodConfig.ReqRefs {
+		if len(ref) == 1 && ref[0] == "*" {
+			// Looked up by a hash, e.g. eth_getTransactionReceipt
+			block.Unknown = true
+			continue
+		}
+		val, err := rpcReq.PeekByPath(ref...)
+		if err != nil || val == nil {
+			if key, ok := ref[len(ref)-1].(string); !ok || key != "blockHash" {
+				block.Tag = moreRecentBlockTag(block.Tag, "latest")
+			}
+			continue
+		}
+		bref, bn, err := parseCompositeBlockParam(val)
+		switch {
+		case err != nil:
+			block.Unknown = true
+		case bn > 0:
+			block.Number = max(block.Number, bn)
+		case strings.HasPrefix(bref, "0x"):
+			block.Unknown = true
+		case bref != "":
+			block.Tag = moreRecentBlockTag(block.Tag, bref)
+		}
+	}
+	return block
+}
+
+// blockTagRecency ranks block tags from the oldest data they read, unknown tags being read at the head.
+var blockTagRecency = map[string]int{
+	"":                       0,
+	health.BlockTagEarliest:  1,
+	health.BlockTagFinalized: 2,
+	"safe":                   3,
+	"latest":                 4,
+	"pending":                5,
+}
+
+func moreRecentBlockTag(a, b string) string {
+	ra, ok := blockTagRecency[a]
+	if !ok {
+		ra = blockTagRecency["latest"]
+	}
+	rb, ok := blockTagRecency[b]
+	if !ok {
+		rb = blockTagRecency["latest"]
+	}
+	if rb > ra {
+		return b
+	}
+	return a
+}
+
 func ExtractBlockReferenceFromResponse(ctx context.Context, r *common.NormalizedResponse) (string, int64, error) {
 	ctx, span := common.StartDetailSpan(ctx, "Evm.ExtractBlockReferenceFromResponse")
 	defer span.End()
@@ -2,9 +2,11 @@ package evm
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/health"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -420,3 +422,93 @@ func TestExtractBlockReference(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractRequestBlock(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		params   []interface{}
+		expected health.RequestBlock
+	}{
+		{
+			name:     "numeric block",
+			method:   "eth_getBlockByNumber",
+			params:   []interface{}{"0x64", false},
+			expected: health.RequestBlock{Number: 100},
+		},
+		{
+			name:     "latest tag",
+			method:   "eth_call",
+			params:   []interface{}{map[string]interface{}{"to": "0x0"}, "latest"},
+			expected: health.RequestBlock{Tag: "latest"},
+		},
+		{
+			name:     "finalized tag",
+			method:   "eth_getBalance",
+			params:   []interface{}{"0xabc", "finalized"},
+			expected: health.RequestBlock{Tag: "finalized"},
+		},
+		{
+			name:     "omitted block defaults to latest",
+			method:   "eth_call",
+			params:   []interface{}{map[string]interface{}{"to": "0x0"}},
+			expected: health.RequestBlock{Tag: "latest"},
+		},
+		{
+			name:     "numeric range",
+			method:   "eth_getLogs",
+			params:   []interface{}{map[string]interface{}{"fromBlock": "0x64", "toBlock": "0xc8"}},
+			expected: health.RequestBlock{Number: 200},
+		},
+		{
+			name:     "range up to a tag",
+			method:   "eth_getLogs",
+			params:   []interface{}{map[string]interface{}{"fromBlock": "0x64", "toBlock": "finalized"}},
+			expected: health.RequestBlock{Number: 100, Tag: "finalized"},
+		},
+		{
+			name:     "range from earliest up to latest",
+			method:   "eth_getLogs",
+			params:   []interface{}{map[string]interface{}{"fromBlock": "earliest", "toBlock": "latest"}},
+			expected: health.RequestBlock{Tag: "latest"},
+		},
+		{
+			name:     "range with an open end",
+			method:   "eth_getLogs",
+			params:   []interface{}{map[string]interface{}{"fromBlock": "0x64"}},
+			expected: health.RequestBlock{Number: 100, Tag: "latest"},
+		},
+		{
+			name:     "block hash",
+			method:   "eth_getLogs",
+			params:   []interface{}{map[string]interface{}{"blockHash": "0x" + strings.Repeat("ab", 32)}},
+			expected: health.RequestBlock{Tag: "latest", Unknown: true},
+		},
+		{
+			name:     "looked up by hash",
+			method:   "eth_getTransactionReceipt",
+			params:   []interface{}{"0x" + strings.Repeat("ab", 32)},
+			expected: health.RequestBlock{Unknown: true},
+		},
+		{
+			name:     "static method",
+			method:   "eth_chainId",
+			expected: health.RequestBlock{Number: 1},
+		},
+		{
+			name:     "realtime method",
+			method:   "eth_blockNumber",
+			expected: health.RequestBlock{Tag: "latest"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nrq := common.NewNormalizedRequestFromJsonRpcRequest(&common.JsonRpcRequest{
+				Method: tt.method,
+				Params: tt.params,
+			})
+			assert.Equal(t, tt.expected, ExtractRequestBlock(context.TODO(), nrq))
+		})
+	}
+}
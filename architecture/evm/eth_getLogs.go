@@ -557,6 +557,7 @@ func executeGetLogsSubRequests(ctx context.Context, n common.Network, u common.U
 			sbnrq.SetDirectives(dr)
 			sbnrq.SetNetwork(n)
 			sbnrq.SetParentRequestId(r.ID())
+			sbnrq.SetClientId(r.ClientId())
 
 			rs, re := n.Forward(ctx, sbnrq)
 			if re != nil {
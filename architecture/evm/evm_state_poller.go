@@ -35,7 +35,7 @@ type EvmStatePoller struct {
 	logger    *zerolog.Logger
 	upstream  common.Upstream
 	cfg       *common.EvmNetworkConfig
-	tracker   *health.Tracker
+	tracker   health.MetricsTracker
 
 	// When node is fully synced we don't need to query syncing state anymore.
 	// A number is used so that at least X times the upstream tells us it's synced.
@@ -80,7 +80,7 @@ func NewEvmStatePoller(
 	appCtx context.Context,
 	logger *zerolog.Logger,
 	up common.Upstream,
-	tracker *health.Tracker,
+	tracker health.MetricsTracker,
 	sharedState data.SharedStateRegistry,
 ) *EvmStatePoller {
 	lg := logger.With().Str("component", "evmStatePoller").Logger()
@@ -204,6 +204,9 @@ func (e *EvmStatePoller) Poll(ctx context.Context) error {
 	go func() {
 		defer wg.Done()
 		_, err := e.PollLatestBlockNumber(ctx)
+		if !e.shouldSkipLatestBlockCheck() {
+			e.tracker.RecordUpstreamProbe(e.upstream.Config().Id, e.upstream.NetworkId(), err == nil)
+		}
 		if err != nil {
 			e.logger.Debug().Err(err).Msg("failed to get latest block number in evm state poller")
 			ermu.Lock()
@@ -554,6 +557,9 @@ func (e *EvmStatePoller) fetchBlock(ctx context.Context, blockTag string) (int64
 	if err != nil {
 		return 0, err
 	}
+	if hash, err := jrr.PeekStringByPath(ctx, "hash"); err == nil {
+		e.tracker.RecordBlockHash(e.upstream.Config().Id, e.upstream.NetworkId(), blockNum, hash)
+	}
 
 	return blockNum, nil
 }
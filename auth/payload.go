@@ -1,6 +1,11 @@
 package auth
 
-import "github.com/erpc/erpc/common"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/erpc/erpc/common"
+)
 
 type AuthPayload struct {
 	Method  string
@@ -28,3 +33,26 @@ type NetworkPayload struct {
 	Address        string
 	ForwardProxies []string
 }
+
+// ClientId identifies the downstream client presenting the credentials, for the attribution of
+// traffic to clients. Credentials are hashed so that they never show up in metrics, and requests
+// authenticated by network only are not attributed.
+func (ap *AuthPayload) ClientId() string {
+	if ap == nil {
+		return ""
+	}
+	var credential string
+	switch {
+	case ap.Secret != nil:
+		credential = ap.Secret.Value
+	case ap.Jwt != nil:
+		credential = ap.Jwt.Token
+	case ap.Siwe != nil:
+		credential = ap.Siwe.Message
+	}
+	if credential == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(credential))
+	return string(ap.Type) + ":" + hex.EncodeToString(sum[:6])
+}
@@ -461,6 +461,7 @@ func (c *GenericHttpJsonRpcClient) processBatchResponse(requests map[interface{}
 			}
 			return
 		}
+		var unexpectedIds []interface{}
 		for _, elemNode := range arrNodes {
 			var id interface{}
 			jrResp, err := getJsonRpcResponseFromNode(elemNode)
@@ -484,12 +485,18 @@ func (c *GenericHttpJsonRpcClient) processBatchResponse(requests map[interface{}
 				delete(requests, id)
 			} else {
 				c.logger.Warn().Msgf("unexpected response received with ID: %s", id)
+				unexpectedIds = append(unexpectedIds, id)
 			}
 		}
 		// Handle any remaining requests that didn't receive a response
 		anyMissingId := false
 		for _, req := range requests {
-			req.err <- fmt.Errorf("no response received for request ID: %d", req.request.ID())
+			err := fmt.Errorf("no response received for request ID: %d", req.request.ID())
+			if len(unexpectedIds) > 0 {
+				// The upstream answered with ids matching none of the requests sent
+				err = common.NewErrUpstreamResponseIdMismatch(err, c.upstreamId, unexpectedIds)
+			}
+			req.err <- err
 			anyMissingId = true
 		}
 		if anyMissingId {
@@ -516,7 +523,7 @@ func (c *GenericHttpJsonRpcClient) processBatchResponse(requests map[interface{}
 	} else {
 		// Unexpected response type
 		for _, req := range requests {
-			req.err <- common.NewErrUpstreamMalformedResponse(fmt.Errorf("unexpected response type (not array nor object): %s", bodyStr), c.upstreamId)
+			req.err <- common.NewErrUpstreamMalformedPayload(fmt.Errorf("unexpected response type (not array nor object): %s", bodyStr), c.upstreamId, bodyBytes)
 		}
 	}
 }
@@ -115,6 +115,7 @@ const (
 	EvalAllErrorRateBelow100    = "all:errorRateBelow100"
 	EvalEvmAnyChainId           = "any:evm:eth_chainId"
 	EvalEvmAllChainId           = "all:evm:eth_chainId"
+	EvalAllEligibleUpstreams    = "all:eligibleUpstreams"
 )
 
 type TracingProtocol string
@@ -320,6 +321,175 @@ type ProjectConfig struct {
 	RateLimitBudget        string                              `yaml:"rateLimitBudget,omitempty" json:"rateLimitBudget"`
 	ScoreMetricsWindowSize Duration                            `yaml:"scoreMetricsWindowSize" json:"scoreMetricsWindowSize" tstype:"Duration"`
 	DeprecatedHealthCheck  *DeprecatedProjectHealthCheckConfig `yaml:"healthCheck,omitempty" json:"healthCheck"`
+	// ProjectAggregates enables the metrics aggregated across all networks of the project
+	ProjectAggregates bool `yaml:"projectAggregates,omitempty" json:"projectAggregates"`
+	// BroadcastChecks enables following up on transactions accepted by upstreams
+	BroadcastChecks *BroadcastChecksConfig `yaml:"broadcastChecks,omitempty" json:"broadcastChecks"`
+	// HealthTracker tunes how upstream health is tracked and acted upon
+	HealthTracker *HealthTrackerConfig `yaml:"healthTracker,omitempty" json:"healthTracker"`
+}
+
+// BroadcastChecksConfig enables checking that the transactions accepted by an upstream become
+// visible through the other upstreams of the network after "delay", to detect upstreams which
+// silently drop them. At most "maxPending" transactions wait to be checked.
+type BroadcastChecksConfig struct {
+	Delay      Duration `yaml:"delay,omitempty" json:"delay" tstype:"Duration"`
+	MaxPending int      `yaml:"maxPending,omitempty" json:"maxPending"`
+}
+
+// HealthTrackerConfig tunes the health tracker of a project, see the setters of health.Tracker
+// for the meaning of each field. Zero values keep the defaults of the tracker.
+type HealthTrackerConfig struct {
+	// FailureCooldown tries an upstream last for a method for this long after it failed it
+	FailureCooldown Duration `yaml:"failureCooldown,omitempty" json:"failureCooldown" tstype:"Duration"`
+	// WriteHealthBar tries upstreams last for write methods when their writes fail or they lag too much
+	WriteHealthBar *WriteHealthBarConfig `yaml:"writeHealthBar,omitempty" json:"writeHealthBar"`
+	// MinEligibleUpstreams stops automatic cordons from leaving a network with fewer eligible upstreams
+	MinEligibleUpstreams int `yaml:"minEligibleUpstreams,omitempty" json:"minEligibleUpstreams"`
+	// TieBreak orders upstreams with identical scores: alphabetical, roundRobin or lowestLatency
+	TieBreak string `yaml:"tieBreak,omitempty" json:"tieBreak"`
+	// NoDataBehavior is the latency reported for keys without samples: zero or nan
+	NoDataBehavior string `yaml:"noDataBehavior,omitempty" json:"noDataBehavior"`
+	// ScoreRetryLatency makes the latency used for scoring include retries
+	ScoreRetryLatency bool `yaml:"scoreRetryLatency,omitempty" json:"scoreRetryLatency"`
+	// UptimeMaxErrorRate is the error rate from which an interval counts as unavailable
+	UptimeMaxErrorRate float64 `yaml:"uptimeMaxErrorRate,omitempty" json:"uptimeMaxErrorRate"`
+	// RateTimeConstant is how fast the smoothed request and error rates follow traffic changes
+	RateTimeConstant Duration `yaml:"rateTimeConstant,omitempty" json:"rateTimeConstant" tstype:"Duration"`
+	// WarmupRamp caps the traffic of upstreams returning from a cordon
+	WarmupRamp *WarmupRampConfig `yaml:"warmupRamp,omitempty" json:"warmupRamp"`
+	// LatencyAnomaly flags upstreams whose latency deviates from their own baseline
+	LatencyAnomaly *LatencyAnomalyConfig `yaml:"latencyAnomaly,omitempty" json:"latencyAnomaly"`
+
+	// ClientTopK is the number of clients tracked individually
+	ClientTopK int `yaml:"clientTopK,omitempty" json:"clientTopK"`
+	// LatencyBuckets makes latency quantiles slide over the window in this many sub-buckets
+	LatencyBuckets int `yaml:"latencyBuckets,omitempty" json:"latencyBuckets"`
+	// RecentErrorsSize is the number of errors retained per upstream, network and method
+	RecentErrorsSize int `yaml:"recentErrorsSize,omitempty" json:"recentErrorsSize"`
+	// CounterOverflowMode is what happens to an overflowing counter: saturate or reset
+	CounterOverflowMode string `yaml:"counterOverflowMode,omitempty" json:"counterOverflowMode"`
+	// CancellationsAsErrors are the cancellation causes counted as errors: client, deadline or hedge
+	CancellationsAsErrors []string `yaml:"cancellationsAsErrors,omitempty" json:"cancellationsAsErrors"`
+	// MethodsExcludedFromAggregates are tracked under their own key only, e.g. health check methods
+	MethodsExcludedFromAggregates []string `yaml:"methodsExcludedFromAggregates,omitempty" json:"methodsExcludedFromAggregates"`
+	// MethodAliases resolves method names to a canonical one, e.g. parity_getBlockReceipts
+	MethodAliases map[string]string `yaml:"methodAliases,omitempty" json:"methodAliases"`
+	// NetworkAliases resolves network identifiers to a canonical one, e.g. eth-mainnet to evm:1
+	NetworkAliases map[string]string `yaml:"networkAliases,omitempty" json:"networkAliases"`
+	// WriteMethods replaces the methods classified as writes
+	WriteMethods []string `yaml:"writeMethods,omitempty" json:"writeMethods"`
+	// TraceSampling retains a sample of full request records
+	TraceSampling *TraceSamplingConfig `yaml:"traceSampling,omitempty" json:"traceSampling"`
+	// TrafficShareDivergence flags networks whose traffic share diverges from the intended one
+	TrafficShareDivergence *TrafficShareDivergenceConfig `yaml:"trafficShareDivergence,omitempty" json:"trafficShareDivergence"`
+	// LatencySLOs are serving latency objectives of methods, "*" for the upstream-wide keys
+	LatencySLOs []*LatencySLOConfig `yaml:"latencySLOs,omitempty" json:"latencySLOs"`
+	// SLOs are the availability and latency objectives of networks
+	SLOs []*SLOConfig `yaml:"slos,omitempty" json:"slos"`
+
+	// BlockNumberCeiling rejects block numbers above it
+	BlockNumberCeiling int64 `yaml:"blockNumberCeiling,omitempty" json:"blockNumberCeiling"`
+	// BlockHeadLargeRollbackDecay makes large rollbacks decay to zero over this duration
+	BlockHeadLargeRollbackDecay Duration `yaml:"blockHeadLargeRollbackDecay,omitempty" json:"blockHeadLargeRollbackDecay" tstype:"Duration"`
+	// ReorgTimeout closes the reorg windows left open
+	ReorgTimeout Duration `yaml:"reorgTimeout,omitempty" json:"reorgTimeout" tstype:"Duration"`
+	// ForkHorizon is how many blocks below the highest one block hashes are compared
+	ForkHorizon int64 `yaml:"forkHorizon,omitempty" json:"forkHorizon"`
+	// HistoricalLagMargin is how many blocks below the head requested data counts as historical
+	HistoricalLagMargin int64 `yaml:"historicalLagMargin,omitempty" json:"historicalLagMargin"`
+	// BehindHeadEvidenceLag is the lag assumed for the window of an upstream returning behind head errors
+	BehindHeadEvidenceLag int64 `yaml:"behindHeadEvidenceLag,omitempty" json:"behindHeadEvidenceLag"`
+	// BehindHeadMatchers replaces the error messages classified as behind head per client
+	BehindHeadMatchers map[string][]string `yaml:"behindHeadMatchers,omitempty" json:"behindHeadMatchers"`
+
+	// CordonDryRun only logs and counts the automatic cordons instead of applying them
+	CordonDryRun bool `yaml:"cordonDryRun,omitempty" json:"cordonDryRun"`
+	// ChainIdMismatchPenalty is cordon or flag
+	ChainIdMismatchPenalty string `yaml:"chainIdMismatchPenalty,omitempty" json:"chainIdMismatchPenalty"`
+	// HandshakeCordonThreshold cordons upstreams after this many consecutive failed handshakes
+	HandshakeCordonThreshold int64 `yaml:"handshakeCordonThreshold,omitempty" json:"handshakeCordonThreshold"`
+	// ReconnectCordonThreshold cordons upstreams reconnecting more than this many times within a window
+	ReconnectCordonThreshold int64 `yaml:"reconnectCordonThreshold,omitempty" json:"reconnectCordonThreshold"`
+	// MalformedResponseCordonThreshold cordons upstreams after this many malformed responses within a window
+	MalformedResponseCordonThreshold int64 `yaml:"malformedResponseCordonThreshold,omitempty" json:"malformedResponseCordonThreshold"`
+	// ShortResultCordon cordons upstreams after this many short results within a window
+	ShortResultCordon int64 `yaml:"shortResultCordon,omitempty" json:"shortResultCordon"`
+	// DisagreementCordon cordons upstreams disagreeing with the consensus too often
+	DisagreementCordon *RateCordonConfig `yaml:"disagreementCordon,omitempty" json:"disagreementCordon"`
+	// MismatchCordon cordons upstreams returning mismatching data too often
+	MismatchCordon *RateCordonConfig `yaml:"mismatchCordon,omitempty" json:"mismatchCordon"`
+	// CordonRecovery lifts the automatic cordons of a reason once their condition cleared
+	CordonRecovery map[string]*CordonRecoveryConfig `yaml:"cordonRecovery,omitempty" json:"cordonRecovery"`
+}
+
+type WriteHealthBarConfig struct {
+	MaxErrorRate    float64 `yaml:"maxErrorRate,omitempty" json:"maxErrorRate"`
+	MaxBlockHeadLag int64   `yaml:"maxBlockHeadLag,omitempty" json:"maxBlockHeadLag"`
+}
+
+type WarmupRampConfig struct {
+	Duration Duration `yaml:"duration,omitempty" json:"duration" tstype:"Duration"`
+	// Curve is linear or exponential
+	Curve              string  `yaml:"curve,omitempty" json:"curve"`
+	InitialShare       float64 `yaml:"initialShare,omitempty" json:"initialShare"`
+	MaxErrorRate       float64 `yaml:"maxErrorRate,omitempty" json:"maxErrorRate"`
+	MinSamples         int64   `yaml:"minSamples,omitempty" json:"minSamples"`
+	AbortOnDegradation bool    `yaml:"abortOnDegradation,omitempty" json:"abortOnDegradation"`
+}
+
+type LatencyAnomalyConfig struct {
+	Alpha              float64 `yaml:"alpha,omitempty" json:"alpha"`
+	MinWindows         int     `yaml:"minWindows,omitempty" json:"minWindows"`
+	Factor             float64 `yaml:"factor,omitempty" json:"factor"`
+	ConsecutiveWindows int     `yaml:"consecutiveWindows,omitempty" json:"consecutiveWindows"`
+	MaxIdleWindows     int     `yaml:"maxIdleWindows,omitempty" json:"maxIdleWindows"`
+	RebaselineWindows  int     `yaml:"rebaselineWindows,omitempty" json:"rebaselineWindows"`
+	EmitEvents         bool    `yaml:"emitEvents,omitempty" json:"emitEvents"`
+	ScorePenalty       float64 `yaml:"scorePenalty,omitempty" json:"scorePenalty"`
+}
+
+type TraceSamplingConfig struct {
+	Rate     float64 `yaml:"rate,omitempty" json:"rate"`
+	RingSize int     `yaml:"ringSize,omitempty" json:"ringSize"`
+}
+
+type TrafficShareDivergenceConfig struct {
+	Threshold float64 `yaml:"threshold,omitempty" json:"threshold"`
+	Windows   int     `yaml:"windows,omitempty" json:"windows"`
+}
+
+type LatencySLOConfig struct {
+	Method     string   `yaml:"method" json:"method"`
+	Percentile float64  `yaml:"percentile" json:"percentile"`
+	Target     Duration `yaml:"target" json:"target" tstype:"Duration"`
+}
+
+type SLOConfig struct {
+	Network            string                 `yaml:"network" json:"network"`
+	AvailabilityTarget float64                `yaml:"availabilityTarget,omitempty" json:"availabilityTarget"`
+	LatencyTarget      Duration               `yaml:"latencyTarget,omitempty" json:"latencyTarget" tstype:"Duration"`
+	LatencyQuantile    float64                `yaml:"latencyQuantile,omitempty" json:"latencyQuantile"`
+	BurnRateAlerts     []*BurnRateAlertConfig `yaml:"burnRateAlerts,omitempty" json:"burnRateAlerts"`
+}
+
+type BurnRateAlertConfig struct {
+	ShortWindow Duration `yaml:"shortWindow" json:"shortWindow" tstype:"Duration"`
+	LongWindow  Duration `yaml:"longWindow" json:"longWindow" tstype:"Duration"`
+	Threshold   float64  `yaml:"threshold" json:"threshold"`
+}
+
+// RateCordonConfig cordons an upstream once the rate of a condition exceeds "maxRate" over at
+// least "minSamples" samples within a window.
+type RateCordonConfig struct {
+	MaxRate    float64 `yaml:"maxRate" json:"maxRate"`
+	MinSamples int64   `yaml:"minSamples,omitempty" json:"minSamples"`
+}
+
+type CordonRecoveryConfig struct {
+	Interval       Duration `yaml:"interval,omitempty" json:"interval" tstype:"Duration"`
+	Evaluations    int      `yaml:"evaluations,omitempty" json:"evaluations"`
+	ClearThreshold float64  `yaml:"clearThreshold,omitempty" json:"clearThreshold"`
 }
 
 type NetworkDefaults struct {
@@ -376,6 +546,10 @@ type UpstreamConfig struct {
 	RateLimitBudget              string                   `yaml:"rateLimitBudget,omitempty" json:"rateLimitBudget"`
 	RateLimitAutoTune            *RateLimitAutoTuneConfig `yaml:"rateLimitAutoTune,omitempty" json:"rateLimitAutoTune"`
 	Routing                      *RoutingConfig           `yaml:"routing,omitempty" json:"routing"`
+	CertificateCheck             *CertificateCheckConfig  `yaml:"certificateCheck,omitempty" json:"certificateCheck"`
+	Shadow                       *ShadowConfig            `yaml:"shadow,omitempty" json:"shadow"`
+	MaxConcurrency               int                      `yaml:"maxConcurrency,omitempty" json:"maxConcurrency"`
+	ErrorBudget                  *ErrorBudgetConfig       `yaml:"errorBudget,omitempty" json:"errorBudget"`
 }
 
 func (c *UpstreamConfig) Copy() *UpstreamConfig {
@@ -401,6 +575,15 @@ func (c *UpstreamConfig) Copy() *UpstreamConfig {
 	if c.RateLimitAutoTune != nil {
 		copied.RateLimitAutoTune = c.RateLimitAutoTune.Copy()
 	}
+	if c.CertificateCheck != nil {
+		copied.CertificateCheck = c.CertificateCheck.Copy()
+	}
+	if c.Shadow != nil {
+		copied.Shadow = c.Shadow.Copy()
+	}
+	if c.ErrorBudget != nil {
+		copied.ErrorBudget = c.ErrorBudget.Copy()
+	}
 
 	if c.IgnoreMethods != nil {
 		copied.IgnoreMethods = make([]string, len(c.IgnoreMethods))
@@ -446,6 +629,9 @@ type ScoreMultiplierConfig struct {
 	ThrottledRate   float64 `yaml:"throttledRate" json:"throttledRate"`
 	BlockHeadLag    float64 `yaml:"blockHeadLag" json:"blockHeadLag"`
 	FinalizationLag float64 `yaml:"finalizationLag" json:"finalizationLag"`
+	// PartialResponseRate penalizes upstreams which do not return complete result sets, e.g.
+	// eth_getLogs results they refuse to return in full. Disabled by default
+	PartialResponseRate float64 `yaml:"partialResponseRate" json:"partialResponseRate"`
 }
 
 func (c *ScoreMultiplierConfig) Copy() *ScoreMultiplierConfig {
@@ -489,6 +675,63 @@ func (c *RateLimitAutoTuneConfig) Copy() *RateLimitAutoTuneConfig {
 	return copied
 }
 
+// CertificateCheckConfig enables probing the TLS certificate chain of an https/wss upstream to
+// report the days left until it expires.
+type CertificateCheckConfig struct {
+	Interval    Duration `yaml:"interval,omitempty" json:"interval" tstype:"Duration"`
+	WarningDays float64  `yaml:"warningDays,omitempty" json:"warningDays"`
+}
+
+func (c *CertificateCheckConfig) Copy() *CertificateCheckConfig {
+	if c == nil {
+		return nil
+	}
+
+	copied := &CertificateCheckConfig{}
+	*copied = *c
+
+	return copied
+}
+
+// ShadowConfig makes an upstream a shadow: it is never selected to serve requests, but receives a
+// sample of the reads of its network, whose responses are discarded, so that it can be evaluated
+// on real traffic before going into production.
+type ShadowConfig struct {
+	SampleRate float64 `yaml:"sampleRate,omitempty" json:"sampleRate"`
+}
+
+func (c *ShadowConfig) Copy() *ShadowConfig {
+	if c == nil {
+		return nil
+	}
+
+	copied := &ShadowConfig{}
+	*copied = *c
+
+	return copied
+}
+
+// ErrorBudgetConfig allows an upstream to fail a "fraction" of its requests (at least "minErrors")
+// within a window, after which it is deprioritized ("penalize") or cordoned ("cordon") on its
+// network until the window ends.
+type ErrorBudgetConfig struct {
+	Fraction  float64 `yaml:"fraction" json:"fraction"`
+	MinErrors int64   `yaml:"minErrors,omitempty" json:"minErrors"`
+	Penalize  bool    `yaml:"penalize,omitempty" json:"penalize"`
+	Cordon    bool    `yaml:"cordon,omitempty" json:"cordon"`
+}
+
+func (c *ErrorBudgetConfig) Copy() *ErrorBudgetConfig {
+	if c == nil {
+		return nil
+	}
+
+	copied := &ErrorBudgetConfig{}
+	*copied = *c
+
+	return copied
+}
+
 type JsonRpcUpstreamConfig struct {
 	SupportsBatch *bool             `yaml:"supportsBatch,omitempty" json:"supportsBatch"`
 	BatchMaxSize  int               `yaml:"batchMaxSize,omitempty" json:"batchMaxSize"`
@@ -751,6 +994,36 @@ type NetworkConfig struct {
 	SelectionPolicy   *SelectionPolicyConfig   `yaml:"selectionPolicy,omitempty" json:"selectionPolicy"`
 	DirectiveDefaults *DirectiveDefaultsConfig `yaml:"directiveDefaults,omitempty" json:"directiveDefaults"`
 	Alias             string                   `yaml:"alias,omitempty" json:"alias"`
+	// LatencyCostClasses groups methods by cost so that upstream latencies are compared on their
+	// traffic mix, see health.LatencyCostClasses
+	LatencyCostClasses *LatencyCostClassesConfig `yaml:"latencyCostClasses,omitempty" json:"latencyCostClasses"`
+	// MethodImportance weighs methods in the error rate of upstreams, see health.MethodImportance
+	MethodImportance *MethodImportanceConfig `yaml:"methodImportance,omitempty" json:"methodImportance"`
+	// ErrorRateCordons cordon upstreams for a method ("*" for all of them) while their error rate is
+	// too high, keyed by method
+	ErrorRateCordons map[string]*ErrorRateCordonConfig `yaml:"errorRateCordons,omitempty" json:"errorRateCordons"`
+}
+
+type LatencyCostClassesConfig struct {
+	// Methods maps methods to their cost class (light, medium or heavy), methods not listed are medium
+	Methods map[string]string `yaml:"methods,omitempty" json:"methods"`
+	// Baselines are the expected p90 latency of each cost class
+	Baselines map[string]Duration `yaml:"baselines,omitempty" json:"baselines" tstype:"Record<string, Duration>"`
+	// ScoreNormalized makes selection score upstream latencies relative to the baselines
+	ScoreNormalized bool `yaml:"scoreNormalized,omitempty" json:"scoreNormalized"`
+}
+
+type MethodImportanceConfig struct {
+	// Weights of methods, methods not listed weigh 1 and a zero weight leaves a method out
+	Weights    map[string]float64 `yaml:"weights,omitempty" json:"weights"`
+	MinSamples int64              `yaml:"minSamples,omitempty" json:"minSamples"`
+}
+
+type ErrorRateCordonConfig struct {
+	MaxErrorRate       float64 `yaml:"maxErrorRate" json:"maxErrorRate"`
+	MinSamples         int64   `yaml:"minSamples,omitempty" json:"minSamples"`
+	CleanWindows       int     `yaml:"cleanWindows,omitempty" json:"cleanWindows"`
+	ProbationSuccesses int     `yaml:"probationSuccesses,omitempty" json:"probationSuccesses"`
 }
 
 type DirectiveDefaultsConfig struct {
@@ -818,6 +1091,11 @@ func (c *SelectionPolicyConfig) UnmarshalYAML(unmarshal func(interface{}) error)
 	return nil
 }
 
+// HasCustomEvalFunction tells if the policy evaluates another function than DefaultPolicyFunction.
+func (c *SelectionPolicyConfig) HasCustomEvalFunction() bool {
+	return c.EvalFunction != nil && c.evalFunctionOriginal != DefaultPolicyFunction
+}
+
 func (c *SelectionPolicyConfig) MarshalJSON() ([]byte, error) {
 	evf := "<undefined>"
 	if c.evalFunctionOriginal != "" {
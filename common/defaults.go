@@ -2,8 +2,10 @@ package common
 
 import (
 	"fmt"
+	"math"
 	"net"
 	"net/url"
+	"os"
 	"slices"
 	"strconv"
 	"strings"
@@ -864,6 +866,16 @@ func (p *ProjectConfig) SetDefaults() error {
 			return fmt.Errorf("failed to set defaults for cors: %w", err)
 		}
 	}
+	if p.BroadcastChecks != nil {
+		if err := p.BroadcastChecks.SetDefaults(); err != nil {
+			return fmt.Errorf("failed to set defaults for broadcast checks: %w", err)
+		}
+	}
+	if p.HealthTracker != nil && p.HealthTracker.LatencyAnomaly != nil {
+		if err := p.HealthTracker.LatencyAnomaly.SetDefaults(); err != nil {
+			return fmt.Errorf("failed to set defaults for latency anomaly: %w", err)
+		}
+	}
 	if p.ScoreMetricsWindowSize == 0 {
 		if p.DeprecatedHealthCheck != nil && p.DeprecatedHealthCheck.ScoreMetricsWindowSize != 0 {
 			log.Warn().Msg("projects.*.healthCheck.scoreMetricsWindowSize is deprecated; use projects.*.scoreMetricsWindowSize instead")
@@ -1058,6 +1070,15 @@ func (u *UpstreamConfig) ApplyDefaults(defaults *UpstreamConfig) error {
 	if u.RateLimitAutoTune == nil {
 		u.RateLimitAutoTune = defaults.RateLimitAutoTune
 	}
+	if u.CertificateCheck == nil && defaults.CertificateCheck != nil {
+		u.CertificateCheck = defaults.CertificateCheck.Copy()
+	}
+	if u.MaxConcurrency == 0 {
+		u.MaxConcurrency = defaults.MaxConcurrency
+	}
+	if u.ErrorBudget == nil && defaults.ErrorBudget != nil {
+		u.ErrorBudget = defaults.ErrorBudget.Copy()
+	}
 	// IMPORTANT: Some of the configs must be copied vs referenced, because the object might be updated in runtime only for this specific upstream
 	// TODO Should we refactor so this won't happen?
 	if u.Evm == nil && defaults.Evm != nil {
@@ -1153,6 +1174,21 @@ func (u *UpstreamConfig) SetDefaults(defaults *UpstreamConfig) error {
 			return fmt.Errorf("failed to set defaults for rate limit auto tune: %w", err)
 		}
 	}
+	if u.CertificateCheck != nil {
+		if err := u.CertificateCheck.SetDefaults(); err != nil {
+			return fmt.Errorf("failed to set defaults for certificate check: %w", err)
+		}
+	}
+	if u.Shadow != nil {
+		if err := u.Shadow.SetDefaults(); err != nil {
+			return fmt.Errorf("failed to set defaults for shadow: %w", err)
+		}
+	}
+	if u.ErrorBudget != nil {
+		if err := u.ErrorBudget.SetDefaults(); err != nil {
+			return fmt.Errorf("failed to set defaults for error budget: %w", err)
+		}
+	}
 
 	if u.Evm == nil {
 		if strings.HasPrefix(string(u.Type), "evm") {
@@ -1608,6 +1644,61 @@ func (r *RateLimitAutoTuneConfig) SetDefaults() error {
 	return nil
 }
 
+func (c *CertificateCheckConfig) SetDefaults() error {
+	if c.Interval == 0 {
+		c.Interval = Duration(6 * time.Hour)
+	}
+	if c.WarningDays == 0 {
+		c.WarningDays = 14
+	}
+
+	return nil
+}
+
+func (c *BroadcastChecksConfig) SetDefaults() error {
+	if c.Delay == 0 {
+		c.Delay = Duration(30 * time.Second)
+	}
+	if c.MaxPending == 0 {
+		c.MaxPending = 1000
+	}
+
+	return nil
+}
+
+func (c *LatencyAnomalyConfig) SetDefaults() error {
+	if c.Alpha == 0 {
+		c.Alpha = 0.1
+	}
+	if c.MinWindows == 0 {
+		c.MinWindows = 5
+	}
+	if c.Factor == 0 {
+		c.Factor = 4
+	}
+	if c.ConsecutiveWindows == 0 {
+		c.ConsecutiveWindows = 2
+	}
+
+	return nil
+}
+
+func (c *ErrorBudgetConfig) SetDefaults() error {
+	if !c.Penalize && !c.Cordon {
+		c.Penalize = true
+	}
+
+	return nil
+}
+
+func (c *ShadowConfig) SetDefaults() error {
+	if c.SampleRate == 0 {
+		c.SampleRate = 0.1
+	}
+
+	return nil
+}
+
 func (r *RoutingConfig) SetDefaults() error {
 	if r.ScoreMultipliers != nil {
 		for _, multiplier := range r.ScoreMultipliers {
@@ -1666,13 +1757,35 @@ func (s *ScoreMultiplierConfig) SetDefaults() error {
 	return nil
 }
 
-const DefaultPolicyFunction = `
+// Thresholds upstreams must stay under for DefaultPolicyFunction to select them, unless overridden
+// with the ROUTING_POLICY_MAX_ERROR_RATE and ROUTING_POLICY_MAX_BLOCK_HEAD_LAG environment
+// variables, see DefaultPolicyThresholds.
+const (
+	DefaultPolicyMaxErrorRate    = 0.7
+	DefaultPolicyMaxBlockHeadLag = 10
+)
+
+// DefaultPolicyThresholds returns the error rate and block head lag thresholds DefaultPolicyFunction
+// applies, environment overrides included. The block head lag being a whole number of blocks, the
+// threshold is rounded up.
+func DefaultPolicyThresholds() (maxErrorRate float64, maxBlockHeadLag int64) {
+	maxErrorRate, maxBlockHeadLag = DefaultPolicyMaxErrorRate, DefaultPolicyMaxBlockHeadLag
+	if v, err := strconv.ParseFloat(os.Getenv("ROUTING_POLICY_MAX_ERROR_RATE"), 64); err == nil {
+		maxErrorRate = v
+	}
+	if v, err := strconv.ParseFloat(os.Getenv("ROUTING_POLICY_MAX_BLOCK_HEAD_LAG"), 64); err == nil {
+		maxBlockHeadLag = int64(math.Ceil(v))
+	}
+	return maxErrorRate, maxBlockHeadLag
+}
+
+var DefaultPolicyFunction = fmt.Sprintf(`
 	(upstreams, method) => {
 		const defaults = upstreams.filter(u => u.config.group !== 'fallback')
 		const fallbacks = upstreams.filter(u => u.config.group === 'fallback')
 		
-		const maxErrorRate = parseFloat(process.env.ROUTING_POLICY_MAX_ERROR_RATE || '0.7')
-		const maxBlockHeadLag = parseFloat(process.env.ROUTING_POLICY_MAX_BLOCK_HEAD_LAG || '10')
+		const maxErrorRate = parseFloat(process.env.ROUTING_POLICY_MAX_ERROR_RATE || '%v')
+		const maxBlockHeadLag = parseFloat(process.env.ROUTING_POLICY_MAX_BLOCK_HEAD_LAG || '%v')
 		const minHealthyThreshold = parseInt(process.env.ROUTING_POLICY_MIN_HEALTHY_THRESHOLD || '1')
 		
 		const healthyOnes = defaults.filter(
@@ -1697,7 +1810,7 @@ const DefaultPolicyFunction = `
 		// Order of upstreams does not matter as that will be decided by the upstream scoring mechanism
 		return upstreams
 	}
-`
+`, DefaultPolicyMaxErrorRate, DefaultPolicyMaxBlockHeadLag)
 
 func (c *SelectionPolicyConfig) SetDefaults() error {
 	if c.EvalInterval == 0 {
@@ -1709,6 +1822,7 @@ func (c *SelectionPolicyConfig) SetDefaults() error {
 			log.Error().Err(err).Msg("failed to compile default selection policy function")
 		} else {
 			c.EvalFunction = evalFunction
+			c.evalFunctionOriginal = DefaultPolicyFunction
 		}
 	}
 	if c.ResampleExcluded {
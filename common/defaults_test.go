@@ -263,4 +263,54 @@ func TestSetDefaults_UpstreamConfig(t *testing.T) {
 		err = cfg.Validate()
 		assert.Nil(t, err, "Validate should pass when providers and upstreams with defaults are present")
 	})
+
+	t.Run("ErrorBudgetInheritedFromUpstreamDefaults", func(t *testing.T) {
+		cfg := &Config{
+			Projects: []*ProjectConfig{
+				{
+					Id: "test-error-budget",
+					UpstreamDefaults: &UpstreamConfig{
+						MaxConcurrency: 50,
+						ErrorBudget:    &ErrorBudgetConfig{Fraction: 0.01},
+					},
+					Upstreams: []*UpstreamConfig{
+						{Id: "rpc1", Endpoint: "http://rpc1.localhost"},
+						{Id: "rpc2", Endpoint: "http://rpc2.localhost", MaxConcurrency: 10, ErrorBudget: &ErrorBudgetConfig{Fraction: 0.05, Cordon: true}},
+					},
+				},
+			},
+		}
+		assert.NoError(t, cfg.SetDefaults())
+
+		rpc1, rpc2 := cfg.Projects[0].Upstreams[0], cfg.Projects[0].Upstreams[1]
+		assert.Equal(t, 50, rpc1.MaxConcurrency)
+		assert.Equal(t, &ErrorBudgetConfig{Fraction: 0.01, Penalize: true}, rpc1.ErrorBudget)
+		assert.NotSame(t, cfg.Projects[0].UpstreamDefaults.ErrorBudget, rpc1.ErrorBudget)
+		assert.Equal(t, 10, rpc2.MaxConcurrency)
+		assert.Equal(t, &ErrorBudgetConfig{Fraction: 0.05, Cordon: true}, rpc2.ErrorBudget)
+	})
+}
+
+func TestDefaultPolicyThresholds(t *testing.T) {
+	maxErrorRate, maxBlockHeadLag := DefaultPolicyThresholds()
+	assert.Equal(t, DefaultPolicyMaxErrorRate, maxErrorRate)
+	assert.Equal(t, int64(DefaultPolicyMaxBlockHeadLag), maxBlockHeadLag)
+
+	t.Setenv("ROUTING_POLICY_MAX_ERROR_RATE", "0.3")
+	t.Setenv("ROUTING_POLICY_MAX_BLOCK_HEAD_LAG", "4.5")
+	maxErrorRate, maxBlockHeadLag = DefaultPolicyThresholds()
+	assert.Equal(t, 0.3, maxErrorRate)
+	assert.Equal(t, int64(5), maxBlockHeadLag)
+}
+
+func TestSelectionPolicyConfig_HasCustomEvalFunction(t *testing.T) {
+	cfg := &SelectionPolicyConfig{}
+	assert.NoError(t, cfg.SetDefaults())
+	assert.False(t, cfg.HasCustomEvalFunction())
+
+	evalFunction, err := CompileFunction(`(upstreams) => upstreams`)
+	assert.NoError(t, err)
+	cfg = &SelectionPolicyConfig{EvalFunction: evalFunction}
+	assert.NoError(t, cfg.SetDefaults())
+	assert.True(t, cfg.HasCustomEvalFunction())
 }
@@ -723,10 +723,12 @@ func (e *ErrUpstreamRequest) Hedges() int {
 
 type ErrUpstreamMalformedResponse struct{ BaseError }
 
+const ErrCodeUpstreamMalformedResponse ErrorCode = "ErrUpstreamMalformedResponse"
+
 var NewErrUpstreamMalformedResponse = func(cause error, upstreamId string) error {
 	return &ErrUpstreamMalformedResponse{
 		BaseError{
-			Code:    "ErrUpstreamMalformedResponse",
+			Code:    ErrCodeUpstreamMalformedResponse,
 			Message: "malformed response from upstream",
 			Cause:   cause,
 			Details: map[string]interface{}{
@@ -740,6 +742,24 @@ func (e *ErrUpstreamMalformedResponse) ErrorStatusCode() int {
 	return http.StatusBadRequest
 }
 
+// NewErrUpstreamMalformedPayload is a malformed response keeping the first bytes of its payload.
+var NewErrUpstreamMalformedPayload = func(cause error, upstreamId string, payload []byte) error {
+	err := NewErrUpstreamMalformedResponse(cause, upstreamId).(*ErrUpstreamMalformedResponse)
+	if len(payload) > maxUnparsedBodyPrefix {
+		payload = payload[:maxUnparsedBodyPrefix]
+	}
+	err.Details["payload"] = string(payload)
+	return err
+}
+
+// NewErrUpstreamResponseIdMismatch is a malformed response whose ids match none of the requests
+// sent to the upstream.
+var NewErrUpstreamResponseIdMismatch = func(cause error, upstreamId string, responseIds []interface{}) error {
+	err := NewErrUpstreamMalformedResponse(cause, upstreamId).(*ErrUpstreamMalformedResponse)
+	err.Details["responseIds"] = responseIds
+	return err
+}
+
 type ErrUpstreamsExhausted struct{ BaseError }
 
 const ErrCodeUpstreamsExhausted ErrorCode = "ErrUpstreamsExhausted"
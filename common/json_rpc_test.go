@@ -1,9 +1,12 @@
 package common
 
 import (
+	"io"
 	"reflect"
 	"runtime"
+	"strings"
 	"testing"
+	"testing/iotest"
 	"time"
 )
 
@@ -50,3 +53,23 @@ func TestEnsureCachedNode_HeapAllocation(t *testing.T) {
 		t.Fatal("cached node content changed unexpectedly")
 	}
 }
+
+func TestNormalizedResponse_UnparsedBody(t *testing.T) {
+	page := `{"jsonrpc":"2.0","id":1,"result":{"logs":["` + strings.Repeat("x", 1024)
+	body := io.MultiReader(strings.NewReader(page), iotest.ErrReader(io.ErrUnexpectedEOF))
+	r := NewNormalizedResponse().WithBody(io.NopCloser(body))
+	if _, err := r.JsonRpcResponse(); err == nil {
+		t.Fatal("Expected a cut body to fail parsing")
+	}
+	if got := string(r.UnparsedBody()); got != page[:maxUnparsedBodyPrefix] {
+		t.Errorf("Expected the first %d bytes of the body, got %q", maxUnparsedBodyPrefix, got)
+	}
+
+	r = NewNormalizedResponse().WithBody(io.NopCloser(strings.NewReader(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`)))
+	if _, err := r.JsonRpcResponse(); err != nil {
+		t.Fatalf("Expected a valid response to parse, got %v", err)
+	}
+	if r.UnparsedBody() != nil {
+		t.Error("Expected no unparsed body for a valid response")
+	}
+}
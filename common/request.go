@@ -18,6 +18,7 @@ const (
 	CompositeTypeNone               = "none"
 	CompositeTypeLogsSplitOnError   = "logs-split-on-error"
 	CompositeTypeLogsSplitProactive = "logs-split-proactive"
+	CompositeTypeShadow             = "shadow"
 )
 
 const RequestContextKey ContextKey = "request"
@@ -77,6 +78,8 @@ type NormalizedRequest struct {
 
 	compositeType   atomic.Value // Type of composite request (e.g., "logs-split")
 	parentRequestId atomic.Value // ID of the parent request (for sub-requests)
+	clientId        atomic.Value // Downstream client the request is made for (e.g. its API key)
+	finality        atomic.Value // Finality of the requested data, classified once for all attempts
 }
 
 func NewNormalizedRequest(body []byte) *NormalizedRequest {
@@ -493,3 +496,37 @@ func (r *NormalizedRequest) SetParentRequestId(parentId interface{}) {
 	}
 	r.parentRequestId.Store(parentId)
 }
+
+func (r *NormalizedRequest) ClientId() string {
+	if r == nil {
+		return ""
+	}
+	if id := r.clientId.Load(); id != nil {
+		return id.(string)
+	}
+	return ""
+}
+
+func (r *NormalizedRequest) SetClientId(clientId string) {
+	if r == nil || clientId == "" {
+		return
+	}
+	r.clientId.Store(clientId)
+}
+
+func (r *NormalizedRequest) Finality() (DataFinalityState, bool) {
+	if r == nil {
+		return DataFinalityStateUnknown, false
+	}
+	if f := r.finality.Load(); f != nil {
+		return f.(DataFinalityState), true
+	}
+	return DataFinalityStateUnknown, false
+}
+
+func (r *NormalizedRequest) SetFinality(finality DataFinalityState) {
+	if r == nil {
+		return
+	}
+	r.finality.Store(finality)
+}
@@ -11,12 +11,17 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+// maxUnparsedBodyPrefix bounds the prefix of a body kept when it cannot be parsed.
+const maxUnparsedBodyPrefix = 512
+
 type NormalizedResponse struct {
 	sync.RWMutex
 
 	request      *NormalizedRequest
 	body         io.ReadCloser
 	expectedSize int
+	// unparsedBody is the first bytes of a body which could not be parsed, see UnparsedBody
+	unparsedBody atomic.Pointer[[]byte]
 
 	fromCache bool
 	attempts  int
@@ -202,8 +207,10 @@ func (r *NormalizedResponse) JsonRpcResponse(ctx ...context.Context) (*JsonRpcRe
 	jrr := &JsonRpcResponse{}
 
 	if r.body != nil {
-		err := jrr.ParseFromStream(ctx, r.body, r.expectedSize)
+		body := &prefixReader{Reader: r.body, prefix: make([]byte, 0, maxUnparsedBodyPrefix)}
+		err := jrr.ParseFromStream(ctx, body, r.expectedSize)
 		if err != nil {
+			r.unparsedBody.Store(&body.prefix)
 			return nil, err
 		}
 		r.jsonRpcResponse.Store(jrr)
@@ -213,6 +220,32 @@ func (r *NormalizedResponse) JsonRpcResponse(ctx ...context.Context) (*JsonRpcRe
 	return nil, nil
 }
 
+// UnparsedBody returns the first bytes of a body which could not be parsed as a JSON-RPC
+// response, e.g. to sample malformed responses, nil otherwise.
+func (r *NormalizedResponse) UnparsedBody() []byte {
+	if r == nil {
+		return nil
+	}
+	if body := r.unparsedBody.Load(); body != nil {
+		return *body
+	}
+	return nil
+}
+
+// prefixReader keeps a copy of the first bytes read through it, up to the capacity of prefix.
+type prefixReader struct {
+	io.Reader
+	prefix []byte
+}
+
+func (p *prefixReader) Read(b []byte) (int, error) {
+	n, err := p.Reader.Read(b)
+	if room := cap(p.prefix) - len(p.prefix); room > 0 && n > 0 {
+		p.prefix = append(p.prefix, b[:min(n, room)]...)
+	}
+	return n, err
+}
+
 func (r *NormalizedResponse) WithBody(body io.ReadCloser) *NormalizedResponse {
 	r.body = body
 	return r
@@ -470,6 +470,16 @@ func (p *ProjectConfig) Validate(c *Config) error {
 			return err
 		}
 	}
+	if p.BroadcastChecks != nil {
+		if err := p.BroadcastChecks.Validate(); err != nil {
+			return err
+		}
+	}
+	if p.HealthTracker != nil {
+		if err := p.HealthTracker.Validate(); err != nil {
+			return err
+		}
+	}
 	if p.RateLimitBudget != "" {
 		if !c.HasRateLimiterBudget(p.RateLimitBudget) {
 			return fmt.Errorf("project.*.rateLimitBudget '%s' does not exist in config.rateLimiters", p.RateLimitBudget)
@@ -481,6 +491,169 @@ func (p *ProjectConfig) Validate(c *Config) error {
 	return nil
 }
 
+func (b *BroadcastChecksConfig) Validate() error {
+	if b.Delay < 0 {
+		return fmt.Errorf("project.*.broadcastChecks.delay must be greater than or equal to 0")
+	}
+	if b.MaxPending < 0 {
+		return fmt.Errorf("project.*.broadcastChecks.maxPending must be greater than or equal to 0")
+	}
+	return nil
+}
+
+func (h *HealthTrackerConfig) Validate() error {
+	if h.FailureCooldown < 0 {
+		return fmt.Errorf("project.*.healthTracker.failureCooldown must be greater than or equal to 0")
+	}
+	if h.WriteHealthBar != nil {
+		if h.WriteHealthBar.MaxErrorRate < 0 || h.WriteHealthBar.MaxErrorRate > 1 {
+			return fmt.Errorf("project.*.healthTracker.writeHealthBar.maxErrorRate must be between 0 and 1")
+		}
+		if h.WriteHealthBar.MaxBlockHeadLag < 0 {
+			return fmt.Errorf("project.*.healthTracker.writeHealthBar.maxBlockHeadLag must be greater than or equal to 0")
+		}
+	}
+	if h.MinEligibleUpstreams < 0 {
+		return fmt.Errorf("project.*.healthTracker.minEligibleUpstreams must be greater than or equal to 0")
+	}
+	switch h.TieBreak {
+	case "", "alphabetical", "roundRobin", "lowestLatency":
+	default:
+		return fmt.Errorf("project.*.healthTracker.tieBreak must be alphabetical, roundRobin or lowestLatency, got '%s'", h.TieBreak)
+	}
+	switch h.NoDataBehavior {
+	case "", "zero", "nan":
+	default:
+		return fmt.Errorf("project.*.healthTracker.noDataBehavior must be zero or nan, got '%s'", h.NoDataBehavior)
+	}
+	if h.UptimeMaxErrorRate < 0 || h.UptimeMaxErrorRate > 1 {
+		return fmt.Errorf("project.*.healthTracker.uptimeMaxErrorRate must be between 0 and 1")
+	}
+	if h.RateTimeConstant < 0 {
+		return fmt.Errorf("project.*.healthTracker.rateTimeConstant must be greater than or equal to 0")
+	}
+	if h.WarmupRamp != nil {
+		if h.WarmupRamp.Duration <= 0 {
+			return fmt.Errorf("project.*.healthTracker.warmupRamp.duration must be greater than 0")
+		}
+		switch h.WarmupRamp.Curve {
+		case "", "linear", "exponential":
+		default:
+			return fmt.Errorf("project.*.healthTracker.warmupRamp.curve must be linear or exponential, got '%s'", h.WarmupRamp.Curve)
+		}
+		if h.WarmupRamp.InitialShare < 0 || h.WarmupRamp.InitialShare > 1 {
+			return fmt.Errorf("project.*.healthTracker.warmupRamp.initialShare must be between 0 and 1")
+		}
+	}
+	if h.LatencyAnomaly != nil {
+		if h.LatencyAnomaly.Alpha <= 0 || h.LatencyAnomaly.Alpha > 1 {
+			return fmt.Errorf("project.*.healthTracker.latencyAnomaly.alpha must be greater than 0 and at most 1")
+		}
+		if h.LatencyAnomaly.Factor <= 1 {
+			return fmt.Errorf("project.*.healthTracker.latencyAnomaly.factor must be greater than 1")
+		}
+		if h.LatencyAnomaly.ScorePenalty < 0 || h.LatencyAnomaly.ScorePenalty > 1 {
+			return fmt.Errorf("project.*.healthTracker.latencyAnomaly.scorePenalty must be between 0 and 1")
+		}
+	}
+	if h.ClientTopK < 0 {
+		return fmt.Errorf("project.*.healthTracker.clientTopK must be greater than or equal to 0")
+	}
+	if h.LatencyBuckets < 0 {
+		return fmt.Errorf("project.*.healthTracker.latencyBuckets must be greater than or equal to 0")
+	}
+	if h.RecentErrorsSize < 0 {
+		return fmt.Errorf("project.*.healthTracker.recentErrorsSize must be greater than or equal to 0")
+	}
+	switch h.CounterOverflowMode {
+	case "", "saturate", "reset":
+	default:
+		return fmt.Errorf("project.*.healthTracker.counterOverflowMode must be saturate or reset, got '%s'", h.CounterOverflowMode)
+	}
+	for _, cause := range h.CancellationsAsErrors {
+		if cause != "client" && cause != "deadline" && cause != "hedge" {
+			return fmt.Errorf("project.*.healthTracker.cancellationsAsErrors must contain client, deadline or hedge, got '%s'", cause)
+		}
+	}
+	if h.TraceSampling != nil {
+		if h.TraceSampling.Rate < 0 || h.TraceSampling.Rate > 1 {
+			return fmt.Errorf("project.*.healthTracker.traceSampling.rate must be between 0 and 1")
+		}
+	}
+	for _, slo := range h.LatencySLOs {
+		if slo.Method == "" {
+			return fmt.Errorf("project.*.healthTracker.latencySLOs.*.method is required")
+		}
+		if slo.Percentile <= 0 || slo.Percentile >= 1 {
+			return fmt.Errorf("project.*.healthTracker.latencySLOs.*.percentile must be greater than 0 and less than 1")
+		}
+		if slo.Target <= 0 {
+			return fmt.Errorf("project.*.healthTracker.latencySLOs.*.target must be greater than 0")
+		}
+	}
+	for _, slo := range h.SLOs {
+		if slo.Network == "" {
+			return fmt.Errorf("project.*.healthTracker.slos.*.network is required")
+		}
+		if slo.AvailabilityTarget < 0 || slo.AvailabilityTarget >= 1 {
+			return fmt.Errorf("project.*.healthTracker.slos.*.availabilityTarget must be between 0 and 1")
+		}
+		if slo.LatencyTarget < 0 {
+			return fmt.Errorf("project.*.healthTracker.slos.*.latencyTarget must be greater than or equal to 0")
+		}
+		if slo.LatencyTarget > 0 && (slo.LatencyQuantile <= 0 || slo.LatencyQuantile >= 1) {
+			return fmt.Errorf("project.*.healthTracker.slos.*.latencyQuantile must be greater than 0 and less than 1")
+		}
+		for _, alert := range slo.BurnRateAlerts {
+			if alert.ShortWindow <= 0 || alert.LongWindow < alert.ShortWindow {
+				return fmt.Errorf("project.*.healthTracker.slos.*.burnRateAlerts.*.shortWindow must be greater than 0 and at most longWindow")
+			}
+			if alert.Threshold <= 0 {
+				return fmt.Errorf("project.*.healthTracker.slos.*.burnRateAlerts.*.threshold must be greater than 0")
+			}
+		}
+	}
+	if h.BlockNumberCeiling < 0 {
+		return fmt.Errorf("project.*.healthTracker.blockNumberCeiling must be greater than or equal to 0")
+	}
+	switch h.ChainIdMismatchPenalty {
+	case "", "cordon", "flag":
+	default:
+		return fmt.Errorf("project.*.healthTracker.chainIdMismatchPenalty must be cordon or flag, got '%s'", h.ChainIdMismatchPenalty)
+	}
+	if h.DisagreementCordon != nil {
+		if err := h.DisagreementCordon.Validate("disagreementCordon"); err != nil {
+			return err
+		}
+	}
+	if h.MismatchCordon != nil {
+		if err := h.MismatchCordon.Validate("mismatchCordon"); err != nil {
+			return err
+		}
+	}
+	for reason, recovery := range h.CordonRecovery {
+		switch reason {
+		case "ErrorRate", "DataMismatch", "DataDisagreement", "ShortResults", "HandshakeFailing":
+		default:
+			return fmt.Errorf("project.*.healthTracker.cordonRecovery has an unknown reason '%s', must be ErrorRate, DataMismatch, DataDisagreement, ShortResults or HandshakeFailing", reason)
+		}
+		if recovery.ClearThreshold < 0 || recovery.ClearThreshold >= 1 {
+			return fmt.Errorf("project.*.healthTracker.cordonRecovery.%s.clearThreshold must be greater than or equal to 0 and less than 1", reason)
+		}
+	}
+	return nil
+}
+
+func (r *RateCordonConfig) Validate(name string) error {
+	if r.MaxRate <= 0 || r.MaxRate > 1 {
+		return fmt.Errorf("project.*.healthTracker.%s.maxRate must be greater than 0 and at most 1", name)
+	}
+	if r.MinSamples < 0 {
+		return fmt.Errorf("project.*.healthTracker.%s.minSamples must be greater than or equal to 0", name)
+	}
+	return nil
+}
+
 func (a *AuthConfig) Validate() error {
 	if a.Strategies == nil || len(a.Strategies) == 0 {
 		return fmt.Errorf("project.*.auth.strategies is required, add at least one strategy")
@@ -629,6 +802,19 @@ func (u *UpstreamConfig) Validate(c *Config, skipEndpointCheck bool) error {
 			return err
 		}
 	}
+	if u.Shadow != nil {
+		if err := u.Shadow.Validate(); err != nil {
+			return err
+		}
+	}
+	if u.MaxConcurrency < 0 {
+		return fmt.Errorf("upstream.*.maxConcurrency must be greater than or equal to 0")
+	}
+	if u.ErrorBudget != nil {
+		if err := u.ErrorBudget.Validate(); err != nil {
+			return err
+		}
+	}
 	if u.RateLimitBudget != "" {
 		if !c.HasRateLimiterBudget(u.RateLimitBudget) {
 			return fmt.Errorf("upstream.*.rateLimitBudget '%s' does not exist in config.rateLimiters", u.RateLimitBudget)
@@ -796,6 +982,23 @@ func (r *RoutingConfig) Validate() error {
 	return nil
 }
 
+func (s *ShadowConfig) Validate() error {
+	if s.SampleRate <= 0 || s.SampleRate > 1 {
+		return fmt.Errorf("upstream.*.shadow.sampleRate must be greater than 0 and at most 1")
+	}
+	return nil
+}
+
+func (b *ErrorBudgetConfig) Validate() error {
+	if b.Fraction <= 0 || b.Fraction > 1 {
+		return fmt.Errorf("upstream.*.errorBudget.fraction must be greater than 0 and at most 1")
+	}
+	if b.MinErrors < 0 {
+		return fmt.Errorf("upstream.*.errorBudget.minErrors must be greater than or equal to 0")
+	}
+	return nil
+}
+
 func (n *NetworkConfig) Validate(c *Config) error {
 	if n.Architecture == "" {
 		return fmt.Errorf("network.*.architecture is required")
@@ -818,6 +1021,23 @@ func (n *NetworkConfig) Validate(c *Config) error {
 			return err
 		}
 	}
+	if n.LatencyCostClasses != nil {
+		if err := n.LatencyCostClasses.Validate(); err != nil {
+			return err
+		}
+	}
+	if n.MethodImportance != nil {
+		for method, weight := range n.MethodImportance.Weights {
+			if weight < 0 {
+				return fmt.Errorf("network.*.methodImportance.weights.%s must be greater than or equal to 0", method)
+			}
+		}
+	}
+	for method, cordon := range n.ErrorRateCordons {
+		if cordon.MaxErrorRate <= 0 || cordon.MaxErrorRate > 1 {
+			return fmt.Errorf("network.*.errorRateCordons.%s.maxErrorRate must be greater than 0 and at most 1", method)
+		}
+	}
 	if n.RateLimitBudget != "" {
 		if !c.HasRateLimiterBudget(n.RateLimitBudget) {
 			return fmt.Errorf("network.*.rateLimitBudget '%s' does not exist in config.rateLimiters", n.RateLimitBudget)
@@ -842,6 +1062,26 @@ func (e *EvmNetworkConfig) Validate() error {
 	return nil
 }
 
+func (c *LatencyCostClassesConfig) Validate() error {
+	validClass := func(class string) bool {
+		return class == "light" || class == "medium" || class == "heavy"
+	}
+	for method, class := range c.Methods {
+		if !validClass(class) {
+			return fmt.Errorf("network.*.latencyCostClasses.methods.%s must be light, medium or heavy, got '%s'", method, class)
+		}
+	}
+	for class, baseline := range c.Baselines {
+		if !validClass(class) {
+			return fmt.Errorf("network.*.latencyCostClasses.baselines has an unknown cost class '%s', must be light, medium or heavy", class)
+		}
+		if baseline <= 0 {
+			return fmt.Errorf("network.*.latencyCostClasses.baselines.%s must be greater than 0", class)
+		}
+	}
+	return nil
+}
+
 func (c *SelectionPolicyConfig) Validate() error {
 	if c.EvalInterval <= 0 {
 		return fmt.Errorf("selectionPolicy.evalInterval must be greater than 0")
@@ -880,5 +1120,8 @@ func (p *ScoreMultiplierConfig) Validate() error {
 	if p.FinalizationLag < 0 {
 		return fmt.Errorf("priorityMultipliers.*.finalizationLag multiplier must be greater than or equal to 0")
 	}
+	if p.PartialResponseRate < 0 {
+		return fmt.Errorf("priorityMultipliers.*.partialResponseRate multiplier must be greater than or equal to 0")
+	}
 	return nil
 }
@@ -28,6 +28,10 @@ type ConsensusPolicyBuilder[R any] interface {
 	OnDispute(listener func(failsafe.ExecutionEvent[R])) ConsensusPolicyBuilder[R]
 	OnFailure(listener func(failsafe.ExecutionEvent[R])) ConsensusPolicyBuilder[R]
 	OnLowParticipants(listener func(failsafe.ExecutionEvent[R])) ConsensusPolicyBuilder[R]
+	// OnComparison is called with the successful results of the participants of an execution, once
+	// they all completed. The executor does not fan out to participants yet, so it is not called
+	// until it does.
+	OnComparison(listener func(results []R)) ConsensusPolicyBuilder[R]
 
 	// Build returns a new ConsensusPolicy using the builder's configuration.
 	Build() ConsensusPolicy[R]
@@ -47,6 +51,7 @@ type config[R any] struct {
 	onDispute         func(event failsafe.ExecutionEvent[R])
 	onFailure         func(event failsafe.ExecutionEvent[R])
 	onLowParticipants func(event failsafe.ExecutionEvent[R])
+	onComparison      func(results []R)
 }
 
 var _ ConsensusPolicyBuilder[any] = &config[any]{}
@@ -113,6 +118,11 @@ func (c *config[R]) OnLowParticipants(listener func(failsafe.ExecutionEvent[R]))
 	return c
 }
 
+func (c *config[R]) OnComparison(listener func(results []R)) ConsensusPolicyBuilder[R] {
+	c.onComparison = listener
+	return c
+}
+
 func (c *config[R]) Build() ConsensusPolicy[R] {
 	hCopy := *c
 	if !c.BaseAbortablePolicy.IsConfigured() {
@@ -0,0 +1,93 @@
+package erpc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/erpc/erpc/auth"
+	"github.com/erpc/erpc/common"
+)
+
+const (
+	// adminHealthExplainPath serves the breakdown of the last scores of the upstreams of a network
+	// for a method, see handleAdminHealthExplain.
+	adminHealthExplainPath = "/admin/health/explain"
+	// adminHealthExplainMethod is the method the requests to adminHealthExplainPath are
+	// authenticated as.
+	adminHealthExplainMethod = "erpc_healthExplain"
+
+	// explainScoresHeader asks for the explanation of the scores a single request was routed with
+	// to be attached to its response in scoreExplanationHeader, see canExplainScores.
+	explainScoresHeader    = "X-ERPC-Explain-Scores"
+	scoreExplanationHeader = "X-ERPC-Score-Explanation"
+)
+
+// handleAdminHealthExplain serves GET /admin/health/explain?project=<id>&network=<id>&method=<method>
+// behind the admin authentication, with the explanation of the last score refresh of the network
+// for the method (see upstream.UpstreamsRegistry.ExplainScores).
+func (s *HttpServer) handleAdminHealthExplain(
+	ctx context.Context,
+	w http.ResponseWriter,
+	r *http.Request,
+	startedAt *time.Time,
+	encoder sonic.Encoder,
+	writeFatalError func(ctx context.Context, statusCode int, body error),
+) {
+	logger := s.logger.With().Str("component", "admin").Str("handler", "healthExplain").Logger()
+	if s.adminCfg == nil {
+		handleErrorResponse(ctx, &logger, startedAt, nil, common.NewErrAuthUnauthorized("", "admin is not enabled"), w, encoder, writeFatalError, false)
+		return
+	}
+	if s.adminCfg.CORS != nil && !s.handleCORS(ctx, w, r, s.adminCfg.CORS) {
+		return
+	}
+
+	args := r.URL.Query()
+	ap, err := auth.NewPayloadFromHttp(adminHealthExplainMethod, r.RemoteAddr, r.Header, args)
+	if err != nil {
+		handleErrorResponse(ctx, &logger, startedAt, nil, err, w, encoder, writeFatalError, true)
+		return
+	}
+	if err := s.erpc.AdminAuthenticate(ctx, adminHealthExplainMethod, ap); err != nil {
+		handleErrorResponse(ctx, &logger, startedAt, nil, err, w, encoder, writeFatalError, true)
+		return
+	}
+
+	projectId, networkId, method := args.Get("project"), args.Get("network"), args.Get("method")
+	if projectId == "" || networkId == "" || method == "" {
+		handleErrorResponse(ctx, &logger, startedAt, nil, common.NewErrInvalidRequest(fmt.Errorf("project, network and method query parameters are required")), w, encoder, writeFatalError, true)
+		return
+	}
+	project, err := s.erpc.GetProject(projectId)
+	if err != nil {
+		handleErrorResponse(ctx, &logger, startedAt, nil, err, w, encoder, writeFatalError, true)
+		return
+	}
+	explanation, ok := project.upstreamsRegistry.ExplainScores(networkId, method)
+	if !ok {
+		handleErrorResponse(ctx, &logger, startedAt, nil, common.NewErrInvalidRequest(fmt.Errorf("no scores calculated yet for method %s on network %s", method, networkId)), w, encoder, writeFatalError, true)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := encoder.Encode(explanation); err != nil {
+		logger.Error().Err(err).Msg("failed to encode score explanation")
+		writeFatalError(ctx, http.StatusInternalServerError, err)
+	}
+}
+
+// canExplainScores tells if the request carries admin credentials, which explainScoresHeader
+// requires like adminHealthExplainPath since explanations expose the upstreams of the project.
+func (s *HttpServer) canExplainScores(ctx context.Context, r *http.Request) bool {
+	if s.adminCfg == nil {
+		return false
+	}
+	ap, err := auth.NewPayloadFromHttp(adminHealthExplainMethod, r.RemoteAddr, r.Header, r.URL.Query())
+	if err != nil {
+		return false
+	}
+	return s.erpc.AdminAuthenticate(ctx, adminHealthExplainMethod, ap) == nil
+}
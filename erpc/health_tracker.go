@@ -0,0 +1,157 @@
+package erpc
+
+import (
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/health"
+	"github.com/erpc/erpc/upstream"
+)
+
+// configureHealthTracker applies the health tracker config of a project to its tracker and to
+// the selection of its upstreams. Fields left to their zero value keep the defaults.
+func configureHealthTracker(tracker *health.Tracker, upstreamsRegistry *upstream.UpstreamsRegistry, cfg *common.HealthTrackerConfig) error {
+	upstreamsRegistry.SetFailureCooldown(cfg.FailureCooldown.Duration())
+	if wb := cfg.WriteHealthBar; wb != nil {
+		upstreamsRegistry.SetWriteHealthBar(wb.MaxErrorRate, wb.MaxBlockHeadLag)
+	}
+
+	// Applies to the keys tracked from now on, so it goes first
+	tracker.SetLatencyBuckets(cfg.LatencyBuckets)
+	tracker.SetMinEligibleUpstreams(cfg.MinEligibleUpstreams)
+	switch cfg.TieBreak {
+	case "roundRobin":
+		tracker.SetTieBreak(health.TieBreakRoundRobin)
+	case "lowestLatency":
+		tracker.SetTieBreak(health.TieBreakLowestLatency)
+	}
+	if cfg.NoDataBehavior == "nan" {
+		tracker.SetNoDataBehavior(health.NoDataNaN)
+	}
+	tracker.SetScoreRetryLatency(cfg.ScoreRetryLatency)
+	tracker.SetUptimeMaxErrorRate(cfg.UptimeMaxErrorRate)
+	if cfg.RateTimeConstant > 0 {
+		tracker.SetRateTimeConstant(cfg.RateTimeConstant.Duration())
+	}
+	if r := cfg.WarmupRamp; r != nil {
+		curve := health.RampLinear
+		if r.Curve == "exponential" {
+			curve = health.RampExponential
+		}
+		if err := tracker.SetWarmupRamp(&health.WarmupRampConfig{
+			Duration:           r.Duration.Duration(),
+			Curve:              curve,
+			InitialShare:       r.InitialShare,
+			MaxErrorRate:       r.MaxErrorRate,
+			MinSamples:         r.MinSamples,
+			AbortOnDegradation: r.AbortOnDegradation,
+		}); err != nil {
+			return err
+		}
+	}
+	if a := cfg.LatencyAnomaly; a != nil {
+		tracker.SetLatencyAnomalyConfig(&health.LatencyAnomalyConfig{
+			Alpha:              a.Alpha,
+			MinWindows:         a.MinWindows,
+			Factor:             a.Factor,
+			ConsecutiveWindows: a.ConsecutiveWindows,
+			MaxIdleWindows:     a.MaxIdleWindows,
+			RebaselineWindows:  a.RebaselineWindows,
+			EmitEvents:         a.EmitEvents,
+			ScorePenalty:       a.ScorePenalty,
+		})
+	}
+
+	tracker.SetClientTopK(cfg.ClientTopK)
+	if cfg.RecentErrorsSize > 0 {
+		tracker.SetRecentErrorsSize(cfg.RecentErrorsSize)
+	}
+	if cfg.CounterOverflowMode == "reset" {
+		tracker.SetCounterOverflowMode(health.CounterOverflowReset)
+	}
+	if len(cfg.CancellationsAsErrors) > 0 {
+		causes := make([]health.CancelCause, len(cfg.CancellationsAsErrors))
+		for i, cause := range cfg.CancellationsAsErrors {
+			causes[i] = health.CancelCause(cause)
+		}
+		tracker.SetCancellationsAsErrors(causes...)
+	}
+	for _, method := range cfg.MethodsExcludedFromAggregates {
+		tracker.SetMethodInAggregate(method, false)
+	}
+	if len(cfg.MethodAliases) > 0 {
+		tracker.SetMethodAliases(cfg.MethodAliases)
+	}
+	if len(cfg.NetworkAliases) > 0 {
+		tracker.SetNetworkAliases(cfg.NetworkAliases)
+	}
+	if cfg.WriteMethods != nil {
+		tracker.SetWriteMethods(cfg.WriteMethods)
+	}
+	if ts := cfg.TraceSampling; ts != nil {
+		tracker.SetTraceSampling(ts.Rate, ts.RingSize)
+	}
+	if d := cfg.TrafficShareDivergence; d != nil {
+		tracker.SetTrafficShareDivergence(d.Threshold, d.Windows)
+	}
+	for _, slo := range cfg.LatencySLOs {
+		if err := tracker.SetLatencySLO(slo.Method, slo.Percentile, slo.Target.Duration()); err != nil {
+			return err
+		}
+	}
+	if len(cfg.SLOs) > 0 {
+		defs := make([]*health.SLODefinition, len(cfg.SLOs))
+		for i, slo := range cfg.SLOs {
+			defs[i] = &health.SLODefinition{
+				Network:            slo.Network,
+				AvailabilityTarget: slo.AvailabilityTarget,
+				LatencyTarget:      slo.LatencyTarget.Duration(),
+				LatencyQuantile:    slo.LatencyQuantile,
+			}
+			for _, alert := range slo.BurnRateAlerts {
+				defs[i].BurnRateAlerts = append(defs[i].BurnRateAlerts, health.BurnRateAlert{
+					ShortWindow: alert.ShortWindow.Duration(),
+					LongWindow:  alert.LongWindow.Duration(),
+					Threshold:   alert.Threshold,
+				})
+			}
+		}
+		if err := tracker.SetSLODefinitions(defs); err != nil {
+			return err
+		}
+	}
+
+	tracker.SetBlockNumberCeiling(cfg.BlockNumberCeiling)
+	tracker.SetBlockHeadLargeRollbackDecay(cfg.BlockHeadLargeRollbackDecay.Duration())
+	tracker.SetReorgTimeout(cfg.ReorgTimeout.Duration())
+	tracker.SetForkHorizon(cfg.ForkHorizon)
+	tracker.SetHistoricalLagMargin(cfg.HistoricalLagMargin)
+	tracker.SetBehindHeadEvidenceLag(cfg.BehindHeadEvidenceLag)
+	if cfg.BehindHeadMatchers != nil {
+		tracker.SetBehindHeadMatchers(health.BehindHeadMatchers(cfg.BehindHeadMatchers))
+	}
+
+	tracker.SetCordonDryRun(cfg.CordonDryRun)
+	if cfg.ChainIdMismatchPenalty == "flag" {
+		tracker.SetChainIdMismatchPenalty(health.ChainIdMismatchFlag)
+	}
+	tracker.SetHandshakeCordonThreshold(cfg.HandshakeCordonThreshold)
+	tracker.SetReconnectCordonThreshold(cfg.ReconnectCordonThreshold)
+	tracker.SetMalformedResponseCordonThreshold(cfg.MalformedResponseCordonThreshold)
+	tracker.SetShortResultCordon(cfg.ShortResultCordon)
+	if c := cfg.DisagreementCordon; c != nil {
+		tracker.SetDisagreementCordon(c.MaxRate, c.MinSamples)
+	}
+	if c := cfg.MismatchCordon; c != nil {
+		tracker.SetMismatchCordon(c.MaxRate, c.MinSamples)
+	}
+	for reason, c := range cfg.CordonRecovery {
+		if err := tracker.SetCordonRecovery(reason, &health.CordonRecoveryConfig{
+			Interval:       c.Interval.Duration(),
+			Evaluations:    c.Evaluations,
+			ClearThreshold: c.ClearThreshold,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -5,7 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -13,6 +15,7 @@ import (
 	"github.com/bytedance/sonic"
 	"github.com/erpc/erpc/auth"
 	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/health"
 	"github.com/erpc/erpc/upstream"
 )
 
@@ -179,6 +182,9 @@ func (s *HttpServer) handleHealthCheck(
 			if !s.isSimpleMode() {
 				mts := metricsTracker.GetUpstreamMethodMetrics(ups.Config().Id, "*", "*")
 				upstreamsDetails[ups.Config().Id]["metrics"] = mts
+				if samples := metricsTracker.MalformedResponseSamples(ups.Config().Id); len(samples) > 0 {
+					upstreamsDetails[ups.Config().Id]["malformedSamples"] = samples
+				}
 			}
 		}
 
@@ -249,6 +255,41 @@ func (s *HttpServer) handleHealthCheck(
 				}
 			}
 
+		case common.EvalAllEligibleUpstreams:
+			minEligible := 1
+			if v := r.URL.Query().Get("minEligible"); v != "" {
+				if n, err := strconv.Atoi(v); err == nil && n > 0 {
+					minEligible = n
+				}
+			}
+			eligibleByNetwork := make(map[string]int)
+			var lackingNetworks []string
+			for _, ups := range filteredUpstreams {
+				networkId := ups.NetworkId()
+				if _, ok := eligibleByNetwork[networkId]; ok || networkId == "" {
+					continue
+				}
+				eligible := metricsTracker.EligibleUpstreams(networkId)
+				eligibleByNetwork[networkId] = eligible
+				if eligible < minEligible {
+					lackingNetworks = append(lackingNetworks, networkId)
+				}
+			}
+			projectDetails["eligibleUpstreams"] = eligibleByNetwork
+			if len(eligibleByNetwork) == 0 {
+				projectHealthy = false
+				projectDetails["status"] = "ERROR"
+				projectDetails["message"] = "no upstreams initialized"
+			} else if len(lackingNetworks) > 0 {
+				sort.Strings(lackingNetworks)
+				projectHealthy = false
+				projectDetails["status"] = "ERROR"
+				projectDetails["message"] = fmt.Sprintf("networks with fewer than %d eligible upstreams: %s", minEligible, strings.Join(lackingNetworks, ", "))
+			} else {
+				projectDetails["status"] = "OK"
+				projectDetails["message"] = fmt.Sprintf("%d networks have at least %d eligible upstreams", len(eligibleByNetwork), minEligible)
+			}
+
 		case common.EvalEvmAllChainId,
 			common.EvalEvmAnyChainId:
 			results := checkEvmChainId(ctx, filteredUpstreams, upstreamsDetails, evalStrategy)
@@ -267,6 +308,15 @@ func (s *HttpServer) handleHealthCheck(
 			allHealthy = false
 		}
 		if !s.isSimpleMode() {
+			forksByNetwork := make(map[string]health.ForkSignal)
+			for _, ups := range filteredUpstreams {
+				if networkId := ups.NetworkId(); networkId != "" {
+					if _, ok := forksByNetwork[networkId]; !ok {
+						forksByNetwork[networkId] = metricsTracker.GetForkSignal(networkId, true)
+					}
+				}
+			}
+			projectDetails["forks"] = forksByNetwork
 			projectDetails["upstreams"] = upstreamsDetails
 			projectDetails["initializer"] = project.upstreamsRegistry.GetInitializer().Status()
 		}
@@ -23,6 +23,7 @@ import (
 	"github.com/erpc/erpc/auth"
 	"github.com/erpc/erpc/common"
 	"github.com/erpc/erpc/telemetry"
+	"github.com/erpc/erpc/upstream"
 	"github.com/erpc/erpc/util"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -155,6 +156,11 @@ func (s *HttpServer) createRequestHandler() http.Handler {
 		w.Header().Set("X-ERPC-Version", common.ErpcVersion)
 		w.Header().Set("X-ERPC-Commit", common.ErpcCommitSha)
 
+		if r.Method == http.MethodGet && path.Clean(r.URL.Path) == adminHealthExplainPath {
+			s.handleAdminHealthExplain(httpCtx, w, r, &startedAt, encoder, writeFatalError)
+			return
+		}
+
 		projectId, architecture, chainId, isAdmin, isHealthCheck, err = s.parseUrlPath(r, projectId, architecture, chainId)
 		if err != nil {
 			handleErrorResponse(
@@ -303,6 +309,11 @@ func (s *HttpServer) createRequestHandler() http.Handler {
 		headers := r.Header
 		queryArgs := r.URL.Query()
 
+		// Scores the single request was routed with, only captured when an admin asks through the
+		// debug header
+		var scoreExplanation *upstream.ScoreExplanation
+		explainScores := !isBatch && headers.Get(explainScoresHeader) == "true" && s.canExplainScores(httpCtx, r)
+
 		parseRequestsSpan.End()
 
 		for i, reqBody := range requests {
@@ -367,6 +378,7 @@ func (s *HttpServer) createRequestHandler() http.Handler {
 						common.EndRequestSpan(requestCtx, nil, err)
 						return
 					}
+					nq.SetClientId(ap.ClientId())
 				}
 
 				if isAdmin {
@@ -433,6 +445,10 @@ func (s *HttpServer) createRequestHandler() http.Handler {
 				}
 				nq.SetNetwork(nw)
 
+				if explainScores {
+					scoreExplanation, _ = project.upstreamsRegistry.ExplainScores(networkId, method)
+				}
+
 				resp, err := project.Forward(requestCtx, networkId, nq)
 				if err != nil {
 					responses[index] = processErrorBody(&rlg, &startedAt, nq, err, false)
@@ -497,6 +513,11 @@ func (s *HttpServer) createRequestHandler() http.Handler {
 		} else {
 			res := responses[0]
 			setResponseHeaders(httpCtx, res, w)
+			if scoreExplanation != nil {
+				if exp, err := common.SonicCfg.Marshal(scoreExplanation); err == nil {
+					w.Header().Set(scoreExplanationHeader, string(exp))
+				}
+			}
 			statusCode := determineResponseStatusCode(res)
 			w.WriteHeader(statusCode)
 
@@ -4734,6 +4734,100 @@ func TestHttpServer_HandleHealthCheck(t *testing.T) {
 	}
 }
 
+func TestHttpServer_HandleAdminHealthExplain(t *testing.T) {
+	logger := &log.Logger
+	authReg, err := auth.NewAuthRegistry(logger, "admin", &common.AuthConfig{Strategies: []*common.AuthStrategyConfig{
+		{Type: common.AuthTypeSecret, Secret: &common.SecretStrategyConfig{Value: "test-secret"}},
+	}}, nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		adminCfg   *common.AdminConfig
+		query      string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "Admin disabled",
+			query:      "project=test&network=evm:1&method=eth_call&secret=test-secret",
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   "admin is not enabled",
+		},
+		{
+			name:       "Without secret",
+			adminCfg:   &common.AdminConfig{},
+			query:      "project=test&network=evm:1&method=eth_call",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "Without method",
+			adminCfg:   &common.AdminConfig{},
+			query:      "project=test&network=evm:1&secret=test-secret",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "query parameters are required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &HttpServer{
+				logger:   logger,
+				adminCfg: tt.adminCfg,
+				erpc:     &ERPC{adminAuthRegistry: authReg},
+			}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, adminHealthExplainPath+"?"+tt.query, nil)
+			startTime := time.Now()
+
+			encoder := common.SonicCfg.NewEncoder(w)
+			s.handleAdminHealthExplain(context.Background(), w, r, &startTime, encoder, func(ctx context.Context, statusCode int, body error) {
+				w.WriteHeader(statusCode)
+				encoder.Encode(map[string]string{"error": body.Error()})
+			})
+
+			resp := w.Result()
+			body, _ := io.ReadAll(resp.Body)
+
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			assert.Contains(t, string(body), tt.wantBody)
+		})
+	}
+}
+
+func TestHttpServer_CanExplainScores(t *testing.T) {
+	logger := &log.Logger
+	authReg, err := auth.NewAuthRegistry(logger, "admin", &common.AuthConfig{Strategies: []*common.AuthStrategyConfig{
+		{Type: common.AuthTypeSecret, Secret: &common.SecretStrategyConfig{Value: "test-secret"}},
+	}}, nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		adminCfg *common.AdminConfig
+		query    string
+		want     bool
+	}{
+		{name: "Admin disabled", query: "secret=test-secret"},
+		{name: "Without secret", adminCfg: &common.AdminConfig{}},
+		{name: "Wrong secret", adminCfg: &common.AdminConfig{}, query: "secret=other"},
+		{name: "Admin secret", adminCfg: &common.AdminConfig{}, query: "secret=test-secret", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &HttpServer{
+				logger:   logger,
+				adminCfg: tt.adminCfg,
+				erpc:     &ERPC{adminAuthRegistry: authReg},
+			}
+			r := httptest.NewRequest(http.MethodPost, "/test/evm/1?"+tt.query, nil)
+			r.Header.Set(explainScoresHeader, "true")
+			assert.Equal(t, tt.want, s.canExplainScores(context.Background(), r))
+		})
+	}
+}
+
 func TestHttpServer_ProviderBasedUpstreams(t *testing.T) {
 	t.Run("SimpleCallExistingNetwork", func(t *testing.T) {
 		cfg := &common.Config{
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand"
 	"runtime/debug"
 	"sync"
 	"time"
@@ -21,6 +22,15 @@ import (
 	"go.opentelemetry.io/otel/trace"
 )
 
+const (
+	// shadowRequestTimeout bounds the requests mirrored to shadow upstreams of networks without a
+	// timeout.
+	shadowRequestTimeout = 30 * time.Second
+	// maxShadowRequestsInFlight bounds the requests mirrored to shadow upstreams of a network at
+	// once, mirroring is skipped beyond it.
+	maxShadowRequestsInFlight = 64
+)
+
 type Network struct {
 	networkId                string
 	projectId                string
@@ -33,10 +43,12 @@ type Network struct {
 	failsafeExecutor         failsafe.Executor[*common.NormalizedResponse]
 	rateLimitersRegistry     *upstream.RateLimitersRegistry
 	cacheDal                 common.CacheDAL
-	metricsTracker           *health.Tracker
+	metricsTracker           health.MetricsTracker
 	upstreamsRegistry        *upstream.UpstreamsRegistry
 	selectionPolicyEvaluator *PolicyEvaluator
 	initializer              *util.Initializer
+	// shadowSlots are the permits of the requests mirrored to shadow upstreams, see mirrorToShadows
+	shadowSlots chan struct{}
 }
 
 func (n *Network) Bootstrap(ctx context.Context) error {
@@ -184,7 +196,11 @@ func (n *Network) Forward(ctx context.Context, req *common.NormalizedRequest) (*
 	}
 
 	_, upstreamSpan := common.StartDetailSpan(ctx, "GetSortedUpstreams")
-	upsList, err := n.upstreamsRegistry.GetSortedUpstreams(ctx, n.networkId, method)
+	var block health.RequestBlock
+	if n.Architecture() == common.ArchitectureEvm {
+		block = evm.ExtractRequestBlock(ctx, req)
+	}
+	upsList, err := n.upstreamsRegistry.GetSortedUpstreamsForBlock(ctx, n.networkId, method, block)
 	upstreamSpan.SetAttributes(attribute.Int("upstreams.count", len(upsList)))
 	upstreamSpan.End()
 
@@ -212,7 +228,11 @@ func (n *Network) Forward(ctx context.Context, req *common.NormalizedRequest) (*
 		return nil, err
 	}
 
+	n.mirrorToShadows(ctx, &lg, req)
+
 	// 4) Iterate over upstreams and forward the request until success or fatal failure
+	clock := n.metricsTracker.Clock()
+	chain := newAttemptChain(len(upsList), n.metricsTracker.RecordRequestOutcome)
 	tryForward := func(
 		u *upstream.Upstream,
 		execSpanCtx context.Context,
@@ -328,8 +348,21 @@ func (n *Network) Forward(ctx context.Context, req *common.NormalizedRequest) (*
 				// We need to use write-lock here because "i" is being updated.
 				req.LockWithTrace(loopCtx)
 				u := upsList[i]
-				loopSpan.SetAttributes(attribute.String("upstream.id", u.Config().Id))
-				ulg := lg.With().Str("upstreamId", u.Config().Id).Logger()
+				errRate, p90, lag, cordoned := n.metricsTracker.HealthSummary(u.Config().Id, n.networkId)
+				loopSpan.SetAttributes(
+					attribute.String("upstream.id", u.Config().Id),
+					attribute.Float64("upstream.error_rate", errRate),
+					attribute.Int64("upstream.p90_ms", p90.Milliseconds()),
+					attribute.Int64("upstream.block_head_lag", lag),
+					attribute.Bool("upstream.cordoned", cordoned),
+				)
+				ulg := lg.With().
+					Str("upstreamId", u.Config().Id).
+					Float64("upstreamErrorRate", errRate).
+					Dur("upstreamP90", p90).
+					Int64("upstreamBlockHeadLag", lag).
+					Bool("upstreamCordoned", cordoned).
+					Logger()
 				ulg.Trace().Int("index", i).Int("upstreams", ln).Msgf("attempt to forward request to next upstream")
 				i++
 				if i >= ln {
@@ -361,6 +394,7 @@ func (n *Network) Forward(ctx context.Context, req *common.NormalizedRequest) (*
 					}
 				}
 				req.Unlock()
+				n.metricsTracker.RecordSelection(u.Config().Id, n.networkId, method)
 				hedges := exec.Hedges()
 				attempts := exec.Attempts()
 				if hedges > 0 {
@@ -368,7 +402,23 @@ func (n *Network) Forward(ctx context.Context, req *common.NormalizedRequest) (*
 				}
 
 				var r *common.NormalizedResponse
+				attemptStart := clock.Now()
+				chain.begin()
 				r, err = tryForward(u, loopCtx, &ulg, hedges, attempts, exec.Retries())
+				attempt := health.AttemptResult{
+					Upstream: u.Config().Id,
+					Network:  n.networkId,
+					Method:   method,
+					Duration: clock.Now().Sub(attemptStart),
+					Success:  err == nil || common.IsNull(err),
+					Attempt:  attempts,
+					Hedge:    hedges > 0,
+				}
+				if common.HasErrorCode(err, common.ErrCodeEndpointRequestCanceled) {
+					attempt.Cancelled = true
+					attempt.CancelCause = n.attemptCancelCause(ctx, loopCtx, startTime)
+				}
+				chain.end(attempt)
 
 				if e := n.normalizeResponse(loopCtx, req, r); e != nil {
 					ulg.Error().Err(e).Msgf("failed to normalize response")
@@ -429,6 +479,9 @@ func (n *Network) Forward(ctx context.Context, req *common.NormalizedRequest) (*
 			return nil, err
 		})
 
+	// The hedge policy returns with the winner, the attempts it cancelled are recorded once settled
+	chain.close(execErr == nil)
+
 	req.RLockWithTrace(ctx)
 	defer req.RUnlock()
 
@@ -532,6 +585,62 @@ func (n *Network) doForward(execSpanCtx context.Context, u *upstream.Upstream, r
 	return evm.HandleUpstreamPostForward(execSpanCtx, n, u, req, resp, err, skipCacheRead)
 }
 
+// mirrorToShadows sends in the background a copy of req to a sample of the shadow upstreams of
+// the network (see upstream.UpstreamsRegistry.SetShadowUpstream), so that their health gets
+// tracked on real traffic. Their responses are discarded. Only reads are mirrored, so that shadows
+// never send transactions twice, and at most maxShadowRequestsInFlight at once.
+func (n *Network) mirrorToShadows(ctx context.Context, lg *zerolog.Logger, req *common.NormalizedRequest) {
+	if n.shadowSlots == nil {
+		return
+	}
+	method, err := req.Method()
+	if err != nil || n.metricsTracker.MethodClassOf(method) != health.MethodClassRead {
+		return
+	}
+	var shadows []*upstream.Upstream
+	for _, ups := range n.upstreamsRegistry.GetNetworkUpstreams(ctx, n.networkId) {
+		if rate := n.upstreamsRegistry.ShadowSampleRate(ups.Config().Id); rate > 0 && rand.Float64() < rate {
+			shadows = append(shadows, ups)
+		}
+	}
+	if len(shadows) == 0 {
+		return
+	}
+	jrq, err := req.JsonRpcRequest()
+	if err != nil {
+		return
+	}
+
+	timeout := shadowRequestTimeout
+	if n.timeoutDuration != nil {
+		timeout = *n.timeoutDuration
+	}
+	for _, ups := range shadows {
+		jrq.RLock()
+		shadowJrq := common.NewJsonRpcRequest(jrq.Method, append([]interface{}(nil), jrq.Params...))
+		shadowJrq.ID = jrq.ID
+		jrq.RUnlock()
+		shadowReq := common.NewNormalizedRequestFromJsonRpcRequest(shadowJrq)
+		shadowReq.SetNetwork(n)
+		shadowReq.SetCompositeType(common.CompositeTypeShadow)
+
+		select {
+		case n.shadowSlots <- struct{}{}:
+		default:
+			lg.Debug().Str("upstreamId", ups.Config().Id).Msgf("too many requests mirrored to shadow upstreams, skipping")
+			continue
+		}
+		go func(ups *upstream.Upstream) {
+			defer func() { <-n.shadowSlots }()
+			shadowCtx, cancel := context.WithTimeout(n.appCtx, timeout)
+			defer cancel()
+			if _, err := ups.Forward(shadowCtx, shadowReq, false); err != nil {
+				lg.Debug().Err(err).Str("upstreamId", ups.Config().Id).Msgf("shadow upstream failed mirrored request")
+			}
+		}(ups)
+	}
+}
+
 func (n *Network) acquireSelectionPolicyPermit(ctx context.Context, lg *zerolog.Logger, ups *upstream.Upstream, req *common.NormalizedRequest) error {
 	if n.cfg.SelectionPolicy == nil {
 		return nil
@@ -627,6 +736,80 @@ func (n *Network) cleanupMultiplexer(mlx *Multiplexer) {
 	n.inFlightRequests.Delete(mlx.hash)
 }
 
+// attemptChain collects the upstream attempts of a network-level request and records them once
+// the execution returned and every attempt settled. Hedged attempts complete concurrently, and the
+// hedge policy returns with the winner without waiting for the attempts it cancelled.
+type attemptChain struct {
+	mu       sync.Mutex
+	attempts []health.AttemptResult
+	inFlight int
+	returned bool
+	served   bool
+	record   func([]health.AttemptResult)
+}
+
+func newAttemptChain(size int, record func([]health.AttemptResult)) *attemptChain {
+	return &attemptChain{attempts: make([]health.AttemptResult, 0, size), record: record}
+}
+
+// begin must be called before an attempt is forwarded, and end once it settled.
+func (c *attemptChain) begin() {
+	c.mu.Lock()
+	c.inFlight++
+	c.mu.Unlock()
+}
+
+func (c *attemptChain) end(a health.AttemptResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if a.Cancelled && a.CancelCause != "" && c.served {
+		// The request was served by another attempt, whatever cancelled the context since, e.g.
+		// the client going away once it got the response
+		a.CancelCause = health.CancelCauseHedge
+	}
+	c.attempts = append(c.attempts, a)
+	c.inFlight--
+	c.recordIfSettled()
+}
+
+// close is called once the execution returned, served telling if it returned a response.
+func (c *attemptChain) close(served bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.returned = true
+	c.served = served
+	c.recordIfSettled()
+}
+
+// recordIfSettled records the chain once, when complete. Attempts ending afterwards, i.e. begun
+// by the hedge policy while the execution was returning, are left out.
+func (c *attemptChain) recordIfSettled() {
+	if !c.returned || c.inFlight > 0 || c.record == nil {
+		return
+	}
+	c.record(c.attempts)
+	c.record = nil
+}
+
+// attemptCancelCause tells why an attempt towards an upstream was cancelled while in flight. It is
+// empty when the upstream already attributed the cancellation, i.e. the deadline of the attempt
+// itself fired while the upstream was waiting for the response.
+func (n *Network) attemptCancelCause(ctx, attemptCtx context.Context, startTime time.Time) health.CancelCause {
+	if attemptCtx.Err() == nil || health.CancelCauseOf(attemptCtx) == health.CancelCauseDeadline {
+		return ""
+	}
+	if ctx.Err() != nil {
+		return health.CancelCauseOf(ctx)
+	}
+	if n.timeoutDuration != nil && time.Since(startTime) >= *n.timeoutDuration {
+		// The execution was cancelled by the network timeout policy
+		return health.CancelCauseDeadline
+	}
+	// The execution was cancelled while the request was still live, i.e. another attempt of the
+	// same request won the race, whether this attempt is the hedge or the one being hedged
+	return health.CancelCauseHedge
+}
+
 func (n *Network) shouldHandleMethod(method string, upsList []*upstream.Upstream) error {
 	// TODO Move the logic to evm package?
 	if method == "eth_newFilter" ||
@@ -22,7 +22,7 @@ type NetworksRegistry struct {
 	project              *PreparedProject
 	appCtx               context.Context
 	upstreamsRegistry    *upstream.UpstreamsRegistry
-	metricsTracker       *health.Tracker
+	metricsTracker       health.MetricsTracker
 	evmJsonRpcCache      *evm.EvmJsonRpcCache
 	rateLimitersRegistry *upstream.RateLimitersRegistry
 	preparedNetworks     sync.Map // map[string]*Network
@@ -40,7 +40,7 @@ func NewNetworksRegistry(
 	project *PreparedProject,
 	appCtx context.Context,
 	upstreamsRegistry *upstream.UpstreamsRegistry,
-	metricsTracker *health.Tracker,
+	metricsTracker health.MetricsTracker,
 	evmJsonRpcCache *evm.EvmJsonRpcCache,
 	rateLimitersRegistry *upstream.RateLimitersRegistry,
 	logger *zerolog.Logger,
@@ -68,12 +68,12 @@ func NewNetwork(
 	nwCfg *common.NetworkConfig,
 	rateLimitersRegistry *upstream.RateLimitersRegistry,
 	upstreamsRegistry *upstream.UpstreamsRegistry,
-	metricsTracker *health.Tracker,
+	metricsTracker health.MetricsTracker,
 ) (*Network, error) {
 	lg := logger.With().Str("component", "proxy").Str("networkId", nwCfg.NetworkId()).Logger()
 
 	key := fmt.Sprintf("%s/%s", projectId, nwCfg.NetworkId())
-	pls, err := upstream.CreateFailSafePolicies(&lg, common.ScopeNetwork, key, nwCfg.Failsafe)
+	pls, err := upstream.CreateFailSafePolicies(&lg, common.ScopeNetwork, key, nwCfg.Failsafe, metricsTracker)
 	if err != nil {
 		return nil, err
 	}
@@ -100,11 +100,56 @@ func NewNetwork(
 		timeoutDuration:  timeoutDuration,
 		failsafeExecutor: failsafe.NewExecutor(policyArray...),
 		initializer:      util.NewInitializer(appCtx, &lg, nil),
+		shadowSlots:      make(chan struct{}, maxShadowRequestsInFlight),
 	}
 
 	if nwCfg.Architecture == "" {
 		nwCfg.Architecture = common.ArchitectureEvm
 	}
+	if nwCfg.Evm != nil && nwCfg.Evm.ChainId != 0 {
+		// Upstreams reporting another chain id than the network's one are cordoned
+		metricsTracker.SetExpectedChainId(network.networkId, nwCfg.Evm.ChainId)
+	}
+	if nwCfg.Evm != nil {
+		// Finality of upstreams not reporting finalized blocks is approximated from their latest block
+		metricsTracker.SetFinalityDepth(network.networkId, nwCfg.Evm.FallbackFinalityDepth)
+	}
+	if nwCfg.SelectionPolicy != nil && nwCfg.SelectionPolicy.HasCustomEvalFunction() {
+		// The thresholds of a custom policy are unknown, the upstreams it excludes are cordoned
+		metricsTracker.SetEligibilityFromCordons(network.networkId)
+	}
+	if c := nwCfg.LatencyCostClasses; c != nil {
+		classes := &health.LatencyCostClasses{
+			Methods:         make(map[string]health.MethodCostClass, len(c.Methods)),
+			Baselines:       make(map[health.MethodCostClass]time.Duration, len(c.Baselines)),
+			ScoreNormalized: c.ScoreNormalized,
+		}
+		for method, class := range c.Methods {
+			classes.Methods[method] = health.MethodCostClass(class)
+		}
+		for class, baseline := range c.Baselines {
+			classes.Baselines[health.MethodCostClass(class)] = baseline.Duration()
+		}
+		if err := metricsTracker.SetLatencyCostClasses(network.networkId, classes); err != nil {
+			return nil, err
+		}
+	}
+	if mi := nwCfg.MethodImportance; mi != nil {
+		metricsTracker.SetMethodImportance(network.networkId, &health.MethodImportance{
+			Weights:    mi.Weights,
+			MinSamples: mi.MinSamples,
+		})
+	}
+	for method, c := range nwCfg.ErrorRateCordons {
+		if err := metricsTracker.SetErrorRateCordon(network.networkId, method, &health.ErrorRateCordonConfig{
+			MaxErrorRate:       c.MaxErrorRate,
+			MinSamples:         c.MinSamples,
+			CleanWindows:       c.CleanWindows,
+			ProbationSuccesses: c.ProbationSuccesses,
+		}); err != nil {
+			return nil, err
+		}
+	}
 
 	return network, nil
 }
@@ -4503,6 +4503,508 @@ func TestNetwork_Forward(t *testing.T) {
 		}
 	})
 
+	t.Run("ForwardHedgedAttemptCancelledAfterServed", func(t *testing.T) {
+		util.ResetGock()
+		defer util.ResetGock()
+		util.SetupMocksForEvmStatePoller()
+		defer util.AssertNoPendingMocks(t, 0)
+
+		var requestBytes = []byte(`{"jsonrpc":"2.0","id":1,"method":"eth_traceTransaction","params":["0x1273c18",false]}`)
+
+		gock.New("http://rpc1.localhost").
+			Post("").
+			Reply(200).
+			JSON([]byte(`{"result":{"hash":"0x64d340d2470d2ed0ec979b72d79af9cd09fc4eb2b89ae98728d5fb07fd89baf9","fromHost":"rpc1"}}`)).
+			Delay(500 * time.Millisecond)
+
+		gock.New("http://rpc2.localhost").
+			Post("").
+			Reply(200).
+			JSON([]byte(`{"result":{"hash":"0x64d340d2470d2ed0ec979b72d79af9cd09fc4eb2b89ae98728d5fb07fd89baf9","fromHost":"rpc2"}}`)).
+			Delay(200 * time.Millisecond)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		vr := thirdparty.NewVendorsRegistry()
+		pr, err := thirdparty.NewProvidersRegistry(
+			&log.Logger,
+			vr,
+			[]*common.ProviderConfig{},
+			nil,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		clr := clients.NewClientRegistry(&log.Logger, "prjA", nil)
+		fsCfg := &common.FailsafeConfig{
+			Hedge: &common.HedgePolicyConfig{
+				Delay:    common.Duration(200 * time.Millisecond),
+				MaxCount: 1,
+			},
+		}
+		rlr, err := upstream.NewRateLimitersRegistry(&common.RateLimiterConfig{
+			Budgets: []*common.RateLimitBudgetConfig{},
+		}, &log.Logger)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mt := health.NewTracker(&log.Logger, "prjA", 2*time.Second)
+		up1 := &common.UpstreamConfig{
+			Type:     common.UpstreamTypeEvm,
+			Id:       "rpc1",
+			Endpoint: "http://rpc1.localhost",
+			Evm: &common.EvmUpstreamConfig{
+				ChainId: 123,
+			},
+		}
+		up2 := &common.UpstreamConfig{
+			Type:     common.UpstreamTypeEvm,
+			Id:       "rpc2",
+			Endpoint: "http://rpc2.localhost",
+			Evm: &common.EvmUpstreamConfig{
+				ChainId: 123,
+			},
+		}
+		ssr, err := data.NewSharedStateRegistry(ctx, &log.Logger, &common.SharedStateConfig{
+			Connector: &common.ConnectorConfig{
+				Driver: "memory",
+				Memory: &common.MemoryConnectorConfig{
+					MaxItems: 100_000,
+				},
+			},
+		})
+		if err != nil {
+			panic(err)
+		}
+		upr := upstream.NewUpstreamsRegistry(
+			ctx,
+			&log.Logger,
+			"prjA",
+			[]*common.UpstreamConfig{up1, up2},
+			ssr,
+			rlr,
+			vr,
+			pr,
+			nil,
+			mt,
+			1*time.Second,
+		)
+		err = upr.Bootstrap(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = upr.PrepareUpstreamsForNetwork(ctx, util.EvmNetworkId(123))
+		if err != nil {
+			t.Fatal(err)
+		}
+		pup1, err := upr.NewUpstream(up1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cl1, err := clr.GetOrCreateClient(ctx, pup1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pup1.Client = cl1
+
+		pup2, err := upr.NewUpstream(up2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cl2, err := clr.GetOrCreateClient(ctx, pup2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pup2.Client = cl2
+
+		ntw, err := NewNetwork(
+			ctx,
+			&log.Logger,
+			"prjA",
+			&common.NetworkConfig{
+				Architecture: common.ArchitectureEvm,
+				Evm: &common.EvmNetworkConfig{
+					ChainId: 123,
+				},
+				Failsafe: fsCfg,
+			},
+			rlr,
+			upr,
+			mt,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		upstream.ReorderUpstreams(upr)
+
+		reqCtx, reqCancel := context.WithCancel(ctx)
+		fakeReq := common.NewNormalizedRequest(requestBytes)
+		resp, err := ntw.Forward(reqCtx, fakeReq)
+		// The client goes away once served, while the attempt towards rpc1 is still in flight
+		reqCancel()
+
+		if err != nil {
+			t.Fatalf("Expected nil error, got %v", err)
+		}
+
+		jrr, err := resp.JsonRpcResponse()
+		if err != nil {
+			t.Fatalf("Expected nil error, got %v", err)
+		}
+
+		if jrr.Result == nil {
+			t.Fatalf("Expected result, got nil")
+		}
+
+		fromHost, err := jrr.PeekStringByPath(context.TODO(), "fromHost")
+		if err != nil || fromHost != "rpc2" {
+			t.Errorf("Expected fromHost to be %v, got %v", "rpc2", fromHost)
+		}
+
+		assert.Eventually(t, func() bool {
+			return mt.GetUpstreamMethodMetrics("rpc1", util.EvmNetworkId(123), "eth_traceTransaction").CancelledByHedgeTotal.Load() == 1
+		}, 2*time.Second, 10*time.Millisecond)
+		assert.Equal(t, int64(0), mt.GetUpstreamMethodMetrics("rpc1", util.EvmNetworkId(123), "eth_traceTransaction").CancelledByClientTotal.Load())
+	})
+
+	t.Run("ForwardHedgeLoserCounted", func(t *testing.T) {
+		util.ResetGock()
+		defer util.ResetGock()
+		util.SetupMocksForEvmStatePoller()
+		defer util.AssertNoPendingMocks(t, 0)
+
+		var requestBytes = []byte(`{"jsonrpc":"2.0","id":1,"method":"eth_traceTransaction","params":["0x1273c18",false]}`)
+
+		gock.New("http://rpc1.localhost").
+			Post("").
+			Reply(200).
+			JSON([]byte(`{"result":{"hash":"0x64d340d2470d2ed0ec979b72d79af9cd09fc4eb2b89ae98728d5fb07fd89baf9","fromHost":"rpc1"}}`)).
+			Delay(300 * time.Millisecond)
+
+		gock.New("http://rpc2.localhost").
+			Post("").
+			Reply(200).
+			JSON([]byte(`{"result":{"hash":"0x64d340d2470d2ed0ec979b72d79af9cd09fc4eb2b89ae98728d5fb07fd89baf9","fromHost":"rpc2"}}`)).
+			Delay(1 * time.Second)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		vr := thirdparty.NewVendorsRegistry()
+		pr, err := thirdparty.NewProvidersRegistry(
+			&log.Logger,
+			vr,
+			[]*common.ProviderConfig{},
+			nil,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		clr := clients.NewClientRegistry(&log.Logger, "prjA", nil)
+		fsCfg := &common.FailsafeConfig{
+			Hedge: &common.HedgePolicyConfig{
+				Delay:    common.Duration(100 * time.Millisecond),
+				MaxCount: 1,
+			},
+		}
+		rlr, err := upstream.NewRateLimitersRegistry(&common.RateLimiterConfig{
+			Budgets: []*common.RateLimitBudgetConfig{},
+		}, &log.Logger)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mt := health.NewTracker(&log.Logger, "prjA", 2*time.Second)
+		up1 := &common.UpstreamConfig{
+			Type:     common.UpstreamTypeEvm,
+			Id:       "rpc1",
+			Endpoint: "http://rpc1.localhost",
+			Evm: &common.EvmUpstreamConfig{
+				ChainId: 123,
+			},
+		}
+		up2 := &common.UpstreamConfig{
+			Type:     common.UpstreamTypeEvm,
+			Id:       "rpc2",
+			Endpoint: "http://rpc2.localhost",
+			Evm: &common.EvmUpstreamConfig{
+				ChainId: 123,
+			},
+		}
+		ssr, err := data.NewSharedStateRegistry(ctx, &log.Logger, &common.SharedStateConfig{
+			Connector: &common.ConnectorConfig{
+				Driver: "memory",
+				Memory: &common.MemoryConnectorConfig{
+					MaxItems: 100_000,
+				},
+			},
+		})
+		if err != nil {
+			panic(err)
+		}
+		upr := upstream.NewUpstreamsRegistry(
+			ctx,
+			&log.Logger,
+			"prjA",
+			[]*common.UpstreamConfig{up1, up2},
+			ssr,
+			rlr,
+			vr,
+			pr,
+			nil,
+			mt,
+			1*time.Second,
+		)
+		err = upr.Bootstrap(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = upr.PrepareUpstreamsForNetwork(ctx, util.EvmNetworkId(123))
+		if err != nil {
+			t.Fatal(err)
+		}
+		pup1, err := upr.NewUpstream(up1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cl1, err := clr.GetOrCreateClient(ctx, pup1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pup1.Client = cl1
+
+		pup2, err := upr.NewUpstream(up2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cl2, err := clr.GetOrCreateClient(ctx, pup2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pup2.Client = cl2
+
+		ntw, err := NewNetwork(
+			ctx,
+			&log.Logger,
+			"prjA",
+			&common.NetworkConfig{
+				Architecture: common.ArchitectureEvm,
+				Evm: &common.EvmNetworkConfig{
+					ChainId: 123,
+				},
+				Failsafe: fsCfg,
+			},
+			rlr,
+			upr,
+			mt,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		upstream.ReorderUpstreams(upr)
+
+		fakeReq := common.NewNormalizedRequest(requestBytes)
+		resp, err := ntw.Forward(ctx, fakeReq)
+
+		if err != nil {
+			t.Fatalf("Expected nil error, got %v", err)
+		}
+
+		jrr, err := resp.JsonRpcResponse()
+		if err != nil {
+			t.Fatalf("Expected nil error, got %v", err)
+		}
+
+		if jrr.Result == nil {
+			t.Fatalf("Expected result, got nil")
+		}
+
+		fromHost, err := jrr.PeekStringByPath(context.TODO(), "fromHost")
+		if err != nil || fromHost != "rpc1" {
+			t.Errorf("Expected fromHost to be %v, got %v", "rpc1", fromHost)
+		}
+
+		// The hedge towards rpc2 lost and is only counted once its attempt settled
+		assert.Eventually(t, func() bool {
+			return mt.GetUpstreamMethodMetrics("rpc2", util.EvmNetworkId(123), "eth_traceTransaction").HedgesCancelledTotal.Load() == 1
+		}, 2*time.Second, 10*time.Millisecond)
+		hedged := mt.GetUpstreamMethodMetrics("rpc2", util.EvmNetworkId(123), "eth_traceTransaction")
+		assert.Equal(t, int64(1), hedged.HedgesLaunchedTotal.Load())
+		assert.Equal(t, int64(0), hedged.HedgesWonTotal.Load())
+		assert.Equal(t, int64(0), mt.GetUpstreamMethodMetrics("rpc1", util.EvmNetworkId(123), "eth_traceTransaction").HedgesLaunchedTotal.Load())
+	})
+
+	t.Run("ForwardMirrorsToShadowUpstream", func(t *testing.T) {
+		util.ResetGock()
+		defer util.ResetGock()
+		util.SetupMocksForEvmStatePoller()
+		defer util.AssertNoPendingMocks(t, 0)
+
+		var requestBytes = []byte(`{"jsonrpc":"2.0","id":1,"method":"eth_traceTransaction","params":["0x1273c18",false]}`)
+
+		gock.New("http://rpc1.localhost").
+			Times(3).
+			Post("").
+			Reply(200).
+			JSON([]byte(`{"result":{"fromHost":"rpc1"}}`))
+		gock.New("http://rpc2.localhost").
+			Times(3).
+			Post("").
+			Reply(200).
+			JSON([]byte(`{"result":{"fromHost":"rpc2"}}`))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		vr := thirdparty.NewVendorsRegistry()
+		pr, err := thirdparty.NewProvidersRegistry(
+			&log.Logger,
+			vr,
+			[]*common.ProviderConfig{},
+			nil,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		clr := clients.NewClientRegistry(&log.Logger, "prjA", nil)
+		rlr, err := upstream.NewRateLimitersRegistry(&common.RateLimiterConfig{
+			Budgets: []*common.RateLimitBudgetConfig{
+				{
+					Id: "ShadowBudget",
+					Rules: []*common.RateLimitRuleConfig{
+						{
+							Method:   "*",
+							MaxCount: 1,
+							Period:   common.Duration(60 * time.Second),
+							WaitTime: common.Duration(0),
+						},
+					},
+				},
+			},
+		}, &log.Logger)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mt := health.NewTracker(&log.Logger, "prjA", 2*time.Second)
+		up1 := &common.UpstreamConfig{
+			Type:     common.UpstreamTypeEvm,
+			Id:       "rpc1",
+			Endpoint: "http://rpc1.localhost",
+			Evm: &common.EvmUpstreamConfig{
+				ChainId: 123,
+			},
+		}
+		up2 := &common.UpstreamConfig{
+			Type:            common.UpstreamTypeEvm,
+			Id:              "rpc2",
+			Endpoint:        "http://rpc2.localhost",
+			RateLimitBudget: "ShadowBudget",
+			Evm: &common.EvmUpstreamConfig{
+				ChainId: 123,
+			},
+			Shadow: &common.ShadowConfig{
+				SampleRate: 1,
+			},
+		}
+		ssr, err := data.NewSharedStateRegistry(ctx, &log.Logger, &common.SharedStateConfig{
+			Connector: &common.ConnectorConfig{
+				Driver: "memory",
+				Memory: &common.MemoryConnectorConfig{
+					MaxItems: 100_000,
+				},
+			},
+		})
+		if err != nil {
+			panic(err)
+		}
+		upr := upstream.NewUpstreamsRegistry(
+			ctx,
+			&log.Logger,
+			"prjA",
+			[]*common.UpstreamConfig{up1, up2},
+			ssr,
+			rlr,
+			vr,
+			pr,
+			nil,
+			mt,
+			1*time.Second,
+		)
+		err = upr.Bootstrap(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = upr.PrepareUpstreamsForNetwork(ctx, util.EvmNetworkId(123))
+		if err != nil {
+			t.Fatal(err)
+		}
+		pup1, err := upr.NewUpstream(up1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cl1, err := clr.GetOrCreateClient(ctx, pup1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pup1.Client = cl1
+
+		pup2, err := upr.NewUpstream(up2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cl2, err := clr.GetOrCreateClient(ctx, pup2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pup2.Client = cl2
+
+		ntw, err := NewNetwork(
+			ctx,
+			&log.Logger,
+			"prjA",
+			&common.NetworkConfig{
+				Architecture: common.ArchitectureEvm,
+				Evm: &common.EvmNetworkConfig{
+					ChainId: 123,
+				},
+			},
+			rlr,
+			upr,
+			mt,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		upstream.ReorderUpstreams(upr)
+		assert.Equal(t, 1.0, upr.ShadowSampleRate("rpc2"))
+
+		for i := 0; i < 3; i++ {
+			resp, err := ntw.Forward(ctx, common.NewNormalizedRequest(requestBytes))
+			if err != nil {
+				t.Fatalf("Expected nil error, got %v", err)
+			}
+			jrr, err := resp.JsonRpcResponse()
+			if err != nil {
+				t.Fatalf("Expected nil error, got %v", err)
+			}
+			fromHost, err := jrr.PeekStringByPath(context.TODO(), "fromHost")
+			if err != nil || fromHost != "rpc1" {
+				t.Errorf("Expected fromHost to be %v, got %v", "rpc1", fromHost)
+			}
+		}
+
+		// Every mirrored request reaches the shadow, whose rate limit budget is left untouched
+		shadow := mt.GetUpstreamMethodMetrics("rpc2", util.EvmNetworkId(123), "eth_traceTransaction")
+		assert.Eventually(t, func() bool {
+			return shadow.RequestsTotal.Load() == 3
+		}, 2*time.Second, 10*time.Millisecond)
+		assert.Equal(t, int64(0), shadow.SelfRateLimitedTotal.Load())
+		assert.NotNil(t, shadow.GetCompositeTypeQuantiles(common.CompositeTypeShadow))
+	})
+
 	t.Run("ForwardHedgePolicyNotTriggered", func(t *testing.T) {
 		util.ResetGock()
 		defer util.ResetGock()
@@ -8275,6 +8777,10 @@ func TestNetwork_EvmGetLogs(t *testing.T) {
 		}
 		assert.Contains(t, blockNumbers, "0x18101")
 		assert.Contains(t, blockNumbers, "0x18202")
+
+		// The upstream refusing the complete logs counts as a partial response
+		m := network.metricsTracker.GetUpstreamMethodMetrics(upsList[0].Config().Id, util.EvmNetworkId(123), "eth_getLogs")
+		assert.Positive(t, m.PartialResponsesTotal.Load())
 	})
 
 	t.Run("SplitCorrectlyWhenMaxRangeIsOne", func(t *testing.T) {
@@ -8981,3 +9487,82 @@ func setupTestNetworkWithFullAndArchiveNodeUpstreams(t *testing.T, ctx context.C
 
 	return network
 }
+
+func TestNetwork_AttemptCancelCause(t *testing.T) {
+	timeout := 100 * time.Millisecond
+	n := &Network{timeoutDuration: &timeout}
+	live := context.Background()
+	cancelled, cancel := context.WithCancel(live)
+	cancel()
+	expired, cancelExpired := context.WithDeadline(live, time.Now().Add(-time.Second))
+	defer cancelExpired()
+
+	// The upstream attributes its own deadline and attempts not cancelled by the network
+	assert.Empty(t, n.attemptCancelCause(live, expired, time.Now()))
+	assert.Empty(t, n.attemptCancelCause(live, live, time.Now()))
+
+	assert.Equal(t, health.CancelCauseClient, n.attemptCancelCause(cancelled, cancelled, time.Now()))
+	assert.Equal(t, health.CancelCauseDeadline, n.attemptCancelCause(live, cancelled, time.Now().Add(-timeout)))
+	assert.Equal(t, health.CancelCauseHedge, n.attemptCancelCause(live, cancelled, time.Now()))
+}
+
+func TestNetwork_MirrorToShadowsSkipsWrites(t *testing.T) {
+	// Without a registry, reaching the shadows of the network would panic
+	n := &Network{
+		metricsTracker: health.NewTracker(&log.Logger, "test-mirror-writes", time.Minute),
+		shadowSlots:    make(chan struct{}, 1),
+	}
+	req := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","id":1,"method":"eth_sendRawTransaction","params":["0x00"]}`))
+	assert.NotPanics(t, func() { n.mirrorToShadows(context.Background(), &log.Logger, req) })
+	assert.Empty(t, n.shadowSlots)
+}
+
+func TestNetwork_AppliesTrackerConfig(t *testing.T) {
+	tracker := health.NewTracker(&log.Logger, "prjA", time.Minute)
+	_, err := NewNetwork(
+		context.Background(),
+		&log.Logger,
+		"prjA",
+		&common.NetworkConfig{
+			Architecture: common.ArchitectureEvm,
+			Evm:          &common.EvmNetworkConfig{ChainId: 123},
+			LatencyCostClasses: &common.LatencyCostClassesConfig{
+				Methods:         map[string]string{"eth_getLogs": "heavy"},
+				Baselines:       map[string]common.Duration{"heavy": common.Duration(time.Second)},
+				ScoreNormalized: true,
+			},
+		},
+		nil,
+		nil,
+		tracker,
+	)
+	assert.NoError(t, err)
+	assert.True(t, tracker.ScoresNormalizedLatency("evm:123"))
+
+	// Upstreams of another chain are cordoned
+	tracker.RecordUpstreamChainId("rpc1", "evm:123", 1)
+	assert.True(t, tracker.IsCordoned("rpc1", "evm:123", "*"))
+}
+
+func TestNetwork_AttemptChain(t *testing.T) {
+	var recorded [][]health.AttemptResult
+	chain := newAttemptChain(2, func(c []health.AttemptResult) {
+		recorded = append(recorded, c)
+	})
+
+	chain.begin()
+	chain.begin()
+	chain.end(health.AttemptResult{Upstream: "rpc2", Attempt: 2, Hedge: true, Success: true})
+	// The execution returns with the winner while the other attempt is still being cancelled
+	chain.close(true)
+	assert.Empty(t, recorded)
+
+	// The client went away once served, before the other attempt settled
+	chain.end(health.AttemptResult{Upstream: "rpc1", Attempt: 1, Cancelled: true, CancelCause: health.CancelCauseClient})
+	if assert.Len(t, recorded, 1) && assert.Len(t, recorded[0], 2) {
+		assert.Equal(t, health.CancelCauseHedge, recorded[0][1].CancelCause)
+	}
+
+	chain.close(true)
+	assert.Len(t, recorded, 1, "recorded once")
+}
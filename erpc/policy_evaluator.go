@@ -23,7 +23,7 @@ type PolicyEvaluator struct {
 	config            *common.SelectionPolicyConfig
 	runtime           *common.Runtime
 	upstreamsMu       sync.RWMutex
-	metricsTracker    *health.Tracker
+	metricsTracker    health.MetricsTracker
 	upstreamsRegistry *upstream.UpstreamsRegistry
 
 	// methodName -> upstreamId -> state
@@ -50,7 +50,7 @@ func NewPolicyEvaluator(
 	logger *zerolog.Logger,
 	config *common.SelectionPolicyConfig,
 	upstreamsRegistry *upstream.UpstreamsRegistry,
-	metricsTracker *health.Tracker,
+	metricsTracker health.MetricsTracker,
 ) (*PolicyEvaluator, error) {
 	runtime, err := common.NewRuntime()
 	if err != nil {
@@ -111,7 +111,7 @@ func (p *PolicyEvaluator) evaluateUpstreams() error {
 		// Get all metrics to find unique methods
 		allMetrics := make(map[string]bool)
 		for _, ups := range upsList {
-			metrics := p.metricsTracker.GetUpstreamMetrics(ups.Config().Id)
+			metrics := p.metricsTracker.GetUpstreamMetricsSnapshot(ups.Config().Id)
 			for key := range metrics {
 				// Split network:method into parts
 				parts := strings.SplitN(key, common.KeySeparator, 2)
@@ -269,9 +269,12 @@ func (p *PolicyEvaluator) evaluateMethod(method string, upsList []*upstream.Upst
 
 		// Update tracker state
 		if !state.isActive {
-			p.metricsTracker.Cordon(id, p.networkId, method, "excluded by selection policy")
+			p.metricsTracker.CordonWithInfo(id, p.networkId, method, health.CordonInfo{
+				Reason: "excluded by selection policy",
+				Source: health.CordonSourcePolicy,
+			})
 		} else {
-			p.metricsTracker.Uncordon(id, p.networkId, method)
+			p.metricsTracker.UncordonWithSource(id, p.networkId, method, health.CordonSourcePolicy)
 		}
 
 		state.mu.Unlock()
@@ -667,9 +667,10 @@ func TestPolicyEvaluator(t *testing.T) {
 		// Verify upstream is cordoned for method1
 		metrics = mt.GetUpstreamMethodMetrics("rpc1", "evm:123", "method1")
 		assert.True(t, metrics.Cordoned.Load(), "Upstream should be cordoned for method1")
-		reason, ok := metrics.CordonedReason.Load().(string)
-		assert.True(t, ok, "Cordon reason should be a string")
-		assert.Contains(t, reason, "excluded by selection policy", "Cordon reason should indicate policy exclusion")
+		info := metrics.CordonInfo()
+		require.NotNil(t, info, "Cordon info should be set")
+		assert.Contains(t, info.Reason, "excluded by selection policy", "Cordon reason should indicate policy exclusion")
+		assert.Equal(t, health.CordonSourcePolicy, info.Source)
 
 		// Verify different method (method2) is not cordoned
 		metrics = mt.GetUpstreamMethodMetrics("rpc1", "evm:123", "method2")
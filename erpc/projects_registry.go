@@ -108,6 +108,9 @@ func (r *ProjectsRegistry) RegisterProject(prjCfg *common.ProjectConfig) (*Prepa
 		wsDuration = 30 * time.Minute
 	}
 	metricsTracker := health.NewTracker(&lg, prjCfg.Id, wsDuration)
+	metricsTracker.SetEligibilityThresholds(common.DefaultPolicyThresholds())
+	metricsTracker.SetProjectAggregates(prjCfg.ProjectAggregates)
+	metricsTracker.StartAsyncTelemetry(r.appCtx, health.DefaultTelemetryQueueSize)
 	providersRegistry, err := thirdparty.NewProvidersRegistry(
 		&lg,
 		r.vendorsRegistry,
@@ -130,6 +133,18 @@ func (r *ProjectsRegistry) RegisterProject(prjCfg *common.ProjectConfig) (*Prepa
 		metricsTracker,
 		1*time.Second,
 	)
+	if prjCfg.HealthTracker != nil {
+		if err := configureHealthTracker(metricsTracker, upstreamsRegistry, prjCfg.HealthTracker); err != nil {
+			return nil, err
+		}
+	}
+	if bc := prjCfg.BroadcastChecks; bc != nil {
+		metricsTracker.EnableBroadcastChecks(r.appCtx, health.BroadcastChecksConfig{
+			Checker:    upstreamsRegistry.BroadcastChecker(),
+			Delay:      bc.Delay.Duration(),
+			MaxPending: bc.MaxPending,
+		})
+	}
 
 	var consumerAuthRegistry *auth.AuthRegistry
 	if prjCfg.Auth != nil {
@@ -10,11 +10,13 @@ import (
 
 	"github.com/erpc/erpc/common"
 	"github.com/erpc/erpc/data"
+	"github.com/erpc/erpc/health"
 	"github.com/erpc/erpc/thirdparty"
 	"github.com/erpc/erpc/upstream"
 	"github.com/erpc/erpc/util"
 	"github.com/h2non/gock"
 	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestProject_Forward(t *testing.T) {
@@ -668,3 +670,95 @@ func TestProject_NetworkAlias(t *testing.T) {
 		}
 	})
 }
+
+func TestProject_HealthTracker(t *testing.T) {
+	util.ResetGock()
+	defer util.ResetGock()
+	util.SetupMocksForEvmStatePoller()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ssr, err := data.NewSharedStateRegistry(ctx, &log.Logger, &common.SharedStateConfig{
+		Connector: &common.ConnectorConfig{
+			Driver: "memory",
+			Memory: &common.MemoryConnectorConfig{
+				MaxItems: 100_000,
+			},
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+	rateLimitersRegistry, err := upstream.NewRateLimitersRegistry(&common.RateLimiterConfig{}, &log.Logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prjReg, err := NewProjectsRegistry(
+		ctx,
+		&log.Logger,
+		[]*common.ProjectConfig{
+			{
+				Id: "prjA",
+				HealthTracker: &common.HealthTrackerConfig{
+					TieBreak:       "roundRobin",
+					NetworkAliases: map[string]string{"custom-chain": "evm:123"},
+				},
+				Networks: []*common.NetworkConfig{
+					{
+						Architecture: common.ArchitectureEvm,
+						Evm: &common.EvmNetworkConfig{
+							ChainId:               123,
+							FallbackFinalityDepth: 100,
+						},
+					},
+				},
+				Upstreams: []*common.UpstreamConfig{
+					{
+						Id:       "rpc1",
+						Endpoint: "http://rpc1.localhost",
+						Type:     common.UpstreamTypeEvm,
+						Evm: &common.EvmUpstreamConfig{
+							ChainId: 123,
+						},
+						ErrorBudget: &common.ErrorBudgetConfig{Fraction: 0.01, Penalize: true},
+					},
+				},
+			},
+		},
+		ssr,
+		nil,
+		rateLimitersRegistry,
+		thirdparty.NewVendorsRegistry(),
+		nil, // ProxyPoolRegistry
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = prjReg.Bootstrap(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prj, err := prjReg.GetProject("prjA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = prj.networksRegistry.GetNetwork("evm:123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker := prj.networksRegistry.metricsTracker.(*health.Tracker)
+
+	assert.Equal(t, health.TieBreakRoundRobin, tracker.TieBreak())
+	assert.Same(t,
+		tracker.GetUpstreamMethodMetrics("rpc1", "evm:123", "*"),
+		tracker.GetUpstreamMethodMetrics("rpc1", "custom-chain", "*"),
+	)
+
+	tracker.SetLatestBlockNumber("other", "evm:123", 1000)
+	assert.Equal(t, int64(900), tracker.GetFinalizedBlockNumber("other", "evm:123"))
+
+	_, ok := tracker.GetErrorBudget("rpc1", "evm:123")
+	assert.True(t, ok)
+}
@@ -0,0 +1,78 @@
+package health
+
+// SetMethodInAggregate sets whether a method counts towards the wildcard rollups of its
+// upstreams, i.e. the "*" and method class keys, every method does by default. An excluded
+// method, e.g. the one of health checks, is still tracked under its own key and in the network
+// aggregate of the method, so that the rollups reflect user traffic only. Only recordings made
+// afterwards follow the change. Aggregate methods such as "*" cannot be excluded.
+func (t *Tracker) SetMethodInAggregate(method string, include bool) {
+	method = t.normalizeMethod(method)
+	if isAggregateMethod(method) {
+		return
+	}
+	t.aggregateExclusionsMu.Lock()
+	defer t.aggregateExclusionsMu.Unlock()
+
+	prev := t.aggregateExclusions.Load()
+	next := make(map[string]struct{})
+	if prev != nil {
+		for m := range *prev {
+			next[m] = struct{}{}
+		}
+	}
+	if include {
+		delete(next, method)
+	} else {
+		next[method] = struct{}{}
+	}
+	if len(next) == 0 {
+		t.aggregateExclusions.Store(nil)
+		return
+	}
+	t.aggregateExclusions.Store(&next)
+}
+
+// inAggregate tells whether a normalized method counts towards the wildcard rollups, see
+// SetMethodInAggregate.
+func (t *Tracker) inAggregate(method string) bool {
+	excluded := t.aggregateExclusions.Load()
+	if excluded == nil {
+		return true
+	}
+	_, ok := (*excluded)[method]
+	return !ok
+}
+
+// SetProjectAggregates sets whether the recordings of every network also roll up into the
+// project aggregates, i.e. the {"*", "*", method} and {"*", "*", "*"} keys, see GetProjectMetrics.
+// It is off by default since it adds up to two keys to every recording. Disabling it drops the
+// project aggregates, enabling it only rolls up the recordings made afterwards.
+func (t *Tracker) SetProjectAggregates(enabled bool) {
+	t.projectAggregates.Store(enabled)
+	if enabled {
+		return
+	}
+	t.metrics.Range(func(key, _ any) bool {
+		if k := key.(tripletKey); k.ups == "*" && k.network == "*" {
+			t.metrics.Delete(k)
+		}
+		return true
+	})
+}
+
+// GetProjectMetrics returns snapshots of the project aggregates across all networks keyed by
+// method, "*" being all methods, or nil when project aggregates are disabled. Shadow upstreams
+// are left out like in the network aggregates.
+func (t *Tracker) GetProjectMetrics() map[string]*TrackedMetricsSnapshot {
+	if !t.projectAggregates.Load() {
+		return nil
+	}
+	result := make(map[string]*TrackedMetricsSnapshot)
+	t.metrics.Range(func(key, value any) bool {
+		if k := key.(tripletKey); k.ups == "*" && k.network == "*" {
+			result[k.method] = t.keySnapshotOf(value.(*TrackedMetrics), k)
+		}
+		return true
+	})
+	return result
+}
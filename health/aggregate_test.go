@@ -0,0 +1,118 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMethodInAggregate(t *testing.T) {
+	networkID := "evm:123"
+	requests := func(tracker *Tracker, k tripletKey) int64 {
+		if val, ok := tracker.metrics.Load(k); ok {
+			return val.(*TrackedMetrics).RequestsTotal.Load()
+		}
+		return 0
+	}
+
+	t.Run("ExcludedMethodOnlyUpdatesItsExactKey", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		tracker.SetMethodInAggregate("eth_chainId", false)
+		tracker.RecordUpstreamRequest("a", networkID, "eth_chainId")
+		tracker.RecordUpstreamFailure("a", networkID, "eth_chainId")
+		tracker.RecordUpstreamRequest("a", networkID, "eth_call")
+
+		assert.Equal(t, int64(1), requests(tracker, tripletKey{"a", networkID, "eth_chainId"}))
+		assert.Equal(t, int64(1), tracker.GetUpstreamMethodMetrics("a", networkID, "eth_chainId").ErrorsTotal.Load())
+		assert.Equal(t, int64(1), requests(tracker, tripletKey{"*", networkID, "eth_chainId"}))
+		for _, k := range []tripletKey{
+			{"a", networkID, "*"},
+			{"a", "*", "*"},
+			{"*", networkID, "*"},
+			{"a", networkID, methodClassReadsKey},
+			{"*", networkID, methodClassReadsKey},
+		} {
+			assert.Equal(t, int64(1), requests(tracker, k), "only eth_call in %v", k)
+		}
+		assert.Equal(t, 0.0, tracker.GetUpstreamMethodMetrics("a", networkID, "*").ErrorRate())
+	})
+
+	t.Run("IncludedByDefaultAndAgain", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		tracker.RecordUpstreamRequest("a", networkID, "eth_chainId")
+		tracker.SetMethodInAggregate("eth_chainId", false)
+		tracker.RecordUpstreamRequest("a", networkID, "eth_chainId")
+		tracker.SetMethodInAggregate("eth_chainId", true)
+		tracker.RecordUpstreamRequest("a", networkID, "eth_chainId")
+
+		assert.Equal(t, int64(3), requests(tracker, tripletKey{"a", networkID, "eth_chainId"}))
+		assert.Equal(t, int64(2), requests(tracker, tripletKey{"a", networkID, "*"}))
+		assert.Nil(t, tracker.aggregateExclusions.Load())
+	})
+
+	t.Run("AggregateMethodsCannotBeExcluded", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		tracker.SetMethodInAggregate("*", false)
+		tracker.RecordUpstreamRequest("a", networkID, "*")
+		assert.Equal(t, int64(1), requests(tracker, tripletKey{"a", "*", "*"}))
+	})
+
+	t.Run("ShadowUpstreamsStayOutOfTheNetwork", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		tracker.SetUpstreamShadow("shadow", true)
+		tracker.SetMethodInAggregate("eth_chainId", false)
+		tracker.RecordUpstreamRequest("shadow", networkID, "eth_chainId")
+		assert.Equal(t, int64(1), requests(tracker, tripletKey{"shadow", networkID, "eth_chainId"}))
+		assert.Equal(t, int64(0), requests(tracker, tripletKey{"*", networkID, "eth_chainId"}))
+	})
+}
+
+func TestProjectAggregates(t *testing.T) {
+	t.Run("DisabledByDefault", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		tracker.RecordUpstreamRequest("a", "evm:1", "eth_call")
+		assert.Nil(t, tracker.GetProjectMetrics())
+		_, ok := tracker.metrics.Load(tripletKey{"*", "*", "*"})
+		assert.False(t, ok)
+	})
+
+	t.Run("AggregatesAcrossNetworks", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		tracker.SetProjectAggregates(true)
+		tracker.SetUpstreamShadow("shadow", true)
+		tracker.RecordUpstreamRequest("a", "evm:1", "eth_call")
+		tracker.RecordUpstreamFailure("a", "evm:1", "eth_call")
+		tracker.RecordUpstreamRequest("b", "evm:137", "eth_call")
+		tracker.RecordUpstreamRequest("b", "evm:137", "eth_getLogs")
+		tracker.RecordUpstreamRequest("shadow", "evm:1", "eth_call")
+
+		project := tracker.GetProjectMetrics()
+		assert.Len(t, project, 3)
+		assert.Equal(t, int64(3), project["*"].RequestsTotal)
+		assert.Equal(t, 1.0/3, project["*"].ErrorRate)
+		assert.Equal(t, int64(2), project["eth_call"].RequestsTotal)
+		assert.Equal(t, int64(1), project["eth_getLogs"].RequestsTotal)
+
+		// Upstream-wide keys are distinct from the project ones
+		assert.Equal(t, int64(1), tracker.GetUpstreamMethodMetrics("a", "*", "*").RequestsTotal.Load())
+		assert.Equal(t, int64(2), tracker.GetUpstreamMethodMetrics("b", "*", "*").RequestsTotal.Load())
+
+		tracker.SetProjectAggregates(false)
+		assert.Nil(t, tracker.GetProjectMetrics())
+		_, ok := tracker.metrics.Load(tripletKey{"*", "*", "*"})
+		assert.False(t, ok)
+	})
+
+	t.Run("ExcludedMethodsOnlyInTheirOwnAggregate", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		tracker.SetProjectAggregates(true)
+		tracker.SetMethodInAggregate("eth_chainId", false)
+		tracker.RecordUpstreamRequest("a", "evm:1", "eth_chainId")
+
+		project := tracker.GetProjectMetrics()
+		assert.Equal(t, int64(1), project["eth_chainId"].RequestsTotal)
+		assert.NotContains(t, project, "*")
+	})
+}
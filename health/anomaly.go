@@ -0,0 +1,159 @@
+package health
+
+import (
+	"fmt"
+	"math"
+	"sync"
+)
+
+const (
+	EventLatencyAnomaly          EventType = "latencyAnomaly"
+	EventLatencyAnomalyRecovered EventType = "latencyAnomalyRecovered"
+)
+
+// LatencyAnomalyConfig configures detection of upstreams getting slower than their own usual
+// latency, measured per key against a baseline built from the p50 and p90 of past windows.
+type LatencyAnomalyConfig struct {
+	// Weight of the latest window in the baseline EWMA, e.g. 0.1
+	Alpha float64
+	// Windows needed before a baseline is formed and deviations are computed
+	MinWindows int
+	// Deviation (ratio to the baseline) above which a window is anomalous, e.g. 4
+	Factor float64
+	// Consecutive anomalous windows needed to flag the key as anomalous
+	ConsecutiveWindows int
+	// Baseline is forgotten after this many windows without traffic, zero keeps it forever
+	MaxIdleWindows int
+	// Baseline is rebuilt from scratch after this many consecutive anomalous windows, accepting
+	// the new latency as normal (e.g. after a traffic pattern change). Zero disables rebuilding.
+	RebaselineWindows int
+	// Emit EventLatencyAnomaly and EventLatencyAnomalyRecovered on transitions
+	EmitEvents bool
+	// Multiplier applied by EffectiveWeight while an upstream is anomalous on a network, within
+	// (0, 1]. Zero disables the penalty.
+	ScorePenalty float64
+}
+
+type latencyBaseline struct {
+	mu               sync.Mutex
+	p50              float64
+	p90              float64
+	windows          int
+	idleWindows      int
+	anomalousWindows int
+}
+
+// SetLatencyAnomalyConfig enables latency anomaly detection, nil disables it and drops baselines.
+func (t *Tracker) SetLatencyAnomalyConfig(cfg *LatencyAnomalyConfig) {
+	t.anomalyConfig.Store(cfg)
+	if cfg == nil {
+		t.baselines.Range(func(key, value any) bool {
+			t.baselines.Delete(key)
+			return true
+		})
+		t.metrics.Range(func(key, value any) bool {
+			tm := value.(*TrackedMetrics)
+			tm.setLatencyDeviation(0)
+			tm.LatencyAnomalous.Store(false)
+			return true
+		})
+	}
+}
+
+// rollLatencyBaselines compares the closing window of every upstream key with its baseline,
+// it must run right before metrics are reset.
+func (t *Tracker) rollLatencyBaselines() {
+	cfg := t.anomalyConfig.Load()
+	if cfg == nil {
+		return
+	}
+	t.metrics.Range(func(key, value any) bool {
+		k := key.(tripletKey)
+		if k.ups == "*" {
+			return true
+		}
+		tm := value.(*TrackedMetrics)
+
+		val, _ := t.baselines.LoadOrStore(k, &latencyBaseline{})
+		b := val.(*latencyBaseline)
+		b.mu.Lock()
+		wasAnomalous := tm.LatencyAnomalous.Load()
+		deviation, anomalous, forget := b.observeLocked(cfg, tm.ResponseQuantiles)
+		b.mu.Unlock()
+		if forget {
+			t.baselines.CompareAndDelete(k, b)
+		}
+
+		tm.setLatencyDeviation(deviation)
+		tm.LatencyAnomalous.Store(anomalous)
+		if cfg.EmitEvents && anomalous != wasAnomalous {
+			e := Event{
+				Type:      EventLatencyAnomaly,
+				Upstream:  k.ups,
+				Network:   k.network,
+				Method:    k.method,
+				Value:     deviation,
+				Threshold: cfg.Factor,
+				Message:   fmt.Sprintf("latency deviates %.2fx from baseline for %d windows", deviation, cfg.ConsecutiveWindows),
+			}
+			if !anomalous {
+				e.Type = EventLatencyAnomalyRecovered
+				e.Message = fmt.Sprintf("latency back to %.2fx of baseline", deviation)
+			}
+			t.emit(e)
+		}
+		return true
+	})
+}
+
+// observeLocked folds a closing window into the baseline, returning its deviation score,
+// whether the key is anomalous and whether the baseline should be forgotten.
+func (b *latencyBaseline) observeLocked(cfg *LatencyAnomalyConfig, qt *QuantileTracker) (float64, bool, bool) {
+	if !qt.HasSamples() {
+		b.idleWindows++
+		b.anomalousWindows = 0
+		return 0, false, cfg.MaxIdleWindows > 0 && b.idleWindows >= cfg.MaxIdleWindows
+	}
+	b.idleWindows = 0
+
+	p50 := qt.GetQuantile(0.50).Seconds()
+	p90 := qt.GetQuantile(0.90).Seconds()
+
+	var deviation float64
+	if b.windows >= cfg.MinWindows && b.p50 > 0 && b.p90 > 0 {
+		deviation = math.Max(p50/b.p50, p90/b.p90)
+	}
+
+	if deviation > cfg.Factor {
+		b.anomalousWindows++
+		if cfg.RebaselineWindows > 0 && b.anomalousWindows >= cfg.RebaselineWindows {
+			b.p50, b.p90, b.windows, b.anomalousWindows = p50, p90, 1, 0
+			return 0, false, false
+		}
+		// Anomalous windows are kept out of the baseline so that it doesn't drift towards them
+		return deviation, b.anomalousWindows >= cfg.ConsecutiveWindows, false
+	}
+
+	b.anomalousWindows = 0
+	b.windows++
+	alpha := cfg.Alpha
+	if b.windows < cfg.MinWindows || alpha <= 0 {
+		// Plain average while the baseline is forming
+		alpha = 1 / float64(b.windows)
+	}
+	b.p50 += alpha * (p50 - b.p50)
+	b.p90 += alpha * (p90 - b.p90)
+	return deviation, false, false
+}
+
+// latencyPenalty returns the score multiplier of an upstream on a network.
+func (t *Tracker) latencyPenalty(ups, network string) float64 {
+	cfg := t.anomalyConfig.Load()
+	if cfg == nil || cfg.ScorePenalty <= 0 {
+		return 1
+	}
+	if val, ok := t.metrics.Load(tripletKey{ups, network, "*"}); ok && val.(*TrackedMetrics).LatencyAnomalous.Load() {
+		return cfg.ScorePenalty
+	}
+	return 1
+}
@@ -0,0 +1,107 @@
+package health_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health"
+	"github.com/erpc/erpc/health/healthtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatencyAnomalyDetection(t *testing.T) {
+	networkID := "evm:123"
+
+	// recordWindow records durations on upstream "a" (none if d is zero) and closes the window.
+	recordWindow := func(t *testing.T, tracker *health.Tracker, clock *healthtest.FakeClock, d time.Duration) *health.TrackedMetrics {
+		m := tracker.GetUpstreamMethodMetrics("a", networkID, "*")
+		tracker.RecordUpstreamRequest("a", networkID, "eth_call")
+		if d > 0 {
+			for i := 0; i < 50; i++ {
+				tracker.RecordUpstreamDuration("a", networkID, "eth_call", d, "none")
+			}
+		}
+		healthtest.AdvanceWindow(t, clock, time.Minute, m)
+		return m
+	}
+
+	config := func() *health.LatencyAnomalyConfig {
+		return &health.LatencyAnomalyConfig{
+			Alpha:              0.1,
+			MinWindows:         3,
+			Factor:             4,
+			ConsecutiveWindows: 2,
+			EmitEvents:         true,
+			ScorePenalty:       0.5,
+		}
+	}
+
+	t.Run("FlagsSustainedDeviationAndRecovers", func(t *testing.T) {
+		tracker, clock := newFakeClockTracker(t, time.Minute)
+		tracker.SetLatencyAnomalyConfig(config())
+		events, unsubscribe := tracker.Subscribe(10)
+		defer unsubscribe()
+
+		var m *health.TrackedMetrics
+		for i := 0; i < 3; i++ {
+			m = recordWindow(t, tracker, clock, 100*time.Millisecond)
+		}
+		assert.Eventually(t, func() bool { return m.LatencyDeviation() == 0 }, time.Second, time.Millisecond)
+
+		// First slow window only raises the deviation
+		recordWindow(t, tracker, clock, 600*time.Millisecond)
+		assert.Eventually(t, func() bool { return m.LatencyDeviation() > 5.9 }, time.Second, time.Millisecond)
+		assert.False(t, m.LatencyAnomalous.Load())
+		assert.Equal(t, 1.0, tracker.EffectiveWeight("a", networkID, 1))
+
+		recordWindow(t, tracker, clock, 600*time.Millisecond)
+		e := waitEvent(t, events)
+		assert.Equal(t, health.EventLatencyAnomaly, e.Type)
+		assert.Equal(t, "a", e.Upstream)
+		assert.True(t, m.LatencyAnomalous.Load())
+		assert.Equal(t, 0.5, tracker.EffectiveWeight("a", networkID, 1))
+
+		recordWindow(t, tracker, clock, 100*time.Millisecond)
+		for e.Type != health.EventLatencyAnomalyRecovered {
+			e = waitEvent(t, events)
+		}
+		assert.InDelta(t, 1, m.LatencyDeviation(), 0.05)
+		assert.Equal(t, 1.0, tracker.EffectiveWeight("a", networkID, 1))
+	})
+
+	t.Run("NoBaselineBeforeMinWindows", func(t *testing.T) {
+		tracker, clock := newFakeClockTracker(t, time.Minute)
+		tracker.SetLatencyAnomalyConfig(config())
+
+		recordWindow(t, tracker, clock, 100*time.Millisecond)
+		recordWindow(t, tracker, clock, 100*time.Millisecond)
+		m := recordWindow(t, tracker, clock, time.Second)
+		assert.Equal(t, float64(0), m.LatencyDeviation())
+	})
+
+	t.Run("BaselineAgesOut", func(t *testing.T) {
+		cfg := config()
+		cfg.MaxIdleWindows = 2
+		cfg.RebaselineWindows = 3
+		tracker, clock := newFakeClockTracker(t, time.Minute)
+		tracker.SetLatencyAnomalyConfig(cfg)
+
+		for i := 0; i < 3; i++ {
+			recordWindow(t, tracker, clock, 100*time.Millisecond)
+		}
+		// Idle windows forget the baseline, so a slower latency forms a new one
+		recordWindow(t, tracker, clock, 0)
+		recordWindow(t, tracker, clock, 0)
+		m := recordWindow(t, tracker, clock, time.Second)
+		assert.Equal(t, float64(0), m.LatencyDeviation())
+
+		for i := 0; i < 2; i++ {
+			recordWindow(t, tracker, clock, time.Second)
+		}
+		// A lasting change is eventually accepted as the new normal
+		for i := 0; i < 3; i++ {
+			recordWindow(t, tracker, clock, 5*time.Second)
+		}
+		assert.Eventually(t, func() bool { return !m.LatencyAnomalous.Load() && m.LatencyDeviation() == 0 }, time.Second, time.Millisecond)
+	})
+}
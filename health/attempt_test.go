@@ -0,0 +1,73 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/telemetry"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttemptLatency(t *testing.T) {
+	telemetry.SetHistogramBuckets("0.05,0.5,5,30")
+
+	record := func(tracker *Tracker, attempt int, d time.Duration) {
+		tracker.RecordOutcome("a", "evm:1", "eth_call", Outcome{Kind: OutcomeSuccess, Attempt: attempt, Duration: d})
+	}
+
+	t.Run("RetriesAreKeptApartFromFirstAttempts", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "attempt-project", time.Minute)
+		for i := 0; i < 10; i++ {
+			record(tracker, 1, 100*time.Millisecond)
+			record(tracker, 3, 2*time.Second)
+		}
+		// Unknown attempts count as first attempts
+		record(tracker, 0, 100*time.Millisecond)
+
+		m := tracker.GetUpstreamMethodMetrics("a", "evm:1", "eth_call")
+		assert.InEpsilon(t, 0.1, m.GetAttemptQuantiles(1).GetQuantile(0.9).Seconds(), 0.02)
+		assert.InEpsilon(t, 2, m.GetAttemptQuantiles(2).GetQuantile(0.9).Seconds(), 0.02)
+		assert.Same(t, m.GetAttemptQuantiles(2), m.GetAttemptQuantiles(5))
+		assert.InEpsilon(t, 2, m.ResponseQuantiles.GetQuantile(0.9).Seconds(), 0.02)
+
+		m.Reset()
+		assert.False(t, m.GetAttemptQuantiles(1).HasSamples())
+	})
+
+	t.Run("ScoringUsesFirstAttemptsByDefault", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "attempt-project", time.Minute)
+		for i := 0; i < 10; i++ {
+			record(tracker, 1, 100*time.Millisecond)
+			record(tracker, 2, 2*time.Second)
+		}
+
+		v := tracker.SelectionView("a", "evm:1", "eth_call")
+		assert.InEpsilon(t, 0.1, v.P90Latency.Seconds(), 0.02)
+
+		tracker.SetScoreRetryLatency(true)
+		v = tracker.SelectionView("a", "evm:1", "eth_call")
+		assert.InEpsilon(t, 2, v.P90Latency.Seconds(), 0.02)
+	})
+
+	t.Run("OnlyRetriesFallBackToAllAttempts", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "attempt-project", time.Minute)
+		record(tracker, 2, time.Second)
+
+		v := tracker.SelectionView("a", "evm:1", "eth_call")
+		assert.True(t, v.HasLatency)
+		assert.InEpsilon(t, 1, v.P90Latency.Seconds(), 0.02)
+	})
+
+	t.Run("TimerCarriesTheAttempt", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "attempt-project", time.Minute)
+		timer := tracker.RecordUpstreamDurationStart("a", "evm:2", "eth_call", "none")
+		timer.SetAttempt(4)
+		timer.ObserveOutcome(Outcome{Kind: OutcomeSuccess})
+
+		m := tracker.GetUpstreamMethodMetrics("a", "evm:2", "eth_call")
+		assert.Nil(t, m.GetAttemptQuantiles(1))
+		assert.True(t, m.GetAttemptQuantiles(4).HasSamples())
+		assert.Equal(t, []string{"1", "2", "3+", "3+"}, []string{attemptLabel(0), attemptLabel(2), attemptLabel(3), attemptLabel(10)})
+	})
+}
@@ -0,0 +1,92 @@
+package health
+
+import (
+	"github.com/erpc/erpc/telemetry"
+)
+
+// AttributeVendor is the upstream attribute attached as the "vendor" label on the tracker's
+// upstream metrics.
+const AttributeVendor = "vendor"
+
+// upstreamAttributeKeys bounds the attributes kept per upstream, other keys are dropped.
+var upstreamAttributeKeys = map[string]struct{}{
+	AttributeVendor: {},
+	AttributeClient: {},
+	"provider":      {},
+	"region":        {},
+	"tier":          {},
+}
+
+// SetUpstreamAttributes replaces the attributes of an upstream on a network, e.g. its vendor.
+// Keys outside the allowlist (vendor, client, provider, region, tier) are ignored. When the vendor changes
+// the upstream series labeled with the previous vendor are removed.
+func (t *Tracker) SetUpstreamAttributes(ups, network string, attrs map[string]string) {
+	network = t.canonicalNetwork(network)
+	kept := make(map[string]string, len(attrs))
+	for key, value := range attrs {
+		if _, ok := upstreamAttributeKeys[key]; !ok {
+			t.logger.Debug().Str("upstreamId", ups).Str("attribute", key).Msg("ignoring upstream attribute not in the allowlist")
+			continue
+		}
+		kept[key] = value
+	}
+
+	k := duoKey{ups: ups, network: network}
+	prev, loaded := t.attributes.Swap(k, kept)
+	if loaded {
+		if prevVendor := prev.(map[string]string)[AttributeVendor]; prevVendor != kept[AttributeVendor] {
+			telemetry.DeleteUpstreamVendorSeries(t.projectId, network, ups, prevVendor)
+			t.refreshCordonedGauges(ups, network)
+			t.refreshClientInfoGauge(ups, network)
+		}
+	}
+}
+
+// GetUpstreamAttributes returns a copy of the attributes of an upstream on a network.
+func (t *Tracker) GetUpstreamAttributes(ups, network string) map[string]string {
+	network = t.canonicalNetwork(network)
+	val, ok := t.attributes.Load(duoKey{ups: ups, network: network})
+	if !ok {
+		return nil
+	}
+	attrs := val.(map[string]string)
+	out := make(map[string]string, len(attrs))
+	for k, v := range attrs {
+		out[k] = v
+	}
+	return out
+}
+
+// AttributesSnapshot returns the attributes of every upstream, keyed by "<upstream>|<network>".
+func (t *Tracker) AttributesSnapshot() map[string]map[string]string {
+	out := make(map[string]map[string]string)
+	t.attributes.Range(func(key, value any) bool {
+		k := key.(duoKey)
+		out[k.ups+"|"+k.network] = t.GetUpstreamAttributes(k.ups, k.network)
+		return true
+	})
+	return out
+}
+
+func (t *Tracker) upstreamVendor(ups, network string) string {
+	if val, ok := t.attributes.Load(duoKey{ups: ups, network: network}); ok {
+		return val.(map[string]string)[AttributeVendor]
+	}
+	return ""
+}
+
+// refreshCordonedGauges re-emits the cordon state of an upstream, other gauges are refreshed by
+// their next update.
+func (t *Tracker) refreshCordonedGauges(ups, network string) {
+	vendor := t.upstreamVendor(ups, network)
+	t.metrics.Range(func(key, value any) bool {
+		k := key.(tripletKey)
+		if k.ups != ups || k.network != network {
+			return true
+		}
+		if value.(*TrackedMetrics).Cordoned.Load() {
+			telemetry.MetricUpstreamCordoned.WithLabelValues(t.projectId, network, ups, vendor, k.method).Set(1)
+		}
+		return true
+	})
+}
@@ -0,0 +1,60 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health/internal/metricstest"
+	"github.com/erpc/erpc/telemetry"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpstreamAttributes(t *testing.T) {
+	telemetry.SetHistogramBuckets("0.05,0.5,5,30")
+
+	t.Run("AllowlistAndSnapshot", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-attributes", time.Minute)
+		tracker.SetUpstreamAttributes("a", "evm:0x1", map[string]string{"vendor": "alchemy", "region": "eu", "apiKey": "secret"})
+
+		assert.Equal(t, map[string]string{"vendor": "alchemy", "region": "eu"}, tracker.GetUpstreamAttributes("a", "evm:1"))
+		assert.Equal(t, map[string]map[string]string{
+			"a|evm:1": {"vendor": "alchemy", "region": "eu"},
+		}, tracker.AttributesSnapshot())
+		assert.Nil(t, tracker.GetUpstreamAttributes("b", "evm:1"))
+	})
+
+	t.Run("VendorLabelIsUpdatedWithoutDuplicatingSeries", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-attributes-relabel", time.Minute)
+		t.Cleanup(func() {
+			telemetry.DeleteUpstreamVendorSeries("test-attributes-relabel", "evm:1", "a", "quicknode")
+		})
+		tracker.SetUpstreamAttributes("a", "evm:1", map[string]string{"vendor": "alchemy"})
+		tracker.RecordUpstreamSelfRateLimited("a", "evm:1", "eth_call")
+		tracker.Cordon("a", "evm:1", "eth_call", "test")
+
+		assert.Equal(t, []string{"alchemy"}, vendorLabels(t, "erpc_upstream_request_self_rate_limited_total", "test-attributes-relabel"))
+		assert.Equal(t, []string{"alchemy"}, vendorLabels(t, "erpc_upstream_cordoned", "test-attributes-relabel"))
+
+		// Same vendor keeps the series
+		tracker.SetUpstreamAttributes("a", "evm:1", map[string]string{"vendor": "alchemy", "tier": "paid"})
+		assert.Equal(t, []string{"alchemy"}, vendorLabels(t, "erpc_upstream_request_self_rate_limited_total", "test-attributes-relabel"))
+
+		tracker.SetUpstreamAttributes("a", "evm:1", map[string]string{"vendor": "quicknode"})
+		assert.Empty(t, vendorLabels(t, "erpc_upstream_request_self_rate_limited_total", "test-attributes-relabel"))
+		// The cordon state is re-emitted under the new vendor
+		assert.Equal(t, []string{"quicknode"}, vendorLabels(t, "erpc_upstream_cordoned", "test-attributes-relabel"))
+
+		tracker.RecordUpstreamSelfRateLimited("a", "evm:1", "eth_call")
+		assert.Equal(t, []string{"quicknode"}, vendorLabels(t, "erpc_upstream_request_self_rate_limited_total", "test-attributes-relabel"))
+	})
+}
+
+// vendorLabels returns the vendor label of every series of a metric for a project.
+func vendorLabels(t *testing.T, name, project string) []string {
+	var vendors []string
+	for _, s := range metricstest.Samples(t, name, map[string]string{"project": project}) {
+		vendors = append(vendors, s.Labels["vendor"])
+	}
+	return vendors
+}
@@ -0,0 +1,127 @@
+package health
+
+import (
+	"strings"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/telemetry"
+)
+
+// AttributeClient is the upstream attribute naming its node client (e.g. "geth", "erigon"), used
+// to pick the behind head matchers of the client.
+const AttributeClient = "client"
+
+// BehindHeadMatchers lists, per node client, lowercase substrings of error messages telling that
+// an upstream has not synced the requested block yet. Matchers of "*" apply to every client, on
+// top of the ones of the upstream's client.
+type BehindHeadMatchers map[string][]string
+
+// DefaultBehindHeadMatchers only holds messages unambiguous enough for any client, others go
+// under their client to limit misclassifications. Mind that wordings of our own errors (e.g. "not
+// synced yet" of ErrEndpointMissingData) would match errors without an upstream message.
+var DefaultBehindHeadMatchers = BehindHeadMatchers{
+	"*": {
+		"header not found",
+		"unknown block",
+	},
+	"geth": {
+		"missing trie node",
+	},
+	"erigon": {
+		"block not found",
+	},
+	"reth": {
+		"block not found",
+	},
+	"besu": {
+		"block not found",
+	},
+	"nethermind": {
+		"could not be found",
+		"node is syncing",
+	},
+}
+
+// SetBehindHeadMatchers replaces the matchers classifying errors as behind head errors, nil
+// restores DefaultBehindHeadMatchers. Matchers are compared case-insensitively.
+func (t *Tracker) SetBehindHeadMatchers(matchers BehindHeadMatchers) {
+	if matchers == nil {
+		t.behindHeadMatchers.Store(nil)
+		return
+	}
+	lowered := make(BehindHeadMatchers, len(matchers))
+	for client, subs := range matchers {
+		key := strings.ToLower(client)
+		for _, s := range subs {
+			lowered[key] = append(lowered[key], strings.ToLower(s))
+		}
+	}
+	t.behindHeadMatchers.Store(&lowered)
+}
+
+// SetBehindHeadEvidenceLag makes every behind head error count as evidence that the upstream
+// lags at least blocks behind the network head for the rest of the window, as reported by
+// SelectionView, even when the head it reports looks fine. Zero disables it.
+func (t *Tracker) SetBehindHeadEvidenceLag(blocks int64) {
+	t.behindHeadEvidenceLag.Store(max(blocks, 0))
+}
+
+// isBehindHeadError tells if err matches the behind head matchers of the client of the upstream.
+func (t *Tracker) isBehindHeadError(ups, network string, err error) bool {
+	matchers := DefaultBehindHeadMatchers
+	if m := t.behindHeadMatchers.Load(); m != nil {
+		matchers = *m
+	}
+	// Our own error wrappers have wordings of their own, only the upstream message is matched
+	msg := err.Error()
+	if se, ok := err.(common.StandardError); ok {
+		msg = se.DeepestMessage()
+	}
+	msg = strings.ToLower(msg)
+	var client string
+	if val, ok := t.attributes.Load(duoKey{ups: ups, network: network}); ok {
+		client = strings.ToLower(val.(map[string]string)[AttributeClient])
+	}
+	if client == "" {
+		if val, ok := t.clientVersions.Load(duoKey{ups: ups, network: network}); ok {
+			client = val.(ClientVersion).Client
+		}
+	}
+	for _, key := range []string{"*", client} {
+		if key == "" {
+			continue
+		}
+		for _, s := range matchers[key] {
+			if strings.Contains(msg, s) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RecordUpstreamBehindHeadError counts err in BehindHeadErrorsTotal when it tells that the
+// upstream has not synced the requested block yet, see SetBehindHeadMatchers, and reports whether
+// it did. It is only meant for requests of recent blocks, as the same errors for old blocks
+// rather tell missing (e.g. pruned) data.
+func (t *Tracker) RecordUpstreamBehindHeadError(ups, network, method string, err error) bool {
+	if err == nil {
+		return false
+	}
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
+	if !t.isBehindHeadError(ups, network, err) {
+		return false
+	}
+
+	evidence := t.behindHeadEvidenceLag.Load()
+	for _, k := range t.getKeys(ups, network, method) {
+		m := t.getMetrics(k)
+		m.BehindHeadErrorsTotal.Add(1)
+		if evidence > 0 && k.ups != "*" {
+			m.behindHeadEvidence.Store(evidence)
+		}
+	}
+	telemetry.MetricUpstreamBehindHeadErrorsTotal.WithLabelValues(t.projectId, network, ups, t.upstreamVendor(ups, network), method).Inc()
+	return true
+}
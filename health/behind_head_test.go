@@ -0,0 +1,101 @@
+package health_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/health"
+	"github.com/erpc/erpc/health/healthtest"
+	"github.com/stretchr/testify/assert"
+)
+
+// upstreamError wraps an upstream error message the way the EVM error normalization does.
+func upstreamError(code int, message string) error {
+	return common.NewErrEndpointMissingData(
+		common.NewErrJsonRpcExceptionInternal(code, common.JsonRpcErrorMissingData, message, nil, nil),
+	)
+}
+
+func TestBehindHeadErrors(t *testing.T) {
+	networkID := "evm:123"
+
+	t.Run("ClassifiesPayloadsPerClient", func(t *testing.T) {
+		cases := []struct {
+			name   string
+			client string
+			err    error
+			behind bool
+		}{
+			{"GethMissingTrieNode", "geth", upstreamError(-32000, "missing trie node 9b4b0c6a6f3c1a2e5bd0cfd5fba5f5b3f0f8e2c9d4c5a2f1b0e6c7d8a9b0c1d2 (path ) state 0x9b4b0c6a is not available"), true},
+			{"GethHeaderNotFound", "geth", upstreamError(-32000, "header not found"), true},
+			{"ErigonBlockNotFound", "erigon", upstreamError(-32000, "block not found: 19876543"), true},
+			{"RethUnknownBlock", "reth", upstreamError(-32001, "unknown block"), true},
+			{"BesuBlockNotFound", "besu", upstreamError(-32000, "Block not found"), true},
+			{"NethermindNotFound", "nethermind", upstreamError(-32001, "Block 0x12d8f3a could not be found"), true},
+			{"NethermindSyncing", "nethermind", upstreamError(-32002, "Node is syncing"), true},
+			{"AnyClientUnknownBlock", "", upstreamError(-32000, "unknown block 0x12d8f3a"), true},
+			{"PlainError", "", errors.New(`{"code":-32000,"message":"header not found"}`), true},
+			{"TrieNodeOnlyForGeth", "erigon", upstreamError(-32000, "missing trie node 9b4b0c6a (path )"), false},
+			{"BlockNotFoundNotForUnknownClient", "", upstreamError(-32000, "block not found"), false},
+			{"Reverted", "geth", upstreamError(3, "execution reverted: ERC20: transfer amount exceeds balance"), false},
+			{"MethodNotFound", "geth", upstreamError(-32601, "the method eth_foo does not exist/is not available"), false},
+			{"NonceTooLow", "nethermind", upstreamError(-32000, "nonce too low: next nonce 12, tx nonce 11"), false},
+			// Our own wording for missing data mentions syncing, only the upstream message counts
+			{"OwnWrapperWording", "", common.NewErrEndpointMissingData(nil), false},
+		}
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				tracker, _ := newFakeClockTracker(t, time.Minute)
+				if tc.client != "" {
+					tracker.SetUpstreamAttributes("a", networkID, map[string]string{health.AttributeClient: tc.client})
+				}
+				assert.Equal(t, tc.behind, tracker.RecordUpstreamBehindHeadError("a", networkID, "eth_call", tc.err))
+
+				expected := int64(0)
+				if tc.behind {
+					expected = 1
+				}
+				assert.Equal(t, expected, tracker.GetUpstreamMethodMetrics("a", networkID, "eth_call").BehindHeadErrorsTotal.Load())
+			})
+		}
+	})
+
+	t.Run("CustomMatchers", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		tracker.SetUpstreamAttributes("a", networkID, map[string]string{health.AttributeClient: "Geth"})
+		tracker.SetBehindHeadMatchers(health.BehindHeadMatchers{"GETH": {"State Not Ready"}})
+
+		assert.True(t, tracker.RecordUpstreamBehindHeadError("a", networkID, "eth_call", upstreamError(-32000, "state not ready")))
+		assert.False(t, tracker.RecordUpstreamBehindHeadError("a", networkID, "eth_call", upstreamError(-32000, "header not found")))
+		assert.False(t, tracker.RecordUpstreamBehindHeadError("a", networkID, "eth_call", nil))
+
+		tracker.SetBehindHeadMatchers(nil)
+		assert.True(t, tracker.RecordUpstreamBehindHeadError("a", networkID, "eth_call", upstreamError(-32000, "header not found")))
+	})
+
+	t.Run("OptionallyEvidencesHeadLag", func(t *testing.T) {
+		tracker, clock := newFakeClockTracker(t, time.Minute)
+		tracker.SetLatestBlockNumber("a", networkID, 1000)
+		tracker.SetLatestBlockNumber("b", networkID, 1000)
+		tracker.RecordUpstreamRequest("a", networkID, "eth_call")
+
+		tracker.RecordUpstreamBehindHeadError("a", networkID, "eth_call", upstreamError(-32000, "header not found"))
+		assert.Equal(t, int64(0), tracker.SelectionView("a", networkID, "eth_call").BlockHeadLag)
+
+		tracker.SetBehindHeadEvidenceLag(5)
+		tracker.RecordUpstreamBehindHeadError("a", networkID, "eth_call", upstreamError(-32000, "header not found"))
+		assert.Equal(t, int64(5), tracker.SelectionView("a", networkID, "eth_call").BlockHeadLag)
+		assert.Equal(t, int64(5), tracker.SelectionView("a", networkID, "*").BlockHeadLag)
+		assert.Equal(t, int64(0), tracker.SelectionView("b", networkID, "eth_call").BlockHeadLag)
+		assert.Equal(t, int64(0), tracker.SelectionView("*", networkID, "eth_call").BlockHeadLag)
+
+		// The head it reports keeps looking fine but the evidence holds for the window
+		tracker.SetLatestBlockNumber("a", networkID, 1001)
+		assert.Equal(t, int64(5), tracker.SelectionView("a", networkID, "eth_call").BlockHeadLag)
+
+		healthtest.AdvanceWindow(t, clock, time.Minute, tracker.GetUpstreamMethodMetrics("a", networkID, "eth_call"))
+		assert.Equal(t, int64(0), tracker.SelectionView("a", networkID, "eth_call").BlockHeadLag)
+	})
+}
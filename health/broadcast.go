@@ -0,0 +1,139 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/erpc/erpc/telemetry"
+)
+
+const (
+	defaultBroadcastCheckDelay = 30 * time.Second
+	defaultBroadcastMaxPending = 1000
+)
+
+// BroadcastChecker tells whether a transaction accepted by an upstream became visible through
+// the other upstreams of the network, e.g. with eth_getTransactionByHash. An error means it could
+// not tell (e.g. no other upstream answered), which is not held against the upstream.
+type BroadcastChecker func(ctx context.Context, network, txHash, acceptedBy string) (visible bool, err error)
+
+// BroadcastChecksConfig configures the follow-up of transactions accepted by upstreams, see
+// EnableBroadcastChecks.
+type BroadcastChecksConfig struct {
+	Checker BroadcastChecker
+	// Delay after which an accepted transaction should be visible network-wide, 30s by default.
+	Delay time.Duration
+	// MaxPending bounds the transactions waiting to be checked, 1000 by default. Transactions
+	// accepted while it is reached are not checked.
+	MaxPending int
+}
+
+type pendingBroadcast struct {
+	ups     string
+	network string
+	txHash  string
+	dueAt   time.Time
+}
+
+type broadcastChecks struct {
+	cfg     BroadcastChecksConfig
+	mu      sync.Mutex
+	pending []pendingBroadcast // ordered by dueAt since the delay is the same for all
+}
+
+// EnableBroadcastChecks starts following up on transactions recorded with RecordBroadcastAccepted
+// until ctx is done: after the delay the checker tells whether each one became visible, counting
+// it in BroadcastsConfirmedTotal, or in BroadcastBlackholeSuspected of the accepting upstream when
+// it did not. Checks run one at a time, each at most the delay after it became due. Without it
+// RecordBroadcastAccepted does nothing.
+func (t *Tracker) EnableBroadcastChecks(ctx context.Context, cfg BroadcastChecksConfig) {
+	if cfg.Checker == nil {
+		return
+	}
+	if cfg.Delay <= 0 {
+		cfg.Delay = defaultBroadcastCheckDelay
+	}
+	if cfg.MaxPending <= 0 {
+		cfg.MaxPending = defaultBroadcastMaxPending
+	}
+	bc := &broadcastChecks{cfg: cfg}
+	t.broadcastChecks.Store(bc)
+	go t.broadcastChecksLoop(ctx, bc, t.clock.NewTicker(cfg.Delay))
+}
+
+// RecordBroadcastAccepted schedules checking that a transaction accepted by an upstream becomes
+// visible network-wide, if broadcast checks are enabled.
+func (t *Tracker) RecordBroadcastAccepted(ups, network, txHash string) {
+	bc := t.broadcastChecks.Load()
+	if bc == nil || txHash == "" {
+		return
+	}
+	network = t.canonicalNetwork(network)
+	bc.mu.Lock()
+	full := len(bc.pending) >= bc.cfg.MaxPending
+	if !full {
+		bc.pending = append(bc.pending, pendingBroadcast{ups: ups, network: network, txHash: txHash, dueAt: t.clock.Now().Add(bc.cfg.Delay)})
+	}
+	bc.mu.Unlock()
+	if full {
+		telemetry.MetricUpstreamBroadcastCheckTotal.WithLabelValues(t.projectId, network, ups, t.upstreamVendor(ups, network), "dropped").Inc()
+	}
+}
+
+func (t *Tracker) broadcastChecksLoop(ctx context.Context, bc *broadcastChecks, ticker Ticker) {
+	defer ticker.Stop()
+	defer t.broadcastChecks.CompareAndSwap(bc, nil)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C():
+			for _, p := range bc.takeDue(now) {
+				if ctx.Err() != nil {
+					return
+				}
+				t.checkBroadcast(ctx, bc, p)
+			}
+		}
+	}
+}
+
+// takeDue removes and returns the pending broadcasts due at now.
+func (bc *broadcastChecks) takeDue(now time.Time) []pendingBroadcast {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	n := 0
+	for n < len(bc.pending) && !bc.pending[n].dueAt.After(now) {
+		n++
+	}
+	due := make([]pendingBroadcast, n)
+	copy(due, bc.pending[:n])
+	bc.pending = append(bc.pending[:0], bc.pending[n:]...)
+	return due
+}
+
+func (t *Tracker) checkBroadcast(ctx context.Context, bc *broadcastChecks, p pendingBroadcast) {
+	checkCtx, cancel := context.WithTimeout(ctx, bc.cfg.Delay)
+	visible, err := bc.cfg.Checker(checkCtx, p.network, p.txHash, p.ups)
+	cancel()
+
+	result := "visible"
+	switch {
+	case err != nil:
+		result = "error"
+		t.logger.Debug().Err(err).Str("upstream", p.ups).Str("network", p.network).Str("txHash", p.txHash).Msg("could not check transaction broadcast")
+	case visible:
+		for _, k := range upstreamWideKeys(p.ups, p.network) {
+			t.getMetrics(k).BroadcastsConfirmedTotal.Add(1)
+		}
+	default:
+		result = "blackhole_suspected"
+		for _, k := range upstreamWideKeys(p.ups, p.network) {
+			t.getMetrics(k).BroadcastBlackholeSuspected.Add(1)
+		}
+		t.logger.Warn().Str("upstream", p.ups).Str("network", p.network).Str("txHash", p.txHash).Msg("transaction accepted by upstream is not visible through other upstreams")
+	}
+	telemetry.MetricUpstreamBroadcastCheckTotal.WithLabelValues(t.projectId, p.network, p.ups, t.upstreamVendor(p.ups, p.network), result).Inc()
+}
@@ -0,0 +1,111 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBroadcastChecks(t *testing.T) {
+	networkID := "evm:123"
+	delay := 30 * time.Second
+
+	// checker answers visibility per tx hash and keeps the hashes it was asked about
+	type checker struct {
+		mu      sync.Mutex
+		checked []string
+	}
+	newChecker := func(answers map[string]error, visible map[string]bool) (*checker, health.BroadcastChecker) {
+		c := &checker{}
+		return c, func(ctx context.Context, network, txHash, acceptedBy string) (bool, error) {
+			c.mu.Lock()
+			c.checked = append(c.checked, txHash)
+			c.mu.Unlock()
+			return visible[txHash], answers[txHash]
+		}
+	}
+	checkedCount := func(c *checker) int {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return len(c.checked)
+	}
+	enable := func(t *testing.T, tracker *health.Tracker, cfg health.BroadcastChecksConfig) {
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		tracker.EnableBroadcastChecks(ctx, cfg)
+	}
+
+	t.Run("CountsVisibleAndBlackholed", func(t *testing.T) {
+		tracker, clock := newFakeClockTracker(t, time.Hour)
+		c, check := newChecker(
+			map[string]error{"0xerr": errors.New("no other upstream answered")},
+			map[string]bool{"0xseen": true},
+		)
+		enable(t, tracker, health.BroadcastChecksConfig{Checker: check, Delay: delay})
+
+		tracker.RecordBroadcastAccepted("a", networkID, "0xseen")
+		tracker.RecordBroadcastAccepted("a", networkID, "0xlost")
+		tracker.RecordBroadcastAccepted("b", networkID, "0xerr")
+
+		clock.Advance(delay / 2)
+		assert.Equal(t, 0, checkedCount(c), "not due yet")
+
+		clock.Advance(delay / 2)
+		require.Eventually(t, func() bool {
+			return tracker.GetUpstreamMethodMetrics("a", networkID, "*").BroadcastBlackholeSuspected.Load() == 1
+		}, time.Second, time.Millisecond)
+		require.Eventually(t, func() bool { return checkedCount(c) == 3 }, time.Second, time.Millisecond)
+
+		a := tracker.GetUpstreamMethodMetrics("a", networkID, "*")
+		assert.Equal(t, int64(1), a.BroadcastsConfirmedTotal.Load())
+		b := tracker.GetUpstreamMethodMetrics("b", networkID, "*")
+		assert.Equal(t, int64(0), b.BroadcastsConfirmedTotal.Load()+b.BroadcastBlackholeSuspected.Load(), "errors are not held against the upstream")
+		assert.Equal(t, int64(1), tracker.GetNetworkMethodMetrics(networkID, "*").BroadcastBlackholeSuspected.Load())
+
+		raw, err := a.MarshalJSON()
+		require.NoError(t, err)
+		assert.Contains(t, string(raw), `"broadcastBlackholeSuspected":1`)
+	})
+
+	t.Run("PendingChecksAreBounded", func(t *testing.T) {
+		tracker, clock := newFakeClockTracker(t, time.Hour)
+		c, check := newChecker(nil, nil)
+		enable(t, tracker, health.BroadcastChecksConfig{Checker: check, Delay: delay, MaxPending: 2})
+
+		tracker.RecordBroadcastAccepted("a", networkID, "0x1")
+		tracker.RecordBroadcastAccepted("a", networkID, "0x2")
+		tracker.RecordBroadcastAccepted("a", networkID, "0x3")
+
+		clock.Advance(delay)
+		require.Eventually(t, func() bool {
+			return tracker.GetUpstreamMethodMetrics("a", networkID, "*").BroadcastBlackholeSuspected.Load() == 2
+		}, time.Second, time.Millisecond)
+		assert.Equal(t, []string{"0x1", "0x2"}, c.checked)
+
+		// Room is made once checked
+		tracker.RecordBroadcastAccepted("a", networkID, "0x4")
+		clock.Advance(delay)
+		require.Eventually(t, func() bool { return checkedCount(c) == 3 }, time.Second, time.Millisecond)
+	})
+
+	t.Run("DisabledByDefault", func(t *testing.T) {
+		tracker, clock := newFakeClockTracker(t, time.Hour)
+		tracker.RecordBroadcastAccepted("a", networkID, "0x1")
+		clock.Advance(delay)
+
+		m := tracker.GetUpstreamMethodMetrics("a", networkID, "*")
+		assert.Equal(t, int64(0), m.BroadcastsConfirmedTotal.Load()+m.BroadcastBlackholeSuspected.Load())
+
+		// Without a checker there is nothing to enable
+		tracker.EnableBroadcastChecks(context.Background(), health.BroadcastChecksConfig{})
+		tracker.RecordBroadcastAccepted("a", networkID, "0x1")
+		clock.Advance(delay)
+		assert.Equal(t, int64(0), m.BroadcastBlackholeSuspected.Load())
+	})
+}
@@ -0,0 +1,212 @@
+package health
+
+import (
+	"fmt"
+	"math"
+	"sync/atomic"
+
+	"github.com/erpc/erpc/telemetry"
+)
+
+const (
+	// burnedBudgetPenalty is the score multiplier of an upstream which burned its error budget.
+	burnedBudgetPenalty = 0.01
+
+	// CordonReasonErrorBudgetExhausted is the reason of cordons applied by ErrorBudgetConfig.Cordon.
+	CordonReasonErrorBudgetExhausted = "ErrorBudgetExhausted"
+
+	EventErrorBudgetExhausted EventType = "errorBudgetExhausted"
+)
+
+// ErrorBudgetConfig is the number of errors an upstream may return on a network per window, and
+// what happens once it returned more.
+type ErrorBudgetConfig struct {
+	// Fraction of the requests of the window allowed to fail, within (0, 1], e.g. 0.005 for 0.5%
+	Fraction float64
+	// MinErrors is the floor of the budget, so that a few errors of an upstream with little traffic
+	// do not exhaust it
+	MinErrors int64
+	// Penalize sharply deprioritizes the upstream in EffectiveWeight until the next window reset
+	Penalize bool
+	// Cordon cordons the upstream on the network for the rest of the window
+	Cordon bool
+}
+
+// ErrorBudgetStatus is the error budget of an upstream on a network in the current window.
+type ErrorBudgetStatus struct {
+	// Allowed are the errors allowed so far, scaling with the requests of the window
+	Allowed float64 `json:"allowed"`
+	// Consumed are the errors of the window
+	Consumed int64 `json:"consumed"`
+	// Remaining are the errors left before exhaustion, zero once exhausted
+	Remaining float64 `json:"remaining"`
+	// Exhausted tells the errors reached the allowed ones
+	Exhausted bool `json:"exhausted"`
+}
+
+type errorBudget struct {
+	cfg ErrorBudgetConfig
+	// exhausted tells the budget was exhausted within the window, so that its event is emitted
+	// and the upstream cordoned once per window
+	exhausted atomic.Bool
+}
+
+// SetErrorBudget allows an upstream to fail a fraction of its requests on a network (e.g. 0.01
+// for 1%) per window. Once the budget is burned the upstream is sharply deprioritized by
+// EffectiveWeight until the next window reset. A non-positive fraction removes the budget.
+// See SetErrorBudgetConfig for a floor and other actions.
+func (t *Tracker) SetErrorBudget(ups, network string, fraction float64) {
+	if fraction <= 0 {
+		_ = t.SetErrorBudgetConfig(ups, network, nil)
+		return
+	}
+	_ = t.SetErrorBudgetConfig(ups, network, &ErrorBudgetConfig{Fraction: math.Min(fraction, 1), Penalize: true})
+}
+
+// SetErrorBudgetConfig sets the error budget of an upstream on a network, nil removes it. Once
+// exhausted within a window EventErrorBudgetExhausted is emitted and the actions of the config
+// apply until the next window reset, which restores the budget.
+func (t *Tracker) SetErrorBudgetConfig(ups, network string, cfg *ErrorBudgetConfig) error {
+	network = t.canonicalNetwork(network)
+	k := duoKey{ups: ups, network: network}
+	if cfg == nil {
+		t.errorBudgets.Delete(k)
+		telemetry.MetricUpstreamErrorBudgetRemaining.DeleteLabelValues(t.projectId, network, ups, t.upstreamVendor(ups, network))
+		return nil
+	}
+	if cfg.Fraction <= 0 || cfg.Fraction > 1 {
+		return fmt.Errorf("error budget fraction of upstream %s on network %s must be within (0, 1], got %v", ups, network, cfg.Fraction)
+	}
+	if cfg.MinErrors < 0 {
+		return fmt.Errorf("error budget floor of upstream %s on network %s cannot be negative, got %d", ups, network, cfg.MinErrors)
+	}
+	t.errorBudgets.Store(k, &errorBudget{cfg: *cfg})
+	t.checkErrorBudget(ups, network)
+	return nil
+}
+
+// ErrorBudgetRemaining returns the fraction (0 to 1) of the error budget of an upstream left in
+// the current window for a method, or "*" for all methods. It is 1 when no budget is set.
+func (t *Tracker) ErrorBudgetRemaining(ups, network, method string) float64 {
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
+	return t.errorBudgetRemaining(ups, network, method)
+}
+
+// GetErrorBudget returns the error budget of an upstream on a network across all methods, false
+// when it has none.
+func (t *Tracker) GetErrorBudget(ups, network string) (ErrorBudgetStatus, bool) {
+	network = t.canonicalNetwork(network)
+	return t.errorBudgetStatusOf(ups, network)
+}
+
+func (t *Tracker) errorBudgetOf(ups, network string) *errorBudget {
+	if val, ok := t.errorBudgets.Load(duoKey{ups: ups, network: network}); ok {
+		return val.(*errorBudget)
+	}
+	return nil
+}
+
+// status computes the status of a budget from the counters of a key.
+func (b *errorBudget) status(m *TrackedMetrics) ErrorBudgetStatus {
+	errors, requests := m.errorsAndRequests()
+	allowed := math.Max(b.cfg.Fraction*float64(requests), float64(b.cfg.MinErrors))
+	return ErrorBudgetStatus{
+		Allowed:   allowed,
+		Consumed:  errors,
+		Remaining: math.Max(allowed-float64(errors), 0),
+		Exhausted: errors > 0 && float64(errors) >= allowed,
+	}
+}
+
+func (t *Tracker) errorBudgetStatusOf(ups, network string) (ErrorBudgetStatus, bool) {
+	b := t.errorBudgetOf(ups, network)
+	if b == nil {
+		return ErrorBudgetStatus{}, false
+	}
+	s := ErrorBudgetStatus{Allowed: float64(b.cfg.MinErrors), Remaining: float64(b.cfg.MinErrors)}
+	if val, ok := t.metrics.Load(tripletKey{ups, network, "*"}); ok {
+		s = b.status(val.(*TrackedMetrics))
+	}
+	return s, true
+}
+
+func (t *Tracker) errorBudgetRemaining(ups, network, method string) float64 {
+	b := t.errorBudgetOf(ups, network)
+	if b == nil {
+		return 1
+	}
+	val, ok := t.metrics.Load(tripletKey{ups, network, method})
+	if !ok {
+		return 1
+	}
+	s := b.status(val.(*TrackedMetrics))
+	if s.Consumed == 0 {
+		return 1
+	}
+	if s.Exhausted {
+		return 0
+	}
+	return s.Remaining / s.Allowed
+}
+
+// errorBudgetPenalty returns the score multiplier of an upstream on a network, based on its
+// budget across all methods.
+func (t *Tracker) errorBudgetPenalty(ups, network string) float64 {
+	b := t.errorBudgetOf(ups, network)
+	if b == nil || !b.cfg.Penalize {
+		return 1
+	}
+	if t.errorBudgetRemaining(ups, network, "*") <= 0 {
+		return burnedBudgetPenalty
+	}
+	return 1
+}
+
+// checkErrorBudget exports the remaining error budget of an upstream on a network after an error,
+// and applies the actions of the budget once exhausted within the window. Network must already be
+// canonical.
+func (t *Tracker) checkErrorBudget(ups, network string) {
+	b := t.errorBudgetOf(ups, network)
+	if b == nil {
+		return
+	}
+	s, _ := t.errorBudgetStatusOf(ups, network)
+	t.exportTelemetry(telemetryEvent{kind: telemetryErrorBudgetRemaining, network: network, ups: ups, vendor: t.upstreamVendor(ups, network), value: s.Remaining})
+	if !s.Exhausted || !b.exhausted.CompareAndSwap(false, true) {
+		return
+	}
+
+	detail := fmt.Sprintf("%d errors exhausted a budget of %.1f errors", s.Consumed, s.Allowed)
+	t.logger.Warn().Str("upstream", ups).Str("network", network).
+		Int64("errors", s.Consumed).Float64("allowed", s.Allowed).
+		Msg("upstream exhausted its error budget for the window")
+	t.emit(Event{
+		Type:      EventErrorBudgetExhausted,
+		Upstream:  ups,
+		Network:   network,
+		Message:   detail,
+		Value:     float64(s.Consumed),
+		Threshold: s.Allowed,
+	})
+	if b.cfg.Cordon {
+		t.autoCordonWithInfo(ups, network, "*", CordonInfo{
+			Reason: CordonReasonErrorBudgetExhausted,
+			Detail: detail,
+			Source: CordonSourceTracker,
+		})
+	}
+}
+
+// restoreErrorBudgets restores the budgets exhausted in the closing window, it must run right
+// after metrics are reset, which lifts the cordons of exhausted budgets.
+func (t *Tracker) restoreErrorBudgets() {
+	t.errorBudgets.Range(func(key, value any) bool {
+		k := key.(duoKey)
+		b := value.(*errorBudget)
+		b.exhausted.Store(false)
+		// Queued like the updates of checkErrorBudget, so that none of them overwrites it
+		t.exportTelemetry(telemetryEvent{kind: telemetryErrorBudgetRemaining, network: k.network, ups: k.ups, vendor: t.upstreamVendor(k.ups, k.network), value: float64(b.cfg.MinErrors)})
+		return true
+	})
+}
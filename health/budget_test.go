@@ -0,0 +1,55 @@
+package health_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health/healthtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorBudget(t *testing.T) {
+	networkID := "evm:123"
+
+	t.Run("BurnedByErrorsAndDeprioritized", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		tracker.SetErrorBudget("a", networkID, 0.1)
+		recordRequests(tracker, networkID, "a", "eth_call", 100, 5)
+
+		assert.InDelta(t, 0.5, tracker.ErrorBudgetRemaining("a", networkID, "eth_call"), 1e-9)
+		assert.InDelta(t, 0.5, tracker.ErrorBudgetRemaining("a", networkID, "*"), 1e-9)
+		assert.Equal(t, 10.0, tracker.EffectiveWeight("a", networkID, 10))
+
+		for i := 0; i < 5; i++ {
+			tracker.RecordUpstreamFailure("a", networkID, "eth_call")
+		}
+		assert.Equal(t, 0.0, tracker.ErrorBudgetRemaining("a", networkID, "eth_call"))
+		assert.Less(t, tracker.EffectiveWeight("a", networkID, 10), 0.2)
+
+		// Further errors keep the remaining budget at zero
+		tracker.RecordUpstreamFailure("a", networkID, "eth_call")
+		assert.Equal(t, 0.0, tracker.ErrorBudgetRemaining("a", networkID, "eth_call"))
+	})
+
+	t.Run("RestoredByWindowReset", func(t *testing.T) {
+		tracker, clock := newFakeClockTracker(t, time.Minute)
+		tracker.SetErrorBudget("a", networkID, 0.01)
+		recordRequests(tracker, networkID, "a", "eth_call", 10, 1)
+		assert.Equal(t, 0.0, tracker.ErrorBudgetRemaining("a", networkID, "eth_call"))
+
+		healthtest.AdvanceWindow(t, clock, time.Minute, tracker.GetUpstreamMethodMetrics("a", networkID, "eth_call"))
+		assert.Equal(t, 1.0, tracker.ErrorBudgetRemaining("a", networkID, "eth_call"))
+		assert.Equal(t, 10.0, tracker.EffectiveWeight("a", networkID, 10))
+	})
+
+	t.Run("UnlimitedWithoutBudget", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		recordRequests(tracker, networkID, "a", "eth_call", 10, 10)
+		assert.Equal(t, 1.0, tracker.ErrorBudgetRemaining("a", networkID, "eth_call"))
+
+		tracker.SetErrorBudget("a", networkID, 0.5)
+		assert.Equal(t, 0.0, tracker.ErrorBudgetRemaining("a", networkID, "eth_call"))
+		tracker.SetErrorBudget("a", networkID, 0)
+		assert.Equal(t, 1.0, tracker.ErrorBudgetRemaining("a", networkID, "eth_call"))
+	})
+}
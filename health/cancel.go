@@ -0,0 +1,85 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/erpc/erpc/telemetry"
+)
+
+// CancelCause tells why an in-flight upstream request was cancelled before completing.
+type CancelCause string
+
+const (
+	// CancelCauseClient is a client disconnecting or giving up on the request.
+	CancelCauseClient CancelCause = "client"
+	// CancelCauseDeadline is one of our own deadlines or timeout policies firing.
+	CancelCauseDeadline CancelCause = "deadline"
+	// CancelCauseHedge is a hedged attempt losing the race against another attempt.
+	CancelCauseHedge CancelCause = "hedge"
+)
+
+// CancelCauseOf classifies why ctx is done: a deadline when it (or a parent) exceeded its
+// deadline, the client otherwise. Cancellations by hedging cannot be told apart from the context
+// alone and must be reported by the caller launching the hedges.
+func CancelCauseOf(ctx context.Context) CancelCause {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) || errors.Is(context.Cause(ctx), context.DeadlineExceeded) {
+		return CancelCauseDeadline
+	}
+	return CancelCauseClient
+}
+
+// SetCancellationsAsErrors makes cancellations with the given causes count as failures in
+// ErrorsTotal (and therefore ErrorRate), on top of their own counters. By default no
+// cancellation is considered an upstream failure.
+func (t *Tracker) SetCancellationsAsErrors(causes ...CancelCause) {
+	set := make(map[CancelCause]bool, len(causes))
+	for _, c := range causes {
+		set[c] = true
+	}
+	t.cancelsAsErrors.Store(&set)
+}
+
+func (t *Tracker) cancellationIsError(cause CancelCause) bool {
+	set := t.cancelsAsErrors.Load()
+	return set != nil && (*set)[cause]
+}
+
+// RecordUpstreamCancelled records a request towards an upstream that was cancelled after elapsed,
+// instead of a duration and a failure which would penalize an upstream that may have been fine.
+// Lost hedges are accounted separately by RecordUpstreamHedgeCancelled.
+func (t *Tracker) RecordUpstreamCancelled(ups, network, method string, cause CancelCause, elapsed time.Duration) {
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
+	asError := t.cancellationIsError(cause)
+	now, tau := t.clock.Now(), t.rateTimeConstant()
+	for _, k := range t.getKeys(ups, network, method) {
+		m := t.getMetrics(k)
+		switch cause {
+		case CancelCauseClient:
+			m.CancelledByClientTotal.Add(1)
+		case CancelCauseDeadline:
+			m.CancelledByDeadlineTotal.Add(1)
+		case CancelCauseHedge:
+			m.CancelledByHedgeTotal.Add(1)
+		}
+		if asError {
+			m.ErrorsTotal.Add(1)
+			m.lifetime.errors.Add(1)
+			m.errRate.observe(now, tau)
+			if cause == CancelCauseClient {
+				m.clientCancelErrors.Add(1)
+			}
+		}
+	}
+	telemetry.MetricUpstreamCancelledTotal.WithLabelValues(t.projectId, network, ups, t.upstreamVendor(ups, network), method, string(cause)).Inc()
+	t.logger.Trace().Str("upstream", ups).Str("network", network).Str("method", method).
+		Str("cause", string(cause)).Dur("elapsed", elapsed).Msg("upstream request cancelled")
+}
+
+// ObserveCancelled records that the timed request was cancelled, in place of ObserveDuration.
+func (t *Timer) ObserveCancelled(cause CancelCause) {
+	t.endInFlight()
+	t.tracker.RecordUpstreamCancelled(t.ups, t.network, t.method, cause, t.clock.Now().Sub(t.start))
+}
@@ -0,0 +1,67 @@
+package health_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health"
+	"github.com/erpc/erpc/health/healthtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCancellations(t *testing.T) {
+	networkID := "evm:123"
+
+	t.Run("CountedByCauseWithoutAffectingErrorRate", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		recordRequests(tracker, networkID, "a", "eth_call", 4, 1)
+		for _, cause := range []health.CancelCause{health.CancelCauseClient, health.CancelCauseClient, health.CancelCauseDeadline, health.CancelCauseHedge} {
+			tracker.RecordUpstreamRequest("a", networkID, "eth_call")
+			tracker.RecordUpstreamCancelled("a", networkID, "eth_call", cause, 10*time.Millisecond)
+		}
+
+		m := tracker.GetUpstreamMethodMetrics("a", networkID, "eth_call")
+		assert.Equal(t, int64(2), m.CancelledByClientTotal.Load())
+		assert.Equal(t, int64(1), m.CancelledByDeadlineTotal.Load())
+		assert.Equal(t, int64(1), m.CancelledByHedgeTotal.Load())
+		assert.Equal(t, int64(1), m.ErrorsTotal.Load())
+	})
+
+	t.Run("OptionallyCountedAsErrors", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		tracker.SetCancellationsAsErrors(health.CancelCauseDeadline)
+		recordRequests(tracker, networkID, "a", "eth_call", 2, 0)
+		tracker.RecordUpstreamCancelled("a", networkID, "eth_call", health.CancelCauseDeadline, time.Second)
+		tracker.RecordUpstreamCancelled("a", networkID, "eth_call", health.CancelCauseClient, time.Second)
+
+		m := tracker.GetUpstreamMethodMetrics("a", networkID, "eth_call")
+		assert.Equal(t, int64(1), m.ErrorsTotal.Load())
+		assert.Equal(t, 0.5, m.ErrorRate())
+	})
+
+	t.Run("TimerReportsElapsedTime", func(t *testing.T) {
+		clock := healthtest.NewFakeClock(time.Unix(1700000000, 0))
+		r := healthtest.NewRecorder(nil)
+		r.SetClock(clock)
+		timer := r.RecordUpstreamDurationStart("a", networkID, "eth_call", "none")
+		clock.Advance(250 * time.Millisecond)
+		timer.ObserveCancelled(health.CancelCauseClient)
+
+		calls := r.CallsTo("RecordUpstreamCancelled")
+		require.Len(t, calls, 1)
+		assert.Equal(t, []interface{}{"a", networkID, "eth_call", health.CancelCauseClient, 250 * time.Millisecond}, calls[0].Args)
+		assert.Empty(t, r.CallsTo("RecordUpstreamFinalityDuration"))
+	})
+
+	t.Run("CauseOfContext", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		assert.Equal(t, health.CancelCauseClient, health.CancelCauseOf(ctx))
+
+		ctx, cancel = context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+		assert.Equal(t, health.CancelCauseDeadline, health.CancelCauseOf(ctx))
+	})
+}
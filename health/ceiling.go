@@ -0,0 +1,24 @@
+package health
+
+import (
+	"github.com/erpc/erpc/telemetry"
+)
+
+// SetBlockNumberCeiling rejects latest and finalized block numbers above max, so that an upstream
+// reporting an absurd value cannot poison the network highest block (and thus every other
+// upstream's lag). Zero disables the ceiling, which is the default.
+func (t *Tracker) SetBlockNumberCeiling(max int64) {
+	t.blockNumberCeiling.Store(max)
+}
+
+// exceedsBlockNumberCeiling reports whether a block number must be rejected, counting and
+// logging the rejection.
+func (t *Tracker) exceedsBlockNumberCeiling(ups, network, kind string, blockNumber int64) bool {
+	ceiling := t.blockNumberCeiling.Load()
+	if ceiling <= 0 || blockNumber <= ceiling {
+		return false
+	}
+	t.logger.Warn().Str("upstreamId", ups).Str("networkId", network).Str("kind", kind).Int64("value", blockNumber).Int64("ceiling", ceiling).Msg("ignoring block number above the sanity ceiling in tracker")
+	telemetry.MetricUpstreamBlockNumberRejectedTotal.WithLabelValues(t.projectId, network, ups, t.upstreamVendor(ups, network), kind).Inc()
+	return true
+}
@@ -0,0 +1,59 @@
+package health_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health"
+	"github.com/erpc/erpc/health/internal/metricstest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockNumberCeiling(t *testing.T) {
+	networkID := "evm:123"
+
+	setup := func(t *testing.T, ceiling int64) *health.Tracker {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		tracker.SetBlockNumberCeiling(ceiling)
+		tracker.RecordUpstreamRequest("b", networkID, "eth_call")
+		tracker.SetLatestBlockNumber("a", networkID, 100)
+		tracker.SetLatestBlockNumber("b", networkID, 90)
+		tracker.SetFinalizedBlockNumber("a", networkID, 80)
+		tracker.SetFinalizedBlockNumber("b", networkID, 75)
+		return tracker
+	}
+
+	t.Run("AbsurdValueIsRejected", func(t *testing.T) {
+		tracker := setup(t, 1_000_000_000)
+		latestBefore := rejectedCount(t, networkID, "a", "latest")
+		finalizedBefore := rejectedCount(t, networkID, "a", "finalized")
+
+		tracker.SetLatestBlockNumber("a", networkID, math.MaxInt64-1)
+		tracker.SetFinalizedBlockNumber("a", networkID, math.MaxInt64-1)
+
+		m := tracker.GetUpstreamMethodMetrics("b", networkID, "eth_call")
+		assert.Equal(t, int64(10), m.BlockHeadLag.Load())
+		assert.Equal(t, int64(5), m.FinalizationLag.Load())
+		assert.Equal(t, float64(1), rejectedCount(t, networkID, "a", "latest")-latestBefore)
+		assert.Equal(t, float64(1), rejectedCount(t, networkID, "a", "finalized")-finalizedBefore)
+
+		// Values under the ceiling are still accepted
+		tracker.SetLatestBlockNumber("a", networkID, 110)
+		assert.Equal(t, int64(20), m.BlockHeadLag.Load())
+	})
+
+	t.Run("DisabledByDefault", func(t *testing.T) {
+		tracker := setup(t, 0)
+		tracker.SetLatestBlockNumber("a", networkID, math.MaxInt64-1)
+
+		m := tracker.GetUpstreamMethodMetrics("b", networkID, "eth_call")
+		assert.Equal(t, int64(math.MaxInt64-1-90), m.BlockHeadLag.Load())
+	})
+}
+
+func rejectedCount(t *testing.T, network, ups, kind string) float64 {
+	return metricstest.Value(t, "erpc_upstream_block_number_rejected_total", map[string]string{
+		"project": "test-project", "network": network, "upstream": ups, "kind": kind,
+	})
+}
@@ -0,0 +1,100 @@
+package health
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/erpc/erpc/telemetry"
+)
+
+const (
+	// DefaultCertificateWarningDays is the days left before the expiry of the certificate of an
+	// upstream below which EventCertificateExpiring is emitted, see RecordCertificateExpiry.
+	DefaultCertificateWarningDays = 14
+
+	EventCertificateExpiring EventType = "certificateExpiring"
+)
+
+// CertificateExpiry is the expiry of the TLS certificate chain of an upstream, as last probed.
+type CertificateExpiry struct {
+	// Known is false until a probe succeeded and after a probe failed, in which case the other
+	// fields tell nothing about the certificate
+	Known bool `json:"known"`
+	// NotAfter is the earliest expiry among the certificates of the chain
+	NotAfter time.Time `json:"notAfter"`
+	// DaysLeft are the days until NotAfter at the last probe, negative once expired
+	DaysLeft float64 `json:"daysLeft"`
+	// CheckedAt is the time of the last probe, successful or not
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// RecordCertificateExpiry records the expiry of the certificate chain of an upstream on a network
+// as found by a probe. EventCertificateExpiring is emitted once when fewer than warningDays are
+// left, and again if a later probe still finds them fewer after a renewal went above. A
+// non-positive warningDays uses DefaultCertificateWarningDays.
+func (t *Tracker) RecordCertificateExpiry(ups, network string, notAfter time.Time, warningDays float64) {
+	network = t.canonicalNetwork(network)
+	if warningDays <= 0 {
+		warningDays = DefaultCertificateWarningDays
+	}
+	now := t.clock.Now()
+	daysLeft := notAfter.Sub(now).Hours() / 24
+
+	md := t.getMetadata(duoKey{ups: ups, network: network})
+	md.certNotAfter.Store(notAfter.UnixNano())
+	md.certCheckedAt.Store(now.UnixNano())
+	telemetry.MetricUpstreamCertificateExpiryDays.WithLabelValues(t.projectId, network, ups, t.upstreamVendor(ups, network)).Set(daysLeft)
+
+	if daysLeft >= warningDays {
+		md.certWarned.Store(false)
+		return
+	}
+	if md.certWarned.Swap(true) {
+		return
+	}
+	t.emit(Event{
+		Type:      EventCertificateExpiring,
+		Upstream:  ups,
+		Network:   network,
+		Message:   fmt.Sprintf("certificate expires at %s, in %.1f days", notAfter.UTC().Format(time.RFC3339), daysLeft),
+		Value:     daysLeft,
+		Threshold: warningDays,
+	})
+}
+
+// RecordCertificateProbeFailure records that the certificate chain of an upstream could not be
+// probed, which makes its expiry unknown rather than zero days, both in CertificateExpiry and in
+// the exported gauge whose series is removed.
+func (t *Tracker) RecordCertificateProbeFailure(ups, network string, err error) {
+	network = t.canonicalNetwork(network)
+	md := t.getMetadata(duoKey{ups: ups, network: network})
+	md.certNotAfter.Store(0)
+	md.certCheckedAt.Store(t.clock.Now().UnixNano())
+	telemetry.MetricUpstreamCertificateExpiryDays.DeleteLabelValues(t.projectId, network, ups, t.upstreamVendor(ups, network))
+	t.logger.Debug().Err(err).Str("upstreamId", ups).Str("networkId", network).Msg("could not probe certificate of upstream")
+}
+
+// GetCertificateExpiry returns the expiry of the certificate chain of an upstream as last probed.
+func (t *Tracker) GetCertificateExpiry(ups, network string) CertificateExpiry {
+	network = t.canonicalNetwork(network)
+	val, ok := t.metadata.Load(duoKey{ups: ups, network: network})
+	if !ok {
+		return CertificateExpiry{}
+	}
+	return t.certificateExpiryOf(val.(*NetworkMetadata))
+}
+
+func (t *Tracker) certificateExpiryOf(md *NetworkMetadata) CertificateExpiry {
+	var c CertificateExpiry
+	if checkedAt := md.certCheckedAt.Load(); checkedAt != 0 {
+		c.CheckedAt = time.Unix(0, checkedAt)
+	}
+	notAfter := md.certNotAfter.Load()
+	if notAfter == 0 {
+		return c
+	}
+	c.Known = true
+	c.NotAfter = time.Unix(0, notAfter)
+	c.DaysLeft = c.NotAfter.Sub(c.CheckedAt).Hours() / 24
+	return c
+}
@@ -0,0 +1,55 @@
+package health_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health"
+	"github.com/erpc/erpc/health/internal/metricstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCertificateExpiry(t *testing.T) {
+	networkID := "evm:123"
+	labels := map[string]string{"project": "test-project", "network": networkID, "upstream": "cert-a"}
+
+	tracker, clock := newFakeClockTracker(t, time.Minute)
+	events, unsubscribe := tracker.Subscribe(4)
+	defer unsubscribe()
+
+	assert.False(t, tracker.GetCertificateExpiry("cert-a", networkID).Known)
+
+	tracker.RecordUpstreamRequest("cert-a", networkID, "eth_call")
+	tracker.RecordCertificateExpiry("cert-a", networkID, clock.Now().Add(30*24*time.Hour), 0)
+	c := tracker.GetCertificateExpiry("cert-a", networkID)
+	require.True(t, c.Known)
+	assert.InDelta(t, 30, c.DaysLeft, 0.01)
+	assert.InDelta(t, 30, metricstest.Value(t, "erpc_upstream_certificate_expiry_days", labels), 0.01)
+	assert.InDelta(t, 30, tracker.GetNetworkUpstreamsMetrics(networkID, "*")["cert-a"].Certificate.DaysLeft, 0.01)
+	assert.Empty(t, events, "above the default warning threshold")
+
+	// Warns once when crossing below the threshold
+	tracker.RecordCertificateExpiry("cert-a", networkID, clock.Now().Add(10*24*time.Hour), 0)
+	tracker.RecordCertificateExpiry("cert-a", networkID, clock.Now().Add(9*24*time.Hour), 0)
+	require.Len(t, events, 1)
+	e := <-events
+	assert.Equal(t, health.EventCertificateExpiring, e.Type)
+	assert.Equal(t, "cert-a", e.Upstream)
+	assert.InDelta(t, 10, e.Value, 0.01)
+	assert.Equal(t, float64(health.DefaultCertificateWarningDays), e.Threshold)
+
+	// A renewal re-arms the warning
+	tracker.RecordCertificateExpiry("cert-a", networkID, clock.Now().Add(90*24*time.Hour), 0)
+	tracker.RecordCertificateExpiry("cert-a", networkID, clock.Now().Add(40*24*time.Hour), 60)
+	assert.Len(t, events, 1)
+
+	// Failed probes report an unknown expiry, not zero days
+	tracker.RecordCertificateProbeFailure("cert-a", networkID, errors.New("connection refused"))
+	c = tracker.GetCertificateExpiry("cert-a", networkID)
+	assert.False(t, c.Known)
+	assert.False(t, c.CheckedAt.IsZero())
+	assert.Empty(t, metricstest.Samples(t, "erpc_upstream_certificate_expiry_days", labels))
+	assert.False(t, tracker.GetNetworkUpstreamsMetrics(networkID, "*")["cert-a"].Certificate.Known)
+}
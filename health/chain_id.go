@@ -0,0 +1,161 @@
+package health
+
+import "fmt"
+
+const (
+	// CordonReasonChainIdMismatch is the reason of cordons applied by RecordUpstreamChainId.
+	CordonReasonChainIdMismatch = "chain id mismatch"
+
+	EventChainIdMismatch EventType = "chainIdMismatch"
+)
+
+// ChainIdMismatchPenalty is what happens to an upstream reporting another chain id than the one
+// expected on its network, see SetChainIdMismatchPenalty.
+type ChainIdMismatchPenalty int32
+
+const (
+	// ChainIdMismatchCordon cordons the upstream on the network until it reports the expected
+	// chain id again. The cordon is reapplied at every window reset.
+	ChainIdMismatchCordon ChainIdMismatchPenalty = iota
+	// ChainIdMismatchFlag only emits EventChainIdMismatch and flags the snapshot of the upstream.
+	ChainIdMismatchFlag
+)
+
+// SetChainIdMismatchPenalty sets the penalty of upstreams reporting an unexpected chain id,
+// ChainIdMismatchCordon by default. Switching to ChainIdMismatchFlag lifts the cordons applied.
+func (t *Tracker) SetChainIdMismatchPenalty(p ChainIdMismatchPenalty) {
+	t.chainIdMismatchPenalty.Store(int32(p))
+	t.reevaluateChainIds("")
+}
+
+// SetExpectedChainId sets the chain id the upstreams of a network must report, zero forgets it.
+// Chain ids already reported are evaluated against it right away.
+func (t *Tracker) SetExpectedChainId(network string, chainId int64) {
+	network = t.canonicalNetwork(network)
+	if chainId == 0 {
+		t.expectedChainIds.Delete(network)
+	} else {
+		t.expectedChainIds.Store(network, chainId)
+	}
+	t.reevaluateChainIds(network)
+}
+
+// RecordUpstreamChainId records the chain id reported by an upstream on a network (e.g. from
+// eth_chainId). When it differs from the expected one, EventChainIdMismatch is emitted and the
+// penalty set by SetChainIdMismatchPenalty applies, until the expected chain id is reported again.
+func (t *Tracker) RecordUpstreamChainId(ups, network string, chainId int64) {
+	network = t.canonicalNetwork(network)
+	t.getMetadata(duoKey{ups: ups, network: network}).chainId.Store(chainId)
+	t.evaluateChainId(ups, network, chainId)
+}
+
+// waiveChainIdCordon keeps an upstream lifted by an operator from being cordoned again for its
+// chain id mismatch, until it reports the expected chain id again.
+func (t *Tracker) waiveChainIdCordon(ups, network string) {
+	val, ok := t.metadata.Load(duoKey{ups: ups, network: network})
+	if !ok {
+		return
+	}
+	if md := val.(*NetworkMetadata); md.chainIdMismatch.Load() {
+		md.chainIdCordonWaived.Store(true)
+	}
+}
+
+// ChainIdMismatch tells whether the last chain id reported by an upstream on a network differs
+// from the expected one, along with the reported chain id (zero when never reported).
+func (t *Tracker) ChainIdMismatch(ups, network string) (bool, int64) {
+	network = t.canonicalNetwork(network)
+	return t.chainIdMismatchOf(ups, network)
+}
+
+func (t *Tracker) chainIdMismatchOf(ups, network string) (bool, int64) {
+	val, ok := t.metadata.Load(duoKey{ups: ups, network: network})
+	if !ok {
+		return false, 0
+	}
+	md := val.(*NetworkMetadata)
+	return md.chainIdMismatch.Load(), md.chainId.Load()
+}
+
+// evaluateChainId compares a reported chain id with the expected one of the network, applying
+// or lifting the penalty on transitions. Network must already be canonical.
+func (t *Tracker) evaluateChainId(ups, network string, chainId int64) {
+	md := t.getMetadata(duoKey{ups: ups, network: network})
+	var expected int64
+	if val, ok := t.expectedChainIds.Load(network); ok {
+		expected = val.(int64)
+	}
+	mismatch := expected != 0 && chainId != 0 && chainId != expected
+	wasMismatch := md.chainIdMismatch.Swap(mismatch)
+	if !mismatch {
+		md.chainIdCordonWaived.Store(false)
+	}
+
+	cordon := mismatch && !md.chainIdCordonWaived.Load() &&
+		ChainIdMismatchPenalty(t.chainIdMismatchPenalty.Load()) == ChainIdMismatchCordon
+	if cordon {
+		// An upstream of another chain serves wrong data, so neither the guard nor the eligible
+		// floor may keep it in rotation
+		t.forceAutoCordonWithInfo(ups, network, "*", CordonInfo{
+			Reason: CordonReasonChainIdMismatch,
+			Detail: fmt.Sprintf("reported chain id %d, expected %d", chainId, expected),
+			Source: CordonSourceTracker,
+		})
+	} else {
+		t.liftChainIdCordon(ups, network)
+	}
+
+	if mismatch && !wasMismatch {
+		t.logger.Warn().Str("upstream", ups).Str("network", network).
+			Int64("chainId", chainId).Int64("expectedChainId", expected).
+			Msg("upstream reports an unexpected chain id")
+		t.emit(Event{
+			Type:     EventChainIdMismatch,
+			Upstream: ups,
+			Network:  network,
+			Message:  fmt.Sprintf("reported chain id %d, expected %d", chainId, expected),
+			Value:    float64(chainId),
+		})
+	}
+}
+
+// liftChainIdCordon uncordons an upstream on a network if it is cordoned for a chain id mismatch.
+func (t *Tracker) liftChainIdCordon(ups, network string) {
+	val, ok := t.metrics.Load(tripletKey{ups, network, "*"})
+	if !ok {
+		return
+	}
+	if info := val.(*TrackedMetrics).CordonInfo(); info != nil && info.Reason == CordonReasonChainIdMismatch {
+		t.UncordonWithSource(ups, network, "*", CordonSourceTracker)
+	}
+}
+
+// reevaluateChainIds evaluates again the chain ids reported on a network, or on every network
+// when empty, e.g. after the expected chain id changed.
+func (t *Tracker) reevaluateChainIds(network string) {
+	t.metadata.Range(func(key, value any) bool {
+		k := key.(duoKey)
+		if k.ups == "*" || (network != "" && k.network != network) {
+			return true
+		}
+		if chainId := value.(*NetworkMetadata).chainId.Load(); chainId != 0 {
+			t.evaluateChainId(k.ups, k.network, chainId)
+		}
+		return true
+	})
+}
+
+// reapplyChainIdCordons cordons again the upstreams still reporting an unexpected chain id after
+// the window reset lifted their cordon.
+func (t *Tracker) reapplyChainIdCordons() {
+	if ChainIdMismatchPenalty(t.chainIdMismatchPenalty.Load()) != ChainIdMismatchCordon {
+		return
+	}
+	t.metadata.Range(func(key, value any) bool {
+		k := key.(duoKey)
+		if md := value.(*NetworkMetadata); md.chainIdMismatch.Load() {
+			t.evaluateChainId(k.ups, k.network, md.chainId.Load())
+		}
+		return true
+	})
+}
@@ -0,0 +1,99 @@
+package health_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health"
+	"github.com/erpc/erpc/health/healthtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainIdMismatch(t *testing.T) {
+	networkID := "evm:1"
+
+	t.Run("WrongChainIdCordons", func(t *testing.T) {
+		tracker, clock := newFakeClockTracker(t, time.Minute)
+		events, unsubscribe := tracker.Subscribe(4)
+		defer unsubscribe()
+		tracker.SetExpectedChainId(networkID, 1)
+
+		tracker.RecordUpstreamChainId("good", networkID, 1)
+		assert.False(t, tracker.IsCordoned("good", networkID, "*"))
+		mismatch, _ := tracker.ChainIdMismatch("good", networkID)
+		assert.False(t, mismatch)
+
+		tracker.RecordUpstreamChainId("bad", networkID, 137)
+		tracker.RecordUpstreamChainId("bad", networkID, 137)
+		require.True(t, tracker.IsCordoned("bad", networkID, "*"))
+		info := tracker.GetUpstreamMethodMetrics("bad", networkID, "*").CordonInfo()
+		assert.Equal(t, health.CordonReasonChainIdMismatch, info.Reason)
+		assert.Equal(t, health.CordonSourceTracker, info.Source)
+		s := tracker.GetNetworkUpstreamsMetrics(networkID, "*")["bad"]
+		assert.True(t, s.ChainIdMismatch)
+		assert.Equal(t, int64(137), s.ChainId)
+
+		require.Len(t, events, 1, "emitted once per mismatch")
+		e := <-events
+		assert.Equal(t, health.EventChainIdMismatch, e.Type)
+		assert.Equal(t, "bad", e.Upstream)
+
+		// Survives window resets until the expected chain id is reported again
+		m := tracker.GetUpstreamMethodMetrics("bad", networkID, "*")
+		recordRequests(tracker, networkID, "bad", "eth_chainId", 1, 0)
+		healthtest.AdvanceWindow(t, clock, time.Minute, m)
+		assert.Eventually(t, func() bool { return tracker.IsCordoned("bad", networkID, "*") }, time.Second, time.Millisecond)
+		tracker.RecordUpstreamChainId("bad", networkID, 1)
+		assert.False(t, tracker.IsCordoned("bad", networkID, "*"))
+		recordRequests(tracker, networkID, "bad", "eth_chainId", 1, 0)
+		healthtest.AdvanceWindow(t, clock, time.Minute, m)
+		assert.False(t, tracker.IsCordoned("bad", networkID, "*"))
+	})
+
+	t.Run("ManualCordonsAreKept", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		tracker.SetExpectedChainId(networkID, 1)
+		tracker.Cordon("a", networkID, "*", "maintenance")
+		tracker.RecordUpstreamChainId("a", networkID, 1)
+		assert.True(t, tracker.IsCordoned("a", networkID, "*"))
+	})
+
+	t.Run("BypassesGuardAndFloor", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		tracker.SetMinEligibleUpstreams(2)
+		tracker.SetCordonGuard(func(ups, network, method, reason string) bool { return false })
+		tracker.SetExpectedChainId(networkID, 1)
+		tracker.RecordUpstreamChainId("a", networkID, 1)
+		tracker.RecordUpstreamChainId("b", networkID, 10)
+		require.True(t, tracker.IsCordoned("b", networkID, "*"))
+		assert.Equal(t, health.CordonReasonChainIdMismatch, tracker.GetUpstreamMethodMetrics("b", networkID, "*").CordonInfo().Reason)
+	})
+
+	t.Run("ExpectedChainIdSetLater", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		tracker.RecordUpstreamChainId("a", networkID, 10)
+		assert.False(t, tracker.IsCordoned("a", networkID, "*"), "nothing to compare with")
+
+		tracker.SetExpectedChainId(networkID, 1)
+		assert.True(t, tracker.IsCordoned("a", networkID, "*"))
+		tracker.SetExpectedChainId(networkID, 10)
+		assert.False(t, tracker.IsCordoned("a", networkID, "*"))
+	})
+
+	t.Run("FlagOnly", func(t *testing.T) {
+		tracker, clock := newFakeClockTracker(t, time.Minute)
+		tracker.SetExpectedChainId(networkID, 1)
+		tracker.RecordUpstreamChainId("a", networkID, 10)
+		require.True(t, tracker.IsCordoned("a", networkID, "*"))
+
+		tracker.SetChainIdMismatchPenalty(health.ChainIdMismatchFlag)
+		assert.False(t, tracker.IsCordoned("a", networkID, "*"))
+		mismatch, chainId := tracker.ChainIdMismatch("a", networkID)
+		assert.True(t, mismatch)
+		assert.Equal(t, int64(10), chainId)
+		recordRequests(tracker, networkID, "a", "eth_chainId", 1, 0)
+		healthtest.AdvanceWindow(t, clock, time.Minute, tracker.GetUpstreamMethodMetrics("a", networkID, "*"))
+		assert.False(t, tracker.IsCordoned("a", networkID, "*"))
+	})
+}
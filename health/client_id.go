@@ -0,0 +1,294 @@
+package health
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// ClientIdOther buckets the clients beyond the top K tracked individually, see SetClientTopK.
+	ClientIdOther = "other"
+	// DefaultClientTopK is the number of clients tracked individually unless SetClientTopK says otherwise.
+	DefaultClientTopK = 20
+)
+
+// ClientMetrics is the traffic a downstream client drove to the upstreams of a network over the
+// current window, see GetClientMetrics.
+type ClientMetrics struct {
+	RequestsTotal int64 `json:"requestsTotal"`
+	ErrorsTotal   int64 `json:"errorsTotal"`
+	// RateLimitedTotal counts the requests throttled by the upstreams or by our own rate limits
+	RateLimitedTotal int64   `json:"rateLimitedTotal"`
+	ErrorRate        float64 `json:"errorRate"`
+	// Upstreams breaks the totals down per upstream
+	Upstreams map[string]ClientUpstreamMetrics `json:"upstreams"`
+}
+
+// ClientUpstreamMetrics is the traffic of a client towards one upstream, see ClientMetrics.
+type ClientUpstreamMetrics struct {
+	RequestsTotal    int64 `json:"requestsTotal"`
+	ErrorsTotal      int64 `json:"errorsTotal"`
+	RateLimitedTotal int64 `json:"rateLimitedTotal"`
+}
+
+type clientMetricsKey struct {
+	ups, network, method string
+}
+
+type clientCounters struct {
+	requests, errors, rateLimited atomic.Int64
+}
+
+// trackedClient is the rank and the metrics of a client in the top K, or of ClientIdOther.
+type trackedClient struct {
+	// rank is the requests of the client, halved every window
+	rank atomic.Int64
+	// demoted tells the client left the top K and its metrics are folded into ClientIdOther
+	demoted atomic.Bool
+	metrics sync.Map // map[clientMetricsKey]*clientCounters
+}
+
+// clientIdTracker keeps the metrics of the top K clients by requests, the others going to
+// ClientIdOther. Clients are ranked by requests halved every window, those bucketed as
+// ClientIdOther compete as candidates and take the place of the last tracked client once they
+// overtake it. Recording for a tracked client is lock-free, only the admission of the others is
+// serialized.
+type clientIdTracker struct {
+	clients sync.Map // map[string]*trackedClient, the top K
+	other   trackedClient
+
+	mu         sync.Mutex
+	k          int
+	tracked    int              // clients in the top K
+	candidates map[string]int64 // clients bucketed as ClientIdOther, bounded to k entries
+}
+
+// SetClientTopK sets how many clients are tracked individually by GetClientMetrics, the others
+// being bucketed as ClientIdOther. Zero or a negative k means DefaultClientTopK. It should be set
+// before recording outcomes with a client.
+func (t *Tracker) SetClientTopK(k int) {
+	if k <= 0 {
+		k = DefaultClientTopK
+	}
+	t.clientIds.mu.Lock()
+	defer t.clientIds.mu.Unlock()
+	t.clientIds.k = k
+}
+
+// recordClientOutcome counts an outcome under the client, or ClientIdOther when it is not in the
+// top K, for (ups, network, method) and (ups, network, "*"). Network and method must be canonical.
+func (t *Tracker) recordClientOutcome(client, ups, network, method string, kind OutcomeKind) {
+	c := &t.clientIds
+	tc := c.admit(client)
+	tc.record(clientMetricsKey{ups, network, method}, kind)
+	if method != "*" {
+		tc.record(clientMetricsKey{ups, network, "*"}, kind)
+	}
+	// The client was demoted meanwhile, after the metrics were folded or while they were
+	if tc.demoted.Load() {
+		c.other.absorb(tc)
+	}
+}
+
+func (tc *trackedClient) counters(k clientMetricsKey) *clientCounters {
+	if val, ok := tc.metrics.Load(k); ok {
+		return val.(*clientCounters)
+	}
+	val, _ := tc.metrics.LoadOrStore(k, &clientCounters{})
+	return val.(*clientCounters)
+}
+
+func (tc *trackedClient) record(k clientMetricsKey, kind OutcomeKind) {
+	cc := tc.counters(k)
+	switch kind {
+	case OutcomeSelfRateLimited:
+		cc.rateLimited.Add(1)
+		return
+	case OutcomeRemoteRateLimited:
+		cc.rateLimited.Add(1)
+	case OutcomeFailure:
+		cc.errors.Add(1)
+	}
+	cc.requests.Add(1)
+}
+
+// absorb moves the metrics of a demoted client into tc, it is safe to call concurrently since
+// counters are moved atomically.
+func (tc *trackedClient) absorb(from *trackedClient) {
+	from.metrics.Range(func(key, value any) bool {
+		cc := value.(*clientCounters)
+		requests, errors, rateLimited := cc.requests.Swap(0), cc.errors.Swap(0), cc.rateLimited.Swap(0)
+		if requests == 0 && errors == 0 && rateLimited == 0 {
+			return true
+		}
+		into := tc.counters(key.(clientMetricsKey))
+		into.requests.Add(requests)
+		into.errors.Add(errors)
+		into.rateLimited.Add(rateLimited)
+		return true
+	})
+}
+
+// admit ranks a request of the client and returns where its metrics are tracked.
+func (c *clientIdTracker) admit(client string) *trackedClient {
+	if val, ok := c.clients.Load(client); ok {
+		tc := val.(*trackedClient)
+		tc.rank.Add(1)
+		return tc
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// admitted meanwhile
+	if val, ok := c.clients.Load(client); ok {
+		tc := val.(*trackedClient)
+		tc.rank.Add(1)
+		return tc
+	}
+	if c.k == 0 {
+		c.k = DefaultClientTopK
+	}
+	if c.candidates == nil {
+		c.candidates = make(map[string]int64, c.k)
+	}
+	if c.tracked < c.k {
+		tc := &trackedClient{}
+		tc.rank.Store(1)
+		c.clients.Store(client, tc)
+		c.tracked++
+		return tc
+	}
+
+	// Space-saving over the candidates, a newcomer inherits the count of the one it evicts
+	if _, ok := c.candidates[client]; !ok && len(c.candidates) >= c.k {
+		evicted, count := minRank(c.candidates)
+		delete(c.candidates, evicted)
+		c.candidates[client] = count
+	}
+	c.candidates[client]++
+
+	last, lastClient := c.lastTracked()
+	lastCount := lastClient.rank.Load()
+	if c.candidates[client] <= lastCount {
+		return &c.other
+	}
+	tc := &trackedClient{}
+	tc.rank.Store(c.candidates[client])
+	delete(c.candidates, client)
+	c.clients.Store(client, tc)
+	c.clients.Delete(last)
+	c.candidates[last] = lastCount
+	lastClient.demoted.Store(true)
+	c.other.absorb(lastClient)
+	return tc
+}
+
+// lastTracked returns the tracked client with the lowest rank, c.mu must be held.
+func (c *clientIdTracker) lastTracked() (string, *trackedClient) {
+	ranks := make(map[string]int64, c.tracked)
+	clients := make(map[string]*trackedClient, c.tracked)
+	c.clients.Range(func(key, value any) bool {
+		tc := value.(*trackedClient)
+		ranks[key.(string)] = tc.rank.Load()
+		clients[key.(string)] = tc
+		return true
+	})
+	last, _ := minRank(ranks)
+	return last, clients[last]
+}
+
+// minRank returns the entry with the lowest count, the greatest name among equal ones so that
+// eviction is deterministic.
+func minRank(ranks map[string]int64) (string, int64) {
+	var name string
+	var count int64 = -1
+	for n, c := range ranks {
+		if count < 0 || c < count || (c == count && n > name) {
+			name, count = n, c
+		}
+	}
+	return name, count
+}
+
+// rollClientIds resets the client metrics for the next window and halves the ranks, so that the top
+// K follows the recent traffic.
+func (t *Tracker) rollClientIds() {
+	c := &t.clientIds
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.other.metrics.Clear()
+	c.clients.Range(func(_, value any) bool {
+		tc := value.(*trackedClient)
+		tc.metrics.Clear()
+		for {
+			rank := tc.rank.Load()
+			if tc.rank.CompareAndSwap(rank, rank/2) {
+				break
+			}
+		}
+		return true
+	})
+	for n := range c.candidates {
+		c.candidates[n] /= 2
+	}
+}
+
+// GetClientMetrics returns the traffic a client drove to the upstreams of a network for a method
+// ("*" for all of them) over the current window, broken down per upstream. Clients beyond the top
+// K (see SetClientTopK) are only found under ClientIdOther.
+func (t *Tracker) GetClientMetrics(clientId, network, method string) ClientMetrics {
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
+	res := ClientMetrics{Upstreams: map[string]ClientUpstreamMetrics{}}
+
+	c := &t.clientIds
+	tc := &c.other
+	if clientId != ClientIdOther {
+		val, ok := c.clients.Load(clientId)
+		if !ok {
+			return res
+		}
+		tc = val.(*trackedClient)
+	}
+	tc.metrics.Range(func(key, value any) bool {
+		k := key.(clientMetricsKey)
+		if k.network != network || k.method != method {
+			return true
+		}
+		cc := value.(*clientCounters)
+		cm := ClientUpstreamMetrics{
+			RequestsTotal:    cc.requests.Load(),
+			ErrorsTotal:      cc.errors.Load(),
+			RateLimitedTotal: cc.rateLimited.Load(),
+		}
+		if cm == (ClientUpstreamMetrics{}) {
+			return true
+		}
+		res.Upstreams[k.ups] = cm
+		res.RequestsTotal += cm.RequestsTotal
+		res.ErrorsTotal += cm.ErrorsTotal
+		res.RateLimitedTotal += cm.RateLimitedTotal
+		return true
+	})
+	res.ErrorRate = boundedRatio(res.ErrorsTotal, res.RequestsTotal)
+	return res
+}
+
+// TopClientIds returns the clients currently tracked individually, with the most requests first.
+func (t *Tracker) TopClientIds() []string {
+	var res []string
+	ranks := map[string]int64{}
+	t.clientIds.clients.Range(func(key, value any) bool {
+		res = append(res, key.(string))
+		ranks[key.(string)] = value.(*trackedClient).rank.Load()
+		return true
+	})
+	sort.Slice(res, func(i, j int) bool {
+		if ranks[res[i]] != ranks[res[j]] {
+			return ranks[res[i]] > ranks[res[j]]
+		}
+		return res[i] < res[j]
+	})
+	return res
+}
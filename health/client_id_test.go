@@ -0,0 +1,93 @@
+package health_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health"
+	"github.com/erpc/erpc/health/healthtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientMetrics(t *testing.T) {
+	networkID := "evm:123"
+	tracker, clock := newFakeClockTracker(t, time.Minute)
+	tracker.SetClientTopK(2)
+	record := func(client, ups string, kind health.OutcomeKind, n int) {
+		for i := 0; i < n; i++ {
+			tracker.RecordOutcome(ups, networkID, "eth_call", health.Outcome{Kind: kind, ClientId: client})
+		}
+	}
+
+	record("alice", "a", health.OutcomeSuccess, 8)
+	record("alice", "b", health.OutcomeFailure, 2)
+	record("bob", "a", health.OutcomeSuccess, 5)
+	record("bob", "a", health.OutcomeRemoteRateLimited, 1)
+	// Beyond the top 2 from the start
+	record("carol", "a", health.OutcomeFailure, 3)
+	record("dave", "b", health.OutcomeSuccess, 1)
+	// Without a client nothing is attributed
+	record("", "a", health.OutcomeSuccess, 4)
+
+	alice := tracker.GetClientMetrics("alice", networkID, "eth_call")
+	assert.Equal(t, int64(10), alice.RequestsTotal)
+	assert.Equal(t, int64(2), alice.ErrorsTotal)
+	assert.InDelta(t, 0.2, alice.ErrorRate, 1e-9)
+	assert.Equal(t, map[string]health.ClientUpstreamMetrics{
+		"a": {RequestsTotal: 8},
+		"b": {RequestsTotal: 2, ErrorsTotal: 2},
+	}, alice.Upstreams)
+	bob := tracker.GetClientMetrics("bob", networkID, "*")
+	assert.Equal(t, int64(6), bob.RequestsTotal)
+	assert.Equal(t, int64(1), bob.RateLimitedTotal)
+
+	assert.Zero(t, tracker.GetClientMetrics("carol", networkID, "eth_call").RequestsTotal)
+	other := tracker.GetClientMetrics(health.ClientIdOther, networkID, "eth_call")
+	assert.Equal(t, int64(4), other.RequestsTotal)
+	assert.Equal(t, int64(3), other.ErrorsTotal)
+	assert.Equal(t, []string{"alice", "bob"}, tracker.TopClientIds())
+
+	// A client overtaking the last of the top K takes its place, which is folded into "other"
+	record("carol", "b", health.OutcomeSuccess, 4)
+	assert.Equal(t, []string{"alice", "carol"}, tracker.TopClientIds())
+	carol := tracker.GetClientMetrics("carol", networkID, "eth_call")
+	assert.Equal(t, int64(1), carol.RequestsTotal)
+	assert.Zero(t, tracker.GetClientMetrics("bob", networkID, "eth_call").RequestsTotal)
+	other = tracker.GetClientMetrics(health.ClientIdOther, networkID, "eth_call")
+	assert.Equal(t, int64(4+6+3), other.RequestsTotal)
+	assert.Equal(t, int64(1), other.RateLimitedTotal)
+
+	// Metrics follow the window while the ranking carries over
+	healthtest.AdvanceWindow(t, clock, time.Minute, tracker.GetUpstreamMethodMetrics("a", networkID, "eth_call"))
+	assert.Zero(t, tracker.GetClientMetrics("alice", networkID, "eth_call").RequestsTotal)
+	assert.Equal(t, []string{"alice", "carol"}, tracker.TopClientIds())
+}
+
+func TestClientMetricsConcurrentDemotions(t *testing.T) {
+	networkID := "evm:123"
+	tracker, _ := newFakeClockTracker(t, time.Minute)
+	tracker.SetClientTopK(2)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				client := fmt.Sprintf("client-%d", (g+i)%5)
+				tracker.RecordOutcome("a", networkID, "eth_call", health.Outcome{Kind: health.OutcomeSuccess, ClientId: client})
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	// Demoted clients are folded into "other" without losing requests
+	var total int64
+	for _, client := range append(tracker.TopClientIds(), health.ClientIdOther) {
+		total += tracker.GetClientMetrics(client, networkID, "eth_call").RequestsTotal
+	}
+	assert.Len(t, tracker.TopClientIds(), 2)
+	assert.Equal(t, int64(8*500), total)
+}
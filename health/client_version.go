@@ -0,0 +1,115 @@
+package health
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/erpc/erpc/telemetry"
+)
+
+// EventClientVersionChanged is emitted when the client or version reported by an upstream changes,
+// e.g. on upgrades.
+const EventClientVersionChanged EventType = "clientVersionChanged"
+
+// ClientOther is the client of upstreams whose client version is not recognized.
+const ClientOther = "other"
+
+// knownClients bounds the clients reported in metrics, others count as ClientOther.
+var knownClients = map[string]struct{}{
+	"geth":       {},
+	"erigon":     {},
+	"reth":       {},
+	"besu":       {},
+	"nethermind": {},
+	"bor":        {},
+	"nitro":      {},
+	"op-geth":    {},
+}
+
+var (
+	clientVersionPattern = regexp.MustCompile(`^v?(\d+(?:\.\d+){0,3})`)
+	// taggedVersionPattern is a version with its "v" prefix, which tells it apart from the node
+	// name some clients report before it (e.g. "Geth/mainnet-node-7/v1.13.5-stable/...")
+	taggedVersionPattern = regexp.MustCompile(`^v(\d+(?:\.\d+){1,3})`)
+)
+
+// ClientVersion is the node client of an upstream as reported by web3_clientVersion.
+type ClientVersion struct {
+	// Client is the lowercase client name (e.g. "geth"), ClientOther when not recognized
+	Client string `json:"client"`
+	// Version is the numeric version (e.g. "1.13.5"), empty when not recognized
+	Version string `json:"version,omitempty"`
+	// Raw is the string reported by the upstream
+	Raw string `json:"raw"`
+}
+
+// ParseClientVersion parses web3_clientVersion strings of the "<client>/v<version>/<platform>/..."
+// form used by most clients (e.g. "Geth/v1.13.5-stable-916d6a44/linux-amd64/go1.21.4"). Strings
+// of unknown clients or formats only keep Raw, with ClientOther as client.
+func ParseClientVersion(raw string) ClientVersion {
+	cv := ClientVersion{Client: ClientOther, Raw: raw}
+	parts := strings.Split(raw, "/")
+	if len(parts) < 2 {
+		return cv
+	}
+	client := strings.ToLower(strings.TrimSpace(parts[0]))
+	if _, ok := knownClients[client]; !ok {
+		return cv
+	}
+	cv.Client = client
+	for _, part := range parts[1:] {
+		if m := taggedVersionPattern.FindStringSubmatch(strings.TrimSpace(part)); m != nil {
+			cv.Version = m[1]
+			return cv
+		}
+	}
+	if m := clientVersionPattern.FindStringSubmatch(strings.TrimSpace(parts[1])); m != nil {
+		cv.Version = m[1]
+	}
+	return cv
+}
+
+// RecordUpstreamClientVersion records the web3_clientVersion reported by an upstream, see
+// ParseClientVersion. The client is used for the behind head matchers of upstreams without a
+// client attribute, and a change of client or version emits EventClientVersionChanged.
+func (t *Tracker) RecordUpstreamClientVersion(ups, network, raw string) {
+	network = t.canonicalNetwork(network)
+	cv := ParseClientVersion(raw)
+	k := duoKey{ups: ups, network: network}
+	prev, loaded := t.clientVersions.Swap(k, cv)
+
+	vendor := t.upstreamVendor(ups, network)
+	if loaded {
+		p := prev.(ClientVersion)
+		if p.Client == cv.Client && p.Version == cv.Version {
+			return
+		}
+		telemetry.MetricUpstreamClientInfo.DeleteLabelValues(t.projectId, network, ups, vendor, p.Client, p.Version)
+		t.emit(Event{
+			Type:     EventClientVersionChanged,
+			Upstream: ups,
+			Network:  network,
+			Message:  fmt.Sprintf("client version changed from %q to %q", p.Raw, cv.Raw),
+		})
+	}
+	telemetry.MetricUpstreamClientInfo.WithLabelValues(t.projectId, network, ups, vendor, cv.Client, cv.Version).Set(1)
+}
+
+// refreshClientInfoGauge re-emits the client info of an upstream, e.g. after its vendor changed.
+func (t *Tracker) refreshClientInfoGauge(ups, network string) {
+	if val, ok := t.clientVersions.Load(duoKey{ups: ups, network: network}); ok {
+		cv := val.(ClientVersion)
+		telemetry.MetricUpstreamClientInfo.WithLabelValues(t.projectId, network, ups, t.upstreamVendor(ups, network), cv.Client, cv.Version).Set(1)
+	}
+}
+
+// GetUpstreamClientVersion returns the client version last recorded for an upstream.
+func (t *Tracker) GetUpstreamClientVersion(ups, network string) (ClientVersion, bool) {
+	network = t.canonicalNetwork(network)
+	val, ok := t.clientVersions.Load(duoKey{ups: ups, network: network})
+	if !ok {
+		return ClientVersion{}, false
+	}
+	return val.(ClientVersion), true
+}
@@ -0,0 +1,74 @@
+package health_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseClientVersion(t *testing.T) {
+	cases := []struct {
+		raw     string
+		client  string
+		version string
+	}{
+		{"Geth/v1.13.5-stable-916d6a44/linux-amd64/go1.21.4", "geth", "1.13.5"},
+		{"erigon/2.60.1/linux-amd64/go1.21.5", "erigon", "2.60.1"},
+		{"reth/v0.2.0-beta.5-0b8ee3b/x86_64-unknown-linux-gnu", "reth", "0.2.0"},
+		{"besu/v24.1.0/linux-x86_64/openjdk-java-17", "besu", "24.1.0"},
+		{"Nethermind/v1.25.4+20b10b35/linux-x64/dotnet8.0.2", "nethermind", "1.25.4"},
+		{"bor/v1.2.0-stable/linux-amd64/go1.21.6", "bor", "1.2.0"},
+		{"Geth/mainnet-node-7/v1.13.5-stable/linux-amd64/go1.21.4", "geth", "1.13.5"},
+		{"Geth/v1.14.11-stable-f3c696fa/linux-amd64/go1.23.2", "geth", "1.14.11"},
+		{"Geth/node-2/linux-amd64/go1.21.4", "geth", ""},
+		{"SomeProvider/v3.1.0", health.ClientOther, ""},
+		{"my-custom-node", health.ClientOther, ""},
+		{"", health.ClientOther, ""},
+	}
+	for _, tc := range cases {
+		cv := health.ParseClientVersion(tc.raw)
+		assert.Equal(t, tc.client, cv.Client, tc.raw)
+		assert.Equal(t, tc.version, cv.Version, tc.raw)
+		assert.Equal(t, tc.raw, cv.Raw)
+	}
+}
+
+func TestUpstreamClientVersion(t *testing.T) {
+	networkID := "evm:123"
+	tracker, _ := newFakeClockTracker(t, time.Minute)
+	events, unsubscribe := tracker.Subscribe(4)
+	defer unsubscribe()
+
+	_, ok := tracker.GetUpstreamClientVersion("a", networkID)
+	assert.False(t, ok)
+
+	tracker.RecordUpstreamRequest("a", networkID, "eth_call")
+	tracker.RecordUpstreamClientVersion("a", networkID, "erigon/2.60.1/linux-amd64/go1.21.5")
+	tracker.RecordUpstreamClientVersion("a", networkID, "erigon/2.60.1/linux-amd64/go1.21.5")
+	cv, ok := tracker.GetUpstreamClientVersion("a", networkID)
+	require.True(t, ok)
+	assert.Equal(t, "erigon", cv.Client)
+	assert.Equal(t, "2.60.1", tracker.GetNetworkUpstreamsMetrics(networkID, "*")["a"].Client.Version)
+
+	// The detected client picks the behind head matchers unless the client attribute is set
+	assert.True(t, tracker.RecordUpstreamBehindHeadError("a", networkID, "eth_call", errors.New("block not found: 19876543")))
+
+	tracker.RecordUpstreamClientVersion("a", networkID, "erigon/2.61.0/linux-amd64/go1.22.1")
+	select {
+	case e := <-events:
+		assert.Equal(t, health.EventClientVersionChanged, e.Type)
+		assert.Equal(t, "a", e.Upstream)
+		assert.Contains(t, e.Message, "2.61.0")
+	default:
+		t.Fatal("expected a client version change event")
+	}
+	select {
+	case e := <-events:
+		t.Fatalf("unexpected event %v", e)
+	default:
+	}
+}
@@ -0,0 +1,46 @@
+package health
+
+import (
+	"time"
+)
+
+// Clock abstracts time for the tracker so window resets and time-based state can be tested deterministically.
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+	After(d time.Duration) <-chan time.Time
+}
+
+// Ticker is the subset of *time.Ticker used by the tracker. It is an alias of an unnamed interface
+// so that clocks can be implemented without importing this package.
+type Ticker = interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// RealClock is the default Clock backed by the time package.
+type RealClock struct{}
+
+func (RealClock) Now() time.Time {
+	return time.Now()
+}
+
+func (RealClock) NewTicker(d time.Duration) Ticker {
+	return &realTicker{t: time.NewTicker(d)}
+}
+
+func (RealClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+type realTicker struct {
+	t *time.Ticker
+}
+
+func (r *realTicker) C() <-chan time.Time {
+	return r.t.C
+}
+
+func (r *realTicker) Stop() {
+	r.t.Stop()
+}
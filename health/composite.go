@@ -0,0 +1,58 @@
+package health
+
+import (
+	"github.com/erpc/erpc/common"
+)
+
+// CompositeTypeOther is the composite type of durations recorded with an unknown composite type.
+const CompositeTypeOther = "other"
+
+// compositeTypes bounds the composite types with their own latency quantiles, see
+// GetCompositeTypeQuantiles.
+var compositeTypes = [...]string{
+	common.CompositeTypeNone,
+	common.CompositeTypeLogsSplitOnError,
+	common.CompositeTypeLogsSplitProactive,
+	common.CompositeTypeShadow,
+	CompositeTypeHedge,
+	CompositeTypeOther,
+}
+
+func compositeTypeIndex(compositeType string) int {
+	if compositeType == "" {
+		return 0
+	}
+	for i, ct := range compositeTypes {
+		if ct == compositeType {
+			return i
+		}
+	}
+	return len(compositeTypes) - 1
+}
+
+// GetCompositeTypeQuantiles returns the response quantiles of requests of a composite type (e.g.
+// CompositeTypeHedge), unknown types being accounted as CompositeTypeOther, or nil when no such
+// request has been observed yet.
+func (m *TrackedMetrics) GetCompositeTypeQuantiles(compositeType string) *QuantileTracker {
+	return m.compositeQuantiles[compositeTypeIndex(compositeType)].Load()
+}
+
+func (m *TrackedMetrics) compositeTypeQuantiles(compositeType string) *QuantileTracker {
+	i := compositeTypeIndex(compositeType)
+	if qt := m.compositeQuantiles[i].Load(); qt != nil {
+		return qt
+	}
+	m.compositeQuantiles[i].CompareAndSwap(nil, m.newQuantileTracker())
+	return m.compositeQuantiles[i].Load()
+}
+
+// compositeTypeP90s returns the p90 in seconds of every composite type observed in the window.
+func (m *TrackedMetrics) compositeTypeP90s() map[string]float64 {
+	res := make(map[string]float64, len(m.compositeQuantiles))
+	for i := range m.compositeQuantiles {
+		if qt := m.compositeQuantiles[i].Load(); qt != nil && qt.HasSamples() {
+			res[compositeTypes[i]] = qt.GetQuantile(0.90).Seconds()
+		}
+	}
+	return res
+}
@@ -0,0 +1,50 @@
+package health_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompositeTypeQuantiles(t *testing.T) {
+	networkID := "evm:123"
+	tracker, clock := newFakeClockTracker(t, time.Minute)
+
+	for i := 0; i < 20; i++ {
+		tracker.RecordUpstreamDuration("a", networkID, "eth_getLogs", 20*time.Millisecond, common.CompositeTypeNone)
+		tracker.RecordUpstreamDuration("a", networkID, "eth_getLogs", 800*time.Millisecond, common.CompositeTypeLogsSplitOnError)
+	}
+	tracker.RecordUpstreamDuration("a", networkID, "eth_getLogs", 3*time.Second, "some-future-type")
+	hedge := tracker.RecordUpstreamHedgeStart("a", networkID, "eth_getLogs")
+	clock.Advance(100 * time.Millisecond)
+	hedge.ObserveDuration()
+
+	m := tracker.GetUpstreamMethodMetrics("a", networkID, "eth_getLogs")
+	assert.InDelta(t, 0.02, m.GetCompositeTypeQuantiles(common.CompositeTypeNone).GetQuantile(0.90).Seconds(), 0.001)
+	assert.InDelta(t, 0.8, m.GetCompositeTypeQuantiles(common.CompositeTypeLogsSplitOnError).GetQuantile(0.90).Seconds(), 0.02)
+	assert.InDelta(t, 0.1, m.GetCompositeTypeQuantiles(health.CompositeTypeHedge).GetQuantile(0.90).Seconds(), 0.002)
+	assert.InDelta(t, 3, m.GetCompositeTypeQuantiles(health.CompositeTypeOther).GetQuantile(0.90).Seconds(), 0.05)
+	assert.Same(t, m.GetCompositeTypeQuantiles(health.CompositeTypeOther), m.GetCompositeTypeQuantiles("another-type"))
+	assert.Same(t, m.GetCompositeTypeQuantiles(common.CompositeTypeNone), m.GetCompositeTypeQuantiles(""))
+	// Trackers are only allocated for the types observed
+	assert.Nil(t, m.GetCompositeTypeQuantiles(common.CompositeTypeLogsSplitProactive))
+
+	data, err := json.Marshal(m)
+	require.NoError(t, err)
+	var decoded struct {
+		CompositeTypeP90 map[string]float64 `json:"compositeTypeP90"`
+	}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Len(t, decoded.CompositeTypeP90, 4)
+	assert.InDelta(t, 0.8, decoded.CompositeTypeP90[common.CompositeTypeLogsSplitOnError], 0.02)
+
+	clock.Advance(time.Minute)
+	assert.Eventually(t, func() bool {
+		return !m.GetCompositeTypeQuantiles(common.CompositeTypeNone).HasSamples()
+	}, time.Second, time.Millisecond)
+}
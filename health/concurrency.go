@@ -0,0 +1,87 @@
+package health
+
+import (
+	"github.com/erpc/erpc/telemetry"
+)
+
+// SetConcurrencyLimit advises against sending more than c simultaneous requests to an upstream on
+// a network, see ShouldAdmit. A non-positive c removes the limit.
+func (t *Tracker) SetConcurrencyLimit(ups, network string, c int) {
+	network = t.canonicalNetwork(network)
+	k := duoKey{ups: ups, network: network}
+	if c <= 0 {
+		t.concurrencyLimits.Delete(k)
+		return
+	}
+	t.logger.Debug().Str("upstream", ups).
+		Str("network", network).
+		Int("limit", c).
+		Msg("setting upstream concurrency limit in tracker")
+	t.concurrencyLimits.Store(k, int64(c))
+}
+
+// ShouldAdmit tells if a new request for method may be sent to the upstream, i.e. if its
+// in-flight requests are under the limit set by SetConcurrencyLimit. Each denied check counts
+// in ConcurrencyDeniedTotal.
+func (t *Tracker) ShouldAdmit(ups, network, method string) bool {
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
+	if !t.isSaturated(duoKey{ups: ups, network: network}) {
+		return true
+	}
+
+	for _, k := range t.getKeys(ups, network, method) {
+		t.getMetrics(k).ConcurrencyDeniedTotal.Add(1)
+	}
+	telemetry.MetricUpstreamConcurrencyDeniedTotal.WithLabelValues(t.projectId, network, ups, t.upstreamVendor(ups, network), method).Inc()
+	return false
+}
+
+// IsSaturated tells if the in-flight requests of an upstream on a network reached the limit set by
+// SetConcurrencyLimit, like ShouldAdmit but without counting a denial.
+func (t *Tracker) IsSaturated(ups, network string) bool {
+	return t.isSaturated(duoKey{ups: ups, network: t.canonicalNetwork(network)})
+}
+
+func (t *Tracker) isSaturated(k duoKey) bool {
+	val, ok := t.concurrencyLimits.Load(k)
+	return ok && t.getMetadata(k).inFlight.Load() >= val.(int64)
+}
+
+// InFlight returns the number of requests to an upstream on a network whose timer (see
+// RecordUpstreamDurationStart) is not observed yet.
+func (t *Tracker) InFlight(ups, network string) int64 {
+	network = t.canonicalNetwork(network)
+	if val, ok := t.metadata.Load(duoKey{ups: ups, network: network}); ok {
+		return val.(*NetworkMetadata).inFlight.Load()
+	}
+	return 0
+}
+
+// startInFlight counts a request in flight and returns the function ending it, which the timer
+// calls once observed.
+func (t *Tracker) startInFlight(ups, network string) func() {
+	md := t.getMetadata(duoKey{ups: ups, network: network})
+	t.setInFlightGauge(ups, network, md.inFlight.Add(1))
+	return func() {
+		t.setInFlightGauge(ups, network, md.inFlight.Add(-1))
+	}
+}
+
+func (t *Tracker) setInFlightGauge(ups, network string, inFlight int64) {
+	telemetry.MetricUpstreamInFlightRequests.WithLabelValues(t.projectId, network, ups, t.upstreamVendor(ups, network)).Set(float64(inFlight))
+}
+
+// Release ends the request of the timer in the in-flight count without recording anything, it is
+// a no-op once the timer is observed. Deferring it right after starting the timer makes sure no
+// path (e.g. a panic) leaves the request in flight.
+func (t *Timer) Release() {
+	t.endInFlight()
+}
+
+// endInFlight ends the request of the timer in the in-flight count, only the first call counts.
+func (t *Timer) endInFlight() {
+	if t.release != nil && t.ended.CompareAndSwap(false, true) {
+		t.release()
+	}
+}
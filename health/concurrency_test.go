@@ -0,0 +1,52 @@
+package health_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConcurrencyLimit(t *testing.T) {
+	networkID := "evm:123"
+	tracker, _ := newFakeClockTracker(t, time.Minute)
+
+	// Without a limit every request is admitted
+	first := tracker.RecordUpstreamDurationStart("a", networkID, "eth_call", "")
+	assert.True(t, tracker.ShouldAdmit("a", networkID, "eth_call"))
+
+	tracker.SetConcurrencyLimit("a", networkID, 2)
+	second := tracker.RecordUpstreamDurationStart("a", networkID, "eth_call", "")
+	// The request of a hedge counts through its own timer, not the one of the hedge
+	hedge := tracker.RecordUpstreamHedgeStart("a", networkID, "eth_call")
+	assert.Equal(t, int64(2), tracker.InFlight("a", networkID))
+	assert.False(t, tracker.ShouldAdmit("a", networkID, "eth_call"))
+	assert.False(t, tracker.ShouldAdmit("a", networkID, "eth_getBalance"))
+	assert.True(t, tracker.ShouldAdmit("b", networkID, "eth_call"))
+	assert.Equal(t, int64(1), tracker.GetUpstreamMethodMetrics("a", networkID, "eth_call").ConcurrencyDeniedTotal.Load())
+	assert.Equal(t, int64(2), tracker.GetUpstreamMethodMetrics("a", networkID, "*").ConcurrencyDeniedTotal.Load())
+
+	// Observing a timer completes its request, only once
+	first.ObserveOutcome(health.Outcome{Kind: health.OutcomeSuccess})
+	first.ObserveDuration()
+	assert.Equal(t, int64(1), tracker.InFlight("a", networkID))
+	assert.True(t, tracker.ShouldAdmit("a", networkID, "eth_call"))
+
+	third := tracker.RecordUpstreamDurationStart("a", networkID, "eth_call", "")
+	assert.False(t, tracker.ShouldAdmit("a", networkID, "eth_call"))
+	third.ObserveCancelled(health.CancelCauseClient)
+	hedge.ObserveHedgeCancelled()
+	assert.Equal(t, int64(1), tracker.InFlight("a", networkID))
+	// Releasing ends the request once, observing afterwards does not end it again
+	second.Release()
+	second.Release()
+	second.ObserveDuration()
+	assert.Equal(t, int64(0), tracker.InFlight("a", networkID))
+
+	tracker.RecordUpstreamDurationStart("a", networkID, "eth_call", "")
+	tracker.RecordUpstreamDurationStart("a", networkID, "eth_call", "")
+	assert.False(t, tracker.ShouldAdmit("a", networkID, "eth_call"))
+	tracker.SetConcurrencyLimit("a", networkID, 0)
+	assert.True(t, tracker.ShouldAdmit("a", networkID, "eth_call"))
+}
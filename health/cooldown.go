@@ -0,0 +1,23 @@
+package health
+
+import "time"
+
+// InCooldown tells if (ups, network, method) failed less than d ago, for selection to briefly
+// avoid an upstream that just failed even while its aggregate health is still fine. Only failures
+// of the exact key count, and they are remembered across window resets.
+func (t *Tracker) InCooldown(ups, network, method string, d time.Duration) bool {
+	if d <= 0 {
+		return false
+	}
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
+	val, ok := t.metrics.Load(tripletKey{ups, network, method})
+	if !ok {
+		return false
+	}
+	last := val.(*TrackedMetrics).lastFailure.Load()
+	if last == 0 {
+		return false
+	}
+	return t.clock.Now().Sub(time.Unix(0, last)) < d
+}
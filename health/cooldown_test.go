@@ -0,0 +1,56 @@
+package health_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health"
+	"github.com/erpc/erpc/health/healthtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailureCooldown(t *testing.T) {
+	networkID := "evm:123"
+
+	t.Run("EngagesAfterFailureAndLiftsAfterDuration", func(t *testing.T) {
+		tracker, clock := newFakeClockTracker(t, time.Hour)
+		assert.False(t, tracker.InCooldown("a", networkID, "eth_call", time.Second))
+
+		tracker.RecordUpstreamRequest("a", networkID, "eth_call")
+		tracker.RecordUpstreamFailure("a", networkID, "eth_call")
+		assert.True(t, tracker.InCooldown("a", networkID, "eth_call", time.Second))
+		assert.False(t, tracker.InCooldown("a", networkID, "eth_call", 0))
+
+		clock.Advance(999 * time.Millisecond)
+		assert.True(t, tracker.InCooldown("a", networkID, "eth_call", time.Second))
+		clock.Advance(time.Millisecond)
+		assert.False(t, tracker.InCooldown("a", networkID, "eth_call", time.Second))
+	})
+
+	t.Run("OnlyTheExactKeyCoolsDown", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Hour)
+		tracker.RecordOutcome("a", networkID, "eth_call", health.Outcome{Kind: health.OutcomeFailure})
+
+		assert.True(t, tracker.InCooldown("a", networkID, "eth_call", time.Second))
+		assert.False(t, tracker.InCooldown("a", networkID, "eth_getLogs", time.Second))
+		assert.False(t, tracker.InCooldown("a", networkID, "*", time.Second))
+		assert.False(t, tracker.InCooldown("b", networkID, "eth_call", time.Second))
+	})
+
+	t.Run("OnlyFailuresEngageIt", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Hour)
+		for _, kind := range []health.OutcomeKind{health.OutcomeSuccess, health.OutcomeNonCriticalError, health.OutcomeRemoteRateLimited, health.OutcomeUnsupportedMethod} {
+			tracker.RecordOutcome("a", networkID, "eth_call", health.Outcome{Kind: kind})
+		}
+		assert.False(t, tracker.InCooldown("a", networkID, "eth_call", time.Second))
+	})
+
+	t.Run("SurvivesWindowReset", func(t *testing.T) {
+		tracker, clock := newFakeClockTracker(t, time.Minute)
+		clock.Advance(59 * time.Second)
+		tracker.RecordUpstreamRequest("a", networkID, "eth_call")
+		tracker.RecordUpstreamFailure("a", networkID, "eth_call")
+		healthtest.AdvanceWindow(t, clock, time.Minute, tracker.GetUpstreamMethodMetrics("a", networkID, "eth_call"))
+		assert.True(t, tracker.InCooldown("a", networkID, "eth_call", 5*time.Minute))
+	})
+}
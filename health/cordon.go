@@ -0,0 +1,199 @@
+package health
+
+import (
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/telemetry"
+)
+
+// Sources of cordons, see CordonInfo.
+const (
+	CordonSourceManual  = "manual"
+	CordonSourcePolicy  = "selection-policy"
+	CordonSourceTracker = "tracker"
+)
+
+// Precedence classes of cordons, see CordonInfo.Origin. A manual cordon is neither replaced by an
+// automatic cordon nor lifted by the uncordon of another class, while a manual uncordon lifts any
+// cordon along with what would apply it again at the next window reset. External cordons, set up
+// by operators through selection policies, may take a manual cordon over.
+const (
+	CordonOriginManual   = "manual"
+	CordonOriginAuto     = "auto"
+	CordonOriginExternal = "external"
+)
+
+// cordonOriginClass is the precedence class of a cordon source.
+func cordonOriginClass(source string) string {
+	switch source {
+	case CordonSourceManual:
+		return CordonOriginManual
+	case CordonSourceTracker:
+		return CordonOriginAuto
+	default:
+		return CordonOriginExternal
+	}
+}
+
+// CordonInfo describes why and since when a key is cordoned. It is immutable once stored, a new
+// cordon swaps it as a whole.
+type CordonInfo struct {
+	Reason string
+	Detail string
+	// Source is who cordoned, e.g. CordonSourceTracker for cordons applied on thresholds.
+	Source string
+	// Since is when the key went from uncordoned to cordoned.
+	Since time.Time
+	// ExpiresAt is when the cordon is expected to be lifted, zero if only lifted explicitly.
+	ExpiresAt time.Time
+}
+
+// Origin is the precedence class of the source of the cordon, CordonOriginManual, "auto:<reason>"
+// for the cordons applied by the tracker itself, or CordonOriginExternal for the ones of other
+// components such as the selection policy.
+func (c *CordonInfo) Origin() string {
+	class := cordonOriginClass(c.Source)
+	if class == CordonOriginAuto {
+		return class + ":" + c.Reason
+	}
+	return class
+}
+
+func (c *CordonInfo) MarshalJSON() ([]byte, error) {
+	res := map[string]interface{}{
+		"reason": c.Reason,
+		"source": c.Source,
+		"origin": c.Origin(),
+		"since":  c.Since,
+	}
+	if c.Detail != "" {
+		res["detail"] = c.Detail
+	}
+	if !c.ExpiresAt.IsZero() {
+		res["expiresAt"] = c.ExpiresAt
+	}
+	return common.SonicCfg.Marshal(res)
+}
+
+// CordonInfo returns the details of the current cordon, or nil when not cordoned.
+func (m *TrackedMetrics) CordonInfo() *CordonInfo {
+	return m.cordonInfo.Load()
+}
+
+// GetCordonInfo returns the details of the cordon of (ups, network, method) itself, or nil when it
+// is not cordoned. Unlike IsCordoned it does not look at the cordon of the whole upstream.
+func (t *Tracker) GetCordonInfo(ups, network, method string) *CordonInfo {
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
+	val, ok := t.metrics.Load(tripletKey{ups, network, method})
+	if !ok {
+		return nil
+	}
+	return val.(*TrackedMetrics).CordonInfo()
+}
+
+// recordCordonTransition counts a cordon transition of (ups, network, method) requested by source,
+// action being "cordon", "uncordon" or "refused" when the precedence of a manual cordon kept it.
+func (t *Tracker) recordCordonTransition(ups, network, method, source, action string) {
+	telemetry.MetricUpstreamCordonTransitionTotal.
+		WithLabelValues(t.projectId, network, ups, t.upstreamVendor(ups, network), method, cordonOriginClass(source), action).
+		Inc()
+}
+
+// SetCordonDryRun makes every Evaluate*Cordon helper only log and count (MetricUpstreamWouldCordonTotal)
+// the cordons it would apply, leaving routing untouched. Manual Cordon calls are not affected.
+func (t *Tracker) SetCordonDryRun(dryRun bool) {
+	t.cordonDryRun.Store(dryRun)
+}
+
+// CordonGuard is consulted before every automatic cordon, returning false vetoes it, e.g. to keep
+// the last healthy upstream of a network routable. It must not cordon or uncordon itself.
+type CordonGuard func(ups, network, method, reason string) bool
+
+// SetCordonGuard installs a guard consulted by every Evaluate*Cordon helper and threshold before
+// cordoning, nil removes it. Manual Cordon calls and chain id mismatch cordons are not affected.
+// Vetoed cordons are attempted again on the next evaluation, so they apply once the guard allows
+// them.
+func (t *Tracker) SetCordonGuard(fn CordonGuard) {
+	if fn == nil {
+		t.cordonGuard.Store(nil)
+		return
+	}
+	t.cordonGuard.Store(&fn)
+}
+
+// autoCordon cordons on behalf of the tracker itself (e.g. a threshold breach). It keeps the
+// reason of an existing cordon, and like any cordon it is lifted when the window resets. It
+// reports whether the key is cordoned (or would be in dry-run mode).
+func (t *Tracker) autoCordon(ups, network, method, reason string) bool {
+	return t.autoCordonWithInfo(ups, network, method, CordonInfo{
+		Reason:    reason,
+		Source:    CordonSourceTracker,
+		ExpiresAt: time.Unix(0, t.windowStart.Load()).Add(t.windowSize),
+	})
+}
+
+// autoCordonWithInfo is autoCordon describing the cordon with info.
+func (t *Tracker) autoCordonWithInfo(ups, network, method string, info CordonInfo) bool {
+	dryRun := t.cordonDryRun.Load()
+	if !dryRun && t.getMetrics(tripletKey{ups, network, method}).Cordoned.Load() {
+		return true
+	}
+	if guard := t.cordonGuard.Load(); guard != nil && !(*guard)(ups, network, method, info.Reason) {
+		t.logger.Info().Str("upstream", ups).
+			Str("network", network).
+			Str("method", method).
+			Str("reason", info.Reason).
+			Msg("cordon vetoed by guard")
+		telemetry.MetricUpstreamCordonVetoedTotal.WithLabelValues(t.projectId, network, ups, t.upstreamVendor(ups, network), method).Inc()
+		return false
+	}
+	// Only upstream-wide cordons take upstreams out of the eligible ones
+	if floor := t.minEligibleUpstreams.Load(); floor > 0 && method == "*" {
+		t.cordonFloorMu.Lock()
+		defer t.cordonFloorMu.Unlock()
+		// a concurrent evaluation may have cordoned the key meanwhile
+		if !dryRun && t.getMetrics(tripletKey{ups, network, method}).Cordoned.Load() {
+			return true
+		}
+		if t.belowEligibleFloor(ups, network, floor) {
+			t.suppressCordon(ups, network, method, info, floor)
+			return false
+		}
+	}
+	return t.applyAutoCordon(ups, network, method, info, dryRun)
+}
+
+// forceAutoCordonWithInfo is autoCordonWithInfo bypassing the cordon guard and the eligible
+// floor, for upstreams which must not serve traffic whatever is left (e.g. another chain).
+func (t *Tracker) forceAutoCordonWithInfo(ups, network, method string, info CordonInfo) bool {
+	dryRun := t.cordonDryRun.Load()
+	if !dryRun && t.getMetrics(tripletKey{ups, network, method}).Cordoned.Load() {
+		return true
+	}
+	return t.applyAutoCordon(ups, network, method, info, dryRun)
+}
+
+func (t *Tracker) applyAutoCordon(ups, network, method string, info CordonInfo, dryRun bool) bool {
+	if dryRun {
+		t.logger.Info().Str("upstream", ups).
+			Str("network", network).
+			Str("method", method).
+			Str("reason", info.Reason).
+			Msg("would cordon upstream (dry-run)")
+		telemetry.MetricUpstreamWouldCordonTotal.WithLabelValues(t.projectId, network, ups, t.upstreamVendor(ups, network), method).Inc()
+		return true
+	}
+	t.CordonWithInfo(ups, network, method, t.trackCordonRecovery(tripletKey{ups, network, method}, info))
+	return true
+}
+
+// dropReappliedCordons forgets the automatic cordons of a key which window resets would apply
+// again, once an operator lifted its cordon. Network and method must already be canonical.
+func (t *Tracker) dropReappliedCordons(k tripletKey) {
+	t.dropErrorRateCordon(k)
+	if k.method == "*" {
+		t.waiveChainIdCordon(k.ups, k.network)
+	}
+}
@@ -0,0 +1,327 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health/internal/fakeclock"
+	"github.com/erpc/erpc/health/internal/metricstest"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCordonDryRun(t *testing.T) {
+	wouldCordon := func(t *testing.T) float64 {
+		return metricstest.Value(t, "erpc_upstream_would_cordon_total", map[string]string{
+			"project": "test-cordon-dry-run", "network": "evm:1", "upstream": "a", "category": "*",
+		})
+	}
+
+	t.Run("OnlyCountsWouldCordon", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-cordon-dry-run", time.Minute)
+		tracker.SetCordonDryRun(true)
+		tracker.SetReconnectCordonThreshold(1)
+		before := wouldCordon(t)
+
+		tracker.RecordUpstreamReconnect("a", "evm:1")
+		tracker.RecordUpstreamReconnect("a", "evm:1")
+		tracker.RecordUpstreamReconnect("a", "evm:1")
+
+		assert.False(t, tracker.IsCordoned("a", "evm:1", "eth_call"))
+		assert.Equal(t, before+2, wouldCordon(t))
+	})
+
+	t.Run("DisablingDryRunCordons", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-cordon-dry-run", time.Minute)
+		tracker.SetCordonDryRun(true)
+		tracker.SetReconnectCordonThreshold(1)
+		tracker.RecordUpstreamReconnect("a", "evm:1")
+		tracker.RecordUpstreamReconnect("a", "evm:1")
+		before := wouldCordon(t)
+
+		tracker.SetCordonDryRun(false)
+		tracker.RecordUpstreamReconnect("a", "evm:1")
+
+		assert.True(t, tracker.IsCordoned("a", "evm:1", "eth_call"))
+		assert.Equal(t, before, wouldCordon(t))
+	})
+
+	t.Run("ManualCordonIsNotAffected", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-cordon-dry-run", time.Minute)
+		tracker.SetCordonDryRun(true)
+
+		tracker.Cordon("a", "evm:1", "eth_call", "manual")
+		assert.True(t, tracker.IsCordoned("a", "evm:1", "eth_call"))
+	})
+}
+
+func TestCordonInfo(t *testing.T) {
+	t.Run("SinceOnlyChangesOnTransitions", func(t *testing.T) {
+		clock := fakeclock.New(time.Unix(1700000000, 0))
+		tracker := NewTracker(&log.Logger, "test-cordon-info", time.Minute)
+		tracker.SetClock(clock)
+		assert.Nil(t, tracker.GetCordonInfo("a", "evm:1", "eth_call"))
+
+		tracker.Cordon("a", "evm:1", "eth_call", "flaky")
+		first := tracker.GetCordonInfo("a", "evm:1", "eth_call")
+		require.NotNil(t, first)
+		assert.Equal(t, "flaky", first.Reason)
+		assert.Equal(t, CordonSourceManual, first.Source)
+		assert.Equal(t, clock.Now(), first.Since)
+
+		clock.Advance(time.Second)
+		tracker.CordonWithInfo("a", "evm:1", "eth_call", CordonInfo{Reason: "still flaky", Detail: "5 errors", Source: CordonSourcePolicy})
+		again := tracker.GetCordonInfo("a", "evm:1", "eth_call")
+		assert.Equal(t, "still flaky", again.Reason)
+		assert.Equal(t, first.Since, again.Since)
+		assert.Equal(t, "flaky", first.Reason, "stored infos are never mutated")
+
+		tracker.Uncordon("a", "evm:1", "eth_call")
+		assert.Nil(t, tracker.GetCordonInfo("a", "evm:1", "eth_call"))
+		clock.Advance(time.Second)
+		tracker.Cordon("a", "evm:1", "eth_call", "flaky")
+		assert.Equal(t, clock.Now(), tracker.GetCordonInfo("a", "evm:1", "eth_call").Since)
+	})
+
+	t.Run("AutoCordonExpiresAtWindowEnd", func(t *testing.T) {
+		clock := fakeclock.New(time.Unix(1700000000, 0))
+		tracker := NewTracker(&log.Logger, "test-cordon-info", time.Minute)
+		tracker.SetClock(clock)
+		tracker.SetReconnectCordonThreshold(1)
+		tracker.RecordUpstreamReconnect("a", "evm:1")
+		tracker.RecordUpstreamReconnect("a", "evm:1")
+
+		info := tracker.GetCordonInfo("a", "evm:1", "*")
+		require.NotNil(t, info)
+		assert.Equal(t, CordonSourceTracker, info.Source)
+		assert.Equal(t, clock.Now().Add(time.Minute), info.ExpiresAt)
+	})
+
+	t.Run("AbsentFromJSONUnlessCordoned", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-cordon-info", time.Minute)
+		m := tracker.GetUpstreamMethodMetrics("a", "evm:1", "eth_call")
+		b, err := m.MarshalJSON()
+		require.NoError(t, err)
+		assert.NotContains(t, string(b), "cordonInfo")
+		assert.NotContains(t, string(b), "cordonedReason")
+		assert.Contains(t, string(b), `"cordoned":false`)
+
+		tracker.Cordon("a", "evm:1", "eth_call", "maintenance")
+		b, err = m.MarshalJSON()
+		require.NoError(t, err)
+		assert.Contains(t, string(b), `"cordonedReason":"maintenance"`)
+		assert.Contains(t, string(b), `"source":"manual"`)
+		assert.NotContains(t, string(b), "expiresAt")
+
+		m.Reset()
+		assert.Nil(t, m.CordonInfo())
+		assert.False(t, tracker.SelectionView("a", "evm:1", "eth_call").Cordoned)
+	})
+}
+
+func TestCordonGuard(t *testing.T) {
+	vetoed := func(t *testing.T, ups string) float64 {
+		return metricstest.Value(t, "erpc_upstream_cordon_vetoed_total", map[string]string{
+			"project": "test-cordon-guard", "network": "evm:1", "upstream": ups, "category": "*",
+		})
+	}
+	reconnect := func(tracker *Tracker, ups string) {
+		tracker.RecordUpstreamReconnect(ups, "evm:1")
+		tracker.RecordUpstreamReconnect(ups, "evm:1")
+	}
+
+	t.Run("KeepsLastHealthyUpstream", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-cordon-guard", time.Minute)
+		tracker.SetReconnectCordonThreshold(1)
+		var reasons []string
+		tracker.SetCordonGuard(func(ups, network, method, reason string) bool {
+			reasons = append(reasons, reason)
+			for _, other := range []string{"a", "b"} {
+				if other != ups && !tracker.IsCordoned(other, network, method) {
+					return true
+				}
+			}
+			return false
+		})
+		before := vetoed(t, "b")
+
+		reconnect(tracker, "a")
+		assert.True(t, tracker.IsCordoned("a", "evm:1", "*"))
+
+		reconnect(tracker, "b")
+		assert.False(t, tracker.IsCordoned("b", "evm:1", "*"))
+		assert.False(t, tracker.EvaluateReconnectCordon("b", "evm:1"))
+		assert.Equal(t, before+2, vetoed(t, "b"))
+		require.NotEmpty(t, reasons)
+		assert.Contains(t, reasons[len(reasons)-1], "reconnected 2 times")
+
+		// Once another upstream is back the vetoed cordon applies on the next evaluation
+		tracker.Uncordon("a", "evm:1", "*")
+		assert.True(t, tracker.EvaluateReconnectCordon("b", "evm:1"))
+		assert.True(t, tracker.IsCordoned("b", "evm:1", "*"))
+	})
+
+	t.Run("ManualCordonIsNotAffected", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-cordon-guard", time.Minute)
+		tracker.SetCordonGuard(func(ups, network, method, reason string) bool { return false })
+
+		tracker.Cordon("a", "evm:1", "*", "manual")
+		assert.True(t, tracker.IsCordoned("a", "evm:1", "*"))
+	})
+
+	t.Run("RemovedWithNil", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-cordon-guard", time.Minute)
+		tracker.SetReconnectCordonThreshold(1)
+		tracker.SetCordonGuard(func(ups, network, method, reason string) bool { return false })
+		tracker.SetCordonGuard(nil)
+
+		reconnect(tracker, "a")
+		assert.True(t, tracker.IsCordoned("a", "evm:1", "*"))
+	})
+}
+
+func TestCordonPrecedence(t *testing.T) {
+	sources := []string{CordonSourceManual, CordonSourceTracker, CordonSourcePolicy}
+	cordonBy := func(tracker *Tracker, source string) {
+		tracker.CordonWithInfo("a", "evm:1", "eth_call", CordonInfo{Reason: "by " + source, Source: source})
+	}
+	transitions := func(t *testing.T, origin, action string) float64 {
+		return metricstest.Value(t, "erpc_upstream_cordon_transition_total", map[string]string{
+			"project": "test-cordon-precedence", "network": "evm:1", "upstream": "a", "category": "eth_call",
+			"origin": origin, "action": action,
+		})
+	}
+
+	t.Run("Cordon", func(t *testing.T) {
+		for _, holder := range sources {
+			for _, requester := range sources {
+				t.Run(holder+"Then"+requester, func(t *testing.T) {
+					clock := fakeclock.New(time.Unix(1700000000, 0))
+					tracker := NewTracker(&log.Logger, "test-cordon-precedence", time.Minute)
+					tracker.SetClock(clock)
+					cordonBy(tracker, holder)
+					clock.Advance(time.Second)
+					cordonBy(tracker, requester)
+
+					want := requester
+					if holder == CordonSourceManual && requester == CordonSourceTracker {
+						want = holder
+					}
+					info := tracker.GetCordonInfo("a", "evm:1", "eth_call")
+					require.NotNil(t, info)
+					assert.Equal(t, want, info.Source)
+					assert.Equal(t, "by "+want, info.Reason)
+					assert.Equal(t, time.Unix(1700000000, 0), info.Since)
+				})
+			}
+		}
+	})
+
+	t.Run("Uncordon", func(t *testing.T) {
+		for _, holder := range sources {
+			for _, requester := range sources {
+				t.Run(holder+"Then"+requester, func(t *testing.T) {
+					tracker := NewTracker(&log.Logger, "test-cordon-precedence", time.Minute)
+					cordonBy(tracker, holder)
+
+					lifted := holder != CordonSourceManual || requester == CordonSourceManual
+					assert.Equal(t, lifted, tracker.UncordonWithSource("a", "evm:1", "eth_call", requester))
+					assert.Equal(t, !lifted, tracker.IsCordoned("a", "evm:1", "eth_call"))
+				})
+			}
+		}
+	})
+
+	t.Run("ManualCordonOutlivesAutomation", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-cordon-precedence", time.Minute)
+		tracker.SetReconnectCordonThreshold(1)
+		tracker.Cordon("a", "evm:1", "*", "maintenance")
+		tracker.RecordUpstreamReconnect("a", "evm:1")
+		tracker.RecordUpstreamReconnect("a", "evm:1")
+		assert.Equal(t, CordonSourceManual, tracker.GetCordonInfo("a", "evm:1", "*").Source)
+
+		assert.False(t, tracker.UncordonWithSource("a", "evm:1", "*", CordonSourceTracker))
+		assert.True(t, tracker.IsCordoned("a", "evm:1", "eth_call"))
+		tracker.Uncordon("a", "evm:1", "*")
+		assert.False(t, tracker.IsCordoned("a", "evm:1", "eth_call"))
+	})
+
+	t.Run("OperatorLiftsReappliedCordons", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-cordon-precedence", time.Minute)
+		require.NoError(t, tracker.SetErrorRateCordon("evm:1", "eth_call", &ErrorRateCordonConfig{MaxErrorRate: 0.5, MinSamples: 10, CleanWindows: 100}))
+		tracker.SetExpectedChainId("evm:1", 1)
+		simulateRequestMetrics(tracker, "evm:1", "a", "eth_call", 10, 8)
+		tracker.rollWindow(time.Now())
+		tracker.RecordUpstreamChainId("b", "evm:1", 137)
+		require.True(t, tracker.IsCordoned("a", "evm:1", "eth_call"))
+		require.True(t, tracker.IsCordoned("b", "evm:1", "*"))
+
+		tracker.Uncordon("a", "evm:1", "eth_call")
+		tracker.Uncordon("b", "evm:1", "*")
+		tracker.rollWindow(time.Now())
+		assert.False(t, tracker.IsCordoned("a", "evm:1", "eth_call"))
+		assert.False(t, tracker.IsCordoned("b", "evm:1", "*"))
+		assert.Zero(t, tracker.errorRateCordonsActive.Load())
+		mismatch, _ := tracker.ChainIdMismatch("b", "evm:1")
+		assert.True(t, mismatch, "the mismatch is still reported")
+
+		// A new error rate breach or chain id mismatch cordons again
+		simulateRequestMetrics(tracker, "evm:1", "a", "eth_call", 10, 8)
+		tracker.rollWindow(time.Now())
+		assert.True(t, tracker.IsCordoned("a", "evm:1", "eth_call"))
+		tracker.RecordUpstreamChainId("b", "evm:1", 1)
+		tracker.RecordUpstreamChainId("b", "evm:1", 137)
+		assert.True(t, tracker.IsCordoned("b", "evm:1", "*"))
+	})
+
+	t.Run("OperatorLiftsAutoCordon", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-cordon-precedence", time.Minute)
+		tracker.SetReconnectCordonThreshold(1)
+		tracker.RecordUpstreamReconnect("a", "evm:1")
+		tracker.RecordUpstreamReconnect("a", "evm:1")
+		require.True(t, tracker.IsCordoned("a", "evm:1", "*"))
+
+		tracker.Uncordon("a", "evm:1", "*")
+		assert.False(t, tracker.IsCordoned("a", "evm:1", "*"))
+	})
+
+	t.Run("SourceIsExposedWithTheReason", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-cordon-precedence", time.Minute)
+		tracker.CordonWithInfo("a", "evm:1", "eth_call", CordonInfo{Reason: CordonReasonErrorRate, Source: CordonSourceTracker})
+		assert.Equal(t, "auto:ErrorRate", tracker.SelectionView("a", "evm:1", "eth_call").CordonedSource)
+		b, err := tracker.GetUpstreamMethodMetrics("a", "evm:1", "eth_call").MarshalJSON()
+		require.NoError(t, err)
+		assert.Contains(t, string(b), `"cordonedSource":"auto:ErrorRate"`)
+		assert.Contains(t, string(b), `"origin":"auto:ErrorRate"`)
+
+		tracker.Uncordon("a", "evm:1", "eth_call")
+		tracker.CordonWithInfo("a", "evm:1", "*", CordonInfo{Reason: "excluded", Source: CordonSourcePolicy})
+		assert.Equal(t, CordonOriginExternal, tracker.SelectionView("a", "evm:1", "eth_call").CordonedSource)
+		tracker.Uncordon("a", "evm:1", "*")
+		tracker.Cordon("a", "evm:1", "eth_call", "maintenance")
+		assert.Equal(t, CordonOriginManual, tracker.SelectionView("a", "evm:1", "eth_call").CordonedSource)
+	})
+
+	t.Run("TelemetryPerOrigin", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-cordon-precedence", time.Minute)
+		tracker.Uncordon("a", "evm:1", "eth_call")
+		cordons := transitions(t, CordonOriginExternal, "cordon")
+		refused := transitions(t, CordonOriginExternal, "refused")
+		uncordons := transitions(t, CordonOriginManual, "uncordon")
+
+		// Repeated cordons of the same source are a single transition
+		cordonBy(tracker, CordonSourcePolicy)
+		cordonBy(tracker, CordonSourcePolicy)
+		assert.Equal(t, cordons+1, transitions(t, CordonOriginExternal, "cordon"))
+
+		tracker.Cordon("a", "evm:1", "eth_call", "maintenance")
+		tracker.UncordonWithSource("a", "evm:1", "eth_call", CordonSourcePolicy)
+		tracker.UncordonWithSource("a", "evm:1", "eth_call", CordonSourcePolicy)
+		assert.Equal(t, refused+2, transitions(t, CordonOriginExternal, "refused"))
+
+		tracker.Uncordon("a", "evm:1", "eth_call")
+		tracker.Uncordon("a", "evm:1", "eth_call")
+		assert.Equal(t, uncordons+1, transitions(t, CordonOriginManual, "uncordon"), "only lifting a cordon counts")
+	})
+}
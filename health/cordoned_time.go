@@ -0,0 +1,164 @@
+package health
+
+import (
+	"bufio"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/telemetry"
+)
+
+// CordonedTime is the time an upstream spent cordoned on a network as a whole, i.e. with its
+// (ups, network, "*") key cordoned, since Since. It is never reset with the window.
+type CordonedTime struct {
+	// Total sums the closed cordon intervals and the one still open at read time
+	Total time.Duration `json:"total"`
+	// Since is when the accumulation started, the start of the process unless Restored
+	Since time.Time `json:"since"`
+	// Restored tells the accumulation was carried over from a previous process by RestoreFromDump,
+	// otherwise it restarted from zero at Since
+	Restored bool `json:"restored"`
+}
+
+type cordonedTime struct {
+	mu sync.Mutex
+	// closed sums the closed intervals
+	closed time.Duration
+	// openSince is the start of the current interval, zero when not cordoned
+	openSince time.Time
+	// exported is up to when the current interval was added to MetricUpstreamCordonedSecondsTotal
+	exported time.Time
+	since    time.Time
+	restored bool
+}
+
+func (t *Tracker) getCordonedTime(k duoKey) *cordonedTime {
+	if val, ok := t.cordonedTimes.Load(k); ok {
+		return val.(*cordonedTime)
+	}
+	val, _ := t.cordonedTimes.LoadOrStore(k, &cordonedTime{since: t.startedAt})
+	return val.(*cordonedTime)
+}
+
+// syncCordonedTime opens or closes the cordon interval of an upstream on a network to match
+// whether its (ups, network, "*") key is cordoned. It is idempotent so that cordons reapplied
+// right after a window reset carry on the same interval.
+func (t *Tracker) syncCordonedTime(ups, network string, cordoned bool, now time.Time) {
+	if ups == "*" || network == "*" {
+		return
+	}
+	k := duoKey{ups: ups, network: network}
+	if !cordoned {
+		if _, ok := t.cordonedTimes.Load(k); !ok {
+			return
+		}
+	}
+	c := t.getCordonedTime(k)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch {
+	case cordoned && c.openSince.IsZero():
+		c.openSince, c.exported = now, now
+	case !cordoned && !c.openSince.IsZero():
+		c.closed += now.Sub(c.openSince)
+		t.exportCordonedTimeLocked(ups, network, c, now)
+		c.openSince = time.Time{}
+	}
+}
+
+// exportCordonedTimeLocked adds the part of the open interval of c not exported yet to
+// MetricUpstreamCordonedSecondsTotal.
+func (t *Tracker) exportCordonedTimeLocked(ups, network string, c *cordonedTime, now time.Time) {
+	if d := now.Sub(c.exported); d > 0 {
+		telemetry.MetricUpstreamCordonedSecondsTotal.
+			WithLabelValues(t.projectId, network, ups, t.upstreamVendor(ups, network)).
+			Add(d.Seconds())
+	}
+	c.exported = now
+}
+
+// rollCordonedTimes syncs the intervals with the cordons lifted by a window reset, and exports
+// the intervals still open so that the counter does not wait for their end.
+func (t *Tracker) rollCordonedTimes(now time.Time) {
+	t.cordonedTimes.Range(func(key, value any) bool {
+		k := key.(duoKey)
+		cordoned := false
+		if val, ok := t.metrics.Load(tripletKey{k.ups, k.network, "*"}); ok {
+			cordoned = val.(*TrackedMetrics).Cordoned.Load()
+		}
+		t.syncCordonedTime(k.ups, k.network, cordoned, now)
+		c := value.(*cordonedTime)
+		c.mu.Lock()
+		if !c.openSince.IsZero() {
+			t.exportCordonedTimeLocked(k.ups, k.network, c, now)
+		}
+		c.mu.Unlock()
+		return true
+	})
+}
+
+// GetCordonedTime returns the time an upstream spent cordoned on a network, see CordonedTime.
+func (t *Tracker) GetCordonedTime(ups, network string) CordonedTime {
+	network = t.canonicalNetwork(network)
+	return t.cordonedTimeOf(duoKey{ups: ups, network: network}, t.clock.Now())
+}
+
+func (t *Tracker) cordonedTimeOf(k duoKey, now time.Time) CordonedTime {
+	val, ok := t.cordonedTimes.Load(k)
+	if !ok {
+		return CordonedTime{Since: t.startedAt}
+	}
+	c := val.(*cordonedTime)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ct := CordonedTime{Total: c.closed, Since: c.since, Restored: c.restored}
+	if !c.openSince.IsZero() {
+		ct.Total += now.Sub(c.openSince)
+	}
+	return ct
+}
+
+// RestoreFromDump carries over the cordoned times of the last snapshot of a dump written by
+// StartPeriodicDump, typically by a previous process, so that they survive restarts. It must be
+// called once at startup, before the tracker writes to the same dump. Upstreams missing from the
+// snapshot restart from zero.
+func (t *Tracker) RestoreFromDump(r io.Reader) error {
+	var last []byte
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		if line := scanner.Bytes(); len(line) > 0 {
+			last = append(last[:0], line...)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if last == nil {
+		return nil
+	}
+
+	var dump struct {
+		Networks map[string]map[string]struct {
+			CordonedTime CordonedTime
+		} `json:"networks"`
+	}
+	if err := common.SonicCfg.Unmarshal(last, &dump); err != nil {
+		return err
+	}
+	for network, upstreams := range dump.Networks {
+		for ups, s := range upstreams {
+			c := t.getCordonedTime(duoKey{ups: ups, network: t.canonicalNetwork(network)})
+			c.mu.Lock()
+			c.closed += s.CordonedTime.Total
+			if !s.CordonedTime.Since.IsZero() {
+				c.since = s.CordonedTime.Since
+			}
+			c.restored = true
+			c.mu.Unlock()
+		}
+	}
+	return nil
+}
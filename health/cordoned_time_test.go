@@ -0,0 +1,87 @@
+package health
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health/internal/fakeclock"
+	"github.com/erpc/erpc/health/internal/metricstest"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCordonedTime(t *testing.T) {
+	networkID := "evm:123"
+	start := time.Unix(1700000000, 0)
+	newTracker := func(project string) (*Tracker, *fakeclock.FakeClock) {
+		clock := fakeclock.New(start)
+		tracker := NewTracker(&log.Logger, project, time.Hour)
+		tracker.SetClock(clock)
+		tracker.RecordUpstreamRequest("a", networkID, "eth_call")
+		return tracker, clock
+	}
+
+	t.Run("SumsClosedAndOpenIntervals", func(t *testing.T) {
+		tracker, clock := newTracker("test-cordoned-time")
+		tracker.Cordon("a", networkID, "*", "maintenance")
+		clock.Advance(10 * time.Minute)
+		tracker.Uncordon("a", networkID, "*")
+		clock.Advance(5 * time.Minute)
+		tracker.Cordon("a", networkID, "eth_call", "method only")
+		clock.Advance(5 * time.Minute)
+		tracker.Cordon("a", networkID, "*", "maintenance")
+		tracker.Cordon("a", networkID, "*", "still maintenance")
+		clock.Advance(3 * time.Minute)
+
+		ct := tracker.GetCordonedTime("a", networkID)
+		assert.Equal(t, 13*time.Minute, ct.Total)
+		assert.Equal(t, start, ct.Since)
+		assert.False(t, ct.Restored)
+		assert.Equal(t, 13*time.Minute, tracker.GetNetworkUpstreamsMetrics(networkID, "*")["a"].CordonedTime.Total)
+		assert.Equal(t, CordonedTime{Since: start}, tracker.GetCordonedTime("b", networkID))
+
+		labels := map[string]string{"project": "test-cordoned-time", "network": networkID, "upstream": "a"}
+		assert.Equal(t, 600.0, metricstest.Value(t, "erpc_upstream_cordoned_seconds_total", labels), "open intervals wait for the window")
+		tracker.rollWindow(clock.Now())
+		assert.Equal(t, 13*time.Minute, tracker.GetCordonedTime("a", networkID).Total, "never reset with the window")
+		assert.Equal(t, 780.0, metricstest.Value(t, "erpc_upstream_cordoned_seconds_total", labels))
+	})
+
+	t.Run("ReappliedCordonsCarryOn", func(t *testing.T) {
+		tracker, clock := newTracker("test-cordoned-time-reapplied")
+		require.NoError(t, tracker.SetCordonRecovery(CordonReasonHandshakeFailing, &CordonRecoveryConfig{Interval: time.Minute, Evaluations: 100}))
+		tracker.autoCordonWithInfo("a", networkID, "*", CordonInfo{Reason: CordonReasonHandshakeFailing, Source: CordonSourceTracker})
+		tracker.Cordon("b", networkID, "*", "maintenance")
+		clock.Advance(2 * time.Minute)
+		tracker.rollWindow(clock.Now())
+		clock.Advance(time.Minute)
+
+		require.True(t, tracker.IsCordoned("a", networkID, "*"))
+		assert.Equal(t, 3*time.Minute, tracker.GetCordonedTime("a", networkID).Total)
+		assert.False(t, tracker.IsCordoned("b", networkID, "*"), "lifted by the window reset")
+		assert.Equal(t, 2*time.Minute, tracker.GetCordonedTime("b", networkID).Total)
+	})
+
+	t.Run("RestoredFromDump", func(t *testing.T) {
+		tracker, clock := newTracker("test-cordoned-time-restore")
+		tracker.Cordon("a", networkID, "*", "maintenance")
+		clock.Advance(time.Hour)
+		var dump bytes.Buffer
+		require.NoError(t, tracker.writeSnapshotDump(&dump, clock.Now()))
+		clock.Advance(time.Minute)
+		require.NoError(t, tracker.writeSnapshotDump(&dump, clock.Now()))
+
+		restarted, clock := newTracker("test-cordoned-time-restore")
+		clock.Advance(24 * time.Hour)
+		require.NoError(t, restarted.RestoreFromDump(&dump))
+		restarted.Cordon("a", networkID, "*", "maintenance")
+		clock.Advance(time.Minute)
+
+		ct := restarted.GetCordonedTime("a", networkID)
+		assert.Equal(t, 62*time.Minute, ct.Total)
+		assert.Equal(t, start.UTC(), ct.Since.UTC(), "the dump stores times in UTC")
+		assert.True(t, ct.Restored)
+	})
+}
@@ -0,0 +1,146 @@
+package health
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// MethodCostClass groups methods by how expensive they are to serve, so that the latency of an
+// upstream serving mostly heavy methods can be compared with one serving mostly light ones.
+type MethodCostClass string
+
+const (
+	MethodCostLight  MethodCostClass = "light"
+	MethodCostMedium MethodCostClass = "medium"
+	MethodCostHeavy  MethodCostClass = "heavy"
+)
+
+// LatencyCostClasses are the method cost classes of a network with the latency expected from
+// each of them, see NormalizedLatencyScore.
+type LatencyCostClasses struct {
+	// Methods maps methods to their cost class, methods not listed are MethodCostMedium
+	Methods map[string]MethodCostClass
+	// Baselines are the expected p90 latency of each class, methods of a class without a baseline
+	// are left out of the score
+	Baselines map[MethodCostClass]time.Duration
+	// ScoreNormalized makes selection score the latency of the upstreams of the network on their
+	// NormalizedLatencyScore instead of their raw p90 latency
+	ScoreNormalized bool
+}
+
+func (c *LatencyCostClasses) validate(network string) error {
+	for method, class := range c.Methods {
+		if !class.valid() {
+			return fmt.Errorf("unknown cost class %q of method %s on network %s", class, method, network)
+		}
+	}
+	for class, baseline := range c.Baselines {
+		if !class.valid() {
+			return fmt.Errorf("unknown cost class %q on network %s", class, network)
+		}
+		if baseline <= 0 {
+			return fmt.Errorf("latency baseline of cost class %s on network %s must be positive, got %v", class, network, baseline)
+		}
+	}
+	return nil
+}
+
+func (c MethodCostClass) valid() bool {
+	return c == MethodCostLight || c == MethodCostMedium || c == MethodCostHeavy
+}
+
+// SetLatencyCostClasses configures the method cost classes of a network, nil removes them so
+// that the network has no NormalizedLatencyScore.
+func (t *Tracker) SetLatencyCostClasses(network string, classes *LatencyCostClasses) error {
+	network = t.canonicalNetwork(network)
+	if classes == nil {
+		t.latencyCostClasses.Delete(network)
+		return nil
+	}
+	if err := classes.validate(network); err != nil {
+		return err
+	}
+	methods := make(map[string]MethodCostClass, len(classes.Methods))
+	for method, class := range classes.Methods {
+		methods[t.normalizeMethod(method)] = class
+	}
+	baselines := make(map[MethodCostClass]time.Duration, len(classes.Baselines))
+	for class, baseline := range classes.Baselines {
+		baselines[class] = baseline
+	}
+	t.latencyCostClasses.Store(network, &LatencyCostClasses{
+		Methods:         methods,
+		Baselines:       baselines,
+		ScoreNormalized: classes.ScoreNormalized,
+	})
+	return nil
+}
+
+func (t *Tracker) latencyCostClassesOf(network string) *LatencyCostClasses {
+	if val, ok := t.latencyCostClasses.Load(network); ok {
+		return val.(*LatencyCostClasses)
+	}
+	return nil
+}
+
+// ScoresNormalizedLatency tells whether selection should score the latency of the upstreams of a
+// network on their NormalizedLatencyScore, see LatencyCostClasses.ScoreNormalized.
+func (t *Tracker) ScoresNormalizedLatency(network string) bool {
+	network = t.canonicalNetwork(network)
+	c := t.latencyCostClassesOf(network)
+	return c != nil && c.ScoreNormalized
+}
+
+// NormalizedLatencyScore is the p90 latency of an upstream on a network relative to the baseline
+// of the cost class of each method, averaged over its methods weighted by its requests within the
+// window. A score of 1 means the upstream is exactly as fast as expected for its traffic mix,
+// whatever the mix. It is false when the network has no cost classes or the upstream no latency
+// for any method of a class with a baseline.
+func (t *Tracker) NormalizedLatencyScore(ups, network string) (float64, bool) {
+	network = t.canonicalNetwork(network)
+	return t.normalizedLatencyScoreOf(ups, network)
+}
+
+func (t *Tracker) normalizedLatencyScoreOf(ups, network string) (float64, bool) {
+	classes := t.latencyCostClassesOf(network)
+	if classes == nil {
+		return 0, false
+	}
+	methods, ok := t.upstreamMethods.Load(duoKey{ups: ups, network: network})
+	if !ok {
+		return 0, false
+	}
+	var sum, weight float64
+	methods.(*sync.Map).Range(func(key, _ any) bool {
+		method := key.(string)
+		if isAggregateMethod(method) {
+			return true
+		}
+		class, ok := classes.Methods[method]
+		if !ok {
+			class = MethodCostMedium
+		}
+		baseline := classes.Baselines[class]
+		if baseline <= 0 {
+			return true
+		}
+		val, ok := t.metrics.Load(tripletKey{ups, network, method})
+		if !ok {
+			return true
+		}
+		m := val.(*TrackedMetrics)
+		requests := m.RequestsTotal.Load()
+		if requests == 0 || !m.ResponseQuantiles.HasSamples() {
+			return true
+		}
+		p90 := m.ResponseQuantiles.GetQuantile(0.9)
+		sum += float64(requests) * p90.Seconds() / baseline.Seconds()
+		weight += float64(requests)
+		return true
+	})
+	if weight == 0 {
+		return 0, false
+	}
+	return sum / weight, true
+}
@@ -0,0 +1,105 @@
+package health_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizedLatencyScore(t *testing.T) {
+	networkID := "evm:123"
+	classes := &health.LatencyCostClasses{
+		Methods: map[string]health.MethodCostClass{
+			"eth_blockNumber": health.MethodCostLight,
+			"eth_getLogs":     health.MethodCostHeavy,
+		},
+		Baselines: map[health.MethodCostClass]time.Duration{
+			health.MethodCostLight: 100 * time.Millisecond,
+			health.MethodCostHeavy: time.Second,
+		},
+	}
+	record := func(tracker *health.Tracker, ups, method string, n int, d time.Duration) {
+		for i := 0; i < n; i++ {
+			tracker.RecordUpstreamRequest(ups, networkID, method)
+			tracker.RecordUpstreamDuration(ups, networkID, method, d, "none")
+		}
+	}
+
+	t.Run("ComparesAsymmetricTrafficMixes", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		require.NoError(t, tracker.SetLatencyCostClasses(networkID, classes))
+
+		// Mostly heavy traffic, as fast as expected
+		record(tracker, "a", "eth_getLogs", 90, time.Second)
+		record(tracker, "a", "eth_blockNumber", 10, 100*time.Millisecond)
+		// Mostly light traffic, as fast as expected
+		record(tracker, "b", "eth_blockNumber", 95, 100*time.Millisecond)
+		record(tracker, "b", "eth_getLogs", 5, time.Second)
+		// Same mix as b, twice slower on light methods
+		record(tracker, "c", "eth_blockNumber", 95, 200*time.Millisecond)
+		record(tracker, "c", "eth_getLogs", 5, time.Second)
+		// Same mix as a, twice slower on heavy methods
+		record(tracker, "d", "eth_getLogs", 90, 2*time.Second)
+		record(tracker, "d", "eth_blockNumber", 10, 100*time.Millisecond)
+
+		// Raw p90 latencies rank a far behind b although both match their baselines
+		assert.Greater(t, tracker.GetUpstreamMethodMetrics("a", networkID, "*").ResponseQuantiles.GetQuantile(0.9),
+			5*tracker.GetUpstreamMethodMetrics("b", networkID, "*").ResponseQuantiles.GetQuantile(0.9))
+
+		for ups, expected := range map[string]float64{
+			"a": 1,
+			"b": 1,
+			"c": (95*2.0 + 5*1.0) / 100,
+			"d": (90*2.0 + 10*1.0) / 100,
+		} {
+			score, ok := tracker.NormalizedLatencyScore(ups, networkID)
+			require.True(t, ok, ups)
+			assert.InDelta(t, expected, score, 0.03, ups)
+		}
+
+		snapshot := tracker.GetNetworkUpstreamsMetrics(networkID, "*")
+		assert.InDelta(t, 1.95, snapshot["c"].NormalizedLatencyScore, 0.03)
+	})
+
+	t.Run("LeavesOutClassesWithoutBaseline", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		require.NoError(t, tracker.SetLatencyCostClasses(networkID, classes))
+
+		// eth_call is medium, which has no baseline
+		record(tracker, "a", "eth_call", 1000, 5*time.Second)
+		_, ok := tracker.NormalizedLatencyScore("a", networkID)
+		assert.False(t, ok)
+
+		record(tracker, "a", "eth_blockNumber", 10, 300*time.Millisecond)
+		score, ok := tracker.NormalizedLatencyScore("a", networkID)
+		require.True(t, ok)
+		assert.InDelta(t, 3, score, 0.05)
+	})
+
+	t.Run("ConfigurePerNetwork", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		record(tracker, "a", "eth_blockNumber", 10, 100*time.Millisecond)
+		_, ok := tracker.NormalizedLatencyScore("a", networkID)
+		assert.False(t, ok, "no cost classes configured")
+		assert.False(t, tracker.ScoresNormalizedLatency(networkID))
+
+		scoring := *classes
+		scoring.ScoreNormalized = true
+		require.NoError(t, tracker.SetLatencyCostClasses(networkID, &scoring))
+		assert.True(t, tracker.ScoresNormalizedLatency(networkID))
+		assert.False(t, tracker.ScoresNormalizedLatency("evm:1"))
+
+		require.NoError(t, tracker.SetLatencyCostClasses(networkID, nil))
+		assert.False(t, tracker.ScoresNormalizedLatency(networkID))
+
+		assert.Error(t, tracker.SetLatencyCostClasses(networkID, &health.LatencyCostClasses{
+			Methods: map[string]health.MethodCostClass{"eth_call": "huge"},
+		}))
+		assert.Error(t, tracker.SetLatencyCostClasses(networkID, &health.LatencyCostClasses{
+			Baselines: map[health.MethodCostClass]time.Duration{health.MethodCostLight: 0},
+		}))
+	})
+}
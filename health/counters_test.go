@@ -0,0 +1,172 @@
+package health_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCounters covers what every per-key counter of TrackedMetrics shares: each recording counts
+// once on the key of the upstream and on the aggregates of the upstream and of the network, under
+// the canonical network identifier, is exposed in the JSON output and lasts until the window ends.
+func TestCounters(t *testing.T) {
+	networkID := "evm:123"
+	aliasID := "evm:0x7b"
+	windowSize := time.Minute
+
+	comparison := func(network, method string, participants ...health.ConsensusParticipant) health.ConsensusComparison {
+		return health.ConsensusComparison{Network: network, Method: method, Participants: participants}
+	}
+
+	cases := []struct {
+		name    string
+		method  string
+		field   string
+		record  func(tracker *health.Tracker, network, method string)
+		counter func(m *health.TrackedMetrics) int64
+	}{
+		{
+			name:   "BehindHeadErrors",
+			method: "eth_call",
+			field:  "behindHeadErrorsTotal",
+			record: func(tracker *health.Tracker, network, method string) {
+				tracker.RecordUpstreamBehindHeadError("a", network, method, upstreamError(-32000, "header not found"))
+			},
+			counter: func(m *health.TrackedMetrics) int64 { return m.BehindHeadErrorsTotal.Load() },
+		},
+		{
+			name:   "CancelledByClient",
+			method: "eth_call",
+			field:  "cancelledByClient",
+			record: func(tracker *health.Tracker, network, method string) {
+				tracker.RecordUpstreamCancelled("a", network, method, health.CancelCauseClient, 10*time.Millisecond)
+			},
+			counter: func(m *health.TrackedMetrics) int64 { return m.CancelledByClientTotal.Load() },
+		},
+		{
+			name:   "CancelledByHedge",
+			method: "eth_call",
+			field:  "cancelledByHedge",
+			record: func(tracker *health.Tracker, network, method string) {
+				tracker.RecordUpstreamCancelled("a", network, method, health.CancelCauseHedge, 10*time.Millisecond)
+			},
+			counter: func(m *health.TrackedMetrics) int64 { return m.CancelledByHedgeTotal.Load() },
+		},
+		{
+			name:   "MalformedResponses",
+			method: "eth_call",
+			field:  "malformedResponsesTotal",
+			record: func(tracker *health.Tracker, network, method string) {
+				tracker.RecordUpstreamMalformedResponse("a", network, method, health.MalformedInvalidJson)
+			},
+			counter: func(m *health.TrackedMetrics) int64 { return m.MalformedResponsesTotal.Load() },
+		},
+		{
+			name:   "Mismatches",
+			method: "eth_getBlockByNumber",
+			field:  "mismatchesTotal",
+			record: func(tracker *health.Tracker, network, method string) {
+				tracker.RecordUpstreamMismatch("a", network, method)
+			},
+			counter: func(m *health.TrackedMetrics) int64 { return m.MismatchesTotal.Load() },
+		},
+		{
+			name:   "PartialResponses",
+			method: "eth_getLogs",
+			field:  "partialResponsesTotal",
+			record: func(tracker *health.Tracker, network, method string) {
+				tracker.RecordUpstreamPartialResponse("a", network, method)
+			},
+			counter: func(m *health.TrackedMetrics) int64 { return m.PartialResponsesTotal.Load() },
+		},
+		{
+			name:   "ConsensusMinority",
+			method: "eth_getBalance",
+			field:  "consensusMinorityTotal",
+			record: func(tracker *health.Tracker, network, method string) {
+				tracker.RecordConsensusComparison(comparison(network, method,
+					health.ConsensusParticipant{Upstream: "a", ResultHash: "0x2"},
+					health.ConsensusParticipant{Upstream: "b", ResultHash: "0x1"},
+					health.ConsensusParticipant{Upstream: "c", ResultHash: "0x1"},
+				))
+			},
+			counter: func(m *health.TrackedMetrics) int64 { return m.ConsensusMinorityTotal.Load() },
+		},
+		{
+			name:   "ShortResults",
+			method: "eth_getLogs",
+			field:  "shortResultsTotal",
+			record: func(tracker *health.Tracker, network, method string) {
+				tracker.RecordConsensusComparison(comparison(network, method,
+					health.ConsensusParticipant{Upstream: "a", ResultHash: "0x5", IsList: true, ListLength: 5},
+					health.ConsensusParticipant{Upstream: "b", ResultHash: "0x9", IsList: true, ListLength: 9},
+					health.ConsensusParticipant{Upstream: "c", ResultHash: "0x9", IsList: true, ListLength: 9},
+				))
+			},
+			counter: func(m *health.TrackedMetrics) int64 { return m.ShortResultsTotal.Load() },
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Run("AggregatedUnderTheCanonicalNetwork", func(t *testing.T) {
+				tracker, _ := newFakeClockTracker(t, windowSize)
+				tc.record(tracker, aliasID, tc.method)
+				tc.record(tracker, networkID, tc.method)
+
+				m := tracker.GetUpstreamMethodMetrics("a", networkID, tc.method)
+				assert.Equal(t, int64(2), tc.counter(m))
+				assert.Same(t, m, tracker.GetUpstreamMethodMetrics("a", aliasID, tc.method))
+				assert.Equal(t, int64(2), tc.counter(tracker.GetUpstreamMethodMetrics("a", networkID, "*")))
+				assert.Equal(t, int64(2), tc.counter(tracker.GetNetworkMethodMetrics(networkID, tc.method)))
+				assert.Equal(t, int64(2), tc.counter(tracker.GetNetworkMethodMetrics(networkID, "*")))
+				assert.Zero(t, tc.counter(tracker.GetUpstreamMethodMetrics("a", networkID, "eth_unrelated")))
+
+				b, err := m.MarshalJSON()
+				require.NoError(t, err)
+				assert.Contains(t, string(b), fmt.Sprintf(`"%s":2`, tc.field))
+			})
+
+			t.Run("KeptUntilTheWindowEnds", func(t *testing.T) {
+				tracker, clock := newFakeClockTracker(t, windowSize)
+				tc.record(tracker, networkID, tc.method)
+				m := tracker.GetUpstreamMethodMetrics("a", networkID, tc.method)
+				network := tracker.GetNetworkMethodMetrics(networkID, tc.method)
+
+				clock.Advance(windowSize - time.Millisecond)
+				assert.Equal(t, int64(1), tc.counter(m))
+
+				clock.Advance(time.Millisecond)
+				assert.Eventually(t, func() bool {
+					return tc.counter(m) == 0 && tc.counter(network) == 0
+				}, time.Second, time.Millisecond)
+
+				tc.record(tracker, networkID, tc.method)
+				assert.Equal(t, int64(1), tc.counter(m))
+			})
+
+			t.Run("Concurrent", func(t *testing.T) {
+				tracker, _ := newFakeClockTracker(t, windowSize)
+				var wg sync.WaitGroup
+				for g := 0; g < 8; g++ {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						for i := 0; i < 50; i++ {
+							tc.record(tracker, networkID, tc.method)
+						}
+					}()
+				}
+				wg.Wait()
+
+				assert.Equal(t, int64(8*50), tc.counter(tracker.GetUpstreamMethodMetrics("a", networkID, tc.method)))
+				assert.Equal(t, int64(8*50), tc.counter(tracker.GetNetworkMethodMetrics(networkID, "*")))
+			})
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package health
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/erpc/erpc/telemetry"
+)
+
+// CordonReasonDataDisagreement is the reason of cordons applied by SetDisagreementCordon.
+const CordonReasonDataDisagreement = "DataDisagreement"
+
+// ConsensusParticipant is the response of one upstream to a request compared across upstreams.
+type ConsensusParticipant struct {
+	Upstream string
+	// ResultHash identifies the response, participants with equal hashes agree. Participants
+	// without a hash (e.g. which failed) are left out of the comparison.
+	ResultHash string
+	// IsList tells the response is a list of ListLength entries, e.g. the logs of eth_getLogs,
+	// which lets the comparison tell results shorter than the majority, see ShortResultsTotal
+	IsList     bool
+	ListLength int
+}
+
+// ConsensusComparison is the comparison of the responses of several upstreams to the same request,
+// e.g. in consensus or integrity mode.
+type ConsensusComparison struct {
+	Network      string
+	Method       string
+	Participants []ConsensusParticipant
+}
+
+type disagreementCordon struct {
+	maxRate        float64
+	minComparisons int64
+}
+
+// DisagreementRate is ConsensusMinorityTotal over the comparisons the upstream took part in.
+func (m *TrackedMetrics) DisagreementRate() float64 {
+	minority := m.ConsensusMinorityTotal.Load()
+	return boundedRatio(minority, minority+m.ConsensusMajorityTotal.Load())
+}
+
+// SetDisagreementCordon enables cordoning an upstream for a method once it was in the minority of
+// more than maxRate of at least minComparisons comparisons within the current window, with reason
+// CordonReasonDataDisagreement. A zero maxRate disables it.
+func (t *Tracker) SetDisagreementCordon(maxRate float64, minComparisons int64) {
+	if maxRate <= 0 {
+		t.disagreementCordon.Store(nil)
+		return
+	}
+	t.disagreementCordon.Store(&disagreementCordon{maxRate: maxRate, minComparisons: max(minComparisons, 1)})
+}
+
+// RecordConsensusComparison attributes a comparison to every participant at once, so that they
+// are all judged against the same majority: the participants of the largest group of equal
+// responses count in ConsensusMajorityTotal, the others in ConsensusMinorityTotal. Without a
+// single largest group (e.g. two against two) nobody can be told wrong and nothing is recorded.
+// Participants of the minority with fewer list entries than the majority also count a short
+// result, see SetShortResultCordon.
+func (t *Tracker) RecordConsensusComparison(c ConsensusComparison) {
+	network := t.canonicalNetwork(c.Network)
+	method := t.normalizeMethod(c.Method)
+
+	groups := make(map[string]int, len(c.Participants))
+	for _, p := range c.Participants {
+		if p.ResultHash != "" {
+			groups[p.ResultHash]++
+		}
+	}
+	var majority string
+	var largest int
+	tied := false
+	for hash, size := range groups {
+		switch {
+		case size > largest:
+			majority, largest, tied = hash, size, false
+		case size == largest:
+			tied = true
+		}
+	}
+	if largest == 0 || tied {
+		return
+	}
+
+	var majorityParticipant ConsensusParticipant
+	for _, p := range c.Participants {
+		if p.ResultHash == "" {
+			continue
+		}
+		inMajority := p.ResultHash == majority
+		if inMajority {
+			majorityParticipant = p
+		}
+		for _, k := range t.getKeys(p.Upstream, network, method) {
+			m := t.getMetrics(k)
+			if inMajority {
+				m.ConsensusMajorityTotal.Add(1)
+			} else {
+				m.ConsensusMinorityTotal.Add(1)
+			}
+		}
+		result := "majority"
+		if !inMajority {
+			result = "minority"
+		}
+		telemetry.MetricUpstreamConsensusComparisonTotal.WithLabelValues(t.projectId, network, p.Upstream, t.upstreamVendor(p.Upstream, network), method, result).Inc()
+		if !inMajority {
+			t.evaluateDisagreementCordon(p.Upstream, network, method)
+		}
+	}
+	t.recordShortResults(network, method, c.Participants, majorityParticipant)
+}
+
+// evaluateDisagreementCordon cordons (ups, network, method) once its disagreement rate exceeds
+// the threshold set by SetDisagreementCordon.
+func (t *Tracker) evaluateDisagreementCordon(ups, network, method string) {
+	cfg := t.disagreementCordon.Load()
+	if cfg == nil {
+		return
+	}
+	m := t.getMetrics(tripletKey{ups, network, method})
+	minority := m.ConsensusMinorityTotal.Load()
+	comparisons := minority + m.ConsensusMajorityTotal.Load()
+	if comparisons < cfg.minComparisons {
+		return
+	}
+	rate := boundedRatio(minority, comparisons)
+	if rate <= cfg.maxRate {
+		return
+	}
+	t.autoCordonWithInfo(ups, network, method, CordonInfo{
+		Reason:    CordonReasonDataDisagreement,
+		Detail:    fmt.Sprintf("in the minority of %d out of %d comparisons (threshold %.2f)", minority, comparisons, cfg.maxRate),
+		Source:    CordonSourceTracker,
+		ExpiresAt: time.Unix(0, t.windowStart.Load()).Add(t.windowSize),
+	})
+}
@@ -0,0 +1,99 @@
+package health_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health"
+	"github.com/erpc/erpc/health/healthtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsensusDisagreements(t *testing.T) {
+	networkID := "evm:123"
+	compare := func(hashes map[string]string) health.ConsensusComparison {
+		c := health.ConsensusComparison{Network: networkID, Method: "eth_getBalance"}
+		for _, ups := range []string{"a", "b", "c", "d"} {
+			if hash, ok := hashes[ups]; ok {
+				c.Participants = append(c.Participants, health.ConsensusParticipant{Upstream: ups, ResultHash: hash})
+			}
+		}
+		return c
+	}
+
+	t.Run("AttributesMinorityAndMajority", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		tracker.RecordConsensusComparison(compare(map[string]string{"a": "0x1", "b": "0x1", "c": "0x2"}))
+		tracker.RecordConsensusComparison(compare(map[string]string{"a": "0x1", "b": "0x1", "c": "0x1"}))
+
+		a := tracker.GetUpstreamMethodMetrics("a", networkID, "eth_getBalance")
+		c := tracker.GetUpstreamMethodMetrics("c", networkID, "eth_getBalance")
+		assert.Equal(t, int64(2), a.ConsensusMajorityTotal.Load())
+		assert.Equal(t, 0.0, a.DisagreementRate())
+		assert.Equal(t, int64(1), c.ConsensusMinorityTotal.Load())
+		assert.Equal(t, 0.5, c.DisagreementRate())
+		assert.Equal(t, 0.5, tracker.GetUpstreamMethodMetrics("c", networkID, "*").DisagreementRate())
+
+		snapshot := tracker.GetNetworkUpstreamsMetrics(networkID, "eth_getBalance")
+		require.Contains(t, snapshot, "c")
+		assert.Equal(t, 0.5, snapshot["c"].DisagreementRate)
+		assert.Equal(t, 0.0, snapshot["a"].DisagreementRate)
+
+		b, err := c.MarshalJSON()
+		require.NoError(t, err)
+		assert.Contains(t, string(b), `"disagreementRate":0.5`)
+	})
+
+	t.Run("NothingRecordedWithoutSingleMajority", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		tracker.RecordConsensusComparison(compare(map[string]string{"a": "0x1", "b": "0x1", "c": "0x2", "d": "0x2"}))
+		tracker.RecordConsensusComparison(compare(map[string]string{"a": "", "b": ""}))
+
+		for _, ups := range []string{"a", "b", "c", "d"} {
+			m := tracker.GetUpstreamMethodMetrics(ups, networkID, "eth_getBalance")
+			assert.Equal(t, int64(0), m.ConsensusMajorityTotal.Load()+m.ConsensusMinorityTotal.Load(), ups)
+		}
+	})
+
+	t.Run("ParticipantsWithoutResultAreLeftOut", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		tracker.RecordConsensusComparison(compare(map[string]string{"a": "0x1", "b": "0x1", "c": ""}))
+
+		c := tracker.GetUpstreamMethodMetrics("c", networkID, "eth_getBalance")
+		assert.Equal(t, int64(0), c.ConsensusMajorityTotal.Load()+c.ConsensusMinorityTotal.Load())
+		assert.Equal(t, int64(1), tracker.GetUpstreamMethodMetrics("b", networkID, "eth_getBalance").ConsensusMajorityTotal.Load())
+	})
+
+	t.Run("CordonsTheOddOneOut", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		tracker.SetDisagreementCordon(0.5, 3)
+
+		tracker.RecordConsensusComparison(compare(map[string]string{"a": "0x1", "b": "0x1", "c": "0x2"}))
+		tracker.RecordConsensusComparison(compare(map[string]string{"a": "0x1", "b": "0x1", "c": "0x2"}))
+		assert.False(t, tracker.IsCordoned("c", networkID, "eth_getBalance"), "below the minimum comparisons")
+
+		tracker.RecordConsensusComparison(compare(map[string]string{"a": "0x1", "b": "0x1", "c": "0x2"}))
+		assert.True(t, tracker.IsCordoned("c", networkID, "eth_getBalance"))
+		assert.False(t, tracker.IsCordoned("c", networkID, "eth_call"))
+		assert.False(t, tracker.IsCordoned("a", networkID, "eth_getBalance"))
+
+		info := tracker.GetCordonInfo("c", networkID, "eth_getBalance")
+		require.NotNil(t, info)
+		assert.Equal(t, health.CordonReasonDataDisagreement, info.Reason)
+		assert.Equal(t, health.CordonSourceTracker, info.Source)
+		assert.Contains(t, info.Detail, "3 out of 3")
+	})
+
+	t.Run("RecordedInOneCall", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		r := healthtest.NewRecorder(tracker)
+		c := compare(map[string]string{"a": "0x1", "b": "0x2", "c": "0x1"})
+		r.RecordConsensusComparison(c)
+
+		calls := r.CallsTo("RecordConsensusComparison")
+		require.Len(t, calls, 1)
+		assert.Equal(t, []interface{}{c}, calls[0].Args)
+		assert.Equal(t, int64(1), tracker.GetUpstreamMethodMetrics("b", networkID, "eth_getBalance").ConsensusMinorityTotal.Load())
+	})
+}
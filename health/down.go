@@ -0,0 +1,49 @@
+package health
+
+import "sort"
+
+// HealthThresholds bounds what an upstream may lag or fail while still being considered healthy.
+// A zero threshold is not checked.
+type HealthThresholds struct {
+	MaxBlockHeadLag    int64
+	MaxFinalizationLag int64
+	MaxErrorRate       float64
+}
+
+// healthy reports whether an upstream-wide snapshot is within the thresholds and not cordoned.
+func (h HealthThresholds) healthy(s *TrackedMetricsSnapshot) bool {
+	switch {
+	case s.Cordoned:
+		return false
+	case h.MaxBlockHeadLag > 0 && s.BlockHeadLag > h.MaxBlockHeadLag:
+		return false
+	case h.MaxFinalizationLag > 0 && s.FinalizationLag > h.MaxFinalizationLag:
+		return false
+	case h.MaxErrorRate > 0 && s.ErrorRate > h.MaxErrorRate:
+		return false
+	}
+	return true
+}
+
+// DownNetworks returns the sorted networks on which no upstream is healthy, i.e. every upstream
+// is cordoned on the whole network or beyond one of the thresholds. Networks without any tracked
+// upstream are not reported.
+func (t *Tracker) DownNetworks(thresholds HealthThresholds) []string {
+	var down []string
+	t.networkUpstreams.Range(func(key, _ any) bool {
+		network := key.(string)
+		upstreams := t.GetNetworkUpstreamsMetrics(network, "*")
+		if len(upstreams) == 0 {
+			return true
+		}
+		for _, s := range upstreams {
+			if thresholds.healthy(s) {
+				return true
+			}
+		}
+		down = append(down, network)
+		return true
+	})
+	sort.Strings(down)
+	return down
+}
@@ -0,0 +1,52 @@
+package health_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownNetworks(t *testing.T) {
+	t.Run("ReportsNetworksWithoutHealthyUpstream", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		recordRequests(tracker, "evm:1", "a", "eth_call", 10, 0)
+		recordRequests(tracker, "evm:1", "b", "eth_call", 10, 0)
+		recordRequests(tracker, "evm:2", "a", "eth_call", 10, 0)
+		recordRequests(tracker, "evm:2", "c", "eth_call", 10, 0)
+
+		tracker.Cordon("a", "evm:1", "*", "maintenance")
+		tracker.Cordon("b", "evm:1", "*", "maintenance")
+		tracker.Cordon("a", "evm:2", "*", "maintenance")
+		// A method cordon leaves the upstream serving other methods
+		tracker.Cordon("c", "evm:2", "eth_call", "flaky")
+
+		assert.Equal(t, []string{"evm:1"}, tracker.DownNetworks(health.HealthThresholds{}))
+	})
+
+	t.Run("LaggingBeyondThresholds", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		for _, ups := range []string{"a", "b"} {
+			recordRequests(tracker, "evm:1", ups, "eth_call", 10, 0)
+			recordRequests(tracker, "evm:2", ups, "eth_call", 10, 0)
+		}
+		tracker.SetLatestBlockNumber("a", "evm:1", 1000)
+		tracker.SetLatestBlockNumber("b", "evm:1", 900)
+		tracker.SetLatestBlockNumber("a", "evm:2", 1000)
+		tracker.SetLatestBlockNumber("b", "evm:2", 900)
+		tracker.Cordon("a", "evm:1", "*", "maintenance")
+
+		thresholds := health.HealthThresholds{MaxBlockHeadLag: 50}
+		assert.Equal(t, []string{"evm:1"}, tracker.DownNetworks(thresholds))
+
+		recordRequests(tracker, "evm:2", "a", "eth_call", 10, 10)
+		thresholds.MaxErrorRate = 0.2
+		assert.Equal(t, []string{"evm:1", "evm:2"}, tracker.DownNetworks(thresholds))
+	})
+
+	t.Run("NothingTracked", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		assert.Empty(t, tracker.DownNetworks(health.HealthThresholds{}))
+	})
+}
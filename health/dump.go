@@ -0,0 +1,69 @@
+package health
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/erpc/erpc/common"
+)
+
+type snapshotDump struct {
+	Time time.Time `json:"time"`
+	// Networks are the snapshots of the upstreams of each network for all methods, see
+	// GetNetworkUpstreamsMetrics
+	Networks map[string]map[string]*TrackedMetricsSnapshot `json:"networks"`
+	// Forks are the fork signals of the networks with block hashes recorded, see GetForkSignal
+	Forks map[string]ForkSignal `json:"forks,omitempty"`
+	// TrafficShares are the traffic shares of the networks with intended shares reported, see
+	// GetTrafficShares
+	TrafficShares map[string]TrafficShares `json:"trafficShares,omitempty"`
+	// Project are the project aggregates keyed by method when enabled, see GetProjectMetrics
+	Project map[string]*TrackedMetricsSnapshot `json:"project,omitempty"`
+}
+
+// StartPeriodicDump writes a snapshot of every tracked network to w each interval until ctx is
+// done, as one JSON object per line stamped with the time of the dump, which gives a time series
+// of snapshots without a metrics backend. Write errors are logged and the next dump is attempted
+// anyway. The ticker follows the clock of the tracker and is created before returning.
+func (t *Tracker) StartPeriodicDump(ctx context.Context, w io.Writer, interval time.Duration) {
+	ticker := t.clock.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C():
+				if err := t.writeSnapshotDump(w, now); err != nil {
+					t.logger.Warn().Err(err).Msg("failed to dump health tracker snapshot")
+				}
+			}
+		}
+	}()
+}
+
+func (t *Tracker) writeSnapshotDump(w io.Writer, now time.Time) error {
+	dump := snapshotDump{
+		Time:     now.UTC(),
+		Networks: make(map[string]map[string]*TrackedMetricsSnapshot),
+		Forks:    t.forkSignals(),
+
+		TrafficShares: t.allTrafficShares(),
+		Project:       t.GetProjectMetrics(),
+	}
+	t.networkUpstreams.Range(func(key, _ any) bool {
+		network := key.(string)
+		dump.Networks[network] = t.GetNetworkUpstreamsMetrics(network, "*")
+		return true
+	})
+	line, err := common.SonicCfg.Marshal(dump)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	if _, err := w.Write(line); err != nil {
+		return err
+	}
+	return flushWriter(w)
+}
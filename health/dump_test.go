@@ -0,0 +1,76 @@
+package health_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) lines() [][]byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var lines [][]byte
+	scanner := bufio.NewScanner(bytes.NewReader(b.buf.Bytes()))
+	for scanner.Scan() {
+		lines = append(lines, append([]byte(nil), scanner.Bytes()...))
+	}
+	return lines
+}
+
+func TestStartPeriodicDump(t *testing.T) {
+	networkID := "evm:123"
+	tracker, clock := newFakeClockTracker(t, time.Hour)
+	recordRequests(tracker, networkID, "a", "eth_call", 10, 2)
+	recordRequests(tracker, networkID, "b", "eth_call", 5, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := &syncBuffer{}
+	start := clock.Now()
+	tracker.StartPeriodicDump(ctx, out, 10*time.Second)
+
+	assert.Empty(t, out.lines())
+	for i := 1; i <= 3; i++ {
+		clock.Advance(10 * time.Second)
+		require.Eventually(t, func() bool { return len(out.lines()) == i }, time.Second, time.Millisecond)
+	}
+
+	for i, line := range out.lines() {
+		var dump struct {
+			Time     time.Time `json:"time"`
+			Networks map[string]map[string]struct {
+				RequestsTotal int64
+				ErrorsTotal   int64
+			} `json:"networks"`
+		}
+		require.NoError(t, json.Unmarshal(line, &dump))
+		assert.True(t, start.Add(time.Duration(i+1)*10*time.Second).Equal(dump.Time))
+		assert.Equal(t, int64(10), dump.Networks[networkID]["a"].RequestsTotal)
+		assert.Equal(t, int64(2), dump.Networks[networkID]["a"].ErrorsTotal)
+		assert.Equal(t, int64(5), dump.Networks[networkID]["b"].RequestsTotal)
+	}
+
+	// No more dumps once the context is done
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	clock.Advance(time.Minute)
+	time.Sleep(10 * time.Millisecond)
+	assert.Len(t, out.lines(), 3)
+}
@@ -0,0 +1,36 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordUpstreamDurationWithQuantiles(t *testing.T) {
+	networkID := "evm:123"
+	tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+
+	p50, p99 := tracker.RecordUpstreamDurationWithQuantiles("a", networkID, "eth_call", 100*time.Millisecond, "none")
+	assert.InDelta(t, 0.1, p50, 0.002)
+	assert.InDelta(t, 0.1, p99, 0.002)
+
+	for i := 1; i <= 99; i++ {
+		p50, p99 = tracker.RecordUpstreamDurationWithQuantiles("a", networkID, "eth_call", time.Duration(i)*10*time.Millisecond, "none")
+	}
+	explicit50, ok := tracker.GetLatencyQuantile("a", networkID, "eth_call", 0.5)
+	require.True(t, ok)
+	explicit99, _ := tracker.GetLatencyQuantile("a", networkID, "eth_call", 0.99)
+	assert.Equal(t, explicit50, p50)
+	assert.Equal(t, explicit99, p99)
+	assert.InDelta(t, 0.5, p50, 0.02)
+	assert.InDelta(t, 0.98, p99, 0.03)
+
+	// Only the exact key is read, the aggregates are updated as with RecordUpstreamDuration
+	tracker.RecordUpstreamDuration("a", networkID, "eth_getBalance", 5*time.Second, "none")
+	_, p99 = tracker.RecordUpstreamDurationWithQuantiles("a", networkID, "eth_call", 10*time.Millisecond, "none")
+	assert.Less(t, p99, 5.0)
+	assert.Equal(t, int64(102), int64(tracker.GetUpstreamMethodMetrics("a", networkID, "*").ResponseQuantiles.Summary().Count))
+}
@@ -0,0 +1,94 @@
+package health
+
+import (
+	"math"
+	"sync"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/telemetry"
+)
+
+type eligibilityThresholds struct {
+	maxErrorRate    float64
+	maxBlockHeadLag int64
+}
+
+// cordonsOnly counts upstreams as eligible as long as they are not cordoned, see
+// SetEligibilityFromCordons.
+var cordonsOnly = &eligibilityThresholds{maxErrorRate: math.Inf(1), maxBlockHeadLag: math.MaxInt64}
+
+// SetEligibilityThresholds sets the error rate and block head lag an upstream must stay under to
+// count in EligibleUpstreams, the ones of the default selection policy (see
+// common.DefaultPolicyThresholds) by default. They should match the ones the selection policy
+// applies, so that the count reflects what selection actually does.
+func (t *Tracker) SetEligibilityThresholds(maxErrorRate float64, maxBlockHeadLag int64) {
+	t.eligibility.Store(&eligibilityThresholds{maxErrorRate: maxErrorRate, maxBlockHeadLag: maxBlockHeadLag})
+	t.refreshAllEligibleUpstreams()
+}
+
+// SetEligibilityFromCordons leaves the thresholds of SetEligibilityThresholds out of
+// EligibleUpstreams for a network, e.g. when its selection policy is a custom function: its
+// thresholds are unknown, but the upstreams it excludes are cordoned.
+func (t *Tracker) SetEligibilityFromCordons(network string) {
+	network = t.canonicalNetwork(network)
+	t.eligibilityFromCordons.Store(network, struct{}{})
+	t.refreshEligibleUpstreams(network)
+}
+
+// EligibleUpstreams returns the number of upstreams of a network which are neither cordoned nor
+// over the thresholds of SetEligibilityThresholds for the network as a whole, shadow upstreams
+// aside. It is refreshed on upstream-wide cordons and uncordons, and once cordons are lifted at
+// the start of each window.
+func (t *Tracker) EligibleUpstreams(network string) int {
+	network = t.canonicalNetwork(network)
+	if val, ok := t.eligibleUpstreams.Load(network); ok {
+		return val.(int)
+	}
+	return t.refreshEligibleUpstreams(network)
+}
+
+// refreshEligibleUpstreams counts the eligible upstreams of a canonical network, see
+// EligibleUpstreams, and exports the count.
+func (t *Tracker) refreshEligibleUpstreams(network string) int {
+	cfg := t.eligibilityThresholds()
+	if _, ok := t.eligibilityFromCordons.Load(network); ok {
+		cfg = cordonsOnly
+	}
+	eligible := 0
+	if set, ok := t.networkUpstreams.Load(network); ok {
+		set.(*sync.Map).Range(func(key, _ any) bool {
+			ups := key.(string)
+			if !t.IsShadowUpstream(ups) && t.isEligible(ups, network, cfg) {
+				eligible++
+			}
+			return true
+		})
+	}
+	t.eligibleUpstreams.Store(network, eligible)
+	telemetry.MetricNetworkEligibleUpstreams.WithLabelValues(t.projectId, network).Set(float64(eligible))
+	return eligible
+}
+
+func (t *Tracker) eligibilityThresholds() *eligibilityThresholds {
+	if cfg := t.eligibility.Load(); cfg != nil {
+		return cfg
+	}
+	return &eligibilityThresholds{maxErrorRate: common.DefaultPolicyMaxErrorRate, maxBlockHeadLag: common.DefaultPolicyMaxBlockHeadLag}
+}
+
+// isEligible tells if ups is neither cordoned nor over the thresholds on a canonical network.
+func (t *Tracker) isEligible(ups, network string, cfg *eligibilityThresholds) bool {
+	v := SelectionView{}
+	if val, ok := t.metrics.Load(tripletKey{ups, network, "*"}); ok {
+		v = t.selectionViewOf(val.(*TrackedMetrics), ups, network, "*")
+	}
+	return !v.Cordoned && v.ErrorRate < cfg.maxErrorRate && v.BlockHeadLag < cfg.maxBlockHeadLag
+}
+
+// refreshAllEligibleUpstreams refreshes the eligible upstreams of every tracked network.
+func (t *Tracker) refreshAllEligibleUpstreams() {
+	t.networkUpstreams.Range(func(network, _ any) bool {
+		t.refreshEligibleUpstreams(network.(string))
+		return true
+	})
+}
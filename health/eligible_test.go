@@ -0,0 +1,61 @@
+package health_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health/internal/metricstest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEligibleUpstreams(t *testing.T) {
+	networkID := "evm:123"
+	gauge := func(t *testing.T) float64 {
+		return metricstest.Value(t, "erpc_network_eligible_upstreams", map[string]string{"project": "test-project", "network": networkID})
+	}
+
+	tracker, clock := newFakeClockTracker(t, time.Minute)
+	tracker.SetEligibilityThresholds(0.5, 5)
+	for _, ups := range []string{"a", "b", "c", "d", "shadow"} {
+		tracker.RecordUpstreamRequest(ups, networkID, "eth_call")
+	}
+	tracker.SetUpstreamShadow("shadow", true)
+	tracker.RecordUpstreamFailure("b", networkID, "eth_call")
+	tracker.SetLatestBlockNumber("a", networkID, 100)
+	tracker.SetLatestBlockNumber("c", networkID, 90)
+	tracker.SetLatestBlockNumber("d", networkID, 100)
+
+	// b errors and c lags, and a method cordon does not count
+	tracker.Cordon("d", networkID, "eth_call", "test")
+	assert.Equal(t, 2, tracker.EligibleUpstreams(networkID))
+	tracker.Cordon("d", networkID, "*", "test")
+	assert.Equal(t, 1, tracker.EligibleUpstreams(networkID))
+	assert.Equal(t, 1.0, gauge(t))
+
+	tracker.Uncordon("d", networkID, "*")
+	assert.Equal(t, 2, tracker.EligibleUpstreams(networkID))
+
+	// Window resets zero error rates and lags
+	tracker.Cordon("d", networkID, "*", "test")
+	clock.Advance(time.Minute)
+	assert.Eventually(t, func() bool { return tracker.EligibleUpstreams(networkID) == 4 }, time.Second, time.Millisecond)
+	assert.Equal(t, 4.0, gauge(t))
+}
+
+func TestEligibleUpstreams_FromCordons(t *testing.T) {
+	networkID := "evm:124"
+	tracker, _ := newFakeClockTracker(t, time.Minute)
+	for _, ups := range []string{"a", "b", "c"} {
+		tracker.RecordUpstreamRequest(ups, networkID, "eth_call")
+	}
+	tracker.RecordUpstreamFailure("b", networkID, "eth_call")
+	tracker.SetLatestBlockNumber("a", networkID, 100)
+	tracker.SetLatestBlockNumber("c", networkID, 10)
+	assert.Equal(t, 1, tracker.EligibleUpstreams(networkID))
+
+	// Erroring and lagging upstreams stay eligible until the policy cordons them
+	tracker.SetEligibilityFromCordons(networkID)
+	assert.Equal(t, 3, tracker.EligibleUpstreams(networkID))
+	tracker.Cordon("b", networkID, "*", "policy")
+	assert.Equal(t, 2, tracker.EligibleUpstreams(networkID))
+}
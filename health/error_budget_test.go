@@ -0,0 +1,129 @@
+package health
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health/internal/metricstest"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorBudgetConfig(t *testing.T) {
+	networkID := "evm:123"
+	record := func(tracker *Tracker, requests, errors int) {
+		for i := 0; i < requests; i++ {
+			tracker.RecordUpstreamRequest("a", networkID, "eth_call")
+		}
+		for i := 0; i < errors; i++ {
+			tracker.RecordUpstreamFailure("a", networkID, "eth_call")
+		}
+	}
+
+	t.Run("ScalesWithTrafficAboveFloor", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-error-budget-scale", time.Minute)
+		require.NoError(t, tracker.SetErrorBudgetConfig("a", networkID, &ErrorBudgetConfig{Fraction: 0.005, MinErrors: 10}))
+
+		s, ok := tracker.GetErrorBudget("a", networkID)
+		require.True(t, ok)
+		assert.Equal(t, ErrorBudgetStatus{Allowed: 10, Remaining: 10}, s)
+
+		// 0.5% of 1000 requests is under the floor
+		record(tracker, 1000, 4)
+		s, _ = tracker.GetErrorBudget("a", networkID)
+		assert.Equal(t, ErrorBudgetStatus{Allowed: 10, Consumed: 4, Remaining: 6}, s)
+		assert.InDelta(t, 6, metricstest.Value(t, "erpc_upstream_error_budget_remaining", map[string]string{"project": "test-error-budget-scale", "upstream": "a"}), 1e-9)
+
+		record(tracker, 9000, 0)
+		s, _ = tracker.GetErrorBudget("a", networkID)
+		assert.Equal(t, ErrorBudgetStatus{Allowed: 50, Consumed: 4, Remaining: 46}, s)
+
+		snapshot := tracker.GetNetworkUpstreamsMetrics(networkID, "*")
+		require.NotNil(t, snapshot["a"].ErrorBudget)
+		assert.Equal(t, int64(4), snapshot["a"].ErrorBudget.Consumed)
+		assert.Equal(t, 46.0, snapshot["a"].ErrorBudget.Remaining)
+
+		_, ok = tracker.GetErrorBudget("b", networkID)
+		assert.False(t, ok)
+	})
+
+	t.Run("CordonsForTheRestOfTheWindow", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-error-budget-cordon", time.Minute)
+		events, unsubscribe := tracker.Subscribe(4)
+		defer unsubscribe()
+		require.NoError(t, tracker.SetErrorBudgetConfig("a", networkID, &ErrorBudgetConfig{Fraction: 0.005, MinErrors: 10, Cordon: true}))
+
+		record(tracker, 1000, 9)
+		assert.False(t, tracker.IsCordoned("a", networkID, "*"))
+		assert.Empty(t, events)
+
+		record(tracker, 0, 1)
+		s, _ := tracker.GetErrorBudget("a", networkID)
+		assert.Equal(t, ErrorBudgetStatus{Allowed: 10, Consumed: 10, Exhausted: true}, s)
+		assert.True(t, tracker.IsCordoned("a", networkID, "*"))
+		assert.Equal(t, CordonReasonErrorBudgetExhausted, tracker.GetUpstreamMethodMetrics("a", networkID, "*").CordonInfo().Reason)
+		require.Len(t, events, 1)
+		e := <-events
+		assert.Equal(t, EventErrorBudgetExhausted, e.Type)
+		assert.Equal(t, "a", e.Upstream)
+		assert.Equal(t, 10.0, e.Value)
+		assert.Equal(t, 10.0, e.Threshold)
+		// Without the penalty action the score is left alone
+		assert.Equal(t, 1.0, tracker.errorBudgetPenalty("a", networkID))
+
+		// More traffic raising the budget does not lift the cordon, nor emit again
+		record(tracker, 3000, 1)
+		s, _ = tracker.GetErrorBudget("a", networkID)
+		assert.False(t, s.Exhausted)
+		assert.True(t, tracker.IsCordoned("a", networkID, "*"))
+		assert.Empty(t, events)
+
+		tracker.rollWindow(time.Now())
+		assert.False(t, tracker.IsCordoned("a", networkID, "*"))
+		s, _ = tracker.GetErrorBudget("a", networkID)
+		assert.Equal(t, ErrorBudgetStatus{Allowed: 10, Remaining: 10}, s)
+		assert.InDelta(t, 10, metricstest.Value(t, "erpc_upstream_error_budget_remaining", map[string]string{"project": "test-error-budget-cordon", "upstream": "a"}), 1e-9)
+
+		// The restored budget is exhausted again
+		record(tracker, 100, 10)
+		assert.True(t, tracker.IsCordoned("a", networkID, "*"))
+		assert.Len(t, events, 1)
+	})
+
+	t.Run("PenalizesWhenConfigured", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-error-budget-penalty", time.Minute)
+		require.NoError(t, tracker.SetErrorBudgetConfig("a", networkID, &ErrorBudgetConfig{Fraction: 0.01, MinErrors: 2, Penalize: true}))
+		record(tracker, 100, 1)
+		assert.Equal(t, 1.0, tracker.errorBudgetPenalty("a", networkID))
+		record(tracker, 0, 1)
+		assert.Equal(t, burnedBudgetPenalty, tracker.errorBudgetPenalty("a", networkID))
+		assert.False(t, tracker.IsCordoned("a", networkID, "*"))
+	})
+
+	t.Run("ExportsThroughTheTelemetryQueue", func(t *testing.T) {
+		project := "test-error-budget-async"
+		labels := map[string]string{"project": project, "upstream": "a"}
+		tracker := NewTracker(&log.Logger, project, time.Minute)
+		require.NoError(t, tracker.SetErrorBudgetConfig("a", networkID, &ErrorBudgetConfig{Fraction: 0.01, MinErrors: 10}))
+		q := &telemetryQueue{events: make(chan telemetryEvent, 10), stop: make(chan struct{}), drained: make(chan struct{})}
+		require.True(t, tracker.telemetryQueue.CompareAndSwap(nil, q))
+
+		record(tracker, 100, 3)
+		assert.Len(t, q.events, 3, "queued instead of set by the recorders")
+
+		go tracker.drainTelemetryQueue(context.Background(), q)
+		tracker.StopAsyncTelemetry()
+		assert.InDelta(t, 7, metricstest.Value(t, "erpc_upstream_error_budget_remaining", labels), 1e-9)
+	})
+
+	t.Run("RejectsInvalidConfig", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-error-budget-invalid", time.Minute)
+		assert.Error(t, tracker.SetErrorBudgetConfig("a", networkID, &ErrorBudgetConfig{Fraction: 0}))
+		assert.Error(t, tracker.SetErrorBudgetConfig("a", networkID, &ErrorBudgetConfig{Fraction: 1.5}))
+		assert.Error(t, tracker.SetErrorBudgetConfig("a", networkID, &ErrorBudgetConfig{Fraction: 0.1, MinErrors: -1}))
+		_, ok := tracker.GetErrorBudget("a", networkID)
+		assert.False(t, ok)
+	})
+}
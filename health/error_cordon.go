@@ -0,0 +1,228 @@
+package health
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	EventErrorRateEvaluated  EventType = "errorRateEvaluated"
+	EventErrorRateCordoned   EventType = "errorRateCordoned"
+	EventErrorRateUncordoned EventType = "errorRateUncordoned"
+)
+
+// CordonReasonErrorRate is the reason of cordons applied by SetErrorRateCordon thresholds.
+const CordonReasonErrorRate = "ErrorRate"
+
+// ErrorRateCordonConfig cordons a method on an upstream whose error rate for that method exceeds
+// MaxErrorRate over a window, leaving the other methods of the upstream routable.
+type ErrorRateCordonConfig struct {
+	// Error rate above which a window triggers the cordon, within (0, 1]
+	MaxErrorRate float64
+	// Requests needed within a window for it to be evaluated
+	MinSamples int64
+	// Consecutive windows at or under MaxErrorRate (or without traffic) lifting the cordon,
+	// defaults to 1
+	CleanWindows int
+	// Consecutive successful requests lifting the cordon before CleanWindows, e.g. from probes.
+	// Zero disables the probation.
+	ProbationSuccesses int
+}
+
+type networkMethodKey struct {
+	network, method string
+}
+
+type errorRateCordon struct {
+	mu           sync.Mutex
+	since        time.Time
+	rate         float64
+	cleanWindows int
+	successes    int
+}
+
+// SetErrorRateCordon configures the error rate cordon of a method on a network, evaluated for
+// every upstream at each window reset. Unlike other cordons these survive window resets until
+// lifted by clean windows or probation successes. A nil config removes the thresholds and lifts
+// the cordons they applied.
+func (t *Tracker) SetErrorRateCordon(network, method string, cfg *ErrorRateCordonConfig) error {
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
+	k := networkMethodKey{network, method}
+	if cfg == nil {
+		t.errorRateCordonConfigs.Delete(k)
+		t.errorRateCordons.Range(func(key, _ any) bool {
+			if tk := key.(tripletKey); tk.network == network && tk.method == method {
+				t.liftErrorRateCordon(tk, "error rate cordon removed")
+			}
+			return true
+		})
+		return nil
+	}
+	if cfg.MaxErrorRate <= 0 || cfg.MaxErrorRate > 1 {
+		return fmt.Errorf("error rate cordon threshold for method %s must be within (0, 1], got %v", method, cfg.MaxErrorRate)
+	}
+	if cfg.MinSamples < 0 || cfg.CleanWindows < 0 || cfg.ProbationSuccesses < 0 {
+		return fmt.Errorf("error rate cordon of method %s cannot have negative sample or window counts", method)
+	}
+	c := *cfg
+	if c.CleanWindows == 0 {
+		c.CleanWindows = 1
+	}
+	t.errorRateCordonConfigs.Store(k, &c)
+	return nil
+}
+
+func (t *Tracker) errorRateCordonConfig(network, method string) *ErrorRateCordonConfig {
+	if val, ok := t.errorRateCordonConfigs.Load(networkMethodKey{network, method}); ok {
+		return val.(*ErrorRateCordonConfig)
+	}
+	return nil
+}
+
+// rollErrorRateCordons evaluates the closing window of every exact key with thresholds, it must
+// run right before metrics are reset.
+func (t *Tracker) rollErrorRateCordons() {
+	t.metrics.Range(func(key, value any) bool {
+		k := key.(tripletKey)
+		if k.ups == "*" || isAggregateMethod(k.method) {
+			return true
+		}
+		cfg := t.errorRateCordonConfig(k.network, k.method)
+		if cfg == nil {
+			return true
+		}
+		tm := value.(*TrackedMetrics)
+		errors, requests := tm.errorsAndRequests()
+		rate := boundedRatio(errors, requests)
+
+		if val, ok := t.errorRateCordons.Load(k); ok {
+			c := val.(*errorRateCordon)
+			c.mu.Lock()
+			if rate <= cfg.MaxErrorRate {
+				c.cleanWindows++
+			} else {
+				c.cleanWindows = 0
+				c.rate = rate
+			}
+			clean := c.cleanWindows
+			c.mu.Unlock()
+			t.emitErrorRateEvaluated(k, cfg, rate, requests, true)
+			if clean >= cfg.CleanWindows {
+				t.liftErrorRateCordon(k, fmt.Sprintf("error rate under %.2f for %d windows", cfg.MaxErrorRate, clean))
+			}
+			return true
+		}
+
+		if requests < cfg.MinSamples {
+			return true
+		}
+		t.emitErrorRateEvaluated(k, cfg, rate, requests, false)
+		if rate <= cfg.MaxErrorRate {
+			return true
+		}
+		if _, loaded := t.errorRateCordons.LoadOrStore(k, &errorRateCordon{since: t.clock.Now(), rate: rate}); !loaded {
+			t.errorRateCordonsActive.Add(1)
+			t.emit(Event{
+				Type:      EventErrorRateCordoned,
+				Upstream:  k.ups,
+				Network:   k.network,
+				Method:    k.method,
+				Value:     rate,
+				Threshold: cfg.MaxErrorRate,
+				Message:   fmt.Sprintf("cordoning method after %d errors out of %d requests", errors, requests),
+			})
+		}
+		return true
+	})
+}
+
+func (t *Tracker) emitErrorRateEvaluated(k tripletKey, cfg *ErrorRateCordonConfig, rate float64, requests int64, cordoned bool) {
+	state := "uncordoned"
+	if cordoned {
+		state = "cordoned"
+	}
+	t.emit(Event{
+		Type:      EventErrorRateEvaluated,
+		Upstream:  k.ups,
+		Network:   k.network,
+		Method:    k.method,
+		Value:     rate,
+		Threshold: cfg.MaxErrorRate,
+		Message:   fmt.Sprintf("evaluated %s method over %d requests", state, requests),
+	})
+}
+
+// reapplyErrorRateCordons cordons again the keys still over their threshold, it must run right
+// after metrics are reset since resets lift every cordon.
+func (t *Tracker) reapplyErrorRateCordons() {
+	t.errorRateCordons.Range(func(key, value any) bool {
+		k := key.(tripletKey)
+		c := value.(*errorRateCordon)
+		c.mu.Lock()
+		info := CordonInfo{
+			Reason: CordonReasonErrorRate,
+			Detail: fmt.Sprintf("error rate %.2f", c.rate),
+			Source: CordonSourceTracker,
+			Since:  c.since,
+		}
+		c.mu.Unlock()
+		t.autoCordonWithInfo(k.ups, k.network, k.method, info)
+		return true
+	})
+}
+
+// observeErrorRateProbation counts consecutive successes of a cordoned key, lifting its error
+// rate cordon after enough of them. Network and method must already be canonical.
+func (t *Tracker) observeErrorRateProbation(ups, network, method string, success bool) {
+	if t.errorRateCordonsActive.Load() == 0 {
+		return
+	}
+	k := tripletKey{ups, network, method}
+	val, ok := t.errorRateCordons.Load(k)
+	if !ok {
+		return
+	}
+	cfg := t.errorRateCordonConfig(network, method)
+	if cfg == nil || cfg.ProbationSuccesses <= 0 {
+		return
+	}
+	c := val.(*errorRateCordon)
+	c.mu.Lock()
+	if success {
+		c.successes++
+	} else {
+		c.successes = 0
+	}
+	successes := c.successes
+	c.mu.Unlock()
+	if successes >= cfg.ProbationSuccesses {
+		t.liftErrorRateCordon(k, fmt.Sprintf("%d consecutive successes on probation", successes))
+	}
+}
+
+// dropErrorRateCordon forgets the error rate cordon of k, leaving the cordon itself alone.
+func (t *Tracker) dropErrorRateCordon(k tripletKey) bool {
+	if _, ok := t.errorRateCordons.LoadAndDelete(k); !ok {
+		return false
+	}
+	t.errorRateCordonsActive.Add(-1)
+	return true
+}
+
+func (t *Tracker) liftErrorRateCordon(k tripletKey, why string) {
+	if !t.dropErrorRateCordon(k) {
+		return
+	}
+	if info := t.GetCordonInfo(k.ups, k.network, k.method); info != nil && info.Reason == CordonReasonErrorRate {
+		t.UncordonWithSource(k.ups, k.network, k.method, CordonSourceTracker)
+	}
+	t.emit(Event{
+		Type:     EventErrorRateUncordoned,
+		Upstream: k.ups,
+		Network:  k.network,
+		Method:   k.method,
+		Message:  "uncordoning method after " + why,
+	})
+}
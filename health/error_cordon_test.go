@@ -0,0 +1,118 @@
+package health_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorRateCordon(t *testing.T) {
+	networkID := "evm:123"
+	window := time.Minute
+
+	waitEvent := func(t *testing.T, events <-chan health.Event, typ health.EventType) health.Event {
+		t.Helper()
+		timeout := time.After(time.Second)
+		for {
+			select {
+			case e := <-events:
+				if e.Type == typ {
+					return e
+				}
+			case <-timeout:
+				require.FailNow(t, "no event", "waiting for %s", typ)
+			}
+		}
+	}
+
+	setup := func(t *testing.T, cfg health.ErrorRateCordonConfig) (*health.Tracker, func(), <-chan health.Event) {
+		tracker, clock := newFakeClockTracker(t, window)
+		require.NoError(t, tracker.SetErrorRateCordon(networkID, "eth_sendRawTransaction", &cfg))
+		events, unsubscribe := tracker.Subscribe(64)
+		t.Cleanup(unsubscribe)
+		return tracker, func() { clock.Advance(window) }, events
+	}
+
+	t.Run("CordonsOnlyTheMethodUntilCleanWindows", func(t *testing.T) {
+		tracker, nextWindow, events := setup(t, health.ErrorRateCordonConfig{MaxErrorRate: 0.1, MinSamples: 50, CleanWindows: 2})
+		recordRequests(tracker, networkID, "a", "eth_sendRawTransaction", 60, 10)
+		recordRequests(tracker, networkID, "a", "eth_call", 60, 30)
+		nextWindow()
+
+		e := waitEvent(t, events, health.EventErrorRateCordoned)
+		assert.Equal(t, "a", e.Upstream)
+		assert.Equal(t, "eth_sendRawTransaction", e.Method)
+		assert.InDelta(t, 10.0/60, e.Value, 1e-9)
+		assert.Eventually(t, func() bool {
+			return tracker.IsCordoned("a", networkID, "eth_sendRawTransaction")
+		}, time.Second, time.Millisecond)
+		assert.False(t, tracker.IsCordoned("a", networkID, "eth_call"))
+		assert.False(t, tracker.IsCordoned("a", networkID, "*"))
+		info := tracker.GetCordonInfo("a", networkID, "eth_sendRawTransaction")
+		require.NotNil(t, info)
+		assert.Equal(t, health.CordonReasonErrorRate, info.Reason)
+
+		// The cordon survives the reset of a first clean window
+		nextWindow()
+		waitEvent(t, events, health.EventErrorRateEvaluated)
+		assert.Eventually(t, func() bool {
+			return tracker.IsCordoned("a", networkID, "eth_sendRawTransaction")
+		}, time.Second, time.Millisecond)
+
+		nextWindow()
+		waitEvent(t, events, health.EventErrorRateUncordoned)
+		assert.Eventually(t, func() bool {
+			return !tracker.IsCordoned("a", networkID, "eth_sendRawTransaction")
+		}, time.Second, time.Millisecond)
+	})
+
+	t.Run("NotEvaluatedUnderMinSamples", func(t *testing.T) {
+		tracker, nextWindow, events := setup(t, health.ErrorRateCordonConfig{MaxErrorRate: 0.1, MinSamples: 50})
+		recordRequests(tracker, networkID, "a", "eth_sendRawTransaction", 10, 10)
+		recordRequests(tracker, networkID, "b", "eth_sendRawTransaction", 50, 1)
+		nextWindow()
+
+		e := waitEvent(t, events, health.EventErrorRateEvaluated)
+		assert.Equal(t, "b", e.Upstream)
+		assert.Eventually(t, func() bool {
+			return tracker.GetUpstreamMethodMetrics("b", networkID, "eth_sendRawTransaction").RequestsTotal.Load() == 0
+		}, time.Second, time.Millisecond)
+		assert.False(t, tracker.IsCordoned("a", networkID, "eth_sendRawTransaction"))
+	})
+
+	t.Run("LiftedByProbationSuccesses", func(t *testing.T) {
+		tracker, nextWindow, events := setup(t, health.ErrorRateCordonConfig{MaxErrorRate: 0.1, MinSamples: 10, CleanWindows: 5, ProbationSuccesses: 3})
+		recordRequests(tracker, networkID, "a", "eth_sendRawTransaction", 10, 5)
+		nextWindow()
+		waitEvent(t, events, health.EventErrorRateCordoned)
+		assert.Eventually(t, func() bool {
+			return tracker.IsCordoned("a", networkID, "eth_sendRawTransaction")
+		}, time.Second, time.Millisecond)
+
+		probe := func(kind health.OutcomeKind) {
+			tracker.RecordOutcome("a", networkID, "eth_sendRawTransaction", health.Outcome{Kind: kind, Duration: time.Millisecond})
+		}
+		probe(health.OutcomeSuccess)
+		probe(health.OutcomeSuccess)
+		probe(health.OutcomeFailure)
+		probe(health.OutcomeSuccess)
+		probe(health.OutcomeSuccess)
+		assert.True(t, tracker.IsCordoned("a", networkID, "eth_sendRawTransaction"))
+
+		probe(health.OutcomeSuccess)
+		assert.False(t, tracker.IsCordoned("a", networkID, "eth_sendRawTransaction"))
+		e := waitEvent(t, events, health.EventErrorRateUncordoned)
+		assert.Contains(t, e.Message, "3 consecutive successes")
+	})
+
+	t.Run("RejectsInvalidThresholds", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, window)
+		assert.Error(t, tracker.SetErrorRateCordon(networkID, "eth_call", &health.ErrorRateCordonConfig{MaxErrorRate: 0}))
+		assert.Error(t, tracker.SetErrorRateCordon(networkID, "eth_call", &health.ErrorRateCordonConfig{MaxErrorRate: 1.5}))
+		assert.Error(t, tracker.SetErrorRateCordon(networkID, "eth_call", &health.ErrorRateCordonConfig{MaxErrorRate: 0.1, MinSamples: -1}))
+		assert.NoError(t, tracker.SetErrorRateCordon(networkID, "eth_call", nil))
+	})
+}
@@ -0,0 +1,71 @@
+package health
+
+import (
+	"sync"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventSLOBurnRateExceeded  EventType = "sloBurnRateExceeded"
+	EventSLOBurnRateRecovered EventType = "sloBurnRateRecovered"
+)
+
+// Event is a notable state change detected by the tracker, delivered to subscribers.
+type Event struct {
+	Type     EventType `json:"type"`
+	Time     time.Time `json:"time"`
+	Upstream string    `json:"upstream,omitempty"`
+	Network  string    `json:"network,omitempty"`
+	Method   string    `json:"method,omitempty"`
+	Message  string    `json:"message"`
+	// Value that triggered the event and the threshold it was compared to, if any
+	Value     float64 `json:"value"`
+	Threshold float64 `json:"threshold"`
+}
+
+type eventSubscribers struct {
+	mu   sync.RWMutex
+	subs map[chan Event]struct{}
+}
+
+// Subscribe returns a channel receiving the events emitted by the tracker, and a function to
+// unsubscribe. Events are dropped for subscribers whose buffer is full, so that slow consumers
+// never block the tracker.
+func (t *Tracker) Subscribe(buffer int) (<-chan Event, func()) {
+	ch := make(chan Event, buffer)
+
+	t.events.mu.Lock()
+	if t.events.subs == nil {
+		t.events.subs = make(map[chan Event]struct{})
+	}
+	t.events.subs[ch] = struct{}{}
+	t.events.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			t.events.mu.Lock()
+			delete(t.events.subs, ch)
+			t.events.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+func (t *Tracker) emit(e Event) {
+	if e.Time.IsZero() {
+		e.Time = t.clock.Now()
+	}
+
+	t.events.mu.RLock()
+	defer t.events.mu.RUnlock()
+	for ch := range t.events.subs {
+		select {
+		case ch <- e:
+		default:
+			t.logger.Debug().Str("event", string(e.Type)).Msg("dropping tracker event for slow subscriber")
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package health
+
+import (
+	"io"
+
+	"github.com/erpc/erpc/common"
+)
+
+type ndjsonRecord struct {
+	Upstream string          `json:"upstream"`
+	Network  string          `json:"network"`
+	Method   string          `json:"method"`
+	Metrics  *TrackedMetrics `json:"metrics"`
+}
+
+// WriteNDJSON streams every tracked key as newline-delimited JSON, one object per key.
+// Each line is written (and flushed when w supports it) as soon as it is encoded so that
+// memory usage does not grow with the number of keys.
+func (t *Tracker) WriteNDJSON(w io.Writer) error {
+	var err error
+	t.metrics.Range(func(key, value any) bool {
+		k, ok := key.(tripletKey)
+		if !ok {
+			return true
+		}
+		var line []byte
+		line, err = common.SonicCfg.Marshal(ndjsonRecord{
+			Upstream: k.ups,
+			Network:  k.network,
+			Method:   k.method,
+			Metrics:  value.(*TrackedMetrics),
+		})
+		if err != nil {
+			return false
+		}
+		line = append(line, '\n')
+		if _, err = w.Write(line); err != nil {
+			return false
+		}
+		err = flushWriter(w)
+		return err == nil
+	})
+	return err
+}
+
+// flushWriter flushes buffered writers such as *bufio.Writer or http.ResponseWriter.
+func flushWriter(w io.Writer) error {
+	switch f := w.(type) {
+	case interface{ Flush() error }:
+		return f.Flush()
+	case interface{ Flush() }:
+		f.Flush()
+	}
+	return nil
+}
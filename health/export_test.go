@@ -0,0 +1,67 @@
+package health
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingFlusher struct {
+	bytes.Buffer
+	flushes int
+}
+
+func (c *countingFlusher) Flush() {
+	c.flushes++
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("boom")
+}
+
+func TestWriteNDJSON(t *testing.T) {
+	t.Run("OneLinePerKey", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		simulateRequestMetrics(tracker, "evm:1", "a", "eth_call", 10, 2)
+		simulateRequestMetrics(tracker, "evm:1", "b", "eth_getLogs", 5, 0)
+
+		out := &countingFlusher{}
+		require.NoError(t, tracker.WriteNDJSON(out))
+
+		seen := map[string]map[string]interface{}{}
+		scanner := bufio.NewScanner(bytes.NewReader(out.Bytes()))
+		lines := 0
+		for scanner.Scan() {
+			lines++
+			var rec map[string]interface{}
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &rec))
+			key := rec["upstream"].(string) + "|" + rec["network"].(string) + "|" + rec["method"].(string)
+			seen[key] = rec["metrics"].(map[string]interface{})
+		}
+
+		// 5 expanded keys per (ups, method), sharing the {"*", network, "*"} key, plus the read
+		// class aggregates of each upstream and of the network
+		assert.Equal(t, 12, lines)
+		assert.Equal(t, lines, out.flushes)
+		assert.Equal(t, float64(10), seen["a|evm:1|eth_call"]["requestsTotal"])
+		assert.Equal(t, float64(2), seen["a|evm:1|eth_call"]["errorsTotal"])
+		assert.Equal(t, float64(15), seen["*|evm:1|*"]["requestsTotal"])
+		assert.Equal(t, float64(15), seen["*|evm:1|<reads>"]["requestsTotal"])
+	})
+
+	t.Run("ReturnsWriterError", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		simulateRequestMetrics(tracker, "evm:1", "a", "eth_call", 1, 0)
+
+		assert.Error(t, tracker.WriteNDJSON(failingWriter{}))
+	})
+}
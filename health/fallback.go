@@ -0,0 +1,116 @@
+package health
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/erpc/erpc/telemetry"
+)
+
+// AttemptResult describes a single upstream attempt made while serving one network-level request.
+type AttemptResult struct {
+	Upstream string
+	Network  string
+	Method   string
+	Duration time.Duration
+	Success  bool
+
+	// Attempt is the execution attempt index, hedged attempts complete concurrently so the chain
+	// is ordered by it rather than by completion.
+	Attempt int
+	// Hedge is set when the attempt was launched by the hedge policy.
+	Hedge bool
+	// Cancelled is set when the attempt was cancelled while in flight, such attempts are left out
+	// of the fallback chain.
+	Cancelled bool
+	// CancelCause of a cancelled attempt, empty when it was already attributed by the upstream.
+	CancelCause CancelCause
+}
+
+// maxFallbackPositionLabel bounds the cardinality of the "position" label on fallback metrics.
+const maxFallbackPositionLabel = 4
+
+// RecordRequestOutcome records how a request traversed its attempt chain. Per-attempt counters
+// (requests, failures, durations) are still recorded by the upstream itself; this records the
+// hedges launched, won and cancelled, the cancellations of the other attempts, and attributes the
+// request-level fallback behavior to the network-level keys ({"*", network, method} and
+// {"*", network, "*"}).
+func (t *Tracker) RecordRequestOutcome(chain []AttemptResult) {
+	if len(chain) == 0 {
+		return
+	}
+	completed := make([]AttemptResult, 0, len(chain))
+	for _, a := range chain {
+		if a.Hedge {
+			t.recordHedgeLaunched(a.Upstream, a.Network, a.Method)
+		}
+		switch {
+		case !a.Cancelled:
+			completed = append(completed, a)
+		case a.CancelCause == "":
+			// Already attributed by the upstream
+		case a.Hedge && a.CancelCause == CancelCauseHedge:
+			t.RecordUpstreamHedgeCancelled(a.Upstream, a.Network, a.Method, a.Duration)
+		default:
+			t.RecordUpstreamCancelled(a.Upstream, a.Network, a.Method, a.CancelCause, a.Duration)
+		}
+	}
+	if len(completed) == 0 {
+		return
+	}
+	chain = completed
+	sort.SliceStable(chain, func(i, j int) bool {
+		return chain[i].Attempt < chain[j].Attempt
+	})
+
+	servedPos := 0
+	var added time.Duration
+	for i, a := range chain {
+		if a.Success {
+			servedPos = i + 1
+			if a.Hedge {
+				t.RecordUpstreamHedgeWon(a.Upstream, a.Network, a.Method)
+			}
+			break
+		}
+		added += a.Duration
+	}
+
+	if len(chain) < 2 {
+		// Served (or failed) by the first upstream, no fallback happened
+		return
+	}
+
+	network, method := t.canonicalNetwork(chain[0].Network), t.normalizeMethod(chain[0].Method)
+
+	keys := []tripletKey{
+		{"*", network, method},
+		{"*", network, "*"},
+	}
+	for _, k := range keys {
+		m := t.getMetrics(k)
+		m.FallbacksTotal.Add(1)
+		if servedPos > 0 {
+			m.FallbackServedTotal.Add(1)
+			m.FallbackServedPositionSum.Add(int64(servedPos))
+			m.FallbackAddedDurationTotal.Add(int64(added))
+		}
+	}
+
+	position := "failed"
+	if servedPos > 0 {
+		position = fallbackPositionLabel(servedPos)
+	}
+	telemetry.MetricNetworkFallbackTotal.WithLabelValues(t.projectId, network, method, position).Inc()
+	if servedPos > 0 && telemetry.MetricNetworkFallbackAddedDuration != nil {
+		telemetry.MetricNetworkFallbackAddedDuration.WithLabelValues(t.projectId, network, method).Observe(added.Seconds())
+	}
+}
+
+func fallbackPositionLabel(pos int) string {
+	if pos >= maxFallbackPositionLabel {
+		return strconv.Itoa(maxFallbackPositionLabel) + "+"
+	}
+	return strconv.Itoa(pos)
+}
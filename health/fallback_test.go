@@ -0,0 +1,109 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/telemetry"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordRequestOutcome(t *testing.T) {
+	telemetry.SetHistogramBuckets("0.05,0.5,5,30")
+
+	t.Run("NoFallbackWhenFirstAttemptServes", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		tracker.RecordRequestOutcome([]AttemptResult{
+			{Upstream: "a", Network: "evm:1", Method: "eth_call", Duration: 10 * time.Millisecond, Success: true},
+		})
+
+		m := tracker.GetNetworkMethodMetrics("evm:1", "eth_call")
+		assert.Equal(t, int64(0), m.FallbacksTotal.Load())
+		assert.Equal(t, float64(0), m.FallbackAvgServedPosition())
+	})
+
+	t.Run("FallbackPositionAndAddedLatency", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		tracker.RecordRequestOutcome([]AttemptResult{
+			{Upstream: "a", Network: "evm:1", Method: "eth_call", Duration: 100 * time.Millisecond},
+			{Upstream: "b", Network: "evm:1", Method: "eth_call", Duration: 50 * time.Millisecond},
+			{Upstream: "c", Network: "evm:1", Method: "eth_call", Duration: 20 * time.Millisecond, Success: true},
+		})
+		tracker.RecordRequestOutcome([]AttemptResult{
+			{Upstream: "a", Network: "evm:1", Method: "eth_call", Duration: 50 * time.Millisecond},
+			{Upstream: "b", Network: "evm:1", Method: "eth_call", Duration: 20 * time.Millisecond, Success: true},
+		})
+
+		m := tracker.GetNetworkMethodMetrics("evm:1", "eth_call")
+		assert.Equal(t, int64(2), m.FallbacksTotal.Load())
+		assert.Equal(t, 2.5, m.FallbackAvgServedPosition())
+		assert.Equal(t, 100*time.Millisecond, m.FallbackAvgAddedLatency())
+
+		all := tracker.GetNetworkMethodMetrics("evm:1", "*")
+		assert.Equal(t, int64(2), all.FallbacksTotal.Load())
+	})
+
+	t.Run("ExhaustedChainCountsFallbackWithoutServedPosition", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		tracker.RecordRequestOutcome([]AttemptResult{
+			{Upstream: "a", Network: "evm:1", Method: "eth_call", Duration: 10 * time.Millisecond},
+			{Upstream: "b", Network: "evm:1", Method: "eth_call", Duration: 10 * time.Millisecond},
+		})
+
+		m := tracker.GetNetworkMethodMetrics("evm:1", "eth_call")
+		assert.Equal(t, int64(1), m.FallbacksTotal.Load())
+		assert.Equal(t, int64(0), m.FallbackServedTotal.Load())
+		assert.Equal(t, time.Duration(0), m.FallbackAvgAddedLatency())
+	})
+	t.Run("ChainIsOrderedByAttemptAndCountsHedgeWin", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		// The hedge completes before the primary attempt fails
+		tracker.RecordRequestOutcome([]AttemptResult{
+			{Upstream: "b", Network: "evm:1", Method: "eth_call", Duration: 20 * time.Millisecond, Success: true, Attempt: 2, Hedge: true},
+			{Upstream: "a", Network: "evm:1", Method: "eth_call", Duration: 80 * time.Millisecond, Attempt: 1},
+		})
+
+		m := tracker.GetNetworkMethodMetrics("evm:1", "eth_call")
+		assert.Equal(t, int64(1), m.FallbacksTotal.Load())
+		assert.Equal(t, 2.0, m.FallbackAvgServedPosition())
+		assert.Equal(t, 80*time.Millisecond, m.FallbackAvgAddedLatency())
+		assert.Equal(t, int64(1), tracker.GetUpstreamMethodMetrics("b", "evm:1", "eth_call").HedgesWonTotal.Load())
+		assert.Equal(t, int64(0), tracker.GetUpstreamMethodMetrics("a", "evm:1", "eth_call").HedgesWonTotal.Load())
+	})
+
+	t.Run("HedgeServingAloneIsNotAFallback", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		tracker.RecordRequestOutcome([]AttemptResult{
+			{Upstream: "b", Network: "evm:1", Method: "eth_call", Duration: 20 * time.Millisecond, Success: true, Attempt: 2, Hedge: true},
+		})
+
+		assert.Equal(t, int64(0), tracker.GetNetworkMethodMetrics("evm:1", "eth_call").FallbacksTotal.Load())
+		assert.Equal(t, int64(1), tracker.GetUpstreamMethodMetrics("b", "evm:1", "eth_call").HedgesWonTotal.Load())
+	})
+
+	t.Run("CancelledAttemptsAreRecordedOutOfTheChain", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		tracker.RecordRequestOutcome([]AttemptResult{
+			{Upstream: "a", Network: "evm:1", Method: "eth_call", Duration: 80 * time.Millisecond, Attempt: 1, Cancelled: true, CancelCause: CancelCauseHedge},
+			{Upstream: "b", Network: "evm:1", Method: "eth_call", Duration: 20 * time.Millisecond, Success: true, Attempt: 2, Hedge: true},
+			{Upstream: "c", Network: "evm:1", Method: "eth_call", Duration: 30 * time.Millisecond, Attempt: 3, Hedge: true, Cancelled: true, CancelCause: CancelCauseHedge},
+			// Already attributed by the upstream
+			{Upstream: "d", Network: "evm:1", Method: "eth_call", Duration: 40 * time.Millisecond, Attempt: 4, Cancelled: true},
+		})
+
+		assert.Equal(t, int64(0), tracker.GetNetworkMethodMetrics("evm:1", "eth_call").FallbacksTotal.Load())
+		a := tracker.GetUpstreamMethodMetrics("a", "evm:1", "eth_call")
+		assert.Equal(t, int64(1), a.CancelledByHedgeTotal.Load())
+		assert.Equal(t, int64(0), a.HedgesLaunchedTotal.Load())
+		b := tracker.GetUpstreamMethodMetrics("b", "evm:1", "eth_call")
+		assert.Equal(t, int64(1), b.HedgesLaunchedTotal.Load())
+		assert.Equal(t, int64(1), b.HedgesWonTotal.Load())
+		c := tracker.GetUpstreamMethodMetrics("c", "evm:1", "eth_call")
+		assert.Equal(t, int64(1), c.HedgesLaunchedTotal.Load())
+		assert.Equal(t, int64(1), c.HedgesCancelledTotal.Load())
+		assert.Equal(t, int64(0), c.CancelledByHedgeTotal.Load())
+		d := tracker.GetUpstreamMethodMetrics("d", "evm:1", "eth_call")
+		assert.Equal(t, int64(0), d.CancelledByDeadlineTotal.Load()+d.CancelledByClientTotal.Load()+d.CancelledByHedgeTotal.Load())
+	})
+}
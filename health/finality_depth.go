@@ -0,0 +1,52 @@
+package health
+
+// SetFinalityDepth approximates finality on networks whose upstreams do not report finalized
+// blocks: the finalized block of an upstream is derived as its latest block minus blocks, until it
+// reports a finalized block itself. Zero or a negative depth disables the derivation.
+func (t *Tracker) SetFinalityDepth(network string, blocks int64) {
+	network = t.canonicalNetwork(network)
+	if blocks <= 0 {
+		t.finalityDepths.Delete(network)
+		return
+	}
+	t.finalityDepths.Store(network, blocks)
+
+	// Derive right away for the upstreams which already reported a latest block
+	t.metadata.Range(func(key, value any) bool {
+		k := key.(duoKey)
+		if k.network == network && k.ups != "*" {
+			t.deriveFinalizedBlockNumber(k.ups, k.network)
+		}
+		return true
+	})
+}
+
+// deriveFinalizedBlockNumber sets the finalized block of an upstream from its latest block when
+// its network has a finality depth and the upstream never reported a finalized block.
+func (t *Tracker) deriveFinalizedBlockNumber(ups, network string) {
+	depth, ok := t.finalityDepths.Load(network)
+	if !ok {
+		return
+	}
+	meta := t.getMetadata(duoKey{ups, network})
+	if meta.explicitFinalized.Load() {
+		return
+	}
+	derived := meta.evmLatestBlockNumber.Load() - depth.(int64)
+	if derived <= 0 {
+		return
+	}
+	t.setFinalizedBlockNumber(ups, network, derived, false)
+}
+
+// GetFinalizedBlockNumber returns the effective finalized block of an upstream, whether reported
+// or derived from the finality depth, or the highest one of the network when ups is "*". Zero means
+// unknown.
+func (t *Tracker) GetFinalizedBlockNumber(ups, network string) int64 {
+	network = t.canonicalNetwork(network)
+	val, ok := t.metadata.Load(duoKey{ups, network})
+	if !ok {
+		return 0
+	}
+	return val.(*NetworkMetadata).evmFinalizedBlockNumber.Load()
+}
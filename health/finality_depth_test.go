@@ -0,0 +1,62 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFinalityDepth(t *testing.T) {
+	networkID := "evm:123"
+
+	t.Run("FinalizedIsDerivedFromLatest", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-finality-depth", time.Minute)
+		tracker.SetFinalityDepth(networkID, 64)
+		tracker.RecordUpstreamRequest("b", networkID, "eth_call")
+		tracker.SetLatestBlockNumber("a", networkID, 1000)
+		tracker.SetLatestBlockNumber("b", networkID, 990)
+
+		assert.Equal(t, int64(936), tracker.GetFinalizedBlockNumber("a", networkID))
+		assert.Equal(t, int64(926), tracker.GetFinalizedBlockNumber("b", networkID))
+		assert.Equal(t, int64(936), tracker.GetFinalizedBlockNumber("*", networkID))
+		m := tracker.GetUpstreamMethodMetrics("b", networkID, "eth_call")
+		assert.Equal(t, int64(10), m.FinalizationLag.Load())
+
+		tracker.SetLatestBlockNumber("b", networkID, 1010)
+		assert.Equal(t, int64(946), tracker.GetFinalizedBlockNumber("b", networkID))
+		assert.Equal(t, int64(0), m.FinalizationLag.Load())
+	})
+
+	t.Run("ExplicitFinalizedWins", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-finality-depth", time.Minute)
+		tracker.SetFinalityDepth(networkID, 64)
+		tracker.SetLatestBlockNumber("a", networkID, 1000)
+		tracker.SetFinalizedBlockNumber("a", networkID, 980)
+		tracker.SetLatestBlockNumber("a", networkID, 1100)
+
+		assert.Equal(t, int64(980), tracker.GetFinalizedBlockNumber("a", networkID))
+	})
+
+	t.Run("AppliesToAlreadyKnownUpstreams", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-finality-depth", time.Minute)
+		tracker.SetLatestBlockNumber("a", networkID, 1000)
+		assert.Equal(t, int64(0), tracker.GetFinalizedBlockNumber("a", networkID))
+
+		tracker.SetFinalityDepth(networkID, 100)
+		assert.Equal(t, int64(900), tracker.GetFinalizedBlockNumber("a", networkID))
+	})
+
+	t.Run("DisabledAndShallowChains", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-finality-depth", time.Minute)
+		tracker.SetFinalityDepth(networkID, 64)
+		tracker.SetLatestBlockNumber("a", networkID, 10)
+		assert.Equal(t, int64(0), tracker.GetFinalizedBlockNumber("a", networkID))
+
+		tracker.SetFinalityDepth(networkID, 0)
+		tracker.SetLatestBlockNumber("a", networkID, 1000)
+		assert.Equal(t, int64(0), tracker.GetFinalizedBlockNumber("a", networkID))
+		assert.Equal(t, int64(0), tracker.GetFinalizedBlockNumber("unknown", networkID))
+	})
+}
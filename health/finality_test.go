@@ -0,0 +1,67 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/health/internal/metricstest"
+	"github.com/erpc/erpc/telemetry"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFinalitySegmentedDurations(t *testing.T) {
+	telemetry.SetHistogramBuckets("0.05,0.5,5,30")
+
+	t.Run("SeparateQuantilesPerFinality", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		for i := 0; i < 20; i++ {
+			tracker.RecordUpstreamFinalityDuration("a", "evm:1", "eth_getLogs", 500*time.Millisecond, "none", common.DataFinalityStateFinalized)
+			tracker.RecordUpstreamFinalityDuration("a", "evm:1", "eth_getLogs", 20*time.Millisecond, "none", common.DataFinalityStateRealtime)
+		}
+
+		m := tracker.GetUpstreamMethodMetrics("a", "evm:1", "eth_getLogs")
+		assert.InDelta(t, 0.5, m.GetFinalityQuantiles(common.DataFinalityStateFinalized).GetQuantile(0.90).Seconds(), 0.01)
+		assert.InDelta(t, 0.02, m.GetFinalityQuantiles(common.DataFinalityStateRealtime).GetQuantile(0.90).Seconds(), 0.001)
+		assert.Nil(t, m.GetFinalityQuantiles(common.DataFinalityStateUnfinalized))
+
+		p90s := m.finalityP90s()
+		assert.Len(t, p90s, 2)
+		assert.Contains(t, p90s, "finalized")
+		assert.Contains(t, p90s, "realtime")
+	})
+
+	t.Run("DefaultsToUnknown", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		tracker.RecordUpstreamDuration("a", "evm:1", "eth_call", 10*time.Millisecond, "none")
+		timer := tracker.RecordUpstreamDurationStart("a", "evm:1", "eth_call", "")
+		timer.ObserveDuration()
+
+		m := tracker.GetUpstreamMethodMetrics("a", "evm:1", "eth_call")
+		assert.NotNil(t, m.GetFinalityQuantiles(common.DataFinalityStateUnknown))
+		assert.Nil(t, m.GetFinalityQuantiles(common.DataFinalityStateFinalized))
+	})
+
+	t.Run("TimerCarriesFinality", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		timer := tracker.RecordUpstreamDurationStart("a", "evm:1", "eth_call", "none")
+		timer.SetFinality(common.DataFinalityStateUnfinalized)
+		timer.ObserveDuration()
+
+		m := tracker.GetNetworkMethodMetrics("evm:1", "eth_call")
+		assert.NotNil(t, m.GetFinalityQuantiles(common.DataFinalityStateUnfinalized))
+	})
+
+	t.Run("FinalityOnAttemptHistogramOnly", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-finality-histogram", time.Minute)
+		tracker.RecordUpstreamFinalityDuration("a", "evm:1", "eth_getLogs", 10*time.Millisecond, "none", common.DataFinalityStateFinalized)
+
+		labels := map[string]string{"project": "test-finality-histogram", "upstream": "a"}
+		assert.Equal(t, 1.0, metricstest.Value(t, "erpc_upstream_request_duration_seconds", labels))
+		for _, s := range metricstest.Samples(t, "erpc_upstream_request_duration_seconds", labels) {
+			assert.Equal(t, map[string]string{"project": "test-finality-histogram", "network": "evm:1", "upstream": "a", "category": "eth_getLogs", "composite": "none"}, s.Labels)
+		}
+		assert.Equal(t, 1.0, metricstest.Value(t, "erpc_upstream_attempt_duration_seconds", map[string]string{"project": "test-finality-histogram", "upstream": "a", "finality": "finalized", "attempt": "1"}))
+	})
+}
@@ -0,0 +1,52 @@
+package health
+
+import (
+	"fmt"
+
+	"github.com/erpc/erpc/telemetry"
+)
+
+// EventCordonSuppressed is emitted for each automatic cordon not applied to keep a network at its
+// minimum of eligible upstreams, see SetMinEligibleUpstreams.
+const EventCordonSuppressed EventType = "cordonSuppressed"
+
+// SetMinEligibleUpstreams makes the tracker stop cordoning upstreams by itself once a network
+// would be left with fewer than n eligible upstreams (see EligibleUpstreams), preferring a
+// degraded service over no service at all. Suppressed cordons are counted in
+// MetricUpstreamCordonSuppressedTotal, emitted as EventCordonSuppressed, and attempted again on
+// the next evaluation. Manual cordons, chain id mismatch cordons and cordons of a single method,
+// which leave the upstream eligible, are not affected. Zero disables it.
+func (t *Tracker) SetMinEligibleUpstreams(n int) {
+	t.minEligibleUpstreams.Store(int64(max(n, 0)))
+}
+
+// belowEligibleFloor tells if cordoning ups would leave the network with fewer eligible upstreams
+// than SetMinEligibleUpstreams allows. Callers hold cordonFloorMu until they applied the cordon,
+// so that concurrent evaluations near the floor do not all see room for one more cordon.
+func (t *Tracker) belowEligibleFloor(ups, network string, floor int64) bool {
+	eligible := int64(t.refreshEligibleUpstreams(network))
+	if t.isEligible(ups, network, t.eligibilityThresholds()) {
+		eligible--
+	}
+	return eligible < floor
+}
+
+// suppressCordon records an automatic cordon not applied because of the floor.
+func (t *Tracker) suppressCordon(ups, network, method string, info CordonInfo, floor int64) {
+	t.logger.Warn().Str("upstream", ups).
+		Str("network", network).
+		Str("method", method).
+		Str("reason", info.Reason).
+		Int64("minEligibleUpstreams", floor).
+		Msg("cordon suppressed to keep the minimum of eligible upstreams")
+	telemetry.MetricUpstreamCordonSuppressedTotal.WithLabelValues(t.projectId, network, ups, t.upstreamVendor(ups, network), method).Inc()
+	t.emit(Event{
+		Type:      EventCordonSuppressed,
+		Upstream:  ups,
+		Network:   network,
+		Method:    method,
+		Message:   fmt.Sprintf("would have cordoned (%s) below the minimum of eligible upstreams", info.Reason),
+		Value:     float64(t.EligibleUpstreams(network)),
+		Threshold: float64(floor),
+	})
+}
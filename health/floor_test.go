@@ -0,0 +1,108 @@
+package health
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health/internal/metricstest"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMinEligibleUpstreams(t *testing.T) {
+	networkID := "evm:123"
+	newTracker := func() *Tracker {
+		tracker := NewTracker(&log.Logger, "test-min-eligible", time.Minute)
+		for _, ups := range []string{"a", "b", "c"} {
+			tracker.RecordUpstreamRequest(ups, networkID, "eth_call")
+		}
+		tracker.SetMinEligibleUpstreams(2)
+		return tracker
+	}
+
+	t.Run("SuppressesAutomaticCordonsBelowFloor", func(t *testing.T) {
+		tracker := newTracker()
+		events, unsubscribe := tracker.Subscribe(10)
+		defer unsubscribe()
+		suppressed := func() float64 {
+			return metricstest.Value(t, "erpc_upstream_cordon_suppressed_total", map[string]string{
+				"project": "test-min-eligible", "upstream": "b", "category": "*",
+			})
+		}
+		before := suppressed()
+
+		assert.True(t, tracker.autoCordon("a", networkID, "*", "too many reconnects"))
+		assert.False(t, tracker.autoCordon("b", networkID, "*", "too many reconnects"))
+		assert.False(t, tracker.IsCordoned("b", networkID, "*"))
+		assert.Equal(t, 2, tracker.EligibleUpstreams(networkID))
+		assert.Equal(t, before+1, suppressed())
+
+		select {
+		case e := <-events:
+			assert.Equal(t, EventCordonSuppressed, e.Type)
+			assert.Equal(t, "b", e.Upstream)
+			assert.Contains(t, e.Message, "too many reconnects")
+			assert.Equal(t, 2.0, e.Threshold)
+		case <-time.After(time.Second):
+			require.Fail(t, "expected a suppressed cordon event")
+		}
+
+		// Manual cordons bypass the floor
+		tracker.Cordon("b", networkID, "*", "maintenance")
+		assert.True(t, tracker.IsCordoned("b", networkID, "*"))
+		assert.Equal(t, 1, tracker.EligibleUpstreams(networkID))
+	})
+
+	t.Run("IneligibleUpstreamsDoNotCountTowardsFloor", func(t *testing.T) {
+		tracker := newTracker()
+		tracker.RecordUpstreamFailure("c", networkID, "eth_call")
+
+		// c is already out of the eligible upstreams, cordoning it leaves the count untouched
+		assert.True(t, tracker.autoCordon("c", networkID, "*", "too many reconnects"))
+		assert.False(t, tracker.autoCordon("a", networkID, "*", "too many reconnects"))
+	})
+
+	t.Run("MethodCordonsAreNotSuppressed", func(t *testing.T) {
+		tracker := newTracker()
+		assert.True(t, tracker.autoCordon("a", networkID, "*", "too many reconnects"))
+
+		// The network is at its floor, but cordoning a method leaves b eligible
+		assert.True(t, tracker.autoCordon("b", networkID, "eth_call", "too many errors"))
+		assert.True(t, tracker.IsCordoned("b", networkID, "eth_call"))
+		assert.Equal(t, 2, tracker.EligibleUpstreams(networkID))
+	})
+
+	t.Run("ConcurrentEvaluationsNearFloor", func(t *testing.T) {
+		for i := 0; i < 50; i++ {
+			tracker := newTracker()
+			// Holds every evaluation until all of them passed the guard, right before the floor check
+			var arrived sync.WaitGroup
+			arrived.Add(3)
+			tracker.SetCordonGuard(func(ups, network, method, reason string) bool {
+				arrived.Done()
+				arrived.Wait()
+				return true
+			})
+			var wg sync.WaitGroup
+			for _, ups := range []string{"a", "b", "c"} {
+				wg.Add(1)
+				go func(ups string) {
+					defer wg.Done()
+					tracker.autoCordon(ups, networkID, "*", "too many reconnects")
+				}(ups)
+			}
+			wg.Wait()
+
+			cordoned := 0
+			for _, ups := range []string{"a", "b", "c"} {
+				if tracker.IsCordoned(ups, networkID, "*") {
+					cordoned++
+				}
+			}
+			require.Equal(t, 1, cordoned)
+			require.Equal(t, 2, tracker.EligibleUpstreams(networkID))
+		}
+	})
+}
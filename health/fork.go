@@ -0,0 +1,171 @@
+package health
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/erpc/erpc/telemetry"
+)
+
+// DefaultForkHorizon is the number of blocks below the highest observed one for which block
+// hashes are kept unless SetForkHorizon says otherwise.
+const DefaultForkHorizon = 64
+
+// ForkSignal tells whether the upstreams of a network disagree on the hash of the same block
+// number, which flags an unstable chain even when every upstream is internally consistent.
+type ForkSignal struct {
+	// ContestedHeights is the number of block numbers within the horizon currently reported with
+	// more than one hash
+	ContestedHeights int `json:"contestedHeights"`
+	// ForkEventsTotal counts the block numbers which became contested since the tracker started
+	ForkEventsTotal int64 `json:"forkEventsTotal"`
+	// Heights lists the contested heights, only filled when asked for verbosely
+	Heights []ContestedHeight `json:"heights,omitempty"`
+}
+
+// ContestedHeight is a block number reported with conflicting hashes, see ForkSignal.
+type ContestedHeight struct {
+	Number int64 `json:"number"`
+	// Hashes lists the upstreams currently reporting each hash
+	Hashes map[string][]string `json:"hashes"`
+}
+
+// forkState keeps the last hash reported by each upstream for the recent heights of a network.
+type forkState struct {
+	mu        sync.Mutex
+	heights   map[int64]map[string]string // upstream -> hash keyed by block number
+	highest   int64
+	contested int
+	events    int64
+}
+
+// SetForkHorizon sets how many blocks below the highest observed one hashes are compared across
+// upstreams, older heights being forgotten. Zero or a negative horizon means DefaultForkHorizon.
+func (t *Tracker) SetForkHorizon(blocks int64) {
+	t.forkHorizon.Store(max(blocks, 0))
+}
+
+func (t *Tracker) forkHorizonBlocks() int64 {
+	if blocks := t.forkHorizon.Load(); blocks > 0 {
+		return blocks
+	}
+	return DefaultForkHorizon
+}
+
+// RecordBlockHash records the hash an upstream reported for a block number. A height becoming
+// reported with more than one hash across the upstreams of the network counts as a fork event,
+// and stops being contested once they agree again (e.g. after the losing upstreams reorg) or it
+// falls out of the horizon.
+func (t *Tracker) RecordBlockHash(ups, network string, number int64, hash string) {
+	if number <= 0 || hash == "" {
+		return
+	}
+	network = t.canonicalNetwork(network)
+	val, ok := t.forks.Load(network)
+	if !ok {
+		val, _ = t.forks.LoadOrStore(network, &forkState{heights: make(map[int64]map[string]string)})
+	}
+	fs := val.(*forkState)
+
+	fs.mu.Lock()
+	horizon := t.forkHorizonBlocks()
+	if number <= fs.highest-horizon {
+		fs.mu.Unlock()
+		return
+	}
+	reported, ok := fs.heights[number]
+	if !ok {
+		reported = make(map[string]string)
+		fs.heights[number] = reported
+	}
+	wasContested := distinctHashes(reported) > 1
+	reported[ups] = hash
+	isContested := distinctHashes(reported) > 1
+	forked := !wasContested && isContested
+	switch {
+	case forked:
+		fs.contested++
+		fs.events++
+	case wasContested && !isContested:
+		fs.contested--
+	}
+	if number > fs.highest {
+		fs.highest = number
+		for n, r := range fs.heights {
+			if n <= fs.highest-horizon {
+				if distinctHashes(r) > 1 {
+					fs.contested--
+				}
+				delete(fs.heights, n)
+			}
+		}
+	}
+	contested := fs.contested
+	fs.mu.Unlock()
+
+	if forked {
+		telemetry.MetricNetworkForkEventsTotal.WithLabelValues(t.projectId, network).Inc()
+	}
+	telemetry.MetricNetworkContestedHeights.WithLabelValues(t.projectId, network).Set(float64(contested))
+}
+
+// GetForkSignal returns the fork signal of a network, listing the contested heights from the
+// most recent one when verbose.
+func (t *Tracker) GetForkSignal(network string, verbose bool) ForkSignal {
+	network = t.canonicalNetwork(network)
+	val, ok := t.forks.Load(network)
+	if !ok {
+		return ForkSignal{}
+	}
+	fs := val.(*forkState)
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	signal := ForkSignal{ContestedHeights: fs.contested, ForkEventsTotal: fs.events}
+	if !verbose {
+		return signal
+	}
+	for n, reported := range fs.heights {
+		if distinctHashes(reported) < 2 {
+			continue
+		}
+		h := ContestedHeight{Number: n, Hashes: make(map[string][]string)}
+		for ups, hash := range reported {
+			h.Hashes[hash] = append(h.Hashes[hash], ups)
+		}
+		for _, upstreams := range h.Hashes {
+			sort.Strings(upstreams)
+		}
+		signal.Heights = append(signal.Heights, h)
+	}
+	sort.Slice(signal.Heights, func(i, j int) bool {
+		return signal.Heights[i].Number > signal.Heights[j].Number
+	})
+	return signal
+}
+
+// forkSignals returns the fork signal of every network with block hashes recorded.
+func (t *Tracker) forkSignals() map[string]ForkSignal {
+	signals := make(map[string]ForkSignal)
+	t.forks.Range(func(key, _ any) bool {
+		network := key.(string)
+		signals[network] = t.GetForkSignal(network, false)
+		return true
+	})
+	return signals
+}
+
+// distinctHashes counts the distinct hashes reported for a height, up to 2.
+func distinctHashes(reported map[string]string) int {
+	if len(reported) < 2 {
+		return len(reported)
+	}
+	var first string
+	for _, hash := range reported {
+		if first == "" {
+			first = hash
+		} else if hash != first {
+			return 2
+		}
+	}
+	return 1
+}
@@ -0,0 +1,67 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health/internal/metricstest"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForkSignal(t *testing.T) {
+	networkID := "evm:123"
+	labels := map[string]string{"project": "test-fork", "network": networkID}
+
+	tracker := NewTracker(&log.Logger, "test-fork", time.Minute)
+	tracker.SetForkHorizon(10)
+
+	t.Run("AgreeingHashesAreNotContested", func(t *testing.T) {
+		tracker.RecordBlockHash("a", networkID, 100, "0xaa")
+		tracker.RecordBlockHash("b", networkID, 100, "0xaa")
+		assert.Equal(t, ForkSignal{}, tracker.GetForkSignal(networkID, true))
+	})
+
+	t.Run("ConflictingHashesCountAsOneForkEvent", func(t *testing.T) {
+		tracker.RecordBlockHash("c", networkID, 100, "0xbb")
+		tracker.RecordBlockHash("d", networkID, 100, "0xcc")
+		signal := tracker.GetForkSignal(networkID, false)
+		assert.Equal(t, 1, signal.ContestedHeights)
+		assert.Equal(t, int64(1), signal.ForkEventsTotal)
+		assert.Empty(t, signal.Heights)
+		assert.Equal(t, 1.0, metricstest.Value(t, "erpc_network_contested_heights", labels))
+		assert.Equal(t, 1.0, metricstest.Value(t, "erpc_network_fork_events_total", labels))
+
+		verbose := tracker.GetForkSignal(networkID, true)
+		assert.Equal(t, []ContestedHeight{{
+			Number: 100,
+			Hashes: map[string][]string{"0xaa": {"a", "b"}, "0xbb": {"c"}, "0xcc": {"d"}},
+		}}, verbose.Heights)
+	})
+
+	t.Run("ConvergingUpstreamsClearTheHeight", func(t *testing.T) {
+		tracker.RecordBlockHash("c", networkID, 100, "0xaa")
+		tracker.RecordBlockHash("d", networkID, 100, "0xaa")
+		signal := tracker.GetForkSignal(networkID, true)
+		assert.Equal(t, 0, signal.ContestedHeights)
+		assert.Equal(t, int64(1), signal.ForkEventsTotal)
+		assert.Empty(t, signal.Heights)
+		assert.Equal(t, 0.0, metricstest.Value(t, "erpc_network_contested_heights", labels))
+	})
+
+	t.Run("HeightsOutOfTheHorizonAreForgotten", func(t *testing.T) {
+		tracker.RecordBlockHash("a", networkID, 105, "0x01")
+		tracker.RecordBlockHash("b", networkID, 105, "0x02")
+		assert.Equal(t, 1, tracker.GetForkSignal(networkID, false).ContestedHeights)
+
+		tracker.RecordBlockHash("a", networkID, 115, "0x03")
+		signal := tracker.GetForkSignal(networkID, false)
+		assert.Equal(t, 0, signal.ContestedHeights)
+		assert.Equal(t, int64(2), signal.ForkEventsTotal)
+
+		// Late reports below the horizon are ignored
+		tracker.RecordBlockHash("b", networkID, 104, "0x04")
+		tracker.RecordBlockHash("c", networkID, 104, "0x05")
+		assert.Equal(t, 0, tracker.GetForkSignal(networkID, false).ContestedHeights)
+	})
+}
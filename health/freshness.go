@@ -0,0 +1,73 @@
+package health
+
+import "sync/atomic"
+
+// servedStalenessBuckets bounds the staleness histogram, staleness of servedStalenessBuckets-1
+// blocks or more shares the last bucket.
+const servedStalenessBuckets = 128
+
+// stalenessHistogram counts served blocks by how many blocks they were behind the network head.
+type stalenessHistogram struct {
+	counts [servedStalenessBuckets]atomic.Int64
+}
+
+func (h *stalenessHistogram) reset() {
+	for i := range h.counts {
+		h.counts[i].Store(0)
+	}
+}
+
+func (m *TrackedMetrics) stalenessHistogram() *stalenessHistogram {
+	if h := m.servedStaleness.Load(); h != nil {
+		return h
+	}
+	m.servedStaleness.CompareAndSwap(nil, &stalenessHistogram{})
+	return m.servedStaleness.Load()
+}
+
+// RecordServedBlock records the block an upstream actually served for a "latest" query, so that
+// upstreams serving stale blocks can be told apart even when the heads they report do not lag.
+// Staleness is measured against the highest head of the network, blocks served before any head
+// is known are ignored.
+func (t *Tracker) RecordServedBlock(ups, network, method string, block int64) {
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
+	val, ok := t.metadata.Load(duoKey{"*", network})
+	if !ok {
+		return
+	}
+	head := val.(*NetworkMetadata).evmLatestBlockNumber.Load()
+	if head <= 0 || block <= 0 {
+		return
+	}
+	staleness := min(max(head-block, 0), servedStalenessBuckets-1)
+	for _, k := range t.getKeys(ups, network, method) {
+		t.getMetrics(k).stalenessHistogram().counts[staleness].Add(1)
+	}
+}
+
+// StaleResponseRate returns the fraction of the blocks served by (ups, network, method) within the
+// current window which were more than maxStaleBlocks behind the network head. maxStaleBlocks is
+// capped to servedStalenessBuckets-2. It is zero when no served block was recorded.
+func (t *Tracker) StaleResponseRate(ups, network, method string, maxStaleBlocks int64) float64 {
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
+	val, ok := t.metrics.Load(tripletKey{ups, network, method})
+	if !ok {
+		return 0
+	}
+	h := val.(*TrackedMetrics).servedStaleness.Load()
+	if h == nil {
+		return 0
+	}
+	maxStaleBlocks = min(max(maxStaleBlocks, 0), servedStalenessBuckets-2)
+	var total, stale int64
+	for i := range h.counts {
+		n := h.counts[i].Load()
+		total += n
+		if int64(i) > maxStaleBlocks {
+			stale += n
+		}
+	}
+	return boundedRatio(stale, total)
+}
@@ -0,0 +1,61 @@
+package health_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health/healthtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaleResponseRate(t *testing.T) {
+	networkID := "evm:123"
+
+	t.Run("RisesWhenServingOldBlocks", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		tracker.SetLatestBlockNumber("a", networkID, 1000)
+		tracker.SetLatestBlockNumber("b", networkID, 1000)
+
+		for i := 0; i < 10; i++ {
+			tracker.RecordServedBlock("a", networkID, "eth_blockNumber", 1000)
+			tracker.RecordServedBlock("b", networkID, "eth_blockNumber", 999)
+		}
+		assert.Equal(t, 0.0, tracker.StaleResponseRate("a", networkID, "eth_blockNumber", 2))
+		assert.Equal(t, 0.0, tracker.StaleResponseRate("b", networkID, "eth_blockNumber", 2))
+
+		// b keeps reporting a fresh head but serves old blocks
+		for i := 0; i < 10; i++ {
+			tracker.RecordServedBlock("b", networkID, "eth_blockNumber", 990)
+		}
+		assert.Equal(t, 0.5, tracker.StaleResponseRate("b", networkID, "eth_blockNumber", 2))
+		assert.Equal(t, 0.0, tracker.StaleResponseRate("b", networkID, "eth_blockNumber", 10))
+		assert.Equal(t, 1.0, tracker.StaleResponseRate("b", networkID, "eth_blockNumber", 0))
+		assert.Equal(t, 0.5, tracker.StaleResponseRate("b", networkID, "*", 2))
+		assert.Equal(t, int64(0), tracker.GetUpstreamMethodMetrics("b", networkID, "*").BlockHeadLag.Load())
+	})
+
+	t.Run("VeryStaleBlocksShareTheLastBucket", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		tracker.SetLatestBlockNumber("a", networkID, 100000)
+		tracker.RecordServedBlock("a", networkID, "eth_blockNumber", 1)
+		tracker.RecordServedBlock("a", networkID, "eth_blockNumber", 100001)
+
+		assert.Equal(t, 0.5, tracker.StaleResponseRate("a", networkID, "eth_blockNumber", 1000))
+	})
+
+	t.Run("IgnoredWithoutKnownHeadAndResetEachWindow", func(t *testing.T) {
+		tracker, clock := newFakeClockTracker(t, time.Minute)
+		tracker.RecordServedBlock("a", networkID, "eth_blockNumber", 10)
+		assert.Equal(t, 0.0, tracker.StaleResponseRate("a", networkID, "eth_blockNumber", 0))
+
+		tracker.SetLatestBlockNumber("a", networkID, 100)
+		tracker.RecordUpstreamRequest("a", networkID, "eth_blockNumber")
+		tracker.RecordServedBlock("a", networkID, "eth_blockNumber", 10)
+		assert.Equal(t, 1.0, tracker.StaleResponseRate("a", networkID, "eth_blockNumber", 0))
+
+		healthtest.AdvanceWindow(t, clock, time.Minute, tracker.GetUpstreamMethodMetrics("a", networkID, "eth_blockNumber"))
+		assert.Eventually(t, func() bool {
+			return tracker.StaleResponseRate("a", networkID, "eth_blockNumber", 0) == 0
+		}, time.Second, time.Millisecond)
+	})
+}
@@ -0,0 +1,94 @@
+package health
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/DataDog/sketches-go/ddsketch"
+	"github.com/rs/zerolog/log"
+)
+
+// AggregatedMetrics rolls up the metrics of several upstreams, see GetGroupMetrics.
+type AggregatedMetrics struct {
+	// Upstreams are the members with metrics for the key, sorted
+	Upstreams              []string `json:"upstreams"`
+	RequestsTotal          int64    `json:"requestsTotal"`
+	ErrorsTotal            int64    `json:"errorsTotal"`
+	SelfRateLimitedTotal   int64    `json:"selfRateLimitedTotal"`
+	RemoteRateLimitedTotal int64    `json:"remoteRateLimitedTotal"`
+	ErrorRate              float64  `json:"errorRate"`
+	ThrottledRate          float64  `json:"throttledRate"`
+	// MaxBlockHeadLag is the lag of the member furthest behind
+	MaxBlockHeadLag int64 `json:"maxBlockHeadLag"`
+	// CordonedUpstreams counts the members cordoned for the key
+	CordonedUpstreams int `json:"cordonedUpstreams"`
+	// ResponseQuantiles merges the response quantiles of the members
+	ResponseQuantiles *QuantileTracker `json:"responseQuantiles"`
+}
+
+// SetUpstreamGroup puts an upstream in a group, e.g. the provider serving it, so that incidents
+// of the provider can be seen rolled up with GetGroupMetrics. The group applies to every network
+// of the upstream, an empty group removes it from its group.
+func (t *Tracker) SetUpstreamGroup(ups, group string) {
+	if group == "" {
+		t.upstreamGroups.Delete(ups)
+		return
+	}
+	t.upstreamGroups.Store(ups, group)
+}
+
+// UpstreamGroup returns the group of an upstream, empty if it has none.
+func (t *Tracker) UpstreamGroup(ups string) string {
+	if val, ok := t.upstreamGroups.Load(ups); ok {
+		return val.(string)
+	}
+	return ""
+}
+
+// GetGroupMetrics sums the metrics of (ups, network, method) over the upstreams of a group, and
+// merges their response quantiles. Like GetNetworkUpstreamsMetrics it never creates keys.
+func (t *Tracker) GetGroupMetrics(group, network, method string) AggregatedMetrics {
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
+	agg := AggregatedMetrics{ResponseQuantiles: NewQuantileTracker()}
+	set, ok := t.networkUpstreams.Load(network)
+	if !ok {
+		return agg
+	}
+	set.(*sync.Map).Range(func(key, _ any) bool {
+		ups := key.(string)
+		if t.UpstreamGroup(ups) != group {
+			return true
+		}
+		val, ok := t.metrics.Load(tripletKey{ups, network, method})
+		if !ok {
+			return true
+		}
+		m := val.(*TrackedMetrics)
+		c := m.readCounters()
+		agg.Upstreams = append(agg.Upstreams, ups)
+		agg.RequestsTotal += c.requests
+		agg.ErrorsTotal += c.errors
+		agg.SelfRateLimitedTotal += c.selfRateLimited
+		agg.RemoteRateLimitedTotal += c.remoteRateLimited
+		agg.MaxBlockHeadLag = max(agg.MaxBlockHeadLag, c.blockHeadLag)
+		if t.withUpstreamCordon(SelectionView{Cordoned: m.Cordoned.Load()}, ups, network, method).Cordoned {
+			agg.CordonedUpstreams++
+		}
+		m.ResponseQuantiles.mergeInto(agg.ResponseQuantiles.sketch)
+		return true
+	})
+	sort.Strings(agg.Upstreams)
+	agg.ErrorRate = boundedRatio(agg.ErrorsTotal, agg.RequestsTotal)
+	agg.ThrottledRate = boundedRatio(agg.SelfRateLimitedTotal+agg.RemoteRateLimitedTotal, agg.RequestsTotal)
+	return agg
+}
+
+// mergeInto adds the values of the tracker (the retained buckets of a bucketed one) to dst.
+func (q *QuantileTracker) mergeInto(dst *ddsketch.DDSketch) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if err := dst.MergeWith(q.currentLocked()); err != nil {
+		log.Warn().Err(err).Msg("failed to merge quantile tracker")
+	}
+}
@@ -0,0 +1,53 @@
+package health_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupMetrics(t *testing.T) {
+	networkID := "evm:123"
+	tracker, _ := newFakeClockTracker(t, time.Minute)
+	tracker.SetUpstreamGroup("alchemy-1", "alchemy")
+	tracker.SetUpstreamGroup("alchemy-2", "alchemy")
+	tracker.SetUpstreamGroup("infura", "infura")
+
+	recordRequests(tracker, networkID, "alchemy-1", "eth_call", 10, 2)
+	recordRequests(tracker, networkID, "alchemy-2", "eth_call", 30, 6)
+	recordRequests(tracker, networkID, "infura", "eth_call", 50, 0)
+	recordRequests(tracker, networkID, "unknown", "eth_call", 50, 50)
+	for i := 0; i < 10; i++ {
+		tracker.RecordUpstreamDuration("alchemy-1", networkID, "eth_call", 10*time.Millisecond, "none")
+		tracker.RecordUpstreamDuration("alchemy-2", networkID, "eth_call", time.Second, "none")
+	}
+	tracker.RecordUpstreamRemoteRateLimited("alchemy-2", networkID, "eth_call")
+	tracker.SetLatestBlockNumber("alchemy-1", networkID, 100)
+	tracker.SetLatestBlockNumber("infura", networkID, 110)
+	tracker.SetLatestBlockNumber("alchemy-2", networkID, 105)
+	tracker.Cordon("alchemy-2", networkID, "*", "incident")
+
+	agg := tracker.GetGroupMetrics("alchemy", networkID, "eth_call")
+	assert.Equal(t, []string{"alchemy-1", "alchemy-2"}, agg.Upstreams)
+	assert.Equal(t, int64(40), agg.RequestsTotal)
+	assert.Equal(t, int64(8), agg.ErrorsTotal)
+	assert.InDelta(t, 0.2, agg.ErrorRate, 1e-9)
+	assert.InDelta(t, 1.0/40, agg.ThrottledRate, 1e-9)
+	assert.Equal(t, int64(10), agg.MaxBlockHeadLag)
+	assert.Equal(t, 1, agg.CordonedUpstreams)
+	assert.InDelta(t, 0.01, agg.ResponseQuantiles.GetQuantile(0.25).Seconds(), 0.001)
+	assert.InDelta(t, 1, agg.ResponseQuantiles.GetQuantile(0.90).Seconds(), 0.02)
+
+	// Members keep their own quantiles untouched
+	assert.InDelta(t, 0.01, tracker.GetUpstreamMethodMetrics("alchemy-1", networkID, "eth_call").ResponseQuantiles.GetQuantile(0.90).Seconds(), 0.001)
+
+	tracker.SetUpstreamGroup("alchemy-2", "")
+	agg = tracker.GetGroupMetrics("alchemy", networkID, "eth_call")
+	assert.Equal(t, []string{"alchemy-1"}, agg.Upstreams)
+	assert.Equal(t, int64(10), agg.RequestsTotal)
+
+	agg = tracker.GetGroupMetrics("alchemy", "evm:456", "eth_call")
+	assert.Empty(t, agg.Upstreams)
+	assert.False(t, agg.ResponseQuantiles.HasSamples())
+}
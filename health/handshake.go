@@ -0,0 +1,102 @@
+package health
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/erpc/erpc/telemetry"
+)
+
+// CordonReasonHandshakeFailing is the reason of cordons applied by SetHandshakeCordonThreshold.
+const CordonReasonHandshakeFailing = "HandshakeFailing"
+
+// SetHandshakeCordonThreshold enables cordoning an upstream on a network once threshold handshakes
+// in a row failed, with reason CordonReasonHandshakeFailing. The cordon lasts until the end of the
+// window, and applies again on the next failure as long as no handshake succeeded. Zero disables it.
+func (t *Tracker) SetHandshakeCordonThreshold(threshold int64) {
+	t.handshakeCordonThreshold.Store(threshold)
+}
+
+// RecordUpstreamHandshake records the outcome and duration of the handshake of a persistent
+// connection (e.g. websocket subscription confirmation or authentication) of an upstream on a
+// network. Handshakes are tracked apart from requests: they count neither in RequestsTotal nor in
+// ErrorsTotal, and only successful ones in the handshake quantiles, see GetHandshakeQuantiles.
+func (t *Tracker) RecordUpstreamHandshake(ups, network string, success bool, d time.Duration) {
+	network = t.canonicalNetwork(network)
+	for _, k := range upstreamWideKeys(ups, network) {
+		m := t.getMetrics(k)
+		m.HandshakesTotal.Add(1)
+		if success {
+			m.handshakeQuantilesOrNew().Add(d.Seconds())
+			m.handshakeFailureStreak.Store(0)
+		} else {
+			m.HandshakeFailuresTotal.Add(1)
+			m.handshakeFailureStreak.Add(1)
+		}
+	}
+	outcome := "success"
+	if !success {
+		outcome = "failure"
+	}
+	telemetry.MetricUpstreamHandshakeTotal.WithLabelValues(t.projectId, network, ups, t.upstreamVendor(ups, network), outcome).Inc()
+
+	if !success {
+		t.evaluateHandshakeCordon(ups, network)
+	}
+}
+
+// GetHandshakeQuantiles returns the durations of the successful handshakes of an upstream on a
+// network within the window, or nil when none was ever recorded.
+func (t *Tracker) GetHandshakeQuantiles(ups, network string) *QuantileTracker {
+	network = t.canonicalNetwork(network)
+	val, ok := t.metrics.Load(tripletKey{ups, network, "*"})
+	if !ok {
+		return nil
+	}
+	return val.(*TrackedMetrics).handshakeQuantiles.Load()
+}
+
+// HandshakeFailureStreak returns the number of handshakes that failed in a row since the last
+// successful one. Unlike HandshakeFailuresTotal it is not reset with the window.
+func (m *TrackedMetrics) HandshakeFailureStreak() int64 {
+	return m.handshakeFailureStreak.Load()
+}
+
+// evaluateHandshakeCordon cordons an upstream on a network once its failed handshakes in a row
+// reach the threshold set by SetHandshakeCordonThreshold.
+func (t *Tracker) evaluateHandshakeCordon(ups, network string) {
+	threshold := t.handshakeCordonThreshold.Load()
+	if threshold <= 0 {
+		return
+	}
+	val, ok := t.metrics.Load(tripletKey{ups, network, "*"})
+	if !ok {
+		return
+	}
+	streak := val.(*TrackedMetrics).handshakeFailureStreak.Load()
+	if streak < threshold {
+		return
+	}
+	t.autoCordonWithInfo(ups, network, "*", CordonInfo{
+		Reason:    CordonReasonHandshakeFailing,
+		Detail:    fmt.Sprintf("%d handshakes failed in a row (threshold %d)", streak, threshold),
+		Source:    CordonSourceTracker,
+		ExpiresAt: time.Unix(0, t.windowStart.Load()).Add(t.windowSize),
+	})
+}
+
+func (m *TrackedMetrics) handshakeQuantilesOrNew() *QuantileTracker {
+	if qt := m.handshakeQuantiles.Load(); qt != nil {
+		return qt
+	}
+	m.handshakeQuantiles.CompareAndSwap(nil, m.newQuantileTracker())
+	return m.handshakeQuantiles.Load()
+}
+
+func (m *TrackedMetrics) handshakeP90() interface{} {
+	qt := m.handshakeQuantiles.Load()
+	if qt == nil || !qt.HasSamples() {
+		return nil
+	}
+	return qt.GetQuantile(0.90).Seconds()
+}
@@ -0,0 +1,78 @@
+package health_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health"
+	"github.com/erpc/erpc/health/healthtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordUpstreamHandshake(t *testing.T) {
+	networkID := "evm:123"
+
+	t.Run("IndependentFromRequests", func(t *testing.T) {
+		tracker, clock := newFakeClockTracker(t, time.Minute)
+		recordRequests(tracker, networkID, "a", "eth_call", 10, 1)
+
+		for i := 0; i < 9; i++ {
+			tracker.RecordUpstreamHandshake("a", networkID, true, 100*time.Millisecond)
+		}
+		tracker.RecordUpstreamHandshake("a", networkID, false, 5*time.Second)
+
+		m := tracker.GetUpstreamMethodMetrics("a", networkID, "*")
+		assert.Equal(t, int64(10), m.HandshakesTotal.Load())
+		assert.Equal(t, int64(1), m.HandshakeFailuresTotal.Load())
+		assert.Equal(t, int64(1), m.HandshakeFailureStreak())
+		assert.Equal(t, int64(10), tracker.GetNetworkMethodMetrics(networkID, "*").HandshakesTotal.Load())
+		// Failed handshakes are neither errors nor part of the handshake latency
+		assert.Equal(t, int64(10), m.RequestsTotal.Load())
+		assert.Equal(t, int64(1), m.ErrorsTotal.Load())
+		assert.InDelta(t, 0.1, tracker.GetHandshakeQuantiles("a", networkID).GetQuantile(0.90).Seconds(), 0.002)
+		assert.False(t, m.ResponseQuantiles.HasSamples())
+		assert.Nil(t, tracker.GetHandshakeQuantiles("b", networkID))
+		assert.Zero(t, tracker.GetUpstreamMethodMetrics("a", networkID, "eth_call").HandshakesTotal.Load())
+
+		data, err := json.Marshal(m)
+		require.NoError(t, err)
+		var decoded map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, float64(10), decoded["handshakesTotal"])
+		assert.Equal(t, float64(1), decoded["handshakeFailuresTotal"])
+		assert.InDelta(t, 0.1, decoded["handshakeP90"], 0.002)
+
+		// Requests do not touch handshake stats either
+		recordRequests(tracker, networkID, "a", "eth_call", 10, 10)
+		assert.Equal(t, int64(10), m.HandshakesTotal.Load())
+		assert.Equal(t, int64(1), m.HandshakeFailureStreak())
+
+		healthtest.AdvanceWindow(t, clock, time.Minute, m)
+		assert.Zero(t, m.HandshakesTotal.Load())
+		assert.False(t, tracker.GetHandshakeQuantiles("a", networkID).HasSamples())
+		assert.Equal(t, int64(1), m.HandshakeFailureStreak())
+	})
+
+	t.Run("CordonsOnPersistentFailures", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		tracker.SetHandshakeCordonThreshold(3)
+
+		tracker.RecordUpstreamHandshake("a", networkID, false, time.Second)
+		tracker.RecordUpstreamHandshake("a", networkID, false, time.Second)
+		tracker.RecordUpstreamHandshake("a", networkID, true, time.Second)
+		tracker.RecordUpstreamHandshake("a", networkID, false, time.Second)
+		tracker.RecordUpstreamHandshake("a", networkID, false, time.Second)
+		assert.False(t, tracker.IsCordoned("a", networkID, "eth_call"))
+
+		tracker.RecordUpstreamHandshake("a", networkID, false, time.Second)
+		assert.True(t, tracker.IsCordoned("a", networkID, "eth_call"))
+		assert.False(t, tracker.IsCordoned("b", networkID, "eth_call"))
+		info := tracker.GetUpstreamMethodMetrics("a", networkID, "*").CordonInfo()
+		require.NotNil(t, info)
+		assert.Equal(t, health.CordonReasonHandshakeFailing, info.Reason)
+		assert.Contains(t, info.Detail, "3 handshakes failed in a row")
+		assert.Equal(t, health.CordonSourceTracker, info.Source)
+	})
+}
@@ -0,0 +1,23 @@
+package health
+
+import "time"
+
+// NetworkHeadStalled tells whether the highest head of the network did not advance for more than
+// maxStall, e.g. on a chain halt or when every upstream stopped following it. Lag based checks
+// miss such stalls as they compare upstreams to each other. It is false before any head is known
+// or when maxStall is not positive.
+func (t *Tracker) NetworkHeadStalled(network string, maxStall time.Duration) bool {
+	if maxStall <= 0 {
+		return false
+	}
+	network = t.canonicalNetwork(network)
+	val, ok := t.metadata.Load(duoKey{"*", network})
+	if !ok {
+		return false
+	}
+	advancedAt := val.(*NetworkMetadata).headAdvancedAt.Load()
+	if advancedAt == 0 {
+		return false
+	}
+	return t.clock.Now().Sub(time.Unix(0, advancedAt)) > maxStall
+}
@@ -0,0 +1,35 @@
+package health_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNetworkHeadStalled(t *testing.T) {
+	networkID := "evm:123"
+	tracker, clock := newFakeClockTracker(t, time.Hour)
+	assert.False(t, tracker.NetworkHeadStalled(networkID, time.Minute), "no head known yet")
+
+	tracker.SetLatestBlockNumber("a", networkID, 100)
+	tracker.SetLatestBlockNumber("b", networkID, 100)
+
+	// Every upstream keeps reporting the same head, none lags behind the others
+	for i := 0; i < 6; i++ {
+		clock.Advance(10 * time.Second)
+		tracker.SetLatestBlockNumber("a", networkID, 100)
+		tracker.SetLatestBlockNumber("b", networkID, 99)
+	}
+	assert.False(t, tracker.NetworkHeadStalled(networkID, time.Minute))
+	assert.Equal(t, int64(0), tracker.SelectionView("a", networkID, "*").BlockHeadLag)
+
+	clock.Advance(time.Second)
+	assert.True(t, tracker.NetworkHeadStalled(networkID, time.Minute))
+	assert.False(t, tracker.NetworkHeadStalled(networkID, 2*time.Minute))
+	assert.False(t, tracker.NetworkHeadStalled(networkID, 0))
+	assert.False(t, tracker.NetworkHeadStalled("evm:456", time.Minute))
+
+	tracker.SetLatestBlockNumber("b", networkID, 101)
+	assert.False(t, tracker.NetworkHeadStalled(networkID, time.Minute))
+}
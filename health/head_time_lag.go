@@ -0,0 +1,76 @@
+package health
+
+import (
+	"sort"
+	"time"
+)
+
+// maxHeadArrivals are the most recent advances of the head of a network kept to measure time
+// lags, see BlockHeadTimeLag.
+const maxHeadArrivals = 256
+
+type headArrival struct {
+	number int64
+	at     time.Time
+}
+
+// recordHeadArrival records that the head of a network advanced to number at at. Network-level
+// metadata only.
+func (md *NetworkMetadata) recordHeadArrival(number int64, at time.Time) {
+	md.headArrivalsMu.Lock()
+	defer md.headArrivalsMu.Unlock()
+	if n := len(md.headArrivals); n > 0 && md.headArrivals[n-1].number >= number {
+		return
+	}
+	if len(md.headArrivals) == maxHeadArrivals {
+		md.headArrivals = append(md.headArrivals[:0], md.headArrivals[1:]...)
+	}
+	md.headArrivals = append(md.headArrivals, headArrival{number: number, at: at})
+}
+
+// firstArrivalAbove returns when the head of a network first went above number, or the oldest
+// arrival kept when it went above before that, false when it never went above.
+func (md *NetworkMetadata) firstArrivalAbove(number int64) (time.Time, bool) {
+	md.headArrivalsMu.Lock()
+	defer md.headArrivalsMu.Unlock()
+	i := sort.Search(len(md.headArrivals), func(i int) bool {
+		return md.headArrivals[i].number > number
+	})
+	if i == len(md.headArrivals) {
+		return time.Time{}, false
+	}
+	return md.headArrivals[i].at, true
+}
+
+// BlockHeadTimeLag is the block head lag of an upstream on a network expressed in time, from the
+// observed arrival of blocks rather than an average block time: how long ago the network head
+// first went past the head of the upstream, i.e. how long the first block the upstream misses has
+// been available elsewhere. It is zero while the upstream is at the network head, and false while
+// either head is unknown. Lags beyond the last 256 head advances are underestimated.
+func (t *Tracker) BlockHeadTimeLag(ups, network string) (time.Duration, bool) {
+	network = t.canonicalNetwork(network)
+	return t.blockHeadTimeLagOf(ups, network, t.clock.Now())
+}
+
+func (t *Tracker) blockHeadTimeLagOf(ups, network string, now time.Time) (time.Duration, bool) {
+	ntwVal, ok := t.metadata.Load(duoKey{ups: "*", network: network})
+	if !ok {
+		return 0, false
+	}
+	upsVal, ok := t.metadata.Load(duoKey{ups: ups, network: network})
+	if !ok {
+		return 0, false
+	}
+	upsBn := upsVal.(*NetworkMetadata).evmLatestBlockNumber.Load()
+	if upsBn <= 0 {
+		return 0, false
+	}
+	arrivedAt, behind := ntwVal.(*NetworkMetadata).firstArrivalAbove(upsBn)
+	if !behind {
+		return 0, true
+	}
+	if lag := now.Sub(arrivedAt); lag > 0 {
+		return lag, true
+	}
+	return 0, true
+}
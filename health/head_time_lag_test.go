@@ -0,0 +1,62 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health/internal/fakeclock"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockHeadTimeLag(t *testing.T) {
+	networkID := "evm:123"
+	clock := fakeclock.New(time.Unix(1700000000, 0))
+	tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+	tracker.SetClock(clock)
+
+	_, ok := tracker.BlockHeadTimeLag("a", networkID)
+	assert.False(t, ok, "unknown before any head")
+
+	// Lags are tracked on the keys of the upstreams serving traffic
+	simulateRequestMetrics(tracker, networkID, "a", "eth_call", 1, 0)
+	simulateRequestMetrics(tracker, networkID, "b", "eth_call", 1, 0)
+
+	// Blocks arrive every 12s through a, b follows late
+	tracker.SetLatestBlockNumber("a", networkID, 100)
+	tracker.SetLatestBlockNumber("b", networkID, 100)
+	clock.Advance(12 * time.Second)
+	tracker.SetLatestBlockNumber("a", networkID, 101)
+	clock.Advance(12 * time.Second)
+	tracker.SetLatestBlockNumber("a", networkID, 102)
+	clock.Advance(5 * time.Second)
+
+	lag, ok := tracker.BlockHeadTimeLag("a", networkID)
+	assert.True(t, ok)
+	assert.Zero(t, lag, "at the head")
+	lag, ok = tracker.BlockHeadTimeLag("b", networkID)
+	assert.True(t, ok)
+	assert.Equal(t, 17*time.Second, lag, "block 101 arrived 17s ago")
+	assert.Equal(t, int64(2), tracker.GetUpstreamMethodMetrics("b", networkID, "*").BlockHeadLag.Load())
+
+	// Catching up partially leaves the lag of the next missing block
+	tracker.SetLatestBlockNumber("b", networkID, 101)
+	lag, _ = tracker.BlockHeadTimeLag("b", networkID)
+	assert.Equal(t, 5*time.Second, lag)
+	assert.Equal(t, 5*time.Second, tracker.GetNetworkUpstreamsMetrics(networkID, "*")["b"].BlockHeadTimeLag)
+
+	// A jump of several blocks counts from its arrival
+	clock.Advance(3 * time.Second)
+	tracker.SetLatestBlockNumber("a", networkID, 110)
+	tracker.SetLatestBlockNumber("b", networkID, 102)
+	clock.Advance(4 * time.Second)
+	lag, _ = tracker.BlockHeadTimeLag("b", networkID)
+	assert.Equal(t, 4*time.Second, lag)
+
+	tracker.SetLatestBlockNumber("b", networkID, 110)
+	lag, _ = tracker.BlockHeadTimeLag("b", networkID)
+	assert.Zero(t, lag)
+
+	_, ok = tracker.BlockHeadTimeLag("c", networkID)
+	assert.False(t, ok, "unknown upstream head")
+}
@@ -0,0 +1,51 @@
+package health
+
+import (
+	"github.com/erpc/erpc/telemetry"
+)
+
+const (
+	// lowRateLimitHeadroom is the headroom under which EffectiveWeight deprioritizes an upstream,
+	// proportionally to the headroom left.
+	lowRateLimitHeadroom = 0.2
+	// exhaustedHeadroomPenalty is the score multiplier of an upstream with no headroom left.
+	exhaustedHeadroomPenalty = 0.01
+)
+
+// SetUpstreamRateLimitHeadroom records the usage of an upstream on a network against the rate
+// limit of its provider, e.g. as reported by its response headers. Once less than a fifth of the
+// limit remains, EffectiveWeight deprioritizes the upstream the more it approaches the cap. A
+// non-positive limit forgets the headroom.
+func (t *Tracker) SetUpstreamRateLimitHeadroom(ups, network string, remaining, limit int64) {
+	network = t.canonicalNetwork(network)
+	k := duoKey{ups: ups, network: network}
+	gauge := telemetry.MetricUpstreamRateLimitHeadroom.WithLabelValues(t.projectId, network, ups, t.upstreamVendor(ups, network))
+	if limit <= 0 {
+		t.rateLimitHeadrooms.Delete(k)
+		gauge.Set(1)
+		return
+	}
+	headroom := float64(min(max(remaining, 0), limit)) / float64(limit)
+	t.rateLimitHeadrooms.Store(k, headroom)
+	gauge.Set(headroom)
+}
+
+// RateLimitHeadroom returns the fraction (0 to 1) of the rate limit of an upstream left on a
+// network, i.e. remaining/limit as last set by SetUpstreamRateLimitHeadroom. It is 1 when unknown.
+func (t *Tracker) RateLimitHeadroom(ups, network string) float64 {
+	network = t.canonicalNetwork(network)
+	if val, ok := t.rateLimitHeadrooms.Load(duoKey{ups: ups, network: network}); ok {
+		return val.(float64)
+	}
+	return 1
+}
+
+// headroomPenalty returns the score multiplier of an upstream on a network, based on its rate
+// limit headroom.
+func (t *Tracker) headroomPenalty(ups, network string) float64 {
+	val, ok := t.rateLimitHeadrooms.Load(duoKey{ups: ups, network: network})
+	if !ok || val.(float64) >= lowRateLimitHeadroom {
+		return 1
+	}
+	return max(val.(float64)/lowRateLimitHeadroom, exhaustedHeadroomPenalty)
+}
@@ -0,0 +1,37 @@
+package health_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitHeadroom(t *testing.T) {
+	networkID := "evm:123"
+	tracker, _ := newFakeClockTracker(t, time.Minute)
+
+	assert.Equal(t, 1.0, tracker.RateLimitHeadroom("a", networkID))
+	assert.Equal(t, 10.0, tracker.EffectiveWeight("a", networkID, 10))
+
+	// Plenty of headroom keeps the weight, a nearly capped upstream is deprioritized
+	tracker.SetUpstreamRateLimitHeadroom("a", networkID, 600, 1000)
+	tracker.SetUpstreamRateLimitHeadroom("b", networkID, 50, 1000)
+	assert.InDelta(t, 0.6, tracker.RateLimitHeadroom("a", networkID), 1e-9)
+	assert.InDelta(t, 0.05, tracker.RateLimitHeadroom("b", networkID), 1e-9)
+	assert.Equal(t, 10.0, tracker.EffectiveWeight("a", networkID, 10))
+	assert.InDelta(t, 2.5, tracker.EffectiveWeight("b", networkID, 10), 1e-9)
+	assert.Greater(t, tracker.EffectiveWeight("a", networkID, 5), tracker.EffectiveWeight("b", networkID, 10))
+
+	// Usage over the limit counts as no headroom, the weight stays positive
+	tracker.SetUpstreamRateLimitHeadroom("b", networkID, -3, 1000)
+	assert.Equal(t, 0.0, tracker.RateLimitHeadroom("b", networkID))
+	assert.InDelta(t, 0.1, tracker.EffectiveWeight("b", networkID, 10), 1e-9)
+	tracker.SetUpstreamRateLimitHeadroom("b", networkID, 2000, 1000)
+	assert.Equal(t, 1.0, tracker.RateLimitHeadroom("b", networkID))
+
+	// Headroom is per network and forgotten without a limit
+	assert.Equal(t, 1.0, tracker.RateLimitHeadroom("a", "evm:456"))
+	tracker.SetUpstreamRateLimitHeadroom("a", networkID, 0, 0)
+	assert.Equal(t, 1.0, tracker.RateLimitHeadroom("a", networkID))
+}
@@ -0,0 +1,269 @@
+package healthtest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/erpc/erpc/health"
+	"github.com/rs/zerolog"
+)
+
+// latencySamples is the number of synthetic observations generated per key with latency targets.
+const latencySamples = 1000
+
+type builderKey struct {
+	ups     string
+	network string
+	method  string
+}
+
+type latencyTarget struct {
+	q float64
+	d time.Duration
+}
+
+type keySpec struct {
+	requests          int
+	errors            int
+	selfRateLimited   int
+	remoteRateLimited int
+	latencies         []latencyTarget
+	blockHeadLag      *int64
+	finalizationLag   *int64
+	cordonReason      *string
+}
+
+// TrackerBuilder builds a tracker pre-populated with a desired health state, going through the
+// same Record* methods production code uses. Upstream, Network and Method select the key the
+// following calls apply to, each of them stays in effect until changed.
+//
+// The built tracker is not bootstrapped, so the seeded state is never reset by a window.
+type TrackerBuilder struct {
+	projectId  string
+	windowSize time.Duration
+	clock      health.Clock
+
+	current builderKey
+	order   []builderKey
+	specs   map[builderKey]*keySpec
+}
+
+// NewTrackerBuilder creates an empty builder.
+func NewTrackerBuilder() *TrackerBuilder {
+	return &TrackerBuilder{
+		projectId:  "test-project",
+		windowSize: time.Minute,
+		specs:      make(map[builderKey]*keySpec),
+	}
+}
+
+// Project sets the project id of the tracker, defaults to "test-project".
+func (b *TrackerBuilder) Project(projectId string) *TrackerBuilder {
+	b.projectId = projectId
+	return b
+}
+
+// WindowSize sets the window size of the tracker, defaults to one minute.
+func (b *TrackerBuilder) WindowSize(windowSize time.Duration) *TrackerBuilder {
+	b.windowSize = windowSize
+	return b
+}
+
+// Clock sets the clock of the tracker.
+func (b *TrackerBuilder) Clock(clock health.Clock) *TrackerBuilder {
+	b.clock = clock
+	return b
+}
+
+func (b *TrackerBuilder) Upstream(ups string) *TrackerBuilder {
+	b.current.ups = ups
+	return b
+}
+
+func (b *TrackerBuilder) Network(network string) *TrackerBuilder {
+	b.current.network = network
+	return b
+}
+
+func (b *TrackerBuilder) Method(method string) *TrackerBuilder {
+	b.current.method = method
+	return b
+}
+
+// Requests records n requests on the current key.
+func (b *TrackerBuilder) Requests(n int) *TrackerBuilder {
+	b.spec().requests += n
+	return b
+}
+
+// Errors records n failures on the current key, they are not counted as extra requests.
+func (b *TrackerBuilder) Errors(n int) *TrackerBuilder {
+	b.spec().errors += n
+	return b
+}
+
+func (b *TrackerBuilder) SelfRateLimited(n int) *TrackerBuilder {
+	b.spec().selfRateLimited += n
+	return b
+}
+
+func (b *TrackerBuilder) RemoteRateLimited(n int) *TrackerBuilder {
+	b.spec().remoteRateLimited += n
+	return b
+}
+
+// Latency seeds synthetic durations so that the qtile quantile of the current key is d (within
+// the 1% accuracy of the quantile tracker). Several quantiles can be combined on the same key.
+func (b *TrackerBuilder) Latency(qtile float64, d time.Duration) *TrackerBuilder {
+	if qtile <= 0 || qtile >= 1 {
+		panic(fmt.Sprintf("healthtest: quantile must be within (0, 1), got %v", qtile))
+	}
+	s := b.spec()
+	s.latencies = append(s.latencies, latencyTarget{q: qtile, d: d})
+	return b
+}
+
+func (b *TrackerBuilder) P50(d time.Duration) *TrackerBuilder {
+	return b.Latency(0.50, d)
+}
+
+func (b *TrackerBuilder) P90(d time.Duration) *TrackerBuilder {
+	return b.Latency(0.90, d)
+}
+
+func (b *TrackerBuilder) P99(d time.Duration) *TrackerBuilder {
+	return b.Latency(0.99, d)
+}
+
+// BlockHeadLag sets the block head lag of the current upstream on the current network.
+func (b *TrackerBuilder) BlockHeadLag(lag int64) *TrackerBuilder {
+	b.spec().blockHeadLag = &lag
+	return b
+}
+
+// FinalizationLag sets the finalization lag of the current upstream on the current network.
+func (b *TrackerBuilder) FinalizationLag(lag int64) *TrackerBuilder {
+	b.spec().finalizationLag = &lag
+	return b
+}
+
+// Cordoned cordons the current key with the given reason.
+func (b *TrackerBuilder) Cordoned(reason string) *TrackerBuilder {
+	b.spec().cordonReason = &reason
+	return b
+}
+
+func (b *TrackerBuilder) spec() *keySpec {
+	k := b.current
+	if k.ups == "" || k.network == "" || k.method == "" {
+		panic(fmt.Sprintf("healthtest: upstream, network and method must be set before seeding metrics, got %+v", k))
+	}
+	s, ok := b.specs[k]
+	if !ok {
+		s = &keySpec{}
+		b.specs[k] = s
+		b.order = append(b.order, k)
+	}
+	return s
+}
+
+// Build creates the tracker and replays the seeded state into it, in the order keys were first used.
+func (b *TrackerBuilder) Build() *health.Tracker {
+	logger := zerolog.Nop()
+	t := health.NewTracker(&logger, b.projectId, b.windowSize)
+	if b.clock != nil {
+		t.SetClock(b.clock)
+	}
+
+	for _, k := range b.order {
+		s := b.specs[k]
+		for i := 0; i < s.requests; i++ {
+			t.RecordUpstreamRequest(k.ups, k.network, k.method)
+		}
+		for i := 0; i < s.errors; i++ {
+			t.RecordUpstreamFailure(k.ups, k.network, k.method)
+		}
+		for i := 0; i < s.selfRateLimited; i++ {
+			t.RecordUpstreamSelfRateLimited(k.ups, k.network, k.method)
+		}
+		for i := 0; i < s.remoteRateLimited; i++ {
+			t.RecordUpstreamRemoteRateLimited(k.ups, k.network, k.method)
+		}
+		for _, d := range syntheticDurations(s.latencies) {
+			t.RecordUpstreamDuration(k.ups, k.network, k.method, d, "none")
+		}
+	}
+
+	// Lags are applied once every key exists, so that they land on all keys of the upstream
+	for _, k := range b.order {
+		s := b.specs[k]
+		if s.blockHeadLag == nil && s.finalizationLag == nil {
+			continue
+		}
+		// Make sure the keys exist even if nothing else was seeded on them
+		t.GetUpstreamMethodMetrics(k.ups, k.network, k.method)
+		t.GetUpstreamMethodMetrics(k.ups, k.network, "*")
+		for key, m := range t.GetUpstreamMetrics(k.ups) {
+			// Keys are formatted as "network|method"
+			if !strings.HasPrefix(key, k.network+"|") {
+				continue
+			}
+			if s.blockHeadLag != nil {
+				m.BlockHeadLag.Store(*s.blockHeadLag)
+			}
+			if s.finalizationLag != nil {
+				m.FinalizationLag.Store(*s.finalizationLag)
+			}
+		}
+	}
+
+	for _, k := range b.order {
+		if reason := b.specs[k].cordonReason; reason != nil {
+			t.Cordon(k.ups, k.network, k.method, *reason)
+		}
+	}
+
+	return t
+}
+
+// BuildRecorder builds the tracker and wraps it in a Recorder.
+func (b *TrackerBuilder) BuildRecorder() *Recorder {
+	r := NewRecorder(b.Build())
+	if b.clock != nil {
+		r.SetClock(b.clock)
+	}
+	return r
+}
+
+// syntheticDurations generates observations from a piecewise linear distribution that goes
+// through every target (quantile, duration), so that the tracked quantiles match the targets.
+func syntheticDurations(targets []latencyTarget) []time.Duration {
+	if len(targets) == 0 {
+		return nil
+	}
+	points := append([]latencyTarget(nil), targets...)
+	sort.Slice(points, func(i, j int) bool { return points[i].q < points[j].q })
+	for i := 1; i < len(points); i++ {
+		if points[i].d < points[i-1].d {
+			// Quantiles can't decrease, keep the distribution monotonic
+			points[i].d = points[i-1].d
+		}
+	}
+	points = append([]latencyTarget{{q: 0, d: points[0].d / 2}}, points...)
+	points = append(points, latencyTarget{q: 1, d: points[len(points)-1].d * 3 / 2})
+
+	out := make([]time.Duration, latencySamples)
+	j := 0
+	for i := range out {
+		q := (float64(i) + 0.5) / latencySamples
+		for points[j+1].q < q {
+			j++
+		}
+		lo, hi := points[j], points[j+1]
+		frac := (q - lo.q) / (hi.q - lo.q)
+		out[i] = lo.d + time.Duration(frac*float64(hi.d-lo.d))
+	}
+	return out
+}
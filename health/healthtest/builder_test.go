@@ -0,0 +1,62 @@
+package healthtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrackerBuilder(t *testing.T) {
+	t.Run("SeedsCountersOnAllKeys", func(t *testing.T) {
+		tracker := NewTrackerBuilder().
+			Upstream("a").Network("evm:1").Method("eth_call").Requests(1000).Errors(20).SelfRateLimited(5).
+			Upstream("b").Requests(10).
+			Build()
+
+		m := tracker.GetUpstreamMethodMetrics("a", "evm:1", "eth_call")
+		assert.Equal(t, int64(1000), m.RequestsTotal.Load())
+		assert.Equal(t, int64(20), m.ErrorsTotal.Load())
+		assert.Equal(t, int64(5), m.SelfRateLimitedTotal.Load())
+		assert.InDelta(t, 0.02, m.ErrorRate(), 1e-9)
+
+		network := tracker.GetNetworkMethodMetrics("evm:1", "eth_call")
+		assert.Equal(t, int64(1010), network.RequestsTotal.Load())
+	})
+
+	t.Run("SeedsQuantilesWithinTolerance", func(t *testing.T) {
+		tracker := NewTrackerBuilder().
+			Upstream("a").Network("evm:1").Method("eth_call").P90(150 * time.Millisecond).
+			Upstream("b").P50(20 * time.Millisecond).P90(400 * time.Millisecond).P99(2 * time.Second).
+			Build()
+
+		qa := tracker.GetUpstreamMethodMetrics("a", "evm:1", "eth_call").ResponseQuantiles
+		assert.InEpsilon(t, 0.150, qa.GetQuantile(0.90).Seconds(), 0.02)
+
+		qb := tracker.GetUpstreamMethodMetrics("b", "evm:1", "eth_call").ResponseQuantiles
+		assert.InEpsilon(t, 0.020, qb.GetQuantile(0.50).Seconds(), 0.02)
+		assert.InEpsilon(t, 0.400, qb.GetQuantile(0.90).Seconds(), 0.02)
+		assert.InEpsilon(t, 2.0, qb.GetQuantile(0.99).Seconds(), 0.02)
+	})
+
+	t.Run("SeedsLagAndCordon", func(t *testing.T) {
+		tracker := NewTrackerBuilder().
+			Upstream("a").Network("evm:1").Method("eth_call").Requests(1).BlockHeadLag(3).FinalizationLag(7).Cordoned("flaky").
+			Upstream("a").Network("evm:2").Requests(1).
+			Build()
+
+		assert.Equal(t, int64(3), tracker.GetUpstreamMethodMetrics("a", "evm:1", "eth_call").BlockHeadLag.Load())
+		assert.Equal(t, int64(3), tracker.GetUpstreamMethodMetrics("a", "evm:1", "*").BlockHeadLag.Load())
+		assert.Equal(t, int64(7), tracker.GetUpstreamMethodMetrics("a", "evm:1", "*").FinalizationLag.Load())
+		assert.Equal(t, int64(0), tracker.GetUpstreamMethodMetrics("a", "evm:2", "eth_call").BlockHeadLag.Load())
+
+		assert.True(t, tracker.IsCordoned("a", "evm:1", "eth_call"))
+		assert.False(t, tracker.IsCordoned("a", "evm:2", "eth_call"))
+	})
+
+	t.Run("PanicsWithoutKey", func(t *testing.T) {
+		assert.Panics(t, func() {
+			NewTrackerBuilder().Upstream("a").Requests(1)
+		})
+	})
+}
@@ -0,0 +1,30 @@
+package healthtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health"
+	"github.com/erpc/erpc/health/internal/fakeclock"
+	"github.com/stretchr/testify/assert"
+)
+
+// FakeClock is a health.Clock whose time only moves when Advance is called.
+type FakeClock = fakeclock.FakeClock
+
+var _ health.Clock = (*FakeClock)(nil)
+
+// NewFakeClock creates a fake clock starting at the given time.
+func NewFakeClock(start time.Time) *FakeClock {
+	return fakeclock.New(start)
+}
+
+// AdvanceWindow moves the clock of a bootstrapped tracker by one window and waits for the reset
+// to be applied, observed on m, metrics with requests recorded in the window.
+func AdvanceWindow(t testing.TB, clock *FakeClock, windowSize time.Duration, m *health.TrackedMetrics) {
+	t.Helper()
+	clock.Advance(windowSize)
+	assert.Eventually(t, func() bool {
+		return m.RequestsTotal.Load() == 0
+	}, time.Second, time.Millisecond)
+}
@@ -0,0 +1,47 @@
+package healthtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeClock(t *testing.T) {
+	t.Run("AdvanceDeliversEveryDueTick", func(t *testing.T) {
+		clock := NewFakeClock(time.Unix(0, 0))
+		ticker := clock.NewTicker(time.Second)
+		defer ticker.Stop()
+
+		ticks := make(chan time.Time, 10)
+		go func() {
+			for tm := range ticker.C() {
+				ticks <- tm
+			}
+		}()
+
+		clock.Advance(3500 * time.Millisecond)
+		assert.Len(t, ticks, 3)
+		assert.Equal(t, time.Unix(1, 0), <-ticks)
+		assert.Equal(t, time.Unix(3, 500_000_000), clock.Now())
+	})
+
+	t.Run("AfterFiresOnlyWhenDue", func(t *testing.T) {
+		clock := NewFakeClock(time.Unix(0, 0))
+		ch := clock.After(time.Minute)
+
+		clock.Advance(59 * time.Second)
+		assert.Len(t, ch, 0)
+		clock.Advance(time.Second)
+		assert.Len(t, ch, 1)
+	})
+
+	t.Run("StoppedTickerDoesNotBlockAdvance", func(t *testing.T) {
+		clock := NewFakeClock(time.Unix(0, 0))
+		ticker := clock.NewTicker(time.Second)
+		ticker.Stop()
+
+		clock.Advance(10 * time.Second)
+		assert.Equal(t, time.Unix(10, 0), clock.Now())
+	})
+}
@@ -0,0 +1,5 @@
+// Package healthtest provides test helpers for code depending on the health package:
+// a FakeClock to drive windows and timers deterministically, a Recorder capturing calls
+// made to a health.MetricsTracker, and a TrackerBuilder to seed a tracker with a desired
+// health state instead of hand-calling Record* methods.
+package healthtest
@@ -0,0 +1,45 @@
+package healthtest_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/erpc/erpc/health/healthtest"
+)
+
+func ExampleTrackerBuilder() {
+	tracker := healthtest.NewTrackerBuilder().
+		Upstream("a").Network("evm:1").Method("eth_call").
+		Requests(1000).Errors(20).P90(150 * time.Millisecond).BlockHeadLag(3).
+		Upstream("b").
+		Requests(1000).Cordoned("maintenance").
+		Build()
+
+	a := tracker.GetUpstreamMethodMetrics("a", "evm:1", "eth_call")
+	fmt.Printf("error rate: %.2f\n", a.ErrorRate())
+	fmt.Printf("p90: %dms\n", a.ResponseQuantiles.GetQuantile(0.90).Round(10*time.Millisecond).Milliseconds())
+	fmt.Printf("block head lag: %d\n", a.BlockHeadLag.Load())
+	fmt.Printf("b cordoned: %v\n", tracker.IsCordoned("b", "evm:1", "eth_call"))
+	// Output:
+	// error rate: 0.02
+	// p90: 150ms
+	// block head lag: 3
+	// b cordoned: true
+}
+
+func ExampleTrackerBuilder_BuildRecorder() {
+	recorder := healthtest.NewTrackerBuilder().
+		Upstream("a").Network("evm:1").Method("eth_call").Cordoned("maintenance").
+		BuildRecorder()
+
+	if recorder.IsCordoned("a", "evm:1", "eth_call") {
+		recorder.RecordUpstreamFailure("a", "evm:1", "eth_call")
+	}
+
+	for _, c := range recorder.Calls() {
+		fmt.Println(c.Method, c.Args)
+	}
+	// Output:
+	// IsCordoned [a evm:1 eth_call]
+	// RecordUpstreamFailure [a evm:1 eth_call]
+}
@@ -0,0 +1,467 @@
+package healthtest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/health"
+)
+
+// Call is a single method call captured by a Recorder.
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// Recorder is a health.MetricsTracker that captures every call before forwarding it to an
+// inner tracker. Timers it hands out report back to the recorder, so ObserveDuration and the
+// hedge observations are captured as well.
+type Recorder struct {
+	inner health.MetricsTracker
+	clock health.Clock
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ health.MetricsTracker = (*Recorder)(nil)
+
+// NewRecorder creates a recorder forwarding to inner, or to a no-op tracker if inner is nil.
+func NewRecorder(inner health.MetricsTracker) *Recorder {
+	if inner == nil {
+		inner = health.NewNoopTracker()
+	}
+	return &Recorder{inner: inner, clock: health.RealClock{}}
+}
+
+// SetClock sets the clock used by the timers handed out by the recorder.
+func (r *Recorder) SetClock(c health.Clock) {
+	r.clock = c
+}
+
+// Calls returns a copy of all captured calls in the order they were made.
+func (r *Recorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Call(nil), r.calls...)
+}
+
+// CallsTo returns the captured calls of the given method.
+func (r *Recorder) CallsTo(method string) []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []Call
+	for _, c := range r.calls {
+		if c.Method == method {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// Reset forgets all captured calls.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = nil
+}
+
+func (r *Recorder) record(method string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, Call{Method: method, Args: args})
+}
+
+func (r *Recorder) RecordUpstreamRequest(ups, network, method string) {
+	r.record("RecordUpstreamRequest", ups, network, method)
+	r.inner.RecordUpstreamRequest(ups, network, method)
+}
+
+func (r *Recorder) RecordUpstreamDurationStart(ups, network, method string, compositeType string) *health.Timer {
+	r.record("RecordUpstreamDurationStart", ups, network, method, compositeType)
+	return health.NewTimer(r, r.clock, ups, network, method, compositeType)
+}
+
+func (r *Recorder) RecordUpstreamDuration(ups, network, method string, duration time.Duration, compositeType string) {
+	r.record("RecordUpstreamDuration", ups, network, method, duration, compositeType)
+	r.inner.RecordUpstreamDuration(ups, network, method, duration, compositeType)
+}
+
+func (r *Recorder) RecordUpstreamDurationWithQuantiles(ups, network, method string, duration time.Duration, compositeType string) (p50, p99 float64) {
+	r.record("RecordUpstreamDurationWithQuantiles", ups, network, method, duration, compositeType)
+	return r.inner.RecordUpstreamDurationWithQuantiles(ups, network, method, duration, compositeType)
+}
+
+func (r *Recorder) RecordUpstreamFinalityDuration(ups, network, method string, duration time.Duration, compositeType string, finality common.DataFinalityState) {
+	r.record("RecordUpstreamFinalityDuration", ups, network, method, duration, compositeType, finality)
+	r.inner.RecordUpstreamFinalityDuration(ups, network, method, duration, compositeType, finality)
+}
+
+func (r *Recorder) RecordUpstreamFailure(ups, network, method string) {
+	r.record("RecordUpstreamFailure", ups, network, method)
+	r.inner.RecordUpstreamFailure(ups, network, method)
+}
+
+func (r *Recorder) RecordUpstreamSelfRateLimited(ups, network, method string) {
+	r.record("RecordUpstreamSelfRateLimited", ups, network, method)
+	r.inner.RecordUpstreamSelfRateLimited(ups, network, method)
+}
+
+func (r *Recorder) RecordUpstreamRemoteRateLimited(ups, network, method string) {
+	r.record("RecordUpstreamRemoteRateLimited", ups, network, method)
+	r.inner.RecordUpstreamRemoteRateLimited(ups, network, method)
+}
+
+func (r *Recorder) RecordUpstreamHedgeStart(ups, network, method string) *health.Timer {
+	r.record("RecordUpstreamHedgeStart", ups, network, method)
+	r.inner.RecordUpstreamHedgeStart(ups, network, method)
+	return health.NewTimer(r, r.clock, ups, network, method, health.CompositeTypeHedge)
+}
+
+func (r *Recorder) RecordUpstreamHedgeWon(ups, network, method string) {
+	r.record("RecordUpstreamHedgeWon", ups, network, method)
+	r.inner.RecordUpstreamHedgeWon(ups, network, method)
+}
+
+func (r *Recorder) RecordUpstreamHedgeCancelled(ups, network, method string, wasted time.Duration) {
+	r.record("RecordUpstreamHedgeCancelled", ups, network, method, wasted)
+	r.inner.RecordUpstreamHedgeCancelled(ups, network, method, wasted)
+}
+
+func (r *Recorder) RecordOutcome(ups, network, method string, o health.Outcome) {
+	r.record("RecordOutcome", ups, network, method, o)
+	r.inner.RecordOutcome(ups, network, method, o)
+}
+
+func (r *Recorder) RecordUpstreamCancelled(ups, network, method string, cause health.CancelCause, elapsed time.Duration) {
+	r.record("RecordUpstreamCancelled", ups, network, method, cause, elapsed)
+	r.inner.RecordUpstreamCancelled(ups, network, method, cause, elapsed)
+}
+
+func (r *Recorder) RecordRequestOutcome(chain []health.AttemptResult) {
+	r.record("RecordRequestOutcome", chain)
+	r.inner.RecordRequestOutcome(chain)
+}
+
+func (r *Recorder) Clock() health.Clock {
+	return r.inner.Clock()
+}
+
+func (r *Recorder) RecordUpstreamReconnect(ups, network string) {
+	r.record("RecordUpstreamReconnect", ups, network)
+	r.inner.RecordUpstreamReconnect(ups, network)
+}
+
+func (r *Recorder) RecordUpstreamHandshake(ups, network string, success bool, d time.Duration) {
+	r.record("RecordUpstreamHandshake", ups, network, success, d)
+	r.inner.RecordUpstreamHandshake(ups, network, success, d)
+}
+
+func (r *Recorder) RecordUpstreamProbe(ups, network string, success bool) {
+	r.record("RecordUpstreamProbe", ups, network, success)
+	r.inner.RecordUpstreamProbe(ups, network, success)
+}
+
+func (r *Recorder) RecordUpstreamMalformedResponse(ups, network, method string, kind string) {
+	r.record("RecordUpstreamMalformedResponse", ups, network, method, kind)
+	r.inner.RecordUpstreamMalformedResponse(ups, network, method, kind)
+}
+
+func (r *Recorder) RecordUpstreamMalformedSample(ups, network, method, kind string, payload []byte) {
+	r.record("RecordUpstreamMalformedSample", ups, network, method, kind, payload)
+	r.inner.RecordUpstreamMalformedSample(ups, network, method, kind, payload)
+}
+
+func (r *Recorder) RecordUpstreamTraceSample(rec health.RequestRecord) {
+	r.record("RecordUpstreamTraceSample", rec)
+	r.inner.RecordUpstreamTraceSample(rec)
+}
+
+func (r *Recorder) SetUpstreamAttributes(ups, network string, attrs map[string]string) {
+	r.record("SetUpstreamAttributes", ups, network, attrs)
+	r.inner.SetUpstreamAttributes(ups, network, attrs)
+}
+
+func (r *Recorder) RecordUpstreamClientVersion(ups, network, raw string) {
+	r.record("RecordUpstreamClientVersion", ups, network, raw)
+	r.inner.RecordUpstreamClientVersion(ups, network, raw)
+}
+
+func (r *Recorder) RecordCertificateExpiry(ups, network string, notAfter time.Time, warningDays float64) {
+	r.record("RecordCertificateExpiry", ups, network, notAfter, warningDays)
+	r.inner.RecordCertificateExpiry(ups, network, notAfter, warningDays)
+}
+
+func (r *Recorder) RecordCertificateProbeFailure(ups, network string, err error) {
+	r.record("RecordCertificateProbeFailure", ups, network, err)
+	r.inner.RecordCertificateProbeFailure(ups, network, err)
+}
+
+func (r *Recorder) RecordBlockHeadLargeRollback(ups, network, finality string, currentVal, newVal int64) {
+	r.record("RecordBlockHeadLargeRollback", ups, network, finality, currentVal, newVal)
+	r.inner.RecordBlockHeadLargeRollback(ups, network, finality, currentVal, newVal)
+}
+
+func (r *Recorder) SetLatestBlockNumber(ups, network string, blockNumber int64) {
+	r.record("SetLatestBlockNumber", ups, network, blockNumber)
+	r.inner.SetLatestBlockNumber(ups, network, blockNumber)
+}
+
+func (r *Recorder) RecordServedBlock(ups, network, method string, block int64) {
+	r.record("RecordServedBlock", ups, network, method, block)
+	r.inner.RecordServedBlock(ups, network, method, block)
+}
+
+func (r *Recorder) SetFinalizedBlockNumber(ups, network string, blockNumber int64) {
+	r.record("SetFinalizedBlockNumber", ups, network, blockNumber)
+	r.inner.SetFinalizedBlockNumber(ups, network, blockNumber)
+}
+
+func (r *Recorder) RecordBlockHash(ups, network string, number int64, hash string) {
+	r.record("RecordBlockHash", ups, network, number, hash)
+	r.inner.RecordBlockHash(ups, network, number, hash)
+}
+
+func (r *Recorder) BeginReorg(network string) {
+	r.record("BeginReorg", network)
+	r.inner.BeginReorg(network)
+}
+
+func (r *Recorder) EndReorg(network string) {
+	r.record("EndReorg", network)
+	r.inner.EndReorg(network)
+}
+
+func (r *Recorder) Cordon(ups, network, method, reason string) {
+	r.record("Cordon", ups, network, method, reason)
+	r.inner.Cordon(ups, network, method, reason)
+}
+
+func (r *Recorder) CordonWithInfo(ups, network, method string, info health.CordonInfo) {
+	r.record("CordonWithInfo", ups, network, method, info)
+	r.inner.CordonWithInfo(ups, network, method, info)
+}
+
+func (r *Recorder) Uncordon(ups, network, method string) {
+	r.record("Uncordon", ups, network, method)
+	r.inner.Uncordon(ups, network, method)
+}
+
+func (r *Recorder) UncordonWithSource(ups, network, method, source string) bool {
+	r.record("UncordonWithSource", ups, network, method, source)
+	return r.inner.UncordonWithSource(ups, network, method, source)
+}
+
+func (r *Recorder) IsCordoned(ups, network, method string) bool {
+	r.record("IsCordoned", ups, network, method)
+	return r.inner.IsCordoned(ups, network, method)
+}
+
+func (r *Recorder) IsMethodAllowed(ups, network, method string) bool {
+	r.record("IsMethodAllowed", ups, network, method)
+	return r.inner.IsMethodAllowed(ups, network, method)
+}
+
+func (r *Recorder) ShouldAdmit(ups, network, method string) bool {
+	r.record("ShouldAdmit", ups, network, method)
+	return r.inner.ShouldAdmit(ups, network, method)
+}
+
+func (r *Recorder) IsSaturated(ups, network string) bool {
+	r.record("IsSaturated", ups, network)
+	return r.inner.IsSaturated(ups, network)
+}
+
+func (r *Recorder) EligibleUpstreams(network string) int {
+	r.record("EligibleUpstreams", network)
+	return r.inner.EligibleUpstreams(network)
+}
+
+func (r *Recorder) GetForkSignal(network string, verbose bool) health.ForkSignal {
+	r.record("GetForkSignal", network, verbose)
+	return r.inner.GetForkSignal(network, verbose)
+}
+
+func (r *Recorder) OrderTies(network, method string, tied []string) {
+	r.record("OrderTies", network, method, tied)
+	r.inner.OrderTies(network, method, tied)
+}
+
+func (r *Recorder) SetUpstreamShadow(ups string, shadow bool) {
+	r.record("SetUpstreamShadow", ups, shadow)
+	r.inner.SetUpstreamShadow(ups, shadow)
+}
+
+func (r *Recorder) SetUpstreamGroup(ups, group string) {
+	r.record("SetUpstreamGroup", ups, group)
+	r.inner.SetUpstreamGroup(ups, group)
+}
+
+func (r *Recorder) SetConcurrencyLimit(ups, network string, c int) {
+	r.record("SetConcurrencyLimit", ups, network, c)
+	r.inner.SetConcurrencyLimit(ups, network, c)
+}
+
+func (r *Recorder) SetErrorBudgetConfig(ups, network string, cfg *health.ErrorBudgetConfig) error {
+	r.record("SetErrorBudgetConfig", ups, network, cfg)
+	return r.inner.SetErrorBudgetConfig(ups, network, cfg)
+}
+
+func (r *Recorder) EffectiveWeight(ups, network string, healthWeight float64) float64 {
+	r.record("EffectiveWeight", ups, network, healthWeight)
+	return r.inner.EffectiveWeight(ups, network, healthWeight)
+}
+
+func (r *Recorder) TrafficShareCap(ups, network string) float64 {
+	r.record("TrafficShareCap", ups, network)
+	return r.inner.TrafficShareCap(ups, network)
+}
+
+func (r *Recorder) ReportIntendedShares(network string, weights map[string]float64) {
+	r.record("ReportIntendedShares", network, weights)
+	r.inner.ReportIntendedShares(network, weights)
+}
+
+func (r *Recorder) IsHistoricalFor(ups, network string, block health.RequestBlock) bool {
+	r.record("IsHistoricalFor", ups, network, block)
+	return r.inner.IsHistoricalFor(ups, network, block)
+}
+
+func (r *Recorder) GetUpstreamMethodMetrics(ups, network, method string) *health.TrackedMetrics {
+	r.record("GetUpstreamMethodMetrics", ups, network, method)
+	return r.inner.GetUpstreamMethodMetrics(ups, network, method)
+}
+
+func (r *Recorder) LookupUpstreamMethodMetrics(ups, network, method string) (*health.TrackedMetrics, bool) {
+	r.record("LookupUpstreamMethodMetrics", ups, network, method)
+	return r.inner.LookupUpstreamMethodMetrics(ups, network, method)
+}
+
+func (r *Recorder) GetUpstreamMetrics(upsId string) map[string]*health.TrackedMetrics {
+	r.record("GetUpstreamMetrics", upsId)
+	return r.inner.GetUpstreamMetrics(upsId)
+}
+
+func (r *Recorder) GetUpstreamMetricsSnapshot(upsId string) map[string]*health.TrackedMetricsSnapshot {
+	r.record("GetUpstreamMetricsSnapshot", upsId)
+	return r.inner.GetUpstreamMetricsSnapshot(upsId)
+}
+
+func (r *Recorder) GetNetworkMethodMetrics(network, method string) *health.TrackedMetrics {
+	r.record("GetNetworkMethodMetrics", network, method)
+	return r.inner.GetNetworkMethodMetrics(network, method)
+}
+
+func (r *Recorder) GetNetworkUpstreamsMetrics(network, method string) map[string]*health.TrackedMetricsSnapshot {
+	r.record("GetNetworkUpstreamsMetrics", network, method)
+	return r.inner.GetNetworkUpstreamsMetrics(network, method)
+}
+
+func (r *Recorder) RecordUpstreamBehindHeadError(ups, network, method string, err error) bool {
+	r.record("RecordUpstreamBehindHeadError", ups, network, method, err)
+	return r.inner.RecordUpstreamBehindHeadError(ups, network, method, err)
+}
+
+func (r *Recorder) RecordConsensusComparison(c health.ConsensusComparison) {
+	r.record("RecordConsensusComparison", c)
+	r.inner.RecordConsensusComparison(c)
+}
+
+func (r *Recorder) RecordUpstreamMismatch(ups, network, method string) {
+	r.record("RecordUpstreamMismatch", ups, network, method)
+	r.inner.RecordUpstreamMismatch(ups, network, method)
+}
+
+func (r *Recorder) RecordUpstreamPartialResponse(ups, network, method string) {
+	r.record("RecordUpstreamPartialResponse", ups, network, method)
+	r.inner.RecordUpstreamPartialResponse(ups, network, method)
+}
+
+func (r *Recorder) RecordSelection(ups, network, method string) {
+	r.record("RecordSelection", ups, network, method)
+	r.inner.RecordSelection(ups, network, method)
+}
+
+func (r *Recorder) RecordBroadcastAccepted(ups, network, txHash string) {
+	r.record("RecordBroadcastAccepted", ups, network, txHash)
+	r.inner.RecordBroadcastAccepted(ups, network, txHash)
+}
+
+func (r *Recorder) RecordUpstreamChainId(ups, network string, chainId int64) {
+	r.record("RecordUpstreamChainId", ups, network, chainId)
+	r.inner.RecordUpstreamChainId(ups, network, chainId)
+}
+
+func (r *Recorder) SetExpectedChainId(network string, chainId int64) {
+	r.record("SetExpectedChainId", network, chainId)
+	r.inner.SetExpectedChainId(network, chainId)
+}
+
+func (r *Recorder) SetEligibilityFromCordons(network string) {
+	r.record("SetEligibilityFromCordons", network)
+	r.inner.SetEligibilityFromCordons(network)
+}
+
+func (r *Recorder) InCooldown(ups, network, method string, d time.Duration) bool {
+	r.record("InCooldown", ups, network, method, d)
+	return r.inner.InCooldown(ups, network, method, d)
+}
+
+func (r *Recorder) MalformedResponseSamples(ups string) []health.MalformedSample {
+	r.record("MalformedResponseSamples", ups)
+	return r.inner.MalformedResponseSamples(ups)
+}
+
+func (r *Recorder) SelectionView(ups, network, method string) health.SelectionView {
+	r.record("SelectionView", ups, network, method)
+	return r.inner.SelectionView(ups, network, method)
+}
+
+func (r *Recorder) ClassSelectionView(ups, network string, class health.MethodClass) health.SelectionView {
+	r.record("ClassSelectionView", ups, network, class)
+	return r.inner.ClassSelectionView(ups, network, class)
+}
+
+func (r *Recorder) HealthSummary(ups, network string) (float64, time.Duration, int64, bool) {
+	r.record("HealthSummary", ups, network)
+	return r.inner.HealthSummary(ups, network)
+}
+
+func (r *Recorder) NormalizedLatencyScore(ups, network string) (float64, bool) {
+	r.record("NormalizedLatencyScore", ups, network)
+	return r.inner.NormalizedLatencyScore(ups, network)
+}
+
+func (r *Recorder) ScoresNormalizedLatency(network string) bool {
+	r.record("ScoresNormalizedLatency", network)
+	return r.inner.ScoresNormalizedLatency(network)
+}
+
+func (r *Recorder) SetLatencyCostClasses(network string, classes *health.LatencyCostClasses) error {
+	r.record("SetLatencyCostClasses", network, classes)
+	return r.inner.SetLatencyCostClasses(network, classes)
+}
+
+func (r *Recorder) SetFinalityDepth(network string, blocks int64) {
+	r.record("SetFinalityDepth", network, blocks)
+	r.inner.SetFinalityDepth(network, blocks)
+}
+
+func (r *Recorder) SetMethodImportance(network string, importance *health.MethodImportance) {
+	r.record("SetMethodImportance", network, importance)
+	r.inner.SetMethodImportance(network, importance)
+}
+
+func (r *Recorder) SetErrorRateCordon(network, method string, cfg *health.ErrorRateCordonConfig) error {
+	r.record("SetErrorRateCordon", network, method, cfg)
+	return r.inner.SetErrorRateCordon(network, method, cfg)
+}
+
+func (r *Recorder) MethodClassOf(method string) health.MethodClass {
+	return r.inner.MethodClassOf(method)
+}
+
+func (r *Recorder) NoDataBehavior() health.NoDataBehavior {
+	return r.inner.NoDataBehavior()
+}
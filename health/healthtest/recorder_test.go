@@ -0,0 +1,61 @@
+package healthtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/health"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorder(t *testing.T) {
+	t.Run("CapturesCallsInOrder", func(t *testing.T) {
+		r := NewRecorder(nil)
+		r.RecordUpstreamRequest("a", "evm:1", "eth_call")
+		r.RecordUpstreamFailure("a", "evm:1", "eth_call")
+		assert.True(t, r.IsMethodAllowed("a", "evm:1", "eth_call"))
+
+		calls := r.Calls()
+		assert.Len(t, calls, 3)
+		assert.Equal(t, Call{Method: "RecordUpstreamRequest", Args: []interface{}{"a", "evm:1", "eth_call"}}, calls[0])
+		assert.Equal(t, "RecordUpstreamFailure", calls[1].Method)
+		assert.Len(t, r.CallsTo("IsMethodAllowed"), 1)
+
+		r.Reset()
+		assert.Empty(t, r.Calls())
+	})
+
+	t.Run("TimersReportBackToRecorder", func(t *testing.T) {
+		clock := NewFakeClock(time.Unix(1700000000, 0))
+		r := NewRecorder(nil)
+		r.SetClock(clock)
+
+		timer := r.RecordUpstreamDurationStart("a", "evm:1", "eth_call", "")
+		timer.SetFinality(common.DataFinalityStateFinalized)
+		clock.Advance(150 * time.Millisecond)
+		timer.ObserveDuration()
+
+		hedge := r.RecordUpstreamHedgeStart("b", "evm:1", "eth_call")
+		clock.Advance(20 * time.Millisecond)
+		hedge.ObserveHedgeCancelled()
+
+		durations := r.CallsTo("RecordUpstreamFinalityDuration")
+		assert.Len(t, durations, 1)
+		assert.Equal(t, []interface{}{"a", "evm:1", "eth_call", 150 * time.Millisecond, "none", common.DataFinalityStateFinalized}, durations[0].Args)
+
+		cancelled := r.CallsTo("RecordUpstreamHedgeCancelled")
+		assert.Len(t, cancelled, 1)
+		assert.Equal(t, 20*time.Millisecond, cancelled[0].Args[3])
+	})
+
+	t.Run("ForwardsToInnerTracker", func(t *testing.T) {
+		inner := health.NewTracker(&log.Logger, "test-project", time.Minute)
+		r := NewRecorder(inner)
+		r.Cordon("a", "evm:1", "eth_call", "test")
+
+		assert.True(t, inner.IsCordoned("a", "evm:1", "eth_call"))
+		assert.True(t, r.IsCordoned("a", "evm:1", "eth_call"))
+	})
+}
@@ -0,0 +1,64 @@
+package health
+
+import (
+	"time"
+
+	"github.com/erpc/erpc/telemetry"
+)
+
+// CompositeTypeHedge is the composite type used for timers started by RecordUpstreamHedgeStart.
+const CompositeTypeHedge = "hedge"
+
+// RecordUpstreamHedgeStart counts a hedge launched towards an upstream and returns a timer
+// that must be closed with either ObserveHedgeWon or ObserveHedgeCancelled. The request of the
+// hedge counts in flight through its own timer (see RecordUpstreamDurationStart), not this one.
+// Hedges reported through RecordRequestOutcome are already counted, they must not be started here
+// too.
+func (t *Tracker) RecordUpstreamHedgeStart(ups, network, method string) *Timer {
+	t.recordHedgeLaunched(ups, network, method)
+	return NewTimer(t, t.clock, ups, t.canonicalNetwork(network), t.normalizeMethod(method), CompositeTypeHedge)
+}
+
+func (t *Tracker) recordHedgeLaunched(ups, network, method string) {
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
+	for _, k := range t.getKeys(ups, network, method) {
+		t.getMetrics(k).HedgesLaunchedTotal.Add(1)
+	}
+}
+
+// RecordUpstreamHedgeWon records that a hedge served the request.
+func (t *Tracker) RecordUpstreamHedgeWon(ups, network, method string) {
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
+	for _, k := range t.getKeys(ups, network, method) {
+		t.getMetrics(k).HedgesWonTotal.Add(1)
+	}
+	telemetry.MetricUpstreamHedgeOutcomeTotal.WithLabelValues(t.projectId, network, ups, t.upstreamVendor(ups, network), method, "won").Inc()
+}
+
+// RecordUpstreamHedgeCancelled records that a hedge lost the race, counting the time spent
+// until cancellation as wasted upstream capacity.
+func (t *Tracker) RecordUpstreamHedgeCancelled(ups, network, method string, wasted time.Duration) {
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
+	for _, k := range t.getKeys(ups, network, method) {
+		m := t.getMetrics(k)
+		m.HedgesCancelledTotal.Add(1)
+		m.HedgeWastedDurationTotal.Add(int64(wasted))
+	}
+	telemetry.MetricUpstreamHedgeOutcomeTotal.WithLabelValues(t.projectId, network, ups, t.upstreamVendor(ups, network), method, "cancelled").Inc()
+	telemetry.MetricUpstreamHedgeWastedSecondsTotal.WithLabelValues(t.projectId, network, ups, t.upstreamVendor(ups, network), method).Add(wasted.Seconds())
+}
+
+// ObserveHedgeWon records that the hedge served the request.
+func (t *Timer) ObserveHedgeWon() {
+	t.endInFlight()
+	t.tracker.RecordUpstreamHedgeWon(t.ups, t.network, t.method)
+}
+
+// ObserveHedgeCancelled records that the hedge lost the race.
+func (t *Timer) ObserveHedgeCancelled() {
+	t.endInFlight()
+	t.tracker.RecordUpstreamHedgeCancelled(t.ups, t.network, t.method, t.clock.Now().Sub(t.start))
+}
@@ -0,0 +1,40 @@
+package health_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health"
+	"github.com/erpc/erpc/health/healthtest"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHedgeOutcomes(t *testing.T) {
+	clock := healthtest.NewFakeClock(time.Unix(1700000000, 0))
+	tracker := health.NewTracker(&log.Logger, "test-project", time.Minute)
+	tracker.SetClock(clock)
+
+	won := tracker.RecordUpstreamHedgeStart("a", "evm:1", "eth_call")
+	won.ObserveHedgeWon()
+
+	for i := 0; i < 3; i++ {
+		lost := tracker.RecordUpstreamHedgeStart("a", "evm:1", "eth_call")
+		clock.Advance(5 * time.Millisecond)
+		lost.ObserveHedgeCancelled()
+	}
+
+	m := tracker.GetUpstreamMethodMetrics("a", "evm:1", "eth_call")
+	assert.Equal(t, int64(4), m.HedgesLaunchedTotal.Load())
+	assert.Equal(t, int64(1), m.HedgesWonTotal.Load())
+	assert.Equal(t, int64(3), m.HedgesCancelledTotal.Load())
+	assert.Equal(t, 0.75, m.HedgeWasteRatio())
+	assert.Equal(t, 15*time.Millisecond, time.Duration(m.HedgeWastedDurationTotal.Load()))
+
+	ntw := tracker.GetNetworkMethodMetrics("evm:1", "eth_call")
+	assert.Equal(t, int64(3), ntw.HedgesCancelledTotal.Load())
+
+	m.Reset()
+	assert.Equal(t, float64(0), m.HedgeWasteRatio())
+	assert.Equal(t, int64(0), m.HedgeWastedDurationTotal.Load())
+}
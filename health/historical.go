@@ -0,0 +1,82 @@
+package health
+
+// DefaultHistoricalLagMargin is the number of blocks below the head of an upstream from which
+// requested data counts as historical unless SetHistoricalLagMargin says otherwise.
+const DefaultHistoricalLagMargin = 128
+
+// Block tags of RequestBlock, the ones not listed here being read at the head.
+const (
+	BlockTagEarliest  = "earliest"
+	BlockTagFinalized = "finalized"
+)
+
+// RequestBlock is the block context of a request, resolved from its block parameters, e.g. by
+// evm.ExtractRequestBlock.
+type RequestBlock struct {
+	// Number is the highest block number the request reads, zero when it reads none.
+	Number int64
+	// Tag is the most recent block tag the request reads, e.g. "latest" or "finalized" for an
+	// eth_getLogs range ending at that tag. Empty when it reads none.
+	Tag string
+	// Unknown is set when some block parameter cannot be resolved to a number, e.g. a block hash.
+	Unknown bool
+}
+
+// IsZero tells whether the request reads no block at all, e.g. for methods without block parameters.
+func (b RequestBlock) IsZero() bool {
+	return b.Number == 0 && b.Tag == "" && !b.Unknown
+}
+
+// SetHistoricalLagMargin sets how many blocks below the head of an upstream requested data must be
+// for IsHistoricalFor to tell the block head lag of the upstream does not matter for it. Zero means
+// the default, a negative margin makes every request depend on the lag.
+func (t *Tracker) SetHistoricalLagMargin(blocks int64) {
+	if blocks < 0 {
+		blocks = -1
+	}
+	t.historicalLagMargin.Store(blocks)
+}
+
+func (t *Tracker) historicalMargin() (int64, bool) {
+	switch margin := t.historicalLagMargin.Load(); {
+	case margin == 0:
+		return DefaultHistoricalLagMargin, true
+	case margin < 0:
+		return 0, false
+	default:
+		return margin, true
+	}
+}
+
+// IsHistoricalFor tells whether the data a request reads is older than the head of an upstream
+// minus the margin of SetHistoricalLagMargin, in which case the block head lag of the upstream
+// says nothing about its ability to serve it and selection ignores it. Requests reading the head
+// (latest, pending, safe...) or blocks which cannot be resolved are never historical, the
+// finalized tag resolves to the finalized block of the network.
+func (t *Tracker) IsHistoricalFor(ups, network string, block RequestBlock) bool {
+	margin, ok := t.historicalMargin()
+	if !ok || block.Unknown || block.IsZero() {
+		return false
+	}
+	network = t.canonicalNetwork(network)
+
+	number := block.Number
+	switch block.Tag {
+	case "", BlockTagEarliest:
+	case BlockTagFinalized:
+		finalized := t.GetFinalizedBlockNumber("*", network)
+		if finalized <= 0 {
+			return false
+		}
+		number = max(number, finalized)
+	default:
+		return false
+	}
+
+	val, ok := t.metadata.Load(duoKey{ups, network})
+	if !ok {
+		return false
+	}
+	head := val.(*NetworkMetadata).evmLatestBlockNumber.Load()
+	return head > 0 && number <= head-margin
+}
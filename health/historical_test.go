@@ -0,0 +1,52 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsHistoricalFor(t *testing.T) {
+	networkID := "evm:123"
+	tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+	tracker.SetLatestBlockNumber("synced", networkID, 10_000)
+	tracker.SetLatestBlockNumber("lagging", networkID, 9_500)
+	tracker.SetFinalizedBlockNumber("synced", networkID, 9_000)
+
+	tests := []struct {
+		name            string
+		block           RequestBlock
+		synced, lagging bool
+	}{
+		{"no block", RequestBlock{}, false, false},
+		{"latest", RequestBlock{Tag: "latest"}, false, false},
+		{"pending", RequestBlock{Tag: "pending"}, false, false},
+		{"old number", RequestBlock{Number: 1_000}, true, true},
+		{"within the margin of the lagging head", RequestBlock{Number: 9_400}, true, false},
+		{"above the lagging head", RequestBlock{Number: 9_800}, true, false},
+		{"within the margin of the synced head", RequestBlock{Number: 9_900}, false, false},
+		{"finalized", RequestBlock{Tag: BlockTagFinalized}, true, true},
+		{"range up to finalized", RequestBlock{Number: 9_450, Tag: BlockTagFinalized}, true, false},
+		{"range up to latest", RequestBlock{Number: 1_000, Tag: "latest"}, false, false},
+		{"earliest", RequestBlock{Tag: BlockTagEarliest}, true, true},
+		{"block hash", RequestBlock{Number: 1_000, Unknown: true}, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.synced, tracker.IsHistoricalFor("synced", networkID, tt.block))
+			assert.Equal(t, tt.lagging, tracker.IsHistoricalFor("lagging", networkID, tt.block))
+		})
+	}
+
+	// Without a known head nothing is historical
+	assert.False(t, tracker.IsHistoricalFor("unknown", networkID, RequestBlock{Number: 1}))
+
+	tracker.SetHistoricalLagMargin(1_000)
+	assert.False(t, tracker.IsHistoricalFor("lagging", networkID, RequestBlock{Number: 9_000}))
+	assert.True(t, tracker.IsHistoricalFor("lagging", networkID, RequestBlock{Number: 8_500}))
+
+	tracker.SetHistoricalLagMargin(-1)
+	assert.False(t, tracker.IsHistoricalFor("lagging", networkID, RequestBlock{Number: 1}))
+}
@@ -0,0 +1,80 @@
+package health
+
+// MethodImportance weighs the methods of a network in WeightedErrorRate, e.g. to make an outage of
+// eth_sendRawTransaction count despite its low volume next to eth_call.
+type MethodImportance struct {
+	// Weights of methods, methods not listed weigh 1 and a zero weight leaves a method out.
+	Weights map[string]float64
+	// MinSamples is the number of requests a method needs within the window to count, so that
+	// a single failure of a rarely used method does not dominate the rate.
+	MinSamples int64
+}
+
+// SetMethodImportance configures how WeightedErrorRate weighs the methods of a network, nil
+// removes it so that every method weighs the same.
+func (t *Tracker) SetMethodImportance(network string, importance *MethodImportance) {
+	network = t.canonicalNetwork(network)
+	if importance == nil {
+		t.methodImportances.Delete(network)
+		return
+	}
+	weights := make(map[string]float64, len(importance.Weights))
+	for method, w := range importance.Weights {
+		weights[method] = max(w, 0)
+	}
+	t.methodImportances.Store(network, &MethodImportance{Weights: weights, MinSamples: importance.MinSamples})
+}
+
+func (t *Tracker) methodImportance(network string) *MethodImportance {
+	if val, ok := t.methodImportances.Load(network); ok {
+		return val.(*MethodImportance)
+	}
+	return &MethodImportance{}
+}
+
+// WeightedErrorRate is the error rate of an upstream on a network (or of the network with "*")
+// averaged over its methods weighted by their importance instead of their volume, see
+// SetMethodImportance. It is computed from the windowed per-method keys when read.
+func (t *Tracker) WeightedErrorRate(ups, network string) float64 {
+	network = t.canonicalNetwork(network)
+	return t.weightedErrorRates(network)[ups]
+}
+
+// weightedErrorRates computes WeightedErrorRate of every upstream of a network, and of the
+// network under "*", in a single pass over the keys.
+func (t *Tracker) weightedErrorRates(network string) map[string]float64 {
+	importance := t.methodImportance(network)
+	type sums struct{ rate, weight float64 }
+	perUpstream := make(map[string]*sums)
+	t.metrics.Range(func(key, value any) bool {
+		k := key.(tripletKey)
+		if k.network != network || isAggregateMethod(k.method) {
+			return true
+		}
+		m := value.(*TrackedMetrics)
+		requests := m.RequestsTotal.Load()
+		if requests == 0 || requests < importance.MinSamples {
+			return true
+		}
+		w, ok := importance.Weights[k.method]
+		if !ok {
+			w = 1
+		}
+		if w == 0 {
+			return true
+		}
+		s := perUpstream[k.ups]
+		if s == nil {
+			s = &sums{}
+			perUpstream[k.ups] = s
+		}
+		s.rate += w * m.ErrorRate()
+		s.weight += w
+		return true
+	})
+	result := make(map[string]float64, len(perUpstream))
+	for ups, s := range perUpstream {
+		result[ups] = s.rate / s.weight
+	}
+	return result
+}
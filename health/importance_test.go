@@ -0,0 +1,61 @@
+package health_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWeightedErrorRate(t *testing.T) {
+	networkID := "evm:123"
+
+	seed := func(tracker *health.Tracker) {
+		recordRequests(tracker, networkID, "a", "eth_call", 1000, 0)
+		recordRequests(tracker, networkID, "a", "eth_sendRawTransaction", 10, 10)
+		recordRequests(tracker, networkID, "a", "eth_chainId", 2, 2)
+		recordRequests(tracker, networkID, "b", "eth_call", 100, 10)
+	}
+
+	t.Run("WeighsMethodsByImportance", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		seed(tracker)
+		tracker.SetMethodImportance(networkID, &health.MethodImportance{
+			Weights:    map[string]float64{"eth_sendRawTransaction": 3, "eth_chainId": 0},
+			MinSamples: 5,
+		})
+
+		// The volume-weighted rate barely moves while the send outage weighs 3 against eth_call
+		assert.Less(t, tracker.GetUpstreamMethodMetrics("a", networkID, "*").ErrorRate(), 0.02)
+		assert.InDelta(t, (1*0.0+3*1.0)/(1+3), tracker.WeightedErrorRate("a", networkID), 1e-9)
+		assert.InDelta(t, 0.1, tracker.WeightedErrorRate("b", networkID), 1e-9)
+		assert.InDelta(t, (1*(10.0/1100)+3*1.0)/(1+3), tracker.WeightedErrorRate("*", networkID), 1e-9)
+		assert.Equal(t, 0.0, tracker.WeightedErrorRate("c", networkID))
+	})
+
+	t.Run("SkipsMethodsBelowMinSamples", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		seed(tracker)
+		tracker.SetMethodImportance(networkID, &health.MethodImportance{MinSamples: 5})
+		assert.InDelta(t, (0.0+1.0)/2, tracker.WeightedErrorRate("a", networkID), 1e-9)
+
+		tracker.SetMethodImportance(networkID, nil)
+		assert.InDelta(t, (0.0+1.0+1.0)/3, tracker.WeightedErrorRate("a", networkID), 1e-9)
+	})
+
+	t.Run("ExposedInSnapshots", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		seed(tracker)
+		tracker.SetMethodImportance(networkID, &health.MethodImportance{MinSamples: 5})
+
+		all := tracker.GetNetworkUpstreamsMetrics(networkID, "*")
+		require.Contains(t, all, "a")
+		assert.InDelta(t, 0.5, all["a"].WeightedErrorRate, 1e-9)
+		assert.InDelta(t, 12.0/1012, all["a"].ErrorRate, 1e-9)
+
+		exact := tracker.GetNetworkUpstreamsMetrics(networkID, "eth_call")
+		assert.Equal(t, exact["b"].ErrorRate, exact["b"].WeightedErrorRate)
+	})
+}
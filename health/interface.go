@@ -0,0 +1,96 @@
+package health
+
+import (
+	"time"
+
+	"github.com/erpc/erpc/common"
+)
+
+// MetricsTracker is the subset of the tracker used by upstreams, networks and selection
+// policies. *Tracker is the production implementation, NewNoopTracker returns one that
+// discards everything and healthtest.Recorder captures calls for assertions.
+type MetricsTracker interface {
+	RecordUpstreamRequest(ups, network, method string)
+	RecordUpstreamDurationStart(ups, network, method string, compositeType string) *Timer
+	RecordUpstreamDuration(ups, network, method string, duration time.Duration, compositeType string)
+	RecordUpstreamDurationWithQuantiles(ups, network, method string, duration time.Duration, compositeType string) (p50, p99 float64)
+	RecordUpstreamFinalityDuration(ups, network, method string, duration time.Duration, compositeType string, finality common.DataFinalityState)
+	RecordUpstreamFailure(ups, network, method string)
+	RecordUpstreamSelfRateLimited(ups, network, method string)
+	RecordUpstreamRemoteRateLimited(ups, network, method string)
+	RecordUpstreamHedgeStart(ups, network, method string) *Timer
+	RecordUpstreamHedgeWon(ups, network, method string)
+	RecordUpstreamHedgeCancelled(ups, network, method string, wasted time.Duration)
+	RecordOutcome(ups, network, method string, o Outcome)
+	RecordUpstreamCancelled(ups, network, method string, cause CancelCause, elapsed time.Duration)
+	RecordRequestOutcome(chain []AttemptResult)
+	Clock() Clock
+	RecordUpstreamReconnect(ups, network string)
+	RecordUpstreamHandshake(ups, network string, success bool, d time.Duration)
+	RecordUpstreamProbe(ups, network string, success bool)
+	RecordUpstreamMalformedResponse(ups, network, method string, kind string)
+	RecordUpstreamMalformedSample(ups, network, method, kind string, payload []byte)
+	RecordUpstreamBehindHeadError(ups, network, method string, err error) bool
+	RecordConsensusComparison(c ConsensusComparison)
+	RecordUpstreamMismatch(ups, network, method string)
+	RecordUpstreamPartialResponse(ups, network, method string)
+	RecordSelection(ups, network, method string)
+	RecordBroadcastAccepted(ups, network, txHash string)
+	RecordUpstreamChainId(ups, network string, chainId int64)
+	SetExpectedChainId(network string, chainId int64)
+	RecordUpstreamTraceSample(rec RequestRecord)
+	RecordBlockHeadLargeRollback(ups, network, finality string, currentVal, newVal int64)
+	RecordServedBlock(ups, network, method string, block int64)
+	SetLatestBlockNumber(ups, network string, blockNumber int64)
+	SetFinalizedBlockNumber(ups, network string, blockNumber int64)
+	RecordBlockHash(ups, network string, number int64, hash string)
+	BeginReorg(network string)
+	EndReorg(network string)
+	SetUpstreamAttributes(ups, network string, attrs map[string]string)
+	RecordUpstreamClientVersion(ups, network, raw string)
+	RecordCertificateExpiry(ups, network string, notAfter time.Time, warningDays float64)
+	RecordCertificateProbeFailure(ups, network string, err error)
+	SetUpstreamShadow(ups string, shadow bool)
+	SetUpstreamGroup(ups, group string)
+	SetConcurrencyLimit(ups, network string, c int)
+	SetErrorBudgetConfig(ups, network string, cfg *ErrorBudgetConfig) error
+
+	Cordon(ups, network, method, reason string)
+	CordonWithInfo(ups, network, method string, info CordonInfo)
+	Uncordon(ups, network, method string)
+	UncordonWithSource(ups, network, method, source string) bool
+	IsCordoned(ups, network, method string) bool
+	InCooldown(ups, network, method string, d time.Duration) bool
+	IsMethodAllowed(ups, network, method string) bool
+	ShouldAdmit(ups, network, method string) bool
+	IsSaturated(ups, network string) bool
+	EffectiveWeight(ups, network string, healthWeight float64) float64
+	TrafficShareCap(ups, network string) float64
+	ReportIntendedShares(network string, weights map[string]float64)
+	IsHistoricalFor(ups, network string, block RequestBlock) bool
+	EligibleUpstreams(network string) int
+	SetEligibilityFromCordons(network string)
+	GetForkSignal(network string, verbose bool) ForkSignal
+	OrderTies(network, method string, tied []string)
+
+	GetUpstreamMethodMetrics(ups, network, method string) *TrackedMetrics
+	LookupUpstreamMethodMetrics(ups, network, method string) (*TrackedMetrics, bool)
+	GetUpstreamMetrics(upsId string) map[string]*TrackedMetrics
+	GetUpstreamMetricsSnapshot(upsId string) map[string]*TrackedMetricsSnapshot
+	GetNetworkMethodMetrics(network, method string) *TrackedMetrics
+	GetNetworkUpstreamsMetrics(network, method string) map[string]*TrackedMetricsSnapshot
+	MalformedResponseSamples(ups string) []MalformedSample
+	SelectionView(ups, network, method string) SelectionView
+	ClassSelectionView(ups, network string, class MethodClass) SelectionView
+	HealthSummary(ups, network string) (errRate float64, p90 time.Duration, lag int64, cordoned bool)
+	NormalizedLatencyScore(ups, network string) (float64, bool)
+	ScoresNormalizedLatency(network string) bool
+	SetLatencyCostClasses(network string, classes *LatencyCostClasses) error
+	SetFinalityDepth(network string, blocks int64)
+	SetMethodImportance(network string, importance *MethodImportance)
+	SetErrorRateCordon(network, method string, cfg *ErrorRateCordonConfig) error
+	MethodClassOf(method string) MethodClass
+	NoDataBehavior() NoDataBehavior
+}
+
+var _ MetricsTracker = (*Tracker)(nil)
@@ -0,0 +1,156 @@
+// Package fakeclock implements the fake clock exposed as healthtest.FakeClock. It does not depend on
+// the health package so that the tracker's own tests can use it.
+package fakeclock
+
+import (
+	"sync"
+	"time"
+)
+
+// FakeClock is a health.Clock whose time only moves when Advance is called.
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*fakeTicker
+	waiters []*fakeWaiter
+	added   *sync.Cond
+}
+
+type fakeTicker struct {
+	clock    *FakeClock
+	c        chan time.Time
+	done     chan struct{}
+	interval time.Duration
+	next     time.Time
+	stopped  bool
+}
+
+type fakeWaiter struct {
+	c  chan time.Time
+	at time.Time
+}
+
+// New creates a fake clock starting at the given time.
+func New(start time.Time) *FakeClock {
+	c := &FakeClock{now: start}
+	c.added = sync.NewCond(&c.mu)
+	return c
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) NewTicker(d time.Duration) interface {
+	C() <-chan time.Time
+	Stop()
+} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTicker{
+		clock:    c,
+		c:        make(chan time.Time),
+		done:     make(chan struct{}),
+		interval: d,
+		next:     c.now.Add(d),
+	}
+	c.tickers = append(c.tickers, t)
+	c.added.Broadcast()
+	return t
+}
+
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w := &fakeWaiter{c: make(chan time.Time, 1), at: c.now.Add(d)}
+	if d <= 0 {
+		w.c <- c.now
+		return w.c
+	}
+	c.waiters = append(c.waiters, w)
+	c.added.Broadcast()
+	return w.c
+}
+
+// WaitForTickers blocks until at least n tickers have been created, so that Advance
+// is not called before a background loop started listening.
+func (c *FakeClock) WaitForTickers(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.tickers) < n {
+		c.added.Wait()
+	}
+}
+
+// Advance moves the clock forward and fires every tick and timer that became due, in order.
+// Each tick is delivered synchronously, i.e. Advance returns only after every due tick was
+// received by its listener (or the ticker was stopped); work triggered by the tick may still be
+// in progress when Advance returns.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	target := c.now.Add(d)
+	c.mu.Unlock()
+
+	for {
+		c.mu.Lock()
+		t, at := c.nextTickLocked(target)
+		if t == nil {
+			c.now = target
+			c.fireWaitersLocked()
+			c.mu.Unlock()
+			return
+		}
+		c.now = at
+		c.fireWaitersLocked()
+		t.next = at.Add(t.interval)
+		c.mu.Unlock()
+
+		select {
+		case t.c <- at:
+		case <-t.done:
+		}
+	}
+}
+
+func (c *FakeClock) nextTickLocked(target time.Time) (*fakeTicker, time.Time) {
+	var due *fakeTicker
+	for _, t := range c.tickers {
+		if t.stopped || t.interval <= 0 || t.next.After(target) {
+			continue
+		}
+		if due == nil || t.next.Before(due.next) {
+			due = t
+		}
+	}
+	if due == nil {
+		return nil, time.Time{}
+	}
+	return due, due.next
+}
+
+func (c *FakeClock) fireWaitersLocked() {
+	remaining := c.waiters[:0]
+	for _, w := range c.waiters {
+		if !w.at.After(c.now) {
+			w.c <- c.now
+		} else {
+			remaining = append(remaining, w)
+		}
+	}
+	c.waiters = remaining
+}
+
+func (t *fakeTicker) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTicker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	if !t.stopped {
+		t.stopped = true
+		close(t.done)
+	}
+}
@@ -0,0 +1,62 @@
+// Package metricstest reads the metrics registered by the tracker back from the default
+// prometheus registry. It does not depend on the health package so that the tracker's own tests
+// can use it.
+package metricstest
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+type Sample struct {
+	Labels map[string]string
+	Value  float64
+}
+
+// Samples returns every series of a registered metric whose labels include the given ones.
+func Samples(t testing.TB, name string, labels map[string]string) []Sample {
+	t.Helper()
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+
+	var samples []Sample
+	for _, f := range families {
+		if f.GetName() != name {
+			continue
+		}
+	metrics:
+		for _, m := range f.GetMetric() {
+			s := Sample{Labels: make(map[string]string, len(m.GetLabel()))}
+			for _, l := range m.GetLabel() {
+				s.Labels[l.GetName()] = l.GetValue()
+			}
+			for k, v := range labels {
+				if s.Labels[k] != v {
+					continue metrics
+				}
+			}
+			if c := m.GetCounter(); c != nil {
+				s.Value = c.GetValue()
+			} else if g := m.GetGauge(); g != nil {
+				s.Value = g.GetValue()
+			} else if h := m.GetHistogram(); h != nil {
+				s.Value = float64(h.GetSampleCount())
+			}
+			samples = append(samples, s)
+		}
+	}
+	return samples
+}
+
+// Value sums the counter or gauge values, or histogram sample counts, of the matching series,
+// zero when there is none.
+func Value(t testing.TB, name string, labels map[string]string) float64 {
+	t.Helper()
+	var sum float64
+	for _, s := range Samples(t, name, labels) {
+		sum += s.Value
+	}
+	return sum
+}
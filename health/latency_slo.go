@@ -0,0 +1,64 @@
+package health
+
+import (
+	"fmt"
+	"time"
+)
+
+type latencySLO struct {
+	percentile float64
+	target     time.Duration
+}
+
+// SetLatencySLO defines a serving-latency SLO for a method, e.g. p99 under 500ms. At each window
+// reset every upstream key of the method with traffic counts as a compliant or violated window.
+// Use "*" to evaluate the upstream-wide keys. A non-positive target removes the SLO.
+func (t *Tracker) SetLatencySLO(method string, percentile float64, target time.Duration) error {
+	method = t.normalizeMethod(method)
+	if target <= 0 {
+		t.latencySLOs.Delete(method)
+		return nil
+	}
+	if percentile <= 0 || percentile >= 1 {
+		return fmt.Errorf("latency slo percentile for method %s must be within (0, 1), got %v", method, percentile)
+	}
+	t.latencySLOs.Store(method, &latencySLO{percentile: percentile, target: target})
+	return nil
+}
+
+// SLOComplianceRate returns the ratio of windows that met the latency SLO of the key and whether
+// any window was evaluated yet. Unlike most metrics it spans every window since the tracker started.
+func (m *TrackedMetrics) SLOComplianceRate() (float64, bool) {
+	compliant := m.SLOCompliantWindows.Load()
+	total := compliant + m.SLOViolatedWindows.Load()
+	if total == 0 {
+		return 0, false
+	}
+	return float64(compliant) / float64(total), true
+}
+
+// rollLatencySLOs evaluates the closing window against latency SLOs, it must run right before
+// metrics are reset.
+func (t *Tracker) rollLatencySLOs() {
+	t.metrics.Range(func(key, value any) bool {
+		k := key.(tripletKey)
+		if k.ups == "*" {
+			return true
+		}
+		val, ok := t.latencySLOs.Load(k.method)
+		if !ok {
+			return true
+		}
+		slo := val.(*latencySLO)
+		tm := value.(*TrackedMetrics)
+		if !tm.ResponseQuantiles.HasSamples() {
+			return true
+		}
+		if tm.ResponseQuantiles.GetQuantile(slo.percentile) <= slo.target {
+			tm.SLOCompliantWindows.Add(1)
+		} else {
+			tm.SLOViolatedWindows.Add(1)
+		}
+		return true
+	})
+}
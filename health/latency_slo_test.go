@@ -0,0 +1,68 @@
+package health_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health/healthtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatencySLO(t *testing.T) {
+	networkID := "evm:123"
+
+	t.Run("CountsCompliantAndViolatedWindows", func(t *testing.T) {
+		tracker, clock := newFakeClockTracker(t, time.Minute)
+		assert.NoError(t, tracker.SetLatencySLO("eth_call", 0.99, 500*time.Millisecond))
+
+		m := tracker.GetUpstreamMethodMetrics("a", networkID, "eth_call")
+		for _, d := range []time.Duration{100, 200, 900, 300} {
+			recordRequests(tracker, networkID, "a", "eth_call", 1, 0)
+			for i := 0; i < 100; i++ {
+				tracker.RecordUpstreamDuration("a", networkID, "eth_call", d*time.Millisecond, "none")
+			}
+			// Another method without SLO is left alone
+			tracker.RecordUpstreamDuration("a", networkID, "eth_getLogs", time.Second, "none")
+			healthtest.AdvanceWindow(t, clock, time.Minute, m)
+		}
+
+		assert.Equal(t, int64(3), m.SLOCompliantWindows.Load())
+		assert.Equal(t, int64(1), m.SLOViolatedWindows.Load())
+		rate, ok := m.SLOComplianceRate()
+		assert.True(t, ok)
+		assert.Equal(t, 0.75, rate)
+
+		other := tracker.GetUpstreamMethodMetrics("a", networkID, "eth_getLogs")
+		assert.Equal(t, int64(0), other.SLOCompliantWindows.Load()+other.SLOViolatedWindows.Load())
+		network := tracker.GetNetworkMethodMetrics(networkID, "eth_call")
+		_, ok = network.SLOComplianceRate()
+		assert.False(t, ok)
+	})
+
+	t.Run("IdleWindowsAreNotEvaluated", func(t *testing.T) {
+		tracker, clock := newFakeClockTracker(t, time.Minute)
+		assert.NoError(t, tracker.SetLatencySLO("*", 0.5, 100*time.Millisecond))
+
+		m := tracker.GetUpstreamMethodMetrics("a", networkID, "*")
+		recordRequests(tracker, networkID, "a", "eth_call", 1, 0)
+		tracker.RecordUpstreamDuration("a", networkID, "eth_call", 200*time.Millisecond, "none")
+		healthtest.AdvanceWindow(t, clock, time.Minute, m)
+
+		recordRequests(tracker, networkID, "a", "eth_call", 1, 0)
+		healthtest.AdvanceWindow(t, clock, time.Minute, m)
+
+		assert.Equal(t, int64(1), m.SLOViolatedWindows.Load())
+		assert.Equal(t, int64(0), m.SLOCompliantWindows.Load())
+		rate, ok := m.SLOComplianceRate()
+		assert.True(t, ok)
+		assert.Equal(t, float64(0), rate)
+	})
+	t.Run("RejectsPercentilesOutsideUnitInterval", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		for _, p := range []float64{0, 1, -0.5, 99} {
+			assert.Error(t, tracker.SetLatencySLO("eth_call", p, 500*time.Millisecond), "percentile %v", p)
+		}
+		// Removing an SLO does not need a percentile
+		assert.NoError(t, tracker.SetLatencySLO("eth_call", 0, 0))
+	})
+}
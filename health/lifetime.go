@@ -0,0 +1,32 @@
+package health
+
+import "sync/atomic"
+
+// LifetimeTotals are counters of a key since it started being tracked. Unlike their windowed
+// counterparts they are never reset, so that consumers of snapshots can compute rates of their
+// own out of them.
+type LifetimeTotals struct {
+	RequestsTotal          int64 `json:"requestsTotal"`
+	ErrorsTotal            int64 `json:"errorsTotal"`
+	SelfRateLimitedTotal   int64 `json:"selfRateLimitedTotal"`
+	RemoteRateLimitedTotal int64 `json:"remoteRateLimitedTotal"`
+}
+
+type lifetimeCounters struct {
+	requests          atomic.Int64
+	errors            atomic.Int64
+	selfRateLimited   atomic.Int64
+	remoteRateLimited atomic.Int64
+}
+
+// Lifetime returns the lifetime totals of the key, see LifetimeTotals.
+func (m *TrackedMetrics) Lifetime() LifetimeTotals {
+	// Numerators first, like readCounters
+	l := LifetimeTotals{
+		ErrorsTotal:            m.lifetime.errors.Load(),
+		SelfRateLimitedTotal:   m.lifetime.selfRateLimited.Load(),
+		RemoteRateLimitedTotal: m.lifetime.remoteRateLimited.Load(),
+	}
+	l.RequestsTotal = m.lifetime.requests.Load()
+	return l
+}
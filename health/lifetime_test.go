@@ -0,0 +1,48 @@
+package health_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health"
+	"github.com/erpc/erpc/health/healthtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLifetimeTotals(t *testing.T) {
+	networkID := "evm:123"
+	tracker, clock := newFakeClockTracker(t, time.Minute)
+	tracker.SetCancellationsAsErrors(health.CancelCauseDeadline)
+
+	recordRequests(tracker, networkID, "a", "eth_call", 10, 3)
+	tracker.RecordUpstreamSelfRateLimited("a", networkID, "eth_call")
+	tracker.RecordUpstreamRemoteRateLimited("a", networkID, "eth_call")
+	m := tracker.GetUpstreamMethodMetrics("a", networkID, "eth_call")
+	healthtest.AdvanceWindow(t, clock, time.Minute, m)
+
+	recordRequests(tracker, networkID, "a", "eth_call", 5, 1)
+	tracker.RecordUpstreamCancelled("a", networkID, "eth_call", health.CancelCauseDeadline, time.Second)
+
+	assert.Equal(t, int64(5), m.RequestsTotal.Load())
+	assert.Equal(t, health.LifetimeTotals{
+		RequestsTotal:          15,
+		ErrorsTotal:            5,
+		SelfRateLimitedTotal:   1,
+		RemoteRateLimitedTotal: 1,
+	}, m.Lifetime())
+	assert.Equal(t, int64(15), tracker.GetNetworkMethodMetrics(networkID, "*").Lifetime().RequestsTotal)
+	assert.Equal(t, int64(15), tracker.GetNetworkUpstreamsMetrics(networkID, "eth_call")["a"].Lifetime.RequestsTotal)
+
+	data, err := json.Marshal(m)
+	require.NoError(t, err)
+	var decoded struct {
+		RequestsTotal int64                 `json:"requestsTotal"`
+		Lifetime      health.LifetimeTotals `json:"lifetime"`
+	}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, int64(5), decoded.RequestsTotal)
+	assert.Equal(t, int64(15), decoded.Lifetime.RequestsTotal)
+	assert.Equal(t, int64(5), decoded.Lifetime.ErrorsTotal)
+}
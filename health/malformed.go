@@ -0,0 +1,179 @@
+package health
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/erpc/erpc/telemetry"
+)
+
+// Kinds of malformed upstream responses, see RecordUpstreamMalformedResponse. Any other kind is
+// recorded as MalformedOther to keep the cardinality bounded.
+const (
+	// MalformedInvalidJson is a response body that is not valid JSON, e.g. an HTML error page.
+	MalformedInvalidJson = "invalid-json"
+	// MalformedNotJsonRpc is valid JSON that is not a JSON-RPC response.
+	MalformedNotJsonRpc = "not-jsonrpc"
+	// MalformedIdMismatch is a JSON-RPC response whose id matches none of the requests sent.
+	MalformedIdMismatch = "id-mismatch"
+	// MalformedWrongContentType is a response served with a non-JSON content type.
+	MalformedWrongContentType = "wrong-content-type"
+	// MalformedOther is any kind not listed above.
+	MalformedOther = "other"
+)
+
+var malformedKinds = [...]string{
+	MalformedInvalidJson,
+	MalformedNotJsonRpc,
+	MalformedIdMismatch,
+	MalformedWrongContentType,
+	MalformedOther,
+}
+
+func malformedKindIndex(kind string) int {
+	for i, k := range malformedKinds {
+		if k == kind {
+			return i
+		}
+	}
+	return len(malformedKinds) - 1
+}
+
+const (
+	// malformedSamplesSize is the number of payload samples retained per upstream.
+	malformedSamplesSize = 8
+	// maxMalformedSamplePayload bounds the length of a retained payload prefix.
+	maxMalformedSamplePayload = 256
+)
+
+// secretLike matches long opaque tokens (API keys, auth tokens, hashes) redacted from samples.
+var secretLike = regexp.MustCompile(`[A-Za-z0-9_\-]{20,}`)
+
+// MalformedSample is the redacted prefix of a malformed response, retained for debugging.
+type MalformedSample struct {
+	Time    time.Time `json:"time"`
+	Network string    `json:"network"`
+	Method  string    `json:"method"`
+	Kind    string    `json:"kind"`
+	Payload string    `json:"payload"`
+}
+
+type malformedSampleRing struct {
+	mu   sync.Mutex
+	buf  [malformedSamplesSize]MalformedSample
+	next int
+	full bool
+}
+
+// MalformedResponses returns the malformed responses of a given kind recorded within the window.
+func (m *TrackedMetrics) MalformedResponses(kind string) int64 {
+	return m.malformedByKind[malformedKindIndex(kind)].Load()
+}
+
+func (m *TrackedMetrics) malformedResponsesByKind() map[string]int64 {
+	res := make(map[string]int64)
+	for i := range m.malformedByKind {
+		if n := m.malformedByKind[i].Load(); n > 0 {
+			res[malformedKinds[i]] = n
+		}
+	}
+	return res
+}
+
+// SetMalformedResponseCordonThreshold enables cordoning an upstream on a network once it returned
+// threshold malformed responses within the current window, as this usually indicates a broken
+// intermediary rather than a transient issue. Zero disables it.
+func (t *Tracker) SetMalformedResponseCordonThreshold(threshold int64) {
+	t.malformedCordonThreshold.Store(threshold)
+}
+
+// RecordUpstreamMalformedResponse counts a response of an upstream which could not be used as a
+// JSON-RPC response, see the Malformed* kinds.
+func (t *Tracker) RecordUpstreamMalformedResponse(ups, network, method string, kind string) {
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
+	i := malformedKindIndex(kind)
+	for _, k := range t.getKeys(ups, network, method) {
+		m := t.getMetrics(k)
+		m.MalformedResponsesTotal.Add(1)
+		m.malformedByKind[i].Add(1)
+	}
+	telemetry.MetricUpstreamMalformedResponseTotal.WithLabelValues(t.projectId, network, ups, t.upstreamVendor(ups, network), method, malformedKinds[i]).Inc()
+
+	threshold := t.malformedCordonThreshold.Load()
+	if threshold <= 0 {
+		return
+	}
+	total := t.getMetrics(tripletKey{ups, network, "*"}).MalformedResponsesTotal.Load()
+	if total >= threshold {
+		t.autoCordon(ups, network, "*", fmt.Sprintf("returned %d malformed responses within window (threshold %d)", total, threshold))
+	}
+}
+
+// RecordUpstreamMalformedSample retains the prefix of a malformed response of an upstream, with
+// anything looking like a secret redacted. Only the last few samples of each upstream are kept,
+// across window resets.
+func (t *Tracker) RecordUpstreamMalformedSample(ups, network, method, kind string, payload []byte) {
+	s := MalformedSample{
+		Time:    t.clock.Now(),
+		Network: t.canonicalNetwork(network),
+		Method:  t.normalizeMethod(method),
+		Kind:    malformedKinds[malformedKindIndex(kind)],
+		Payload: redactMalformedPayload(payload),
+	}
+	val, ok := t.malformedSamples.Load(ups)
+	if !ok {
+		val, _ = t.malformedSamples.LoadOrStore(ups, &malformedSampleRing{})
+	}
+	r := val.(*malformedSampleRing)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.buf[r.next] = s
+	r.next++
+	if r.next == len(r.buf) {
+		r.next = 0
+		r.full = true
+	}
+}
+
+// MalformedResponseSamples returns the retained malformed response samples of an upstream,
+// newest first.
+func (t *Tracker) MalformedResponseSamples(ups string) []MalformedSample {
+	val, ok := t.malformedSamples.Load(ups)
+	if !ok {
+		return nil
+	}
+	r := val.(*malformedSampleRing)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	count := r.next
+	if r.full {
+		count = len(r.buf)
+	}
+	out := make([]MalformedSample, 0, count)
+	for i := 1; i <= count; i++ {
+		out = append(out, r.buf[(r.next-i+len(r.buf))%len(r.buf)])
+	}
+	return out
+}
+
+// redactMalformedPayload truncates payload to a printable prefix and masks secret-looking tokens.
+// Tokens are masked before truncating so that a secret cut at the end is not partially kept.
+func redactMalformedPayload(payload []byte) string {
+	if len(payload) > 2*maxMalformedSamplePayload {
+		payload = payload[:2*maxMalformedSamplePayload]
+	}
+	s := strings.Map(func(r rune) rune {
+		if r == utf8.RuneError || r < 0x20 || r == 0x7f {
+			return '.'
+		}
+		return r
+	}, string(payload))
+	s = secretLike.ReplaceAllString(s, "[redacted]")
+	return truncateString(s, maxMalformedSamplePayload)
+}
@@ -0,0 +1,89 @@
+package health
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health/internal/metricstest"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMalformedResponses(t *testing.T) {
+	networkID := "evm:123"
+
+	t.Run("CountedPerKind", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-malformed", time.Minute)
+		labels := map[string]string{"project": "test-malformed", "upstream": "a", "kind": MalformedInvalidJson}
+		before := metricstest.Value(t, "erpc_upstream_malformed_response_total", labels)
+
+		tracker.RecordUpstreamMalformedResponse("a", networkID, "eth_call", MalformedInvalidJson)
+		tracker.RecordUpstreamMalformedResponse("a", networkID, "eth_call", MalformedInvalidJson)
+		tracker.RecordUpstreamMalformedResponse("a", networkID, "eth_call", MalformedIdMismatch)
+		tracker.RecordUpstreamMalformedResponse("a", networkID, "eth_call", "something-else")
+
+		m := tracker.GetUpstreamMethodMetrics("a", networkID, "eth_call")
+		assert.Equal(t, int64(4), m.MalformedResponsesTotal.Load())
+		assert.Equal(t, int64(2), m.MalformedResponses(MalformedInvalidJson))
+		assert.Equal(t, int64(1), m.MalformedResponses(MalformedIdMismatch))
+		assert.Equal(t, int64(1), m.MalformedResponses(MalformedOther))
+		assert.Equal(t, before+2, metricstest.Value(t, "erpc_upstream_malformed_response_total", labels))
+
+		b, err := m.MarshalJSON()
+		require.NoError(t, err)
+		assert.Contains(t, string(b), `"invalid-json":2`)
+
+		m.Reset()
+		assert.Equal(t, int64(0), m.MalformedResponses(MalformedInvalidJson))
+	})
+
+	t.Run("CordonsAfterThreshold", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-malformed", time.Minute)
+		tracker.SetMalformedResponseCordonThreshold(3)
+		tracker.RecordUpstreamMalformedResponse("a", networkID, "eth_call", MalformedNotJsonRpc)
+		tracker.RecordUpstreamMalformedResponse("a", networkID, "eth_getLogs", MalformedWrongContentType)
+		assert.False(t, tracker.IsCordoned("a", networkID, "eth_call"))
+
+		tracker.RecordUpstreamMalformedResponse("a", networkID, "eth_call", MalformedNotJsonRpc)
+		assert.True(t, tracker.IsCordoned("a", networkID, "eth_call"))
+		info := tracker.GetCordonInfo("a", networkID, "*")
+		require.NotNil(t, info)
+		assert.Equal(t, CordonSourceTracker, info.Source)
+		assert.Contains(t, info.Reason, "3 malformed responses")
+	})
+
+	t.Run("SamplesAreRedactedAndBounded", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-malformed", time.Minute)
+		assert.Empty(t, tracker.MalformedResponseSamples("a"))
+
+		html := "<html>\n<body>502 Bad Gateway ?apikey=abcdefghijklmnopqrstuvwxyz0123</body>" + strings.Repeat("x", 1000)
+		tracker.RecordUpstreamMalformedSample("a", networkID, "eth_call", MalformedInvalidJson, []byte(html))
+		for i := 0; i < malformedSamplesSize; i++ {
+			tracker.RecordUpstreamMalformedSample("a", networkID, "eth_getLogs", MalformedIdMismatch, []byte("{}"))
+		}
+		tracker.RecordUpstreamMalformedSample("a", networkID, "eth_call", MalformedInvalidJson, []byte(html))
+
+		samples := tracker.MalformedResponseSamples("a")
+		require.Len(t, samples, malformedSamplesSize)
+		assert.Equal(t, "eth_call", samples[0].Method)
+		assert.Equal(t, MalformedInvalidJson, samples[0].Kind)
+		assert.Equal(t, "<html>.<body>502 Bad Gateway ?apikey=[redacted]</body>[redacted]", samples[0].Payload)
+		assert.NotContains(t, samples[0].Payload, "abcdefghij")
+		assert.LessOrEqual(t, len(samples[0].Payload), maxMalformedSamplePayload)
+		assert.Equal(t, "eth_getLogs", samples[1].Method)
+		assert.Empty(t, tracker.MalformedResponseSamples("b"))
+	})
+}
+
+func TestRedactMalformedPayload(t *testing.T) {
+	assert.Equal(t, "", redactMalformedPayload(nil))
+	assert.Equal(t, `{"jsonrpc":"2.0"}`, redactMalformedPayload([]byte(`{"jsonrpc":"2.0"}`)))
+	assert.Equal(t, "a.b", redactMalformedPayload([]byte("a\x00b")))
+	assert.Equal(t, "token [redacted] end", redactMalformedPayload([]byte("token 0x00112233445566778899aabbccddeeff end")))
+
+	long := redactMalformedPayload([]byte(strings.Repeat("é ", 400)))
+	assert.LessOrEqual(t, len(long), maxMalformedSamplePayload)
+	assert.True(t, strings.HasPrefix(long, "é é"))
+}
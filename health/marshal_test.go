@@ -0,0 +1,86 @@
+package health
+
+import (
+	"encoding/json"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalConsistency(t *testing.T) {
+	t.Run("RatesStayWithinBoundsWhileResetting", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		m := tracker.GetUpstreamMethodMetrics("a", "evm:1", "eth_call")
+
+		var wg sync.WaitGroup
+		done := make(chan struct{})
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				tracker.RecordUpstreamRequest("a", "evm:1", "eth_call")
+				tracker.RecordUpstreamFailure("a", "evm:1", "eth_call")
+				tracker.RecordUpstreamRemoteRateLimited("a", "evm:1", "eth_call")
+				runtime.Gosched()
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				m.Reset()
+				runtime.Gosched()
+			}
+		}()
+
+		for i := 0; i < 200; i++ {
+			b, err := m.MarshalJSON()
+			require.NoError(t, err)
+			var out struct {
+				ErrorsTotal   int64   `json:"errorsTotal"`
+				RequestsTotal int64   `json:"requestsTotal"`
+				ErrorRate     float64 `json:"errorRate"`
+				ThrottledRate float64 `json:"throttledRate"`
+			}
+			require.NoError(t, json.Unmarshal(b, &out))
+			// The rate is computed from the same reads as the counters next to it
+			assert.Equal(t, boundedRatio(out.ErrorsTotal, out.RequestsTotal), out.ErrorRate)
+			assert.GreaterOrEqual(t, out.ErrorRate, 0.0)
+			assert.LessOrEqual(t, out.ErrorRate, 1.0)
+			assert.GreaterOrEqual(t, out.ThrottledRate, 0.0)
+			assert.LessOrEqual(t, out.ThrottledRate, 1.0)
+			assert.LessOrEqual(t, m.ErrorRate(), 1.0)
+			assert.LessOrEqual(t, m.ThrottledRate(), 1.0)
+		}
+		close(done)
+		wg.Wait()
+	})
+
+	t.Run("TornCountersAreClamped", func(t *testing.T) {
+		m := NewTrackedMetrics()
+		// As left by a reader seeing errors from before a reset and requests from after it
+		m.ErrorsTotal.Store(5)
+		m.RemoteRateLimitedTotal.Store(3)
+		m.RequestsTotal.Store(2)
+		assert.Equal(t, 1.0, m.ErrorRate())
+		assert.Equal(t, 1.0, m.ThrottledRate())
+		assert.Equal(t, 1.0, m.UnavailableRate())
+
+		m.RequestsTotal.Store(0)
+		assert.Equal(t, 0.0, m.ErrorRate())
+	})
+}
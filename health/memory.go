@@ -0,0 +1,99 @@
+package health
+
+import (
+	"unsafe"
+
+	"github.com/DataDog/sketches-go/ddsketch"
+	"github.com/DataDog/sketches-go/ddsketch/store"
+)
+
+// sketchOverheadBytes approximates a sketch without bins: the sketch, its index mapping and its
+// two stores.
+const sketchOverheadBytes = 256
+
+// TrackerMemoryStats is an estimate of the memory held by the tracked keys, see MemoryStats.
+type TrackerMemoryStats struct {
+	Keys int `json:"keys"`
+	// ApproxBytes counts the metrics of every key along with the bins of their quantile sketches.
+	// Indexes, cordons and other per-upstream state are left out.
+	ApproxBytes        int64  `json:"approxBytes"`
+	SketchBytes        int64  `json:"sketchBytes"`
+	LargestMethod      string `json:"largestMethod,omitempty"`
+	LargestMethodBytes int64  `json:"largestMethodBytes,omitempty"`
+}
+
+// MemoryStats estimates the memory used by the tracked keys, to help deciding whether keys need
+// pruning or sketches a lower accuracy. The largest method is the one whose keys hold the most
+// sketch bytes, keys aggregating methods ("*" and method classes) are not attributed to any.
+func (t *Tracker) MemoryStats() TrackerMemoryStats {
+	var stats TrackerMemoryStats
+	perMethod := make(map[string]int64)
+	t.metrics.Range(func(key, value any) bool {
+		k := key.(tripletKey)
+		sketches := value.(*TrackedMetrics).sketchBytes()
+		stats.Keys++
+		stats.SketchBytes += sketches
+		stats.ApproxBytes += int64(unsafe.Sizeof(tripletKey{})+unsafe.Sizeof(TrackedMetrics{})) + sketches
+		if !isAggregateMethod(k.method) {
+			perMethod[k.method] += sketches
+		}
+		return true
+	})
+	for method, bytes := range perMethod {
+		if bytes > stats.LargestMethodBytes || (bytes == stats.LargestMethodBytes && method < stats.LargestMethod) {
+			stats.LargestMethod, stats.LargestMethodBytes = method, bytes
+		}
+	}
+	return stats
+}
+
+// sketchBytes sums the approximate size of every quantile tracker of the metrics.
+func (m *TrackedMetrics) sketchBytes() int64 {
+	total := m.ResponseQuantiles.approxBytes()
+	for i := range m.FinalityQuantiles {
+		total += m.FinalityQuantiles[i].Load().approxBytes()
+	}
+	for i := range m.AttemptQuantiles {
+		total += m.AttemptQuantiles[i].Load().approxBytes()
+	}
+	for i := range m.compositeQuantiles {
+		total += m.compositeQuantiles[i].Load().approxBytes()
+	}
+	return total + m.ttfbQuantiles.Load().approxBytes() + m.successQuantiles.Load().approxBytes() +
+		m.serverErrorQuantiles.Load().approxBytes()
+}
+
+// approxBytes is the approximate size of the sketches of the tracker, zero for a nil tracker.
+func (q *QuantileTracker) approxBytes() int64 {
+	if q == nil {
+		return 0
+	}
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.buckets == nil {
+		return sketchApproxBytes(q.sketch)
+	}
+	total := sketchApproxBytes(q.closed) + sketchApproxBytes(q.merged)
+	for _, b := range q.buckets {
+		total += sketchApproxBytes(b)
+	}
+	return total
+}
+
+// sketchApproxBytes counts a float64 per bin between the lowest and highest index of each store,
+// as dense stores allocate them.
+func sketchApproxBytes(s *ddsketch.DDSketch) int64 {
+	return sketchOverheadBytes + storeApproxBytes(s.GetPositiveValueStore()) + storeApproxBytes(s.GetNegativeValueStore())
+}
+
+func storeApproxBytes(s store.Store) int64 {
+	lowest, err := s.MinIndex()
+	if err != nil {
+		return 0
+	}
+	highest, err := s.MaxIndex()
+	if err != nil {
+		return 0
+	}
+	return int64(highest-lowest+1) * 8
+}
@@ -0,0 +1,70 @@
+package health_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStats(t *testing.T) {
+	networkID := "evm:123"
+
+	t.Run("CountsSeededKeys", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		assert.Equal(t, 0, tracker.MemoryStats().Keys)
+
+		for _, ups := range []string{"a", "b"} {
+			for _, method := range []string{"eth_call", "eth_getLogs"} {
+				tracker.RecordUpstreamRequest(ups, networkID, method)
+			}
+		}
+		// 4 exact keys, 2 per upstream wildcards, 2 upstream-wide, 2 network per method, 1 network
+		// wide and the read class aggregates of both upstreams and of the network
+		stats := tracker.MemoryStats()
+		assert.Equal(t, 4+2+2+2+1+3, stats.Keys)
+		assert.Greater(t, stats.ApproxBytes, stats.SketchBytes)
+		assert.Greater(t, stats.SketchBytes, int64(0))
+	})
+
+	t.Run("LargestMethodBySketchSize", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		tracker.RecordOutcome("a", networkID, "eth_call", health.Outcome{Kind: health.OutcomeSuccess, Duration: 10 * time.Millisecond})
+		before := tracker.MemoryStats()
+
+		// Latencies spread over several orders of magnitude take many more bins
+		for d := time.Millisecond; d < time.Minute; d = d * 11 / 10 {
+			tracker.RecordOutcome("a", networkID, "eth_getLogs", health.Outcome{Kind: health.OutcomeSuccess, Duration: d})
+		}
+		stats := tracker.MemoryStats()
+		assert.Equal(t, "eth_getLogs", stats.LargestMethod)
+		assert.Greater(t, stats.LargestMethodBytes, before.LargestMethodBytes)
+		assert.Greater(t, stats.ApproxBytes, before.ApproxBytes)
+	})
+}
+
+func TestLookupUpstreamMethodMetrics(t *testing.T) {
+	networkID := "evm:123"
+	tracker, _ := newFakeClockTracker(t, time.Minute)
+	tracker.RecordUpstreamRequest("a", networkID, "eth_call")
+	keys := tracker.MemoryStats().Keys
+
+	for _, ups := range []string{"a", "b", "*"} {
+		for _, method := range []string{"eth_getLogs", "eth_getBalance", "eth_chainId"} {
+			m, ok := tracker.LookupUpstreamMethodMetrics(ups, networkID, method)
+			assert.False(t, ok)
+			assert.Nil(t, m)
+		}
+		_, ok := tracker.LookupUpstreamMethodMetrics(ups, "evm:456", "*")
+		assert.False(t, ok)
+	}
+	assert.Equal(t, keys, tracker.MemoryStats().Keys)
+
+	// Tracked keys are found, through the same normalization as the other accessors
+	m, ok := tracker.LookupUpstreamMethodMetrics("a", "evm:0x7b", "eth_call")
+	assert.True(t, ok)
+	assert.Same(t, tracker.GetUpstreamMethodMetrics("a", networkID, "eth_call"), m)
+	assert.Equal(t, int64(1), m.RequestsTotal.Load())
+	assert.Equal(t, keys, tracker.MemoryStats().Keys)
+}
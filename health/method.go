@@ -0,0 +1,146 @@
+package health
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/telemetry"
+)
+
+// methodNormalizer maps the method names received from clients to the name used in tracker keys.
+type methodNormalizer struct {
+	aliases   map[string]string // exact alias -> canonical name
+	canonical map[string]struct{}
+	folded    map[string]string // lowercased name -> canonical name, only when unambiguous
+}
+
+// defaultCanonicalMethods are the method names known by the default cache policies.
+func defaultCanonicalMethods() []string {
+	var names []string
+	for _, methods := range []map[string]*common.CacheMethodConfig{
+		common.DefaultStaticCacheMethods,
+		common.DefaultRealtimeCacheMethods,
+		common.DefaultWithBlockCacheMethods,
+		common.DefaultSpecialCacheMethods,
+	} {
+		for name := range methods {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func newMethodNormalizer(aliases map[string]string, canonical []string) *methodNormalizer {
+	n := &methodNormalizer{
+		aliases:   make(map[string]string, len(aliases)),
+		canonical: make(map[string]struct{}),
+		folded:    make(map[string]string),
+	}
+	for alias, name := range aliases {
+		n.aliases[alias] = name
+		canonical = append(canonical, name)
+	}
+
+	ambiguous := make(map[string]bool)
+	for _, name := range canonical {
+		n.canonical[name] = struct{}{}
+		lower := strings.ToLower(name)
+		if existing, ok := n.folded[lower]; ok && existing != name {
+			// Two known methods only differ by case, folding would merge them
+			ambiguous[lower] = true
+		}
+		n.folded[lower] = name
+	}
+	for lower := range ambiguous {
+		delete(n.folded, lower)
+	}
+	return n
+}
+
+func (n *methodNormalizer) normalize(method string) string {
+	if method == "*" {
+		return method
+	}
+	if name, ok := n.aliases[method]; ok {
+		return name
+	}
+	if _, ok := n.canonical[method]; ok {
+		return method
+	}
+	// Only fold case for names matching a single known method, other methods might be case-sensitive
+	if name, ok := n.folded[strings.ToLower(method)]; ok {
+		return name
+	}
+	return method
+}
+
+// SetMethodAliases configures aliases resolved to a canonical method name in tracker keys,
+// accessors and telemetry labels, e.g. "parity_getBlockReceipts" -> "eth_getBlockReceipts".
+// Names differing only by case from a known method (default cache methods and alias targets)
+// are folded as well. Keys tracked under a name that is now normalized are dropped.
+func (t *Tracker) SetMethodAliases(aliases map[string]string) {
+	n := newMethodNormalizer(aliases, defaultCanonicalMethods())
+	t.methodNormalizer.Store(n)
+
+	t.metrics.Range(func(key, value any) bool {
+		k := key.(tripletKey)
+		if n.normalize(k.method) != k.method {
+			t.metrics.Delete(key)
+			t.unindexUpstreamMethod(k)
+		}
+		return true
+	})
+}
+
+// normalizeMethod resolves the name a method is tracked under, counting applied normalizations.
+func (t *Tracker) normalizeMethod(method string) string {
+	if method == methodClassReadsKey || method == methodClassWritesKey {
+		return escapedMethodPrefix + method
+	}
+	n := t.methodNormalizer.Load()
+	if n == nil {
+		return method
+	}
+	name := n.normalize(method)
+	if name != method {
+		// The received name is client controlled, only the (known) normalized name is used as a label
+		t.logger.Debug().Str("method", method).Str("normalized", name).Msg("normalized method name in health tracker")
+		telemetry.MetricUpstreamMethodNormalizedTotal.WithLabelValues(t.projectId, name).Inc()
+	}
+	return name
+}
+
+// MethodBatch is the method MethodFromRequest returns for batches of different methods.
+const MethodBatch = "batch"
+
+// MethodFromRequest extracts the method to record a raw JSON-RPC request under. A batch gets
+// the method of its requests when they all share it, MethodBatch otherwise. It returns false
+// for bodies which are not JSON-RPC requests, or batches with such a request or no request.
+func MethodFromRequest(body []byte) (string, bool) {
+	type request struct {
+		Method string `json:"method"`
+	}
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		var batch []request
+		if err := common.SonicCfg.Unmarshal(body, &batch); err != nil || len(batch) == 0 {
+			return "", false
+		}
+		method := batch[0].Method
+		for _, req := range batch {
+			if req.Method == "" {
+				return "", false
+			}
+			if req.Method != method {
+				method = MethodBatch
+			}
+		}
+		return method, true
+	}
+	var req request
+	if err := common.SonicCfg.Unmarshal(body, &req); err != nil || req.Method == "" {
+		return "", false
+	}
+	return req.Method, true
+}
@@ -0,0 +1,111 @@
+package health
+
+// MethodClass separates methods reading state from methods writing it (e.g. sending
+// transactions), whose failures have user visible consequences reads do not have.
+type MethodClass int
+
+const (
+	MethodClassRead MethodClass = iota
+	MethodClassWrite
+)
+
+// Pseudo-methods of the per-class aggregate keys, updated alongside the other expansions of every
+// recorded method. Received methods with these names are tracked under escapedMethodPrefix so
+// that they never land in the aggregates.
+const (
+	methodClassReadsKey  = "<reads>"
+	methodClassWritesKey = "<writes>"
+	escapedMethodPrefix  = "escaped:"
+)
+
+func (c MethodClass) String() string {
+	if c == MethodClassWrite {
+		return "write"
+	}
+	return "read"
+}
+
+func (c MethodClass) key() string {
+	if c == MethodClassWrite {
+		return methodClassWritesKey
+	}
+	return methodClassReadsKey
+}
+
+// isAggregateMethod tells if method is a key aggregating several methods rather than a method.
+func isAggregateMethod(method string) bool {
+	return method == "*" || method == methodClassReadsKey || method == methodClassWritesKey
+}
+
+// DefaultWriteMethods are the EVM methods classified as writes by default, the same as
+// evm.IsWriteMethod.
+var DefaultWriteMethods = []string{
+	"eth_sendRawTransaction",
+	"eth_sendTransaction",
+	"eth_createAccessList",
+	"eth_submitTransaction",
+	"eth_submitWork",
+	"eth_newFilter",
+	"eth_newBlockFilter",
+	"eth_newPendingTransactionFilter",
+}
+
+var defaultWriteMethodsSet = writeMethodsSet(DefaultWriteMethods)
+
+func writeMethodsSet(methods []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(methods))
+	for _, m := range methods {
+		set[m] = struct{}{}
+	}
+	return set
+}
+
+// SetWriteMethods replaces the methods classified as writes, every other method is a read. Nil
+// restores DefaultWriteMethods. Only recordings made afterwards follow the new classification.
+func (t *Tracker) SetWriteMethods(methods []string) {
+	if methods == nil {
+		t.writeMethods.Store(nil)
+		return
+	}
+	set := writeMethodsSet(methods)
+	t.writeMethods.Store(&set)
+}
+
+// MethodClassOf returns the class a method is aggregated in.
+func (t *Tracker) MethodClassOf(method string) MethodClass {
+	return t.methodClass(t.normalizeMethod(method))
+}
+
+// methodClass is MethodClassOf for a method already normalized.
+func (t *Tracker) methodClass(method string) MethodClass {
+	set := defaultWriteMethodsSet
+	if s := t.writeMethods.Load(); s != nil {
+		set = *s
+	}
+	if _, ok := set[method]; ok {
+		return MethodClassWrite
+	}
+	return MethodClassRead
+}
+
+// GetUpstreamClassMetrics returns the metrics of every method of a class for an upstream, or for
+// the network when ups is "*".
+func (t *Tracker) GetUpstreamClassMetrics(ups, network string, class MethodClass) *TrackedMetrics {
+	network = t.canonicalNetwork(network)
+	return t.getMetrics(tripletKey{ups, network, class.key()})
+}
+
+// ClassSelectionView is SelectionView over every method of a class, e.g. to hold upstreams to a
+// stricter bar for writes than for reads.
+func (t *Tracker) ClassSelectionView(ups, network string, class MethodClass) SelectionView {
+	network = t.canonicalNetwork(network)
+	return t.selectionViewOf(t.getMetrics(tripletKey{ups, network, class.key()}), ups, network, class.key())
+}
+
+// classViewIfTracked is ClassSelectionView without creating the key, network must be canonical.
+func (t *Tracker) classViewIfTracked(ups, network string, class MethodClass) SelectionView {
+	if val, ok := t.metrics.Load(tripletKey{ups, network, class.key()}); ok {
+		return t.selectionViewOf(val.(*TrackedMetrics), ups, network, class.key())
+	}
+	return t.withUpstreamCordon(SelectionView{}, ups, network, class.key())
+}
@@ -0,0 +1,72 @@
+package health_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMethodClasses(t *testing.T) {
+	networkID := "evm:123"
+
+	t.Run("AggregatesReadsAndWritesSeparately", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		recordRequests(tracker, networkID, "a", "eth_call", 100, 1)
+		recordRequests(tracker, networkID, "a", "eth_getLogs", 50, 0)
+		recordRequests(tracker, networkID, "a", "eth_sendRawTransaction", 4, 2)
+		recordRequests(tracker, networkID, "b", "eth_sendRawTransaction", 6, 0)
+
+		reads := tracker.GetUpstreamClassMetrics("a", networkID, health.MethodClassRead)
+		writes := tracker.GetUpstreamClassMetrics("a", networkID, health.MethodClassWrite)
+		assert.Equal(t, int64(150), reads.RequestsTotal.Load())
+		assert.Equal(t, int64(1), reads.ErrorsTotal.Load())
+		assert.Equal(t, int64(4), writes.RequestsTotal.Load())
+		assert.Equal(t, 0.5, tracker.ClassSelectionView("a", networkID, health.MethodClassWrite).ErrorRate)
+		assert.Equal(t, int64(10), tracker.GetUpstreamClassMetrics("*", networkID, health.MethodClassWrite).RequestsTotal.Load())
+		// The existing expansions are unchanged
+		assert.Equal(t, int64(154), tracker.GetUpstreamMethodMetrics("a", networkID, "*").RequestsTotal.Load())
+	})
+
+	t.Run("ConfigurableClassification", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		assert.Equal(t, health.MethodClassWrite, tracker.MethodClassOf("eth_sendRawTransaction"))
+		assert.Equal(t, health.MethodClassRead, tracker.MethodClassOf("eth_call"))
+
+		tracker.SetWriteMethods([]string{"eth_sendRawTransactionConditional"})
+		assert.Equal(t, health.MethodClassRead, tracker.MethodClassOf("eth_sendRawTransaction"))
+		recordRequests(tracker, networkID, "a", "eth_sendRawTransactionConditional", 3, 0)
+		assert.Equal(t, int64(3), tracker.GetUpstreamClassMetrics("a", networkID, health.MethodClassWrite).RequestsTotal.Load())
+
+		tracker.SetWriteMethods(nil)
+		assert.Equal(t, health.MethodClassWrite, tracker.MethodClassOf("eth_sendRawTransaction"))
+	})
+
+	t.Run("ReservedNamesDoNotCollide", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		recordRequests(tracker, networkID, "a", "<writes>", 5, 5)
+
+		assert.Equal(t, int64(0), tracker.GetUpstreamClassMetrics("a", networkID, health.MethodClassWrite).RequestsTotal.Load())
+		assert.Equal(t, int64(5), tracker.GetUpstreamClassMetrics("a", networkID, health.MethodClassRead).ErrorsTotal.Load())
+		assert.Equal(t, int64(5), tracker.GetUpstreamMethodMetrics("a", networkID, "<writes>").RequestsTotal.Load())
+		top := tracker.TopByMetric(health.MetricErrorRate, 0)
+		require.Len(t, top, 1)
+		assert.NotEqual(t, "<writes>", top[0].Method)
+	})
+
+	t.Run("SurfacedInSnapshots", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		recordRequests(tracker, networkID, "a", "eth_call", 10, 0)
+		recordRequests(tracker, networkID, "a", "eth_sendRawTransaction", 2, 1)
+		recordRequests(tracker, networkID, "b", "eth_call", 10, 0)
+
+		all := tracker.GetNetworkUpstreamsMetrics(networkID, "*")
+		require.Contains(t, all, "a")
+		assert.Equal(t, int64(10), all["a"].Reads.RequestsTotal)
+		assert.Equal(t, 0.5, all["a"].Writes.ErrorRate)
+		assert.Equal(t, int64(0), all["b"].Writes.RequestsTotal)
+		assert.Equal(t, 0.0, all["a"].Reads.ErrorRate)
+	})
+}
@@ -0,0 +1,115 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health/internal/metricstest"
+	"github.com/erpc/erpc/telemetry"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMethodNormalization(t *testing.T) {
+	telemetry.SetHistogramBuckets("0.05,0.5,5,30")
+
+	t.Run("WithoutConfigurationMethodsAreKeptAsIs", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		tracker.RecordUpstreamRequest("a", "evm:1", "eth_Call")
+
+		assert.Equal(t, int64(1), tracker.GetUpstreamMethodMetrics("a", "evm:1", "eth_Call").RequestsTotal.Load())
+		assert.Equal(t, int64(0), tracker.GetUpstreamMethodMetrics("a", "evm:1", "eth_call").RequestsTotal.Load())
+	})
+
+	t.Run("AliasesAndCaseFolding", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		tracker.SetMethodAliases(map[string]string{"parity_getBlockReceipts": "eth_getBlockReceipts"})
+
+		tracker.RecordUpstreamRequest("a", "evm:1", "eth_call")
+		tracker.RecordUpstreamRequest("a", "evm:1", "eth_Call")
+		tracker.RecordUpstreamFailure("a", "evm:1", "ETH_CALL")
+		tracker.RecordUpstreamRequest("a", "evm:1", "parity_getBlockReceipts")
+		tracker.RecordUpstreamDuration("a", "evm:1", "Eth_GetBlockReceipts", 100*time.Millisecond, "none")
+		// Unknown methods are never case-folded
+		tracker.RecordUpstreamRequest("a", "evm:1", "custom_Method")
+
+		m := tracker.GetUpstreamMethodMetrics("a", "evm:1", "eth_Call")
+		assert.Equal(t, int64(2), m.RequestsTotal.Load())
+		assert.Equal(t, int64(1), m.ErrorsTotal.Load())
+
+		receipts := tracker.GetNetworkMethodMetrics("evm:1", "parity_getBlockReceipts")
+		assert.Equal(t, int64(1), receipts.RequestsTotal.Load())
+		assert.True(t, receipts.ResponseQuantiles.HasSamples())
+
+		assert.Equal(t, int64(1), tracker.GetUpstreamMethodMetrics("a", "evm:1", "custom_Method").RequestsTotal.Load())
+		assert.Equal(t, int64(0), tracker.GetUpstreamMethodMetrics("a", "evm:1", "custom_method").RequestsTotal.Load())
+
+		tracker.Cordon("a", "evm:1", "ETH_call", "test")
+		assert.True(t, tracker.IsCordoned("a", "evm:1", "eth_call"))
+
+		for key := range tracker.GetUpstreamMetricsSnapshot("a") {
+			assert.NotContains(t, []string{"evm:1|eth_Call", "evm:1|ETH_CALL", "evm:1|parity_getBlockReceipts"}, key)
+		}
+	})
+
+	t.Run("NormalizedCounterOnlyLabelsTheResultingName", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "method-label-project", time.Minute)
+		tracker.SetMethodAliases(nil)
+		labels := map[string]string{"project": "method-label-project", "normalized": "eth_call"}
+		before := metricstest.Value(t, "erpc_upstream_method_normalized_total", labels)
+
+		tracker.RecordUpstreamRequest("a", "evm:1", "ETH_Call")
+		tracker.RecordUpstreamRequest("a", "evm:1", "eth_CALL")
+
+		assert.Equal(t, before+2, metricstest.Value(t, "erpc_upstream_method_normalized_total", labels))
+		for _, sample := range metricstest.Samples(t, "erpc_upstream_method_normalized_total", labels) {
+			assert.Equal(t, labels, sample.Labels)
+		}
+	})
+
+	t.Run("StaleKeysAreDroppedOnReconfiguration", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		tracker.RecordUpstreamRequest("a", "evm:1", "eth_Call")
+		tracker.RecordUpstreamRequest("a", "evm:1", "eth_call")
+
+		tracker.SetMethodAliases(nil)
+
+		_, stale := tracker.metrics.Load(tripletKey{"a", "evm:1", "eth_Call"})
+		assert.False(t, stale)
+		assert.Equal(t, int64(1), tracker.GetUpstreamMethodMetrics("a", "evm:1", "eth_call").RequestsTotal.Load())
+	})
+
+	t.Run("AmbiguousNamesAreNotFolded", func(t *testing.T) {
+		n := newMethodNormalizer(map[string]string{"x_A": "custom_foo", "x_B": "custom_Foo"}, nil)
+		assert.Equal(t, "custom_foo", n.normalize("x_A"))
+		assert.Equal(t, "CUSTOM_FOO", n.normalize("CUSTOM_FOO"))
+		assert.Equal(t, "*", n.normalize("*"))
+	})
+}
+
+func TestMethodFromRequest(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		body   string
+		method string
+		ok     bool
+	}{
+		{"Single", `{"jsonrpc":"2.0","id":1,"method":"eth_call","params":[]}`, "eth_call", true},
+		{"SingleWithWhitespace", " \n{\"method\":\"eth_chainId\"}\n", "eth_chainId", true},
+		{"BatchOfOneMethod", `[{"id":1,"method":"eth_getBalance"},{"id":2,"method":"eth_getBalance"}]`, "eth_getBalance", true},
+		{"BatchOfSeveralMethods", `[{"id":1,"method":"eth_getBalance"},{"id":2,"method":"eth_call"}]`, MethodBatch, true},
+		{"EmptyBatch", `[]`, "", false},
+		{"BatchWithoutMethod", `[{"id":1,"method":"eth_call"},{"id":2}]`, "", false},
+		{"WithoutMethod", `{"jsonrpc":"2.0","id":1}`, "", false},
+		{"NonStringMethod", `{"method":42}`, "", false},
+		{"Truncated", `{"method":"eth_ca`, "", false},
+		{"Empty", ``, "", false},
+		{"NotJson", `eth_call`, "", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			method, ok := MethodFromRequest([]byte(tc.body))
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.method, method)
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package health
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/erpc/erpc/telemetry"
+)
+
+// CordonReasonDataMismatch is the reason of cordons applied by SetMismatchCordon.
+const CordonReasonDataMismatch = "DataMismatch"
+
+type mismatchCordon struct {
+	maxRate     float64
+	minRequests int64
+}
+
+// MismatchRate is MismatchesTotal over RequestsTotal.
+func (m *TrackedMetrics) MismatchRate() float64 {
+	mismatches := m.MismatchesTotal.Load()
+	return boundedRatio(mismatches, m.RequestsTotal.Load())
+}
+
+// SetMismatchCordon enables cordoning an upstream for a method once more than maxRate of at least
+// minRequests requests within the current window were found mismatching, with reason
+// CordonReasonDataMismatch. A zero maxRate disables it.
+func (t *Tracker) SetMismatchCordon(maxRate float64, minRequests int64) {
+	if maxRate <= 0 {
+		t.mismatchCordon.Store(nil)
+		return
+	}
+	t.mismatchCordon.Store(&mismatchCordon{maxRate: maxRate, minRequests: max(minRequests, 1)})
+}
+
+// RecordUpstreamMismatch records that a response of the upstream was the outlier when
+// cross-checked against other upstreams, e.g. a different block hash or receipt. Unlike
+// RecordConsensusComparison the caller tells which upstream is wrong, so it also applies to a
+// comparison of two upstreams against a reference.
+func (t *Tracker) RecordUpstreamMismatch(ups, network, method string) {
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
+	for _, k := range t.getKeys(ups, network, method) {
+		t.getMetrics(k).MismatchesTotal.Add(1)
+	}
+	telemetry.MetricUpstreamMismatchTotal.WithLabelValues(t.projectId, network, ups, t.upstreamVendor(ups, network), method).Inc()
+	t.evaluateMismatchCordon(ups, network, method)
+}
+
+// evaluateMismatchCordon cordons (ups, network, method) once its mismatch rate exceeds the
+// threshold set by SetMismatchCordon.
+func (t *Tracker) evaluateMismatchCordon(ups, network, method string) {
+	cfg := t.mismatchCordon.Load()
+	if cfg == nil {
+		return
+	}
+	m := t.getMetrics(tripletKey{ups, network, method})
+	mismatches := m.MismatchesTotal.Load()
+	requests := m.RequestsTotal.Load()
+	if requests < cfg.minRequests {
+		return
+	}
+	rate := boundedRatio(mismatches, requests)
+	if rate <= cfg.maxRate {
+		return
+	}
+	t.autoCordonWithInfo(ups, network, method, CordonInfo{
+		Reason:    CordonReasonDataMismatch,
+		Detail:    fmt.Sprintf("%d mismatches out of %d requests (threshold %.2f)", mismatches, requests, cfg.maxRate),
+		Source:    CordonSourceTracker,
+		ExpiresAt: time.Unix(0, t.windowStart.Load()).Add(t.windowSize),
+	})
+}
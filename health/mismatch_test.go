@@ -0,0 +1,55 @@
+package health_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpstreamMismatches(t *testing.T) {
+	networkID := "evm:123"
+
+	t.Run("TracksRate", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		recordRequests(tracker, networkID, "a", "eth_getBlockByNumber", 10, 0)
+		recordRequests(tracker, networkID, "b", "eth_getBlockByNumber", 10, 0)
+		tracker.RecordUpstreamMismatch("a", networkID, "eth_getBlockByNumber")
+		tracker.RecordUpstreamMismatch("a", networkID, "eth_getBlockByNumber")
+
+		m := tracker.GetUpstreamMethodMetrics("a", networkID, "eth_getBlockByNumber")
+		assert.Equal(t, int64(2), m.MismatchesTotal.Load())
+		assert.InDelta(t, 0.2, m.MismatchRate(), 1e-9)
+		assert.InDelta(t, 0.2, tracker.GetUpstreamMethodMetrics("a", networkID, "*").MismatchRate(), 1e-9)
+		assert.InDelta(t, 0.1, tracker.GetNetworkMethodMetrics(networkID, "eth_getBlockByNumber").MismatchRate(), 1e-9)
+		assert.Zero(t, tracker.GetUpstreamMethodMetrics("b", networkID, "eth_getBlockByNumber").MismatchRate())
+		assert.InDelta(t, 0.2, tracker.GetNetworkUpstreamsMetrics(networkID, "eth_getBlockByNumber")["a"].MismatchRate, 1e-9)
+		assert.False(t, tracker.IsCordoned("a", networkID, "eth_getBlockByNumber"), "no cordon unless configured")
+
+		b, err := m.MarshalJSON()
+		require.NoError(t, err)
+		assert.Contains(t, string(b), `"mismatchRate":0.2`)
+	})
+
+	t.Run("CordonsConsistentOutlier", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		tracker.SetMismatchCordon(0.2, 5)
+
+		recordRequests(tracker, networkID, "a", "eth_getTransactionReceipt", 4, 0)
+		tracker.RecordUpstreamMismatch("a", networkID, "eth_getTransactionReceipt")
+		tracker.RecordUpstreamMismatch("a", networkID, "eth_getTransactionReceipt")
+		assert.False(t, tracker.IsCordoned("a", networkID, "eth_getTransactionReceipt"), "below the minimum requests")
+
+		recordRequests(tracker, networkID, "a", "eth_getTransactionReceipt", 6, 0)
+		tracker.RecordUpstreamMismatch("a", networkID, "eth_getTransactionReceipt")
+		assert.True(t, tracker.IsCordoned("a", networkID, "eth_getTransactionReceipt"))
+		assert.False(t, tracker.IsCordoned("a", networkID, "eth_call"))
+
+		info := tracker.GetCordonInfo("a", networkID, "eth_getTransactionReceipt")
+		require.NotNil(t, info)
+		assert.Equal(t, health.CordonReasonDataMismatch, info.Reason)
+		assert.Contains(t, info.Detail, "3 mismatches out of 10 requests")
+	})
+}
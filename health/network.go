@@ -0,0 +1,97 @@
+package health
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/erpc/erpc/telemetry"
+)
+
+// NetworkCanonicalizer maps a network identifier received by the tracker to the identifier used
+// in tracker keys and telemetry labels. It must be safe for concurrent use and return the input
+// unchanged when it is already canonical.
+type NetworkCanonicalizer func(network string) string
+
+// CanonicalEvmNetworkId rewrites "evm:<chain id>" identifiers with a hex or zero-padded chain id
+// to "evm:<decimal chain id>", e.g. "evm:0x89" -> "evm:137". Other identifiers are returned as-is.
+func CanonicalEvmNetworkId(network string) string {
+	chainId, ok := strings.CutPrefix(network, "evm:")
+	if !ok || chainId == "" || isCanonicalDecimal(chainId) {
+		return network
+	}
+	var id uint64
+	var err error
+	if strings.HasPrefix(chainId, "0x") || strings.HasPrefix(chainId, "0X") {
+		id, err = strconv.ParseUint(chainId[2:], 16, 64)
+	} else {
+		id, err = strconv.ParseUint(chainId, 10, 64)
+	}
+	if err != nil {
+		return network
+	}
+	return "evm:" + strconv.FormatUint(id, 10)
+}
+
+// isCanonicalDecimal avoids parsing and re-formatting ids that are already canonical on the hot path.
+func isCanonicalDecimal(s string) bool {
+	if s[0] == '0' {
+		return len(s) == 1
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// SetNetworkCanonicalizer replaces the function used to canonicalize network arguments, nil
+// restores the default which resolves aliases (see SetNetworkAliases) then applies
+// CanonicalEvmNetworkId.
+func (t *Tracker) SetNetworkCanonicalizer(fn NetworkCanonicalizer) {
+	if fn == nil {
+		t.networkCanonicalizer.Store(nil)
+		return
+	}
+	t.networkCanonicalizer.Store(&fn)
+}
+
+// SetNetworkAliases configures aliases resolved by the default canonicalizer, e.g.
+// "eth-mainnet" -> "evm:1". Targets are canonicalized as well.
+func (t *Tracker) SetNetworkAliases(aliases map[string]string) {
+	resolved := make(map[string]string, len(aliases))
+	for alias, network := range aliases {
+		resolved[alias] = CanonicalEvmNetworkId(network)
+	}
+	t.networkAliases.Store(&resolved)
+}
+
+func (t *Tracker) defaultCanonicalNetwork(network string) string {
+	if aliases := t.networkAliases.Load(); aliases != nil {
+		if resolved, ok := (*aliases)[network]; ok {
+			return resolved
+		}
+	}
+	return CanonicalEvmNetworkId(network)
+}
+
+// canonicalNetwork resolves the identifier a network is tracked under. Non-canonical inputs are
+// counted, and logged once per distinct value so that the caller sending them can be fixed.
+func (t *Tracker) canonicalNetwork(network string) string {
+	if network == "*" || network == "" {
+		return network
+	}
+	var canonical string
+	if fn := t.networkCanonicalizer.Load(); fn != nil {
+		canonical = (*fn)(network)
+	} else {
+		canonical = t.defaultCanonicalNetwork(network)
+	}
+	if canonical != network {
+		telemetry.MetricNetworkNonCanonicalTotal.WithLabelValues(t.projectId, canonical).Inc()
+		if _, seen := t.nonCanonicalSeen.LoadOrStore(network, struct{}{}); !seen {
+			t.logger.Warn().Str("network", network).Str("canonical", canonical).Msg("health tracker received a non-canonical network identifier")
+		}
+	}
+	return canonical
+}
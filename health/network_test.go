@@ -0,0 +1,76 @@
+package health
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health/internal/metricstest"
+	"github.com/erpc/erpc/telemetry"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalEvmNetworkId(t *testing.T) {
+	cases := map[string]string{
+		"evm:1":       "evm:1",
+		"evm:0x1":     "evm:1",
+		"evm:0X89":    "evm:137",
+		"evm:0137":    "evm:137",
+		"evm:":        "evm:",
+		"evm:foo":     "evm:foo",
+		"eth-mainnet": "eth-mainnet",
+		"*":           "*",
+	}
+	for in, expected := range cases {
+		assert.Equal(t, expected, CanonicalEvmNetworkId(in), in)
+	}
+}
+
+func TestNetworkCanonicalization(t *testing.T) {
+	telemetry.SetHistogramBuckets("0.05,0.5,5,30")
+
+	t.Run("AliasesAndHexChainIdsResolveToCanonicalKey", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		tracker.SetNetworkAliases(map[string]string{"polygon": "evm:0x89"})
+
+		before := nonCanonicalCount(t, "evm:137")
+		tracker.RecordUpstreamRequest("a", "evm:137", "eth_call")
+		tracker.RecordUpstreamRequest("a", "evm:0x89", "eth_call")
+		tracker.RecordUpstreamFailure("a", "polygon", "eth_call")
+		tracker.SetLatestBlockNumber("a", "polygon", 100)
+		assert.Equal(t, float64(3), nonCanonicalCount(t, "evm:137")-before)
+
+		for _, network := range []string{"evm:137", "evm:0x89", "polygon"} {
+			m := tracker.GetUpstreamMethodMetrics("a", network, "eth_call")
+			assert.Equal(t, int64(2), m.RequestsTotal.Load(), network)
+			assert.Equal(t, int64(1), m.ErrorsTotal.Load(), network)
+		}
+
+		tracker.Cordon("a", "polygon", "*", "test")
+		assert.True(t, tracker.IsCordoned("a", "evm:137", "eth_call"))
+
+		for key := range tracker.GetUpstreamMetricsSnapshot("a") {
+			assert.False(t, strings.HasPrefix(key, "polygon") || strings.HasPrefix(key, "evm:0x89"), key)
+		}
+	})
+
+	t.Run("CustomCanonicalizer", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		tracker.SetNetworkCanonicalizer(strings.ToLower)
+
+		tracker.RecordUpstreamRequest("a", "SOLANA:Mainnet", "getSlot")
+		assert.Equal(t, int64(1), tracker.GetNetworkMethodMetrics("solana:mainnet", "getSlot").RequestsTotal.Load())
+		// The EVM default is replaced, hex chain ids are kept as-is
+		tracker.RecordUpstreamRequest("a", "evm:0x1", "eth_call")
+		assert.Equal(t, int64(0), tracker.GetNetworkMethodMetrics("evm:1", "eth_call").RequestsTotal.Load())
+
+		tracker.SetNetworkCanonicalizer(nil)
+		tracker.RecordUpstreamRequest("a", "evm:0x1", "eth_call")
+		assert.Equal(t, int64(1), tracker.GetNetworkMethodMetrics("evm:1", "eth_call").RequestsTotal.Load())
+	})
+}
+
+func nonCanonicalCount(t *testing.T, network string) float64 {
+	return metricstest.Value(t, "erpc_network_non_canonical_total", map[string]string{"project": "test-project", "network": network})
+}
@@ -0,0 +1,42 @@
+package health
+
+import (
+	"math"
+)
+
+// NoDataBehavior controls what latency accessors report for keys without any samples.
+type NoDataBehavior int32
+
+const (
+	// NoDataZero reports zero latency for idle keys (legacy behavior).
+	NoDataZero NoDataBehavior = iota
+	// NoDataNaN reports NaN so callers can treat idle keys as unknown rather than fast.
+	NoDataNaN
+)
+
+// SetNoDataBehavior configures the sentinel returned by GetLatencyQuantile for keys without samples.
+// With NoDataNaN upstream selection also ranks idle upstreams as the slowest ones instead of the fastest.
+func (t *Tracker) SetNoDataBehavior(b NoDataBehavior) {
+	t.noDataBehavior.Store(int32(b))
+}
+
+// NoDataBehavior returns the configured behavior for keys without samples.
+func (t *Tracker) NoDataBehavior() NoDataBehavior {
+	return NoDataBehavior(t.noDataBehavior.Load())
+}
+
+// GetLatencyQuantile returns the requested quantile in seconds for (ups, network, method) and
+// whether the key has any samples. Without samples the value is the configured sentinel.
+func (t *Tracker) GetLatencyQuantile(ups, network, method string, qtile float64) (float64, bool) {
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
+	// Reading must not create the key of an upstream never used for the method
+	val, ok := t.metrics.Load(tripletKey{ups, network, method})
+	if !ok || !val.(*TrackedMetrics).ResponseQuantiles.HasSamples() {
+		if t.NoDataBehavior() == NoDataNaN {
+			return math.NaN(), false
+		}
+		return 0, false
+	}
+	return val.(*TrackedMetrics).ResponseQuantiles.GetQuantile(qtile).Seconds(), true
+}
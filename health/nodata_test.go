@@ -0,0 +1,52 @@
+package health
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoDataQuantiles(t *testing.T) {
+	t.Run("HasSamples", func(t *testing.T) {
+		qt := NewQuantileTracker()
+		assert.False(t, qt.HasSamples())
+		qt.Add(0.1)
+		assert.True(t, qt.HasSamples())
+		qt.Reset()
+		assert.False(t, qt.HasSamples())
+	})
+
+	t.Run("IdleKeyReportsZeroByDefault", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		tracker.RecordUpstreamRequest("a", "evm:1", "eth_call")
+
+		v, ok := tracker.GetLatencyQuantile("a", "evm:1", "eth_call", 0.90)
+		assert.False(t, ok)
+		assert.Equal(t, float64(0), v)
+	})
+
+	t.Run("IdleKeyReportsNoDataWithNaN", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		tracker.SetNoDataBehavior(NoDataNaN)
+
+		v, ok := tracker.GetLatencyQuantile("a", "evm:1", "eth_call", 0.90)
+		assert.False(t, ok)
+		assert.True(t, math.IsNaN(v))
+
+		tracker.RecordUpstreamDuration("a", "evm:1", "eth_call", 50*time.Millisecond, "none")
+		v, ok = tracker.GetLatencyQuantile("a", "evm:1", "eth_call", 0.90)
+		assert.True(t, ok)
+		assert.InDelta(t, 0.05, v, 0.001)
+	})
+
+	t.Run("ReadingDoesNotCreateKeys", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		_, ok := tracker.GetLatencyQuantile("a", "evm:1", "eth_call", 0.90)
+		assert.False(t, ok)
+		_, exists := tracker.metrics.Load(tripletKey{"a", "evm:1", "eth_call"})
+		assert.False(t, exists)
+	})
+}
@@ -0,0 +1,225 @@
+package health
+
+import (
+	"sort"
+	"time"
+
+	"github.com/erpc/erpc/common"
+)
+
+type noopTracker struct{}
+
+// NewNoopTracker returns a MetricsTracker that records nothing, never cordons nor denies,
+// keeps health-derived weights untouched and returns empty metrics.
+func NewNoopTracker() MetricsTracker {
+	return noopTracker{}
+}
+
+func (n noopTracker) RecordUpstreamRequest(ups, network, method string) {}
+
+func (n noopTracker) RecordUpstreamDurationStart(ups, network, method string, compositeType string) *Timer {
+	return NewTimer(n, RealClock{}, ups, network, method, compositeType)
+}
+
+func (n noopTracker) RecordUpstreamDuration(ups, network, method string, duration time.Duration, compositeType string) {
+}
+
+func (n noopTracker) RecordUpstreamDurationWithQuantiles(ups, network, method string, duration time.Duration, compositeType string) (p50, p99 float64) {
+	return 0, 0
+}
+
+func (n noopTracker) RecordUpstreamFinalityDuration(ups, network, method string, duration time.Duration, compositeType string, finality common.DataFinalityState) {
+}
+
+func (n noopTracker) RecordUpstreamFailure(ups, network, method string) {}
+
+func (n noopTracker) RecordUpstreamSelfRateLimited(ups, network, method string) {}
+
+func (n noopTracker) RecordUpstreamRemoteRateLimited(ups, network, method string) {}
+
+func (n noopTracker) RecordUpstreamHedgeStart(ups, network, method string) *Timer {
+	return NewTimer(n, RealClock{}, ups, network, method, CompositeTypeHedge)
+}
+
+func (n noopTracker) RecordUpstreamHedgeWon(ups, network, method string) {}
+
+func (n noopTracker) RecordUpstreamHedgeCancelled(ups, network, method string, wasted time.Duration) {
+}
+
+func (n noopTracker) RecordOutcome(ups, network, method string, o Outcome) {}
+
+func (n noopTracker) RecordUpstreamCancelled(ups, network, method string, cause CancelCause, elapsed time.Duration) {
+}
+
+func (n noopTracker) RecordRequestOutcome(chain []AttemptResult) {}
+
+func (n noopTracker) Clock() Clock { return RealClock{} }
+
+func (n noopTracker) RecordUpstreamReconnect(ups, network string) {}
+
+func (n noopTracker) RecordUpstreamHandshake(ups, network string, success bool, d time.Duration) {}
+
+func (n noopTracker) RecordUpstreamProbe(ups, network string, success bool) {}
+
+func (n noopTracker) RecordUpstreamMalformedResponse(ups, network, method string, kind string) {}
+
+func (n noopTracker) RecordUpstreamMalformedSample(ups, network, method, kind string, payload []byte) {
+}
+
+func (n noopTracker) RecordUpstreamTraceSample(rec RequestRecord) {}
+
+func (n noopTracker) SetUpstreamAttributes(ups, network string, attrs map[string]string) {}
+
+func (n noopTracker) RecordUpstreamClientVersion(ups, network, raw string) {}
+
+func (n noopTracker) RecordCertificateExpiry(ups, network string, notAfter time.Time, warningDays float64) {
+}
+
+func (n noopTracker) RecordCertificateProbeFailure(ups, network string, err error) {}
+
+func (n noopTracker) RecordBlockHeadLargeRollback(ups, network, finality string, currentVal, newVal int64) {
+}
+
+func (n noopTracker) SetLatestBlockNumber(ups, network string, blockNumber int64) {}
+
+func (n noopTracker) RecordServedBlock(ups, network, method string, block int64) {}
+
+func (n noopTracker) SetFinalizedBlockNumber(ups, network string, blockNumber int64) {}
+
+func (n noopTracker) RecordBlockHash(ups, network string, number int64, hash string) {}
+
+func (n noopTracker) BeginReorg(network string) {}
+
+func (n noopTracker) EndReorg(network string) {}
+
+func (n noopTracker) Cordon(ups, network, method, reason string) {}
+
+func (n noopTracker) CordonWithInfo(ups, network, method string, info CordonInfo) {}
+
+func (n noopTracker) Uncordon(ups, network, method string) {}
+
+func (n noopTracker) UncordonWithSource(ups, network, method, source string) bool {
+	return true
+}
+
+func (n noopTracker) IsCordoned(ups, network, method string) bool { return false }
+
+func (n noopTracker) IsMethodAllowed(ups, network, method string) bool { return true }
+
+func (n noopTracker) ShouldAdmit(ups, network, method string) bool { return true }
+
+func (n noopTracker) IsSaturated(ups, network string) bool { return false }
+
+func (n noopTracker) EffectiveWeight(ups, network string, healthWeight float64) float64 {
+	return healthWeight
+}
+
+func (n noopTracker) TrafficShareCap(ups, network string) float64 { return 1 }
+
+func (n noopTracker) ReportIntendedShares(network string, weights map[string]float64) {}
+
+func (n noopTracker) IsHistoricalFor(ups, network string, block RequestBlock) bool { return false }
+
+func (n noopTracker) EligibleUpstreams(network string) int { return 0 }
+
+func (n noopTracker) GetForkSignal(network string, verbose bool) ForkSignal { return ForkSignal{} }
+
+func (n noopTracker) OrderTies(network, method string, tied []string) { sort.Strings(tied) }
+
+func (n noopTracker) SetUpstreamShadow(ups string, shadow bool) {}
+
+func (n noopTracker) SetUpstreamGroup(ups, group string) {}
+
+func (n noopTracker) SetConcurrencyLimit(ups, network string, c int) {}
+
+func (n noopTracker) SetErrorBudgetConfig(ups, network string, cfg *ErrorBudgetConfig) error {
+	return nil
+}
+
+func (n noopTracker) GetUpstreamMethodMetrics(ups, network, method string) *TrackedMetrics {
+	return NewTrackedMetrics()
+}
+
+func (n noopTracker) LookupUpstreamMethodMetrics(ups, network, method string) (*TrackedMetrics, bool) {
+	return nil, false
+}
+
+func (n noopTracker) GetUpstreamMetrics(upsId string) map[string]*TrackedMetrics {
+	return map[string]*TrackedMetrics{}
+}
+
+func (n noopTracker) GetUpstreamMetricsSnapshot(upsId string) map[string]*TrackedMetricsSnapshot {
+	return map[string]*TrackedMetricsSnapshot{}
+}
+
+func (n noopTracker) GetNetworkMethodMetrics(network, method string) *TrackedMetrics {
+	return NewTrackedMetrics()
+}
+
+func (n noopTracker) GetNetworkUpstreamsMetrics(network, method string) map[string]*TrackedMetricsSnapshot {
+	return map[string]*TrackedMetricsSnapshot{}
+}
+
+func (n noopTracker) RecordUpstreamBehindHeadError(ups, network, method string, err error) bool {
+	return false
+}
+
+func (n noopTracker) RecordConsensusComparison(c ConsensusComparison) {}
+
+func (n noopTracker) RecordUpstreamMismatch(ups, network, method string) {}
+
+func (n noopTracker) RecordUpstreamPartialResponse(ups, network, method string) {}
+
+func (n noopTracker) RecordSelection(ups, network, method string) {}
+
+func (n noopTracker) RecordBroadcastAccepted(ups, network, txHash string) {}
+
+func (n noopTracker) RecordUpstreamChainId(ups, network string, chainId int64) {}
+
+func (n noopTracker) SetExpectedChainId(network string, chainId int64) {}
+
+func (n noopTracker) SetEligibilityFromCordons(network string) {}
+
+func (n noopTracker) InCooldown(ups, network, method string, d time.Duration) bool {
+	return false
+}
+
+func (n noopTracker) MalformedResponseSamples(ups string) []MalformedSample {
+	return nil
+}
+
+func (n noopTracker) SelectionView(ups, network, method string) SelectionView {
+	return SelectionView{}
+}
+
+func (n noopTracker) ClassSelectionView(ups, network string, class MethodClass) SelectionView {
+	return SelectionView{}
+}
+
+func (n noopTracker) HealthSummary(ups, network string) (float64, time.Duration, int64, bool) {
+	return 0, 0, 0, false
+}
+
+func (n noopTracker) NormalizedLatencyScore(ups, network string) (float64, bool) { return 0, false }
+
+func (n noopTracker) ScoresNormalizedLatency(network string) bool { return false }
+
+func (n noopTracker) SetLatencyCostClasses(network string, classes *LatencyCostClasses) error {
+	return nil
+}
+
+func (n noopTracker) SetFinalityDepth(network string, blocks int64) {}
+
+func (n noopTracker) SetMethodImportance(network string, importance *MethodImportance) {}
+
+func (n noopTracker) SetErrorRateCordon(network, method string, cfg *ErrorRateCordonConfig) error {
+	return nil
+}
+
+func (n noopTracker) MethodClassOf(method string) MethodClass {
+	return MethodClassRead
+}
+
+func (n noopTracker) NoDataBehavior() NoDataBehavior {
+	return NoDataZero
+}
@@ -0,0 +1,268 @@
+package health
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/erpc/erpc/common"
+)
+
+// OutcomeKind classifies how a request towards an upstream ended.
+type OutcomeKind int
+
+const (
+	// OutcomeSuccess is a request served by the upstream.
+	OutcomeSuccess OutcomeKind = iota
+	// OutcomeFailure is a request that failed because of the upstream, counted in ErrorsTotal.
+	OutcomeFailure
+	// OutcomeNonCriticalError is a request that failed for reasons not held against the upstream,
+	// e.g. client errors, missing data or unsupported methods.
+	OutcomeNonCriticalError
+	// OutcomeRemoteRateLimited is a request throttled by the upstream.
+	OutcomeRemoteRateLimited
+	// OutcomeSelfRateLimited is a request never sent because of our own rate limits towards the
+	// upstream, it is not counted as a request.
+	OutcomeSelfRateLimited
+	// OutcomeCancelled is a request cancelled before completing, see Outcome.CancelCause.
+	OutcomeCancelled
+	// OutcomeUnsupportedMethod is a request for a method the upstream does not support, which is a
+	// capability rather than a health problem. It is counted in UnsupportedMethodTotal.
+	OutcomeUnsupportedMethod
+)
+
+func (k OutcomeKind) String() string {
+	switch k {
+	case OutcomeSuccess:
+		return "success"
+	case OutcomeFailure:
+		return "failure"
+	case OutcomeNonCriticalError:
+		return "non_critical_error"
+	case OutcomeRemoteRateLimited:
+		return "remote_rate_limited"
+	case OutcomeSelfRateLimited:
+		return "self_rate_limited"
+	case OutcomeCancelled:
+		return "cancelled"
+	case OutcomeUnsupportedMethod:
+		return "unsupported_method"
+	}
+	return "unknown"
+}
+
+// Outcome describes a completed request towards an upstream, see RecordOutcome.
+type Outcome struct {
+	Kind OutcomeKind
+	// JsonRpcCode is the normalized JSON-RPC error code of the response if any.
+	JsonRpcCode int
+	// HttpStatus is the status code of the upstream response if known.
+	HttpStatus int
+	// Bytes is the size of the response.
+	Bytes int64
+	// Duration is ignored for self rate limited and cancelled requests. Timer.ObserveOutcome sets it.
+	Duration      time.Duration
+	CompositeType string
+	// TTFB is the time to first byte of the response, zero when not measured. Timer.ObserveFirstByte
+	// sets it.
+	TTFB time.Duration
+	// Attempt is the index of the attempt (1 for the first one), zero when unknown which is
+	// accounted as a first attempt. Timer.ObserveOutcome sets it from the timer.
+	Attempt int
+	// Finality of the requested data, mind the zero value is finalized. Timer.ObserveOutcome sets
+	// it from the timer, see Timer.SetFinality.
+	Finality common.DataFinalityState
+	// Err is the error returned by the upstream if any, retained in RecentErrors unless the
+	// request was cancelled or never sent.
+	Err error
+	// CancelCause is only used with OutcomeCancelled. When empty only the request is counted,
+	// for callers leaving the cancellation to be attributed by whoever knows the cause.
+	CancelCause CancelCause
+	// ClientId is the downstream client the request was made for, e.g. its API key. Empty leaves
+	// the request out of GetClientMetrics. Timer.ObserveOutcome sets it from the timer.
+	ClientId string
+}
+
+// outcomeUpdate lists what a single recording updates, so that RecordOutcome and the
+// single-purpose recorders go through the same pass over the keys.
+type outcomeUpdate struct {
+	request           bool
+	failure           bool
+	selfRateLimited   bool
+	remoteRateLimited bool
+	unsupported       bool
+	success           bool
+	serverError       bool
+	observeDuration   bool
+	duration          time.Duration
+	compositeType     string
+	finality          common.DataFinalityState
+	attempt           int
+	ttfb              time.Duration
+	bytes             int64
+}
+
+// recordUpdate applies an update to every key of (ups, network, method), network and method
+// must already be canonical.
+func (t *Tracker) recordUpdate(ups, network, method string, u outcomeUpdate) {
+	now, tau := t.clock.Now(), t.rateTimeConstant()
+	finality := normalizeFinality(u.finality)
+	sec := u.duration.Seconds()
+	for _, k := range t.getKeys(ups, network, method) {
+		m := t.getMetrics(k)
+		if u.request {
+			t.addCounter(k, m, &m.RequestsTotal, "requests", 1)
+			m.lifetime.requests.Add(1)
+			m.reqRate.observe(now, tau)
+		}
+		if u.failure {
+			t.addCounter(k, m, &m.ErrorsTotal, "errors", 1)
+			m.lifetime.errors.Add(1)
+			m.errRate.observe(now, tau)
+		}
+		if u.selfRateLimited {
+			t.addCounter(k, m, &m.SelfRateLimitedTotal, "self_rate_limited", 1)
+			m.lifetime.selfRateLimited.Add(1)
+		}
+		if u.remoteRateLimited {
+			t.addCounter(k, m, &m.RemoteRateLimitedTotal, "remote_rate_limited", 1)
+			m.lifetime.remoteRateLimited.Add(1)
+		}
+		if u.unsupported {
+			t.addCounter(k, m, &m.UnsupportedMethodTotal, "unsupported_method", 1)
+		}
+		if u.observeDuration {
+			m.ResponseQuantiles.Add(sec)
+			m.finalityQuantiles(finality).Add(sec)
+			m.attemptQuantiles(u.attempt).Add(sec)
+			m.compositeTypeQuantiles(u.compositeType).Add(sec)
+			if u.ttfb > 0 {
+				m.ttfbQuantilesOrNew().Add(u.ttfb.Seconds())
+			}
+			if u.success {
+				m.successQuantilesOrNew().Add(sec)
+			}
+			if u.serverError {
+				m.serverErrorQuantilesOrNew().Add(sec)
+			}
+		}
+		if u.bytes > 0 {
+			t.addCounter(k, m, &m.ResponseBytesTotal, "response_bytes", u.bytes)
+		}
+	}
+	if u.failure {
+		t.getMetrics(tripletKey{ups, network, method}).lastFailure.Store(now.UnixNano())
+		t.checkRampDegradation(ups, network)
+		t.checkErrorBudget(ups, network)
+	}
+
+	if !u.selfRateLimited && !u.remoteRateLimited && !u.observeDuration && u.bytes <= 0 {
+		return
+	}
+	vendor := t.upstreamVendor(ups, network)
+	if u.selfRateLimited {
+		t.exportTelemetry(telemetryEvent{kind: telemetrySelfRateLimited, network: network, ups: ups, vendor: vendor, method: method})
+	}
+	if u.remoteRateLimited {
+		t.exportTelemetry(telemetryEvent{kind: telemetryRemoteRateLimited, network: network, ups: ups, vendor: vendor, method: method})
+	}
+	if u.observeDuration {
+		t.observeSLODuration(network, u.duration)
+		compositeType := u.compositeType
+		if compositeType == "" {
+			compositeType = "none"
+		}
+		t.exportTelemetry(telemetryEvent{
+			kind:    telemetryDuration,
+			network: network, ups: ups, vendor: vendor, method: method,
+			labels: [3]string{compositeType, finality.String(), attemptLabel(u.attempt)},
+			value:  sec,
+		})
+	}
+	if u.bytes > 0 {
+		t.exportTelemetry(telemetryEvent{kind: telemetryResponseBytes, network: network, ups: ups, vendor: vendor, method: method, value: float64(u.bytes)})
+	}
+}
+
+// RecordOutcome records a completed request towards an upstream in one call: the request itself,
+// its duration, and the failure, throttling or cancellation counters matching its kind. Each kind
+// lands in exactly one category: only OutcomeFailure counts in ErrorsTotal, self rate limited
+// requests are not counted as requests, and unsupported methods and cancellations do not
+// observe a duration.
+func (t *Tracker) RecordOutcome(ups, network, method string, o Outcome) {
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
+
+	u := outcomeUpdate{
+		duration:      o.Duration,
+		compositeType: o.CompositeType,
+		finality:      o.Finality,
+		attempt:       o.Attempt,
+		ttfb:          o.TTFB,
+		bytes:         o.Bytes,
+	}
+	switch o.Kind {
+	case OutcomeSelfRateLimited:
+		u.selfRateLimited = true
+	case OutcomeCancelled:
+		u.request = true
+	case OutcomeUnsupportedMethod:
+		u.request = true
+		u.unsupported = true
+	default:
+		u.request = true
+		u.observeDuration = true
+		u.failure = o.Kind == OutcomeFailure
+		u.success = o.Kind == OutcomeSuccess
+		u.remoteRateLimited = o.Kind == OutcomeRemoteRateLimited
+		u.serverError = o.HttpStatus >= 500 && o.HttpStatus <= 599
+	}
+	t.recordUpdate(ups, network, method, u)
+	if o.Kind == OutcomeSuccess || o.Kind == OutcomeFailure {
+		t.observeErrorRateProbation(ups, network, method, o.Kind == OutcomeSuccess)
+	}
+
+	if o.Err != nil && o.Kind != OutcomeCancelled && o.Kind != OutcomeSelfRateLimited {
+		t.recordRecentError(ups, network, method, o.Kind, o.Err)
+	}
+	if o.Kind == OutcomeCancelled && o.CancelCause != "" {
+		t.RecordUpstreamCancelled(ups, network, method, o.CancelCause, o.Duration)
+	}
+	if o.ClientId != "" {
+		t.recordClientOutcome(o.ClientId, ups, network, method, o.Kind)
+	}
+	t.exportTelemetry(telemetryEvent{
+		kind:    telemetryOutcome,
+		network: network, ups: ups, vendor: t.upstreamVendor(ups, network), method: method,
+		labels: [3]string{o.Kind.String(), strconv.Itoa(o.JsonRpcCode), httpStatusClass(o.HttpStatus)},
+	})
+}
+
+// httpStatusClass keeps the cardinality of the status label bounded, e.g. 503 is "5xx".
+func httpStatusClass(status int) string {
+	if status < 100 || status > 599 {
+		return "none"
+	}
+	return strconv.Itoa(status/100) + "xx"
+}
+
+// ObserveOutcome records the outcome of the timed request along with its duration and finality,
+// in place of ObserveDuration. The composite type, attempt and time to first byte of the timer are
+// used unless set on the outcome.
+func (t *Timer) ObserveOutcome(o Outcome) {
+	t.endInFlight()
+	o.Duration = t.clock.Now().Sub(t.start)
+	o.Finality = t.finality
+	if o.Attempt == 0 {
+		o.Attempt = t.attempt
+	}
+	if o.TTFB == 0 {
+		o.TTFB = t.ttfb
+	}
+	if o.CompositeType == "" {
+		o.CompositeType = t.compositeType
+	}
+	if o.ClientId == "" {
+		o.ClientId = t.clientId
+	}
+	t.tracker.RecordOutcome(t.ups, t.network, t.method, o)
+}
@@ -0,0 +1,137 @@
+package health_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordOutcome(t *testing.T) {
+	networkID := "evm:123"
+
+	t.Run("UpdatesCountersMatchingTheKind", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		for _, kind := range []health.OutcomeKind{
+			health.OutcomeSuccess,
+			health.OutcomeFailure,
+			health.OutcomeNonCriticalError,
+			health.OutcomeRemoteRateLimited,
+			health.OutcomeSelfRateLimited,
+		} {
+			tracker.RecordOutcome("a", networkID, "eth_call", health.Outcome{
+				Kind:     kind,
+				Bytes:    100,
+				Duration: 100 * time.Millisecond,
+				Finality: common.DataFinalityStateUnknown,
+			})
+		}
+		tracker.RecordOutcome("a", networkID, "eth_call", health.Outcome{Kind: health.OutcomeCancelled, CancelCause: health.CancelCauseClient, Duration: time.Second})
+
+		m := tracker.GetUpstreamMethodMetrics("a", networkID, "eth_call")
+		// Self rate limited requests were never sent
+		assert.Equal(t, int64(5), m.RequestsTotal.Load())
+		assert.Equal(t, int64(1), m.ErrorsTotal.Load())
+		assert.Equal(t, int64(1), m.SelfRateLimitedTotal.Load())
+		assert.Equal(t, int64(1), m.RemoteRateLimitedTotal.Load())
+		assert.Equal(t, int64(1), m.CancelledByClientTotal.Load())
+		assert.Equal(t, int64(500), m.ResponseBytesTotal.Load())
+		// Cancelled requests do not drag the latency
+		assert.InEpsilon(t, 0.1, m.ResponseQuantiles.GetQuantile(0.99).Seconds(), 0.02)
+
+		assert.Equal(t, int64(5), tracker.GetNetworkMethodMetrics(networkID, "*").RequestsTotal.Load())
+	})
+
+	t.Run("EachKindLandsInExactlyOneCategory", func(t *testing.T) {
+		type counters struct {
+			requests, errors, self, remote, unsupported, cancelled int64
+		}
+		for _, tc := range []struct {
+			outcome health.Outcome
+			want    counters
+		}{
+			{health.Outcome{Kind: health.OutcomeSuccess}, counters{requests: 1}},
+			{health.Outcome{Kind: health.OutcomeFailure}, counters{requests: 1, errors: 1}},
+			{health.Outcome{Kind: health.OutcomeNonCriticalError}, counters{requests: 1}},
+			{health.Outcome{Kind: health.OutcomeRemoteRateLimited}, counters{requests: 1, remote: 1}},
+			{health.Outcome{Kind: health.OutcomeSelfRateLimited}, counters{self: 1}},
+			{health.Outcome{Kind: health.OutcomeUnsupportedMethod}, counters{requests: 1, unsupported: 1}},
+			{health.Outcome{Kind: health.OutcomeCancelled, CancelCause: health.CancelCauseClient}, counters{requests: 1, cancelled: 1}},
+		} {
+			t.Run(tc.outcome.Kind.String(), func(t *testing.T) {
+				tracker, _ := newFakeClockTracker(t, time.Minute)
+				tracker.RecordOutcome("a", networkID, "eth_call", tc.outcome)
+
+				m := tracker.GetUpstreamMethodMetrics("a", networkID, "eth_call")
+				assert.Equal(t, tc.want, counters{
+					requests:    m.RequestsTotal.Load(),
+					errors:      m.ErrorsTotal.Load(),
+					self:        m.SelfRateLimitedTotal.Load(),
+					remote:      m.RemoteRateLimitedTotal.Load(),
+					unsupported: m.UnsupportedMethodTotal.Load(),
+					cancelled:   m.CancelledByClientTotal.Load(),
+				})
+			})
+		}
+	})
+
+	t.Run("EffectiveErrorRateExcludesNonHealthOutcomes", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		tracker.SetCancellationsAsErrors(health.CancelCauseClient)
+		record := func(kind health.OutcomeKind, n int) {
+			for i := 0; i < n; i++ {
+				tracker.RecordOutcome("a", networkID, "eth_call", health.Outcome{Kind: kind, CancelCause: health.CancelCauseClient})
+			}
+		}
+		record(health.OutcomeSuccess, 6)
+		record(health.OutcomeFailure, 2)
+		record(health.OutcomeUnsupportedMethod, 4)
+		record(health.OutcomeSelfRateLimited, 5)
+		record(health.OutcomeCancelled, 4)
+
+		m := tracker.GetUpstreamMethodMetrics("a", networkID, "eth_call")
+		// 2 failures and 4 client cancellations over 16 requests
+		assert.Equal(t, 6.0/16, m.ErrorRate())
+		// 2 failures over the 8 requests which completed
+		assert.Equal(t, 2.0/8, m.EffectiveErrorRate())
+
+		b, err := m.MarshalJSON()
+		require.NoError(t, err)
+		assert.Contains(t, string(b), `"errorRate":0.375`)
+		assert.Contains(t, string(b), `"effectiveErrorRate":0.25`)
+		assert.Contains(t, string(b), `"unsupportedMethodTotal":4`)
+	})
+
+	t.Run("SingleCallersMatchTheOutcome", func(t *testing.T) {
+		outcomes, _ := newFakeClockTracker(t, time.Minute)
+		outcomes.RecordOutcome("a", networkID, "eth_call", health.Outcome{Kind: health.OutcomeFailure, Duration: 50 * time.Millisecond})
+
+		legacy, _ := newFakeClockTracker(t, time.Minute)
+		legacy.RecordUpstreamRequest("a", networkID, "eth_call")
+		legacy.RecordUpstreamFinalityDuration("a", networkID, "eth_call", 50*time.Millisecond, "none", common.DataFinalityStateFinalized)
+		legacy.RecordUpstreamFailure("a", networkID, "eth_call")
+
+		want := legacy.GetUpstreamMethodMetrics("a", networkID, "eth_call")
+		got := outcomes.GetUpstreamMethodMetrics("a", networkID, "eth_call")
+		assert.Equal(t, want.RequestsTotal.Load(), got.RequestsTotal.Load())
+		assert.Equal(t, want.ErrorsTotal.Load(), got.ErrorsTotal.Load())
+		assert.Equal(t, want.ResponseQuantiles.GetQuantile(0.5), got.ResponseQuantiles.GetQuantile(0.5))
+		assert.Equal(t, want.RequestsPerSecond(), got.RequestsPerSecond())
+	})
+
+	t.Run("TimerProvidesDurationAndFinality", func(t *testing.T) {
+		tracker, clock := newFakeClockTracker(t, time.Minute)
+		timer := tracker.RecordUpstreamDurationStart("a", networkID, "eth_getBlockByNumber", "none")
+		timer.SetFinality(common.DataFinalityStateRealtime)
+		clock.Advance(200 * time.Millisecond)
+		timer.ObserveOutcome(health.Outcome{Kind: health.OutcomeSuccess})
+
+		m := tracker.GetUpstreamMethodMetrics("a", networkID, "eth_getBlockByNumber")
+		assert.Equal(t, int64(1), m.RequestsTotal.Load())
+		assert.True(t, m.FinalityQuantiles[common.DataFinalityStateRealtime].Load().HasSamples())
+		assert.InEpsilon(t, 0.2, m.ResponseQuantiles.GetQuantile(0.5).Seconds(), 0.02)
+	})
+}
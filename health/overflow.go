@@ -0,0 +1,65 @@
+package health
+
+import (
+	"math"
+	"sync/atomic"
+
+	"github.com/erpc/erpc/telemetry"
+)
+
+// CounterOverflowMode controls what happens to a counter about to overflow, which only happens
+// with windows long enough (or resets disabled) for it to wrap negative and break the rates.
+type CounterOverflowMode int32
+
+const (
+	// CounterOverflowSaturate keeps the counter at its limit until the next window reset (default).
+	CounterOverflowSaturate CounterOverflowMode = iota
+	// CounterOverflowReset resets the metrics of the key early, as the window reset would, except
+	// for its cordon.
+	CounterOverflowReset
+)
+
+// counterOverflowLimit leaves headroom for concurrent additions made between the check and the
+// correction, so that a counter never wraps negative.
+const counterOverflowLimit = math.MaxInt64 - 1<<32
+
+func (m CounterOverflowMode) String() string {
+	if m == CounterOverflowReset {
+		return "reset"
+	}
+	return "saturate"
+}
+
+// SetCounterOverflowMode configures how request counters (requests, errors, rate limited,
+// unsupported methods and response bytes) are kept from overflowing.
+func (t *Tracker) SetCounterOverflowMode(mode CounterOverflowMode) {
+	t.counterOverflowMode.Store(int32(mode))
+}
+
+// addCounter adds delta to counter c of the metrics m of key k, guarding it against overflows.
+func (t *Tracker) addCounter(k tripletKey, m *TrackedMetrics, c *atomic.Int64, name string, delta int64) {
+	if n := c.Add(delta); n >= 0 && n <= counterOverflowLimit {
+		return
+	}
+
+	mode := CounterOverflowMode(t.counterOverflowMode.Load())
+	if mode == CounterOverflowReset {
+		info, cordoned := m.cordonInfo.Load(), m.Cordoned.Load()
+		m.Reset()
+		if cordoned {
+			m.cordonInfo.Store(info)
+			m.Cordoned.Store(true)
+		}
+		c.Add(delta)
+	} else {
+		c.Store(counterOverflowLimit)
+		// Only the first overflow of the window is reported, every later addition saturates again
+		if !m.overflowed.CompareAndSwap(false, true) {
+			return
+		}
+	}
+
+	t.logger.Warn().Str("upstreamId", k.ups).Str("networkId", k.network).Str("method", k.method).
+		Str("counter", name).Str("mode", mode.String()).Msg("health tracker counter about to overflow")
+	telemetry.MetricUpstreamCounterOverflowTotal.WithLabelValues(t.projectId, k.network, k.ups, t.upstreamVendor(k.ups, k.network), k.method, name, mode.String()).Inc()
+}
@@ -0,0 +1,60 @@
+package health
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health/internal/metricstest"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCounterOverflow(t *testing.T) {
+	networkID := "evm:123"
+
+	t.Run("SaturatesByDefault", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-overflow", time.Minute)
+		labels := map[string]string{"project": "test-overflow", "upstream": "a", "category": "eth_call", "counter": "requests", "mode": "saturate"}
+		before := metricstest.Value(t, "erpc_upstream_counter_overflow_total", labels)
+
+		m := tracker.getMetrics(tripletKey{"a", networkID, "eth_call"})
+		m.RequestsTotal.Store(math.MaxInt64 - 1)
+		m.ErrorsTotal.Store(math.MaxInt64 - 1)
+		for i := 0; i < 3; i++ {
+			tracker.RecordUpstreamRequest("a", networkID, "eth_call")
+			tracker.RecordUpstreamFailure("a", networkID, "eth_call")
+		}
+
+		assert.Equal(t, int64(counterOverflowLimit), m.RequestsTotal.Load())
+		assert.Equal(t, int64(counterOverflowLimit), m.ErrorsTotal.Load())
+		assert.Equal(t, 1.0, m.ErrorRate())
+		assert.Equal(t, before+1, metricstest.Value(t, "erpc_upstream_counter_overflow_total", labels))
+		// Other keys are unaffected
+		assert.Equal(t, int64(3), tracker.GetUpstreamMethodMetrics("a", networkID, "*").RequestsTotal.Load())
+	})
+
+	t.Run("NeverWrapsNegative", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-overflow", time.Minute)
+		m := tracker.getMetrics(tripletKey{"a", networkID, "eth_call"})
+		m.ResponseBytesTotal.Store(math.MaxInt64)
+		tracker.RecordOutcome("a", networkID, "eth_call", Outcome{Kind: OutcomeSuccess, Bytes: 1 << 20})
+		assert.Equal(t, int64(counterOverflowLimit), m.ResponseBytesTotal.Load())
+	})
+
+	t.Run("ResetsEarlyKeepingTheCordon", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-overflow", time.Minute)
+		tracker.SetCounterOverflowMode(CounterOverflowReset)
+		tracker.Cordon("a", networkID, "eth_call", "manual")
+
+		m := tracker.getMetrics(tripletKey{"a", networkID, "eth_call"})
+		m.RequestsTotal.Store(math.MaxInt64 - 1)
+		m.ErrorsTotal.Store(10)
+		tracker.RecordUpstreamRequest("a", networkID, "eth_call")
+
+		assert.Equal(t, int64(1), m.RequestsTotal.Load())
+		assert.Equal(t, int64(0), m.ErrorsTotal.Load())
+		assert.True(t, tracker.IsCordoned("a", networkID, "eth_call"))
+		assert.Equal(t, "manual", tracker.GetCordonInfo("a", networkID, "eth_call").Reason)
+	})
+}
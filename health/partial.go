@@ -0,0 +1,22 @@
+package health
+
+import "github.com/erpc/erpc/telemetry"
+
+// PartialResponseRate is PartialResponsesTotal over RequestsTotal.
+func (m *TrackedMetrics) PartialResponseRate() float64 {
+	partial := m.PartialResponsesTotal.Load()
+	return boundedRatio(partial, m.RequestsTotal.Load())
+}
+
+// RecordUpstreamPartialResponse records that a response of the upstream was incomplete, e.g.
+// eth_getLogs results truncated or a page missing, so that selection can prefer upstreams
+// returning complete result sets. The request is still counted as served: it is neither a
+// success nor an error of its own, only RecordUpstreamRequest and RecordUpstreamFailure are.
+func (t *Tracker) RecordUpstreamPartialResponse(ups, network, method string) {
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
+	for _, k := range t.getKeys(ups, network, method) {
+		t.getMetrics(k).PartialResponsesTotal.Add(1)
+	}
+	telemetry.MetricUpstreamPartialResponseTotal.WithLabelValues(t.projectId, network, ups, t.upstreamVendor(ups, network), method).Inc()
+}
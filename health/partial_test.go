@@ -0,0 +1,42 @@
+package health_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpstreamPartialResponses(t *testing.T) {
+	networkID := "evm:123"
+
+	t.Run("CountedApartFromSuccessAndError", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		recordRequests(tracker, networkID, "a", "eth_getLogs", 10, 1)
+		recordRequests(tracker, networkID, "b", "eth_getLogs", 10, 0)
+		for i := 0; i < 3; i++ {
+			tracker.RecordUpstreamPartialResponse("a", networkID, "eth_getLogs")
+		}
+
+		m := tracker.GetUpstreamMethodMetrics("a", networkID, "eth_getLogs")
+		assert.Equal(t, int64(3), m.PartialResponsesTotal.Load())
+		assert.Equal(t, int64(10), m.RequestsTotal.Load())
+		assert.Equal(t, int64(1), m.ErrorsTotal.Load())
+		assert.InDelta(t, 0.1, m.ErrorRate(), 1e-9)
+		assert.InDelta(t, 0.3, m.PartialResponseRate(), 1e-9)
+		assert.InDelta(t, 0.3, tracker.GetUpstreamMethodMetrics("a", networkID, "*").PartialResponseRate(), 1e-9)
+		assert.InDelta(t, 0.15, tracker.GetNetworkMethodMetrics(networkID, "eth_getLogs").PartialResponseRate(), 1e-9)
+		assert.Zero(t, tracker.GetUpstreamMethodMetrics("b", networkID, "eth_getLogs").PartialResponseRate())
+		assert.InDelta(t, 0.3, tracker.SelectionView("a", networkID, "eth_getLogs").PartialResponseRate, 1e-9)
+		assert.Zero(t, tracker.SelectionView("b", networkID, "eth_getLogs").PartialResponseRate)
+
+		snapshot := tracker.GetNetworkUpstreamsMetrics(networkID, "eth_getLogs")
+		assert.InDelta(t, 0.3, snapshot["a"].PartialResponseRate, 1e-9)
+		assert.Zero(t, snapshot["b"].PartialResponseRate)
+
+		b, err := m.MarshalJSON()
+		require.NoError(t, err)
+		assert.Contains(t, string(b), `"partialResponseRate":0.3`)
+	})
+}
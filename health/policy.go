@@ -0,0 +1,43 @@
+package health
+
+import (
+	"github.com/erpc/erpc/telemetry"
+)
+
+// SetMethodPolicy allows or denies a method on an upstream for a network.
+// Use "*" as network to apply the policy to every network of the upstream.
+func (t *Tracker) SetMethodPolicy(ups, network, method string, allowed bool) {
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
+	t.logger.Debug().Str("upstream", ups).
+		Str("network", network).
+		Str("method", method).
+		Bool("allowed", allowed).
+		Msg("setting method policy in tracker")
+
+	t.methodPolicies.Store(tripletKey{ups, network, method}, allowed)
+}
+
+// IsMethodAllowed checks the configured policy for (ups, network, method), falling back to
+// (ups, "*", method) and allowing by default. Each denied check counts as an attempt towards
+// a denied method and increments PolicyDeniedTotal.
+func (t *Tracker) IsMethodAllowed(ups, network, method string) bool {
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
+	allowed := true
+	if v, ok := t.methodPolicies.Load(tripletKey{ups, network, method}); ok {
+		allowed = v.(bool)
+	} else if v, ok := t.methodPolicies.Load(tripletKey{ups, "*", method}); ok {
+		allowed = v.(bool)
+	}
+	if allowed {
+		return true
+	}
+
+	for _, k := range t.getKeys(ups, network, method) {
+		t.getMetrics(k).PolicyDeniedTotal.Add(1)
+	}
+	telemetry.MetricUpstreamPolicyDeniedTotal.WithLabelValues(t.projectId, network, ups, t.upstreamVendor(ups, network), method).Inc()
+
+	return false
+}
@@ -0,0 +1,42 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMethodPolicy(t *testing.T) {
+	t.Run("AllowedByDefault", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+
+		assert.True(t, tracker.IsMethodAllowed("a", "evm:1", "eth_call"))
+		assert.Equal(t, int64(0), tracker.GetUpstreamMethodMetrics("a", "evm:1", "eth_call").PolicyDeniedTotal.Load())
+	})
+
+	t.Run("DeniedMethodIsReportedAndCounted", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		tracker.SetMethodPolicy("a", "evm:1", "trace_block", false)
+
+		assert.False(t, tracker.IsMethodAllowed("a", "evm:1", "trace_block"))
+		assert.False(t, tracker.IsMethodAllowed("a", "evm:1", "trace_block"))
+		assert.True(t, tracker.IsMethodAllowed("a", "evm:1", "eth_call"))
+		assert.True(t, tracker.IsMethodAllowed("b", "evm:1", "trace_block"))
+
+		assert.Equal(t, int64(2), tracker.GetUpstreamMethodMetrics("a", "evm:1", "trace_block").PolicyDeniedTotal.Load())
+		assert.Equal(t, int64(2), tracker.GetUpstreamMethodMetrics("a", "evm:1", "*").PolicyDeniedTotal.Load())
+		assert.Equal(t, int64(2), tracker.GetNetworkMethodMetrics("evm:1", "trace_block").PolicyDeniedTotal.Load())
+		assert.Equal(t, int64(0), tracker.GetUpstreamMethodMetrics("a", "evm:1", "eth_call").PolicyDeniedTotal.Load())
+	})
+
+	t.Run("UpstreamWidePolicyAndOverride", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		tracker.SetMethodPolicy("a", "*", "debug_traceTransaction", false)
+		tracker.SetMethodPolicy("a", "evm:10", "debug_traceTransaction", true)
+
+		assert.False(t, tracker.IsMethodAllowed("a", "evm:1", "debug_traceTransaction"))
+		assert.True(t, tracker.IsMethodAllowed("a", "evm:10", "debug_traceTransaction"))
+	})
+}
@@ -12,6 +12,18 @@ import (
 type QuantileTracker struct {
 	mu     sync.RWMutex
 	sketch *ddsketch.DDSketch
+
+	// Sub-bucket sketches of a bucketed tracker (nil otherwise), see NewBucketedQuantileTracker
+	buckets   []*ddsketch.DDSketch
+	width     time.Duration
+	clock     Clock
+	head      int
+	headStart time.Time
+	// closed merges the buckets other than the head, rebuilt at each rotation, and merged adds
+	// the head to it, rebuilt on the first read after a change. Both are reused across rebuilds.
+	closed      *ddsketch.DDSketch
+	merged      *ddsketch.DDSketch
+	mergedStale bool
 }
 
 func NewQuantileTracker() *QuantileTracker {
@@ -22,10 +34,73 @@ func NewQuantileTracker() *QuantileTracker {
 	}
 }
 
+// NewBucketedQuantileTracker returns a tracker over a sliding window split in the given number of
+// sub-buckets, each with its own sketch. Values are added to the current bucket, quantiles merge
+// the retained buckets, and each elapsed bucket width drops the oldest bucket.
+func NewBucketedQuantileTracker(buckets int, window time.Duration, clock Clock) *QuantileTracker {
+	buckets = max(buckets, 1)
+	q := &QuantileTracker{
+		buckets:   make([]*ddsketch.DDSketch, buckets),
+		width:     max(window/time.Duration(buckets), time.Nanosecond),
+		clock:     clock,
+		headStart: clock.Now(),
+	}
+	for i := range q.buckets {
+		q.buckets[i], _ = ddsketch.NewDefaultDDSketch(0.01)
+	}
+	q.closed, _ = ddsketch.NewDefaultDDSketch(0.01)
+	q.merged, _ = ddsketch.NewDefaultDDSketch(0.01)
+	return q
+}
+
+// advanceLocked rotates the buckets of a bucketed tracker up to now, clearing the buckets which
+// fell out of the window.
+func (q *QuantileTracker) advanceLocked() {
+	steps := int(q.clock.Now().Sub(q.headStart) / q.width)
+	if steps <= 0 {
+		return
+	}
+	for i := 0; i < min(steps, len(q.buckets)); i++ {
+		q.head = (q.head + 1) % len(q.buckets)
+		q.buckets[q.head].Clear()
+	}
+	q.headStart = q.headStart.Add(time.Duration(steps) * q.width)
+
+	q.closed.Clear()
+	for i, b := range q.buckets {
+		if i != q.head && !b.IsEmpty() {
+			_ = q.closed.MergeWith(b)
+		}
+	}
+	q.mergedStale = true
+}
+
+// currentLocked returns the sketch quantiles are read from, which callers must not retain past
+// the lock. For bucketed trackers it is the merge of the retained buckets.
+func (q *QuantileTracker) currentLocked() *ddsketch.DDSketch {
+	if q.buckets == nil {
+		return q.sketch
+	}
+	q.advanceLocked()
+	if q.mergedStale {
+		q.merged.Clear()
+		_ = q.merged.MergeWith(q.closed)
+		_ = q.merged.MergeWith(q.buckets[q.head])
+		q.mergedStale = false
+	}
+	return q.merged
+}
+
 func (q *QuantileTracker) Add(value float64) {
 	q.mu.Lock()
 	defer q.mu.Unlock()
-	err := q.sketch.Add(value)
+	sketch := q.sketch
+	if q.buckets != nil {
+		q.advanceLocked()
+		sketch = q.buckets[q.head]
+		q.mergedStale = true
+	}
+	err := sketch.Add(value)
 	if err != nil {
 		log.Warn().Err(err).Float64("value", value).Msg("failed to add value to quantile tracker")
 	}
@@ -34,10 +109,26 @@ func (q *QuantileTracker) Add(value float64) {
 func (q *QuantileTracker) Reset() {
 	q.mu.Lock()
 	defer q.mu.Unlock()
+	if q.buckets != nil {
+		for _, b := range q.buckets {
+			b.Clear()
+		}
+		q.closed.Clear()
+		q.mergedStale = true
+		return
+	}
 	// Re-init the sketch
 	q.sketch, _ = ddsketch.NewDefaultDDSketch(0.01)
 }
 
+// resetWindow is Reset for window resets, bucketed trackers are left untouched since their
+// buckets expire on their own.
+func (q *QuantileTracker) resetWindow() {
+	if q.buckets == nil {
+		q.Reset()
+	}
+}
+
 func (q *QuantileTracker) MarshalJSON() ([]byte, error) {
 	return sonic.Marshal(struct {
 		P50 float64 `json:"p50"`
@@ -54,13 +145,50 @@ func (q *QuantileTracker) MarshalJSON() ([]byte, error) {
 	})
 }
 
+// HasSamples reports whether any value has been added since the last reset, or within the
+// retained buckets of a bucketed tracker.
+func (q *QuantileTracker) HasSamples() bool {
+	if q.buckets != nil {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		q.advanceLocked()
+		for _, b := range q.buckets {
+			if !b.IsEmpty() {
+				return true
+			}
+		}
+		return false
+	}
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return !q.sketch.IsEmpty()
+}
+
 func (q *QuantileTracker) GetQuantile(qtile float64) time.Duration {
 	q.mu.Lock()
 	defer q.mu.Unlock()
-	seconds, err := q.sketch.GetValueAtQuantile(qtile)
+	seconds, err := q.currentLocked().GetValueAtQuantile(qtile)
 	if err != nil {
 		// If there's no data, return 0
 		return 0
 	}
 	return time.Duration(seconds * float64(time.Second))
 }
+
+// newQuantileTracker returns a new tracker for the latency quantiles of the key, bucketed when the
+// tracker slides latency over the window, see SetLatencyBuckets.
+func (m *TrackedMetrics) newQuantileTracker() *QuantileTracker {
+	if m.newQuantiles != nil {
+		return m.newQuantiles()
+	}
+	return NewQuantileTracker()
+}
+
+// SetLatencyBuckets makes the latency quantiles of keys tracked from now on (the response ones and
+// those by finality, attempt, composite type, outcome, time to first byte and handshake) slide
+// over the window in the given number of sub-buckets instead of being reset with the window, so
+// that latency always covers the last window and recent values are not lost at each reset. Zero or
+// a negative number restores resetting quantiles. It must be called before any key is tracked.
+func (t *Tracker) SetLatencyBuckets(buckets int) {
+	t.latencyBuckets.Store(int64(max(buckets, 0)))
+}
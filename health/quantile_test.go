@@ -3,6 +3,10 @@ package health
 import (
 	"math"
 	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health/internal/fakeclock"
+	"github.com/rs/zerolog/log"
 )
 
 // A small helper to check approximate equality,
@@ -148,3 +152,66 @@ func TestWideRange(t *testing.T) {
 		t.Errorf("Expected P99 ~ 1000000, got %f", p99)
 	}
 }
+
+func TestBucketedQuantileTracker(t *testing.T) {
+	clock := fakeclock.New(time.Unix(1700000000, 0))
+	qt := NewBucketedQuantileTracker(3, 3*time.Second, clock)
+
+	// One bucket per second: 100, 200 then 300
+	qt.Add(100)
+	clock.Advance(time.Second)
+	qt.Add(200)
+	clock.Advance(time.Second)
+	qt.Add(300)
+	if p0 := qt.GetQuantile(0).Seconds(); !approxEqual(p0, 100, 2) {
+		t.Errorf("Expected the oldest bucket to be retained, got min %f", p0)
+	}
+
+	// The next bucket drops the oldest sketch
+	clock.Advance(time.Second)
+	if p0 := qt.GetQuantile(0).Seconds(); !approxEqual(p0, 200, 3) {
+		t.Errorf("Expected min 200 once the oldest bucket rotated out, got %f", p0)
+	}
+	if p100 := qt.GetQuantile(1).Seconds(); !approxEqual(p100, 300, 4) {
+		t.Errorf("Expected max 300, got %f", p100)
+	}
+
+	// Reads of an unchanged tracker reuse the merged sketch
+	if allocs := testing.AllocsPerRun(10, func() { qt.GetQuantile(0.5) }); allocs > 0 {
+		t.Errorf("Expected reads to reuse the merged sketch, got %f allocations", allocs)
+	}
+
+	// Window resets leave the buckets to expire on their own
+	qt.resetWindow()
+	if !qt.HasSamples() {
+		t.Error("Expected window reset to keep bucketed samples")
+	}
+
+	// A whole idle window drops every bucket
+	clock.Advance(3 * time.Second)
+	if qt.HasSamples() {
+		t.Error("Expected no samples after an idle window")
+	}
+}
+
+func TestTrackerLatencyBuckets(t *testing.T) {
+	clock := fakeclock.New(time.Unix(1700000000, 0))
+	tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+	tracker.SetClock(clock)
+	tracker.SetLatencyBuckets(4)
+
+	tracker.RecordUpstreamDuration("a", "evm:1", "eth_call", 2*time.Second, "none")
+	m := tracker.GetUpstreamMethodMetrics("a", "evm:1", "eth_call")
+	m.Reset()
+	if p50 := m.ResponseQuantiles.GetQuantile(0.5).Seconds(); !approxEqual(p50, 2, 0.05) {
+		t.Errorf("Expected latency to survive the window reset, got %f", p50)
+	}
+	if qt := m.GetAttemptQuantiles(1); qt == nil || !qt.HasSamples() {
+		t.Error("Expected latency by attempt to survive the window reset")
+	}
+
+	clock.Advance(time.Minute)
+	if m.ResponseQuantiles.HasSamples() {
+		t.Error("Expected latency to slide out of the window")
+	}
+}
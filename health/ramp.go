@@ -0,0 +1,255 @@
+package health
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	EventWarmupRampStarted   EventType = "warmupRampStarted"
+	EventWarmupRampRestarted EventType = "warmupRampRestarted"
+	EventWarmupRampAborted   EventType = "warmupRampAborted"
+)
+
+// defaultRampInitialShare is the traffic share cap right after a ramp starts when not configured.
+const defaultRampInitialShare = 0.1
+
+// RampCurve is how the traffic share cap of a warmup ramp grows to 1.
+type RampCurve int
+
+const (
+	// RampLinear grows the cap by the same amount over time.
+	RampLinear RampCurve = iota
+	// RampExponential multiplies the cap by the same factor over time, so that the upstream stays
+	// at a low share longer and takes most of its traffic at the end of the ramp.
+	RampExponential
+)
+
+// WarmupRampConfig caps the traffic share of upstreams returning from an upstream-wide cordon (or
+// newly added, see StartWarmupRamp) so that cold caches do not spike their latency, which would
+// degrade their score right away.
+type WarmupRampConfig struct {
+	// Duration of the ramp, after which the upstream takes its full share
+	Duration time.Duration
+	Curve    RampCurve
+	// Cap right after the ramp starts, within (0, 1], defaults to 0.1
+	InitialShare float64
+	// Error rate of the upstream since the ramp started above which the ramp is degraded, zero
+	// disables the check
+	MaxErrorRate float64
+	// Requests needed since the ramp started before its error rate is checked
+	MinSamples int64
+	// Abort the ramp on degradation, leaving the upstream to its score, instead of restarting it
+	AbortOnDegradation bool
+}
+
+// RampState is the state of the warmup ramp of an upstream on a network.
+type RampState struct {
+	Since time.Time `json:"since"`
+	// TrafficShareCap is the fraction of its share the upstream may currently take
+	TrafficShareCap float64       `json:"trafficShareCap"`
+	Remaining       time.Duration `json:"remaining"`
+}
+
+// warmupRamp is immutable once stored, a restart swaps it as a whole.
+type warmupRamp struct {
+	since time.Time
+	// Upstream-wide counters when the ramp started, so that the errors which led to the cordon
+	// do not count as a degradation
+	baseRequests, baseErrors int64
+}
+
+// SetWarmupRamp enables warmup ramps, nil disables them and ends the ramps in progress.
+func (t *Tracker) SetWarmupRamp(cfg *WarmupRampConfig) error {
+	if cfg == nil {
+		t.rampConfig.Store(nil)
+		t.ramps.Range(func(key, _ any) bool {
+			t.ramps.Delete(key)
+			return true
+		})
+		return nil
+	}
+	if cfg.Duration <= 0 {
+		return fmt.Errorf("warmup ramp duration must be positive, got %v", cfg.Duration)
+	}
+	if cfg.InitialShare < 0 || cfg.InitialShare > 1 {
+		return fmt.Errorf("warmup ramp initial share must be within (0, 1], got %v", cfg.InitialShare)
+	}
+	if cfg.MaxErrorRate < 0 || cfg.MaxErrorRate > 1 || cfg.MinSamples < 0 {
+		return fmt.Errorf("warmup ramp degradation threshold must be within [0, 1] with non-negative samples")
+	}
+	c := *cfg
+	if c.InitialShare == 0 {
+		c.InitialShare = defaultRampInitialShare
+	}
+	t.rampConfig.Store(&c)
+	return nil
+}
+
+// StartWarmupRamp starts (or restarts) the warmup ramp of an upstream, e.g. when it is added to a
+// network already serving traffic. It is a no-op unless SetWarmupRamp is configured. Ramps start
+// on their own when an upstream-wide cordon is lifted.
+func (t *Tracker) StartWarmupRamp(ups, network string) {
+	t.startWarmupRamp(ups, t.canonicalNetwork(network))
+}
+
+func (t *Tracker) startWarmupRamp(ups, network string) {
+	if t.rampConfig.Load() == nil {
+		return
+	}
+	t.ramps.Store(duoKey{ups: ups, network: network}, t.newWarmupRamp(ups, network))
+	t.emit(Event{
+		Type:     EventWarmupRampStarted,
+		Upstream: ups,
+		Network:  network,
+		Message:  "warmup ramp started",
+	})
+}
+
+func (t *Tracker) newWarmupRamp(ups, network string) *warmupRamp {
+	r := &warmupRamp{since: t.clock.Now()}
+	if val, ok := t.metrics.Load(tripletKey{ups, network, "*"}); ok {
+		m := val.(*TrackedMetrics)
+		r.baseErrors, r.baseRequests = m.errorsAndRequests()
+	}
+	return r
+}
+
+// TrafficShareCap returns the fraction of its share an upstream may take, below 1 while it warms
+// up, see SetWarmupRamp. Selection is expected to scale the traffic it sends accordingly.
+func (t *Tracker) TrafficShareCap(ups, network string) float64 {
+	if s, ok := t.WarmupRamp(ups, network); ok {
+		return s.TrafficShareCap
+	}
+	return 1
+}
+
+// WarmupRamp returns the state of the warmup ramp of an upstream, false if it is not warming up.
+func (t *Tracker) WarmupRamp(ups, network string) (RampState, bool) {
+	return t.warmupRampState(ups, t.canonicalNetwork(network))
+}
+
+func (t *Tracker) warmupRampState(ups, network string) (RampState, bool) {
+	cfg := t.rampConfig.Load()
+	if cfg == nil {
+		return RampState{}, false
+	}
+	k := duoKey{ups: ups, network: network}
+	val, ok := t.ramps.Load(k)
+	if !ok {
+		return RampState{}, false
+	}
+	r := val.(*warmupRamp)
+	elapsed := t.clock.Now().Sub(r.since)
+	if elapsed >= cfg.Duration {
+		t.ramps.CompareAndDelete(k, r)
+		return RampState{}, false
+	}
+	return RampState{
+		Since:           r.since,
+		TrafficShareCap: cfg.shareCap(elapsed),
+		Remaining:       cfg.Duration - elapsed,
+	}, true
+}
+
+func (c *WarmupRampConfig) shareCap(elapsed time.Duration) float64 {
+	progress := max(float64(elapsed)/float64(c.Duration), 0)
+	if c.Curve == RampExponential {
+		return math.Min(1, c.InitialShare*math.Pow(1/c.InitialShare, progress))
+	}
+	return math.Min(1, c.InitialShare+(1-c.InitialShare)*progress)
+}
+
+// checkRampDegradation restarts or aborts the warmup ramp of an upstream whose error rate since
+// the ramp started exceeds the configured threshold, it is called on failures.
+func (t *Tracker) checkRampDegradation(ups, network string) {
+	cfg := t.rampConfig.Load()
+	if cfg == nil || cfg.MaxErrorRate <= 0 {
+		return
+	}
+	k := duoKey{ups: ups, network: network}
+	val, ok := t.ramps.Load(k)
+	if !ok {
+		return
+	}
+	r := val.(*warmupRamp)
+	mval, ok := t.metrics.Load(tripletKey{ups, network, "*"})
+	if !ok {
+		return
+	}
+	m := mval.(*TrackedMetrics)
+	errors, requests := m.errorsAndRequests()
+	if requests >= r.baseRequests {
+		// Otherwise the window was reset and the ramp not re-based yet
+		errors, requests = errors-r.baseErrors, requests-r.baseRequests
+	}
+	if requests < max(cfg.MinSamples, 1) {
+		return
+	}
+	rate := boundedRatio(errors, requests)
+	if rate <= cfg.MaxErrorRate {
+		return
+	}
+
+	e := Event{
+		Upstream:  ups,
+		Network:   network,
+		Value:     rate,
+		Threshold: cfg.MaxErrorRate,
+	}
+	if cfg.AbortOnDegradation {
+		if !t.ramps.CompareAndDelete(k, r) {
+			return
+		}
+		e.Type = EventWarmupRampAborted
+		e.Message = fmt.Sprintf("warmup ramp aborted at error rate %.2f", rate)
+	} else {
+		if !t.ramps.CompareAndSwap(k, r, t.newWarmupRamp(ups, network)) {
+			return
+		}
+		e.Type = EventWarmupRampRestarted
+		e.Message = fmt.Sprintf("warmup ramp restarted at error rate %.2f", rate)
+	}
+	t.logger.Debug().Str("upstream", ups).Str("network", network).Float64("errorRate", rate).Msg(e.Message)
+	t.emit(e)
+}
+
+// rebaseWarmupRamps re-captures the base counters of the ramps in progress, it must run right
+// after metrics are reset so that the degradation check only counts the new window.
+func (t *Tracker) rebaseWarmupRamps() {
+	t.ramps.Range(func(key, value any) bool {
+		k := key.(duoKey)
+		r := value.(*warmupRamp)
+		rebased := t.newWarmupRamp(k.ups, k.network)
+		rebased.since = r.since
+		t.ramps.CompareAndSwap(k, r, rebased)
+		return true
+	})
+}
+
+// upstreamWideCordons returns the upstreams cordoned on a whole network, to start their ramps if
+// a window reset lifts their cordons.
+func (t *Tracker) upstreamWideCordons() []duoKey {
+	if t.rampConfig.Load() == nil {
+		return nil
+	}
+	var cordoned []duoKey
+	t.metrics.Range(func(key, value any) bool {
+		k := key.(tripletKey)
+		if k.ups != "*" && k.network != "*" && k.method == "*" && value.(*TrackedMetrics).Cordoned.Load() {
+			cordoned = append(cordoned, duoKey{ups: k.ups, network: k.network})
+		}
+		return true
+	})
+	return cordoned
+}
+
+// startLiftedRamps starts the ramps of the upstreams among cordoned which are no longer cordoned.
+func (t *Tracker) startLiftedRamps(cordoned []duoKey) {
+	for _, k := range cordoned {
+		if val, ok := t.metrics.Load(tripletKey{k.ups, k.network, "*"}); ok && !val.(*TrackedMetrics).Cordoned.Load() {
+			t.startWarmupRamp(k.ups, k.network)
+		}
+	}
+}
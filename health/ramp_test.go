@@ -0,0 +1,136 @@
+package health_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health"
+	"github.com/erpc/erpc/health/healthtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWarmupRamp(t *testing.T) {
+	networkID := "evm:123"
+
+	t.Run("LinearRampAfterUncordon", func(t *testing.T) {
+		tracker, clock := newFakeClockTracker(t, time.Hour)
+		require.NoError(t, tracker.SetWarmupRamp(&health.WarmupRampConfig{Duration: 10 * time.Minute, InitialShare: 0.2}))
+		tracker.RecordUpstreamRequest("a", networkID, "eth_call")
+
+		// Uncordoning what is not cordoned does not start a ramp
+		tracker.Uncordon("a", networkID, "*")
+		assert.Equal(t, 1.0, tracker.TrafficShareCap("a", networkID))
+
+		tracker.Cordon("a", networkID, "*", "maintenance")
+		tracker.Uncordon("a", networkID, "*")
+		assert.InDelta(t, 0.2, tracker.TrafficShareCap("a", networkID), 1e-9)
+
+		clock.Advance(5 * time.Minute)
+		assert.InDelta(t, 0.6, tracker.TrafficShareCap("a", networkID), 1e-9)
+		ramp := tracker.GetNetworkUpstreamsMetrics(networkID, "*")["a"].Ramp
+		require.NotNil(t, ramp)
+		assert.InDelta(t, 0.6, ramp.TrafficShareCap, 1e-9)
+		assert.Equal(t, 5*time.Minute, ramp.Remaining)
+
+		clock.Advance(5 * time.Minute)
+		assert.Equal(t, 1.0, tracker.TrafficShareCap("a", networkID))
+		assert.Nil(t, tracker.GetNetworkUpstreamsMetrics(networkID, "*")["a"].Ramp)
+	})
+
+	t.Run("ExponentialRampOfNewUpstream", func(t *testing.T) {
+		tracker, clock := newFakeClockTracker(t, time.Hour)
+		require.NoError(t, tracker.SetWarmupRamp(&health.WarmupRampConfig{Duration: 10 * time.Minute, Curve: health.RampExponential, InitialShare: 0.01}))
+
+		tracker.StartWarmupRamp("a", networkID)
+		clock.Advance(5 * time.Minute)
+		assert.InDelta(t, 0.1, tracker.TrafficShareCap("a", networkID), 1e-9)
+		clock.Advance(4 * time.Minute)
+		assert.Less(t, tracker.TrafficShareCap("a", networkID), 1.0)
+		clock.Advance(time.Minute)
+		assert.Equal(t, 1.0, tracker.TrafficShareCap("a", networkID))
+	})
+
+	t.Run("DegradationRestartsOrAborts", func(t *testing.T) {
+		for _, abort := range []bool{false, true} {
+			tracker, clock := newFakeClockTracker(t, time.Hour)
+			events, unsubscribe := tracker.Subscribe(8)
+			require.NoError(t, tracker.SetWarmupRamp(&health.WarmupRampConfig{
+				Duration:           10 * time.Minute,
+				InitialShare:       0.2,
+				MaxErrorRate:       0.5,
+				MinSamples:         4,
+				AbortOnDegradation: abort,
+			}))
+
+			// Errors leading to the cordon do not count against the ramp
+			recordRequests(tracker, networkID, "a", "eth_call", 10, 10)
+			tracker.Cordon("a", networkID, "*", "errors")
+			tracker.Uncordon("a", networkID, "*")
+			clock.Advance(5 * time.Minute)
+			recordRequests(tracker, networkID, "a", "eth_call", 3, 3)
+			assert.InDelta(t, 0.6, tracker.TrafficShareCap("a", networkID), 1e-9)
+
+			recordRequests(tracker, networkID, "a", "eth_call", 1, 1)
+			var types []health.EventType
+			for len(events) > 0 {
+				types = append(types, (<-events).Type)
+			}
+			if abort {
+				assert.Equal(t, []health.EventType{health.EventWarmupRampStarted, health.EventWarmupRampAborted}, types)
+				assert.Equal(t, 1.0, tracker.TrafficShareCap("a", networkID))
+			} else {
+				assert.Equal(t, []health.EventType{health.EventWarmupRampStarted, health.EventWarmupRampRestarted}, types)
+				assert.InDelta(t, 0.2, tracker.TrafficShareCap("a", networkID), 1e-9)
+			}
+			unsubscribe()
+		}
+	})
+
+	t.Run("DegradationCountsFromTheWindowReset", func(t *testing.T) {
+		tracker, clock := newFakeClockTracker(t, time.Minute)
+		events, unsubscribe := tracker.Subscribe(8)
+		defer unsubscribe()
+		require.NoError(t, tracker.SetWarmupRamp(&health.WarmupRampConfig{
+			Duration:     10 * time.Minute,
+			MaxErrorRate: 0.5,
+			MinSamples:   4,
+		}))
+
+		recordRequests(tracker, networkID, "a", "eth_call", 10, 10)
+		tracker.Cordon("a", networkID, "*", "errors")
+		tracker.Uncordon("a", networkID, "*")
+		require.Equal(t, health.EventWarmupRampStarted, (<-events).Type)
+		healthtest.AdvanceWindow(t, clock, time.Minute, tracker.GetUpstreamMethodMetrics("a", networkID, "*"))
+
+		// The errors before the ramp are gone with the window, those of the new one all count
+		recordRequests(tracker, networkID, "a", "eth_call", 10, 0)
+		recordRequests(tracker, networkID, "a", "eth_call", 10, 10)
+		assert.Empty(t, events)
+		recordRequests(tracker, networkID, "a", "eth_call", 1, 1)
+		require.Len(t, events, 1)
+		assert.Equal(t, health.EventWarmupRampRestarted, (<-events).Type)
+	})
+
+	t.Run("StartsWhenWindowLiftsAutomaticCordon", func(t *testing.T) {
+		tracker, clock := newFakeClockTracker(t, time.Minute)
+		require.NoError(t, tracker.SetWarmupRamp(&health.WarmupRampConfig{Duration: 10 * time.Minute}))
+		tracker.RecordUpstreamRequest("a", networkID, "eth_call")
+		tracker.Cordon("a", networkID, "*", "errors")
+
+		clock.Advance(time.Minute)
+		assert.Eventually(t, func() bool {
+			return tracker.TrafficShareCap("a", networkID) < 1
+		}, time.Second, time.Millisecond)
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Hour)
+		assert.Error(t, tracker.SetWarmupRamp(&health.WarmupRampConfig{}))
+		assert.Error(t, tracker.SetWarmupRamp(&health.WarmupRampConfig{Duration: time.Minute, InitialShare: 2}))
+
+		// Without a configuration ramps never start
+		tracker.StartWarmupRamp("a", networkID)
+		assert.Equal(t, 1.0, tracker.TrafficShareCap("a", networkID))
+	})
+}
@@ -0,0 +1,121 @@
+package health
+
+import (
+	"context"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/erpc/erpc/telemetry"
+)
+
+// DefaultRateTimeConstant is the default time constant of the smoothed request and error rates.
+const DefaultRateTimeConstant = 30 * time.Second
+
+// rateGaugesInterval is how often the rate gauges are refreshed.
+const rateGaugesInterval = time.Second
+
+// rateTicksPerSecond is the resolution of the last update time of smoothed rates, the packed
+// timestamp wraps after about 8 years which only matters for longer idle periods.
+const rateTicksPerSecond = 16
+
+// ewmaRate estimates an event rate (per second) with an exponentially weighted moving average,
+// decaying towards zero when no events are observed. The rate and the time of the last update
+// are packed in a single word updated with CAS, so that keys shared by every upstream of a
+// network do not serialize the request path.
+type ewmaRate struct {
+	state atomic.Uint64 // float32 rate bits << 32 | last update in rate ticks
+	tau   atomic.Int64  // time.Duration
+}
+
+func rateTicks(now time.Time) uint32 {
+	return uint32(now.UnixNano() / int64(time.Second/rateTicksPerSecond))
+}
+
+func (r *ewmaRate) observe(now time.Time, tau time.Duration) {
+	r.tau.Store(int64(tau))
+	ticks := rateTicks(now)
+	for {
+		old := r.state.Load()
+		rate, last := decayRate(old, ticks, tau)
+		rate += 1 / tau.Seconds()
+		if r.state.CompareAndSwap(old, uint64(math.Float32bits(float32(rate)))<<32|uint64(last)) {
+			return
+		}
+	}
+}
+
+func (r *ewmaRate) value(now time.Time) float64 {
+	rate, _ := decayRate(r.state.Load(), rateTicks(now), time.Duration(r.tau.Load()))
+	return rate
+}
+
+// decayRate returns the packed rate decayed until ticks, and the time it is now valid at. Updates
+// racing with a later one (ticks before the last update) do not move the time backwards.
+func decayRate(state uint64, ticks uint32, tau time.Duration) (float64, uint32) {
+	rate := float64(math.Float32frombits(uint32(state >> 32)))
+	last := uint32(state)
+	dt := int32(ticks - last)
+	if rate == 0 {
+		return 0, ticks
+	}
+	if dt <= 0 || tau <= 0 {
+		return rate, last
+	}
+	return rate * math.Exp(-float64(dt)/rateTicksPerSecond/tau.Seconds()), ticks
+}
+
+// SetRateTimeConstant sets the time constant of the smoothed request and error rates, i.e. how
+// fast they follow traffic changes. It applies to events recorded from now on.
+func (t *Tracker) SetRateTimeConstant(tau time.Duration) {
+	if tau <= 0 {
+		tau = DefaultRateTimeConstant
+	}
+	t.rateTau.Store(int64(tau))
+}
+
+func (t *Tracker) rateTimeConstant() time.Duration {
+	if tau := t.rateTau.Load(); tau > 0 {
+		return time.Duration(tau)
+	}
+	return DefaultRateTimeConstant
+}
+
+// RequestsPerSecond returns the smoothed request rate of the key.
+func (m *TrackedMetrics) RequestsPerSecond() float64 {
+	return m.reqRate.value(m.now())
+}
+
+// ErrorsPerSecond returns the smoothed error rate of the key.
+func (m *TrackedMetrics) ErrorsPerSecond() float64 {
+	return m.errRate.value(m.now())
+}
+
+func (m *TrackedMetrics) now() time.Time {
+	if m.clock == nil {
+		return time.Now()
+	}
+	return m.clock.Now()
+}
+
+// rateGaugesLoop periodically publishes the smoothed rates, so that they decay on dashboards too,
+// and caches the latency of HealthSummary.
+func (t *Tracker) rateGaugesLoop(ctx context.Context, ticker Ticker) {
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+			t.metrics.Range(func(key, value any) bool {
+				k := key.(tripletKey)
+				m := value.(*TrackedMetrics)
+				telemetry.MetricUpstreamRequestsPerSecond.WithLabelValues(t.projectId, k.network, k.ups, t.upstreamVendor(k.ups, k.network), k.method).Set(m.RequestsPerSecond())
+				telemetry.MetricUpstreamErrorsPerSecond.WithLabelValues(t.projectId, k.network, k.ups, t.upstreamVendor(k.ups, k.network), k.method).Set(m.ErrorsPerSecond())
+				m.refreshSummaryP90(k)
+				return true
+			})
+		}
+	}
+}
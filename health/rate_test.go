@@ -0,0 +1,71 @@
+package health_test
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health/healthtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSmoothedRates(t *testing.T) {
+	networkID := "evm:123"
+
+	t.Run("ConvergesAndDecays", func(t *testing.T) {
+		tracker, clock := newFakeClockTracker(t, time.Hour)
+		tracker.SetRateTimeConstant(2 * time.Second)
+
+		// 10 req/s with 20% errors during 10 time constants
+		for i := 0; i < 200; i++ {
+			errors := 0
+			if i%5 == 0 {
+				errors = 1
+			}
+			recordRequests(tracker, networkID, "a", "eth_call", 1, errors)
+			clock.Advance(100 * time.Millisecond)
+		}
+
+		m := tracker.GetUpstreamMethodMetrics("a", networkID, "eth_call")
+		assert.InDelta(t, 10, m.RequestsPerSecond(), 0.5)
+		assert.InDelta(t, 2, m.ErrorsPerSecond(), 0.5)
+		assert.InDelta(t, 10, tracker.GetNetworkMethodMetrics(networkID, "*").RequestsPerSecond(), 0.5)
+
+		// Rates decay instead of freezing once traffic stops
+		before := m.RequestsPerSecond()
+		clock.Advance(4 * time.Second)
+		assert.InDelta(t, before*math.Exp(-2), m.RequestsPerSecond(), 1e-6)
+		clock.Advance(time.Minute)
+		assert.Less(t, m.RequestsPerSecond(), 1e-6)
+	})
+
+	t.Run("SurvivesWindowReset", func(t *testing.T) {
+		tracker, clock := newFakeClockTracker(t, time.Second)
+
+		recordRequests(tracker, networkID, "a", "eth_call", 30, 0)
+		m := tracker.GetUpstreamMethodMetrics("a", networkID, "eth_call")
+		healthtest.AdvanceWindow(t, clock, time.Second, m)
+
+		assert.Equal(t, int64(0), m.RequestsTotal.Load())
+		assert.Greater(t, m.RequestsPerSecond(), 0.0)
+	})
+	t.Run("ConcurrentUpdatesAreNotLost", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Hour)
+		tracker.SetRateTimeConstant(time.Second)
+
+		// All upstreams share the network-wide key, none of the updates may be dropped
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(ups string) {
+				defer wg.Done()
+				recordRequests(tracker, networkID, ups, "eth_call", 100, 0)
+			}(fmt.Sprintf("ups%d", i))
+		}
+		wg.Wait()
+
+		assert.InDelta(t, 800, tracker.GetNetworkMethodMetrics(networkID, "*").RequestsPerSecond(), 0.01)
+	})
+}
@@ -0,0 +1,123 @@
+package health
+
+import (
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// defaultRecentErrorsSize is the number of errors retained per key unless SetRecentErrorsSize
+	// says otherwise.
+	defaultRecentErrorsSize = 16
+	// maxRecentErrorMessage bounds the length of retained error messages.
+	maxRecentErrorMessage = 512
+)
+
+// RecentError is an error returned by an upstream, retained for spotting error patterns.
+type RecentError struct {
+	Time    time.Time
+	Message string
+	Class   OutcomeKind
+}
+
+type errorRing struct {
+	mu   sync.Mutex
+	buf  []RecentError
+	next int
+	full bool
+}
+
+func (r *errorRing) add(e RecentError) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.buf[r.next] = e
+	r.next++
+	if r.next == len(r.buf) {
+		r.next = 0
+		r.full = true
+	}
+}
+
+// SetRecentErrorsSize sets how many errors are retained per (upstream, network, method). It only
+// applies to keys which did not record any error yet. Zero or a negative size disables retention.
+func (t *Tracker) SetRecentErrorsSize(size int) {
+	if size <= 0 {
+		size = -1
+	}
+	t.recentErrorsSize.Store(int64(size))
+}
+
+func (t *Tracker) recentErrorsCapacity() int {
+	switch size := t.recentErrorsSize.Load(); {
+	case size == 0:
+		return defaultRecentErrorsSize
+	case size < 0:
+		return 0
+	default:
+		return int(size)
+	}
+}
+
+// recordRecentError keeps err in the ring of the exact key (ups, network, method), wildcard keys
+// do not retain errors to bound memory. Network and method must already be canonical.
+func (t *Tracker) recordRecentError(ups, network, method string, class OutcomeKind, err error) {
+	if ups == "*" || method == "*" {
+		return
+	}
+	size := t.recentErrorsCapacity()
+	if size == 0 {
+		return
+	}
+	m := t.getMetrics(tripletKey{ups, network, method})
+	r := m.recentErrors.Load()
+	if r == nil {
+		m.recentErrors.CompareAndSwap(nil, &errorRing{buf: make([]RecentError, size)})
+		r = m.recentErrors.Load()
+	}
+
+	r.add(RecentError{Time: t.clock.Now(), Message: truncateString(err.Error(), maxRecentErrorMessage), Class: class})
+}
+
+// truncateString keeps at most n bytes of s, without splitting a multi-byte character.
+func truncateString(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	s = s[:n]
+	for len(s) > 0 && !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+// RecentErrors returns up to k of the most recent errors of (ups, network, method), newest first.
+// Errors are retained across window resets.
+func (t *Tracker) RecentErrors(ups, network, method string, k int) []RecentError {
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
+	if k <= 0 {
+		return nil
+	}
+	val, ok := t.metrics.Load(tripletKey{ups, network, method})
+	if !ok {
+		return nil
+	}
+	r := val.(*TrackedMetrics).recentErrors.Load()
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	count := r.next
+	if r.full {
+		count = len(r.buf)
+	}
+	k = min(k, count)
+	out := make([]RecentError, 0, k)
+	for i := 1; i <= k; i++ {
+		out = append(out, r.buf[(r.next-i+len(r.buf))%len(r.buf)])
+	}
+	return out
+}
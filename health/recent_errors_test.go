@@ -0,0 +1,103 @@
+package health_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecentErrors(t *testing.T) {
+	networkID := "evm:123"
+	fail := func(tracker *health.Tracker, kind health.OutcomeKind, msg string) {
+		tracker.RecordOutcome("a", networkID, "eth_call", health.Outcome{Kind: kind, Err: errors.New(msg)})
+	}
+
+	t.Run("NewestFirstWithEviction", func(t *testing.T) {
+		tracker, clock := newFakeClockTracker(t, time.Hour)
+		tracker.SetRecentErrorsSize(3)
+		for i := 0; i < 5; i++ {
+			fail(tracker, health.OutcomeFailure, fmt.Sprintf("err %d", i))
+			clock.Advance(time.Second)
+		}
+		fail(tracker, health.OutcomeRemoteRateLimited, "throttled")
+
+		recent := tracker.RecentErrors("a", networkID, "eth_call", 10)
+		require.Len(t, recent, 3)
+		assert.Equal(t, "throttled", recent[0].Message)
+		assert.Equal(t, health.OutcomeRemoteRateLimited, recent[0].Class)
+		assert.Equal(t, "err 4", recent[1].Message)
+		assert.Equal(t, "err 3", recent[2].Message)
+		assert.Equal(t, health.OutcomeFailure, recent[2].Class)
+		assert.True(t, recent[1].Time.After(recent[2].Time))
+
+		assert.Len(t, tracker.RecentErrors("a", networkID, "eth_call", 1), 1)
+	})
+
+	t.Run("OnlyExactKeysOfRealErrors", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Hour)
+		fail(tracker, health.OutcomeNonCriticalError, strings.Repeat("x", 2000))
+		fail(tracker, health.OutcomeCancelled, "context canceled")
+		tracker.RecordOutcome("a", networkID, "eth_call", health.Outcome{Kind: health.OutcomeSuccess})
+
+		recent := tracker.RecentErrors("a", networkID, "eth_call", 10)
+		require.Len(t, recent, 1)
+		assert.Len(t, recent[0].Message, 512)
+		assert.Empty(t, tracker.RecentErrors("a", networkID, "*", 10))
+		assert.Empty(t, tracker.RecentErrors("*", networkID, "eth_call", 10))
+		assert.Empty(t, tracker.RecentErrors("b", networkID, "eth_call", 10))
+	})
+
+	t.Run("TruncatesOnCharacterBoundary", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Hour)
+		// The 512th byte is within the first 3-byte character
+		fail(tracker, health.OutcomeFailure, strings.Repeat("x", 510)+strings.Repeat("€", 10))
+
+		recent := tracker.RecentErrors("a", networkID, "eth_call", 1)
+		require.Len(t, recent, 1)
+		assert.Equal(t, strings.Repeat("x", 510), recent[0].Message)
+	})
+
+	t.Run("DisabledWithZeroSize", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Hour)
+		tracker.SetRecentErrorsSize(0)
+		fail(tracker, health.OutcomeFailure, "boom")
+		assert.Empty(t, tracker.RecentErrors("a", networkID, "eth_call", 10))
+	})
+
+	t.Run("ConcurrentRecordingIsBounded", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Hour)
+		tracker.SetRecentErrorsSize(8)
+
+		var wg sync.WaitGroup
+		for w := 0; w < 4; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < 100; i++ {
+					fail(tracker, health.OutcomeFailure, fmt.Sprintf("%d-%03d", w, i))
+					_ = tracker.RecentErrors("a", networkID, "eth_call", 4)
+				}
+			}(w)
+		}
+		wg.Wait()
+
+		recent := tracker.RecentErrors("a", networkID, "eth_call", 100)
+		require.Len(t, recent, 8)
+		// Errors of a single writer keep their relative order, newest first
+		last := map[string]string{}
+		for _, e := range recent {
+			w, seq, _ := strings.Cut(e.Message, "-")
+			if prev, ok := last[w]; ok {
+				assert.Less(t, seq, prev)
+			}
+			last[w] = seq
+		}
+	})
+}
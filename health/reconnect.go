@@ -0,0 +1,69 @@
+package health
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/erpc/erpc/telemetry"
+)
+
+// SetReconnectCordonThreshold enables cordoning an upstream on a network once its persistent
+// connection reconnected more than threshold times within the current window. Zero disables it.
+func (t *Tracker) SetReconnectCordonThreshold(threshold int64) {
+	t.reconnectCordonThreshold.Store(threshold)
+}
+
+// RecordUpstreamReconnect counts a reconnection of the persistent connection (e.g. websocket)
+// of an upstream on a network.
+func (t *Tracker) RecordUpstreamReconnect(ups, network string) {
+	network = t.canonicalNetwork(network)
+	keys := []tripletKey{
+		{ups, network, "*"},
+		{ups, "*", "*"},
+		{"*", network, "*"},
+	}
+	for _, k := range keys {
+		t.getMetrics(k).ReconnectsTotal.Add(1)
+	}
+	telemetry.MetricUpstreamReconnectTotal.WithLabelValues(t.projectId, network, ups, t.upstreamVendor(ups, network)).Inc()
+
+	t.EvaluateReconnectCordon(ups, network)
+}
+
+// EvaluateReconnectCordon cordons an upstream on a network once it reconnected more times than
+// the threshold set by SetReconnectCordonThreshold within the current window.
+func (t *Tracker) EvaluateReconnectCordon(ups, network string) bool {
+	network = t.canonicalNetwork(network)
+	threshold := t.reconnectCordonThreshold.Load()
+	if threshold <= 0 {
+		return false
+	}
+	val, ok := t.metrics.Load(tripletKey{ups, network, "*"})
+	if !ok {
+		return false
+	}
+	reconnects := val.(*TrackedMetrics).ReconnectsTotal.Load()
+	if reconnects <= threshold {
+		return false
+	}
+	return t.autoCordon(ups, network, "*", fmt.Sprintf("reconnected %d times within window (threshold %d)", reconnects, threshold))
+}
+
+// GetUpstreamReconnectRate returns the reconnects per second of an upstream on a network,
+// averaged over the elapsed part of the current window.
+func (t *Tracker) GetUpstreamReconnectRate(ups, network string) float64 {
+	network = t.canonicalNetwork(network)
+	val, ok := t.metrics.Load(tripletKey{ups, network, "*"})
+	if !ok {
+		return 0
+	}
+	reconnects := val.(*TrackedMetrics).ReconnectsTotal.Load()
+	if reconnects == 0 {
+		return 0
+	}
+	elapsed := t.clock.Now().Sub(time.Unix(0, t.windowStart.Load()))
+	if elapsed < time.Second {
+		elapsed = time.Second
+	}
+	return float64(reconnects) / elapsed.Seconds()
+}
@@ -0,0 +1,58 @@
+package health_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordUpstreamReconnect(t *testing.T) {
+	networkID := "evm:123"
+
+	t.Run("CountsAndRate", func(t *testing.T) {
+		tracker, clock := newFakeClockTracker(t, time.Minute)
+
+		for i := 0; i < 6; i++ {
+			tracker.RecordUpstreamReconnect("a", networkID)
+		}
+		clock.Advance(30 * time.Second)
+
+		assert.Equal(t, int64(6), tracker.GetUpstreamMethodMetrics("a", networkID, "*").ReconnectsTotal.Load())
+		assert.Equal(t, int64(6), tracker.GetNetworkMethodMetrics(networkID, "*").ReconnectsTotal.Load())
+		assert.InDelta(t, 0.2, tracker.GetUpstreamReconnectRate("a", networkID), 1e-9)
+		assert.Equal(t, float64(0), tracker.GetUpstreamReconnectRate("b", networkID))
+		assert.False(t, tracker.IsCordoned("a", networkID, "eth_call"))
+	})
+
+	t.Run("CordonsAboveThresholdUntilWindowResets", func(t *testing.T) {
+		tracker, clock := newFakeClockTracker(t, time.Minute)
+		tracker.SetReconnectCordonThreshold(3)
+
+		for i := 0; i < 3; i++ {
+			tracker.RecordUpstreamReconnect("a", networkID)
+		}
+		assert.False(t, tracker.IsCordoned("a", networkID, "eth_call"))
+
+		tracker.RecordUpstreamReconnect("a", networkID)
+		assert.True(t, tracker.IsCordoned("a", networkID, "eth_call"))
+		assert.False(t, tracker.IsCordoned("b", networkID, "eth_call"))
+
+		m := tracker.GetUpstreamMethodMetrics("a", networkID, "*")
+		info := m.CordonInfo()
+		require.NotNil(t, info)
+		assert.Contains(t, info.Reason, "reconnected 4 times")
+		assert.Equal(t, health.CordonSourceTracker, info.Source)
+
+		tracker.RecordUpstreamReconnect("a", networkID)
+		assert.Same(t, info, m.CordonInfo())
+
+		clock.Advance(time.Minute)
+		assert.Eventually(t, func() bool {
+			return !tracker.IsCordoned("a", networkID, "eth_call")
+		}, time.Second, time.Millisecond)
+		assert.Equal(t, float64(0), tracker.GetUpstreamReconnectRate("a", networkID))
+	})
+}
@@ -0,0 +1,283 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// EventCordonRecovered is emitted when an automatic cordon is lifted on recovery evidence, see
+// SetCordonRecovery.
+const EventCordonRecovered EventType = "cordonRecovered"
+
+const (
+	// DefaultCordonRecoveryInterval is the default time between two recovery evaluations.
+	DefaultCordonRecoveryInterval = 30 * time.Second
+	// DefaultCordonRecoveryEvaluations is the default number of consecutive clean evaluations
+	// lifting a cordon.
+	DefaultCordonRecoveryEvaluations = 3
+
+	// recoveryTickInterval bounds the resolution of recovery intervals
+	recoveryTickInterval = time.Second
+)
+
+// CordonRecoveryConfig lifts the automatic cordons of a reason once the condition which
+// triggered them stayed clear for a number of consecutive evaluations, see SetCordonRecovery.
+type CordonRecoveryConfig struct {
+	// Time between two evaluations of a cordoned key, defaults to DefaultCordonRecoveryInterval
+	Interval time.Duration
+	// Consecutive clean evaluations lifting the cordon, defaults to DefaultCordonRecoveryEvaluations
+	Evaluations int
+	// Rate of the condition over the traffic since the previous evaluation at or under which an
+	// evaluation is clean, within [0, 1). It should stay under the rate triggering the cordon.
+	ClearThreshold float64
+}
+
+// recoverySignal reads the counters a cordon reason is evaluated on, the rate of the condition
+// being bad over total.
+type recoverySignal struct {
+	bad, total string // what the counters count, for the evidence of events
+	read       func(m *TrackedMetrics) (bad, total int64)
+}
+
+var recoverySignals = map[string]recoverySignal{
+	CordonReasonErrorRate: {"errors", "requests", func(m *TrackedMetrics) (int64, int64) {
+		return m.errorsAndRequests()
+	}},
+	CordonReasonDataMismatch: {"mismatches", "requests", func(m *TrackedMetrics) (int64, int64) {
+		return m.MismatchesTotal.Load(), m.RequestsTotal.Load()
+	}},
+	CordonReasonDataDisagreement: {"disagreements", "comparisons", func(m *TrackedMetrics) (int64, int64) {
+		minority := m.ConsensusMinorityTotal.Load()
+		return minority, minority + m.ConsensusMajorityTotal.Load()
+	}},
+	CordonReasonShortResults: {"short results", "comparisons", func(m *TrackedMetrics) (int64, int64) {
+		return m.ShortResultsTotal.Load(), m.ConsensusMinorityTotal.Load() + m.ConsensusMajorityTotal.Load()
+	}},
+	CordonReasonHandshakeFailing: {"failed handshakes", "handshakes", func(m *TrackedMetrics) (int64, int64) {
+		return m.HandshakeFailuresTotal.Load(), m.HandshakesTotal.Load()
+	}},
+}
+
+// cordonRecovery follows the evidence of an automatic cordon under recovery evaluation.
+type cordonRecovery struct {
+	info   CordonInfo // as applied, reapplied after window resets
+	signal recoverySignal
+
+	mu   sync.Mutex
+	next time.Time // time of the next evaluation
+	// resetGen of the metrics and their counters at the last evaluation
+	gen        uint64
+	bad, total int64
+	// consecutive clean evaluations and the traffic they covered
+	clean                int
+	cleanBad, cleanTotal int64
+}
+
+// SetCordonRecovery makes the automatic cordons of a reason (CordonReasonErrorRate,
+// CordonReasonDataMismatch, CordonReasonDataDisagreement, CordonReasonShortResults or
+// CordonReasonHandshakeFailing) survive window resets, and lifts them once the rate of their
+// condition over the traffic reaching the cordoned key, e.g. probes, stayed at or under
+// ClearThreshold for Evaluations consecutive evaluations. Evaluations without traffic neither
+// count nor break the streak. Manual and policy cordons are never lifted. A nil config removes
+// it, leaving the current cordons of the reason to the next window reset.
+func (t *Tracker) SetCordonRecovery(reason string, cfg *CordonRecoveryConfig) error {
+	if _, ok := recoverySignals[reason]; !ok {
+		return fmt.Errorf("cordons with reason %s cannot be evaluated for recovery", reason)
+	}
+	if cfg == nil {
+		t.cordonRecoveryConfigs.Delete(reason)
+		t.cordonRecoveries.Range(func(key, value any) bool {
+			if value.(*cordonRecovery).info.Reason == reason {
+				t.cordonRecoveries.Delete(key)
+			}
+			return true
+		})
+		return nil
+	}
+	if cfg.ClearThreshold < 0 || cfg.ClearThreshold >= 1 {
+		return fmt.Errorf("recovery clear threshold of reason %s must be within [0, 1), got %v", reason, cfg.ClearThreshold)
+	}
+	if cfg.Interval < 0 || cfg.Evaluations < 0 {
+		return fmt.Errorf("recovery of reason %s cannot have a negative interval or evaluation count", reason)
+	}
+	c := *cfg
+	if c.Interval == 0 {
+		c.Interval = DefaultCordonRecoveryInterval
+	}
+	if c.Evaluations == 0 {
+		c.Evaluations = DefaultCordonRecoveryEvaluations
+	}
+	t.cordonRecoveryConfigs.Store(reason, &c)
+	return nil
+}
+
+func (t *Tracker) cordonRecoveryConfig(reason string) *CordonRecoveryConfig {
+	if val, ok := t.cordonRecoveryConfigs.Load(reason); ok {
+		return val.(*CordonRecoveryConfig)
+	}
+	return nil
+}
+
+// trackCordonRecovery starts the recovery evaluation of an automatic cordon about to be applied
+// on k when its reason has a recovery config, returning the info to apply.
+func (t *Tracker) trackCordonRecovery(k tripletKey, info CordonInfo) CordonInfo {
+	if info.Source != CordonSourceTracker {
+		return info
+	}
+	cfg := t.cordonRecoveryConfig(info.Reason)
+	if cfg == nil {
+		return info
+	}
+	// Lifted on evidence rather than at the end of the window
+	info.ExpiresAt = time.Time{}
+	if info.Since.IsZero() {
+		info.Since = t.clock.Now()
+	}
+	m := t.getMetrics(k)
+	r := &cordonRecovery{info: info, signal: recoverySignals[info.Reason], next: t.clock.Now().Add(cfg.Interval)}
+	r.gen = m.resetGen.Load()
+	r.bad, r.total = r.signal.read(m)
+	t.cordonRecoveries.LoadOrStore(k, r)
+	return info
+}
+
+// reapplyRecoveringCordons cordons again the keys still under recovery evaluation, it must run
+// right after metrics are reset since resets lift every cordon. A key whose cordon is suppressed,
+// e.g. by the guard or the eligible upstreams floor, stays under evaluation and is retried at the
+// interval of its reason until reapplied.
+func (t *Tracker) reapplyRecoveringCordons() {
+	t.cordonRecoveries.Range(func(key, value any) bool {
+		k := key.(tripletKey)
+		t.reapplyRecoveringCordon(k, value.(*cordonRecovery))
+		return true
+	})
+}
+
+// reapplyRecoveringCordon cordons k again, restarting the evidence of its recovery from the
+// current counters when applied.
+func (t *Tracker) reapplyRecoveringCordon(k tripletKey, r *cordonRecovery) bool {
+	if !t.autoCordonWithInfo(k.ups, k.network, k.method, r.info) {
+		return false
+	}
+	m := t.getMetrics(k)
+	r.mu.Lock()
+	r.gen = m.resetGen.Load()
+	r.bad, r.total = r.signal.read(m)
+	r.clean, r.cleanBad, r.cleanTotal = 0, 0, 0
+	r.mu.Unlock()
+	return true
+}
+
+// recoveryLoop evaluates the recovery of automatic cordons, each at the interval of its reason.
+func (t *Tracker) recoveryLoop(ctx context.Context, ticker Ticker) {
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C():
+			t.evaluateCordonRecoveries(now)
+		}
+	}
+}
+
+func (t *Tracker) evaluateCordonRecoveries(now time.Time) {
+	t.cordonRecoveries.Range(func(key, value any) bool {
+		k := key.(tripletKey)
+		r := value.(*cordonRecovery)
+		cfg := t.cordonRecoveryConfig(r.info.Reason)
+		if cfg == nil {
+			t.cordonRecoveries.Delete(k)
+			return true
+		}
+		val, ok := t.metrics.Load(k)
+		if !ok {
+			return true
+		}
+		m := val.(*TrackedMetrics)
+		info := m.CordonInfo()
+		if info == nil {
+			// briefly missing while a window reset reapplies it, or suppressed when it did
+			t.retryRecoveringCordon(k, m, r, cfg, now)
+			return true
+		}
+		if info.Source != CordonSourceTracker || info.Reason != r.info.Reason {
+			// taken over by another cordon, e.g. a manual one, which is never lifted automatically
+			t.cordonRecoveries.CompareAndDelete(k, r)
+			return true
+		}
+		t.evaluateCordonRecovery(k, m, r, cfg, now)
+		return true
+	})
+}
+
+// retryRecoveringCordon reapplies the cordon of a key under recovery evaluation whose reapply was
+// suppressed, at most once per interval.
+func (t *Tracker) retryRecoveringCordon(k tripletKey, m *TrackedMetrics, r *cordonRecovery, cfg *CordonRecoveryConfig, now time.Time) {
+	r.mu.Lock()
+	if now.Before(r.next) || m.resetGen.Load()%2 == 1 {
+		r.mu.Unlock()
+		return
+	}
+	r.next = now.Add(cfg.Interval)
+	r.mu.Unlock()
+	t.reapplyRecoveringCordon(k, r)
+}
+
+func (t *Tracker) evaluateCordonRecovery(k tripletKey, m *TrackedMetrics, r *cordonRecovery, cfg *CordonRecoveryConfig, now time.Time) {
+	r.mu.Lock()
+	gen := m.resetGen.Load()
+	if now.Before(r.next) || gen%2 == 1 {
+		r.mu.Unlock()
+		return
+	}
+	r.next = now.Add(cfg.Interval)
+	bad, total := r.signal.read(m)
+	if gen != r.gen {
+		// counters restarted from zero with the window
+		r.gen, r.bad, r.total = gen, 0, 0
+	}
+	total, r.total = max(total-r.total, 0), total
+	bad, r.bad = min(max(bad-r.bad, 0), total), bad
+	if total == 0 {
+		r.mu.Unlock()
+		return
+	}
+	if boundedRatio(bad, total) > cfg.ClearThreshold {
+		r.clean, r.cleanBad, r.cleanTotal = 0, 0, 0
+		r.mu.Unlock()
+		return
+	}
+	r.clean++
+	r.cleanBad += bad
+	r.cleanTotal += total
+	clean, cleanBad, cleanTotal := r.clean, r.cleanBad, r.cleanTotal
+	r.mu.Unlock()
+	if clean < cfg.Evaluations {
+		return
+	}
+	if !t.cordonRecoveries.CompareAndDelete(k, r) {
+		return
+	}
+
+	evidence := fmt.Sprintf("%d %s out of %d %s over %d consecutive evaluations at or under %.2f",
+		cleanBad, r.signal.bad, cleanTotal, r.signal.total, clean, cfg.ClearThreshold)
+	if r.info.Reason == CordonReasonErrorRate {
+		t.liftErrorRateCordon(k, evidence)
+	}
+	if info := m.CordonInfo(); info != nil && info.Source == CordonSourceTracker && info.Reason == r.info.Reason {
+		t.UncordonWithSource(k.ups, k.network, k.method, CordonSourceTracker)
+	}
+	t.emit(Event{
+		Type:      EventCordonRecovered,
+		Upstream:  k.ups,
+		Network:   k.network,
+		Method:    k.method,
+		Value:     boundedRatio(cleanBad, cleanTotal),
+		Threshold: cfg.ClearThreshold,
+		Message:   fmt.Sprintf("uncordoning %s cordon after %s", r.info.Reason, evidence),
+	})
+}
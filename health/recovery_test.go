@@ -0,0 +1,141 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCordonRecovery(t *testing.T) {
+	networkID := "evm:123"
+	tracker := NewTracker(&log.Logger, "test-project", time.Hour)
+	tracker.SetMismatchCordon(0.1, 5)
+	require.Error(t, tracker.SetCordonRecovery("incident", &CordonRecoveryConfig{}))
+	require.Error(t, tracker.SetCordonRecovery(CordonReasonDataMismatch, &CordonRecoveryConfig{ClearThreshold: 1}))
+	require.NoError(t, tracker.SetCordonRecovery(CordonReasonDataMismatch, &CordonRecoveryConfig{
+		Interval:       10 * time.Second,
+		Evaluations:    2,
+		ClearThreshold: 0.05,
+	}))
+	events, unsubscribe := tracker.Subscribe(10)
+	defer unsubscribe()
+
+	mismatching := func(ups string, requests, mismatches int) {
+		simulateRequestMetrics(tracker, networkID, ups, "eth_call", requests, 0)
+		for i := 0; i < mismatches; i++ {
+			tracker.RecordUpstreamMismatch(ups, networkID, "eth_call")
+		}
+	}
+	mismatching("a", 10, 3)
+	mismatching("b", 10, 3)
+	start := time.Now()
+	at := func(seconds int) time.Time { return start.Add(time.Duration(seconds) * time.Second) }
+
+	info := tracker.GetCordonInfo("a", networkID, "eth_call")
+	require.NotNil(t, info)
+	assert.Equal(t, CordonReasonDataMismatch, info.Reason)
+	assert.True(t, info.ExpiresAt.IsZero())
+
+	// A window reset does not lift the cordon without evidence
+	tracker.rollWindow(at(1))
+	require.NotNil(t, tracker.GetCordonInfo("a", networkID, "eth_call"))
+	assert.Equal(t, info.Since, tracker.GetCordonInfo("a", networkID, "eth_call").Since)
+
+	// Manual cordons are never lifted
+	tracker.Cordon("b", networkID, "eth_call", "incident")
+
+	// Evaluations without traffic, under the threshold, or before the interval do not count
+	tracker.evaluateCordonRecoveries(at(10))
+	mismatching("a", 10, 1)
+	mismatching("b", 10, 1)
+	tracker.evaluateCordonRecoveries(at(20))
+	mismatching("a", 10, 0)
+	mismatching("b", 10, 0)
+	tracker.evaluateCordonRecoveries(at(30))
+	mismatching("a", 10, 0)
+	tracker.evaluateCordonRecoveries(at(35))
+	assert.True(t, tracker.IsCordoned("a", networkID, "eth_call"))
+
+	tracker.evaluateCordonRecoveries(at(40))
+	assert.False(t, tracker.IsCordoned("a", networkID, "eth_call"))
+	assert.True(t, tracker.IsCordoned("b", networkID, "eth_call"))
+	assert.Equal(t, CordonSourceManual, tracker.GetCordonInfo("b", networkID, "eth_call").Source)
+
+	var recovered []Event
+	for len(events) > 0 {
+		if ev := <-events; ev.Type == EventCordonRecovered {
+			recovered = append(recovered, ev)
+		}
+	}
+	require.Len(t, recovered, 1)
+	assert.Equal(t, "a", recovered[0].Upstream)
+	assert.Equal(t, "eth_call", recovered[0].Method)
+	assert.Equal(t, 0.05, recovered[0].Threshold)
+	assert.Contains(t, recovered[0].Message, "0 mismatches out of 20 requests over 2 consecutive evaluations")
+
+	// Without recovery cordons expire with the window again
+	require.NoError(t, tracker.SetCordonRecovery(CordonReasonDataMismatch, nil))
+	mismatching("a", 0, 3)
+	require.True(t, tracker.IsCordoned("a", networkID, "eth_call"))
+	assert.False(t, tracker.GetCordonInfo("a", networkID, "eth_call").ExpiresAt.IsZero())
+	tracker.rollWindow(at(50))
+	assert.False(t, tracker.IsCordoned("a", networkID, "eth_call"))
+}
+
+func TestCordonRecoveryLiftsErrorRateCordon(t *testing.T) {
+	networkID := "evm:123"
+	tracker := NewTracker(&log.Logger, "test-project", time.Hour)
+	require.NoError(t, tracker.SetErrorRateCordon(networkID, "eth_call", &ErrorRateCordonConfig{MaxErrorRate: 0.5, MinSamples: 10, CleanWindows: 100}))
+	require.NoError(t, tracker.SetCordonRecovery(CordonReasonErrorRate, &CordonRecoveryConfig{Interval: time.Second, Evaluations: 1}))
+	start := time.Now()
+
+	simulateRequestMetrics(tracker, networkID, "a", "eth_call", 10, 8)
+	tracker.rollWindow(start.Add(time.Second))
+	require.True(t, tracker.IsCordoned("a", networkID, "eth_call"))
+
+	simulateRequestMetrics(tracker, networkID, "a", "eth_call", 5, 0)
+	tracker.evaluateCordonRecoveries(start.Add(3 * time.Second))
+	assert.False(t, tracker.IsCordoned("a", networkID, "eth_call"))
+
+	// The error rate cordon is gone too, so the next reset does not reapply it
+	tracker.rollWindow(start.Add(4 * time.Second))
+	assert.False(t, tracker.IsCordoned("a", networkID, "eth_call"))
+}
+
+func TestCordonRecoveryRetriesSuppressedReapply(t *testing.T) {
+	networkID := "evm:123"
+	tracker := NewTracker(&log.Logger, "test-project", time.Hour)
+	tracker.SetMismatchCordon(0.1, 5)
+	require.NoError(t, tracker.SetCordonRecovery(CordonReasonDataMismatch, &CordonRecoveryConfig{Interval: 10 * time.Second, Evaluations: 1}))
+	simulateRequestMetrics(tracker, networkID, "a", "eth_call", 10, 0)
+	for i := 0; i < 3; i++ {
+		tracker.RecordUpstreamMismatch("a", networkID, "eth_call")
+	}
+	require.True(t, tracker.IsCordoned("a", networkID, "eth_call"))
+	start := time.Now()
+
+	vetoing := true
+	tracker.SetCordonGuard(func(ups, network, method, reason string) bool { return !vetoing })
+	tracker.rollWindow(start)
+	assert.False(t, tracker.IsCordoned("a", networkID, "eth_call"))
+
+	// Still vetoed, and then retried no sooner than the interval
+	tracker.evaluateCordonRecoveries(start.Add(10 * time.Second))
+	assert.False(t, tracker.IsCordoned("a", networkID, "eth_call"))
+	vetoing = false
+	tracker.evaluateCordonRecoveries(start.Add(15 * time.Second))
+	assert.False(t, tracker.IsCordoned("a", networkID, "eth_call"))
+	tracker.evaluateCordonRecoveries(start.Add(20 * time.Second))
+	require.True(t, tracker.IsCordoned("a", networkID, "eth_call"))
+	assert.True(t, tracker.GetCordonInfo("a", networkID, "eth_call").ExpiresAt.IsZero())
+
+	// The traffic while it was not cordoned is no evidence of recovery
+	tracker.evaluateCordonRecoveries(start.Add(30 * time.Second))
+	assert.True(t, tracker.IsCordoned("a", networkID, "eth_call"))
+	simulateRequestMetrics(tracker, networkID, "a", "eth_call", 10, 0)
+	tracker.evaluateCordonRecoveries(start.Add(40 * time.Second))
+	assert.False(t, tracker.IsCordoned("a", networkID, "eth_call"))
+}
@@ -0,0 +1,90 @@
+package health
+
+import "time"
+
+// DefaultReorgTimeout is how long a reorg window stays open without EndReorg unless
+// SetReorgTimeout says otherwise.
+const DefaultReorgTimeout = time.Minute
+
+// BeginReorg opens a reorg window on a network, during which the block numbers reported by its
+// upstreams still raise their maxima but the block head and finalization lags are left as they
+// are, since a deep reorg reports new heads in rapid succession which would each recompute the
+// lags of the whole network. Windows may overlap, e.g. when several upstreams notice the same
+// reorg, and the lags are recomputed once by the EndReorg closing the last of them. Windows left
+// open expire after the reorg timeout since the last BeginReorg, see SetReorgTimeout.
+func (t *Tracker) BeginReorg(network string) {
+	network = t.canonicalNetwork(network)
+	ntwMeta := t.getMetadata(duoKey{ups: "*", network: network})
+	ntwMeta.reorgDeadline.Store(t.clock.Now().Add(t.reorgTimeoutOrDefault()).UnixNano())
+	ntwMeta.reorgs.Add(1)
+}
+
+// EndReorg closes a reorg window opened by BeginReorg, recomputing the lags of every upstream of
+// the network once no reorg is in progress anymore. Unbalanced calls are ignored.
+func (t *Tracker) EndReorg(network string) {
+	network = t.canonicalNetwork(network)
+	ntwMeta := t.getMetadata(duoKey{ups: "*", network: network})
+	for {
+		n := ntwMeta.reorgs.Load()
+		if n <= 0 {
+			return
+		}
+		if ntwMeta.reorgs.CompareAndSwap(n, n-1) {
+			if n > 1 {
+				return
+			}
+			break
+		}
+	}
+	t.recomputeNetworkLags(network, ntwMeta)
+}
+
+// SetReorgTimeout sets how long reorg windows stay open without EndReorg, after which they are
+// all closed at once as if EndReorg was called, so that a missed EndReorg does not freeze the lags
+// of a network. Zero or a negative timeout means DefaultReorgTimeout.
+func (t *Tracker) SetReorgTimeout(timeout time.Duration) {
+	if timeout < 0 {
+		timeout = 0
+	}
+	t.reorgTimeout.Store(int64(timeout))
+}
+
+func (t *Tracker) reorgTimeoutOrDefault() time.Duration {
+	if timeout := time.Duration(t.reorgTimeout.Load()); timeout > 0 {
+		return timeout
+	}
+	return DefaultReorgTimeout
+}
+
+// InReorg tells whether a reorg window is open on a network, see BeginReorg.
+func (t *Tracker) InReorg(network string) bool {
+	network = t.canonicalNetwork(network)
+	val, ok := t.metadata.Load(duoKey{ups: "*", network: network})
+	return ok && t.reorgInProgress(network, val.(*NetworkMetadata))
+}
+
+// reorgInProgress tells whether lag recomputes of a network are deferred by a reorg window,
+// closing the windows which outlived the reorg timeout. Network must already be canonical.
+func (t *Tracker) reorgInProgress(network string, ntwMeta *NetworkMetadata) bool {
+	if ntwMeta.reorgs.Load() <= 0 {
+		return false
+	}
+	if t.clock.Now().UnixNano() < ntwMeta.reorgDeadline.Load() {
+		return true
+	}
+	if n := ntwMeta.reorgs.Swap(0); n > 0 {
+		t.logger.Warn().Str("networkId", network).Int32("windows", n).
+			Msg("reorg windows expired without EndReorg, recomputing lags")
+		t.recomputeNetworkLags(network, ntwMeta)
+	}
+	return false
+}
+
+func (t *Tracker) recomputeNetworkLags(network string, ntwMeta *NetworkMetadata) {
+	if ntwBn := ntwMeta.evmLatestBlockNumber.Load(); ntwBn > 0 {
+		t.recomputeBlockHeadLags(network, ntwBn)
+	}
+	if ntwVal := ntwMeta.evmFinalizedBlockNumber.Load(); ntwVal > 0 {
+		t.recomputeFinalizationLags(network, ntwVal)
+	}
+}
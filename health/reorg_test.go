@@ -0,0 +1,114 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health/internal/fakeclock"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReorgDefersLagRecompute(t *testing.T) {
+	networkID := "evm:123"
+	method := "eth_call"
+	setup := func() *Tracker {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		for _, ups := range []string{"a", "b", "c"} {
+			tracker.RecordUpstreamRequest(ups, networkID, method)
+			tracker.SetLatestBlockNumber(ups, networkID, 100)
+			tracker.SetFinalizedBlockNumber(ups, networkID, 90)
+		}
+		return tracker
+	}
+	// Each upstream in turn reports a new head during a deep reorg
+	storm := func(tracker *Tracker) {
+		for bn := int64(101); bn <= 120; bn++ {
+			tracker.SetLatestBlockNumber("a", networkID, bn)
+			tracker.SetLatestBlockNumber("b", networkID, bn-2)
+			tracker.SetFinalizedBlockNumber("a", networkID, bn-10)
+		}
+	}
+
+	t.Run("LagIsCorrectAfterEndReorg", func(t *testing.T) {
+		tracker := setup()
+		tracker.BeginReorg(networkID)
+		assert.True(t, tracker.InReorg(networkID))
+		storm(tracker)
+
+		assert.Equal(t, int64(120), tracker.getMetadata(duoKey{"*", networkID}).evmLatestBlockNumber.Load(), "maxima update during the reorg")
+		assert.Equal(t, int64(118), tracker.getMetadata(duoKey{"b", networkID}).evmLatestBlockNumber.Load())
+		assert.Equal(t, int64(110), tracker.GetFinalizedBlockNumber("*", networkID))
+		assert.Equal(t, int64(0), tracker.GetUpstreamMethodMetrics("c", networkID, method).BlockHeadLag.Load(), "lags are deferred")
+
+		tracker.EndReorg(networkID)
+		assert.False(t, tracker.InReorg(networkID))
+		assert.Equal(t, int64(0), tracker.GetUpstreamMethodMetrics("a", networkID, method).BlockHeadLag.Load())
+		assert.Equal(t, int64(2), tracker.GetUpstreamMethodMetrics("b", networkID, method).BlockHeadLag.Load())
+		assert.Equal(t, int64(20), tracker.GetUpstreamMethodMetrics("c", networkID, method).BlockHeadLag.Load())
+		assert.Equal(t, int64(20), tracker.GetUpstreamMethodMetrics("c", networkID, method).FinalizationLag.Load())
+		assert.Equal(t, int64(20), tracker.GetUpstreamMethodMetrics("b", networkID, "*").FinalizationLag.Load())
+	})
+
+	t.Run("RecomputeCountIsReduced", func(t *testing.T) {
+		tracker := setup()
+		before := tracker.lagRecomputes.Load()
+		storm(tracker)
+		withoutReorg := tracker.lagRecomputes.Load() - before
+
+		tracker = setup()
+		before = tracker.lagRecomputes.Load()
+		tracker.BeginReorg(networkID)
+		storm(tracker)
+		tracker.EndReorg(networkID)
+		withReorg := tracker.lagRecomputes.Load() - before
+
+		assert.Equal(t, int64(40), withoutReorg)
+		assert.Equal(t, int64(2), withReorg, "one recompute of each lag")
+	})
+
+	t.Run("OverlappingWindows", func(t *testing.T) {
+		tracker := setup()
+		tracker.BeginReorg(networkID)
+		tracker.BeginReorg(networkID)
+		tracker.SetLatestBlockNumber("a", networkID, 105)
+
+		tracker.EndReorg(networkID)
+		assert.True(t, tracker.InReorg(networkID))
+		assert.Equal(t, int64(0), tracker.GetUpstreamMethodMetrics("b", networkID, method).BlockHeadLag.Load())
+
+		tracker.EndReorg(networkID)
+		assert.Equal(t, int64(5), tracker.GetUpstreamMethodMetrics("b", networkID, method).BlockHeadLag.Load())
+
+		// Unbalanced calls are ignored
+		tracker.EndReorg(networkID)
+		assert.False(t, tracker.InReorg(networkID))
+		tracker.SetLatestBlockNumber("a", networkID, 107)
+		assert.Equal(t, int64(7), tracker.GetUpstreamMethodMetrics("b", networkID, method).BlockHeadLag.Load())
+	})
+
+	t.Run("WindowsExpireWithoutEndReorg", func(t *testing.T) {
+		tracker := setup()
+		clock := fakeclock.New(time.Unix(1700000000, 0))
+		tracker.SetClock(clock)
+		tracker.SetReorgTimeout(30 * time.Second)
+		tracker.BeginReorg(networkID)
+		clock.Advance(20 * time.Second)
+		tracker.BeginReorg(networkID)
+		tracker.SetLatestBlockNumber("a", networkID, 105)
+
+		// The last BeginReorg extends the windows
+		clock.Advance(20 * time.Second)
+		assert.True(t, tracker.InReorg(networkID))
+		assert.Equal(t, int64(0), tracker.GetUpstreamMethodMetrics("b", networkID, method).BlockHeadLag.Load())
+
+		clock.Advance(11 * time.Second)
+		assert.False(t, tracker.InReorg(networkID))
+		assert.Equal(t, int64(5), tracker.GetUpstreamMethodMetrics("b", networkID, method).BlockHeadLag.Load())
+
+		// A late EndReorg is ignored
+		tracker.EndReorg(networkID)
+		tracker.SetLatestBlockNumber("a", networkID, 106)
+		assert.Equal(t, int64(6), tracker.GetUpstreamMethodMetrics("b", networkID, method).BlockHeadLag.Load())
+	})
+}
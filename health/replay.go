@@ -0,0 +1,146 @@
+package health
+
+import (
+	"fmt"
+	"time"
+)
+
+// TrackerEventKind is the kind of a TrackerEvent, naming the tracker method it replays.
+type TrackerEventKind string
+
+const (
+	TrackerEventRequest           TrackerEventKind = "request"
+	TrackerEventFailure           TrackerEventKind = "failure"
+	TrackerEventDuration          TrackerEventKind = "duration"
+	TrackerEventSelfRateLimited   TrackerEventKind = "selfRateLimited"
+	TrackerEventRemoteRateLimited TrackerEventKind = "remoteRateLimited"
+	TrackerEventLatestBlock       TrackerEventKind = "latestBlock"
+	TrackerEventFinalizedBlock    TrackerEventKind = "finalizedBlock"
+	TrackerEventCordon            TrackerEventKind = "cordon"
+	TrackerEventUncordon          TrackerEventKind = "uncordon"
+)
+
+// TrackerEvent is a recorded call to the tracker, see Replay.
+type TrackerEvent struct {
+	// After is the time elapsed since the previous event (or the start of the replay)
+	After    time.Duration    `json:"after,omitempty"`
+	Kind     TrackerEventKind `json:"kind"`
+	Upstream string           `json:"upstream"`
+	Network  string           `json:"network"`
+	// Method is required by request events, cordons default to every method ("*")
+	Method string `json:"method,omitempty"`
+	// Duration of TrackerEventDuration events
+	Duration time.Duration `json:"duration,omitempty"`
+	// BlockNumber of TrackerEventLatestBlock and TrackerEventFinalizedBlock events
+	BlockNumber int64 `json:"blockNumber,omitempty"`
+	// Reason of TrackerEventCordon events
+	Reason string `json:"reason,omitempty"`
+}
+
+// advancingClock is a Clock whose time can be moved forward, such as healthtest.FakeClock.
+type advancingClock interface {
+	Clock
+	Advance(d time.Duration)
+}
+
+// Replay applies a recorded sequence of events in order, advancing clock by the After of each
+// event before applying it, e.g. to turn a captured incident into a test fixture for routing.
+// clock must be the clock of the tracker and be able to advance like healthtest.FakeClock.
+//
+// Replay rolls the windows itself as the clock passes their end so that the result does not
+// depend on goroutine scheduling, hence the tracker must not be bootstrapped. Events are all
+// validated before any is applied.
+func (t *Tracker) Replay(events []TrackerEvent, clock Clock) error {
+	if t.bootstrapped.Load() {
+		return fmt.Errorf("cannot replay events on a bootstrapped tracker")
+	}
+	advancer, ok := clock.(advancingClock)
+	if !ok {
+		return fmt.Errorf("cannot replay events with a clock that cannot advance, got %T", clock)
+	}
+	for i, e := range events {
+		if err := e.validate(); err != nil {
+			return fmt.Errorf("invalid replay event #%d: %w", i, err)
+		}
+	}
+
+	for _, e := range events {
+		if e.After > 0 {
+			advancer.Advance(e.After)
+			t.rollReplayWindows(advancer.Now())
+		}
+		t.applyReplayEvent(e)
+	}
+	return nil
+}
+
+// rollReplayWindows rolls every window ending at or before now.
+func (t *Tracker) rollReplayWindows(now time.Time) {
+	if t.windowSize <= 0 {
+		return
+	}
+	for {
+		end := time.Unix(0, t.windowStart.Load()).Add(t.windowSize)
+		if now.Before(end) {
+			return
+		}
+		t.rollWindow(end)
+	}
+}
+
+func (t *Tracker) applyReplayEvent(e TrackerEvent) {
+	switch e.Kind {
+	case TrackerEventRequest:
+		t.RecordUpstreamRequest(e.Upstream, e.Network, e.Method)
+	case TrackerEventFailure:
+		t.RecordUpstreamFailure(e.Upstream, e.Network, e.Method)
+	case TrackerEventDuration:
+		t.RecordUpstreamDuration(e.Upstream, e.Network, e.Method, e.Duration, "none")
+	case TrackerEventSelfRateLimited:
+		t.RecordUpstreamSelfRateLimited(e.Upstream, e.Network, e.Method)
+	case TrackerEventRemoteRateLimited:
+		t.RecordUpstreamRemoteRateLimited(e.Upstream, e.Network, e.Method)
+	case TrackerEventLatestBlock:
+		t.SetLatestBlockNumber(e.Upstream, e.Network, e.BlockNumber)
+	case TrackerEventFinalizedBlock:
+		t.SetFinalizedBlockNumber(e.Upstream, e.Network, e.BlockNumber)
+	case TrackerEventCordon:
+		t.Cordon(e.Upstream, e.Network, replayMethod(e.Method), e.Reason)
+	case TrackerEventUncordon:
+		t.Uncordon(e.Upstream, e.Network, replayMethod(e.Method))
+	}
+}
+
+func replayMethod(method string) string {
+	if method == "" {
+		return "*"
+	}
+	return method
+}
+
+func (e TrackerEvent) validate() error {
+	if e.After < 0 {
+		return fmt.Errorf("%s event cannot go back in time, got %v", e.Kind, e.After)
+	}
+	if e.Upstream == "" || e.Network == "" {
+		return fmt.Errorf("%s event requires an upstream and a network", e.Kind)
+	}
+	switch e.Kind {
+	case TrackerEventRequest, TrackerEventFailure, TrackerEventSelfRateLimited, TrackerEventRemoteRateLimited:
+		if e.Method == "" {
+			return fmt.Errorf("%s event requires a method", e.Kind)
+		}
+	case TrackerEventDuration:
+		if e.Method == "" || e.Duration < 0 {
+			return fmt.Errorf("duration event requires a method and a non-negative duration")
+		}
+	case TrackerEventLatestBlock, TrackerEventFinalizedBlock:
+		if e.BlockNumber <= 0 {
+			return fmt.Errorf("%s event requires a positive block number, got %d", e.Kind, e.BlockNumber)
+		}
+	case TrackerEventCordon, TrackerEventUncordon:
+	default:
+		return fmt.Errorf("unknown event kind %q", e.Kind)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package health_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health"
+	"github.com/erpc/erpc/health/healthtest"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplay(t *testing.T) {
+	networkID := "evm:123"
+
+	newTracker := func() (*health.Tracker, *healthtest.FakeClock) {
+		clock := healthtest.NewFakeClock(time.Unix(1700000000, 0))
+		tracker := health.NewTracker(&log.Logger, "test-project", time.Minute)
+		tracker.SetClock(clock)
+		return tracker, clock
+	}
+
+	t.Run("AppliesEventsAndRollsWindows", func(t *testing.T) {
+		tracker, clock := newTracker()
+		events := []health.TrackerEvent{
+			{Kind: health.TrackerEventRequest, Upstream: "a", Network: networkID, Method: "eth_call"},
+			{Kind: health.TrackerEventFailure, Upstream: "a", Network: networkID, Method: "eth_call"},
+			// Crosses the end of the first window, the failure above is forgotten
+			{After: 70 * time.Second, Kind: health.TrackerEventRequest, Upstream: "a", Network: networkID, Method: "eth_call"},
+			{Kind: health.TrackerEventLatestBlock, Upstream: "a", Network: networkID, BlockNumber: 100},
+			{Kind: health.TrackerEventLatestBlock, Upstream: "b", Network: networkID, BlockNumber: 105},
+			{Kind: health.TrackerEventRequest, Upstream: "b", Network: networkID, Method: "eth_call"},
+			{Kind: health.TrackerEventFailure, Upstream: "b", Network: networkID, Method: "eth_call"},
+			{After: time.Second, Kind: health.TrackerEventCordon, Upstream: "b", Network: networkID, Reason: "incident"},
+		}
+		require.NoError(t, tracker.Replay(events, clock))
+
+		assert.Equal(t, time.Unix(1700000071, 0), clock.Now())
+		a := tracker.GetUpstreamMethodMetrics("a", networkID, "eth_call")
+		assert.Equal(t, int64(1), a.RequestsTotal.Load())
+		assert.Equal(t, int64(0), a.ErrorsTotal.Load())
+		assert.Equal(t, int64(5), a.BlockHeadLag.Load())
+
+		b := tracker.GetUpstreamMethodMetrics("b", networkID, "eth_call")
+		assert.Equal(t, 1.0, b.ErrorRate())
+		assert.True(t, tracker.IsCordoned("b", networkID, "eth_call"))
+		assert.Equal(t, "incident", tracker.GetCordonInfo("b", networkID, "*").Reason)
+
+		require.NoError(t, tracker.Replay([]health.TrackerEvent{
+			{Kind: health.TrackerEventUncordon, Upstream: "b", Network: networkID},
+		}, clock))
+		assert.False(t, tracker.IsCordoned("b", networkID, "eth_call"))
+	})
+
+	t.Run("RejectsInvalidEventsBeforeApplyingAny", func(t *testing.T) {
+		tracker, clock := newTracker()
+		err := tracker.Replay([]health.TrackerEvent{
+			{Kind: health.TrackerEventRequest, Upstream: "a", Network: networkID, Method: "eth_call"},
+			{Kind: health.TrackerEventLatestBlock, Upstream: "a", Network: networkID},
+		}, clock)
+		assert.ErrorContains(t, err, "#1")
+		assert.Equal(t, int64(0), tracker.GetUpstreamMethodMetrics("a", networkID, "eth_call").RequestsTotal.Load())
+
+		assert.Error(t, tracker.Replay([]health.TrackerEvent{{Kind: "unknown", Upstream: "a", Network: networkID}}, clock))
+		assert.Error(t, tracker.Replay(nil, health.RealClock{}))
+	})
+
+	t.Run("RejectsBootstrappedTrackers", func(t *testing.T) {
+		tracker, clock := newFakeClockTracker(t, time.Minute)
+		assert.Error(t, tracker.Replay(nil, clock))
+	})
+}
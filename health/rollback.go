@@ -0,0 +1,81 @@
+package health
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/erpc/erpc/telemetry"
+)
+
+// minRollbackDecayInterval bounds how often decayed rollback values are recomputed.
+const minRollbackDecayInterval = time.Second
+
+// SetBlockHeadLargeRollbackDecay makes BlockHeadLargeRollback linearly decay to zero over the
+// given duration after each rollback, instead of lingering until the next one. Zero (the
+// default) disables the decay. It must be called before Bootstrap.
+func (t *Tracker) SetBlockHeadLargeRollbackDecay(decay time.Duration) {
+	t.rollbackDecay = decay
+}
+
+func (t *Tracker) rollbackDecayInterval() time.Duration {
+	interval := t.rollbackDecay / 10
+	if interval < minRollbackDecayInterval {
+		interval = minRollbackDecayInterval
+	}
+	return interval
+}
+
+// decayRollbacksLoop periodically recomputes the decayed rollback values.
+func (t *Tracker) decayRollbacksLoop(ctx context.Context, ticker Ticker) {
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C():
+			t.decayRollbacks(now)
+		}
+	}
+}
+
+// upstreamWideKeys are the keys of getKeys without a method, for what concerns the upstream as a
+// whole (e.g. rollbacks).
+func upstreamWideKeys(ups, network string) []tripletKey {
+	return []tripletKey{
+		{ups, network, "*"},
+		{ups, "*", "*"},
+		{"*", network, "*"},
+	}
+}
+
+func (t *Tracker) decayRollbacks(now time.Time) {
+	t.metrics.Range(func(key, value any) bool {
+		k := key.(tripletKey)
+		tm := value.(*TrackedMetrics)
+		peak := tm.rollbackPeak.Load()
+		if peak <= 0 {
+			return true
+		}
+
+		var val int64
+		elapsed := now.Sub(time.Unix(0, tm.rollbackAt.Load()))
+		if elapsed < t.rollbackDecay {
+			remaining := 1 - float64(elapsed)/float64(t.rollbackDecay)
+			val = int64(math.Round(float64(peak) * remaining))
+		} else {
+			tm.rollbackPeak.CompareAndSwap(peak, 0)
+		}
+		tm.BlockHeadLargeRollback.Store(val)
+
+		// The gauge is per upstream and network, aggregates are only visible through the tracker
+		if k.ups == "*" || k.network == "*" {
+			return true
+		}
+		telemetry.MetricUpstreamBlockHeadLargeRollback.
+			WithLabelValues(t.projectId, k.network, k.ups, t.upstreamVendor(k.ups, k.network)).
+			Set(float64(val))
+		return true
+	})
+}
@@ -0,0 +1,86 @@
+package health_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health"
+	"github.com/erpc/erpc/health/healthtest"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockHeadLargeRollbackDecay(t *testing.T) {
+	networkID := "evm:123"
+
+	newTracker := func(t *testing.T, decay time.Duration) (*health.Tracker, *healthtest.FakeClock) {
+		clock := healthtest.NewFakeClock(time.Unix(1700000000, 0))
+		tracker := health.NewTracker(&log.Logger, "test-project", time.Hour)
+		tracker.SetClock(clock)
+		tracker.SetBlockHeadLargeRollbackDecay(decay)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		tracker.Bootstrap(ctx)
+		tickers := 1
+		if decay > 0 {
+			tickers = 2
+		}
+		clock.WaitForTickers(tickers)
+		return tracker, clock
+	}
+
+	rollbackOf := func(tracker *health.Tracker) int64 {
+		return tracker.GetUpstreamMethodMetrics("a", networkID, "*").BlockHeadLargeRollback.Load()
+	}
+
+	t.Run("DecaysToZeroAfterHorizon", func(t *testing.T) {
+		tracker, clock := newTracker(t, 100*time.Second)
+		tracker.RecordBlockHeadLargeRollback("a", networkID, "latest", 1100, 1000)
+		assert.Equal(t, int64(100), rollbackOf(tracker))
+
+		clock.Advance(50 * time.Second)
+		assert.Eventually(t, func() bool { return rollbackOf(tracker) == 50 }, time.Second, time.Millisecond)
+
+		clock.Advance(50 * time.Second)
+		assert.Eventually(t, func() bool { return rollbackOf(tracker) == 0 }, time.Second, time.Millisecond)
+	})
+
+	t.Run("NewRollbackRestartsDecay", func(t *testing.T) {
+		tracker, clock := newTracker(t, 100*time.Second)
+		tracker.RecordBlockHeadLargeRollback("a", networkID, "latest", 1100, 1000)
+		clock.Advance(90 * time.Second)
+		assert.Eventually(t, func() bool { return rollbackOf(tracker) == 10 }, time.Second, time.Millisecond)
+
+		tracker.RecordBlockHeadLargeRollback("a", networkID, "latest", 1040, 1000)
+		clock.Advance(20 * time.Second)
+		assert.Eventually(t, func() bool { return rollbackOf(tracker) == 32 }, time.Second, time.Millisecond)
+	})
+
+	t.Run("LingersWithoutDecay", func(t *testing.T) {
+		tracker, clock := newTracker(t, 0)
+		tracker.RecordBlockHeadLargeRollback("a", networkID, "latest", 1100, 1000)
+
+		clock.Advance(30 * time.Minute)
+		assert.Equal(t, int64(100), rollbackOf(tracker))
+	})
+}
+
+func TestBlockHeadLargeRollbackAggregates(t *testing.T) {
+	networkID := "evm:123"
+	tracker, clock := newFakeClockTracker(t, time.Minute)
+	tracker.RecordUpstreamRequest("a", networkID, "eth_call")
+	tracker.RecordBlockHeadLargeRollback("a", networkID, "latest", 1100, 1000)
+
+	assert.Equal(t, int64(100), tracker.GetUpstreamMethodMetrics("a", networkID, "*").BlockHeadLargeRollback.Load())
+	assert.Equal(t, int64(100), tracker.GetUpstreamMethodMetrics("a", "*", "*").BlockHeadLargeRollback.Load())
+	assert.Equal(t, int64(100), tracker.GetNetworkMethodMetrics(networkID, "*").BlockHeadLargeRollback.Load())
+	assert.Equal(t, int64(0), tracker.GetUpstreamMethodMetrics("b", networkID, "*").BlockHeadLargeRollback.Load())
+
+	// Like on the upstream key, window resets leave it to the next rollback (or the decay)
+	healthtest.AdvanceWindow(t, clock, time.Minute, tracker.GetNetworkMethodMetrics(networkID, "*"))
+	assert.Equal(t, int64(100), tracker.GetNetworkMethodMetrics(networkID, "*").BlockHeadLargeRollback.Load())
+	tracker.RecordBlockHeadLargeRollback("a", networkID, "latest", 1020, 1000)
+	assert.Equal(t, int64(20), tracker.GetNetworkMethodMetrics(networkID, "*").BlockHeadLargeRollback.Load())
+}
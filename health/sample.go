@@ -0,0 +1,110 @@
+package health
+
+import (
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// defaultSampleRingSize is the number of samples retained when SetTraceSampling is given no size.
+const defaultSampleRingSize = 1024
+
+// RequestRecord is a single upstream request kept as an exemplar for deep debugging.
+type RequestRecord struct {
+	TraceId  string
+	Upstream string
+	Network  string
+	Method   string
+	Time     time.Time
+	Duration time.Duration
+	Success  bool
+	Error    error
+}
+
+type sampleRing struct {
+	rate float64
+
+	mu   sync.Mutex
+	buf  []RequestRecord
+	next int
+	full bool
+}
+
+func (r *sampleRing) sampled(traceId string) bool {
+	if r.rate >= 1 {
+		return true
+	}
+	if traceId == "" {
+		return rand.Float64() < r.rate // #nosec G404
+	}
+	// Decide on the trace id so that all attempts of a sampled trace are kept together
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(traceId))
+	return float64(h.Sum64()) < r.rate*math.MaxUint64
+}
+
+func (r *sampleRing) add(rec RequestRecord) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.buf[r.next] = rec
+	r.next++
+	if r.next == len(r.buf) {
+		r.next = 0
+		r.full = true
+	}
+}
+
+// SetTraceSampling enables sampling of full request records at the given rate within [0, 1],
+// retaining the last ringSize samples (a default size is used when it is not positive). A zero
+// rate disables sampling. Previously retained samples are dropped.
+func (t *Tracker) SetTraceSampling(rate float64, ringSize int) {
+	if rate <= 0 {
+		t.samples.Store(nil)
+		return
+	}
+	if ringSize <= 0 {
+		ringSize = defaultSampleRingSize
+	}
+	t.samples.Store(&sampleRing{
+		rate: math.Min(rate, 1),
+		buf:  make([]RequestRecord, ringSize),
+	})
+}
+
+// RecordUpstreamTraceSample keeps the record if it is sampled. Records carrying a trace id are
+// sampled consistently, i.e. either all or none of the attempts of a trace are kept.
+func (t *Tracker) RecordUpstreamTraceSample(rec RequestRecord) {
+	r := t.samples.Load()
+	if r == nil || !r.sampled(rec.TraceId) {
+		return
+	}
+	rec.Network = t.canonicalNetwork(rec.Network)
+	rec.Method = t.normalizeMethod(rec.Method)
+	if rec.Time.IsZero() {
+		rec.Time = t.clock.Now()
+	}
+	r.add(rec)
+}
+
+// RecentSamples returns up to n of the most recent sampled records, newest first.
+func (t *Tracker) RecentSamples(n int) []RequestRecord {
+	r := t.samples.Load()
+	if r == nil || n <= 0 {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	count := r.next
+	if r.full {
+		count = len(r.buf)
+	}
+	n = min(n, count)
+	out := make([]RequestRecord, 0, n)
+	for i := 1; i <= n; i++ {
+		out = append(out, r.buf[(r.next-i+len(r.buf))%len(r.buf)])
+	}
+	return out
+}
@@ -0,0 +1,88 @@
+package health_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTraceSampling(t *testing.T) {
+	t.Run("DisabledByDefault", func(t *testing.T) {
+		tracker := health.NewTracker(&log.Logger, "test-project", time.Minute)
+		tracker.RecordUpstreamTraceSample(health.RequestRecord{TraceId: "t1", Upstream: "a", Network: "evm:1", Method: "eth_call"})
+		assert.Empty(t, tracker.RecentSamples(10))
+	})
+
+	t.Run("SamplingRate", func(t *testing.T) {
+		tracker := health.NewTracker(&log.Logger, "test-project", time.Minute)
+		tracker.SetTraceSampling(0.25, 100000)
+
+		for i := 0; i < 20000; i++ {
+			tracker.RecordUpstreamTraceSample(health.RequestRecord{TraceId: fmt.Sprintf("trace-%d", i), Upstream: "a", Network: "evm:1", Method: "eth_call"})
+			tracker.RecordUpstreamTraceSample(health.RequestRecord{Upstream: "a", Network: "evm:1", Method: "eth_call"})
+		}
+
+		samples := tracker.RecentSamples(100000)
+		assert.InDelta(t, 10000, len(samples), 500)
+
+		withTrace := 0
+		for _, s := range samples {
+			if s.TraceId != "" {
+				withTrace++
+			}
+		}
+		assert.InDelta(t, 5000, withTrace, 350)
+	})
+
+	t.Run("AttemptsOfATraceAreSampledTogether", func(t *testing.T) {
+		tracker := health.NewTracker(&log.Logger, "test-project", time.Minute)
+		tracker.SetTraceSampling(0.5, 10000)
+
+		for i := 0; i < 1000; i++ {
+			id := fmt.Sprintf("trace-%d", i)
+			tracker.RecordUpstreamTraceSample(health.RequestRecord{TraceId: id, Upstream: "a", Network: "evm:1", Method: "eth_call"})
+			tracker.RecordUpstreamTraceSample(health.RequestRecord{TraceId: id, Upstream: "b", Network: "evm:1", Method: "eth_call"})
+		}
+
+		perTrace := map[string]int{}
+		for _, s := range tracker.RecentSamples(10000) {
+			perTrace[s.TraceId]++
+		}
+		assert.NotEmpty(t, perTrace)
+		for id, count := range perTrace {
+			assert.Equal(t, 2, count, id)
+		}
+	})
+
+	t.Run("RingEviction", func(t *testing.T) {
+		tracker, clock := newFakeClockTracker(t, time.Minute)
+		tracker.SetTraceSampling(1, 3)
+
+		for i := 1; i <= 5; i++ {
+			tracker.RecordUpstreamTraceSample(health.RequestRecord{
+				TraceId:  fmt.Sprintf("trace-%d", i),
+				Upstream: "a",
+				Network:  "evm:0x1",
+				Method:   "eth_call",
+				Duration: time.Duration(i) * time.Millisecond,
+				Success:  i%2 == 0,
+			})
+		}
+
+		samples := tracker.RecentSamples(10)
+		assert.Len(t, samples, 3)
+		assert.Equal(t, []string{"trace-5", "trace-4", "trace-3"}, []string{samples[0].TraceId, samples[1].TraceId, samples[2].TraceId})
+		assert.Equal(t, 5*time.Millisecond, samples[0].Duration)
+		assert.True(t, samples[1].Success)
+		assert.Equal(t, "evm:1", samples[0].Network)
+		assert.Equal(t, clock.Now(), samples[0].Time)
+
+		latest := tracker.RecentSamples(1)
+		assert.Len(t, latest, 1)
+		assert.Equal(t, "trace-5", latest[0].TraceId)
+	})
+}
@@ -0,0 +1,101 @@
+package health
+
+import (
+	"math"
+	"sort"
+)
+
+// ScoreWeights weighs the health signals combined into an upstream health score, similar to the
+// score multipliers used for upstream selection minus the load balancing part.
+type ScoreWeights struct {
+	ErrorRate       float64
+	P90Latency      float64
+	ThrottledRate   float64
+	BlockHeadLag    float64
+	FinalizationLag float64
+}
+
+// DefaultScoreWeights weighs error rate and latency the most.
+var DefaultScoreWeights = ScoreWeights{
+	ErrorRate:       8,
+	P90Latency:      4,
+	ThrottledRate:   3,
+	BlockHeadLag:    2,
+	FinalizationLag: 1,
+}
+
+// upstreamHealthScores scores every upstream tracked on a network between 0 (worst) and 1 (best).
+// Each signal is normalized against the worst upstream of the network, so scores are relative to
+// the fleet. Cordoned upstreams score 0.
+func (t *Tracker) upstreamHealthScores(network string, weights ScoreWeights) []float64 {
+	var views []SelectionView
+	t.metrics.Range(func(key, value any) bool {
+		k := key.(tripletKey)
+		if k.network == network && k.method == "*" && k.ups != "*" {
+			views = append(views, t.SelectionView(k.ups, k.network, k.method))
+		}
+		return true
+	})
+
+	signals := []struct {
+		weight float64
+		value  func(v SelectionView) float64
+	}{
+		{weights.ErrorRate, func(v SelectionView) float64 { return v.ErrorRate }},
+		{weights.P90Latency, func(v SelectionView) float64 { return v.P90Latency.Seconds() }},
+		{weights.ThrottledRate, func(v SelectionView) float64 { return v.ThrottledRate }},
+		{weights.BlockHeadLag, func(v SelectionView) float64 { return float64(v.BlockHeadLag) }},
+		{weights.FinalizationLag, func(v SelectionView) float64 { return float64(v.FinalizationLag) }},
+	}
+
+	scores := make([]float64, len(views))
+	var totalWeight float64
+	for _, s := range signals {
+		if s.weight <= 0 {
+			continue
+		}
+		totalWeight += s.weight
+		var worst float64
+		for _, v := range views {
+			worst = math.Max(worst, s.value(v))
+		}
+		for i, v := range views {
+			norm := 0.0
+			if worst > 0 {
+				norm = math.Max(s.value(v), 0) / worst
+			}
+			scores[i] += (1 - norm) * (1 - norm) * s.weight
+		}
+	}
+
+	for i, v := range views {
+		if v.Cordoned || totalWeight == 0 {
+			scores[i] = 0
+			continue
+		}
+		scores[i] /= totalWeight
+	}
+	return scores
+}
+
+// NetworkHealthPercentile returns the p-th percentile (0 to 1, linearly interpolated) of the health
+// scores of the upstreams of a network, e.g. 0.25 to make sure one great upstream does not mask a
+// struggling fleet. Scores range from 0 to 1, see ScoreWeights. Without any upstream the value
+// follows the no-data behavior.
+func (t *Tracker) NetworkHealthPercentile(network string, p float64, weights ScoreWeights) float64 {
+	network = t.canonicalNetwork(network)
+	scores := t.upstreamHealthScores(network, weights)
+	if len(scores) == 0 {
+		if t.NoDataBehavior() == NoDataNaN {
+			return math.NaN()
+		}
+		return 0
+	}
+	sort.Float64s(scores)
+
+	p = math.Min(math.Max(p, 0), 1)
+	rank := p * float64(len(scores)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return scores[lower] + (scores[upper]-scores[lower])*(rank-float64(lower))
+}
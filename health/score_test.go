@@ -0,0 +1,62 @@
+package health_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNetworkHealthPercentile(t *testing.T) {
+	networkID := "evm:123"
+	errorsOnly := health.ScoreWeights{ErrorRate: 1}
+
+	newFleet := func(t *testing.T) *health.Tracker {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		// Error rates of 0%, 10%, 20%, 30% and 40%
+		for i := 0; i < 5; i++ {
+			recordRequests(tracker, networkID, fmt.Sprintf("ups%d", i), "eth_call", 10, i)
+		}
+		return tracker
+	}
+
+	t.Run("InterpolatesAcrossUpstreamScores", func(t *testing.T) {
+		tracker := newFleet(t)
+
+		// Scores are (1 - rate/worst)^2: 0, 0.0625, 0.25, 0.5625 and 1
+		assert.InDelta(t, 0.0, tracker.NetworkHealthPercentile(networkID, 0, errorsOnly), 1e-9)
+		assert.InDelta(t, 0.025, tracker.NetworkHealthPercentile(networkID, 0.1, errorsOnly), 1e-9)
+		assert.InDelta(t, 0.0625, tracker.NetworkHealthPercentile(networkID, 0.25, errorsOnly), 1e-9)
+		assert.InDelta(t, 0.25, tracker.NetworkHealthPercentile(networkID, 0.5, errorsOnly), 1e-9)
+		assert.InDelta(t, 1.0, tracker.NetworkHealthPercentile(networkID, 1, errorsOnly), 1e-9)
+		// Out of range percentiles are clamped
+		assert.InDelta(t, 1.0, tracker.NetworkHealthPercentile(networkID, 2, errorsOnly), 1e-9)
+	})
+
+	t.Run("CordonedUpstreamsScoreZero", func(t *testing.T) {
+		tracker := newFleet(t)
+		tracker.Cordon("ups0", networkID, "*", "maintenance")
+
+		// The best upstream is out, the median drops from 0.25 to 0.0625
+		assert.InDelta(t, 0.0625, tracker.NetworkHealthPercentile(networkID, 0.5, errorsOnly), 1e-9)
+	})
+
+	t.Run("HealthyFleetScoresOne", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		for i := 0; i < 3; i++ {
+			recordRequests(tracker, networkID, fmt.Sprintf("ups%d", i), "eth_call", 10, 0)
+		}
+		assert.Equal(t, 1.0, tracker.NetworkHealthPercentile(networkID, 0.25, health.DefaultScoreWeights))
+	})
+
+	t.Run("NoUpstreamsFollowsNoDataBehavior", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		assert.Equal(t, 0.0, tracker.NetworkHealthPercentile(networkID, 0.25, errorsOnly))
+
+		tracker.SetNoDataBehavior(health.NoDataNaN)
+		assert.True(t, math.IsNaN(tracker.NetworkHealthPercentile(networkID, 0.25, errorsOnly)))
+	})
+}
@@ -0,0 +1,86 @@
+package health
+
+import (
+	"math"
+	"time"
+)
+
+// DefaultBaselineRequests is the weight, in requests, of a baseline seeded without one.
+const DefaultBaselineRequests = 10
+
+// BaselineMetrics is the assumed health of a key before it served traffic, see SeedBaseline.
+type BaselineMetrics struct {
+	// Requests is the weight of the baseline, i.e. the real requests after which it is washed out,
+	// DefaultBaselineRequests when not positive
+	Requests int64
+	// ErrorRate assumed, within [0, 1]
+	ErrorRate float64
+	// Latency is the assumed p90 latency, zero leaves the latency unknown
+	Latency time.Duration
+}
+
+type seedBaseline struct {
+	BaselineMetrics
+	// lifetimeRequests are the lifetime requests of the key when seeded
+	lifetimeRequests int64
+}
+
+// SeedBaseline primes (ups, network, method) with a baseline, so that selection does not treat a
+// cold upstream as unknown. The baseline only shows in selection views, as synthetic requests and
+// errors added to the counters of the window and as the p90 latency until real latencies are
+// recorded. Its weight decreases with every real request, washing it out once as many real
+// requests as baseline.Requests were recorded, across window resets. The counters themselves,
+// lifetime totals and exported metrics only reflect real traffic. Seeding again replaces the
+// baseline.
+func (t *Tracker) SeedBaseline(ups, network, method string, baseline BaselineMetrics) {
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
+	if baseline.Requests <= 0 {
+		baseline.Requests = DefaultBaselineRequests
+	}
+	baseline.ErrorRate = math.Min(math.Max(baseline.ErrorRate, 0), 1)
+	m := t.getMetrics(tripletKey{ups, network, method})
+	m.seed.Store(&seedBaseline{
+		BaselineMetrics:  baseline,
+		lifetimeRequests: m.lifetime.requests.Load(),
+	})
+}
+
+// SeededRequests returns the synthetic requests of the baseline of the key not washed out yet,
+// zero when it has none, see SeedBaseline.
+func (m *TrackedMetrics) SeededRequests() int64 {
+	requests, _, _ := m.seeded()
+	return requests
+}
+
+// seeded returns the synthetic requests and errors of the baseline not washed out yet, along
+// with its latency. The baseline is dropped once washed out.
+func (m *TrackedMetrics) seeded() (requests, errors int64, latency time.Duration) {
+	s := m.seed.Load()
+	if s == nil {
+		return 0, 0, 0
+	}
+	requests = s.Requests - (m.lifetime.requests.Load() - s.lifetimeRequests)
+	if requests <= 0 {
+		m.seed.CompareAndSwap(s, nil)
+		return 0, 0, 0
+	}
+	return requests, int64(math.Round(float64(requests) * s.ErrorRate)), s.Latency
+}
+
+// withSeed adds the baseline of the key not washed out yet to a view of its real metrics.
+func (m *TrackedMetrics) withSeed(v SelectionView) SelectionView {
+	requests, errors, latency := m.seeded()
+	if requests == 0 {
+		return v
+	}
+	v.SeededRequests = requests
+	v.RequestsTotal += requests
+	v.ErrorsTotal += errors
+	v.ErrorRate = boundedRatio(v.ErrorsTotal, v.RequestsTotal)
+	if !v.HasLatency && latency > 0 {
+		v.HasLatency = true
+		v.P90Latency = latency
+	}
+	return v
+}
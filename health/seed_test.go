@@ -0,0 +1,59 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeedBaseline(t *testing.T) {
+	networkID := "evm:123"
+
+	t.Run("SeededMetricsShowInitially", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		tracker.SeedBaseline("a", networkID, "eth_call", BaselineMetrics{Requests: 20, ErrorRate: 0.1, Latency: 200 * time.Millisecond})
+
+		v := tracker.SelectionView("a", networkID, "eth_call")
+		assert.Equal(t, int64(20), v.SeededRequests)
+		assert.Equal(t, int64(20), v.RequestsTotal)
+		assert.Equal(t, int64(2), v.ErrorsTotal)
+		assert.InDelta(t, 0.1, v.ErrorRate, 1e-9)
+		assert.True(t, v.HasLatency)
+		assert.Equal(t, 200*time.Millisecond, v.P90Latency)
+
+		// Only selection sees the baseline
+		m := tracker.GetUpstreamMethodMetrics("a", networkID, "eth_call")
+		assert.Zero(t, m.RequestsTotal.Load())
+		assert.Equal(t, LifetimeTotals{}, m.Lifetime())
+		assert.Zero(t, tracker.SelectionView("a", networkID, "*").RequestsTotal)
+
+		// Survives window resets without traffic
+		tracker.rollWindow(time.Now())
+		assert.Equal(t, int64(20), tracker.SelectionView("a", networkID, "eth_call").SeededRequests)
+	})
+
+	t.Run("RealDataOverridesTheBaseline", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		tracker.SeedBaseline("a", networkID, "eth_call", BaselineMetrics{ErrorRate: 0, Latency: 50 * time.Millisecond})
+
+		for i := 0; i < 4; i++ {
+			tracker.RecordOutcome("a", networkID, "eth_call", Outcome{Kind: OutcomeFailure, Duration: time.Second})
+		}
+		v := tracker.SelectionView("a", networkID, "eth_call")
+		assert.Equal(t, int64(DefaultBaselineRequests-4), v.SeededRequests)
+		assert.Equal(t, int64(DefaultBaselineRequests), v.RequestsTotal)
+		assert.InDelta(t, 0.4, v.ErrorRate, 1e-9, "real errors diluted by the baseline left")
+		assert.Equal(t, time.Second, v.P90Latency.Round(100*time.Millisecond), "real latencies replace the baseline one")
+
+		for i := 0; i < DefaultBaselineRequests-4; i++ {
+			tracker.RecordOutcome("a", networkID, "eth_call", Outcome{Kind: OutcomeFailure, Duration: time.Second})
+		}
+		v = tracker.SelectionView("a", networkID, "eth_call")
+		assert.Zero(t, v.SeededRequests)
+		assert.Equal(t, int64(DefaultBaselineRequests), v.RequestsTotal)
+		assert.Equal(t, 1.0, v.ErrorRate)
+		assert.Nil(t, tracker.GetUpstreamMethodMetrics("a", networkID, "eth_call").seed.Load(), "dropped once washed out")
+	})
+}
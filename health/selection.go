@@ -0,0 +1,56 @@
+package health
+
+import (
+	"math"
+	"sync"
+)
+
+// RecordSelection records that an upstream was picked to serve a request of a method, whether as
+// the first choice, a retry or a hedge, see SelectionEntropy.
+func (t *Tracker) RecordSelection(ups, network, method string) {
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
+	for _, k := range t.getKeys(ups, network, method) {
+		t.getMetrics(k).SelectionsTotal.Add(1)
+	}
+}
+
+// SelectionEntropy returns the Shannon entropy of the selections of the upstreams of a network
+// for a method (or "*") within the current window, normalized by the one of an even spread so
+// that 1 means traffic is evenly spread and 0 that it collapsed onto a single upstream. Shadow
+// upstreams are left out. Until there are two upstreams and a selection to compare, traffic is
+// deemed evenly spread and 1 is returned.
+func (t *Tracker) SelectionEntropy(network, method string) float64 {
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
+	set, ok := t.networkUpstreams.Load(network)
+	if !ok {
+		return 1
+	}
+	var counts []int64
+	var total int64
+	set.(*sync.Map).Range(func(key, _ any) bool {
+		ups := key.(string)
+		if t.IsShadowUpstream(ups) {
+			return true
+		}
+		var n int64
+		if val, ok := t.metrics.Load(tripletKey{ups, network, method}); ok {
+			n = val.(*TrackedMetrics).SelectionsTotal.Load()
+		}
+		counts = append(counts, n)
+		total += n
+		return true
+	})
+	if len(counts) < 2 || total == 0 {
+		return 1
+	}
+	entropy := 0.0
+	for _, n := range counts {
+		if n > 0 {
+			p := float64(n) / float64(total)
+			entropy -= p * math.Log(p)
+		}
+	}
+	return entropy / math.Log(float64(len(counts)))
+}
@@ -0,0 +1,64 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectionEntropy(t *testing.T) {
+	networkID := "evm:123"
+	method := "eth_call"
+	selectN := func(tracker *Tracker, counts map[string]int) {
+		for ups, n := range counts {
+			tracker.RecordUpstreamRequest(ups, networkID, method)
+			for i := 0; i < n; i++ {
+				tracker.RecordSelection(ups, networkID, method)
+			}
+		}
+	}
+
+	t.Run("EvenSpread", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		selectN(tracker, map[string]int{"a": 25, "b": 25, "c": 25, "d": 25})
+		assert.InDelta(t, 1.0, tracker.SelectionEntropy(networkID, method), 1e-9)
+		assert.InDelta(t, 1.0, tracker.SelectionEntropy(networkID, "*"), 1e-9)
+	})
+
+	t.Run("SkewedSpread", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		selectN(tracker, map[string]int{"a": 70, "b": 10, "c": 10, "d": 10})
+		// -(0.7 ln 0.7 + 3 * 0.1 ln 0.1) / ln 4
+		assert.InDelta(t, 0.6784, tracker.SelectionEntropy(networkID, method), 1e-4)
+	})
+
+	t.Run("CollapsedOntoOneUpstream", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		selectN(tracker, map[string]int{"a": 100, "b": 0, "c": 0})
+		assert.Equal(t, 0.0, tracker.SelectionEntropy(networkID, method))
+	})
+
+	t.Run("ShadowUpstreamsAreLeftOut", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		tracker.SetUpstreamShadow("shadow", true)
+		selectN(tracker, map[string]int{"a": 10, "b": 10, "shadow": 0})
+		assert.InDelta(t, 1.0, tracker.SelectionEntropy(networkID, method), 1e-9)
+	})
+
+	t.Run("NothingToCompareIsEven", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		assert.Equal(t, 1.0, tracker.SelectionEntropy(networkID, method))
+		selectN(tracker, map[string]int{"a": 10})
+		assert.Equal(t, 1.0, tracker.SelectionEntropy(networkID, method))
+	})
+
+	t.Run("ResetWithTheWindow", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		selectN(tracker, map[string]int{"a": 10, "b": 0})
+		assert.Equal(t, 0.0, tracker.SelectionEntropy(networkID, method))
+		tracker.rollWindow(time.Now())
+		assert.Equal(t, 1.0, tracker.SelectionEntropy(networkID, method))
+	})
+}
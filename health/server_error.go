@@ -0,0 +1,39 @@
+package health
+
+import "time"
+
+// ServerErrorLatencyQuantiles returns the quantiles of the durations of the requests of (ups,
+// network, method) answered with a 5xx status within the window, or nil when none was ever
+// recorded. Only outcomes recorded with RecordOutcome (or Timer.ObserveOutcome) carry a status.
+func (t *Tracker) ServerErrorLatencyQuantiles(ups, network, method string) *QuantileTracker {
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
+	val, ok := t.metrics.Load(tripletKey{ups, network, method})
+	if !ok {
+		return nil
+	}
+	return val.(*TrackedMetrics).serverErrorQuantiles.Load()
+}
+
+// ShouldFastFail tells whether the p90 duration of the 5xx responses of (ups, network, method)
+// within the window reached threshold, i.e. the upstream is slowly failing and waiting on it is
+// better avoided (e.g. with a shorter timeout or backoff). It is false without 5xx responses or
+// with a zero threshold.
+func (t *Tracker) ShouldFastFail(ups, network, method string, threshold time.Duration) bool {
+	if threshold <= 0 {
+		return false
+	}
+	qt := t.ServerErrorLatencyQuantiles(ups, network, method)
+	if qt == nil || !qt.HasSamples() {
+		return false
+	}
+	return qt.GetQuantile(0.90) >= threshold
+}
+
+func (m *TrackedMetrics) serverErrorQuantilesOrNew() *QuantileTracker {
+	if qt := m.serverErrorQuantiles.Load(); qt != nil {
+		return qt
+	}
+	m.serverErrorQuantiles.CompareAndSwap(nil, m.newQuantileTracker())
+	return m.serverErrorQuantiles.Load()
+}
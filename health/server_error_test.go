@@ -0,0 +1,65 @@
+package health_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerErrorLatency(t *testing.T) {
+	networkID := "evm:123"
+	method := "eth_call"
+	serverError := func(d time.Duration) health.Outcome {
+		return health.Outcome{Kind: health.OutcomeFailure, HttpStatus: 503, Duration: d, Err: errors.New("unavailable")}
+	}
+
+	t.Run("SlowServerErrorsFastFail", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		for i := 0; i < 10; i++ {
+			tracker.RecordOutcome("a", networkID, method, serverError(8*time.Second))
+		}
+		// Fast successes do not dilute the latency of the 5xx responses
+		for i := 0; i < 50; i++ {
+			tracker.RecordOutcome("a", networkID, method, health.Outcome{Kind: health.OutcomeSuccess, HttpStatus: 200, Duration: 20 * time.Millisecond})
+		}
+
+		qt := tracker.ServerErrorLatencyQuantiles("a", networkID, method)
+		require.NotNil(t, qt)
+		assert.InDelta(t, 8, qt.GetQuantile(0.90).Seconds(), 0.1)
+		assert.True(t, tracker.ShouldFastFail("a", networkID, method, 5*time.Second))
+		assert.True(t, tracker.ShouldFastFail("a", networkID, "*", 5*time.Second))
+		assert.False(t, tracker.ShouldFastFail("a", networkID, method, 10*time.Second))
+		assert.False(t, tracker.ShouldFastFail("a", networkID, method, 0))
+	})
+
+	t.Run("FastServerErrorsDoNotFastFail", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		for i := 0; i < 10; i++ {
+			tracker.RecordOutcome("a", networkID, method, serverError(50*time.Millisecond))
+		}
+		// Slow outcomes without a 5xx status are not server errors
+		tracker.RecordOutcome("a", networkID, method, health.Outcome{Kind: health.OutcomeFailure, HttpStatus: 429, Duration: 10 * time.Second})
+		tracker.RecordOutcome("a", networkID, method, health.Outcome{Kind: health.OutcomeSuccess, Duration: 10 * time.Second})
+
+		assert.InDelta(t, 0.05, tracker.ServerErrorLatencyQuantiles("a", networkID, method).GetQuantile(0.99).Seconds(), 0.005)
+		assert.False(t, tracker.ShouldFastFail("a", networkID, method, time.Second))
+	})
+
+	t.Run("NoServerErrorsNoFastFail", func(t *testing.T) {
+		tracker, clock := newFakeClockTracker(t, time.Minute)
+		assert.Nil(t, tracker.ServerErrorLatencyQuantiles("a", networkID, method))
+		assert.False(t, tracker.ShouldFastFail("a", networkID, method, time.Second))
+
+		// Reset with the window
+		tracker.RecordOutcome("a", networkID, method, serverError(8*time.Second))
+		assert.True(t, tracker.ShouldFastFail("a", networkID, method, time.Second))
+		clock.Advance(time.Minute)
+		assert.Eventually(t, func() bool {
+			return !tracker.ShouldFastFail("a", networkID, method, time.Second)
+		}, time.Second, time.Millisecond)
+	})
+}
@@ -0,0 +1,28 @@
+package health
+
+// SetUpstreamShadow marks an upstream as a shadow, i.e. a candidate served a mirrored sample of
+// the traffic to be evaluated before production. Its requests are recorded on its own keys like
+// any other upstream, but kept out of the network request aggregates so that mirrored traffic
+// never skews the health of the network; its block heads still count towards the network head.
+// It applies to every network of the upstream.
+func (t *Tracker) SetUpstreamShadow(ups string, shadow bool) {
+	if !shadow {
+		if _, loaded := t.shadowUpstreams.LoadAndDelete(ups); loaded {
+			t.shadowUpstreamsCount.Add(-1)
+		}
+		return
+	}
+	if _, loaded := t.shadowUpstreams.LoadOrStore(ups, struct{}{}); !loaded {
+		t.shadowUpstreamsCount.Add(1)
+		t.logger.Debug().Str("upstream", ups).Msg("marking upstream as shadow in tracker")
+	}
+}
+
+// IsShadowUpstream tells if an upstream was marked as a shadow with SetUpstreamShadow.
+func (t *Tracker) IsShadowUpstream(ups string) bool {
+	if t.shadowUpstreamsCount.Load() == 0 {
+		return false
+	}
+	_, ok := t.shadowUpstreams.Load(ups)
+	return ok
+}
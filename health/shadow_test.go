@@ -0,0 +1,36 @@
+package health_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShadowUpstream(t *testing.T) {
+	networkID := "evm:123"
+	tracker, _ := newFakeClockTracker(t, time.Minute)
+	tracker.SetUpstreamShadow("candidate", true)
+
+	recordRequests(tracker, networkID, "prod", "eth_call", 10, 1)
+	recordRequests(tracker, networkID, "candidate", "eth_call", 30, 30)
+
+	// The shadow has metrics of its own, like any upstream
+	assert.Equal(t, int64(30), tracker.GetUpstreamMethodMetrics("candidate", networkID, "eth_call").RequestsTotal.Load())
+	assert.Equal(t, int64(30), tracker.GetUpstreamMethodMetrics("candidate", networkID, "*").ErrorsTotal.Load())
+
+	// but none of them reach the network aggregates
+	assert.Equal(t, int64(10), tracker.GetNetworkMethodMetrics(networkID, "eth_call").RequestsTotal.Load())
+	assert.Equal(t, int64(1), tracker.GetNetworkMethodMetrics(networkID, "*").ErrorsTotal.Load())
+	assert.InDelta(t, 0.1, tracker.WeightedNetworkErrorRate(networkID, "eth_call"), 1e-9)
+
+	snapshots := tracker.GetNetworkUpstreamsMetrics(networkID, "eth_call")
+	assert.True(t, snapshots["candidate"].Shadow)
+	assert.InDelta(t, 1, snapshots["candidate"].ErrorRate, 1e-9)
+	assert.False(t, snapshots["prod"].Shadow)
+
+	tracker.SetUpstreamShadow("candidate", false)
+	assert.False(t, tracker.IsShadowUpstream("candidate"))
+	recordRequests(tracker, networkID, "candidate", "eth_call", 5, 0)
+	assert.Equal(t, int64(15), tracker.GetNetworkMethodMetrics(networkID, "eth_call").RequestsTotal.Load())
+}
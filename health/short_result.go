@@ -0,0 +1,62 @@
+package health
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/erpc/erpc/telemetry"
+)
+
+// CordonReasonShortResults is the reason of cordons applied by SetShortResultCordon.
+const CordonReasonShortResults = "ShortResults"
+
+// SetShortResultCordon enables cordoning an upstream for a method once it returned fewer entries
+// than the majority in at least minIncidents comparisons within the current window, with reason
+// CordonReasonShortResults. A zero minIncidents disables it.
+func (t *Tracker) SetShortResultCordon(minIncidents int64) {
+	t.shortResultCordon.Store(max(minIncidents, 0))
+}
+
+// recordShortResults records a short result incident for each participant of a comparison whose
+// list result has fewer entries than the one of the majority, e.g. a provider silently capping
+// eth_getLogs results. Network and method must already be canonical.
+func (t *Tracker) recordShortResults(network, method string, participants []ConsensusParticipant, majority ConsensusParticipant) {
+	if !majority.IsList {
+		return
+	}
+	for _, p := range participants {
+		if !p.IsList || p.ResultHash == "" || p.ResultHash == majority.ResultHash || p.ListLength >= majority.ListLength {
+			continue
+		}
+		missing := int64(majority.ListLength - p.ListLength)
+		for _, k := range t.getKeys(p.Upstream, network, method) {
+			m := t.getMetrics(k)
+			m.ShortResultsTotal.Add(1)
+			m.ShortResultMissingTotal.Add(missing)
+		}
+		vendor := t.upstreamVendor(p.Upstream, network)
+		telemetry.MetricUpstreamShortResultTotal.WithLabelValues(t.projectId, network, p.Upstream, vendor, method).Inc()
+		telemetry.MetricUpstreamShortResultMissingTotal.WithLabelValues(t.projectId, network, p.Upstream, vendor, method).Add(float64(missing))
+		t.evaluateShortResultCordon(p.Upstream, network, method)
+	}
+}
+
+// evaluateShortResultCordon cordons (ups, network, method) once its short result incidents reach
+// the threshold set by SetShortResultCordon.
+func (t *Tracker) evaluateShortResultCordon(ups, network, method string) {
+	minIncidents := t.shortResultCordon.Load()
+	if minIncidents <= 0 {
+		return
+	}
+	m := t.getMetrics(tripletKey{ups, network, method})
+	incidents := m.ShortResultsTotal.Load()
+	if incidents < minIncidents {
+		return
+	}
+	t.autoCordonWithInfo(ups, network, method, CordonInfo{
+		Reason:    CordonReasonShortResults,
+		Detail:    fmt.Sprintf("%d short results missing %d entries in total (threshold %d)", incidents, m.ShortResultMissingTotal.Load(), minIncidents),
+		Source:    CordonSourceTracker,
+		ExpiresAt: time.Unix(0, t.windowStart.Load()).Add(t.windowSize),
+	})
+}
@@ -0,0 +1,88 @@
+package health_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShortResults(t *testing.T) {
+	networkID := "evm:123"
+	method := "eth_getLogs"
+	compare := func(lengths map[string]int) health.ConsensusComparison {
+		c := health.ConsensusComparison{Network: networkID, Method: method}
+		for _, ups := range []string{"a", "b", "c", "d"} {
+			if n, ok := lengths[ups]; ok {
+				c.Participants = append(c.Participants, health.ConsensusParticipant{
+					Upstream:   ups,
+					ResultHash: fmt.Sprintf("0x%x", n),
+					IsList:     true,
+					ListLength: n,
+				})
+			}
+		}
+		return c
+	}
+
+	t.Run("CountsEntriesMissingFromTheMajority", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		tracker.RecordConsensusComparison(compare(map[string]int{"a": 9, "b": 9, "c": 5, "d": 9}))
+		tracker.RecordConsensusComparison(compare(map[string]int{"a": 8, "b": 8, "c": 6}))
+
+		c := tracker.GetUpstreamMethodMetrics("c", networkID, method)
+		assert.Equal(t, int64(2), c.ShortResultsTotal.Load())
+		assert.Equal(t, int64(6), c.ShortResultMissingTotal.Load())
+		assert.Equal(t, int64(2), c.ConsensusMinorityTotal.Load())
+		assert.Equal(t, int64(0), tracker.GetUpstreamMethodMetrics("a", networkID, method).ShortResultsTotal.Load())
+
+		snapshot := tracker.GetNetworkUpstreamsMetrics(networkID, method)
+		require.Contains(t, snapshot, "c")
+		assert.Equal(t, int64(2), snapshot["c"].ShortResultsTotal)
+		assert.Equal(t, int64(6), snapshot["c"].ShortResultMissingTotal)
+
+		b, err := c.MarshalJSON()
+		require.NoError(t, err)
+		assert.Contains(t, string(b), `"shortResultMissingTotal":6`)
+	})
+
+	t.Run("LongerOrNonListResultsAreNotShort", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		tracker.RecordConsensusComparison(compare(map[string]int{"a": 4, "b": 4, "c": 7}))
+		tracker.RecordConsensusComparison(health.ConsensusComparison{
+			Network: networkID,
+			Method:  method,
+			Participants: []health.ConsensusParticipant{
+				{Upstream: "a", ResultHash: "0x1"},
+				{Upstream: "b", ResultHash: "0x1"},
+				{Upstream: "c", ResultHash: "0x2"},
+			},
+		})
+
+		c := tracker.GetUpstreamMethodMetrics("c", networkID, method)
+		assert.Equal(t, int64(2), c.ConsensusMinorityTotal.Load())
+		assert.Equal(t, int64(0), c.ShortResultsTotal.Load())
+	})
+
+	t.Run("CordonsAfterRepeatedIncidents", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		tracker.SetShortResultCordon(3)
+
+		tracker.RecordConsensusComparison(compare(map[string]int{"a": 9, "b": 9, "c": 5}))
+		tracker.RecordConsensusComparison(compare(map[string]int{"a": 9, "b": 9, "c": 5}))
+		assert.False(t, tracker.IsCordoned("c", networkID, method), "below the minimum incidents")
+
+		tracker.RecordConsensusComparison(compare(map[string]int{"a": 9, "b": 9, "c": 5}))
+		assert.True(t, tracker.IsCordoned("c", networkID, method))
+		assert.False(t, tracker.IsCordoned("c", networkID, "eth_call"))
+
+		info := tracker.GetCordonInfo("c", networkID, method)
+		require.NotNil(t, info)
+		assert.Equal(t, health.CordonReasonShortResults, info.Reason)
+		assert.Equal(t, health.CordonSourceTracker, info.Source)
+		assert.Contains(t, info.Detail, "3 short results missing 12 entries")
+	})
+}
@@ -0,0 +1,47 @@
+package health
+
+import "time"
+
+// GoodRequestRate is the fraction of the requests of (ups, network, method) within the window
+// which both succeeded and completed within latencyThreshold, combining availability and latency
+// in a single SLI. Only requests recorded with RecordOutcome (or Timer.ObserveOutcome) can be
+// told good, since the other recorders never see the outcome and the duration together. The
+// latency comparison is as accurate as the quantile sketches (1% relative error).
+func (t *Tracker) GoodRequestRate(ups, network, method string, latencyThreshold time.Duration) float64 {
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
+	val, ok := t.metrics.Load(tripletKey{ups, network, method})
+	if !ok {
+		return 0
+	}
+	m := val.(*TrackedMetrics)
+	requests := m.RequestsTotal.Load()
+	qt := m.successQuantiles.Load()
+	if qt == nil || requests <= 0 {
+		return 0
+	}
+	good := qt.countAtOrBelow(latencyThreshold.Seconds())
+	return min(good/float64(requests), 1)
+}
+
+func (m *TrackedMetrics) successQuantilesOrNew() *QuantileTracker {
+	if qt := m.successQuantiles.Load(); qt != nil {
+		return qt
+	}
+	m.successQuantiles.CompareAndSwap(nil, m.newQuantileTracker())
+	return m.successQuantiles.Load()
+}
+
+// countAtOrBelow returns how many of the values added are lower or equal to value.
+func (q *QuantileTracker) countAtOrBelow(value float64) float64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var count float64
+	q.currentLocked().ForEach(func(v, c float64) bool {
+		if v <= value {
+			count += c
+		}
+		return false
+	})
+	return count
+}
@@ -0,0 +1,81 @@
+package health_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health"
+	"github.com/erpc/erpc/health/healthtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGoodRequestRate(t *testing.T) {
+	networkID := "evm:123"
+	outcome := func(kind health.OutcomeKind, d time.Duration) health.Outcome {
+		o := health.Outcome{Kind: kind, Duration: d}
+		if kind == health.OutcomeFailure {
+			o.Err = errors.New("boom")
+		}
+		return o
+	}
+
+	cases := []struct {
+		name      string
+		outcomes  []health.Outcome
+		threshold time.Duration
+		expected  float64
+	}{
+		{"AllFastSuccesses", []health.Outcome{
+			outcome(health.OutcomeSuccess, 10*time.Millisecond),
+			outcome(health.OutcomeSuccess, 20*time.Millisecond),
+		}, 100 * time.Millisecond, 1},
+		{"SlowSuccessesAreNotGood", []health.Outcome{
+			outcome(health.OutcomeSuccess, 10*time.Millisecond),
+			outcome(health.OutcomeSuccess, 500*time.Millisecond),
+			outcome(health.OutcomeSuccess, 2*time.Second),
+			outcome(health.OutcomeSuccess, 50*time.Millisecond),
+		}, 100 * time.Millisecond, 0.5},
+		{"FastFailuresAreNotGood", []health.Outcome{
+			outcome(health.OutcomeSuccess, 10*time.Millisecond),
+			outcome(health.OutcomeFailure, 5*time.Millisecond),
+			outcome(health.OutcomeFailure, 5*time.Millisecond),
+			outcome(health.OutcomeNonCriticalError, 5*time.Millisecond),
+		}, 100 * time.Millisecond, 0.25},
+		{"Mixed", []health.Outcome{
+			outcome(health.OutcomeSuccess, 10*time.Millisecond),
+			outcome(health.OutcomeSuccess, 90*time.Millisecond),
+			outcome(health.OutcomeSuccess, 300*time.Millisecond),
+			outcome(health.OutcomeFailure, 10*time.Millisecond),
+			outcome(health.OutcomeFailure, 3*time.Second),
+		}, 100 * time.Millisecond, 0.4},
+		{"CancelledCountAsRequests", []health.Outcome{
+			outcome(health.OutcomeSuccess, 10*time.Millisecond),
+			{Kind: health.OutcomeCancelled},
+		}, 100 * time.Millisecond, 0.5},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tracker, _ := newFakeClockTracker(t, time.Minute)
+			for _, o := range tc.outcomes {
+				tracker.RecordOutcome("a", networkID, "eth_call", o)
+			}
+			assert.InDelta(t, tc.expected, tracker.GoodRequestRate("a", networkID, "eth_call", tc.threshold), 1e-9)
+			assert.InDelta(t, tc.expected, tracker.GoodRequestRate("a", networkID, "*", tc.threshold), 1e-9)
+		})
+	}
+
+	t.Run("NoRequests", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		assert.Equal(t, 0.0, tracker.GoodRequestRate("a", networkID, "eth_call", time.Second))
+	})
+
+	t.Run("ResetWithTheWindow", func(t *testing.T) {
+		tracker, clock := newFakeClockTracker(t, time.Minute)
+		tracker.RecordOutcome("a", networkID, "eth_call", outcome(health.OutcomeSuccess, 10*time.Millisecond))
+		healthtest.AdvanceWindow(t, clock, time.Minute, tracker.GetUpstreamMethodMetrics("a", networkID, "eth_call"))
+
+		tracker.RecordOutcome("a", networkID, "eth_call", outcome(health.OutcomeFailure, 10*time.Millisecond))
+		assert.Equal(t, 0.0, tracker.GoodRequestRate("a", networkID, "eth_call", time.Second))
+	})
+}
@@ -0,0 +1,314 @@
+package health
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/erpc/erpc/telemetry"
+)
+
+const (
+	SLIAvailability = "availability"
+	SLILatency      = "latency"
+)
+
+// SLODefinition describes the objectives promised for a network, measured on upstream attempts.
+type SLODefinition struct {
+	Network string
+
+	// Ratio of successful attempts, e.g. 0.999. Zero disables the availability SLI.
+	AvailabilityTarget float64
+
+	// At most (1 - LatencyQuantile) of attempts may be slower than LatencyTarget, e.g. 500ms
+	// at 0.9 for "p90 < 500ms". A zero target disables the latency SLI.
+	LatencyTarget   time.Duration
+	LatencyQuantile float64
+
+	BurnRateAlerts []BurnRateAlert
+}
+
+// BurnRateAlert fires when the burn rate over both windows reaches the threshold. Windows are
+// rounded to a whole number of tracker windows.
+type BurnRateAlert struct {
+	ShortWindow time.Duration
+	LongWindow  time.Duration
+	Threshold   float64
+}
+
+// SLIStatus reports error budget usage of one SLI. A burn rate of 1 means the budget is being
+// consumed exactly at the pace allowed by the target, WindowBudgetConsumed is the share of the
+// budget of a single window consumed by the last completed window. BurnRates are keyed by window.
+type SLIStatus struct {
+	Target               float64            `json:"target"`
+	WindowBudgetConsumed float64            `json:"windowBudgetConsumed"`
+	BurnRates            map[string]float64 `json:"burnRates"`
+}
+
+type SLOStatus struct {
+	Network      string     `json:"network"`
+	Availability *SLIStatus `json:"availability,omitempty"`
+	Latency      *SLIStatus `json:"latency,omitempty"`
+}
+
+type sloWindow struct {
+	requests int64
+	errors   int64
+	slow     int64
+}
+
+type sloState struct {
+	def  atomic.Pointer[SLODefinition]
+	slow atomic.Int64 // slow attempts of the current window
+
+	mu      sync.Mutex
+	history []sloWindow // completed windows, most recent last
+	firing  map[string]bool
+}
+
+func (d *SLODefinition) validate() error {
+	if d.Network == "" {
+		return fmt.Errorf("slo definition requires a network")
+	}
+	if d.AvailabilityTarget < 0 || d.AvailabilityTarget >= 1 {
+		return fmt.Errorf("slo availability target for network %s must be within [0, 1), got %v", d.Network, d.AvailabilityTarget)
+	}
+	if d.LatencyTarget > 0 && (d.LatencyQuantile <= 0 || d.LatencyQuantile >= 1) {
+		return fmt.Errorf("slo latency quantile for network %s must be within (0, 1), got %v", d.Network, d.LatencyQuantile)
+	}
+	for _, a := range d.BurnRateAlerts {
+		if a.ShortWindow <= 0 || a.LongWindow < a.ShortWindow || a.Threshold <= 0 {
+			return fmt.Errorf("invalid slo burn rate alert for network %s: %+v", d.Network, a)
+		}
+	}
+	return nil
+}
+
+// SetSLODefinitions replaces all SLO definitions. It can be called at any time to reload them,
+// history of networks that stay defined is kept.
+func (t *Tracker) SetSLODefinitions(defs []*SLODefinition) error {
+	byNetwork := make(map[string]*SLODefinition, len(defs))
+	for _, d := range defs {
+		if err := d.validate(); err != nil {
+			return err
+		}
+		byNetwork[t.canonicalNetwork(d.Network)] = d
+	}
+
+	t.slos.Range(func(key, value any) bool {
+		if _, ok := byNetwork[key.(string)]; !ok {
+			t.slos.Delete(key)
+		}
+		return true
+	})
+	for network, d := range byNetwork {
+		val, _ := t.slos.LoadOrStore(network, &sloState{})
+		st := val.(*sloState)
+		st.mu.Lock()
+		st.def.Store(d)
+		st.firing = nil
+		st.mu.Unlock()
+	}
+	return nil
+}
+
+// GetNetworkSLOStatus returns the SLO status of a network as of the last completed window,
+// or nil if the network has no SLO defined.
+func (t *Tracker) GetNetworkSLOStatus(network string) *SLOStatus {
+	network = t.canonicalNetwork(network)
+	val, ok := t.slos.Load(network)
+	if !ok {
+		return nil
+	}
+	st := val.(*sloState)
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.statusLocked(network, t.windowSize)
+}
+
+// SLOSnapshot returns the SLO status of every network with an SLO defined.
+func (t *Tracker) SLOSnapshot() map[string]*SLOStatus {
+	result := make(map[string]*SLOStatus)
+	t.slos.Range(func(key, value any) bool {
+		network := key.(string)
+		if s := t.GetNetworkSLOStatus(network); s != nil {
+			result[network] = s
+		}
+		return true
+	})
+	return result
+}
+
+func (t *Tracker) observeSLODuration(network string, duration time.Duration) {
+	val, ok := t.slos.Load(network)
+	if !ok {
+		return
+	}
+	st := val.(*sloState)
+	if def := st.def.Load(); def.LatencyTarget > 0 && duration > def.LatencyTarget {
+		st.slow.Add(1)
+	}
+}
+
+// rollSLOWindows closes the current window of every SLO, it must run right before metrics are reset.
+func (t *Tracker) rollSLOWindows() {
+	t.slos.Range(func(key, value any) bool {
+		network := key.(string)
+		st := value.(*sloState)
+
+		w := sloWindow{slow: st.slow.Swap(0)}
+		if val, ok := t.metrics.Load(tripletKey{"*", network, "*"}); ok {
+			tm := val.(*TrackedMetrics)
+			w.errors, w.requests = tm.errorsAndRequests()
+		}
+
+		st.mu.Lock()
+		def := st.def.Load()
+		st.history = append(st.history, w)
+		if keep := def.historySize(t.windowSize); len(st.history) > keep {
+			st.history = st.history[len(st.history)-keep:]
+		}
+		status := st.statusLocked(network, t.windowSize)
+		events := st.evaluateAlertsLocked(network, t.windowSize)
+		st.mu.Unlock()
+
+		t.reportSLOStatus(status)
+		for _, e := range events {
+			t.emit(e)
+		}
+		return true
+	})
+}
+
+func (t *Tracker) reportSLOStatus(s *SLOStatus) {
+	for sli, st := range map[string]*SLIStatus{SLIAvailability: s.Availability, SLILatency: s.Latency} {
+		if st == nil {
+			continue
+		}
+		telemetry.MetricNetworkSLOErrorBudgetConsumed.WithLabelValues(t.projectId, s.Network, sli).Set(st.WindowBudgetConsumed)
+		for window, rate := range st.BurnRates {
+			telemetry.MetricNetworkSLOBurnRate.WithLabelValues(t.projectId, s.Network, sli, window).Set(rate)
+		}
+	}
+}
+
+// windowsFor converts a duration into a whole number of tracker windows.
+func windowsFor(d, windowSize time.Duration) int {
+	if windowSize <= 0 {
+		return 1
+	}
+	n := int((d + windowSize/2) / windowSize)
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+func (d *SLODefinition) historySize(windowSize time.Duration) int {
+	n := 1
+	for _, a := range d.BurnRateAlerts {
+		if w := windowsFor(a.LongWindow, windowSize); w > n {
+			n = w
+		}
+	}
+	return n
+}
+
+// allowedBadRatio returns the error budget of an SLI as a ratio of attempts.
+func (d *SLODefinition) allowedBadRatio(sli string) float64 {
+	if sli == SLIAvailability {
+		return 1 - d.AvailabilityTarget
+	}
+	return 1 - d.LatencyQuantile
+}
+
+func (d *SLODefinition) slis() []string {
+	var slis []string
+	if d.AvailabilityTarget > 0 {
+		slis = append(slis, SLIAvailability)
+	}
+	if d.LatencyTarget > 0 {
+		slis = append(slis, SLILatency)
+	}
+	return slis
+}
+
+// burnRateLocked computes the burn rate of an SLI over the last n completed windows.
+func (st *sloState) burnRateLocked(d *SLODefinition, sli string, n int) float64 {
+	if n > len(st.history) {
+		n = len(st.history)
+	}
+	var reqs, bad int64
+	for _, w := range st.history[len(st.history)-n:] {
+		reqs += w.requests
+		if sli == SLIAvailability {
+			bad += w.errors
+		} else {
+			bad += w.slow
+		}
+	}
+	if reqs == 0 {
+		return 0
+	}
+	return float64(bad) / float64(reqs) / d.allowedBadRatio(sli)
+}
+
+func (st *sloState) statusLocked(network string, windowSize time.Duration) *SLOStatus {
+	d := st.def.Load()
+	s := &SLOStatus{Network: network}
+	for _, sli := range d.slis() {
+		ss := &SLIStatus{
+			WindowBudgetConsumed: st.burnRateLocked(d, sli, 1),
+			BurnRates:            make(map[string]float64),
+		}
+		for _, a := range d.BurnRateAlerts {
+			for _, w := range []time.Duration{a.ShortWindow, a.LongWindow} {
+				ss.BurnRates[w.String()] = st.burnRateLocked(d, sli, windowsFor(w, windowSize))
+			}
+		}
+		if sli == SLIAvailability {
+			ss.Target = d.AvailabilityTarget
+			s.Availability = ss
+		} else {
+			ss.Target = d.LatencyQuantile
+			s.Latency = ss
+		}
+	}
+	return s
+}
+
+// evaluateAlertsLocked returns the events of alerts that started or stopped firing.
+func (st *sloState) evaluateAlertsLocked(network string, windowSize time.Duration) []Event {
+	d := st.def.Load()
+	if st.firing == nil {
+		st.firing = make(map[string]bool)
+	}
+	var events []Event
+	for _, sli := range d.slis() {
+		for i, a := range d.BurnRateAlerts {
+			short := st.burnRateLocked(d, sli, windowsFor(a.ShortWindow, windowSize))
+			long := st.burnRateLocked(d, sli, windowsFor(a.LongWindow, windowSize))
+			firing := short >= a.Threshold && long >= a.Threshold
+
+			key := fmt.Sprintf("%s|%d", sli, i)
+			if firing == st.firing[key] {
+				continue
+			}
+			st.firing[key] = firing
+			e := Event{
+				Type:      EventSLOBurnRateExceeded,
+				Network:   network,
+				Value:     short,
+				Threshold: a.Threshold,
+				Message:   fmt.Sprintf("%s burn rate %.2fx (%s) / %.2fx (%s) reached threshold %.2fx", sli, short, a.ShortWindow, long, a.LongWindow, a.Threshold),
+			}
+			if !firing {
+				e.Type = EventSLOBurnRateRecovered
+				e.Message = fmt.Sprintf("%s burn rate %.2fx (%s) / %.2fx (%s) back below threshold %.2fx", sli, short, a.ShortWindow, long, a.LongWindow, a.Threshold)
+			}
+			events = append(events, e)
+		}
+	}
+	return events
+}
@@ -0,0 +1,103 @@
+package health_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func waitEvent(t *testing.T, events <-chan health.Event) health.Event {
+	t.Helper()
+	select {
+	case e := <-events:
+		return e
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for tracker event")
+		return health.Event{}
+	}
+}
+
+func TestSLOTracking(t *testing.T) {
+	networkID := "evm:123"
+
+	t.Run("AvailabilityBurnRateAndEvents", func(t *testing.T) {
+		tracker, clock := newFakeClockTracker(t, time.Minute)
+		require.NoError(t, tracker.SetSLODefinitions([]*health.SLODefinition{{
+			Network:            networkID,
+			AvailabilityTarget: 0.99,
+			BurnRateAlerts: []health.BurnRateAlert{
+				{ShortWindow: time.Minute, LongWindow: 5 * time.Minute, Threshold: 2},
+			},
+		}}))
+		events, unsubscribe := tracker.Subscribe(10)
+		defer unsubscribe()
+
+		recordRequests(tracker, networkID, "a", "eth_call", 1000, 50)
+		clock.Advance(time.Minute)
+
+		e := waitEvent(t, events)
+		assert.Equal(t, health.EventSLOBurnRateExceeded, e.Type)
+		assert.Equal(t, networkID, e.Network)
+		assert.InDelta(t, 5, e.Value, 1e-9)
+
+		status := tracker.GetNetworkSLOStatus(networkID)
+		require.NotNil(t, status)
+		assert.Nil(t, status.Latency)
+		assert.InDelta(t, 5, status.Availability.WindowBudgetConsumed, 1e-9)
+		assert.InDelta(t, 5, status.Availability.BurnRates["1m0s"], 1e-9)
+		assert.InDelta(t, 5, status.Availability.BurnRates["5m0s"], 1e-9)
+
+		// A clean window brings the short window back below the threshold while the long one stays above
+		recordRequests(tracker, networkID, "a", "eth_call", 1000, 0)
+		clock.Advance(time.Minute)
+
+		e = waitEvent(t, events)
+		assert.Equal(t, health.EventSLOBurnRateRecovered, e.Type)
+
+		status = tracker.SLOSnapshot()[networkID]
+		assert.InDelta(t, 0, status.Availability.BurnRates["1m0s"], 1e-9)
+		assert.InDelta(t, 2.5, status.Availability.BurnRates["5m0s"], 1e-9)
+	})
+
+	t.Run("LatencyBurnRate", func(t *testing.T) {
+		tracker, clock := newFakeClockTracker(t, time.Minute)
+		require.NoError(t, tracker.SetSLODefinitions([]*health.SLODefinition{{
+			Network:         networkID,
+			LatencyTarget:   100 * time.Millisecond,
+			LatencyQuantile: 0.9,
+		}}))
+
+		recordRequests(tracker, networkID, "a", "eth_call", 100, 0)
+		for i := 0; i < 100; i++ {
+			d := 50 * time.Millisecond
+			if i < 20 {
+				d = 200 * time.Millisecond
+			}
+			tracker.RecordUpstreamDuration("a", networkID, "eth_call", d, "none")
+		}
+		clock.Advance(time.Minute)
+
+		assert.Eventually(t, func() bool {
+			s := tracker.GetNetworkSLOStatus(networkID)
+			return s.Latency != nil && s.Latency.WindowBudgetConsumed > 1.99 && s.Latency.WindowBudgetConsumed < 2.01
+		}, time.Second, time.Millisecond)
+		assert.Nil(t, tracker.GetNetworkSLOStatus(networkID).Availability)
+	})
+
+	t.Run("ReloadDefinitions", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		require.NoError(t, tracker.SetSLODefinitions([]*health.SLODefinition{{Network: networkID, AvailabilityTarget: 0.999}}))
+		assert.NotNil(t, tracker.GetNetworkSLOStatus(networkID))
+
+		require.NoError(t, tracker.SetSLODefinitions([]*health.SLODefinition{{Network: "evm:1", AvailabilityTarget: 0.99}}))
+		assert.Nil(t, tracker.GetNetworkSLOStatus(networkID))
+		assert.Equal(t, 0.99, tracker.GetNetworkSLOStatus("evm:1").Availability.Target)
+
+		err := tracker.SetSLODefinitions([]*health.SLODefinition{{Network: networkID, AvailabilityTarget: 1.5}})
+		assert.Error(t, err)
+		assert.NotNil(t, tracker.GetNetworkSLOStatus("evm:1"))
+	})
+}
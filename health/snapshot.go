@@ -0,0 +1,173 @@
+package health
+
+import (
+	"sync"
+	"time"
+)
+
+// TrackedMetricsSnapshot is a point-in-time copy of the metrics of an upstream for a method,
+// along with its cordon state, which stays untouched by later recordings and window resets.
+type TrackedMetricsSnapshot struct {
+	SelectionView
+	SelfRateLimitedTotal   int64
+	RemoteRateLimitedTotal int64
+	DisagreementRate       float64
+	MismatchRate           float64
+	PartialResponseRate    float64
+	// ShortResultsTotal are the comparisons in which the upstream returned fewer list entries than
+	// the majority, missing ShortResultMissingTotal entries in total
+	ShortResultsTotal       int64
+	ShortResultMissingTotal int64
+	// WeightedErrorRate is the error rate weighted by method importance for "*", see
+	// Tracker.WeightedErrorRate, and ErrorRate for a single method.
+	WeightedErrorRate float64
+	// Reads and Writes are the views of the method class aggregates of the upstream, see MethodClass.
+	Reads  SelectionView
+	Writes SelectionView
+	// Client is the node client reported by the upstream, zero until known
+	Client ClientVersion
+	// Ramp is the warmup ramp of the upstream, nil unless it is warming up, see SetWarmupRamp
+	Ramp *RampState
+	// Shadow tells the upstream only serves mirrored traffic, see SetUpstreamShadow
+	Shadow bool
+	// Lifetime are the totals of the key never reset with the window
+	Lifetime LifetimeTotals
+	// Uptime is the availability of the upstream over the last UptimePeriod
+	Uptime Uptime
+	// Certificate is the expiry of the TLS certificate of the upstream, unknown unless probed
+	Certificate CertificateExpiry
+	// CordonedTime is the time the upstream spent cordoned on the network as a whole
+	CordonedTime CordonedTime
+	// RealizedShare and IntendedShare are the shares of the traffic of the network served by the
+	// upstream in the window and intended by selection, see GetTrafficShares
+	RealizedShare float64
+	IntendedShare float64
+	// ChainId is the last chain id reported by the upstream, zero if never reported, and
+	// ChainIdMismatch whether it differs from the expected one, see RecordUpstreamChainId
+	ChainId         int64
+	ChainIdMismatch bool
+	// BlockHeadTimeLag is the block head lag of the upstream in time, see Tracker.BlockHeadTimeLag,
+	// zero while unknown
+	BlockHeadTimeLag time.Duration
+	// NormalizedLatencyScore is the latency of the upstream relative to the baselines of the cost
+	// classes of its methods, see Tracker.NormalizedLatencyScore, zero while unknown
+	NormalizedLatencyScore float64 `json:"normalizedLatencyScore,omitempty"`
+	// ErrorBudget is the error budget of the upstream on the network in the window, nil without
+	// one, see SetErrorBudgetConfig
+	ErrorBudget *ErrorBudgetStatus
+	// ResponseQuantiles are the response time quantiles of the key in the window
+	ResponseQuantiles QuantileSummary
+	// CordonInfo is a copy of the cordon of the key itself, nil when not cordoned
+	CordonInfo *CordonInfo
+}
+
+// indexNetworkUpstream adds the upstream of a newly tracked key to the upstreams of its network,
+// and its method to the methods of the upstream on the network.
+func (t *Tracker) indexNetworkUpstream(k tripletKey) {
+	if k.ups == "*" || k.network == "*" {
+		return
+	}
+	set, ok := t.networkUpstreams.Load(k.network)
+	if !ok {
+		set, _ = t.networkUpstreams.LoadOrStore(k.network, &sync.Map{})
+	}
+	set.(*sync.Map).Store(k.ups, struct{}{})
+
+	dk := duoKey{ups: k.ups, network: k.network}
+	methods, ok := t.upstreamMethods.Load(dk)
+	if !ok {
+		methods, _ = t.upstreamMethods.LoadOrStore(dk, &sync.Map{})
+	}
+	methods.(*sync.Map).Store(k.method, struct{}{})
+}
+
+// unindexUpstreamMethod removes the method of a dropped key from the methods of its upstream.
+func (t *Tracker) unindexUpstreamMethod(k tripletKey) {
+	if methods, ok := t.upstreamMethods.Load(duoKey{ups: k.ups, network: k.network}); ok {
+		methods.(*sync.Map).Delete(k.method)
+	}
+}
+
+// GetNetworkUpstreamsMetrics returns a snapshot of the metrics of every upstream tracked on a
+// network for a method (or "*"), keyed by upstream id, so that selection needs a single tracker
+// call per request. Upstreams that never served the method get an empty snapshot which still
+// reflects an upstream-wide cordon. Unlike the other accessors it never creates keys.
+func (t *Tracker) GetNetworkUpstreamsMetrics(network, method string) map[string]*TrackedMetricsSnapshot {
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
+	result := make(map[string]*TrackedMetricsSnapshot)
+	set, ok := t.networkUpstreams.Load(network)
+	if !ok {
+		return result
+	}
+	now := t.clock.Now()
+	interval := uptimeIntervalOf(now)
+	var weighted map[string]float64
+	if method == "*" {
+		weighted = t.weightedErrorRates(network)
+	}
+	shares := t.trafficSharesOf(network)
+	set.(*sync.Map).Range(func(key, _ any) bool {
+		ups := key.(string)
+		s := &TrackedMetricsSnapshot{}
+		if val, ok := t.metrics.Load(tripletKey{ups, network, method}); ok {
+			s = t.keySnapshotOf(val.(*TrackedMetrics), tripletKey{ups, network, method})
+			if weighted != nil {
+				s.WeightedErrorRate = weighted[ups]
+			}
+		} else {
+			s.SelectionView = t.withUpstreamCordon(SelectionView{}, ups, network, method)
+		}
+		s.Reads = t.classViewIfTracked(ups, network, MethodClassRead)
+		s.Writes = t.classViewIfTracked(ups, network, MethodClassWrite)
+		if val, ok := t.clientVersions.Load(duoKey{ups: ups, network: network}); ok {
+			s.Client = val.(ClientVersion)
+		}
+		if ramp, ok := t.warmupRampState(ups, network); ok {
+			s.Ramp = &ramp
+		}
+		s.Shadow = t.IsShadowUpstream(ups)
+		s.Uptime = t.uptimeOf(duoKey{ups: ups, network: network}, interval)
+		s.CordonedTime = t.cordonedTimeOf(duoKey{ups: ups, network: network}, now)
+		if val, ok := t.metadata.Load(duoKey{ups: ups, network: network}); ok {
+			s.Certificate = t.certificateExpiryOf(val.(*NetworkMetadata))
+		}
+		s.RealizedShare = shares.Realized[ups]
+		s.IntendedShare = shares.Intended[ups]
+		s.ChainIdMismatch, s.ChainId = t.chainIdMismatchOf(ups, network)
+		s.BlockHeadTimeLag, _ = t.blockHeadTimeLagOf(ups, network, now)
+		s.NormalizedLatencyScore, _ = t.normalizedLatencyScoreOf(ups, network)
+		if budget, ok := t.errorBudgetStatusOf(ups, network); ok {
+			s.ErrorBudget = &budget
+		}
+		result[ups] = s
+		return true
+	})
+	return result
+}
+
+// WeightedNetworkErrorRate is the error rate of a network for a method (or "*") summed over the
+// metrics of each of its upstreams, so that every upstream weighs by its request volume. Unlike
+// the ErrorRate of GetNetworkMethodMetrics it does not depend on the network aggregate, and it
+// never creates keys. Shadow upstreams are left out like in the network aggregate.
+func (t *Tracker) WeightedNetworkErrorRate(network, method string) float64 {
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
+	set, ok := t.networkUpstreams.Load(network)
+	if !ok {
+		return 0
+	}
+	var errors, requests int64
+	set.(*sync.Map).Range(func(key, _ any) bool {
+		if t.IsShadowUpstream(key.(string)) {
+			return true
+		}
+		if val, ok := t.metrics.Load(tripletKey{key.(string), network, method}); ok {
+			e, r := val.(*TrackedMetrics).errorsAndRequests()
+			errors += e
+			requests += r
+		}
+		return true
+	})
+	return boundedRatio(errors, requests)
+}
@@ -0,0 +1,122 @@
+package health_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetNetworkUpstreamsMetrics(t *testing.T) {
+	networkID := "evm:123"
+
+	t.Run("ReturnsEveryUpstreamOfTheNetwork", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		recordRequests(tracker, networkID, "a", "eth_call", 4, 1)
+		recordRequests(tracker, networkID, "b", "eth_getLogs", 2, 0)
+		recordRequests(tracker, "evm:456", "c", "eth_call", 1, 0)
+		tracker.RecordUpstreamRemoteRateLimited("a", networkID, "eth_call")
+		tracker.Cordon("b", networkID, "*", "too slow")
+
+		all := tracker.GetNetworkUpstreamsMetrics(networkID, "eth_call")
+		require.Len(t, all, 2)
+		assert.NotContains(t, all, "*")
+		assert.Equal(t, int64(4), all["a"].RequestsTotal)
+		assert.Equal(t, 0.25, all["a"].ErrorRate)
+		assert.Equal(t, int64(1), all["a"].RemoteRateLimitedTotal)
+		assert.False(t, all["a"].Cordoned)
+
+		// b never served eth_call, its snapshot is empty but carries the upstream-wide cordon
+		assert.Equal(t, int64(0), all["b"].RequestsTotal)
+		assert.True(t, all["b"].Cordoned)
+		assert.Equal(t, "too slow", all["b"].CordonedReason)
+		assert.Nil(t, tracker.GetUpstreamMetrics("b")[networkID+"|eth_call"])
+
+		wide := tracker.GetNetworkUpstreamsMetrics(networkID, "*")
+		assert.Equal(t, int64(2), wide["b"].RequestsTotal)
+	})
+
+	t.Run("SnapshotsAreDetachedFromLiveMetrics", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		recordRequests(tracker, networkID, "a", "eth_call", 1, 0)
+		s := tracker.GetNetworkUpstreamsMetrics(networkID, "eth_call")["a"]
+
+		recordRequests(tracker, networkID, "a", "eth_call", 5, 5)
+		assert.Equal(t, int64(1), s.RequestsTotal)
+		assert.Equal(t, int64(0), s.ErrorsTotal)
+	})
+
+	t.Run("UnknownNetworkIsEmpty", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		assert.Empty(t, tracker.GetNetworkUpstreamsMetrics(networkID, "eth_call"))
+	})
+}
+
+func TestWeightedNetworkErrorRate(t *testing.T) {
+	networkID := "evm:123"
+
+	t.Run("WeighsUpstreamsByVolume", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		recordRequests(tracker, networkID, "a", "eth_call", 90, 9)
+		recordRequests(tracker, networkID, "b", "eth_call", 10, 5)
+		recordRequests(tracker, networkID, "b", "eth_getLogs", 20, 20)
+		recordRequests(tracker, "evm:456", "c", "eth_call", 10, 10)
+
+		// Not the mean of 0.1 and 0.5 but 14 errors out of 100 requests
+		assert.InDelta(t, float64(9+5)/float64(90+10), tracker.WeightedNetworkErrorRate(networkID, "eth_call"), 1e-9)
+		assert.InDelta(t, float64(9+5+20)/float64(90+10+20), tracker.WeightedNetworkErrorRate(networkID, "*"), 1e-9)
+		assert.InDelta(t, tracker.GetNetworkMethodMetrics(networkID, "eth_call").ErrorRate(), tracker.WeightedNetworkErrorRate(networkID, "eth_call"), 1e-9)
+		assert.Equal(t, 0.0, tracker.WeightedNetworkErrorRate(networkID, "eth_getBalance"))
+	})
+
+	t.Run("UnknownNetworkIsZero", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		assert.Equal(t, 0.0, tracker.WeightedNetworkErrorRate(networkID, "eth_call"))
+		assert.Empty(t, tracker.GetNetworkUpstreamsMetrics(networkID, "eth_call"))
+	})
+}
+
+func TestGetUpstreamMetricsSnapshot(t *testing.T) {
+	networkID := "evm:123"
+
+	t.Run("CopiesCountersQuantilesAndCordon", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		recordRequests(tracker, networkID, "a", "eth_call", 4, 1)
+		for i := 1; i <= 100; i++ {
+			tracker.RecordUpstreamDuration("a", networkID, "eth_call", time.Duration(i)*time.Millisecond, "none")
+		}
+		tracker.Cordon("a", networkID, "eth_call", "too slow")
+
+		all := tracker.GetUpstreamMetricsSnapshot("a")
+		require.Contains(t, all, networkID+"|eth_call")
+		require.Contains(t, all, networkID+"|*")
+		s := all[networkID+"|eth_call"]
+		assert.Equal(t, int64(4), s.RequestsTotal)
+		assert.Equal(t, 0.25, s.ErrorRate)
+		assert.Equal(t, int64(100), s.ResponseQuantiles.Count)
+		// within the relative accuracy of the sketch
+		assert.InEpsilon(t, 0.050, s.ResponseQuantiles.P50.Seconds(), 0.02)
+		assert.InEpsilon(t, 0.090, s.ResponseQuantiles.P90.Seconds(), 0.02)
+		assert.InEpsilon(t, 0.099, s.ResponseQuantiles.P99.Seconds(), 0.02)
+		assert.True(t, s.Cordoned)
+		require.NotNil(t, s.CordonInfo)
+		assert.Equal(t, "too slow", s.CordonInfo.Reason)
+	})
+
+	t.Run("SnapshotsAreDetachedFromLiveMetrics", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		recordRequests(tracker, networkID, "a", "eth_call", 1, 0)
+		tracker.Cordon("a", networkID, "eth_call", "test")
+		s := tracker.GetUpstreamMetricsSnapshot("a")[networkID+"|eth_call"]
+
+		recordRequests(tracker, networkID, "a", "eth_call", 5, 5)
+		tracker.RecordUpstreamDuration("a", networkID, "eth_call", time.Second, "none")
+		tracker.Uncordon("a", networkID, "eth_call")
+		assert.Equal(t, int64(1), s.RequestsTotal)
+		assert.Equal(t, int64(0), s.ErrorsTotal)
+		assert.Equal(t, int64(0), s.ResponseQuantiles.Count)
+		assert.True(t, s.Cordoned)
+		assert.Equal(t, "test", s.CordonInfo.Reason)
+	})
+}
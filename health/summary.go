@@ -0,0 +1,27 @@
+package health
+
+import (
+	"time"
+)
+
+// HealthSummary returns the error rate, p90 latency, block head lag and cordon state of an
+// upstream on a network in the current window, e.g. to annotate the logs and spans of requests
+// it serves. Meant to be called on every request, it only loads atomics of existing keys: the
+// latency is the one cached by the tracker every second, and an unknown key returns zeros.
+func (t *Tracker) HealthSummary(ups, network string) (errRate float64, p90 time.Duration, lag int64, cordoned bool) {
+	val, ok := t.metrics.Load(tripletKey{ups, t.canonicalNetwork(network), "*"})
+	if !ok {
+		return 0, 0, 0, false
+	}
+	m := val.(*TrackedMetrics)
+	errRate = m.ErrorRate()
+	lag = max(m.BlockHeadLag.Load(), m.behindHeadEvidence.Load())
+	return errRate, time.Duration(m.summaryP90.Load()), lag, m.Cordoned.Load()
+}
+
+// refreshSummaryP90 caches the p90 latency of an upstream-wide key for HealthSummary.
+func (m *TrackedMetrics) refreshSummaryP90(k tripletKey) {
+	if k.method == "*" && k.ups != "*" {
+		m.summaryP90.Store(int64(m.ResponseQuantiles.GetQuantile(0.90)))
+	}
+}
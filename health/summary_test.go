@@ -0,0 +1,49 @@
+package health_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthSummary(t *testing.T) {
+	networkID := "evm:123"
+	tracker, clock := newFakeClockTracker(t, time.Minute)
+
+	errRate, p90, lag, cordoned := tracker.HealthSummary("a", networkID)
+	assert.Equal(t, 0.0, errRate)
+	assert.Zero(t, p90)
+	assert.Zero(t, lag)
+	assert.False(t, cordoned)
+	// Summaries never create keys
+	_, ok := tracker.LookupUpstreamMethodMetrics("a", networkID, "*")
+	assert.False(t, ok)
+
+	recordRequests(tracker, networkID, "a", "eth_call", 10, 2)
+	for i := 0; i < 10; i++ {
+		tracker.RecordUpstreamDuration("a", networkID, "eth_call", 200*time.Millisecond, "none")
+	}
+	tracker.SetLatestBlockNumber("a", networkID, 100)
+	tracker.SetLatestBlockNumber("b", networkID, 105)
+	tracker.Cordon("a", networkID, "*", "incident")
+
+	errRate, p90, lag, cordoned = tracker.HealthSummary("a", "evm:0x7b")
+	assert.InDelta(t, 0.2, errRate, 1e-9)
+	assert.Equal(t, int64(5), lag)
+	assert.True(t, cordoned)
+	// The latency is cached by the tracker every second
+	assert.Eventually(t, func() bool {
+		clock.Advance(time.Second)
+		_, p90, _, _ = tracker.HealthSummary("a", networkID)
+		return p90 > 0
+	}, time.Second, time.Millisecond)
+	assert.InDelta(t, 0.2, p90.Seconds(), 0.005)
+
+	// A method cordon does not cordon the upstream
+	_, _, _, cordoned = tracker.HealthSummary("b", networkID)
+	assert.False(t, cordoned)
+	tracker.Cordon("b", networkID, "eth_call", "incident")
+	_, _, _, cordoned = tracker.HealthSummary("b", networkID)
+	assert.False(t, cordoned)
+}
@@ -0,0 +1,158 @@
+package health
+
+import (
+	"context"
+	"runtime"
+	"sync/atomic"
+
+	"github.com/erpc/erpc/telemetry"
+)
+
+// DefaultTelemetryQueueSize is the number of recordings StartAsyncTelemetry buffers when given a
+// non-positive size.
+const DefaultTelemetryQueueSize = 8192
+
+type telemetryKind uint8
+
+const (
+	telemetrySelfRateLimited telemetryKind = iota
+	telemetryRemoteRateLimited
+	telemetryDuration
+	telemetryResponseBytes
+	telemetryOutcome
+	telemetryErrorBudgetRemaining
+)
+
+// telemetryEvent is a hot path recording to export, with every label resolved by the recorder so
+// that exporting it later yields the same series. The vendor is the exception, see
+// writeQueuedTelemetry.
+type telemetryEvent struct {
+	kind                         telemetryKind
+	network, ups, vendor, method string
+	// composite type, finality and attempt of durations, outcome, code and status of outcomes
+	labels [3]string
+	value  float64
+}
+
+type telemetryQueue struct {
+	events chan telemetryEvent
+	// senders are the recorders between their check of stopped and their enqueue
+	senders atomic.Int64
+	stopped atomic.Bool
+	stop    chan struct{}
+	// drained is closed once the queue is stopped and every enqueued recording exported
+	drained chan struct{}
+}
+
+// StartAsyncTelemetry moves the export of the hot path recordings (request durations, outcomes,
+// throttling, response bytes and error budgets) to Prometheus off the recorders, into a queue of
+// queueSize recordings drained by a dedicated goroutine, so that contention on the label vectors
+// does not add to request latency. The TrackedMetrics read by routing are still updated
+// synchronously. When the queue is full recordings are exported synchronously and counted in
+// MetricTrackerTelemetryQueueOverflowTotal. The queue is drained and stopped when ctx is done,
+// see StopAsyncTelemetry. It is a no-op while a queue is running.
+func (t *Tracker) StartAsyncTelemetry(ctx context.Context, queueSize int) {
+	if queueSize <= 0 {
+		queueSize = DefaultTelemetryQueueSize
+	}
+	q := &telemetryQueue{
+		events:  make(chan telemetryEvent, queueSize),
+		stop:    make(chan struct{}),
+		drained: make(chan struct{}),
+	}
+	if !t.telemetryQueue.CompareAndSwap(nil, q) {
+		return
+	}
+	go t.drainTelemetryQueue(ctx, q)
+}
+
+// StopAsyncTelemetry stops the queue started by StartAsyncTelemetry, returning once every
+// recording enqueued is exported. Later recordings are exported synchronously.
+func (t *Tracker) StopAsyncTelemetry() {
+	q := t.telemetryQueue.Load()
+	if q == nil {
+		return
+	}
+	if q.stopped.CompareAndSwap(false, true) {
+		close(q.stop)
+	}
+	<-q.drained
+	t.telemetryQueue.CompareAndSwap(q, nil)
+}
+
+func (t *Tracker) drainTelemetryQueue(ctx context.Context, q *telemetryQueue) {
+	defer close(q.drained)
+running:
+	for {
+		select {
+		case e := <-q.events:
+			t.writeQueuedTelemetry(e)
+		case <-ctx.Done():
+			q.stopped.Store(true)
+			break running
+		case <-q.stop:
+			break running
+		}
+	}
+	// Recorders which saw the queue running may still be enqueueing
+	for q.senders.Load() > 0 {
+		runtime.Gosched()
+	}
+	for {
+		select {
+		case e := <-q.events:
+			t.writeQueuedTelemetry(e)
+		default:
+			t.telemetryQueue.CompareAndSwap(q, nil)
+			return
+		}
+	}
+}
+
+// exportTelemetry exports a recording through the queue when running, synchronously otherwise.
+func (t *Tracker) exportTelemetry(e telemetryEvent) {
+	if q := t.telemetryQueue.Load(); q != nil {
+		q.senders.Add(1)
+		if !q.stopped.Load() {
+			select {
+			case q.events <- e:
+				q.senders.Add(-1)
+				return
+			default:
+				telemetry.MetricTrackerTelemetryQueueOverflowTotal.WithLabelValues(t.projectId).Inc()
+			}
+		}
+		q.senders.Add(-1)
+	}
+	t.writeTelemetry(e)
+}
+
+// writeQueuedTelemetry exports a recording taken off the queue under the current vendor of its
+// upstream, so that recordings queued before a vendor change do not revive the series
+// SetUpstreamAttributes removed.
+func (t *Tracker) writeQueuedTelemetry(e telemetryEvent) {
+	e.vendor = t.upstreamVendor(e.ups, e.network)
+	t.writeTelemetry(e)
+}
+
+func (t *Tracker) writeTelemetry(e telemetryEvent) {
+	switch e.kind {
+	case telemetrySelfRateLimited:
+		telemetry.MetricUpstreamSelfRateLimitedTotal.WithLabelValues(t.projectId, e.network, e.ups, e.vendor, e.method).Inc()
+	case telemetryRemoteRateLimited:
+		telemetry.MetricUpstreamRemoteRateLimitedTotal.WithLabelValues(t.projectId, e.network, e.ups, e.vendor, e.method).Inc()
+	case telemetryDuration:
+		if telemetry.MetricUpstreamRequestDuration != nil {
+			telemetry.MetricUpstreamRequestDuration.WithLabelValues(t.projectId, e.network, e.ups, e.method, e.labels[0]).Observe(e.value)
+		}
+		if telemetry.MetricUpstreamAttemptDuration != nil {
+			telemetry.MetricUpstreamAttemptDuration.WithLabelValues(t.projectId, e.network, e.ups, e.vendor, e.labels[1], e.labels[2]).Observe(e.value)
+		}
+	case telemetryResponseBytes:
+		telemetry.MetricUpstreamResponseBytesTotal.WithLabelValues(t.projectId, e.network, e.ups, e.vendor, e.method).Add(e.value)
+	case telemetryOutcome:
+		telemetry.MetricUpstreamOutcomeTotal.WithLabelValues(t.projectId, e.network, e.ups, e.vendor, e.method, e.labels[0], e.labels[1], e.labels[2]).Inc()
+	case telemetryErrorBudgetRemaining:
+		telemetry.MetricUpstreamErrorBudgetRemaining.WithLabelValues(t.projectId, e.network, e.ups, e.vendor).Set(e.value)
+	}
+}
@@ -0,0 +1,88 @@
+package health
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health/internal/metricstest"
+	"github.com/erpc/erpc/telemetry"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAsyncTelemetry(t *testing.T) {
+	networkID := "evm:123"
+
+	t.Run("StopDrainsTheQueue", func(t *testing.T) {
+		project := "test-async-telemetry"
+		labels := map[string]string{"project": project, "network": networkID, "upstream": "a"}
+		tracker := NewTracker(&log.Logger, project, time.Minute)
+		tracker.StartAsyncTelemetry(context.Background(), 0)
+		for i := 0; i < 1000; i++ {
+			tracker.RecordOutcome("a", networkID, "eth_call", Outcome{Kind: OutcomeSuccess, Duration: time.Millisecond, Bytes: 10})
+		}
+		// Local state routing reads from is never stale
+		assert.Equal(t, int64(1000), tracker.GetUpstreamMethodMetrics("a", networkID, "eth_call").RequestsTotal.Load())
+
+		tracker.StopAsyncTelemetry()
+		assert.Equal(t, 1000.0, metricstest.Value(t, "erpc_upstream_outcome_total", labels))
+		assert.Equal(t, 10000.0, metricstest.Value(t, "erpc_upstream_response_bytes_total", labels))
+		assert.Nil(t, tracker.telemetryQueue.Load())
+
+		tracker.RecordUpstreamRemoteRateLimited("a", networkID, "eth_call")
+		assert.Equal(t, 1.0, metricstest.Value(t, "erpc_upstream_request_remote_rate_limited_total", labels), "synchronous once stopped")
+	})
+
+	t.Run("ContextCancellationDrainsTheQueue", func(t *testing.T) {
+		project := "test-async-telemetry-ctx"
+		labels := map[string]string{"project": project, "network": networkID, "upstream": "a"}
+		tracker := NewTracker(&log.Logger, project, time.Minute)
+		ctx, cancel := context.WithCancel(context.Background())
+		tracker.StartAsyncTelemetry(ctx, 0)
+		for i := 0; i < 100; i++ {
+			tracker.RecordUpstreamSelfRateLimited("a", networkID, "eth_call")
+		}
+		cancel()
+		tracker.StopAsyncTelemetry()
+		assert.Equal(t, 100.0, metricstest.Value(t, "erpc_upstream_request_self_rate_limited_total", labels))
+	})
+
+	t.Run("OverflowExportsSynchronously", func(t *testing.T) {
+		project := "test-async-telemetry-overflow"
+		labels := map[string]string{"project": project, "network": networkID, "upstream": "a"}
+		tracker := NewTracker(&log.Logger, project, time.Minute)
+		// A queue nobody drains yet
+		q := &telemetryQueue{events: make(chan telemetryEvent, 1), stop: make(chan struct{}), drained: make(chan struct{})}
+		require.True(t, tracker.telemetryQueue.CompareAndSwap(nil, q))
+
+		for i := 0; i < 3; i++ {
+			tracker.RecordUpstreamRemoteRateLimited("a", networkID, "eth_call")
+		}
+		assert.Equal(t, 2.0, metricstest.Value(t, "erpc_upstream_request_remote_rate_limited_total", labels))
+		assert.Equal(t, 2.0, metricstest.Value(t, "erpc_tracker_telemetry_queue_overflow_total", map[string]string{"project": project}))
+
+		go tracker.drainTelemetryQueue(context.Background(), q)
+		tracker.StopAsyncTelemetry()
+		assert.Equal(t, 3.0, metricstest.Value(t, "erpc_upstream_request_remote_rate_limited_total", labels))
+	})
+
+	t.Run("VendorChangeDoesNotReviveSeries", func(t *testing.T) {
+		project := "test-async-telemetry-vendor"
+		t.Cleanup(func() {
+			telemetry.DeleteUpstreamVendorSeries(project, networkID, "a", "quicknode")
+		})
+		tracker := NewTracker(&log.Logger, project, time.Minute)
+		tracker.SetUpstreamAttributes("a", networkID, map[string]string{"vendor": "alchemy"})
+		q := &telemetryQueue{events: make(chan telemetryEvent, 10), stop: make(chan struct{}), drained: make(chan struct{})}
+		require.True(t, tracker.telemetryQueue.CompareAndSwap(nil, q))
+
+		tracker.RecordUpstreamSelfRateLimited("a", networkID, "eth_call")
+		tracker.SetUpstreamAttributes("a", networkID, map[string]string{"vendor": "quicknode"})
+
+		go tracker.drainTelemetryQueue(context.Background(), q)
+		tracker.StopAsyncTelemetry()
+		assert.Equal(t, []string{"quicknode"}, vendorLabels(t, "erpc_upstream_request_self_rate_limited_total", project))
+	})
+}
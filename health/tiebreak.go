@@ -0,0 +1,70 @@
+package health
+
+import (
+	"sort"
+	"sync/atomic"
+)
+
+// TieBreak controls how upstreams with identical scores are ordered by selection, see OrderTies.
+type TieBreak int32
+
+const (
+	// TieBreakAlphabetical orders tied upstreams by id (legacy behavior).
+	TieBreakAlphabetical TieBreak = iota
+	// TieBreakRoundRobin rotates tied upstreams on each call, with a cursor per network, so that
+	// the same upstream is not always tried first.
+	TieBreakRoundRobin
+	// TieBreakLowestLatency orders tied upstreams by their p90 latency for the method, upstreams
+	// without samples last.
+	TieBreakLowestLatency
+)
+
+// SetTieBreak configures how OrderTies orders upstreams with identical scores.
+func (t *Tracker) SetTieBreak(tb TieBreak) {
+	t.tieBreak.Store(int32(tb))
+}
+
+// TieBreak returns the configured tie-break mode.
+func (t *Tracker) TieBreak() TieBreak {
+	return TieBreak(t.tieBreak.Load())
+}
+
+// OrderTies orders in place upstreams that selection found tied on a network for a method,
+// according to the mode set by SetTieBreak. Every mode falls back to the upstream id so that the
+// order stays deterministic.
+func (t *Tracker) OrderTies(network, method string, tied []string) {
+	if len(tied) < 2 {
+		return
+	}
+	network = t.canonicalNetwork(network)
+	sort.Strings(tied)
+
+	switch t.TieBreak() {
+	case TieBreakRoundRobin:
+		val, ok := t.tieBreakCursors.Load(network)
+		if !ok {
+			val, _ = t.tieBreakCursors.LoadOrStore(network, &atomic.Uint64{})
+		}
+		shift := int((val.(*atomic.Uint64).Add(1) - 1) % uint64(len(tied)))
+		rotated := append(tied[shift:len(tied):len(tied)], tied[:shift]...)
+		copy(tied, rotated)
+	case TieBreakLowestLatency:
+		method = t.normalizeMethod(method)
+		p90s := make(map[string]float64, len(tied))
+		for _, ups := range tied {
+			if val, ok := t.metrics.Load(tripletKey{ups, network, method}); ok {
+				if qt := val.(*TrackedMetrics).ResponseQuantiles; qt.HasSamples() {
+					p90s[ups] = qt.GetQuantile(0.90).Seconds()
+				}
+			}
+		}
+		sort.SliceStable(tied, func(i, j int) bool {
+			pi, oki := p90s[tied[i]]
+			pj, okj := p90s[tied[j]]
+			if oki != okj {
+				return oki
+			}
+			return pi < pj
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package health_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderTies(t *testing.T) {
+	networkID := "evm:123"
+
+	t.Run("Alphabetical", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		for i := 0; i < 3; i++ {
+			tied := []string{"c", "a", "b"}
+			tracker.OrderTies(networkID, "eth_call", tied)
+			assert.Equal(t, []string{"a", "b", "c"}, tied)
+		}
+	})
+
+	t.Run("RoundRobin", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		tracker.SetTieBreak(health.TieBreakRoundRobin)
+
+		firsts := map[string]int{}
+		for i := 0; i < 30; i++ {
+			tied := []string{"c", "a", "b"}
+			tracker.OrderTies(networkID, "eth_call", tied)
+			assert.ElementsMatch(t, []string{"a", "b", "c"}, tied)
+			firsts[tied[0]]++
+		}
+		assert.Equal(t, map[string]int{"a": 10, "b": 10, "c": 10}, firsts)
+
+		// Each network has its own cursor
+		tied := []string{"b", "a"}
+		tracker.OrderTies("evm:456", "eth_call", tied)
+		assert.Equal(t, []string{"a", "b"}, tied)
+		tracker.OrderTies("evm:456", "eth_call", tied)
+		assert.Equal(t, []string{"b", "a"}, tied)
+	})
+
+	t.Run("LowestLatency", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		tracker.SetTieBreak(health.TieBreakLowestLatency)
+		tracker.RecordUpstreamDuration("a", networkID, "eth_call", 300*time.Millisecond, "none")
+		tracker.RecordUpstreamDuration("c", networkID, "eth_call", 100*time.Millisecond, "none")
+		tracker.RecordUpstreamDuration("d", networkID, "eth_getBalance", 10*time.Millisecond, "none")
+
+		tied := []string{"d", "a", "b", "c"}
+		tracker.OrderTies(networkID, "eth_call", tied)
+		// Upstreams without samples for the method go last
+		assert.Equal(t, []string{"c", "a", "b", "d"}, tied)
+	})
+}
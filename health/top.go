@@ -0,0 +1,73 @@
+package health
+
+import (
+	"sort"
+)
+
+// MetricField selects the metric keys are ranked by in TopByMetric.
+type MetricField int
+
+const (
+	MetricErrorRate MetricField = iota
+	MetricP99Latency
+	MetricBlockHeadLag
+	MetricThrottledRate
+)
+
+// ScoredKey is a tracked key with the value of the metric it was ranked by. Latencies are in seconds.
+type ScoredKey struct {
+	Upstream string  `json:"upstream"`
+	Network  string  `json:"network"`
+	Method   string  `json:"method"`
+	Value    float64 `json:"value"`
+}
+
+// TopByMetric returns up to n concrete keys (no "*" in upstream, network nor method, nor empty
+// methods or method class aggregates) with the highest value of the given metric, ties broken by
+// key. Keys without latency samples are skipped when ranking by latency. A non-positive n returns
+// every key.
+func (t *Tracker) TopByMetric(field MetricField, n int) []ScoredKey {
+	var result []ScoredKey
+	t.metrics.Range(func(key, value any) bool {
+		k := key.(tripletKey)
+		if k.ups == "*" || k.network == "*" || k.method == "" || isAggregateMethod(k.method) {
+			return true
+		}
+		m := value.(*TrackedMetrics)
+
+		var v float64
+		switch field {
+		case MetricErrorRate:
+			v = m.ErrorRate()
+		case MetricP99Latency:
+			if !m.ResponseQuantiles.HasSamples() {
+				return true
+			}
+			v = m.ResponseQuantiles.GetQuantile(0.99).Seconds()
+		case MetricBlockHeadLag:
+			v = float64(m.BlockHeadLag.Load())
+		case MetricThrottledRate:
+			v = m.ThrottledRate()
+		}
+		result = append(result, ScoredKey{Upstream: k.ups, Network: k.network, Method: k.method, Value: v})
+		return true
+	})
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Value != b.Value {
+			return a.Value > b.Value
+		}
+		if a.Upstream != b.Upstream {
+			return a.Upstream < b.Upstream
+		}
+		if a.Network != b.Network {
+			return a.Network < b.Network
+		}
+		return a.Method < b.Method
+	})
+	if n > 0 && len(result) > n {
+		result = result[:n]
+	}
+	return result
+}
@@ -0,0 +1,87 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/telemetry"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTopByMetric(t *testing.T) {
+	telemetry.SetHistogramBuckets("0.05,0.5,5,30")
+
+	record := func(tracker *Tracker, ups, method string, total, errors, throttled int, d time.Duration) {
+		for i := 0; i < total; i++ {
+			tracker.RecordUpstreamRequest(ups, "evm:1", method)
+			tracker.RecordUpstreamDuration(ups, "evm:1", method, d, "none")
+			if i < errors {
+				tracker.RecordUpstreamFailure(ups, "evm:1", method)
+			}
+			if i < throttled {
+				tracker.RecordUpstreamRemoteRateLimited(ups, "evm:1", method)
+			}
+		}
+	}
+
+	newTracker := func() *Tracker {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		record(tracker, "a", "eth_call", 100, 10, 0, 100*time.Millisecond)
+		record(tracker, "b", "eth_call", 100, 30, 5, 50*time.Millisecond)
+		record(tracker, "c", "eth_call", 100, 10, 20, 400*time.Millisecond)
+		record(tracker, "a", "eth_getLogs", 100, 0, 0, 900*time.Millisecond)
+		return tracker
+	}
+
+	keysOf := func(top []ScoredKey) []string {
+		var keys []string
+		for _, k := range top {
+			keys = append(keys, k.Upstream+"|"+k.Method)
+		}
+		return keys
+	}
+
+	t.Run("ErrorRateWithTieBreak", func(t *testing.T) {
+		top := newTracker().TopByMetric(MetricErrorRate, 0)
+		assert.Equal(t, []string{"b|eth_call", "a|eth_call", "c|eth_call", "a|eth_getLogs"}, keysOf(top))
+		assert.InDelta(t, 0.3, top[0].Value, 1e-9)
+	})
+
+	t.Run("P99Latency", func(t *testing.T) {
+		tracker := newTracker()
+		tracker.RecordUpstreamRequest("d", "evm:1", "eth_call")
+
+		top := tracker.TopByMetric(MetricP99Latency, 0)
+		assert.Equal(t, []string{"a|eth_getLogs", "c|eth_call", "a|eth_call", "b|eth_call"}, keysOf(top))
+		assert.InEpsilon(t, 0.9, top[0].Value, 0.02)
+	})
+
+	t.Run("BlockHeadLag", func(t *testing.T) {
+		tracker := newTracker()
+		tracker.SetLatestBlockNumber("a", "evm:1", 100)
+		tracker.SetLatestBlockNumber("b", "evm:1", 105)
+		tracker.SetLatestBlockNumber("c", "evm:1", 103)
+
+		top := tracker.TopByMetric(MetricBlockHeadLag, 2)
+		assert.Equal(t, []string{"a|eth_call", "a|eth_getLogs"}, keysOf(top))
+		assert.Equal(t, float64(5), top[0].Value)
+	})
+
+	t.Run("ThrottledRateCappedAtN", func(t *testing.T) {
+		top := newTracker().TopByMetric(MetricThrottledRate, 2)
+		assert.Equal(t, []string{"c|eth_call", "b|eth_call"}, keysOf(top))
+	})
+
+	t.Run("ExcludesWildcards", func(t *testing.T) {
+		tracker := newTracker()
+		// e.g. requests whose method could not be parsed
+		record(tracker, "a", "", 100, 100, 0, 100*time.Millisecond)
+		for _, k := range tracker.TopByMetric(MetricErrorRate, 0) {
+			assert.NotEqual(t, "*", k.Upstream)
+			assert.NotEqual(t, "*", k.Network)
+			assert.NotEqual(t, "*", k.Method)
+			assert.NotEmpty(t, k.Method)
+		}
+	})
+}
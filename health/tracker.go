@@ -2,6 +2,8 @@ package health
 
 import (
 	"context"
+	"math"
+	"runtime"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -30,6 +32,37 @@ type duoKey struct {
 type NetworkMetadata struct {
 	evmLatestBlockNumber    atomic.Int64
 	evmFinalizedBlockNumber atomic.Int64
+
+	// Set once the upstream reports a finalized block itself, stops deriving it from the finality depth
+	explicitFinalized atomic.Bool
+
+	// When evmLatestBlockNumber last increased (unix nanos), see NetworkHeadStalled
+	headAdvancedAt atomic.Int64
+	// Recent advances of the network head (network-level metadata only), see BlockHeadTimeLag
+	headArrivalsMu sync.Mutex
+	headArrivals   []headArrival
+
+	// Requests whose timer is not observed yet, see ShouldAdmit
+	inFlight atomic.Int64
+
+	// Certificate expiry (unix nanos, zero when unknown) and time of the last probe, see
+	// RecordCertificateExpiry
+	certNotAfter  atomic.Int64
+	certCheckedAt atomic.Int64
+	// Set once EventCertificateExpiring was emitted for the current certificate
+	certWarned atomic.Bool
+
+	// Reorgs in progress on the network and the time (unix nanos) they expire at (network-level
+	// metadata only), see BeginReorg
+	reorgs        atomic.Int32
+	reorgDeadline atomic.Int64
+
+	// Last chain id reported by the upstream and whether it differs from the expected one, see
+	// RecordUpstreamChainId
+	chainId         atomic.Int64
+	chainIdMismatch atomic.Bool
+	// Set when an operator lifted the chain id mismatch cordon, until the mismatch clears
+	chainIdCordonWaived atomic.Bool
 }
 
 type Timer struct {
@@ -38,12 +71,57 @@ type Timer struct {
 	ups           string
 	method        string
 	compositeType string
-	tracker       *Tracker
+	finality      common.DataFinalityState
+	attempt       int
+	clientId      string
+	ttfb          time.Duration
+	clock         Clock
+	tracker       MetricsTracker
+
+	// Ends the request in the in-flight count of the tracker which started the timer, if any
+	release func()
+	ended   atomic.Bool
+}
+
+// NewTimer starts a timer that reports to the given tracker once observed. It is mainly useful
+// for MetricsTracker implementations other than *Tracker.
+func NewTimer(tracker MetricsTracker, clock Clock, ups, network, method, compositeType string) *Timer {
+	if compositeType == "" {
+		compositeType = "none"
+	}
+	return &Timer{
+		start:         clock.Now(),
+		network:       network,
+		ups:           ups,
+		method:        method,
+		compositeType: compositeType,
+		finality:      common.DataFinalityStateUnknown,
+		clock:         clock,
+		tracker:       tracker,
+	}
+}
+
+// SetFinality classifies the request being timed, defaults to unknown.
+func (t *Timer) SetFinality(finality common.DataFinalityState) {
+	t.finality = finality
+}
+
+// SetAttempt sets the index of the attempt being timed (1 for the first one) reported by
+// ObserveOutcome, defaults to unknown which is accounted as a first attempt.
+func (t *Timer) SetAttempt(attempt int) {
+	t.attempt = attempt
+}
+
+// SetClientId sets the downstream client of the request being timed reported by ObserveOutcome,
+// see Outcome.ClientId.
+func (t *Timer) SetClientId(clientId string) {
+	t.clientId = clientId
 }
 
 func (t *Timer) ObserveDuration() {
-	duration := time.Since(t.start)
-	t.tracker.RecordUpstreamDuration(t.ups, t.network, t.method, duration, t.compositeType)
+	t.endInFlight()
+	duration := t.clock.Now().Sub(t.start)
+	t.tracker.RecordUpstreamFinalityDuration(t.ups, t.network, t.method, duration, t.compositeType, t.finality)
 }
 
 // ------------------------------------
@@ -55,64 +133,560 @@ type TrackedMetrics struct {
 	ErrorsTotal            atomic.Int64     `json:"errorsTotal"`
 	SelfRateLimitedTotal   atomic.Int64     `json:"selfRateLimitedTotal"`
 	RemoteRateLimitedTotal atomic.Int64     `json:"remoteRateLimitedTotal"`
+	UnsupportedMethodTotal atomic.Int64     `json:"unsupportedMethodTotal"`
 	RequestsTotal          atomic.Int64     `json:"requestsTotal"`
 	BlockHeadLag           atomic.Int64     `json:"blockHeadLag"`
 	FinalizationLag        atomic.Int64     `json:"finalizationLag"`
 	BlockHeadLargeRollback atomic.Int64     `json:"blockHeadLargeRollback"`
 	Cordoned               atomic.Bool      `json:"cordoned"`
-	CordonedReason         atomic.Value     `json:"cordonedReason"`
+
+	// Details of the current cordon, nil when not cordoned, see CordonInfo
+	cordonInfo atomic.Pointer[CordonInfo]
+
+	// Response quantiles per common.DataFinalityState, lazily allocated
+	FinalityQuantiles [4]atomic.Pointer[QuantileTracker]
+
+	// Response quantiles of first attempts (index 0) and retries (index 1), lazily allocated
+	AttemptQuantiles [2]atomic.Pointer[QuantileTracker]
+
+	// Response quantiles per composite type, lazily allocated, see GetCompositeTypeQuantiles
+	compositeQuantiles [len(compositeTypes)]atomic.Pointer[QuantileTracker]
+
+	// Time to first byte quantiles, only allocated once a TTFB is reported
+	ttfbQuantiles atomic.Pointer[QuantileTracker]
+
+	// Durations of successful requests recorded with RecordOutcome, see GoodRequestRate
+	successQuantiles atomic.Pointer[QuantileTracker]
+
+	// Durations of 5xx responses recorded with RecordOutcome, see ServerErrorLatencyQuantiles
+	serverErrorQuantiles atomic.Pointer[QuantileTracker]
+
+	// Last errors of exact keys, only allocated once an error is recorded, see RecentErrors
+	recentErrors atomic.Pointer[errorRing]
+
+	// Staleness of served blocks, only allocated once one is recorded, see RecordServedBlock
+	servedStaleness atomic.Pointer[stalenessHistogram]
+
+	// Responses unusable as JSON-RPC responses, see RecordUpstreamMalformedResponse
+	MalformedResponsesTotal atomic.Int64 `json:"malformedResponsesTotal"`
+	malformedByKind         [len(malformedKinds)]atomic.Int64
+
+	// Errors telling the upstream did not sync the requested block yet, see
+	// RecordUpstreamBehindHeadError, and the head lag they evidence within the window
+	BehindHeadErrorsTotal atomic.Int64 `json:"behindHeadErrorsTotal"`
+	behindHeadEvidence    atomic.Int64
+
+	// Comparisons with other upstreams in the majority or the minority, see RecordConsensusComparison
+	ConsensusMajorityTotal atomic.Int64 `json:"consensusMajorityTotal"`
+	ConsensusMinorityTotal atomic.Int64 `json:"consensusMinorityTotal"`
+
+	// Responses found to be the outlier by a cross-check, see RecordUpstreamMismatch
+	MismatchesTotal atomic.Int64 `json:"mismatchesTotal"`
+
+	// Incomplete responses, e.g. truncated eth_getLogs results, see RecordUpstreamPartialResponse
+	PartialResponsesTotal atomic.Int64 `json:"partialResponsesTotal"`
+
+	// Comparisons in which the list result had fewer entries than the majority, and the entries
+	// missing in total, see RecordConsensusComparison
+	ShortResultsTotal       atomic.Int64 `json:"shortResultsTotal"`
+	ShortResultMissingTotal atomic.Int64 `json:"shortResultMissingTotal"`
+
+	// Times the upstream was picked to serve a request, see RecordSelection
+	SelectionsTotal atomic.Int64 `json:"selectionsTotal"`
+
+	// Accepted transactions later seen or not through other upstreams, see EnableBroadcastChecks.
+	// Only populated on keys without a method.
+	BroadcastsConfirmedTotal    atomic.Int64 `json:"broadcastsConfirmedTotal"`
+	BroadcastBlackholeSuspected atomic.Int64 `json:"broadcastBlackholeSuspected"`
+
+	// Attempts towards a method denied by SetMethodPolicy
+	PolicyDeniedTotal atomic.Int64 `json:"policyDeniedTotal"`
+
+	// Requests not admitted because of the concurrency limit, see ShouldAdmit
+	ConcurrencyDeniedTotal atomic.Int64 `json:"concurrencyDeniedTotal"`
+
+	// Hedge outcomes, see RecordUpstreamHedgeStart
+	HedgesLaunchedTotal      atomic.Int64 `json:"hedgesLaunchedTotal"`
+	HedgesWonTotal           atomic.Int64 `json:"hedgesWonTotal"`
+	HedgesCancelledTotal     atomic.Int64 `json:"hedgesCancelledTotal"`
+	HedgeWastedDurationTotal atomic.Int64 `json:"hedgeWastedDurationTotal"`
+
+	// Requests cancelled before completing by cause, see RecordUpstreamCancelled
+	CancelledByClientTotal   atomic.Int64 `json:"cancelledByClientTotal"`
+	CancelledByDeadlineTotal atomic.Int64 `json:"cancelledByDeadlineTotal"`
+	CancelledByHedgeTotal    atomic.Int64 `json:"cancelledByHedgeTotal"`
+	// Client cancellations counted in ErrorsTotal, excluded by EffectiveErrorRate
+	clientCancelErrors atomic.Int64
+
+	// Size of the responses reported through RecordOutcome
+	ResponseBytesTotal atomic.Int64 `json:"responseBytesTotal"`
+
+	// Fallback attribution, only populated on network-level keys ({"*", network, method})
+	FallbacksTotal             atomic.Int64 `json:"fallbacksTotal"`
+	FallbackServedPositionSum  atomic.Int64 `json:"fallbackServedPositionSum"`
+	FallbackServedTotal        atomic.Int64 `json:"fallbackServedTotal"`
+	FallbackAddedDurationTotal atomic.Int64 `json:"fallbackAddedDurationTotal"`
+
+	// Peak and time of the last large rollback, used to decay BlockHeadLargeRollback
+	rollbackPeak atomic.Int64
+	rollbackAt   atomic.Int64
+
+	// Latency deviation from the key's own baseline as of the last window, see SetLatencyAnomalyConfig.
+	// Not reset with the window.
+	latencyDeviation atomic.Uint64 // float64 bits
+	LatencyAnomalous atomic.Bool   `json:"latencyAnomalous"`
+
+	// Windows meeting or violating the latency SLO, see SetLatencySLO. Not reset with the window.
+	SLOCompliantWindows atomic.Int64 `json:"sloCompliantWindows"`
+	SLOViolatedWindows  atomic.Int64 `json:"sloViolatedWindows"`
+
+	// Never reset, see Lifetime
+	lifetime lifetimeCounters
+
+	// Baseline assumed until washed out by real requests, see SeedBaseline
+	seed atomic.Pointer[seedBaseline]
+
+	// P90 latency in nanos cached every second on upstream-wide keys, see HealthSummary
+	summaryP90 atomic.Int64
+
+	// Incremented before and after each Reset, odd while a reset is in progress
+	resetGen atomic.Uint64
+
+	// Smoothed rates, see SetRateTimeConstant. Not reset with the window.
+	reqRate ewmaRate
+	errRate ewmaRate
+	clock   Clock
+	// newQuantiles creates the latency quantiles of the key, nil for resetting ones, see
+	// SetLatencyBuckets
+	newQuantiles func() *QuantileTracker
+
+	// Persistent connection reconnects, only populated on keys without a method ({ups, network, "*"})
+	ReconnectsTotal atomic.Int64 `json:"reconnectsTotal"`
+
+	// Persistent connection handshakes, only populated on keys without a method, see
+	// RecordUpstreamHandshake
+	HandshakesTotal        atomic.Int64 `json:"handshakesTotal"`
+	HandshakeFailuresTotal atomic.Int64 `json:"handshakeFailuresTotal"`
+	handshakeQuantiles     atomic.Pointer[QuantileTracker]
+	// Not reset with the window, see HandshakeFailureStreak
+	handshakeFailureStreak atomic.Int64
+
+	// Errors of the past windows with traffic, not reset with the window, see WindowErrors
+	windowErrors windowErrorHistogram
+
+	// Unix nanos of the last failure, only populated on the exact key recorded, see InCooldown.
+	// Not reset with the window.
+	lastFailure atomic.Int64
+
+	// Set once a counter reached counterOverflowLimit within the window, see SetCounterOverflowMode
+	overflowed atomic.Bool
+}
+
+// NewTrackedMetrics creates an empty set of metrics.
+func NewTrackedMetrics() *TrackedMetrics {
+	return &TrackedMetrics{
+		ResponseQuantiles: NewQuantileTracker(),
+	}
 }
 
+// ErrorRate is ErrorsTotal over RequestsTotal. ErrorsTotal counts the failures attributable to
+// the upstream (OutcomeFailure) and the cancellations made errors by SetCancellationsAsErrors.
+// Throttling, non-critical errors and unsupported methods have their own counters.
 func (m *TrackedMetrics) ErrorRate() float64 {
-	reqs := m.RequestsTotal.Load()
-	if reqs == 0 {
+	return boundedRatio(m.errorsAndRequests())
+}
+
+// errorsAndRequests loads ErrorsTotal and RequestsTotal for ratios between them. Errors are
+// recorded after their request, so loading them first keeps errors <= requests while requests
+// are being recorded concurrently.
+func (m *TrackedMetrics) errorsAndRequests() (errors, requests int64) {
+	errors = m.ErrorsTotal.Load()
+	return errors, m.RequestsTotal.Load()
+}
+
+// EffectiveErrorRate is ErrorRate left only with what says something about the upstream health:
+// requests for unsupported methods and requests cancelled by clients are excluded, along with
+// client cancellations counted as errors. Self rate limited requests never count as requests.
+func (m *TrackedMetrics) EffectiveErrorRate() float64 {
+	errors := m.ErrorsTotal.Load() - m.clientCancelErrors.Load()
+	requests := m.RequestsTotal.Load() - m.UnsupportedMethodTotal.Load() - m.CancelledByClientTotal.Load()
+	return boundedRatio(errors, requests)
+}
+
+// boundedRatio divides part by total, clamped to [0, 1] as both may be read across a window
+// reset, e.g. errors from before it and requests from after it.
+func boundedRatio(part, total int64) float64 {
+	if total <= 0 || part <= 0 {
 		return 0
 	}
-	return float64(m.ErrorsTotal.Load()) / float64(reqs)
+	if part >= total {
+		return 1
+	}
+	return float64(part) / float64(total)
+}
+
+// LatencyDeviation returns the ratio between the latency of the last window and the baseline,
+// or zero if no baseline is formed yet.
+func (m *TrackedMetrics) LatencyDeviation() float64 {
+	return math.Float64frombits(m.latencyDeviation.Load())
+}
+
+func (m *TrackedMetrics) setLatencyDeviation(v float64) {
+	m.latencyDeviation.Store(math.Float64bits(v))
 }
 
 func (m *TrackedMetrics) GetResponseQuantiles() common.QuantileTracker {
 	return m.ResponseQuantiles
 }
 
+// GetFinalityQuantiles returns the quantile tracker for requests of a given finality,
+// or nil when no such request has been observed yet.
+func (m *TrackedMetrics) GetFinalityQuantiles(finality common.DataFinalityState) *QuantileTracker {
+	return m.FinalityQuantiles[normalizeFinality(finality)].Load()
+}
+
+func (m *TrackedMetrics) finalityQuantiles(finality common.DataFinalityState) *QuantileTracker {
+	finality = normalizeFinality(finality)
+	if qt := m.FinalityQuantiles[finality].Load(); qt != nil {
+		return qt
+	}
+	m.FinalityQuantiles[finality].CompareAndSwap(nil, m.newQuantileTracker())
+	return m.FinalityQuantiles[finality].Load()
+}
+
+// GetAttemptQuantiles returns the quantile tracker of first attempts (attempt 1, or unknown) or of
+// retries (any later attempt), or nil when no such request has been observed yet.
+func (m *TrackedMetrics) GetAttemptQuantiles(attempt int) *QuantileTracker {
+	return m.AttemptQuantiles[attemptIndex(attempt)].Load()
+}
+
+func (m *TrackedMetrics) attemptQuantiles(attempt int) *QuantileTracker {
+	i := attemptIndex(attempt)
+	if qt := m.AttemptQuantiles[i].Load(); qt != nil {
+		return qt
+	}
+	m.AttemptQuantiles[i].CompareAndSwap(nil, m.newQuantileTracker())
+	return m.AttemptQuantiles[i].Load()
+}
+
+func attemptIndex(attempt int) int {
+	if attempt > 1 {
+		return 1
+	}
+	return 0
+}
+
+// attemptLabel bounds the attempt label of the duration histogram to 1, 2 and 3+.
+func attemptLabel(attempt int) string {
+	switch {
+	case attempt <= 1:
+		return "1"
+	case attempt == 2:
+		return "2"
+	}
+	return "3+"
+}
+
+// normalizeFinality bounds finality to the four known states so it is safe as an index and a label.
+func normalizeFinality(finality common.DataFinalityState) common.DataFinalityState {
+	if finality < common.DataFinalityStateFinalized || finality > common.DataFinalityStateUnknown {
+		return common.DataFinalityStateUnknown
+	}
+	return finality
+}
+
+func (m *TrackedMetrics) finalityP90s() map[string]float64 {
+	res := make(map[string]float64, len(m.FinalityQuantiles))
+	for i := range m.FinalityQuantiles {
+		if qt := m.FinalityQuantiles[i].Load(); qt != nil {
+			res[common.DataFinalityState(i).String()] = qt.GetQuantile(0.90).Seconds()
+		}
+	}
+	return res
+}
+
 func (m *TrackedMetrics) ThrottledRate() float64 {
-	reqs := m.RequestsTotal.Load()
-	if reqs == 0 {
+	throttled := m.RemoteRateLimitedTotal.Load() + m.SelfRateLimitedTotal.Load()
+	return boundedRatio(throttled, m.RequestsTotal.Load())
+}
+
+// UnavailableRate is the share of requests the upstream could not serve, failures and remote rate
+// limits alike. Self rate limits are left out since throttling ourselves says nothing about the
+// upstream, unlike ThrottledRate.
+func (m *TrackedMetrics) UnavailableRate() float64 {
+	unavailable := m.ErrorsTotal.Load() + m.RemoteRateLimitedTotal.Load()
+	return boundedRatio(unavailable, m.RequestsTotal.Load())
+}
+
+// HedgeWasteRatio returns the fraction of launched hedges that lost the race and were cancelled.
+func (m *TrackedMetrics) HedgeWasteRatio() float64 {
+	cancelled := m.HedgesCancelledTotal.Load()
+	return boundedRatio(cancelled, m.HedgesLaunchedTotal.Load())
+}
+
+// FallbackAvgServedPosition returns the average 1-based position in the attempt chain
+// that finally served requests which needed a fallback.
+func (m *TrackedMetrics) FallbackAvgServedPosition() float64 {
+	served := m.FallbackServedTotal.Load()
+	if served == 0 {
 		return 0
 	}
-	throttled := float64(m.RemoteRateLimitedTotal.Load()) + float64(m.SelfRateLimitedTotal.Load())
-	return throttled / float64(reqs)
+	return float64(m.FallbackServedPositionSum.Load()) / float64(served)
+}
+
+// FallbackAvgAddedLatency returns the average latency added by failed attempts
+// before a fallback upstream served the request.
+func (m *TrackedMetrics) FallbackAvgAddedLatency() time.Duration {
+	served := m.FallbackServedTotal.Load()
+	if served == 0 {
+		return 0
+	}
+	return time.Duration(m.FallbackAddedDurationTotal.Load() / served)
+}
+
+// marshaledCounters holds the counters of a TrackedMetrics read once for MarshalJSON.
+type marshaledCounters struct {
+	errors, selfRateLimited, remoteRateLimited, requests                 int64
+	unsupported, clientCancelErrors                                      int64
+	blockHeadLag, finalizationLag, policyDenied, concurrencyDenied       int64
+	hedgesLaunched, hedgesWon, hedgesCancelled, hedgeWasted              int64
+	cancelledByClient, cancelledByDeadline, cancelledByHedge, respBytes  int64
+	fallbacks, fallbackPositionSum, fallbackServed, fallbackAddedLatency int64
+	reconnects, malformed, behindHead                                    int64
+	handshakes, handshakeFailures                                        int64
+	consensusMajority, consensusMinority, mismatches, partialResponses   int64
+	selections, shortResults, shortResultMissing                         int64
+	broadcastsConfirmed, broadcastBlackholes                             int64
+}
+
+// readCounters reads every counter once, retrying like SelectionView while a window reset runs
+// so that a marshal does not mix values from before and after the reset. Numerators are read
+// before their denominators since they are recorded after them.
+func (m *TrackedMetrics) readCounters() marshaledCounters {
+	var c marshaledCounters
+	for attempt := 0; attempt < maxViewAttempts; attempt++ {
+		gen := m.resetGen.Load()
+		if gen%2 == 1 {
+			runtime.Gosched()
+			continue
+		}
+		c = marshaledCounters{
+			errors:               m.ErrorsTotal.Load(),
+			selfRateLimited:      m.SelfRateLimitedTotal.Load(),
+			remoteRateLimited:    m.RemoteRateLimitedTotal.Load(),
+			clientCancelErrors:   m.clientCancelErrors.Load(),
+			hedgesWon:            m.HedgesWonTotal.Load(),
+			hedgesCancelled:      m.HedgesCancelledTotal.Load(),
+			hedgeWasted:          m.HedgeWastedDurationTotal.Load(),
+			fallbackPositionSum:  m.FallbackServedPositionSum.Load(),
+			fallbackAddedLatency: m.FallbackAddedDurationTotal.Load(),
+		}
+		c.requests = m.RequestsTotal.Load()
+		c.unsupported = m.UnsupportedMethodTotal.Load()
+		c.hedgesLaunched = m.HedgesLaunchedTotal.Load()
+		c.fallbackServed = m.FallbackServedTotal.Load()
+		c.blockHeadLag = m.BlockHeadLag.Load()
+		c.finalizationLag = m.FinalizationLag.Load()
+		c.policyDenied = m.PolicyDeniedTotal.Load()
+		c.concurrencyDenied = m.ConcurrencyDeniedTotal.Load()
+		c.cancelledByClient = m.CancelledByClientTotal.Load()
+		c.cancelledByDeadline = m.CancelledByDeadlineTotal.Load()
+		c.cancelledByHedge = m.CancelledByHedgeTotal.Load()
+		c.respBytes = m.ResponseBytesTotal.Load()
+		c.fallbacks = m.FallbacksTotal.Load()
+		c.reconnects = m.ReconnectsTotal.Load()
+		c.handshakeFailures = m.HandshakeFailuresTotal.Load()
+		c.handshakes = m.HandshakesTotal.Load()
+		c.malformed = m.MalformedResponsesTotal.Load()
+		c.behindHead = m.BehindHeadErrorsTotal.Load()
+		c.consensusMinority = m.ConsensusMinorityTotal.Load()
+		c.consensusMajority = m.ConsensusMajorityTotal.Load()
+		c.mismatches = m.MismatchesTotal.Load()
+		c.partialResponses = m.PartialResponsesTotal.Load()
+		c.selections = m.SelectionsTotal.Load()
+		c.shortResultMissing = m.ShortResultMissingTotal.Load()
+		c.shortResults = m.ShortResultsTotal.Load()
+		c.broadcastsConfirmed = m.BroadcastsConfirmedTotal.Load()
+		c.broadcastBlackholes = m.BroadcastBlackholeSuspected.Load()
+		if m.resetGen.Load() == gen {
+			break
+		}
+	}
+	return c
 }
 
 func (m *TrackedMetrics) MarshalJSON() ([]byte, error) {
-	return common.SonicCfg.Marshal(map[string]interface{}{
-		"responseQuantiles":      m.ResponseQuantiles,
-		"errorsTotal":            m.ErrorsTotal.Load(),
-		"selfRateLimitedTotal":   m.SelfRateLimitedTotal.Load(),
-		"remoteRateLimitedTotal": m.RemoteRateLimitedTotal.Load(),
-		"requestsTotal":          m.RequestsTotal.Load(),
-		"blockHeadLag":           m.BlockHeadLag.Load(),
-		"finalizationLag":        m.FinalizationLag.Load(),
-		"cordoned":               m.Cordoned.Load(),
-		"cordonedReason":         m.CordonedReason.Load(),
-		"errorRate":              m.ErrorRate(),
-		"throttledRate":          m.ThrottledRate(),
-	})
+	var sloComplianceRate interface{}
+	if rate, ok := m.SLOComplianceRate(); ok {
+		sloComplianceRate = rate
+	}
+	cordonInfo := m.CordonInfo()
+	c := m.readCounters()
+	var fallbackAvgPosition, fallbackAvgAddedSec float64
+	if c.fallbackServed > 0 {
+		fallbackAvgPosition = float64(c.fallbackPositionSum) / float64(c.fallbackServed)
+		fallbackAvgAddedSec = time.Duration(c.fallbackAddedLatency / c.fallbackServed).Seconds()
+	}
+	res := map[string]interface{}{
+		"responseQuantiles":       m.ResponseQuantiles,
+		"finalityP90":             m.finalityP90s(),
+		"compositeTypeP90":        m.compositeTypeP90s(),
+		"ttfbP90":                 m.ttfbP90(),
+		"errorsTotal":             c.errors,
+		"selfRateLimitedTotal":    c.selfRateLimited,
+		"remoteRateLimitedTotal":  c.remoteRateLimited,
+		"unsupportedMethodTotal":  c.unsupported,
+		"requestsTotal":           c.requests,
+		"blockHeadLag":            c.blockHeadLag,
+		"finalizationLag":         c.finalizationLag,
+		"cordoned":                cordonInfo != nil,
+		"errorRate":               boundedRatio(c.errors, c.requests),
+		"effectiveErrorRate":      boundedRatio(c.errors-c.clientCancelErrors, c.requests-c.unsupported-c.cancelledByClient),
+		"throttledRate":           boundedRatio(c.selfRateLimited+c.remoteRateLimited, c.requests),
+		"unavailableRate":         boundedRatio(c.errors+c.remoteRateLimited, c.requests),
+		"policyDeniedTotal":       c.policyDenied,
+		"concurrencyDeniedTotal":  c.concurrencyDenied,
+		"hedgesLaunchedTotal":     c.hedgesLaunched,
+		"hedgesWonTotal":          c.hedgesWon,
+		"hedgesCancelledTotal":    c.hedgesCancelled,
+		"hedgeWastedSec":          time.Duration(c.hedgeWasted).Seconds(),
+		"hedgeWasteRatio":         boundedRatio(c.hedgesCancelled, c.hedgesLaunched),
+		"cancelledByClient":       c.cancelledByClient,
+		"cancelledByDeadline":     c.cancelledByDeadline,
+		"cancelledByHedge":        c.cancelledByHedge,
+		"responseBytesTotal":      c.respBytes,
+		"fallbacksTotal":          c.fallbacks,
+		"fallbackAvgPosition":     fallbackAvgPosition,
+		"fallbackAvgAddedSec":     fallbackAvgAddedSec,
+		"reconnectsTotal":         c.reconnects,
+		"malformedResponsesTotal": c.malformed,
+		"behindHeadErrorsTotal":   c.behindHead,
+		"consensusMajorityTotal":  c.consensusMajority,
+		"consensusMinorityTotal":  c.consensusMinority,
+		"disagreementRate":        boundedRatio(c.consensusMinority, c.consensusMinority+c.consensusMajority),
+		"mismatchesTotal":         c.mismatches,
+		"mismatchRate":            boundedRatio(c.mismatches, c.requests),
+		"partialResponsesTotal":   c.partialResponses,
+		"partialResponseRate":     boundedRatio(c.partialResponses, c.requests),
+		"selectionsTotal":         c.selections,
+		"latencyDeviation":        m.LatencyDeviation(),
+		"latencyAnomalous":        m.LatencyAnomalous.Load(),
+		"reqPerSec":               m.RequestsPerSecond(),
+		"errPerSec":               m.ErrorsPerSecond(),
+		"sloCompliantWindows":     m.SLOCompliantWindows.Load(),
+		"sloViolatedWindows":      m.SLOViolatedWindows.Load(),
+		"sloComplianceRate":       sloComplianceRate,
+		"lifetime":                m.Lifetime(),
+	}
+	if c.malformed > 0 {
+		res["malformedResponses"] = m.malformedResponsesByKind()
+	}
+	if c.handshakes > 0 {
+		res["handshakesTotal"] = c.handshakes
+		res["handshakeFailuresTotal"] = c.handshakeFailures
+		res["handshakeP90"] = m.handshakeP90()
+	}
+	if c.shortResults > 0 {
+		res["shortResultsTotal"] = c.shortResults
+		res["shortResultMissingTotal"] = c.shortResultMissing
+	}
+	if c.broadcastsConfirmed+c.broadcastBlackholes > 0 {
+		res["broadcastsConfirmedTotal"] = c.broadcastsConfirmed
+		res["broadcastBlackholeSuspected"] = c.broadcastBlackholes
+	}
+	if seeded := m.SeededRequests(); seeded > 0 {
+		res["seededRequests"] = seeded
+	}
+	if cordonInfo != nil {
+		res["cordonedReason"] = cordonInfo.Reason
+		res["cordonedSource"] = cordonInfo.Origin()
+		res["cordonInfo"] = cordonInfo
+	}
+	return common.SonicCfg.Marshal(res)
 }
 
 // Reset zeroes out counters for the next window.
 func (m *TrackedMetrics) Reset() {
+	m.resetGen.Add(1)
+	defer m.resetGen.Add(1)
+
 	m.ErrorsTotal.Store(0)
 	m.RequestsTotal.Store(0)
 	m.SelfRateLimitedTotal.Store(0)
 	m.RemoteRateLimitedTotal.Store(0)
+	m.UnsupportedMethodTotal.Store(0)
+	m.overflowed.Store(false)
 	m.BlockHeadLag.Store(0)
 	m.FinalizationLag.Store(0)
-	m.ResponseQuantiles.Reset()
+	m.PolicyDeniedTotal.Store(0)
+	m.ConcurrencyDeniedTotal.Store(0)
+	m.HedgesLaunchedTotal.Store(0)
+	m.HedgesWonTotal.Store(0)
+	m.HedgesCancelledTotal.Store(0)
+	m.HedgeWastedDurationTotal.Store(0)
+	m.CancelledByClientTotal.Store(0)
+	m.CancelledByDeadlineTotal.Store(0)
+	m.CancelledByHedgeTotal.Store(0)
+	m.clientCancelErrors.Store(0)
+	m.ResponseBytesTotal.Store(0)
+	m.FallbacksTotal.Store(0)
+	m.FallbackServedPositionSum.Store(0)
+	m.FallbackServedTotal.Store(0)
+	m.FallbackAddedDurationTotal.Store(0)
+	m.ReconnectsTotal.Store(0)
+	m.HandshakesTotal.Store(0)
+	m.HandshakeFailuresTotal.Store(0)
+	m.MalformedResponsesTotal.Store(0)
+	m.BehindHeadErrorsTotal.Store(0)
+	m.behindHeadEvidence.Store(0)
+	m.summaryP90.Store(0)
+	m.ConsensusMajorityTotal.Store(0)
+	m.ConsensusMinorityTotal.Store(0)
+	m.MismatchesTotal.Store(0)
+	m.PartialResponsesTotal.Store(0)
+	m.SelectionsTotal.Store(0)
+	m.ShortResultsTotal.Store(0)
+	m.ShortResultMissingTotal.Store(0)
+	m.BroadcastsConfirmedTotal.Store(0)
+	m.BroadcastBlackholeSuspected.Store(0)
+	for i := range m.malformedByKind {
+		m.malformedByKind[i].Store(0)
+	}
+	m.ResponseQuantiles.resetWindow()
+	for i := range m.FinalityQuantiles {
+		if qt := m.FinalityQuantiles[i].Load(); qt != nil {
+			qt.resetWindow()
+		}
+	}
+	for i := range m.AttemptQuantiles {
+		if qt := m.AttemptQuantiles[i].Load(); qt != nil {
+			qt.resetWindow()
+		}
+	}
+	for i := range m.compositeQuantiles {
+		if qt := m.compositeQuantiles[i].Load(); qt != nil {
+			qt.resetWindow()
+		}
+	}
+	if qt := m.ttfbQuantiles.Load(); qt != nil {
+		qt.resetWindow()
+	}
+	if qt := m.handshakeQuantiles.Load(); qt != nil {
+		qt.resetWindow()
+	}
+	if qt := m.successQuantiles.Load(); qt != nil {
+		qt.resetWindow()
+	}
+	if qt := m.serverErrorQuantiles.Load(); qt != nil {
+		qt.resetWindow()
+	}
+	if h := m.servedStaleness.Load(); h != nil {
+		h.reset()
+	}
 
 	// Optionally uncordon
 	m.Cordoned.Store(false)
-	m.CordonedReason.Store("")
+	m.cordonInfo.Store(nil)
 }
 
 // ------------------------------------
@@ -123,57 +697,259 @@ type Tracker struct {
 	projectId  string
 	windowSize time.Duration
 	logger     *zerolog.Logger
+	clock      Clock
 
 	// Replace the maps + mu with sync.Map for concurrency:
 	metrics  sync.Map // map[tripletKey]*TrackedMetrics
 	metadata sync.Map // map[duoKey]*NetworkMetadata
+
+	methodPolicies         sync.Map // map[tripletKey]bool (false means denied)
+	attributes             sync.Map // map[duoKey]map[string]string, see SetUpstreamAttributes
+	clientVersions         sync.Map // map[duoKey]ClientVersion, see RecordUpstreamClientVersion
+	upstreamGroups         sync.Map // map[string]string of groups keyed by upstream, see SetUpstreamGroup
+	shadowUpstreams        sync.Map // map[string]struct{}, see SetUpstreamShadow
+	tieBreakCursors        sync.Map // map[string]*atomic.Uint64 keyed by network, see TieBreakRoundRobin
+	eligibleUpstreams      sync.Map // map[string]int keyed by network, see EligibleUpstreams
+	eligibilityFromCordons sync.Map // map[string]struct{} keyed by network, see SetEligibilityFromCordons
+	weightOverrides        sync.Map // map[duoKey]*weightOverride
+	finalityDepths         sync.Map // map[string]int64 keyed by network, see SetFinalityDepth
+	errorBudgets           sync.Map // map[duoKey]*errorBudget, see SetErrorBudgetConfig
+	concurrencyLimits      sync.Map // map[duoKey]int64, see SetConcurrencyLimit
+	rateLimitHeadrooms     sync.Map // map[duoKey]float64, see SetUpstreamRateLimitHeadroom
+	uptimes                sync.Map // map[duoKey]*uptimeRing, see Uptime
+	ramps                  sync.Map // map[duoKey]*warmupRamp, see SetWarmupRamp
+
+	errorRateCordonConfigs sync.Map     // map[networkMethodKey]*ErrorRateCordonConfig
+	errorRateCordons       sync.Map     // map[tripletKey]*errorRateCordon
+	errorRateCordonsActive atomic.Int64 // number of entries in errorRateCordons
+
+	cordonRecoveryConfigs sync.Map // map[string]*CordonRecoveryConfig keyed by cordon reason
+	cordonRecoveries      sync.Map // map[tripletKey]*cordonRecovery
+
+	networkUpstreams sync.Map // map[string]*sync.Map of upstream ids keyed by network
+	upstreamMethods  sync.Map // map[duoKey]*sync.Map of methods tracked for an upstream on a network
+	malformedSamples sync.Map // map[string]*malformedSampleRing keyed by upstream
+
+	noDataBehavior           atomic.Int32 // NoDataBehavior
+	counterOverflowMode      atomic.Int32 // CounterOverflowMode
+	reconnectCordonThreshold atomic.Int64
+	handshakeCordonThreshold atomic.Int64
+	malformedCordonThreshold atomic.Int64
+	behindHeadEvidenceLag    atomic.Int64
+	behindHeadMatchers       atomic.Pointer[BehindHeadMatchers]
+	disagreementCordon       atomic.Pointer[disagreementCordon]
+	mismatchCordon           atomic.Pointer[mismatchCordon]
+	shortResultCordon        atomic.Int64 // minimum incidents, zero disables
+	eligibility              atomic.Pointer[eligibilityThresholds]
+	minEligibleUpstreams     atomic.Int64
+	cordonFloorMu            sync.Mutex // serializes automatic cordons near the floor, see SetMinEligibleUpstreams
+	blockNumberCeiling       atomic.Int64
+	recentErrorsSize         atomic.Int64 // zero means the default, negative disables
+	historicalLagMargin      atomic.Int64 // zero means the default, negative disables
+	forkHorizon              atomic.Int64 // zero means the default
+	latencyBuckets           atomic.Int64
+	cordonDryRun             atomic.Bool
+	cordonGuard              atomic.Pointer[CordonGuard]
+	rampConfig               atomic.Pointer[WarmupRampConfig]
+	shadowUpstreamsCount     atomic.Int64 // number of entries in shadowUpstreams
+	tieBreak                 atomic.Int32 // TieBreak
+	broadcastChecks          atomic.Pointer[broadcastChecks]
+	writeMethods             atomic.Pointer[map[string]struct{}]
+	aggregateExclusions      atomic.Pointer[map[string]struct{}] // see SetMethodInAggregate
+	aggregateExclusionsMu    sync.Mutex
+	scoreRetryLatency        atomic.Bool
+	cancelsAsErrors          atomic.Pointer[map[CancelCause]bool]
+	rateTau                  atomic.Int64  // time.Duration
+	uptimeMaxErrorRate       atomic.Uint64 // float64 bits, zero means DefaultUptimeMaxErrorRate
+	rollbackDecay            time.Duration
+	windowStart              atomic.Int64 // unix nanos of the current window start
+	bootstrapped             atomic.Bool
+	lagRecomputes            atomic.Int64 // network-wide lag recomputes, deferred during reorgs
+	reorgTimeout             atomic.Int64 // time.Duration, zero means DefaultReorgTimeout
+
+	events eventSubscribers
+	slos   sync.Map // map[string]*sloState keyed by network
+
+	latencySLOs sync.Map // map[string]*latencySLO keyed by method
+
+	methodImportances sync.Map // map[string]*MethodImportance keyed by network
+
+	latencyCostClasses sync.Map // map[string]*LatencyCostClasses keyed by network
+
+	methodNormalizer atomic.Pointer[methodNormalizer]
+
+	networkCanonicalizer atomic.Pointer[NetworkCanonicalizer]
+	networkAliases       atomic.Pointer[map[string]string]
+	nonCanonicalSeen     sync.Map // map[string]struct{} of logged non-canonical networks
+
+	samples atomic.Pointer[sampleRing]
+
+	clientIds clientIdTracker // see GetClientMetrics
+
+	forks sync.Map // map[string]*forkState keyed by network
+
+	anomalyConfig atomic.Pointer[LatencyAnomalyConfig]
+	baselines     sync.Map // map[tripletKey]*latencyBaseline
+
+	cordonedTimes sync.Map // map[duoKey]*cordonedTime, see GetCordonedTime
+
+	projectAggregates atomic.Bool // see SetProjectAggregates
+
+	telemetryQueue atomic.Pointer[telemetryQueue] // see StartAsyncTelemetry
+
+	expectedChainIds       sync.Map     // map[string]int64 keyed by network, see SetExpectedChainId
+	chainIdMismatchPenalty atomic.Int32 // ChainIdMismatchPenalty
+
+	trafficShares          sync.Map      // map[string]*trafficShareState keyed by network
+	trafficShareDivergence atomic.Uint64 // float64 bits, zero means DefaultTrafficShareDivergence
+	trafficShareWindows    atomic.Int64  // zero means DefaultTrafficShareDivergenceWindows
+
+	// When the tracker started, see CordonedTime
+	startedAt time.Time
 }
 
 // NewTracker constructs a new Tracker, using sync.Map for concurrency.
 func NewTracker(logger *zerolog.Logger, projectId string, windowSize time.Duration) *Tracker {
-	return &Tracker{
+	t := &Tracker{
 		logger:     logger,
 		projectId:  projectId,
 		windowSize: windowSize,
+		clock:      RealClock{},
 	}
+	t.startedAt = t.clock.Now()
+	t.windowStart.Store(t.startedAt.UnixNano())
+	return t
+}
+
+// SetClock replaces the clock used for timers and window resets, it must be called before Bootstrap.
+func (t *Tracker) SetClock(c Clock) {
+	t.clock = c
+	t.startedAt = c.Now()
+	t.windowStart.Store(t.startedAt.UnixNano())
 }
 
-// Bootstrap starts the goroutine that periodically resets the metrics.
+// Clock returns the clock of the tracker, so that callers measure the durations they record with it.
+func (t *Tracker) Clock() Clock {
+	return t.clock
+}
+
+// Bootstrap starts the goroutine that periodically resets the metrics. The first window starts at
+// bootstrap and lasts at least windowSize, whatever the alignment of the ticks of the clock.
 func (t *Tracker) Bootstrap(ctx context.Context) {
-	go t.resetMetricsLoop(ctx)
+	t.bootstrapped.Store(true)
+	bootstrappedAt := t.clock.Now()
+	t.windowStart.Store(bootstrappedAt.UnixNano())
+	// Tickers are created before returning so that windows are aligned with the bootstrap time
+	go t.resetMetricsLoop(ctx, t.clock.NewTicker(t.windowSize), bootstrappedAt.Add(t.windowSize))
+	go t.rateGaugesLoop(ctx, t.clock.NewTicker(rateGaugesInterval))
+	go t.uptimeLoop(ctx, t.clock.NewTicker(UptimeInterval))
+	go t.recoveryLoop(ctx, t.clock.NewTicker(recoveryTickInterval))
+	if t.rollbackDecay > 0 {
+		go t.decayRollbacksLoop(ctx, t.clock.NewTicker(t.rollbackDecayInterval()))
+	}
 }
 
-// resetMetricsLoop periodically resets metrics each windowSize.
-func (t *Tracker) resetMetricsLoop(ctx context.Context) {
-	ticker := time.NewTicker(t.windowSize)
+// resetMetricsLoop periodically resets metrics each windowSize. Ticks before firstReset are
+// skipped, so that a clock aligning its ticks on boundaries (e.g. of wall time) cannot wipe the
+// first window right after bootstrap.
+func (t *Tracker) resetMetricsLoop(ctx context.Context, ticker Ticker, firstReset time.Time) {
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case <-ticker.C:
-			// Range over sync.Map to reset all known metrics
-			t.metrics.Range(func(key, value any) bool {
-				if tm, ok := value.(*TrackedMetrics); ok {
-					tm.Reset()
-				}
-				return true // keep iterating
-			})
+		case now := <-ticker.C():
+			if now.Before(firstReset) {
+				continue
+			}
+			t.rollWindow(now)
 		}
 	}
 }
 
+// rollWindow closes the current window at now and resets the metrics for the next one.
+func (t *Tracker) rollWindow(now time.Time) {
+	t.windowStart.Store(now.UnixNano())
+	t.rollSLOWindows()
+	t.rollLatencyBaselines()
+	t.rollLatencySLOs()
+	t.rollErrorRateCordons()
+	t.rollClientIds()
+	t.rollTrafficShares()
+	cordoned := t.upstreamWideCordons()
+	// Range over sync.Map to reset all known metrics
+	t.metrics.Range(func(key, value any) bool {
+		if tm, ok := value.(*TrackedMetrics); ok {
+			tm.observeWindowErrors()
+			tm.Reset()
+		}
+		return true // keep iterating
+	})
+	t.reapplyErrorRateCordons()
+	t.reapplyRecoveringCordons()
+	t.reapplyChainIdCordons()
+	t.restoreErrorBudgets()
+	t.rebaseWarmupRamps()
+	t.rollCordonedTimes(now)
+	t.startLiftedRamps(cordoned)
+	t.refreshAllEligibleUpstreams()
+}
+
 // For real-time aggregator updates, we store expansions of the key:
+//
+//	{ups, network, method}  the key itself
+//	{ups, network, "*"}     the upstream on the network, all methods
+//	{ups, "*", "*"}         the upstream on all networks
+//	{"*", network, method}  the network for the method
+//	{"*", network, "*"}     the network, all methods
+//	{ups, network, class}   the upstream on the network for the method class, see MethodClass
+//	{"*", network, class}   the network for the method class
+//	{"*", "*", method}      the project for the method, only with project aggregates enabled
+//	{"*", "*", "*"}         the project, all methods, only with project aggregates enabled
+//
+// Aggregate keys always carry "*" as upstream, so that they never collide with the upstream-wide
+// {ups, "*", "*"} keys. Shadow upstreams only get the keys of their own, see SetUpstreamShadow,
+// and methods left out of the rollups only the key itself and the network and project ones for
+// the method, see SetMethodInAggregate.
 func (t *Tracker) getKeys(ups, network, method string) []tripletKey {
-	// same expansions as before
-	return []tripletKey{
-		{ups, network, method},
-		{ups, network, "*"},
-		{ups, "*", "*"},
-		{"*", network, method},
-		{"*", network, "*"},
+	project := t.projectAggregates.Load() && ups != "*" && network != "*"
+	if !t.inAggregate(method) {
+		// left out of the wildcard rollups, see SetMethodInAggregate
+		if t.IsShadowUpstream(ups) {
+			return []tripletKey{{ups, network, method}}
+		}
+		if project {
+			return []tripletKey{{ups, network, method}, {"*", network, method}, {"*", "*", method}}
+		}
+		return []tripletKey{{ups, network, method}, {"*", network, method}}
+	}
+	keys := make([]tripletKey, 5, 9)
+	keys[0] = tripletKey{ups, network, method}
+	keys[1] = tripletKey{ups, network, "*"}
+	keys[2] = tripletKey{ups, "*", "*"}
+	keys[3] = tripletKey{"*", network, method}
+	keys[4] = tripletKey{"*", network, "*"}
+	if t.IsShadowUpstream(ups) {
+		// mirrored traffic stays out of the network aggregates, see SetUpstreamShadow
+		keys = keys[:3]
+		if method == "*" {
+			return keys
+		}
+		return append(keys, tripletKey{ups, network, t.methodClass(method).key()})
+	}
+	if project {
+		keys = append(keys, tripletKey{"*", "*", "*"})
+		if method != "*" {
+			keys = append(keys, tripletKey{"*", "*", method})
+		}
+	}
+	if method == "*" {
+		return keys
 	}
+	// plus the aggregates of the class of the method, see MethodClass
+	class := t.methodClass(method).key()
+	return append(keys, tripletKey{ups, network, class}, tripletKey{"*", network, class})
 }
 
 // getMetadata fetches or creates *NetworkMetadata from sync.Map
@@ -195,13 +971,20 @@ func (t *Tracker) getMetrics(k tripletKey) *TrackedMetrics {
 	if val, ok := t.metrics.Load(k); ok {
 		return val.(*TrackedMetrics)
 	}
-	newTm := &TrackedMetrics{
-		ResponseQuantiles: NewQuantileTracker(),
+	newTm := NewTrackedMetrics()
+	newTm.clock = t.clock
+	if buckets := t.latencyBuckets.Load(); buckets > 0 {
+		window, clock := t.windowSize, t.clock
+		newTm.newQuantiles = func() *QuantileTracker {
+			return NewBucketedQuantileTracker(int(buckets), window, clock)
+		}
+		newTm.ResponseQuantiles = newTm.newQuantileTracker()
 	}
 	actual, loaded := t.metrics.LoadOrStore(k, newTm)
 	if loaded {
 		return actual.(*TrackedMetrics)
 	}
+	t.indexNetworkUpstream(k)
 	return newTm
 }
 
@@ -209,30 +992,106 @@ func (t *Tracker) getMetrics(k tripletKey) *TrackedMetrics {
 // Cordon / Uncordon
 // --------------------
 
+// Cordon cordons (ups, network, method) on behalf of an operator, see CordonOriginManual.
 func (t *Tracker) Cordon(ups, network, method, reason string) {
+	t.CordonWithInfo(ups, network, method, CordonInfo{Reason: reason, Source: CordonSourceManual})
+}
+
+// CordonWithInfo cordons (ups, network, method) describing why with info. Since is set to now
+// (unless given) when the key was not cordoned, otherwise it keeps the time of the original cordon.
+// A manual cordon is kept as is when info comes from another source.
+func (t *Tracker) CordonWithInfo(ups, network, method string, info CordonInfo) {
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
 	t.logger.Debug().Str("upstream", ups).
 		Str("network", network).
 		Str("method", method).
-		Str("reason", reason).
+		Str("reason", info.Reason).
+		Str("source", info.Source).
 		Msg("cordoning upstream to disable routing")
 
 	tm := t.getMetrics(tripletKey{ups, network, method})
+	var prev *CordonInfo
+	for {
+		prev = tm.cordonInfo.Load()
+		if prev != nil && prev.Source == CordonSourceManual && info.Source == CordonSourceTracker {
+			t.recordCordonTransition(ups, network, method, info.Source, "refused")
+			return
+		}
+		next := info
+		if prev != nil {
+			next.Since = prev.Since
+		} else if next.Since.IsZero() {
+			next.Since = t.clock.Now()
+		}
+		if tm.cordonInfo.CompareAndSwap(prev, &next) {
+			break
+		}
+	}
 	tm.Cordoned.Store(true)
-	tm.CordonedReason.Store(reason)
+	if prev == nil || prev.Source != info.Source {
+		t.recordCordonTransition(ups, network, method, info.Source, "cordon")
+	}
+	if method == "*" {
+		t.syncCordonedTime(ups, network, true, t.clock.Now())
+	}
 
-	telemetry.MetricUpstreamCordoned.WithLabelValues(t.projectId, network, ups, method).Set(1)
+	telemetry.MetricUpstreamCordoned.WithLabelValues(t.projectId, network, ups, t.upstreamVendor(ups, network), method).Set(1)
+	if method == "*" {
+		t.refreshEligibleUpstreams(network)
+	}
 }
 
+// Uncordon lifts the cordon of (ups, network, method) on behalf of an operator, whatever its
+// source.
 func (t *Tracker) Uncordon(ups, network, method string) {
+	t.UncordonWithSource(ups, network, method, CordonSourceManual)
+}
+
+// UncordonWithSource lifts the cordon of (ups, network, method) on behalf of source. It reports
+// false, leaving the cordon in place, when a manual cordon would be lifted by another source. A
+// manual uncordon also drops the automatic cordons of the key waiting to be reapplied at the next
+// window reset, e.g. error rate or chain id mismatch ones.
+func (t *Tracker) UncordonWithSource(ups, network, method, source string) bool {
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
 	tm := t.getMetrics(tripletKey{ups, network, method})
-	tm.Cordoned.Store(false)
-	tm.CordonedReason.Store("")
+	var prev *CordonInfo
+	for {
+		prev = tm.cordonInfo.Load()
+		if prev != nil && prev.Source == CordonSourceManual && source != CordonSourceManual {
+			t.recordCordonTransition(ups, network, method, source, "refused")
+			return false
+		}
+		if tm.cordonInfo.CompareAndSwap(prev, nil) {
+			break
+		}
+	}
+	wasCordoned := tm.Cordoned.Swap(false)
+	t.cordonRecoveries.Delete(tripletKey{ups, network, method})
+	if source == CordonSourceManual {
+		t.dropReappliedCordons(tripletKey{ups, network, method})
+	}
+	if prev != nil {
+		t.recordCordonTransition(ups, network, method, source, "uncordon")
+	}
+	if method == "*" {
+		t.syncCordonedTime(ups, network, false, t.clock.Now())
+	}
 
-	telemetry.MetricUpstreamCordoned.WithLabelValues(t.projectId, network, ups, method).Set(0)
+	telemetry.MetricUpstreamCordoned.WithLabelValues(t.projectId, network, ups, t.upstreamVendor(ups, network), method).Set(0)
+
+	if wasCordoned && method == "*" {
+		t.startWarmupRamp(ups, network)
+		t.refreshEligibleUpstreams(network)
+	}
+	return true
 }
 
 // IsCordoned checks if (ups, network, method) or (ups, network, "*") is cordoned.
 func (t *Tracker) IsCordoned(ups, network, method string) bool {
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
 	// If the entire upstream for that network is cordoned, treat it as cordoned
 	if val, ok := t.metrics.Load(tripletKey{ups, network, "*"}); ok {
 		tm := val.(*TrackedMetrics)
@@ -252,75 +1111,100 @@ func (t *Tracker) IsCordoned(ups, network, method string) bool {
 // Basic Request & Failure Tracking
 // ------------------------------------
 
+// RecordUpstreamRequest counts a request towards an upstream, prefer RecordOutcome which also
+// records how it ended.
 func (t *Tracker) RecordUpstreamRequest(ups, network, method string) {
-	keys := t.getKeys(ups, network, method)
-	for _, k := range keys {
-		m := t.getMetrics(k)
-		m.RequestsTotal.Add(1)
-	}
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
+	t.recordUpdate(ups, network, method, outcomeUpdate{request: true})
 }
 
 func (t *Tracker) RecordUpstreamDurationStart(ups, network, method string, compositeType string) *Timer {
-	if compositeType == "" {
-		compositeType = "none"
-	}
-	return &Timer{
-		start:         time.Now(),
-		network:       network,
-		ups:           ups,
-		method:        method,
-		compositeType: compositeType,
-		tracker:       t,
-	}
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
+	timer := NewTimer(t, t.clock, ups, network, method, compositeType)
+	timer.release = t.startInFlight(ups, network)
+	return timer
 }
 
 func (t *Tracker) RecordUpstreamDuration(ups, network, method string, duration time.Duration, compositeType string) {
-	keys := t.getKeys(ups, network, method)
-	sec := duration.Seconds()
-	for _, k := range keys {
-		m := t.getMetrics(k)
-		m.ResponseQuantiles.Add(sec)
-	}
-	if compositeType == "" {
-		compositeType = "none"
-	}
-	telemetry.MetricUpstreamRequestDuration.WithLabelValues(t.projectId, network, ups, method, compositeType).Observe(sec)
+	t.RecordUpstreamFinalityDuration(ups, network, method, duration, compositeType, common.DataFinalityStateUnknown)
+}
+
+// RecordUpstreamDurationWithQuantiles is RecordUpstreamDuration returning the p50 and p99 in
+// seconds of the response quantiles of (ups, network, method) right after the duration is
+// recorded, e.g. to adapt the timeout of the next request without a second lookup.
+func (t *Tracker) RecordUpstreamDurationWithQuantiles(ups, network, method string, duration time.Duration, compositeType string) (p50, p99 float64) {
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
+	t.recordUpdate(ups, network, method, outcomeUpdate{
+		observeDuration: true,
+		duration:        duration,
+		compositeType:   compositeType,
+		finality:        common.DataFinalityStateUnknown,
+	})
+	s := t.getMetrics(tripletKey{ups, network, method}).ResponseQuantiles.Summary()
+	return s.P50.Seconds(), s.P99.Seconds()
+}
+
+// RecordUpstreamFinalityDuration records a duration segmented by the finality of the requested data,
+// in addition to the overall response quantiles.
+func (t *Tracker) RecordUpstreamFinalityDuration(ups, network, method string, duration time.Duration, compositeType string, finality common.DataFinalityState) {
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
+	t.recordUpdate(ups, network, method, outcomeUpdate{
+		observeDuration: true,
+		duration:        duration,
+		compositeType:   compositeType,
+		finality:        finality,
+	})
 }
 
 func (t *Tracker) RecordUpstreamFailure(ups, network, method string) {
-	keys := t.getKeys(ups, network, method)
-	for _, k := range keys {
-		m := t.getMetrics(k)
-		m.ErrorsTotal.Add(1)
-	}
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
+	t.recordUpdate(ups, network, method, outcomeUpdate{failure: true})
 }
 
 func (t *Tracker) RecordUpstreamSelfRateLimited(ups, network, method string) {
-	keys := t.getKeys(ups, network, method)
-	for _, k := range keys {
-		m := t.getMetrics(k)
-		m.SelfRateLimitedTotal.Add(1)
-	}
-	telemetry.MetricUpstreamSelfRateLimitedTotal.WithLabelValues(t.projectId, network, ups, method).Inc()
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
+	t.recordUpdate(ups, network, method, outcomeUpdate{selfRateLimited: true})
 }
 
 func (t *Tracker) RecordUpstreamRemoteRateLimited(ups, network, method string) {
-	keys := t.getKeys(ups, network, method)
-	for _, k := range keys {
-		m := t.getMetrics(k)
-		m.RemoteRateLimitedTotal.Add(1)
-	}
-	telemetry.MetricUpstreamRemoteRateLimitedTotal.WithLabelValues(t.projectId, network, ups, method).Inc()
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
+	t.recordUpdate(ups, network, method, outcomeUpdate{remoteRateLimited: true})
 }
 
 // --------------------------------------------
 // Accessors
 // --------------------------------------------
 
+// GetUpstreamMethodMetrics returns the metrics of (ups, network, method), creating the key when
+// it is not tracked yet, see LookupUpstreamMethodMetrics for reads that must not.
 func (t *Tracker) GetUpstreamMethodMetrics(ups, network, method string) *TrackedMetrics {
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
 	return t.getMetrics(tripletKey{ups, network, method})
 }
 
+// LookupUpstreamMethodMetrics returns the metrics of (ups, network, method) and whether the key is
+// tracked, without ever creating it, e.g. for monitoring tools probing many combinations.
+func (t *Tracker) LookupUpstreamMethodMetrics(ups, network, method string) (*TrackedMetrics, bool) {
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
+	val, ok := t.metrics.Load(tripletKey{ups, network, method})
+	if !ok {
+		return nil, false
+	}
+	return val.(*TrackedMetrics), true
+}
+
+// GetUpstreamMetrics returns the live metrics of every key of an upstream, keyed by
+// "network|method". The tracker keeps mutating them, so they are meant for write paths (e.g.
+// seeding lags); read paths should use GetUpstreamMetricsSnapshot instead.
 func (t *Tracker) GetUpstreamMetrics(upsId string) map[string]*TrackedMetrics {
 	result := make(map[string]*TrackedMetrics)
 
@@ -341,6 +1225,8 @@ func (t *Tracker) GetUpstreamMetrics(upsId string) map[string]*TrackedMetrics {
 }
 
 func (t *Tracker) GetNetworkMethodMetrics(network, method string) *TrackedMetrics {
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
 	return t.getMetrics(tripletKey{"*", network, method})
 }
 
@@ -349,12 +1235,16 @@ func (t *Tracker) GetNetworkMethodMetrics(network, method string) *TrackedMetric
 // --------------------------------------------
 
 func (t *Tracker) SetLatestBlockNumber(ups, network string, blockNumber int64) {
+	network = t.canonicalNetwork(network)
 	t.logger.Trace().Str("upstreamId", ups).Str("networkId", network).Int64("value", blockNumber).Msg("updating latest block number in tracker")
 
 	if blockNumber <= 0 {
 		t.logger.Warn().Str("upstreamId", ups).Str("networkId", network).Int64("value", blockNumber).Msg("ignoring setting non-positive latest block number in tracker")
 		return
 	}
+	if t.exceedsBlockNumberCeiling(ups, network, "latest", blockNumber) {
+		return
+	}
 
 	mdKey := duoKey{ups: ups, network: network}
 	ntwMdKey := duoKey{ups: "*", network: network}
@@ -364,9 +1254,12 @@ func (t *Tracker) SetLatestBlockNumber(ups, network string, blockNumber int64) {
 	oldNtwVal := ntwMeta.evmLatestBlockNumber.Load()
 	needsGlobalUpdate := false
 	if blockNumber > oldNtwVal {
+		now := t.clock.Now()
 		ntwMeta.evmLatestBlockNumber.Store(blockNumber)
+		ntwMeta.headAdvancedAt.Store(now.UnixNano())
+		ntwMeta.recordHeadArrival(blockNumber, now)
 		telemetry.MetricUpstreamLatestBlockNumber.
-			WithLabelValues(t.projectId, network, "*").
+			WithLabelValues(t.projectId, network, "*", "").
 			Set(float64(blockNumber))
 		needsGlobalUpdate = true
 	}
@@ -377,11 +1270,15 @@ func (t *Tracker) SetLatestBlockNumber(ups, network string, blockNumber int64) {
 	if blockNumber > oldUpsVal {
 		upsMeta.evmLatestBlockNumber.Store(blockNumber)
 		telemetry.MetricUpstreamLatestBlockNumber.
-			WithLabelValues(t.projectId, network, ups).
+			WithLabelValues(t.projectId, network, ups, t.upstreamVendor(ups, network)).
 			Set(float64(blockNumber))
 	}
 
-	// 3) Recompute block head lag for this upstream
+	// 3) Recompute block head lag for this upstream, or for all of them once the reorg is over
+	if t.reorgInProgress(network, ntwMeta) {
+		t.deriveFinalizedBlockNumber(ups, network)
+		return
+	}
 	ntwBn := ntwMeta.evmLatestBlockNumber.Load()
 	if ntwBn <= 0 {
 		t.logger.Warn().Str("upstreamId", ups).Str("networkId", network).Int64("value", ntwBn).Msg("ignoring block head lag tracking for non-positive block number in tracker")
@@ -390,33 +1287,12 @@ func (t *Tracker) SetLatestBlockNumber(ups, network string, blockNumber int64) {
 
 	upsLag := ntwBn - upsMeta.evmLatestBlockNumber.Load()
 	telemetry.MetricUpstreamBlockHeadLag.
-		WithLabelValues(t.projectId, network, ups).
+		WithLabelValues(t.projectId, network, ups, t.upstreamVendor(ups, network)).
 		Set(float64(upsLag))
 
 	// 4) Update the TrackedMetrics.BlockHeadLag fields
 	if needsGlobalUpdate {
-		// Recompute for every upstream in the network
-		t.metrics.Range(func(key, value any) bool {
-			k, ok := key.(tripletKey)
-			if !ok {
-				return true
-			}
-			if k.network == network {
-				tm := value.(*TrackedMetrics)
-				otherUpsMeta := t.getMetadata(duoKey{ups: k.ups, network: network})
-				otherVal := otherUpsMeta.evmLatestBlockNumber.Load()
-				if otherVal <= 0 {
-					t.logger.Debug().Str("upstreamId", k.ups).Str("networkId", network).Int64("value", otherVal).Msg("ignoring block head lag tracking for non-positive block number in tracker")
-					return true
-				}
-				otherLag := ntwBn - otherVal
-				tm.BlockHeadLag.Store(otherLag)
-				telemetry.MetricUpstreamBlockHeadLag.
-					WithLabelValues(t.projectId, network, k.ups).
-					Set(float64(otherLag))
-			}
-			return true
-		})
+		t.recomputeBlockHeadLags(network, ntwBn)
 	} else {
 		// Only update items for this single upstream in this network
 		t.metrics.Range(func(key, value any) bool {
@@ -431,21 +1307,62 @@ func (t *Tracker) SetLatestBlockNumber(ups, network string, blockNumber int64) {
 			return true
 		})
 	}
+
+	t.deriveFinalizedBlockNumber(ups, network)
+}
+
+// recomputeBlockHeadLags sets the block head lag of every upstream of a network against its
+// highest block head ntwBn.
+func (t *Tracker) recomputeBlockHeadLags(network string, ntwBn int64) {
+	t.lagRecomputes.Add(1)
+	t.metrics.Range(func(key, value any) bool {
+		k, ok := key.(tripletKey)
+		if !ok {
+			return true
+		}
+		if k.network == network {
+			tm := value.(*TrackedMetrics)
+			otherUpsMeta := t.getMetadata(duoKey{ups: k.ups, network: network})
+			otherVal := otherUpsMeta.evmLatestBlockNumber.Load()
+			if otherVal <= 0 {
+				t.logger.Debug().Str("upstreamId", k.ups).Str("networkId", network).Int64("value", otherVal).Msg("ignoring block head lag tracking for non-positive block number in tracker")
+				return true
+			}
+			otherLag := ntwBn - otherVal
+			tm.BlockHeadLag.Store(otherLag)
+			telemetry.MetricUpstreamBlockHeadLag.
+				WithLabelValues(t.projectId, network, k.ups, t.upstreamVendor(k.ups, network)).
+				Set(float64(otherLag))
+		}
+		return true
+	})
 }
 
 func (t *Tracker) SetFinalizedBlockNumber(ups, network string, blockNumber int64) {
+	t.setFinalizedBlockNumber(ups, t.canonicalNetwork(network), blockNumber, true)
+}
+
+// setFinalizedBlockNumber updates the finalized block of an upstream and the finalization lags,
+// explicit is false for numbers derived from the finality depth.
+func (t *Tracker) setFinalizedBlockNumber(ups, network string, blockNumber int64, explicit bool) {
 	t.logger.Trace().Str("upstreamId", ups).Str("networkId", network).Int64("value", blockNumber).Msg("updating finalized block number in tracker")
 
 	if blockNumber <= 0 {
 		t.logger.Warn().Str("upstreamId", ups).Str("networkId", network).Int64("value", blockNumber).Msg("ignoring setting non-positive block number in finalized block tracker")
 		return
 	}
+	if t.exceedsBlockNumberCeiling(ups, network, "finalized", blockNumber) {
+		return
+	}
 
 	mdKey := duoKey{ups, network}
 	ntwMdKey := duoKey{"*", network}
 
 	upsMeta := t.getMetadata(mdKey)
 	ntwMeta := t.getMetadata(ntwMdKey)
+	if explicit {
+		upsMeta.explicitFinalized.Store(true)
+	}
 
 	// Possibly update the network-level highest finalized block
 	oldNtwVal := ntwMeta.evmFinalizedBlockNumber.Load()
@@ -453,7 +1370,7 @@ func (t *Tracker) SetFinalizedBlockNumber(ups, network string, blockNumber int64
 	if blockNumber > oldNtwVal {
 		ntwMeta.evmFinalizedBlockNumber.Store(blockNumber)
 		telemetry.MetricUpstreamFinalizedBlockNumber.
-			WithLabelValues(t.projectId, network, "*").
+			WithLabelValues(t.projectId, network, "*", "").
 			Set(float64(blockNumber))
 		needsGlobalUpdate = true
 	}
@@ -463,11 +1380,14 @@ func (t *Tracker) SetFinalizedBlockNumber(ups, network string, blockNumber int64
 	if blockNumber > oldUpsVal {
 		upsMeta.evmFinalizedBlockNumber.Store(blockNumber)
 		telemetry.MetricUpstreamFinalizedBlockNumber.
-			WithLabelValues(t.projectId, network, ups).
+			WithLabelValues(t.projectId, network, ups, t.upstreamVendor(ups, network)).
 			Set(float64(blockNumber))
 	}
 
-	// Recompute finalization lag for this upstream
+	// Recompute finalization lag for this upstream, or for all of them once the reorg is over
+	if t.reorgInProgress(network, ntwMeta) {
+		return
+	}
 	ntwVal := ntwMeta.evmFinalizedBlockNumber.Load()
 	if ntwVal <= 0 {
 		t.logger.Warn().Str("upstreamId", ups).Str("networkId", network).Int64("value", ntwVal).Msg("ignoring finalization lag tracking for negative block number in tracker")
@@ -479,32 +1399,12 @@ func (t *Tracker) SetFinalizedBlockNumber(ups, network string, blockNumber int64
 
 	// Update Prometheus for this upstream
 	telemetry.MetricUpstreamFinalizationLag.
-		WithLabelValues(t.projectId, network, ups).
+		WithLabelValues(t.projectId, network, ups, t.upstreamVendor(ups, network)).
 		Set(float64(upsLag))
 
 	// Update the finalization lag across the network if needed
 	if needsGlobalUpdate {
-		t.metrics.Range(func(key, value any) bool {
-			k, ok := key.(tripletKey)
-			if !ok {
-				return true
-			}
-			if k.network == network {
-				tm := value.(*TrackedMetrics)
-				otherUpsMeta := t.getMetadata(duoKey{ups: k.ups, network: k.network})
-				otherVal := otherUpsMeta.evmFinalizedBlockNumber.Load()
-				if otherVal <= 0 {
-					t.logger.Debug().Str("upstreamId", k.ups).Str("networkId", network).Int64("value", otherVal).Msg("ignoring finalization lag tracking for non-positive block number in tracker")
-					return true
-				}
-				otherLag := ntwVal - otherVal
-				tm.FinalizationLag.Store(otherLag)
-				telemetry.MetricUpstreamFinalizationLag.
-					WithLabelValues(t.projectId, network, k.ups).
-					Set(float64(otherLag))
-			}
-			return true
-		})
+		t.recomputeFinalizationLags(network, ntwVal)
 	} else {
 		// Only update finalization lag for this single upstream
 		t.metrics.Range(func(key, value any) bool {
@@ -521,22 +1421,61 @@ func (t *Tracker) SetFinalizedBlockNumber(ups, network string, blockNumber int64
 	}
 }
 
+// recomputeFinalizationLags sets the finalization lag of every upstream of a network against its
+// highest finalized block ntwVal.
+func (t *Tracker) recomputeFinalizationLags(network string, ntwVal int64) {
+	t.lagRecomputes.Add(1)
+	t.metrics.Range(func(key, value any) bool {
+		k, ok := key.(tripletKey)
+		if !ok {
+			return true
+		}
+		if k.network == network {
+			tm := value.(*TrackedMetrics)
+			otherUpsMeta := t.getMetadata(duoKey{ups: k.ups, network: k.network})
+			otherVal := otherUpsMeta.evmFinalizedBlockNumber.Load()
+			if otherVal <= 0 {
+				t.logger.Debug().Str("upstreamId", k.ups).Str("networkId", network).Int64("value", otherVal).Msg("ignoring finalization lag tracking for non-positive block number in tracker")
+				return true
+			}
+			otherLag := ntwVal - otherVal
+			tm.FinalizationLag.Store(otherLag)
+			telemetry.MetricUpstreamFinalizationLag.
+				WithLabelValues(t.projectId, network, k.ups, t.upstreamVendor(k.ups, network)).
+				Set(float64(otherLag))
+		}
+		return true
+	})
+}
+
+// RecordBlockHeadLargeRollback records the latest or finalized block (see finality) of an upstream
+// going back from currentVal to newVal. The difference is stored in BlockHeadLargeRollback of the
+// keys about the upstream as a whole (see upstreamWideKeys): the upstream on the network, the
+// upstream across networks, and the network aggregate. Like the block head lag it is not reset
+// with the window, it lingers until the next rollback or decays, see
+// SetBlockHeadLargeRollbackDecay.
 func (t *Tracker) RecordBlockHeadLargeRollback(ups, network, finality string, currentVal, newVal int64) {
+	network = t.canonicalNetwork(network)
 	rollback := currentVal - newVal
 
-	k := tripletKey{ups: ups, network: network}
-	tm := t.getMetrics(k)
-	tm.BlockHeadLargeRollback.Store(rollback)
+	now := t.clock.Now().UnixNano()
+	for _, k := range upstreamWideKeys(ups, network) {
+		tm := t.getMetrics(k)
+		tm.BlockHeadLargeRollback.Store(rollback)
+		tm.rollbackPeak.Store(rollback)
+		tm.rollbackAt.Store(now)
+	}
 
 	t.logger.Debug().
 		Str("upstream", ups).
 		Str("network", network).
+		Str("finality", finality).
 		Int64("currentValue", currentVal).
 		Int64("newValue", newVal).
 		Int64("rollback", rollback).
 		Msgf("recording block rollback in tracker")
 
 	telemetry.MetricUpstreamBlockHeadLargeRollback.
-		WithLabelValues(t.projectId, network, ups).
+		WithLabelValues(t.projectId, network, ups, t.upstreamVendor(ups, network)).
 		Set(float64(rollback))
 }
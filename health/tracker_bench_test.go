@@ -336,3 +336,45 @@ func BenchmarkConcurrentCordonOperations(b *testing.B) {
 		}
 	})
 }
+
+func BenchmarkHealthSummary(b *testing.B) {
+	tracker := health.NewTracker(&log.Logger, "benchProj", time.Minute)
+	tracker.RecordUpstreamRequest("ups1", "evm:1", "eth_call")
+	tracker.RecordUpstreamFailure("ups1", "evm:1", "eth_call")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			tracker.HealthSummary("ups1", "evm:1")
+		}
+	})
+}
+
+// BenchmarkRecordOutcomeTelemetry compares the latency of recording outcomes with the telemetry
+// exported synchronously and through the queue of StartAsyncTelemetry.
+func BenchmarkRecordOutcomeTelemetry(b *testing.B) {
+	for _, async := range []bool{false, true} {
+		name := "Sync"
+		if async {
+			name = "Async"
+		}
+		b.Run(name, func(b *testing.B) {
+			tracker := health.NewTracker(&log.Logger, "benchProj", time.Minute)
+			if async {
+				tracker.StartAsyncTelemetry(context.Background(), 0)
+				defer tracker.StopAsyncTelemetry()
+			}
+			outcome := health.Outcome{Kind: health.OutcomeSuccess, Duration: 10 * time.Millisecond, Bytes: 512}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					ups, network, method := getRandomTestData()
+					tracker.RecordOutcome(ups, network, method, outcome)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package health_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health"
+	"github.com/erpc/erpc/health/healthtest"
+	"github.com/erpc/erpc/telemetry"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func newFakeClockTracker(t *testing.T, windowSize time.Duration) (*health.Tracker, *healthtest.FakeClock) {
+	telemetry.SetHistogramBuckets("0.05,0.5,5,30")
+	clock := healthtest.NewFakeClock(time.Unix(1700000000, 0))
+	tracker := health.NewTracker(&log.Logger, "test-project", windowSize)
+	tracker.SetClock(clock)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	tracker.Bootstrap(ctx)
+	clock.WaitForTickers(1)
+
+	return tracker, clock
+}
+
+func recordRequests(tracker *health.Tracker, network, upstream, method string, total, errors int) {
+	for i := 0; i < total; i++ {
+		tracker.RecordUpstreamRequest(upstream, network, method)
+		if i < errors {
+			tracker.RecordUpstreamFailure(upstream, network, method)
+		}
+	}
+}
+
+func TestTrackerWindows(t *testing.T) {
+	networkID := "evm:123"
+	windowSize := 2000 * time.Millisecond
+
+	t.Run("MetricsOverTime", func(t *testing.T) {
+		tracker, clock := newFakeClockTracker(t, windowSize)
+
+		// First window
+		recordRequests(tracker, networkID, "a", "method1", 100, 10)
+
+		metrics1 := tracker.GetUpstreamMethodMetrics("a", networkID, "method1")
+		assert.Equal(t, int64(100), metrics1.RequestsTotal.Load())
+		assert.Equal(t, int64(10), metrics1.ErrorsTotal.Load())
+
+		healthtest.AdvanceWindow(t, clock, windowSize, metrics1)
+
+		// Second window
+		recordRequests(tracker, networkID, "a", "method1", 50, 5)
+
+		metrics2 := tracker.GetUpstreamMethodMetrics("a", networkID, "method1")
+		assert.Equal(t, int64(50), metrics2.RequestsTotal.Load())
+		assert.Equal(t, int64(5), metrics2.ErrorsTotal.Load())
+	})
+
+	t.Run("ResetMetrics", func(t *testing.T) {
+		tracker, clock := newFakeClockTracker(t, windowSize)
+		recordRequests(tracker, networkID, "a", "method1", 100, 10)
+
+		metricsBefore := tracker.GetUpstreamMethodMetrics("a", networkID, "method1")
+		assert.Equal(t, int64(100), metricsBefore.RequestsTotal.Load())
+		assert.Equal(t, int64(10), metricsBefore.ErrorsTotal.Load())
+		assert.Equal(t, int64(0), metricsBefore.SelfRateLimitedTotal.Load())
+		assert.Equal(t, int64(0), metricsBefore.RemoteRateLimitedTotal.Load())
+
+		healthtest.AdvanceWindow(t, clock, windowSize, metricsBefore)
+
+		metricsAfter := tracker.GetUpstreamMethodMetrics("a", networkID, "method1")
+		assert.Equal(t, int64(0), metricsAfter.RequestsTotal.Load())
+		assert.Equal(t, int64(0), metricsAfter.ErrorsTotal.Load())
+		assert.Equal(t, int64(0), metricsAfter.SelfRateLimitedTotal.Load())
+		assert.Equal(t, int64(0), metricsAfter.RemoteRateLimitedTotal.Load())
+	})
+
+	t.Run("LongTermMetrics", func(t *testing.T) {
+		tracker, clock := newFakeClockTracker(t, 500*time.Millisecond)
+
+		for i := 0; i < 5; i++ {
+			recordRequests(tracker, networkID, "a", "method1", 20, 2)
+			clock.Advance(50 * time.Millisecond)
+		}
+
+		metrics := tracker.GetUpstreamMethodMetrics("a", networkID, "method1")
+		assert.Equal(t, int64(100), metrics.RequestsTotal.Load())
+		assert.Equal(t, int64(10), metrics.ErrorsTotal.Load())
+	})
+
+	t.Run("LongTermMetricsReset", func(t *testing.T) {
+		tracker, clock := newFakeClockTracker(t, windowSize)
+
+		for i := 0; i < 5; i++ {
+			recordRequests(tracker, networkID, "a", "method1", 20, 2)
+
+			metrics := tracker.GetUpstreamMethodMetrics("a", networkID, "method1")
+			assert.Equal(t, int64(20), metrics.RequestsTotal.Load())
+			assert.Equal(t, int64(2), metrics.ErrorsTotal.Load())
+
+			healthtest.AdvanceWindow(t, clock, windowSize, metrics)
+		}
+	})
+}
+
+func TestTimerUsesClock(t *testing.T) {
+	tracker, clock := newFakeClockTracker(t, time.Hour)
+
+	timer := tracker.RecordUpstreamDurationStart("a", "evm:123", "method1", "none")
+	clock.Advance(250 * time.Millisecond)
+	timer.ObserveDuration()
+
+	metrics := tracker.GetUpstreamMethodMetrics("a", "evm:123", "method1")
+	assert.InDelta(t, 0.25, metrics.ResponseQuantiles.GetQuantile(0.5).Seconds(), 0.005)
+}
+
+// boundaryClock ticks on the multiples of the interval since the epoch, like a clock aligned on
+// wall time, instead of one interval after the ticker creation.
+type boundaryClock struct {
+	*healthtest.FakeClock
+}
+
+type boundaryTicker struct {
+	c    chan time.Time
+	stop func()
+}
+
+func (b *boundaryTicker) C() <-chan time.Time { return b.c }
+func (b *boundaryTicker) Stop()               { b.stop() }
+
+func (c boundaryClock) NewTicker(d time.Duration) health.Ticker {
+	inner := c.FakeClock.NewTicker(d / 4)
+	done := make(chan struct{})
+	b := &boundaryTicker{c: make(chan time.Time), stop: func() { inner.Stop(); close(done) }}
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-inner.C():
+				if now.UnixNano()%int64(d) == 0 {
+					select {
+					case b.c <- now:
+					case <-done:
+						return
+					}
+				}
+			}
+		}
+	}()
+	return b
+}
+
+func TestFirstResetWaitsAFullWindow(t *testing.T) {
+	window := time.Minute
+	// Bootstrapped a quarter of a window before a tick boundary
+	clock := boundaryClock{healthtest.NewFakeClock(time.Unix(0, 0).Add(1000*window - window/4))}
+	tracker := health.NewTracker(&log.Logger, "test-project", window)
+	tracker.SetClock(clock)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	tracker.Bootstrap(ctx)
+	clock.WaitForTickers(1)
+
+	recordRequests(tracker, "evm:123", "a", "eth_call", 10, 0)
+	m := tracker.GetUpstreamMethodMetrics("a", "evm:123", "eth_call")
+
+	// The boundary tick right after bootstrap is skipped, and so are the following ones until a
+	// full window elapsed
+	clock.Advance(window)
+	assert.Never(t, func() bool {
+		return m.RequestsTotal.Load() == 0
+	}, 50*time.Millisecond, time.Millisecond)
+
+	// The next boundary is the first one at least a window after bootstrap
+	clock.Advance(window / 4)
+	assert.Eventually(t, func() bool {
+		return m.RequestsTotal.Load() == 0
+	}, time.Second, time.Millisecond)
+}
@@ -44,32 +44,6 @@ func TestTracker(t *testing.T) {
 		assert.Equal(t, int64(5), metrics2.ErrorsTotal.Load())
 	})
 
-	t.Run("MetricsOverTime", func(t *testing.T) {
-		tracker := NewTracker(&log.Logger, projectID, windowSize)
-
-		ctx, cancel := context.WithCancel(context.Background())
-		defer cancel()
-		tracker.Bootstrap(ctx)
-
-		ups := common.NewFakeUpstream("a")
-
-		// First window
-		simulateRequestMetrics(tracker, networkID, ups.Config().Id, "method1", 100, 10)
-
-		metrics1 := tracker.GetUpstreamMethodMetrics(ups.Config().Id, networkID, "method1")
-		assert.Equal(t, int64(100), metrics1.RequestsTotal.Load())
-		assert.Equal(t, int64(10), metrics1.ErrorsTotal.Load())
-
-		time.Sleep(windowSize + 10*time.Millisecond)
-
-		// Second window
-		simulateRequestMetrics(tracker, networkID, ups.Config().Id, "method1", 50, 5)
-
-		metrics2 := tracker.GetUpstreamMethodMetrics(ups.Config().Id, networkID, "method1")
-		assert.Equal(t, int64(50), metrics2.RequestsTotal.Load())
-		assert.Equal(t, int64(5), metrics2.ErrorsTotal.Load())
-	})
-
 	t.Run("RateLimitingMetrics", func(t *testing.T) {
 		tracker := NewTracker(&log.Logger, projectID, windowSize)
 
@@ -136,32 +110,6 @@ func TestTracker(t *testing.T) {
 		assert.True(t, errorRate2 < errorRate1 && errorRate1 < errorRate3, "Error rates should be ordered: ups2 < ups1 < ups3")
 	})
 
-	t.Run("ResetMetrics", func(t *testing.T) {
-		tracker := NewTracker(&log.Logger, projectID, windowSize)
-
-		ctx, cancel := context.WithCancel(context.Background())
-		defer cancel()
-		tracker.Bootstrap(ctx)
-
-		ups := common.NewFakeUpstream("a")
-
-		simulateRequestMetrics(tracker, networkID, ups.Config().Id, "method1", 100, 10)
-
-		metricsBefore := tracker.GetUpstreamMethodMetrics(ups.Config().Id, networkID, "method1")
-		assert.Equal(t, int64(100), metricsBefore.RequestsTotal.Load())
-		assert.Equal(t, int64(10), metricsBefore.ErrorsTotal.Load())
-		assert.Equal(t, int64(0), metricsBefore.SelfRateLimitedTotal.Load())
-		assert.Equal(t, int64(0), metricsBefore.RemoteRateLimitedTotal.Load())
-
-		time.Sleep(windowSize + 10*time.Millisecond)
-
-		metricsAfter := tracker.GetUpstreamMethodMetrics(ups.Config().Id, networkID, "method1")
-		assert.Equal(t, int64(0), metricsAfter.RequestsTotal.Load())
-		assert.Equal(t, int64(0), metricsAfter.ErrorsTotal.Load())
-		assert.Equal(t, int64(0), metricsAfter.SelfRateLimitedTotal.Load())
-		assert.Equal(t, int64(0), metricsAfter.RemoteRateLimitedTotal.Load())
-	})
-
 	t.Run("DifferentMethods", func(t *testing.T) {
 		tracker := NewTracker(&log.Logger, projectID, windowSize)
 
@@ -181,47 +129,6 @@ func TestTracker(t *testing.T) {
 		assert.Equal(t, int64(50), metrics2.RequestsTotal.Load())
 	})
 
-	t.Run("LongTermMetrics", func(t *testing.T) {
-		longWindowSize := 500 * time.Millisecond
-		tracker := NewTracker(&log.Logger, projectID, longWindowSize)
-
-		ctx, cancel := context.WithCancel(context.Background())
-		defer cancel()
-		tracker.Bootstrap(ctx)
-
-		ups := common.NewFakeUpstream("a")
-
-		for i := 0; i < 5; i++ {
-			simulateRequestMetrics(tracker, networkID, ups.Config().Id, "method1", 20, 2)
-			time.Sleep(50 * time.Millisecond)
-		}
-
-		metrics := tracker.GetUpstreamMethodMetrics(ups.Config().Id, networkID, "method1")
-		assert.Equal(t, int64(100), metrics.RequestsTotal.Load())
-		assert.Equal(t, int64(10), metrics.ErrorsTotal.Load())
-	})
-
-	t.Run("LongTermMetricsReset", func(t *testing.T) {
-		tracker := NewTracker(&log.Logger, projectID, windowSize)
-		resetMetrics()
-
-		ctx, cancel := context.WithCancel(context.Background())
-		defer cancel()
-		tracker.Bootstrap(ctx)
-
-		ups := common.NewFakeUpstream("a")
-
-		for i := 0; i < 5; i++ {
-			simulateRequestMetrics(tracker, networkID, ups.Config().Id, "method1", 20, 2)
-
-			metrics := tracker.GetUpstreamMethodMetrics(ups.Config().Id, networkID, "method1")
-			assert.Equal(t, int64(20), metrics.RequestsTotal.Load())
-			assert.Equal(t, int64(2), metrics.ErrorsTotal.Load())
-
-			time.Sleep(windowSize + 10*time.Millisecond)
-		}
-	})
-
 	t.Run("MultipleMethodsRequestsIncreaseNetworkOverall", func(t *testing.T) {
 		tracker := NewTracker(&log.Logger, projectID, windowSize)
 		ctx, cancel := context.WithCancel(context.Background())
@@ -325,6 +232,9 @@ func resetMetrics() {
 	if telemetry.MetricUpstreamRequestDuration != nil {
 		telemetry.MetricUpstreamRequestDuration.Reset()
 	}
+	if telemetry.MetricUpstreamAttemptDuration != nil {
+		telemetry.MetricUpstreamAttemptDuration.Reset()
+	}
 	if telemetry.MetricUpstreamErrorTotal != nil {
 		telemetry.MetricUpstreamErrorTotal.Reset()
 	}
@@ -0,0 +1,204 @@
+package health
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+
+	"github.com/erpc/erpc/telemetry"
+)
+
+const (
+	// DefaultTrafficShareDivergence is the divergence between the realized and intended traffic
+	// shares of a network above which a window counts towards EventTrafficShareDiverged.
+	DefaultTrafficShareDivergence = 0.25
+	// DefaultTrafficShareDivergenceWindows are the consecutive diverging windows needed to emit
+	// EventTrafficShareDiverged.
+	DefaultTrafficShareDivergenceWindows = 3
+
+	// minTrafficShareRequests are the requests a network needs in a window for its divergence to
+	// be evaluated, below that the realized shares are mostly noise.
+	minTrafficShareRequests = 100
+
+	EventTrafficShareDiverged EventType = "trafficShareDiverged"
+)
+
+// TrafficShares compares the share of the requests of a network served by each upstream in the
+// current window with the share intended by selection, see ReportIntendedShares.
+type TrafficShares struct {
+	// Realized are the shares of the requests of the window per upstream, shadow upstreams excluded
+	Realized map[string]float64 `json:"realized"`
+	// Intended are the shares last reported by selection, nil until reported
+	Intended map[string]float64 `json:"intended,omitempty"`
+	// Divergence is the total variation distance between Realized and Intended, from 0 when they
+	// match to 1 when no request went where intended. Zero until both are known.
+	Divergence float64 `json:"divergence"`
+	// Requests are the requests of the window the realized shares are computed from
+	Requests int64 `json:"requests"`
+}
+
+type trafficShareState struct {
+	intended atomic.Pointer[map[string]float64]
+
+	mu sync.Mutex
+	// divergingWindows are the consecutive windows above the divergence threshold
+	divergingWindows int
+}
+
+// SetTrafficShareDivergence sets the divergence and the consecutive windows above it which emit
+// EventTrafficShareDiverged. Non-positive values use the defaults.
+func (t *Tracker) SetTrafficShareDivergence(threshold float64, windows int) {
+	if threshold <= 0 {
+		threshold = DefaultTrafficShareDivergence
+	}
+	if windows <= 0 {
+		windows = DefaultTrafficShareDivergenceWindows
+	}
+	t.trafficShareDivergence.Store(math.Float64bits(threshold))
+	t.trafficShareWindows.Store(int64(windows))
+}
+
+func (t *Tracker) trafficShareDivergenceConfig() (float64, int) {
+	threshold, windows := DefaultTrafficShareDivergence, DefaultTrafficShareDivergenceWindows
+	if bits := t.trafficShareDivergence.Load(); bits != 0 {
+		threshold = math.Float64frombits(bits)
+	}
+	if w := t.trafficShareWindows.Load(); w > 0 {
+		windows = int(w)
+	}
+	return threshold, windows
+}
+
+// ReportIntendedShares records the traffic distribution selection intends for a network, as
+// weights per upstream which are normalized into shares. Negative weights count as zero, and
+// weights summing to zero are ignored.
+func (t *Tracker) ReportIntendedShares(network string, weights map[string]float64) {
+	network = t.canonicalNetwork(network)
+	var sum float64
+	for _, w := range weights {
+		if w > 0 {
+			sum += w
+		}
+	}
+	if sum <= 0 || math.IsInf(sum, 0) || math.IsNaN(sum) {
+		return
+	}
+	shares := make(map[string]float64, len(weights))
+	for ups, w := range weights {
+		shares[ups] = math.Max(w, 0) / sum
+	}
+	val, ok := t.trafficShares.Load(network)
+	if !ok {
+		val, _ = t.trafficShares.LoadOrStore(network, &trafficShareState{})
+	}
+	val.(*trafficShareState).intended.Store(&shares)
+}
+
+// GetTrafficShares returns the realized and intended traffic shares of a network in the current
+// window, see TrafficShares.
+func (t *Tracker) GetTrafficShares(network string) TrafficShares {
+	network = t.canonicalNetwork(network)
+	return t.trafficSharesOf(network)
+}
+
+func (t *Tracker) trafficSharesOf(network string) TrafficShares {
+	s := TrafficShares{Realized: make(map[string]float64)}
+	if set, ok := t.networkUpstreams.Load(network); ok {
+		requests := make(map[string]int64)
+		set.(*sync.Map).Range(func(key, _ any) bool {
+			ups := key.(string)
+			if t.IsShadowUpstream(ups) {
+				return true
+			}
+			var n int64
+			if val, ok := t.metrics.Load(tripletKey{ups, network, "*"}); ok {
+				n = val.(*TrackedMetrics).RequestsTotal.Load()
+			}
+			requests[ups] = n
+			s.Requests += n
+			return true
+		})
+		for ups, n := range requests {
+			s.Realized[ups] = boundedRatio(n, s.Requests)
+		}
+	}
+
+	val, ok := t.trafficShares.Load(network)
+	if !ok {
+		return s
+	}
+	intended := val.(*trafficShareState).intended.Load()
+	if intended == nil {
+		return s
+	}
+	s.Intended = *intended
+	if s.Requests == 0 {
+		return s
+	}
+	// Upstreams on one side only count fully towards the distance
+	var distance float64
+	for ups, r := range s.Realized {
+		distance += math.Abs(r - s.Intended[ups])
+	}
+	for ups, i := range s.Intended {
+		if _, ok := s.Realized[ups]; !ok {
+			distance += i
+		}
+	}
+	s.Divergence = math.Min(distance/2, 1)
+	return s
+}
+
+// rollTrafficShares exports the traffic shares of the closing window of the networks with
+// intended shares reported, and emits EventTrafficShareDiverged once a divergence is sustained.
+// It must run right before metrics are reset.
+func (t *Tracker) rollTrafficShares() {
+	threshold, windows := t.trafficShareDivergenceConfig()
+	t.trafficShares.Range(func(key, value any) bool {
+		network := key.(string)
+		st := value.(*trafficShareState)
+		s := t.trafficSharesOf(network)
+		if s.Requests < minTrafficShareRequests {
+			return true
+		}
+
+		for ups, share := range s.Realized {
+			telemetry.MetricUpstreamRealizedTrafficShare.WithLabelValues(t.projectId, network, ups, t.upstreamVendor(ups, network)).Set(share)
+		}
+		for ups, share := range s.Intended {
+			telemetry.MetricUpstreamIntendedTrafficShare.WithLabelValues(t.projectId, network, ups, t.upstreamVendor(ups, network)).Set(share)
+		}
+		telemetry.MetricNetworkTrafficShareDivergence.WithLabelValues(t.projectId, network).Set(s.Divergence)
+
+		st.mu.Lock()
+		if s.Divergence > threshold {
+			st.divergingWindows++
+		} else {
+			st.divergingWindows = 0
+		}
+		emit := st.divergingWindows == windows
+		st.mu.Unlock()
+
+		if emit {
+			t.emit(Event{
+				Type:      EventTrafficShareDiverged,
+				Network:   network,
+				Message:   fmt.Sprintf("realized traffic shares diverged from the intended ones by %.2f for %d windows, realized %v, intended %v", s.Divergence, windows, s.Realized, s.Intended),
+				Value:     s.Divergence,
+				Threshold: threshold,
+			})
+		}
+		return true
+	})
+}
+
+func (t *Tracker) allTrafficShares() map[string]TrafficShares {
+	shares := make(map[string]TrafficShares)
+	t.trafficShares.Range(func(key, _ any) bool {
+		network := key.(string)
+		shares[network] = t.trafficSharesOf(network)
+		return true
+	})
+	return shares
+}
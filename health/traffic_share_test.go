@@ -0,0 +1,90 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health/internal/metricstest"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrafficShares(t *testing.T) {
+	networkID := "evm:123"
+	record := func(tracker *Tracker, ups string, n int) {
+		for i := 0; i < n; i++ {
+			tracker.RecordUpstreamRequest(ups, networkID, "eth_call")
+		}
+	}
+
+	t.Run("ComparesRealizedWithIntended", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-traffic-shares", time.Minute)
+		record(tracker, "a", 30)
+		record(tracker, "b", 10)
+		tracker.SetUpstreamShadow("shadow", true)
+		record(tracker, "shadow", 40)
+
+		s := tracker.GetTrafficShares(networkID)
+		assert.Equal(t, map[string]float64{"a": 0.75, "b": 0.25}, s.Realized)
+		assert.Nil(t, s.Intended)
+		assert.Zero(t, s.Divergence)
+		assert.Equal(t, int64(40), s.Requests)
+
+		// Negative weights count as zero, upstreams without traffic count fully
+		tracker.ReportIntendedShares(networkID, map[string]float64{"a": 2, "b": 1, "c": 1, "d": -1})
+		s = tracker.GetTrafficShares(networkID)
+		assert.Equal(t, map[string]float64{"a": 0.5, "b": 0.25, "c": 0.25, "d": 0}, s.Intended)
+		assert.InDelta(t, 0.25, s.Divergence, 1e-9)
+
+		snapshot := tracker.GetNetworkUpstreamsMetrics(networkID, "eth_call")
+		assert.Equal(t, 0.75, snapshot["a"].RealizedShare)
+		assert.Equal(t, 0.5, snapshot["a"].IntendedShare)
+		assert.Zero(t, snapshot["shadow"].RealizedShare)
+
+		tracker.ReportIntendedShares(networkID, map[string]float64{"a": 0})
+		assert.Equal(t, 0.5, tracker.GetTrafficShares(networkID).Intended["a"], "weights summing to zero are ignored")
+	})
+
+	t.Run("EmitsOnSustainedDivergence", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-traffic-shares-diverged", time.Minute)
+		tracker.SetTrafficShareDivergence(0.3, 2)
+		events, unsubscribe := tracker.Subscribe(4)
+		defer unsubscribe()
+		tracker.ReportIntendedShares(networkID, map[string]float64{"a": 1, "b": 1})
+
+		// Windows with too few requests are not evaluated
+		record(tracker, "a", 50)
+		tracker.rollWindow(time.Now())
+		assert.Empty(t, metricstest.Samples(t, "erpc_network_traffic_share_divergence", map[string]string{"project": "test-traffic-shares-diverged"}))
+
+		for i := 0; i < 3; i++ {
+			record(tracker, "a", 90)
+			record(tracker, "b", 10)
+			tracker.rollWindow(time.Now())
+		}
+		require.Len(t, events, 1, "emitted once per sustained divergence")
+		e := <-events
+		assert.Equal(t, EventTrafficShareDiverged, e.Type)
+		assert.Equal(t, networkID, e.Network)
+		assert.InDelta(t, 0.4, e.Value, 1e-9)
+		assert.Equal(t, 0.3, e.Threshold)
+
+		networkLabels := map[string]string{"project": "test-traffic-shares-diverged", "network": networkID}
+		assert.InDelta(t, 0.4, metricstest.Value(t, "erpc_network_traffic_share_divergence", networkLabels), 1e-9)
+		upstreamLabels := map[string]string{"project": "test-traffic-shares-diverged", "network": networkID, "upstream": "a"}
+		assert.InDelta(t, 0.9, metricstest.Value(t, "erpc_upstream_realized_traffic_share", upstreamLabels), 1e-9)
+		assert.InDelta(t, 0.5, metricstest.Value(t, "erpc_upstream_intended_traffic_share", upstreamLabels), 1e-9)
+
+		// A matching window re-arms the event
+		record(tracker, "a", 50)
+		record(tracker, "b", 50)
+		tracker.rollWindow(time.Now())
+		for i := 0; i < 2; i++ {
+			record(tracker, "a", 90)
+			record(tracker, "b", 10)
+			tracker.rollWindow(time.Now())
+		}
+		assert.Len(t, events, 1)
+	})
+}
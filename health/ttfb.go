@@ -0,0 +1,37 @@
+package health
+
+// ObserveFirstByte marks the time the first byte of the response was received, reported as the
+// time to first byte by ObserveOutcome. Only the first call is kept.
+func (t *Timer) ObserveFirstByte() {
+	if t.ttfb == 0 {
+		t.ttfb = t.clock.Now().Sub(t.start)
+	}
+}
+
+// GetTTFBQuantiles returns the time to first byte quantiles of (ups, network, method), or nil when
+// no TTFB was ever reported for it. Full durations are still tracked by ResponseQuantiles.
+func (t *Tracker) GetTTFBQuantiles(ups, network, method string) *QuantileTracker {
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
+	val, ok := t.metrics.Load(tripletKey{ups, network, method})
+	if !ok {
+		return nil
+	}
+	return val.(*TrackedMetrics).ttfbQuantiles.Load()
+}
+
+func (m *TrackedMetrics) ttfbQuantilesOrNew() *QuantileTracker {
+	if qt := m.ttfbQuantiles.Load(); qt != nil {
+		return qt
+	}
+	m.ttfbQuantiles.CompareAndSwap(nil, m.newQuantileTracker())
+	return m.ttfbQuantiles.Load()
+}
+
+func (m *TrackedMetrics) ttfbP90() interface{} {
+	qt := m.ttfbQuantiles.Load()
+	if qt == nil || !qt.HasSamples() {
+		return nil
+	}
+	return qt.GetQuantile(0.90).Seconds()
+}
@@ -0,0 +1,67 @@
+package health_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTTFB(t *testing.T) {
+	networkID := "evm:123"
+
+	t.Run("TrackedApartFromFullDuration", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		for i := 0; i < 10; i++ {
+			tracker.RecordOutcome("a", networkID, "eth_getLogs", health.Outcome{
+				Kind:     health.OutcomeSuccess,
+				Duration: 2 * time.Second,
+				TTFB:     50 * time.Millisecond,
+			})
+		}
+
+		qt := tracker.GetTTFBQuantiles("a", networkID, "eth_getLogs")
+		require.NotNil(t, qt)
+		assert.InEpsilon(t, 0.05, qt.GetQuantile(0.9).Seconds(), 0.02)
+		m := tracker.GetUpstreamMethodMetrics("a", networkID, "eth_getLogs")
+		assert.InEpsilon(t, 2, m.ResponseQuantiles.GetQuantile(0.9).Seconds(), 0.02)
+		assert.NotNil(t, tracker.GetTTFBQuantiles("a", networkID, "*"))
+
+		b, err := m.MarshalJSON()
+		require.NoError(t, err)
+		assert.Contains(t, string(b), `"ttfbP90":0.05`)
+
+		m.Reset()
+		assert.False(t, qt.HasSamples())
+	})
+
+	t.Run("NotAllocatedWithoutTTFB", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		tracker.RecordOutcome("a", networkID, "eth_call", health.Outcome{Kind: health.OutcomeSuccess, Duration: time.Second})
+
+		assert.Nil(t, tracker.GetTTFBQuantiles("a", networkID, "eth_call"))
+		assert.Nil(t, tracker.GetTTFBQuantiles("unknown", networkID, "eth_call"))
+		b, err := tracker.GetUpstreamMethodMetrics("a", networkID, "eth_call").MarshalJSON()
+		require.NoError(t, err)
+		assert.Contains(t, string(b), `"ttfbP90":null`)
+	})
+
+	t.Run("TimerMarksFirstByte", func(t *testing.T) {
+		tracker, clock := newFakeClockTracker(t, time.Minute)
+		timer := tracker.RecordUpstreamDurationStart("a", networkID, "eth_getLogs", "none")
+		clock.Advance(100 * time.Millisecond)
+		timer.ObserveFirstByte()
+		clock.Advance(400 * time.Millisecond)
+		timer.ObserveFirstByte()
+		clock.Advance(500 * time.Millisecond)
+		timer.ObserveOutcome(health.Outcome{Kind: health.OutcomeSuccess})
+
+		qt := tracker.GetTTFBQuantiles("a", networkID, "eth_getLogs")
+		require.NotNil(t, qt)
+		assert.InEpsilon(t, 0.1, qt.GetQuantile(0.5).Seconds(), 0.02)
+		m := tracker.GetUpstreamMethodMetrics("a", networkID, "eth_getLogs")
+		assert.InEpsilon(t, 1, m.ResponseQuantiles.GetQuantile(0.5).Seconds(), 0.02)
+	})
+}
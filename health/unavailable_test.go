@@ -0,0 +1,47 @@
+package health_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnavailableRate(t *testing.T) {
+	networkID := "evm:123"
+
+	t.Run("CountsErrorsAndRemoteRateLimits", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		recordRequests(tracker, networkID, "a", "eth_call", 100, 10)
+		for i := 0; i < 15; i++ {
+			tracker.RecordUpstreamRemoteRateLimited("a", networkID, "eth_call")
+		}
+
+		m := tracker.GetUpstreamMethodMetrics("a", networkID, "eth_call")
+		assert.InDelta(t, 0.1, m.ErrorRate(), 1e-9)
+		assert.InDelta(t, 0.25, m.UnavailableRate(), 1e-9)
+		assert.InDelta(t, 0.25, tracker.GetUpstreamMethodMetrics("a", networkID, "*").UnavailableRate(), 1e-9)
+
+		b, err := m.MarshalJSON()
+		require.NoError(t, err)
+		var out struct {
+			UnavailableRate float64 `json:"unavailableRate"`
+		}
+		require.NoError(t, json.Unmarshal(b, &out))
+		assert.InDelta(t, 0.25, out.UnavailableRate, 1e-9)
+	})
+
+	t.Run("ExcludesSelfRateLimits", func(t *testing.T) {
+		tracker, _ := newFakeClockTracker(t, time.Minute)
+		recordRequests(tracker, networkID, "a", "eth_call", 10, 1)
+		for i := 0; i < 50; i++ {
+			tracker.RecordUpstreamSelfRateLimited("a", networkID, "eth_call")
+		}
+
+		m := tracker.GetUpstreamMethodMetrics("a", networkID, "eth_call")
+		assert.InDelta(t, 0.1, m.UnavailableRate(), 1e-9)
+		assert.Greater(t, m.ThrottledRate(), m.UnavailableRate(), "self rate limits still count as throttled")
+	})
+}
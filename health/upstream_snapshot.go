@@ -0,0 +1,69 @@
+package health
+
+import "time"
+
+// QuantileSummary is the distribution of a QuantileTracker materialized at one point in time.
+type QuantileSummary struct {
+	P50   time.Duration `json:"p50"`
+	P90   time.Duration `json:"p90"`
+	P99   time.Duration `json:"p99"`
+	Count int64         `json:"count"`
+}
+
+// Summary materializes the quantiles of the tracker from a single read of its sketch, zero when
+// it has no samples.
+func (q *QuantileTracker) Summary() QuantileSummary {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	sketch := q.currentLocked()
+	if sketch.IsEmpty() {
+		return QuantileSummary{}
+	}
+	s := QuantileSummary{Count: int64(sketch.GetCount())}
+	if values, err := sketch.GetValuesAtQuantiles([]float64{0.5, 0.9, 0.99}); err == nil {
+		s.P50 = time.Duration(values[0] * float64(time.Second))
+		s.P90 = time.Duration(values[1] * float64(time.Second))
+		s.P99 = time.Duration(values[2] * float64(time.Second))
+	}
+	return s
+}
+
+// GetUpstreamMetricsSnapshot returns snapshots of the metrics of every key of an upstream, keyed
+// by "network|method" like GetUpstreamMetrics, which read paths can range over and keep while the
+// tracker goes on recording. Unlike GetNetworkUpstreamsMetrics they only carry the metrics of the
+// key itself.
+func (t *Tracker) GetUpstreamMetricsSnapshot(upsId string) map[string]*TrackedMetricsSnapshot {
+	result := make(map[string]*TrackedMetricsSnapshot)
+	t.metrics.Range(func(key, value any) bool {
+		k, ok := key.(tripletKey)
+		if !ok || k.ups != upsId {
+			return true
+		}
+		result[k.network+"|"+k.method] = t.keySnapshotOf(value.(*TrackedMetrics), k)
+		return true
+	})
+	return result
+}
+
+// keySnapshotOf fills a snapshot with the metrics of k itself, their counters read once
+// consistently with window resets.
+func (t *Tracker) keySnapshotOf(m *TrackedMetrics, k tripletKey) *TrackedMetricsSnapshot {
+	s := &TrackedMetricsSnapshot{
+		SelectionView: t.selectionViewOf(m, k.ups, k.network, k.method),
+	}
+	s.SelfRateLimitedTotal = m.SelfRateLimitedTotal.Load()
+	s.RemoteRateLimitedTotal = m.RemoteRateLimitedTotal.Load()
+	s.DisagreementRate = m.DisagreementRate()
+	s.MismatchRate = m.MismatchRate()
+	s.PartialResponseRate = m.PartialResponseRate()
+	s.ShortResultsTotal = m.ShortResultsTotal.Load()
+	s.ShortResultMissingTotal = m.ShortResultMissingTotal.Load()
+	s.WeightedErrorRate = s.ErrorRate
+	s.ResponseQuantiles = m.ResponseQuantiles.Summary()
+	if info := m.CordonInfo(); info != nil {
+		cp := *info
+		s.CordonInfo = &cp
+	}
+	s.Lifetime = m.Lifetime()
+	return s
+}
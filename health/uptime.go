@@ -0,0 +1,231 @@
+package health
+
+import (
+	"context"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/erpc/erpc/telemetry"
+)
+
+const (
+	// UptimeInterval is how often the availability of each upstream is evaluated, see Uptime.
+	UptimeInterval = time.Minute
+	// UptimePeriod is the period covered by Uptime.
+	UptimePeriod = 24 * time.Hour
+	// DefaultUptimeMaxErrorRate is the error rate from which an interval with traffic counts as
+	// unavailable, see SetUptimeMaxErrorRate.
+	DefaultUptimeMaxErrorRate = 0.5
+
+	uptimeSlots = int64(UptimePeriod / UptimeInterval)
+)
+
+type uptimeState uint8
+
+const (
+	uptimeUnknown uptimeState = iota
+	uptimeUp
+	uptimeDown
+)
+
+// Uptime is the availability of an upstream on a network over the last UptimePeriod.
+type Uptime struct {
+	// Ratio is the fraction of the known intervals during which the upstream was available, zero
+	// when none is known
+	Ratio float64 `json:"ratio"`
+	// Unknown is the fraction of the period without a result, e.g. before a restart of the process
+	// or for idle upstreams never probed, which is excluded from Ratio
+	Unknown float64 `json:"unknown"`
+}
+
+// uptimeRing keeps the availability of the intervals of the last UptimePeriod of an upstream.
+type uptimeRing struct {
+	mu    sync.Mutex
+	slots [uptimeSlots]uptimeState
+	// up and down count the slots in each state
+	up, down int64
+	// last is the number of the last evaluated interval, zero before the first evaluation
+	last int64
+	// Lifetime totals of the upstream at the last evaluation
+	requests, errors int64
+
+	probe atomic.Int32 // uptimeState of the last probe, see RecordUpstreamProbe
+}
+
+// SetUptimeMaxErrorRate sets the error rate from which an interval with traffic counts as
+// unavailable in Uptime. A non-positive rate restores DefaultUptimeMaxErrorRate.
+func (t *Tracker) SetUptimeMaxErrorRate(rate float64) {
+	if rate <= 0 {
+		t.uptimeMaxErrorRate.Store(0)
+		return
+	}
+	t.uptimeMaxErrorRate.Store(math.Float64bits(rate))
+}
+
+func (t *Tracker) uptimeErrorRateThreshold() float64 {
+	if bits := t.uptimeMaxErrorRate.Load(); bits != 0 {
+		return math.Float64frombits(bits)
+	}
+	return DefaultUptimeMaxErrorRate
+}
+
+// RecordUpstreamProbe records the outcome of a background probe of an upstream on a network, such
+// as a state poll, which tells the availability of intervals during which it served no request.
+func (t *Tracker) RecordUpstreamProbe(ups, network string, success bool) {
+	network = t.canonicalNetwork(network)
+	state := uptimeDown
+	if success {
+		state = uptimeUp
+	}
+	t.indexNetworkUpstream(tripletKey{ups, network, "*"})
+	t.uptimeRing(duoKey{ups: ups, network: network}).probe.Store(int32(state))
+}
+
+// Uptime returns the availability of an upstream on a network over the last UptimePeriod. Each
+// UptimeInterval the upstream counts as available if it is not cordoned and either served at
+// least one request successfully with an error rate under SetUptimeMaxErrorRate, or served none
+// and its last probe succeeded (see RecordUpstreamProbe). Intervals without a result, e.g. before
+// the process started, are reported as unknown.
+func (t *Tracker) Uptime(ups, network string) Uptime {
+	network = t.canonicalNetwork(network)
+	return t.uptimeOf(duoKey{ups: ups, network: network}, uptimeIntervalOf(t.clock.Now()))
+}
+
+func (t *Tracker) uptimeOf(k duoKey, n int64) Uptime {
+	val, ok := t.uptimes.Load(k)
+	if !ok {
+		return Uptime{Unknown: 1}
+	}
+	return val.(*uptimeRing).uptime(n)
+}
+
+func (t *Tracker) uptimeRing(k duoKey) *uptimeRing {
+	if val, ok := t.uptimes.Load(k); ok {
+		return val.(*uptimeRing)
+	}
+	val, _ := t.uptimes.LoadOrStore(k, &uptimeRing{})
+	return val.(*uptimeRing)
+}
+
+// uptimeIntervalOf returns the number of the interval ending at now. It is rounded so that the
+// ticks of the evaluation loop get consecutive numbers despite their jitter.
+func uptimeIntervalOf(now time.Time) int64 {
+	return (now.UnixNano() + int64(UptimeInterval)/2) / int64(UptimeInterval)
+}
+
+// uptimeLoop evaluates the availability of every upstream each UptimeInterval.
+func (t *Tracker) uptimeLoop(ctx context.Context, ticker Ticker) {
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C():
+			t.evaluateUptimes(now)
+		}
+	}
+}
+
+func (t *Tracker) evaluateUptimes(now time.Time) {
+	n := uptimeIntervalOf(now)
+	maxErrorRate := t.uptimeErrorRateThreshold()
+	t.networkUpstreams.Range(func(key, set any) bool {
+		network := key.(string)
+		set.(*sync.Map).Range(func(key, _ any) bool {
+			ups := key.(string)
+			var lifetime LifetimeTotals
+			cordoned := false
+			if val, ok := t.metrics.Load(tripletKey{ups, network, "*"}); ok {
+				m := val.(*TrackedMetrics)
+				lifetime = m.Lifetime()
+				cordoned = m.CordonInfo() != nil
+			}
+			r := t.uptimeRing(duoKey{ups: ups, network: network})
+			r.evaluate(n, lifetime, cordoned, maxErrorRate)
+			u := r.uptime(n)
+			vendor := t.upstreamVendor(ups, network)
+			telemetry.MetricUpstreamUptimeRatio.WithLabelValues(t.projectId, network, ups, vendor).Set(u.Ratio)
+			telemetry.MetricUpstreamUptimeUnknownRatio.WithLabelValues(t.projectId, network, ups, vendor).Set(u.Unknown)
+			return true
+		})
+		return true
+	})
+}
+
+// evaluate records the availability of interval n, given the lifetime totals of the upstream.
+func (r *uptimeRing) evaluate(n int64, lifetime LifetimeTotals, cordoned bool, maxErrorRate float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if n <= r.last {
+		return
+	}
+
+	// Intervals skipped since the last evaluation are unknown
+	if r.last > 0 {
+		for i := r.last + 1; i < n && i <= r.last+uptimeSlots; i++ {
+			r.setSlot(i, uptimeUnknown)
+		}
+	}
+
+	requests := max(lifetime.RequestsTotal-r.requests, 0)
+	errors := min(max(lifetime.ErrorsTotal-r.errors, 0), requests)
+	r.requests, r.errors = lifetime.RequestsTotal, lifetime.ErrorsTotal
+
+	state := uptimeState(r.probe.Load())
+	switch {
+	case cordoned:
+		state = uptimeDown
+	case requests > 0:
+		state = uptimeDown
+		if errors < requests && float64(errors)/float64(requests) < maxErrorRate {
+			state = uptimeUp
+		}
+	}
+	r.setSlot(n, state)
+	r.last = n
+}
+
+func (r *uptimeRing) setSlot(n int64, state uptimeState) {
+	slot := &r.slots[n%uptimeSlots]
+	switch *slot {
+	case uptimeUp:
+		r.up--
+	case uptimeDown:
+		r.down--
+	}
+	*slot = state
+	switch state {
+	case uptimeUp:
+		r.up++
+	case uptimeDown:
+		r.down++
+	}
+}
+
+// uptime returns the availability over the intervals of the period ending with interval n.
+func (r *uptimeRing) uptime(n int64) Uptime {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.last == 0 || n-r.last >= uptimeSlots {
+		return Uptime{Unknown: 1}
+	}
+
+	// Slots of the intervals not evaluated yet hold results from before the period
+	up, down := r.up, r.down
+	for i := r.last + 1; i <= n; i++ {
+		switch r.slots[i%uptimeSlots] {
+		case uptimeUp:
+			up--
+		case uptimeDown:
+			down--
+		}
+	}
+	u := Uptime{Unknown: 1 - float64(up+down)/float64(uptimeSlots)}
+	if up+down > 0 {
+		u.Ratio = float64(up) / float64(up+down)
+	}
+	return u
+}
@@ -0,0 +1,62 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health/internal/metricstest"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUptime(t *testing.T) {
+	networkID := "evm:123"
+	tracker := NewTracker(&log.Logger, "test-project", time.Hour)
+	start := time.Now()
+	at := func(i int) time.Time { return start.Add(time.Duration(i) * UptimeInterval) }
+	uptime := func(ups string, i int) Uptime {
+		return tracker.uptimeOf(duoKey{ups: ups, network: networkID}, uptimeIntervalOf(at(i)))
+	}
+	slot := 1.0 / float64(uptimeSlots)
+
+	assert.Equal(t, Uptime{Unknown: 1}, tracker.Uptime("a", networkID))
+
+	// Traffic under the error rate threshold, too many errors, or idle with a probe
+	simulateRequestMetrics(tracker, networkID, "a", "eth_call", 10, 4)
+	simulateRequestMetrics(tracker, networkID, "b", "eth_call", 10, 5)
+	tracker.RecordUpstreamProbe("c", networkID, true)
+	tracker.SetLatestBlockNumber("d", networkID, 100)
+	tracker.evaluateUptimes(at(1))
+	assert.Equal(t, Uptime{Ratio: 1, Unknown: 1 - slot}, uptime("a", 1))
+	assert.Equal(t, Uptime{Ratio: 0, Unknown: 1 - slot}, uptime("b", 1))
+	assert.Equal(t, Uptime{Ratio: 1, Unknown: 1 - slot}, uptime("c", 1))
+	assert.Equal(t, Uptime{Unknown: 1}, uptime("d", 1))
+	assert.Equal(t, 1-slot, metricstest.Value(t, "erpc_upstream_uptime_unknown_ratio", map[string]string{"project": "test-project", "network": networkID, "upstream": "a"}))
+
+	// Only the traffic of the interval counts, a cordon makes it unavailable
+	simulateRequestMetrics(tracker, networkID, "a", "eth_call", 10, 0)
+	simulateRequestMetrics(tracker, networkID, "b", "eth_call", 10, 0)
+	tracker.Cordon("a", networkID, "*", "incident")
+	tracker.RecordUpstreamProbe("c", networkID, false)
+	tracker.evaluateUptimes(at(2))
+	assert.Equal(t, Uptime{Ratio: 0.5, Unknown: 1 - 2*slot}, uptime("a", 2))
+	assert.Equal(t, Uptime{Ratio: 0.5, Unknown: 1 - 2*slot}, uptime("b", 2))
+	assert.Equal(t, Uptime{Ratio: 0.5, Unknown: 1 - 2*slot}, uptime("c", 2))
+	assert.Equal(t, 0.5, metricstest.Value(t, "erpc_upstream_uptime_ratio", map[string]string{"project": "test-project", "network": networkID, "upstream": "b"}))
+
+	// Intervals without evaluation, e.g. while the process was down, are unknown
+	tracker.Uncordon("a", networkID, "*")
+	simulateRequestMetrics(tracker, networkID, "a", "eth_call", 1, 0)
+	tracker.evaluateUptimes(at(10))
+	assert.InDelta(t, 2.0/3, uptime("a", 10).Ratio, 1e-9)
+	assert.InDelta(t, 1-3*slot, uptime("a", 10).Unknown, 1e-9)
+	assert.InDelta(t, 2.0/3, tracker.GetNetworkUpstreamsMetrics(networkID, "*")["a"].Uptime.Ratio, 1e-9)
+
+	// Results older than the period drop out of it
+	assert.InDelta(t, 1.0/2, uptime("a", 1+int(uptimeSlots)).Ratio, 1e-9)
+	assert.InDelta(t, 1-2*slot, uptime("a", 1+int(uptimeSlots)).Unknown, 1e-9)
+	assert.Equal(t, Uptime{Unknown: 1}, uptime("a", 10+int(uptimeSlots)))
+	tracker.RecordUpstreamProbe("a", networkID, false)
+	tracker.evaluateUptimes(at(20 + int(uptimeSlots)))
+	assert.Equal(t, Uptime{Ratio: 0, Unknown: 1 - slot}, uptime("a", 20+int(uptimeSlots)))
+}
@@ -0,0 +1,117 @@
+package health
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/erpc/erpc/telemetry"
+)
+
+// maxViewAttempts bounds how many times SelectionView retries when racing with a window reset.
+const maxViewAttempts = 4
+
+// SelectionView is a consistent snapshot of what upstream selection needs to know about a key.
+type SelectionView struct {
+	Cordoned          bool
+	CordonedReason    string
+	CordonedSource    string // see CordonInfo.Origin
+	RequestsTotal     int64
+	ErrorsTotal       int64
+	ErrorRate         float64
+	ThrottledRate     float64
+	HasLatency        bool
+	P90Latency        time.Duration // first attempts only by default, see SetScoreRetryLatency
+	BlockHeadLag      int64         // at least the lag evidenced by behind head errors, see SetBehindHeadEvidenceLag
+	FinalizationLag   int64
+	RequestsPerSecond float64
+	SeededRequests    int64 // synthetic requests of a baseline included in the totals, see SeedBaseline
+	// PartialResponseRate is the share of requests answered incompletely, see RecordUpstreamPartialResponse
+	PartialResponseRate float64
+}
+
+// SelectionView returns the cordon state and health numbers of (ups, network, method) captured
+// in a single pass. The pass is retried if a window reset happens meanwhile, so that the view
+// does not mix values from before and after a reset. If resets keep racing after maxViewAttempts
+// the last read is returned anyway and counted in MetricSelectionViewInconsistentTotal.
+func (t *Tracker) SelectionView(ups, network, method string) SelectionView {
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
+	return t.selectionViewOf(t.getMetrics(tripletKey{ups, network, method}), ups, network, method)
+}
+
+// selectionViewOf captures the view of m, the metrics of (ups, network, method) which must
+// already be canonical.
+func (t *Tracker) selectionViewOf(m *TrackedMetrics, ups, network, method string) SelectionView {
+	retries := t.scoreRetryLatency.Load()
+
+	var v SelectionView
+	consistent := false
+	for attempt := 0; attempt < maxViewAttempts && !consistent; attempt++ {
+		gen := m.resetGen.Load()
+		if gen%2 == 1 {
+			// Reset in progress
+			runtime.Gosched()
+			continue
+		}
+		v = m.selectionView(retries)
+		consistent = m.resetGen.Load() == gen
+	}
+	if !consistent {
+		v = m.selectionView(retries)
+		telemetry.MetricSelectionViewInconsistentTotal.WithLabelValues(t.projectId, network).Inc()
+	}
+	return t.withUpstreamCordon(v, ups, network, method)
+}
+
+// withUpstreamCordon marks a method view as cordoned when the whole upstream is cordoned on the
+// network.
+func (t *Tracker) withUpstreamCordon(v SelectionView, ups, network, method string) SelectionView {
+	if !v.Cordoned && method != "*" {
+		if val, ok := t.metrics.Load(tripletKey{ups, network, "*"}); ok {
+			if cordon := val.(*TrackedMetrics).CordonInfo(); cordon != nil {
+				v.Cordoned = true
+				v.CordonedReason = cordon.Reason
+				v.CordonedSource = cordon.Origin()
+			}
+		}
+	}
+	return v
+}
+
+// SetScoreRetryLatency makes the latency of selection views (used for scoring) include retries.
+// By default it only covers first attempts, which is what a fresh request experiences, as long as
+// the key has any.
+func (t *Tracker) SetScoreRetryLatency(include bool) {
+	t.scoreRetryLatency.Store(include)
+}
+
+func (m *TrackedMetrics) selectionView(retries bool) SelectionView {
+	latency := m.ResponseQuantiles
+	if qt := m.GetAttemptQuantiles(1); !retries && qt != nil && qt.HasSamples() {
+		latency = qt
+	}
+
+	errors, requests := m.errorsAndRequests()
+	cordon := m.CordonInfo()
+	v := SelectionView{
+		Cordoned:          cordon != nil,
+		RequestsTotal:     requests,
+		ErrorsTotal:       errors,
+		BlockHeadLag:      max(m.BlockHeadLag.Load(), m.behindHeadEvidence.Load()),
+		FinalizationLag:   m.FinalizationLag.Load(),
+		HasLatency:        latency.HasSamples(),
+		P90Latency:        latency.GetQuantile(0.90),
+		RequestsPerSecond: m.RequestsPerSecond(),
+	}
+	if cordon != nil {
+		v.CordonedReason = cordon.Reason
+		v.CordonedSource = cordon.Origin()
+	}
+	if v.RequestsTotal > 0 {
+		v.ErrorRate = boundedRatio(v.ErrorsTotal, v.RequestsTotal)
+		throttled := m.SelfRateLimitedTotal.Load() + m.RemoteRateLimitedTotal.Load()
+		v.ThrottledRate = boundedRatio(throttled, v.RequestsTotal)
+		v.PartialResponseRate = boundedRatio(m.PartialResponsesTotal.Load(), v.RequestsTotal)
+	}
+	return m.withSeed(v)
+}
@@ -0,0 +1,94 @@
+package health
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health/internal/metricstest"
+	"github.com/erpc/erpc/telemetry"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectionView(t *testing.T) {
+	telemetry.SetHistogramBuckets("0.05,0.5,5,30")
+
+	t.Run("CapturesHealthAndCordon", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		for i := 0; i < 10; i++ {
+			tracker.RecordUpstreamRequest("a", "evm:1", "eth_call")
+			tracker.RecordUpstreamDuration("a", "evm:1", "eth_call", 100*time.Millisecond, "none")
+		}
+		tracker.RecordUpstreamFailure("a", "evm:1", "eth_call")
+		tracker.RecordUpstreamRemoteRateLimited("a", "evm:1", "eth_call")
+
+		v := tracker.SelectionView("a", "evm:1", "eth_call")
+		assert.False(t, v.Cordoned)
+		assert.Equal(t, int64(10), v.RequestsTotal)
+		assert.InDelta(t, 0.1, v.ErrorRate, 1e-9)
+		assert.InDelta(t, 0.1, v.ThrottledRate, 1e-9)
+		assert.True(t, v.HasLatency)
+		assert.InEpsilon(t, 0.1, v.P90Latency.Seconds(), 0.02)
+
+		tracker.Cordon("a", "evm:1", "*", "maintenance")
+		v = tracker.SelectionView("a", "evm:1", "eth_call")
+		assert.True(t, v.Cordoned)
+		assert.Equal(t, "maintenance", v.CordonedReason)
+	})
+
+	t.Run("ConsistentWithConcurrentResets", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		m := tracker.GetUpstreamMethodMetrics("a", "evm:1", "eth_call")
+
+		// One failure for every 4 requests, the window is reset between batches so any view
+		// mixing both sides of a reset breaks the ratio.
+		done := make(chan struct{})
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := 0; ; batch++ {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				for i := 0; i < 4; i++ {
+					tracker.RecordUpstreamRequest("a", "evm:1", "eth_call")
+				}
+				tracker.RecordUpstreamFailure("a", "evm:1", "eth_call")
+				if batch%2 == 1 {
+					m.Reset()
+				}
+			}
+		}()
+
+		for i := 0; i < 50000; i++ {
+			v := tracker.SelectionView("a", "evm:1", "eth_call")
+			if !assert.LessOrEqual(t, 4*v.ErrorsTotal, v.RequestsTotal, "view %+v", v) {
+				break
+			}
+			assert.LessOrEqual(t, v.ErrorRate, 0.25)
+		}
+		close(done)
+		wg.Wait()
+	})
+	t.Run("FallsBackToLastReadWhenResetsKeepRacing", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+		for i := 0; i < 4; i++ {
+			tracker.RecordUpstreamRequest("a", "evm:2", "eth_call")
+		}
+		m := tracker.GetUpstreamMethodMetrics("a", "evm:2", "eth_call")
+		labels := map[string]string{"project": "test-project", "network": "evm:2"}
+		before := metricstest.Value(t, "erpc_selection_view_inconsistent_total", labels)
+
+		// Leave the generation odd as if a reset never completed
+		m.resetGen.Add(1)
+		defer m.resetGen.Add(1)
+
+		v := tracker.SelectionView("a", "evm:2", "eth_call")
+		assert.Equal(t, int64(4), v.RequestsTotal)
+		assert.Equal(t, before+1, metricstest.Value(t, "erpc_selection_view_inconsistent_total", labels))
+	})
+}
@@ -0,0 +1,59 @@
+package health
+
+import (
+	"time"
+)
+
+type weightOverride struct {
+	weight float64
+	decay  time.Duration
+	since  time.Time
+}
+
+// SetUpstreamWeightOverride pins the selection weight (score) of an upstream on a network.
+// The override fully applies right away and linearly fades back to the health-derived
+// weight over the decay duration. A non-positive decay removes any existing override.
+func (t *Tracker) SetUpstreamWeightOverride(ups, network string, weight float64, decay time.Duration) {
+	network = t.canonicalNetwork(network)
+	k := duoKey{ups: ups, network: network}
+	if decay <= 0 {
+		t.weightOverrides.Delete(k)
+		return
+	}
+
+	t.logger.Debug().Str("upstream", ups).
+		Str("network", network).
+		Float64("weight", weight).
+		Dur("decay", decay).
+		Msg("setting upstream weight override in tracker")
+
+	t.weightOverrides.Store(k, &weightOverride{
+		weight: weight,
+		decay:  decay,
+		since:  t.clock.Now(),
+	})
+}
+
+// EffectiveWeight blends an active weight override with the health-derived weight of an upstream.
+// Without an override (or once it has fully decayed) healthWeight is returned as is, apart from
+// the latency anomaly, burned error budget and low rate limit headroom penalties if configured.
+func (t *Tracker) EffectiveWeight(ups, network string, healthWeight float64) float64 {
+	network = t.canonicalNetwork(network)
+	healthWeight *= t.latencyPenalty(ups, network) * t.errorBudgetPenalty(ups, network) * t.headroomPenalty(ups, network)
+
+	k := duoKey{ups: ups, network: network}
+	val, ok := t.weightOverrides.Load(k)
+	if !ok {
+		return healthWeight
+	}
+	wo := val.(*weightOverride)
+
+	elapsed := t.clock.Now().Sub(wo.since)
+	if elapsed >= wo.decay {
+		t.weightOverrides.CompareAndDelete(k, wo)
+		return healthWeight
+	}
+
+	alpha := 1 - float64(elapsed)/float64(wo.decay)
+	return alpha*wo.weight + (1-alpha)*healthWeight
+}
@@ -0,0 +1,53 @@
+package health_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/health"
+	"github.com/erpc/erpc/health/healthtest"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpstreamWeightOverride(t *testing.T) {
+	newTracker := func() (*health.Tracker, *healthtest.FakeClock) {
+		clock := healthtest.NewFakeClock(time.Unix(1700000000, 0))
+		tracker := health.NewTracker(&log.Logger, "test-project", time.Minute)
+		tracker.SetClock(clock)
+		return tracker, clock
+	}
+
+	t.Run("NoOverrideReturnsHealthWeight", func(t *testing.T) {
+		tracker, _ := newTracker()
+		assert.Equal(t, 3.5, tracker.EffectiveWeight("a", "evm:1", 3.5))
+	})
+
+	t.Run("OverrideDominatesThenConverges", func(t *testing.T) {
+		tracker, clock := newTracker()
+		tracker.SetUpstreamWeightOverride("a", "evm:1", 10, 10*time.Minute)
+
+		assert.Equal(t, 10.0, tracker.EffectiveWeight("a", "evm:1", 2))
+		assert.Equal(t, 2.0, tracker.EffectiveWeight("b", "evm:1", 2))
+		assert.Equal(t, 2.0, tracker.EffectiveWeight("a", "evm:2", 2))
+
+		clock.Advance(5 * time.Minute)
+		assert.InDelta(t, 6.0, tracker.EffectiveWeight("a", "evm:1", 2), 1e-9)
+
+		clock.Advance(4 * time.Minute)
+		assert.InDelta(t, 2.8, tracker.EffectiveWeight("a", "evm:1", 2), 1e-9)
+
+		clock.Advance(time.Minute)
+		assert.Equal(t, 2.0, tracker.EffectiveWeight("a", "evm:1", 2))
+		clock.Advance(time.Minute)
+		assert.Equal(t, 2.0, tracker.EffectiveWeight("a", "evm:1", 2))
+	})
+
+	t.Run("NonPositiveDecayClearsOverride", func(t *testing.T) {
+		tracker, _ := newTracker()
+		tracker.SetUpstreamWeightOverride("a", "evm:1", 10, time.Minute)
+		tracker.SetUpstreamWeightOverride("a", "evm:1", 0, 0)
+
+		assert.Equal(t, 1.0, tracker.EffectiveWeight("a", "evm:1", 1))
+	})
+}
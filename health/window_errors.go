@@ -0,0 +1,76 @@
+package health
+
+import (
+	"sync/atomic"
+)
+
+// windowErrorBounds are the inclusive upper bounds of the buckets of WindowErrorHistogram.
+var windowErrorBounds = [...]int64{0, 1, 2, 5, 10, 20, 50, 100, 200, 500, 1000, 2000, 5000, 10000}
+
+// WindowErrorHistogram is the distribution of the number of errors per window of a key, telling
+// whether its errors are spread evenly or come in bursts.
+type WindowErrorHistogram struct {
+	// Bounds are the inclusive upper bounds of the buckets
+	Bounds []int64 `json:"bounds"`
+	// Counts are the windows per bucket, the extra last one counting windows above every bound
+	Counts []int64 `json:"counts"`
+	// Windows is the number of windows observed, idle windows being left out
+	Windows int64 `json:"windows"`
+	// ErrorsSum is the total of the errors of the windows observed
+	ErrorsSum int64 `json:"errorsSum"`
+}
+
+type windowErrorHistogram struct {
+	counts    [len(windowErrorBounds) + 1]atomic.Int64
+	windows   atomic.Int64
+	errorsSum atomic.Int64
+}
+
+// observeWindowErrors records the errors of the closing window when it served requests, it must
+// run right before metrics are reset.
+func (m *TrackedMetrics) observeWindowErrors() {
+	if m.RequestsTotal.Load() == 0 {
+		return
+	}
+	errors := m.ErrorsTotal.Load()
+	i := 0
+	for i < len(windowErrorBounds) && errors > windowErrorBounds[i] {
+		i++
+	}
+	h := &m.windowErrors
+	h.counts[i].Add(1)
+	h.windows.Add(1)
+	h.errorsSum.Add(errors)
+}
+
+// WindowErrors returns the distribution of the number of errors per window since the key was
+// created, over the windows which served requests.
+func (m *TrackedMetrics) WindowErrors() WindowErrorHistogram {
+	h := &m.windowErrors
+	res := newWindowErrorHistogram()
+	res.Windows = h.windows.Load()
+	res.ErrorsSum = h.errorsSum.Load()
+	for i := range h.counts {
+		res.Counts[i] = h.counts[i].Load()
+	}
+	return res
+}
+
+// GetWindowErrorHistogram returns the distribution of the number of errors per window of (ups,
+// network, method), see TrackedMetrics.WindowErrors. Like GetNetworkUpstreamsMetrics it never
+// creates keys.
+func (t *Tracker) GetWindowErrorHistogram(ups, network, method string) WindowErrorHistogram {
+	network = t.canonicalNetwork(network)
+	method = t.normalizeMethod(method)
+	if val, ok := t.metrics.Load(tripletKey{ups, network, method}); ok {
+		return val.(*TrackedMetrics).WindowErrors()
+	}
+	return newWindowErrorHistogram()
+}
+
+func newWindowErrorHistogram() WindowErrorHistogram {
+	return WindowErrorHistogram{
+		Bounds: append([]int64(nil), windowErrorBounds[:]...),
+		Counts: make([]int64, len(windowErrorBounds)+1),
+	}
+}
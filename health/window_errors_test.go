@@ -0,0 +1,47 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWindowErrorHistogram(t *testing.T) {
+	networkID := "evm:123"
+	tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+	start := time.Now()
+
+	empty := tracker.GetWindowErrorHistogram("a", networkID, "eth_call")
+	assert.Equal(t, int64(0), empty.Windows)
+	assert.Len(t, empty.Counts, len(empty.Bounds)+1)
+
+	// Mostly quiet windows with a burst, plus an idle window which is left out
+	for i, errors := range []int{0, 1, 1, 3, 0, 40, -1, 20000} {
+		if errors >= 0 {
+			simulateRequestMetrics(tracker, networkID, "a", "eth_call", max(errors, 10), errors)
+		}
+		tracker.rollWindow(start.Add(time.Duration(i+1) * time.Minute))
+	}
+
+	h := tracker.GetWindowErrorHistogram("a", networkID, "eth_call")
+	assert.Equal(t, int64(7), h.Windows)
+	assert.Equal(t, int64(20045), h.ErrorsSum)
+	counts := map[int64]int64{}
+	for i, c := range h.Counts[:len(h.Bounds)] {
+		if c > 0 {
+			counts[h.Bounds[i]] = c
+		}
+	}
+	assert.Equal(t, map[int64]int64{0: 2, 1: 2, 5: 1, 50: 1}, counts)
+	assert.Equal(t, int64(1), h.Counts[len(h.Bounds)])
+
+	// Aggregates observe their own windows, other methods none
+	assert.Equal(t, int64(7), tracker.GetWindowErrorHistogram("*", networkID, "*").Windows)
+	assert.Equal(t, int64(0), tracker.GetWindowErrorHistogram("a", networkID, "eth_getLogs").Windows)
+
+	// The histogram outlives the windows and is not shared with callers
+	h.Bounds[0] = 42
+	assert.Equal(t, int64(0), tracker.GetWindowErrorHistogram("a", networkID, "eth_call").Bounds[0])
+}
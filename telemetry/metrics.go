@@ -32,13 +32,253 @@ var (
 		Namespace: "erpc",
 		Name:      "upstream_request_self_rate_limited_total",
 		Help:      "Total number of self-imposed rate limited requests before sending to upstreams.",
-	}, []string{"project", "network", "upstream", "category"})
+	}, []string{"project", "network", "upstream", "vendor", "category"})
 
 	MetricUpstreamRemoteRateLimitedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "erpc",
 		Name:      "upstream_request_remote_rate_limited_total",
 		Help:      "Total number of remote rate limited requests by upstreams.",
-	}, []string{"project", "network", "upstream", "category"})
+	}, []string{"project", "network", "upstream", "vendor", "category"})
+
+	MetricUpstreamPolicyDeniedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "upstream_request_policy_denied_total",
+		Help:      "Total number of attempts towards a method denied on an upstream by method policy.",
+	}, []string{"project", "network", "upstream", "vendor", "category"})
+
+	MetricUpstreamConcurrencyDeniedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "upstream_concurrency_denied_total",
+		Help:      "Total number of requests not admitted to an upstream because of its concurrency limit.",
+	}, []string{"project", "network", "upstream", "vendor", "category"})
+
+	MetricUpstreamInFlightRequests = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "erpc",
+		Name:      "upstream_in_flight_requests",
+		Help:      "Number of requests sent to an upstream and not completed yet.",
+	}, []string{"project", "network", "upstream", "vendor"})
+
+	MetricUpstreamRateLimitHeadroom = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "erpc",
+		Name:      "upstream_rate_limit_headroom",
+		Help:      "Fraction of the provider rate limit left for an upstream, as last reported.",
+	}, []string{"project", "network", "upstream", "vendor"})
+
+	MetricUpstreamUptimeRatio = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "erpc",
+		Name:      "upstream_uptime_ratio",
+		Help:      "Fraction of the known intervals of the last 24h during which an upstream was available.",
+	}, []string{"project", "network", "upstream", "vendor"})
+
+	MetricUpstreamUptimeUnknownRatio = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "erpc",
+		Name:      "upstream_uptime_unknown_ratio",
+		Help:      "Fraction of the last 24h without an availability result for an upstream, e.g. before a restart.",
+	}, []string{"project", "network", "upstream", "vendor"})
+
+	MetricUpstreamCertificateExpiryDays = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "erpc",
+		Name:      "upstream_certificate_expiry_days",
+		Help:      "Days until the earliest expiry in the TLS certificate chain of an upstream, as last probed.",
+	}, []string{"project", "network", "upstream", "vendor"})
+
+	MetricUpstreamWouldCordonTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "upstream_would_cordon_total",
+		Help:      "Total number of automatic cordons skipped because the tracker is in dry-run mode.",
+	}, []string{"project", "network", "upstream", "vendor", "category"})
+
+	MetricUpstreamCordonVetoedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "upstream_cordon_vetoed_total",
+		Help:      "Total number of automatic cordons vetoed by the cordon guard.",
+	}, []string{"project", "network", "upstream", "vendor", "category"})
+
+	MetricUpstreamCordonSuppressedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "upstream_cordon_suppressed_total",
+		Help:      "Total number of automatic cordons not applied to keep the minimum of eligible upstreams of a network.",
+	}, []string{"project", "network", "upstream", "vendor", "category"})
+
+	MetricUpstreamRequestsPerSecond = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "erpc",
+		Name:      "upstream_requests_per_second",
+		Help:      "Smoothed (EWMA) rate of requests towards an upstream.",
+	}, []string{"project", "network", "upstream", "vendor", "category"})
+
+	MetricUpstreamErrorsPerSecond = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "erpc",
+		Name:      "upstream_errors_per_second",
+		Help:      "Smoothed (EWMA) rate of failed requests towards an upstream.",
+	}, []string{"project", "network", "upstream", "vendor", "category"})
+
+	MetricUpstreamMethodNormalizedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "upstream_method_normalized_total",
+		Help:      "Total number of method names normalized (aliased or case-folded) by the health tracker, by resulting method name.",
+	}, []string{"project", "normalized"})
+
+	MetricNetworkNonCanonicalTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "network_non_canonical_total",
+		Help:      "Total number of non-canonical network identifiers (aliases, hex chain ids) received by the health tracker.",
+	}, []string{"project", "network"})
+
+	MetricTrackerTelemetryQueueOverflowTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "tracker_telemetry_queue_overflow_total",
+		Help:      "Total number of health tracker recordings exported synchronously because the telemetry queue was full.",
+	}, []string{"project"})
+
+	MetricSelectionViewInconsistentTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "selection_view_inconsistent_total",
+		Help:      "Total number of selection views that kept racing with window resets and were served from a possibly torn read.",
+	}, []string{"project", "network"})
+
+	MetricUpstreamBlockNumberRejectedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "upstream_block_number_rejected_total",
+		Help:      "Total number of latest/finalized block numbers rejected by the health tracker for exceeding the sanity ceiling.",
+	}, []string{"project", "network", "upstream", "vendor", "kind"})
+
+	MetricUpstreamReconnectTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "upstream_reconnect_total",
+		Help:      "Total number of reconnections of an upstream persistent connection (e.g. websocket).",
+	}, []string{"project", "network", "upstream", "vendor"})
+
+	MetricUpstreamHandshakeTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "upstream_handshake_total",
+		Help:      "Total number of handshakes of an upstream persistent connection (e.g. websocket subscription or auth) by outcome.",
+	}, []string{"project", "network", "upstream", "vendor", "outcome"})
+
+	MetricNetworkEligibleUpstreams = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "erpc",
+		Name:      "network_eligible_upstreams",
+		Help:      "Number of upstreams of a network neither cordoned nor over the error rate and block head lag thresholds of selection.",
+	}, []string{"project", "network"})
+
+	MetricNetworkContestedHeights = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "erpc",
+		Name:      "network_contested_heights",
+		Help:      "Number of recent block numbers of a network reported with conflicting hashes across upstreams.",
+	}, []string{"project", "network"})
+
+	MetricNetworkForkEventsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "network_fork_events_total",
+		Help:      "Total number of block numbers of a network which became reported with conflicting hashes across upstreams.",
+	}, []string{"project", "network"})
+
+	MetricNetworkSLOBurnRate = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "erpc",
+		Name:      "network_slo_burn_rate",
+		Help:      "Error budget burn rate of a network SLO over a window (1 means consuming exactly the allowed budget).",
+	}, []string{"project", "network", "sli", "window"})
+
+	MetricNetworkTrafficShareDivergence = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "erpc",
+		Name:      "network_traffic_share_divergence",
+		Help:      "Total variation distance between the realized and intended traffic shares of the upstreams of a network during the last completed window (0 when matching, 1 when disjoint).",
+	}, []string{"project", "network"})
+
+	MetricNetworkSLOErrorBudgetConsumed = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "erpc",
+		Name:      "network_slo_error_budget_consumed",
+		Help:      "Ratio of the error budget of a network SLO consumed during the last completed window.",
+	}, []string{"project", "network", "sli"})
+
+	MetricUpstreamOutcomeTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "upstream_outcome_total",
+		Help:      "Total number of requests towards upstreams by outcome, normalized JSON-RPC error code and HTTP status class.",
+	}, []string{"project", "network", "upstream", "vendor", "category", "outcome", "code", "http_status"})
+
+	MetricUpstreamResponseBytesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "upstream_response_bytes_total",
+		Help:      "Total size of responses received from upstreams.",
+	}, []string{"project", "network", "upstream", "vendor", "category"})
+
+	MetricUpstreamCancelledTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "upstream_cancelled_total",
+		Help:      "Total number of requests towards upstreams cancelled before completing, by cause (client, deadline or hedge).",
+	}, []string{"project", "network", "upstream", "vendor", "category", "cause"})
+
+	MetricUpstreamMalformedResponseTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "upstream_malformed_response_total",
+		Help:      "Total number of upstream responses unusable as JSON-RPC responses, by kind (invalid-json, not-jsonrpc, id-mismatch, wrong-content-type or other).",
+	}, []string{"project", "network", "upstream", "vendor", "category", "kind"})
+
+	MetricUpstreamBehindHeadErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "upstream_behind_head_errors_total",
+		Help:      "Total number of upstream errors telling the requested recent block is not synced yet.",
+	}, []string{"project", "network", "upstream", "vendor", "category"})
+
+	MetricUpstreamCounterOverflowTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "upstream_counter_overflow_total",
+		Help:      "Total number of health tracker counters kept from overflowing, by saturating or resetting early.",
+	}, []string{"project", "network", "upstream", "vendor", "category", "counter", "mode"})
+
+	MetricUpstreamClientInfo = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "erpc",
+		Name:      "upstream_client_info",
+		Help:      "Node client and version reported by the upstream through web3_clientVersion, always 1.",
+	}, []string{"project", "network", "upstream", "vendor", "client", "version"})
+
+	MetricUpstreamBroadcastCheckTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "upstream_broadcast_check_total",
+		Help:      "Total number of checks that transactions accepted by an upstream became visible through other upstreams, by result.",
+	}, []string{"project", "network", "upstream", "vendor", "result"})
+
+	MetricUpstreamConsensusComparisonTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "upstream_consensus_comparison_total",
+		Help:      "Total number of responses compared across upstreams by whether the upstream was in the majority or the minority.",
+	}, []string{"project", "network", "upstream", "vendor", "category", "result"})
+
+	MetricUpstreamMismatchTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "upstream_mismatch_total",
+		Help:      "Total number of responses found to be the outlier when cross-checked against other upstreams.",
+	}, []string{"project", "network", "upstream", "vendor", "category"})
+
+	MetricUpstreamPartialResponseTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "upstream_partial_response_total",
+		Help:      "Total number of incomplete responses of an upstream, e.g. truncated eth_getLogs results.",
+	}, []string{"project", "network", "upstream", "vendor", "category"})
+
+	MetricUpstreamShortResultTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "upstream_short_result_total",
+		Help:      "Total number of list results (e.g. eth_getLogs) with fewer entries than the majority of the upstreams they were compared with.",
+	}, []string{"project", "network", "upstream", "vendor", "category"})
+
+	MetricUpstreamShortResultMissingTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "upstream_short_result_missing_entries_total",
+		Help:      "Total number of list entries missing from short results compared to the majority of the upstreams.",
+	}, []string{"project", "network", "upstream", "vendor", "category"})
+
+	MetricUpstreamHedgeOutcomeTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "upstream_hedge_outcome_total",
+		Help:      "Total number of hedged requests towards upstreams by outcome (won or cancelled).",
+	}, []string{"project", "network", "upstream", "vendor", "category", "outcome"})
+
+	MetricUpstreamHedgeWastedSecondsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "upstream_hedge_wasted_seconds_total",
+		Help:      "Total upstream time spent on hedged requests that lost the race and were cancelled.",
+	}, []string{"project", "network", "upstream", "vendor", "category"})
 
 	MetricUpstreamSkippedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "erpc",
@@ -62,13 +302,13 @@ var (
 		Namespace: "erpc",
 		Name:      "upstream_block_head_lag",
 		Help:      "Total number of blocks (head) behind the most up-to-date upstream.",
-	}, []string{"project", "network", "upstream"})
+	}, []string{"project", "network", "upstream", "vendor"})
 
 	MetricUpstreamFinalizationLag = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: "erpc",
 		Name:      "upstream_finalization_lag",
 		Help:      "Total number of finalized blocks behind the most up-to-date upstream.",
-	}, []string{"project", "network", "upstream"})
+	}, []string{"project", "network", "upstream", "vendor"})
 
 	MetricUpstreamScoreOverall = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: "erpc",
@@ -80,19 +320,49 @@ var (
 		Namespace: "erpc",
 		Name:      "upstream_latest_block_number",
 		Help:      "Latest block number of upstreams.",
-	}, []string{"project", "network", "upstream"})
+	}, []string{"project", "network", "upstream", "vendor"})
 
 	MetricUpstreamFinalizedBlockNumber = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: "erpc",
 		Name:      "upstream_finalized_block_number",
 		Help:      "Finalized block number of upstreams.",
-	}, []string{"project", "network", "upstream"})
+	}, []string{"project", "network", "upstream", "vendor"})
 
 	MetricUpstreamCordoned = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: "erpc",
 		Name:      "upstream_cordoned",
 		Help:      "Whether upstream is un/cordoned (excluded from routing by selection policy).",
-	}, []string{"project", "network", "upstream", "category"})
+	}, []string{"project", "network", "upstream", "vendor", "category"})
+
+	MetricUpstreamCordonedSecondsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "upstream_cordoned_seconds_total",
+		Help:      "Total time an upstream spent cordoned on a network as a whole, open cordons being added at every window reset.",
+	}, []string{"project", "network", "upstream", "vendor"})
+
+	MetricUpstreamRealizedTrafficShare = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "erpc",
+		Name:      "upstream_realized_traffic_share",
+		Help:      "Share of the requests of a network served by an upstream during the last completed window.",
+	}, []string{"project", "network", "upstream", "vendor"})
+
+	MetricUpstreamIntendedTrafficShare = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "erpc",
+		Name:      "upstream_intended_traffic_share",
+		Help:      "Share of the requests of a network intended for an upstream by selection, as reported during the last completed window.",
+	}, []string{"project", "network", "upstream", "vendor"})
+
+	MetricUpstreamErrorBudgetRemaining = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "erpc",
+		Name:      "upstream_error_budget_remaining",
+		Help:      "Errors an upstream can still return on a network in the current window before exhausting its error budget, as of its last error.",
+	}, []string{"project", "network", "upstream", "vendor"})
+
+	MetricUpstreamCordonTransitionTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "upstream_cordon_transition_total",
+		Help:      "Total number of cordons and uncordons of an upstream by origin (manual, auto or external), including the ones refused to keep a manual cordon.",
+	}, []string{"project", "network", "upstream", "vendor", "category", "origin", "action"})
 
 	MetricUpstreamStaleLatestBlock = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "erpc",
@@ -158,7 +428,7 @@ var (
 		Namespace: "erpc",
 		Name:      "upstream_block_head_large_rollback",
 		Help:      "Number of times block head rolled back by a large number vs previous latest block returned by the same upstream.",
-	}, []string{"project", "network", "upstream"})
+	}, []string{"project", "network", "upstream", "vendor"})
 
 	MetricNetworkRequestSelfRateLimited = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "erpc",
@@ -190,6 +460,12 @@ var (
 		Help:      "Total number of hedged requests discarded towards a network (i.e. attempt > 1 means wasted requests).",
 	}, []string{"project", "network", "upstream", "category", "attempt", "hedge"})
 
+	MetricNetworkFallbackTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "network_fallback_total",
+		Help:      "Total number of requests that needed more than one upstream attempt, by position of the attempt that served it.",
+	}, []string{"project", "network", "category", "position"})
+
 	MetricNetworkFailedRequests = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "erpc",
 		Name:      "network_failed_request_total",
@@ -290,7 +566,9 @@ var DefaultHistogramBuckets = []float64{
 
 var (
 	MetricUpstreamRequestDuration,
+	MetricUpstreamAttemptDuration,
 	MetricNetworkRequestDuration,
+	MetricNetworkFallbackAddedDuration,
 	MetricCacheSetSuccessDuration,
 	MetricCacheSetErrorDuration,
 	MetricCacheGetSuccessHitDuration,
@@ -306,7 +584,9 @@ func SetHistogramBuckets(bucketsStr string) error {
 
 	if MetricUpstreamRequestDuration != nil {
 		prometheus.DefaultRegisterer.Unregister(MetricUpstreamRequestDuration)
+		prometheus.DefaultRegisterer.Unregister(MetricUpstreamAttemptDuration)
 		prometheus.DefaultRegisterer.Unregister(MetricNetworkRequestDuration)
+		prometheus.DefaultRegisterer.Unregister(MetricNetworkFallbackAddedDuration)
 		prometheus.DefaultRegisterer.Unregister(MetricCacheSetSuccessDuration)
 		prometheus.DefaultRegisterer.Unregister(MetricCacheSetErrorDuration)
 		prometheus.DefaultRegisterer.Unregister(MetricCacheGetSuccessHitDuration)
@@ -320,6 +600,13 @@ func SetHistogramBuckets(bucketsStr string) error {
 		Buckets:   buckets,
 	}, []string{"project", "network", "upstream", "category", "composite"})
 
+	MetricUpstreamAttemptDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "erpc",
+		Name:      "upstream_attempt_duration_seconds",
+		Help:      "Duration of requests towards upstreams by finality of the requested data and attempt (1, 2, 3+).",
+		Buckets:   buckets,
+	}, []string{"project", "network", "upstream", "vendor", "finality", "attempt"})
+
 	MetricNetworkRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
 		Namespace: "erpc",
 		Name:      "network_request_duration_seconds",
@@ -327,6 +614,13 @@ func SetHistogramBuckets(bucketsStr string) error {
 		Buckets:   buckets,
 	}, []string{"project", "network", "category"})
 
+	MetricNetworkFallbackAddedDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "erpc",
+		Name:      "network_fallback_added_duration_seconds",
+		Help:      "Latency added by failed upstream attempts before a fallback upstream served the request.",
+		Buckets:   buckets,
+	}, []string{"project", "network", "category"})
+
 	MetricCacheSetSuccessDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
 		Namespace: "erpc",
 		Name:      "cache_set_success_duration_seconds",
@@ -384,3 +678,61 @@ func ParseHistogramBuckets(bucketsStr string) ([]float64, error) {
 	sort.Float64s(buckets)
 	return buckets, nil
 }
+
+// DeleteUpstreamVendorSeries removes the series of an upstream labeled with a vendor it no longer
+// has, so that a vendor change (e.g. on config reload) does not leave duplicated series behind.
+func DeleteUpstreamVendorSeries(project, network, upstream, vendor string) {
+	labels := prometheus.Labels{"project": project, "network": network, "upstream": upstream, "vendor": vendor}
+	for _, vec := range []interface {
+		DeletePartialMatch(prometheus.Labels) int
+	}{
+		MetricUpstreamSelfRateLimitedTotal,
+		MetricUpstreamRemoteRateLimitedTotal,
+		MetricUpstreamPolicyDeniedTotal,
+		MetricUpstreamConcurrencyDeniedTotal,
+		MetricUpstreamInFlightRequests,
+		MetricUpstreamRateLimitHeadroom,
+		MetricUpstreamUptimeRatio,
+		MetricUpstreamUptimeUnknownRatio,
+		MetricUpstreamCertificateExpiryDays,
+		MetricUpstreamWouldCordonTotal,
+		MetricUpstreamCordonVetoedTotal,
+		MetricUpstreamCordonSuppressedTotal,
+		MetricUpstreamRequestsPerSecond,
+		MetricUpstreamErrorsPerSecond,
+		MetricUpstreamReconnectTotal,
+		MetricUpstreamHandshakeTotal,
+		MetricUpstreamHedgeOutcomeTotal,
+		MetricUpstreamHedgeWastedSecondsTotal,
+		MetricUpstreamCancelledTotal,
+		MetricUpstreamMalformedResponseTotal,
+		MetricUpstreamBehindHeadErrorsTotal,
+		MetricUpstreamConsensusComparisonTotal,
+		MetricUpstreamMismatchTotal,
+		MetricUpstreamPartialResponseTotal,
+		MetricUpstreamShortResultTotal,
+		MetricUpstreamShortResultMissingTotal,
+		MetricUpstreamBroadcastCheckTotal,
+		MetricUpstreamClientInfo,
+		MetricUpstreamCounterOverflowTotal,
+		MetricUpstreamOutcomeTotal,
+		MetricUpstreamResponseBytesTotal,
+		MetricUpstreamBlockHeadLag,
+		MetricUpstreamFinalizationLag,
+		MetricUpstreamLatestBlockNumber,
+		MetricUpstreamFinalizedBlockNumber,
+		MetricUpstreamCordoned,
+		MetricUpstreamCordonTransitionTotal,
+		MetricUpstreamCordonedSecondsTotal,
+		MetricUpstreamRealizedTrafficShare,
+		MetricUpstreamIntendedTrafficShare,
+		MetricUpstreamErrorBudgetRemaining,
+		MetricUpstreamBlockHeadLargeRollback,
+		MetricUpstreamBlockNumberRejectedTotal,
+	} {
+		vec.DeletePartialMatch(labels)
+	}
+	if MetricUpstreamAttemptDuration != nil {
+		MetricUpstreamAttemptDuration.DeletePartialMatch(labels)
+	}
+}
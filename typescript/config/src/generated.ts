@@ -87,6 +87,7 @@ export const EvalAnyErrorRateBelow100 = "any:errorRateBelow100";
 export const EvalAllErrorRateBelow100 = "all:errorRateBelow100";
 export const EvalEvmAnyChainId = "any:evm:eth_chainId";
 export const EvalEvmAllChainId = "all:evm:eth_chainId";
+export const EvalAllEligibleUpstreams = "all:eligibleUpstreams";
 export type TracingProtocol = string;
 export const TracingProtocolHttp: TracingProtocol = "http";
 export const TracingProtocolGrpc: TracingProtocol = "grpc";
@@ -226,6 +227,123 @@ export interface ProjectConfig {
   rateLimitBudget?: string;
   scoreMetricsWindowSize: Duration;
   healthCheck?: DeprecatedProjectHealthCheckConfig;
+  projectAggregates?: boolean;
+  broadcastChecks?: BroadcastChecksConfig;
+  healthTracker?: HealthTrackerConfig;
+}
+/**
+ * BroadcastChecksConfig enables checking that the transactions accepted by an upstream become
+ * visible through the other upstreams of the network after "delay", to detect upstreams which
+ * silently drop them. At most "maxPending" transactions wait to be checked.
+ */
+export interface BroadcastChecksConfig {
+  delay?: Duration;
+  maxPending?: number /* int */;
+}
+/**
+ * HealthTrackerConfig tunes the health tracker of a project, see the setters of health.Tracker
+ * for the meaning of each field. Zero values keep the defaults of the tracker.
+ */
+export interface HealthTrackerConfig {
+  failureCooldown?: Duration;
+  writeHealthBar?: WriteHealthBarConfig;
+  minEligibleUpstreams?: number /* int */;
+  tieBreak?: string;
+  noDataBehavior?: string;
+  scoreRetryLatency?: boolean;
+  uptimeMaxErrorRate?: number /* float64 */;
+  rateTimeConstant?: Duration;
+  warmupRamp?: WarmupRampConfig;
+  latencyAnomaly?: LatencyAnomalyConfig;
+  clientTopK?: number /* int */;
+  latencyBuckets?: number /* int */;
+  recentErrorsSize?: number /* int */;
+  counterOverflowMode?: string;
+  cancellationsAsErrors?: string[];
+  methodsExcludedFromAggregates?: string[];
+  methodAliases?: { [key: string]: string};
+  networkAliases?: { [key: string]: string};
+  writeMethods?: string[];
+  traceSampling?: TraceSamplingConfig;
+  trafficShareDivergence?: TrafficShareDivergenceConfig;
+  latencySLOs?: (LatencySLOConfig | undefined)[];
+  slos?: (SLOConfig | undefined)[];
+  blockNumberCeiling?: number /* int64 */;
+  blockHeadLargeRollbackDecay?: Duration;
+  reorgTimeout?: Duration;
+  forkHorizon?: number /* int64 */;
+  historicalLagMargin?: number /* int64 */;
+  behindHeadEvidenceLag?: number /* int64 */;
+  behindHeadMatchers?: { [key: string]: string[]};
+  cordonDryRun?: boolean;
+  chainIdMismatchPenalty?: string;
+  handshakeCordonThreshold?: number /* int64 */;
+  reconnectCordonThreshold?: number /* int64 */;
+  malformedResponseCordonThreshold?: number /* int64 */;
+  shortResultCordon?: number /* int64 */;
+  disagreementCordon?: RateCordonConfig;
+  mismatchCordon?: RateCordonConfig;
+  cordonRecovery?: { [key: string]: CordonRecoveryConfig | undefined};
+}
+export interface WriteHealthBarConfig {
+  maxErrorRate?: number /* float64 */;
+  maxBlockHeadLag?: number /* int64 */;
+}
+export interface WarmupRampConfig {
+  duration?: Duration;
+  curve?: string;
+  initialShare?: number /* float64 */;
+  maxErrorRate?: number /* float64 */;
+  minSamples?: number /* int64 */;
+  abortOnDegradation?: boolean;
+}
+export interface LatencyAnomalyConfig {
+  alpha?: number /* float64 */;
+  minWindows?: number /* int */;
+  factor?: number /* float64 */;
+  consecutiveWindows?: number /* int */;
+  maxIdleWindows?: number /* int */;
+  rebaselineWindows?: number /* int */;
+  emitEvents?: boolean;
+  scorePenalty?: number /* float64 */;
+}
+export interface TraceSamplingConfig {
+  rate?: number /* float64 */;
+  ringSize?: number /* int */;
+}
+export interface TrafficShareDivergenceConfig {
+  threshold?: number /* float64 */;
+  windows?: number /* int */;
+}
+export interface LatencySLOConfig {
+  method: string;
+  percentile: number /* float64 */;
+  target: Duration;
+}
+export interface SLOConfig {
+  network: string;
+  availabilityTarget?: number /* float64 */;
+  latencyTarget?: Duration;
+  latencyQuantile?: number /* float64 */;
+  burnRateAlerts?: (BurnRateAlertConfig | undefined)[];
+}
+export interface BurnRateAlertConfig {
+  shortWindow: Duration;
+  longWindow: Duration;
+  threshold: number /* float64 */;
+}
+/**
+ * RateCordonConfig cordons an upstream once the rate of a condition exceeds "maxRate" over at
+ * least "minSamples" samples within a window.
+ */
+export interface RateCordonConfig {
+  maxRate: number /* float64 */;
+  minSamples?: number /* int64 */;
+}
+export interface CordonRecoveryConfig {
+  interval?: Duration;
+  evaluations?: number /* int */;
+  clearThreshold?: number /* float64 */;
 }
 export interface NetworkDefaults {
   rateLimitBudget?: string;
@@ -266,6 +384,10 @@ export interface UpstreamConfig {
   rateLimitBudget?: string;
   rateLimitAutoTune?: RateLimitAutoTuneConfig;
   routing?: RoutingConfig;
+  certificateCheck?: CertificateCheckConfig;
+  shadow?: ShadowConfig;
+  maxConcurrency?: number /* int */;
+  errorBudget?: ErrorBudgetConfig;
 }
 export interface RoutingConfig {
   scoreMultipliers: (ScoreMultiplierConfig | undefined)[];
@@ -280,6 +402,7 @@ export interface ScoreMultiplierConfig {
   throttledRate: number /* float64 */;
   blockHeadLag: number /* float64 */;
   finalizationLag: number /* float64 */;
+  partialResponseRate: number /* float64 */;
 }
 export type Alias = UpstreamConfig;
 export interface RateLimitAutoTuneConfig {
@@ -291,6 +414,33 @@ export interface RateLimitAutoTuneConfig {
   minBudget: number /* int */;
   maxBudget: number /* int */;
 }
+/**
+ * CertificateCheckConfig enables probing the TLS certificate chain of an https/wss upstream to
+ * report the days left until it expires.
+ */
+export interface CertificateCheckConfig {
+  interval?: Duration;
+  warningDays?: number /* float64 */;
+}
+/**
+ * ShadowConfig makes an upstream a shadow: it is never selected to serve requests, but receives a
+ * sample of the reads of its network, whose responses are discarded, so that it can be evaluated
+ * on real traffic before going into production.
+ */
+export interface ShadowConfig {
+  sampleRate?: number /* float64 */;
+}
+/**
+ * ErrorBudgetConfig allows an upstream to fail a "fraction" of its requests (at least "minErrors")
+ * within a window, after which it is deprioritized ("penalize") or cordoned ("cordon") on its
+ * network until the window ends.
+ */
+export interface ErrorBudgetConfig {
+  fraction: number /* float64 */;
+  minErrors?: number /* int64 */;
+  penalize?: boolean;
+  cordon?: boolean;
+}
 export interface JsonRpcUpstreamConfig {
   supportsBatch?: boolean;
   batchMaxSize?: number /* int */;
@@ -396,6 +546,24 @@ export interface NetworkConfig {
   selectionPolicy?: SelectionPolicyConfig;
   directiveDefaults?: DirectiveDefaultsConfig;
   alias?: string;
+  latencyCostClasses?: LatencyCostClassesConfig;
+  methodImportance?: MethodImportanceConfig;
+  errorRateCordons?: { [key: string]: ErrorRateCordonConfig | undefined};
+}
+export interface LatencyCostClassesConfig {
+  methods?: { [key: string]: string};
+  baselines?: Record<string, Duration>;
+  scoreNormalized?: boolean;
+}
+export interface MethodImportanceConfig {
+  weights?: { [key: string]: number /* float64 */};
+  minSamples?: number /* int64 */;
+}
+export interface ErrorRateCordonConfig {
+  maxErrorRate: number /* float64 */;
+  minSamples?: number /* int64 */;
+  cleanWindows?: number /* int */;
+  probationSuccesses?: number /* int */;
 }
 export interface DirectiveDefaultsConfig {
   retryEmpty?: boolean;
@@ -0,0 +1,122 @@
+package upstream
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/erpc/erpc/common"
+)
+
+// certificateProbeTimeout bounds the TLS handshake of a certificate probe.
+const certificateProbeTimeout = 10 * time.Second
+
+// checksCertificate tells whether the certificate of the upstream must be probed, i.e. a
+// certificate check is configured and the endpoint is served over TLS.
+func (u *Upstream) checksCertificate() bool {
+	cfg := u.Config()
+	if cfg.CertificateCheck == nil || cfg.CertificateCheck.Interval <= 0 {
+		return false
+	}
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return false
+	}
+	return endpoint.Scheme == "https" || endpoint.Scheme == "wss"
+}
+
+// refreshCertificateExpiry reports the expiry of the certificate chain of the upstream to the
+// metrics tracker right away and then every configured interval, until ctx is done.
+func (u *Upstream) refreshCertificateExpiry(ctx context.Context) {
+	ticker := time.NewTicker(u.Config().CertificateCheck.Interval.Duration())
+	defer ticker.Stop()
+
+	for {
+		// The check might have been removed by a config reload since
+		if cfg := u.Config(); cfg.CertificateCheck != nil {
+			notAfter, err := probeCertificate(ctx, cfg.Endpoint, hostHeader(cfg.JsonRpc))
+			if err != nil {
+				u.metricsTracker.RecordCertificateProbeFailure(cfg.Id, u.networkId, err)
+			} else {
+				u.metricsTracker.RecordCertificateExpiry(cfg.Id, u.networkId, notAfter, cfg.CertificateCheck.WarningDays)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// probeCertificate completes a TLS handshake with the host of an endpoint and returns the earliest
+// expiry among the certificates it presents. The chain is not verified so that expired or
+// otherwise invalid certificates are still reported. When the host is an IP address, hostHeader
+// (if any) is used as server name, since providers routing on SNI would otherwise present the
+// certificate of another host or none at all.
+func probeCertificate(ctx context.Context, endpoint, hostHeader string) (time.Time, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return time.Time{}, err
+	}
+	host := u.Hostname()
+	if host == "" {
+		return time.Time{}, fmt.Errorf("endpoint %q has no host", endpoint)
+	}
+	port := u.Port()
+	if port == "" {
+		port = "443"
+	}
+	serverName := host
+	if net.ParseIP(host) != nil && hostHeader != "" {
+		serverName = hostHeader
+		if h, _, err := net.SplitHostPort(hostHeader); err == nil {
+			serverName = h
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, certificateProbeTimeout)
+	defer cancel()
+	dialer := &tls.Dialer{
+		Config: &tls.Config{
+			ServerName: serverName,
+			// #nosec G402 - The chain is only inspected for its expiry, no data is exchanged
+			InsecureSkipVerify: true,
+		},
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer conn.Close()
+
+	certs := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return time.Time{}, fmt.Errorf("no certificate presented by %s", host)
+	}
+	notAfter := certs[0].NotAfter
+	for _, c := range certs[1:] {
+		if c.NotAfter.Before(notAfter) {
+			notAfter = c.NotAfter
+		}
+	}
+	return notAfter, nil
+}
+
+// hostHeader returns the Host header sent along with the requests of an upstream, if any.
+func hostHeader(cfg *common.JsonRpcUpstreamConfig) string {
+	if cfg == nil {
+		return ""
+	}
+	for k, v := range cfg.Headers {
+		if http.CanonicalHeaderKey(k) == "Host" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,43 @@
+package upstream
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/erpc/erpc/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProbeCertificate(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	var serverName string
+	srv.TLS = &tls.Config{
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			serverName = hello.ServerName
+			return nil, nil
+		},
+	}
+	srv.StartTLS()
+	defer srv.Close()
+
+	// httptest serves a self-signed certificate, which must be inspected anyway
+	notAfter, err := probeCertificate(context.Background(), srv.URL, "")
+	require.NoError(t, err)
+	assert.Equal(t, srv.Certificate().NotAfter, notAfter)
+	assert.Empty(t, serverName, "no server name is sent for an IP without host header")
+
+	// SNI-routed providers reached by IP need the host header as server name
+	_, err = probeCertificate(context.Background(), srv.URL, "rpc.example.com:443")
+	require.NoError(t, err)
+	assert.Equal(t, "rpc.example.com", serverName)
+
+	_, err = probeCertificate(context.Background(), "https://127.0.0.1:1", "")
+	assert.Error(t, err)
+
+	assert.Equal(t, "rpc.example.com", hostHeader(&common.JsonRpcUpstreamConfig{Headers: map[string]string{"host": "rpc.example.com"}}))
+	assert.Empty(t, hostHeader(nil))
+}
@@ -0,0 +1,123 @@
+package upstream
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/erpc/erpc/health"
+)
+
+// ScoreFactor is the part one health signal took in the score of an upstream.
+type ScoreFactor struct {
+	Name string `json:"name"`
+	// Raw is the value of the signal, e.g. seconds for the p90 latency
+	Raw float64 `json:"raw"`
+	// Normalized is Raw relative to the worst upstream scored along, from 0 (best) to 1 (worst)
+	Normalized float64 `json:"normalized"`
+	// Weight is the score multiplier of the signal, zero leaving it out
+	Weight float64 `json:"weight"`
+	// Contribution is what the signal added to the score before the overall multiplier
+	Contribution float64 `json:"contribution"`
+}
+
+// UpstreamScoreExplanation breaks down the score of an upstream, see ScoreExplanation.
+type UpstreamScoreExplanation struct {
+	UpstreamId string        `json:"upstreamId"`
+	Factors    []ScoreFactor `json:"factors"`
+	// Overall is the overall score multiplier applied to the sum of the contributions
+	Overall float64 `json:"overall"`
+	// HealthScore is the score derived from the factors alone
+	HealthScore float64 `json:"healthScore"`
+	// Score is the score used for sorting, HealthScore adjusted by the tracker, see
+	// health.Tracker.EffectiveWeight
+	Score float64 `json:"score"`
+	// HistoricalScore is Score with the block head lag of the upstream left out, which ranks it for
+	// requests reading blocks well below its head, see GetSortedUpstreamsForBlock
+	HistoricalScore float64 `json:"historicalScore"`
+	// Rank is the 1-based position in the sorted upstreams, zero when excluded
+	Rank int `json:"rank"`
+	// ExclusionReasons tell why the upstream is left out of the sorted upstreams or tried last
+	ExclusionReasons []string `json:"exclusionReasons,omitempty"`
+}
+
+// ScoreExplanation is how the upstreams of a (network, method) were scored and sorted by the last
+// score refresh. It is captured along the scores themselves, so it reflects exactly the snapshot
+// selection uses until the next refresh.
+type ScoreExplanation struct {
+	NetworkId    string                      `json:"networkId"`
+	Method       string                      `json:"method"`
+	CalculatedAt time.Time                   `json:"calculatedAt"`
+	Upstreams    []*UpstreamScoreExplanation `json:"upstreams"`
+}
+
+// ExplainScores returns the breakdown of the last score refresh of (networkId, method), false when
+// no refresh happened for it yet.
+func (u *UpstreamsRegistry) ExplainScores(networkId, method string) (*ScoreExplanation, bool) {
+	u.upstreamsMu.RLock()
+	defer u.upstreamsMu.RUnlock()
+	exp, ok := u.scoreExplanations[networkId][method]
+	return exp, ok
+}
+
+// storeScoreExplanation ranks the explained upstreams after the sorted ones and stores the
+// explanation, upstreamsMu must be held for writing.
+func (u *UpstreamsRegistry) storeScoreExplanation(networkId, method string, explained []*UpstreamScoreExplanation, sorted []*Upstream) {
+	rank := make(map[string]int, len(sorted))
+	for i, ups := range sorted {
+		rank[ups.Config().Id] = i + 1
+	}
+	for _, e := range explained {
+		e.Rank = rank[e.UpstreamId]
+		e.ExclusionReasons = append(e.ExclusionReasons, u.exclusionReasons(e.UpstreamId, networkId, method)...)
+	}
+	if _, ok := u.scoreExplanations[networkId]; !ok {
+		u.scoreExplanations[networkId] = make(map[string]*ScoreExplanation)
+	}
+	u.scoreExplanations[networkId][method] = &ScoreExplanation{
+		NetworkId:    networkId,
+		Method:       method,
+		CalculatedAt: time.Now(),
+		Upstreams:    explained,
+	}
+}
+
+// exclusionReasons lists why selection leaves an upstream out of (networkId, method) or tries it
+// last, whatever its score, in the order demote applies them. The warm-up and saturation
+// demotions are as of the refresh, they vary from one request to the next.
+func (u *UpstreamsRegistry) exclusionReasons(upsId, networkId, method string) []string {
+	var reasons []string
+	if u.metricsTracker.IsCordoned(upsId, networkId, method) {
+		reason := "cordoned"
+		for _, m := range []string{"*", method} {
+			if tm, ok := u.metricsTracker.LookupUpstreamMethodMetrics(upsId, networkId, m); ok {
+				if info := tm.CordonInfo(); info != nil {
+					reason = fmt.Sprintf("cordoned (%s): %s", info.Origin(), info.Reason)
+					break
+				}
+			}
+		}
+		reasons = append(reasons, reason)
+	}
+	if u.ShadowSampleRate(upsId) > 0 {
+		reasons = append(reasons, "shadow upstream")
+	}
+	if shareCap := u.metricsTracker.TrafficShareCap(upsId, networkId); shareCap < 1 {
+		reasons = append(reasons, fmt.Sprintf("warming up, tried first for %.0f%% of requests", shareCap*100))
+	}
+	if u.metricsTracker.IsSaturated(upsId, networkId) {
+		reasons = append(reasons, "at concurrency limit")
+	}
+	if u.metricsTracker.InCooldown(upsId, networkId, method, u.failureCooldown) {
+		reasons = append(reasons, fmt.Sprintf("failed less than %s ago", u.failureCooldown))
+	}
+	if (u.writeMaxErrorRate > 0 || u.writeMaxBlockHeadLag > 0) && u.metricsTracker.MethodClassOf(method) == health.MethodClassWrite {
+		view := u.metricsTracker.ClassSelectionView(upsId, networkId, health.MethodClassWrite)
+		if u.writeMaxErrorRate > 0 && view.ErrorRate > u.writeMaxErrorRate {
+			reasons = append(reasons, fmt.Sprintf("write error rate %.2f over %.2f", view.ErrorRate, u.writeMaxErrorRate))
+		}
+		if u.writeMaxBlockHeadLag > 0 && view.BlockHeadLag > u.writeMaxBlockHeadLag {
+			reasons = append(reasons, fmt.Sprintf("block head lag %d over %d", view.BlockHeadLag, u.writeMaxBlockHeadLag))
+		}
+	}
+	return reasons
+}
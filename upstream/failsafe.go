@@ -1,13 +1,18 @@
 package upstream
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"time"
 
 	"github.com/erpc/erpc/architecture/evm"
 	"github.com/erpc/erpc/common"
 	"github.com/erpc/erpc/consensus"
+	"github.com/erpc/erpc/health"
 	"github.com/failsafe-go/failsafe-go"
 	"github.com/failsafe-go/failsafe-go/circuitbreaker"
 	"github.com/failsafe-go/failsafe-go/hedgepolicy"
@@ -16,7 +21,7 @@ import (
 	"github.com/rs/zerolog"
 )
 
-func CreateFailSafePolicies(logger *zerolog.Logger, scope common.Scope, entity string, fsCfg *common.FailsafeConfig) (map[string]failsafe.Policy[*common.NormalizedResponse], error) {
+func CreateFailSafePolicies(logger *zerolog.Logger, scope common.Scope, entity string, fsCfg *common.FailsafeConfig, metricsTracker health.MetricsTracker) (map[string]failsafe.Policy[*common.NormalizedResponse], error) {
 	// The order of policies below are important as per docs of failsafe-go
 	var policies = map[string]failsafe.Policy[*common.NormalizedResponse]{}
 
@@ -80,7 +85,7 @@ func CreateFailSafePolicies(logger *zerolog.Logger, scope common.Scope, entity s
 				},
 			)
 		}
-		p, err := createConsensusPolicy(&lg, fsCfg.Consensus)
+		p, err := createConsensusPolicy(&lg, fsCfg.Consensus, metricsTracker)
 		if err != nil {
 			return nil, err
 		}
@@ -402,7 +407,7 @@ func createTimeoutPolicy(logger *zerolog.Logger, cfg *common.TimeoutPolicyConfig
 	return builder.Build(), nil
 }
 
-func createConsensusPolicy(logger *zerolog.Logger, cfg *common.ConsensusPolicyConfig) (failsafe.Policy[*common.NormalizedResponse], error) {
+func createConsensusPolicy(logger *zerolog.Logger, cfg *common.ConsensusPolicyConfig, metricsTracker health.MetricsTracker) (failsafe.Policy[*common.NormalizedResponse], error) {
 	if cfg == nil {
 		// No consensus config given, so no policy
 		return nil, nil
@@ -419,11 +424,60 @@ func createConsensusPolicy(logger *zerolog.Logger, cfg *common.ConsensusPolicyCo
 	builder.OnAgreement(func(event failsafe.ExecutionEvent[*common.NormalizedResponse]) {
 		logger.Debug().Msg("spawning additional consensus request")
 	})
+	builder.OnComparison(func(results []*common.NormalizedResponse) {
+		if c, ok := consensusComparison(results); ok {
+			metricsTracker.RecordConsensusComparison(c)
+		}
+	})
 
 	p := builder.Build()
 	return p, nil
 }
 
+// consensusComparison compares the responses of the participants of a consensus execution, false
+// when they are not about a single request.
+func consensusComparison(results []*common.NormalizedResponse) (health.ConsensusComparison, bool) {
+	var c health.ConsensusComparison
+	for _, resp := range results {
+		if resp == nil || resp.Upstream() == nil || resp.Request() == nil {
+			continue
+		}
+		jrr, err := resp.JsonRpcResponse()
+		if err != nil || jrr == nil || jrr.Error != nil {
+			continue
+		}
+		if c.Method == "" {
+			c.Network = resp.Request().NetworkId()
+			c.Method, _ = resp.Request().Method()
+		}
+		p := health.ConsensusParticipant{Upstream: resp.Upstream().Config().Id}
+		var entries []any
+		p.ResultHash, entries, p.IsList = canonicalResultHash(jrr.Result)
+		p.ListLength = len(entries)
+		c.Participants = append(c.Participants, p)
+	}
+	return c, c.Method != "" && len(c.Participants) > 1
+}
+
+// canonicalResultHash hashes a JSON result regardless of its key order and whitespace, so that
+// upstreams returning the same data are not told apart. Results which are not valid JSON are
+// hashed as is. It also returns the entries of the result when it is a list.
+func canonicalResultHash(result []byte) (string, []any, bool) {
+	dec := json.NewDecoder(bytes.NewReader(result))
+	dec.UseNumber()
+	var v any
+	canonical := result
+	if err := dec.Decode(&v); err == nil {
+		// encoding/json sorts the keys of maps
+		if b, err := json.Marshal(v); err == nil {
+			canonical = b
+		}
+	}
+	sum := sha256.Sum256(canonical)
+	entries, isList := v.([]any)
+	return hex.EncodeToString(sum[:]), entries, isList
+}
+
 func TranslateFailsafeError(scope common.Scope, upstreamId string, method string, execErr error, startTime *time.Time) error {
 	var err error
 	var retryExceededErr retrypolicy.ExceededError
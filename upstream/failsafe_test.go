@@ -0,0 +1,52 @@
+package upstream
+
+import (
+	"testing"
+
+	"github.com/erpc/erpc/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsensusComparison(t *testing.T) {
+	req := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","id":1,"method":"eth_getLogs","params":[]}`))
+	response := func(upsId, result string) *common.NormalizedResponse {
+		jrr, err := common.NewJsonRpcResponseFromBytes([]byte("1"), []byte(result), nil)
+		require.NoError(t, err)
+		r := common.NewNormalizedResponse().WithRequest(req).WithJsonRpcResponse(jrr)
+		r.SetUpstream(&Upstream{config: &common.UpstreamConfig{Id: upsId}})
+		return r
+	}
+
+	t.Run("ComparesParticipants", func(t *testing.T) {
+		c, ok := consensusComparison([]*common.NormalizedResponse{
+			response("a", `[1,2]`),
+			response("b", `[1,2]`),
+			response("c", `[1]`),
+		})
+		require.True(t, ok)
+		assert.Equal(t, "eth_getLogs", c.Method)
+		require.Len(t, c.Participants, 3)
+		lengths := map[string]int{}
+		hashes := map[string]string{}
+		for _, p := range c.Participants {
+			assert.True(t, p.IsList)
+			lengths[p.Upstream] = p.ListLength
+			hashes[p.Upstream] = p.ResultHash
+		}
+		assert.Equal(t, map[string]int{"a": 2, "b": 2, "c": 1}, lengths)
+		assert.Equal(t, hashes["a"], hashes["b"])
+		assert.NotEqual(t, hashes["a"], hashes["c"])
+	})
+
+	t.Run("EquivalentResultsAgree", func(t *testing.T) {
+		c, ok := consensusComparison([]*common.NormalizedResponse{
+			response("a", `{"number":"0x1","hash":"0xab","logs":[{"index":1,"data":"0x"}]}`),
+			response("b", "{ \"hash\": \"0xab\",\n \"logs\": [ { \"data\": \"0x\", \"index\": 1 } ], \"number\": \"0x1\" }"),
+		})
+		require.True(t, ok)
+		require.Len(t, c.Participants, 2)
+		assert.False(t, c.Participants[0].IsList)
+		assert.Equal(t, c.Participants[0].ResultHash, c.Participants[1].ResultHash)
+	})
+}
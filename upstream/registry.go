@@ -23,8 +23,13 @@ type UpstreamsRegistry struct {
 	appCtx               context.Context
 	prjId                string
 	scoreRefreshInterval time.Duration
+	failureCooldown      time.Duration
+	writeMaxErrorRate    float64
+	writeMaxBlockHeadLag int64
+	shadowSampleRates    map[string]float64 // keyed by upstream id, see SetShadowUpstream
+	shadowMu             sync.RWMutex
 	logger               *zerolog.Logger
-	metricsTracker       *health.Tracker
+	metricsTracker       health.MetricsTracker
 	sharedStateRegistry  data.SharedStateRegistry
 	clientRegistry       *clients.ClientRegistry
 	vendorsRegistry      *thirdparty.VendorsRegistry
@@ -42,6 +47,8 @@ type UpstreamsRegistry struct {
 	sortedUpstreams map[string]map[string][]*Upstream
 	// map of upstream -> network (or *) -> method (or *) => score
 	upstreamScores map[string]map[string]map[string]float64
+	// map of network -> method => breakdown of the last scores, see ExplainScores
+	scoreExplanations map[string]map[string]*ScoreExplanation
 
 	onUpstreamRegistered func(ups *Upstream) error
 }
@@ -50,6 +57,12 @@ type UpstreamsHealth struct {
 	Upstreams       []*Upstream                              `json:"upstreams"`
 	SortedUpstreams map[string]map[string][]string           `json:"sortedUpstreams"`
 	UpstreamScores  map[string]map[string]map[string]float64 `json:"upstreamScores"`
+	// ShadowUpstreams are the sample rates of the shadow upstreams, which appear in
+	// SortedUpstreams and UpstreamScores with the rank and score they would have in production
+	ShadowUpstreams map[string]float64 `json:"shadowUpstreams,omitempty"`
+	// EligibleUpstreams are the eligible upstreams of each network, see
+	// health.Tracker.EligibleUpstreams
+	EligibleUpstreams map[string]int `json:"eligibleUpstreams"`
 }
 
 func NewUpstreamsRegistry(
@@ -62,7 +75,7 @@ func NewUpstreamsRegistry(
 	vr *thirdparty.VendorsRegistry,
 	pr *thirdparty.ProvidersRegistry,
 	ppr *clients.ProxyPoolRegistry,
-	mt *health.Tracker,
+	mt health.MetricsTracker,
 	scoreRefreshInterval time.Duration,
 ) *UpstreamsRegistry {
 	lg := logger.With().Str("component", "upstreamsRegistry").Logger()
@@ -81,6 +94,8 @@ func NewUpstreamsRegistry(
 		networkUpstreams:     make(map[string][]*Upstream),
 		sortedUpstreams:      make(map[string]map[string][]*Upstream),
 		upstreamScores:       make(map[string]map[string]map[string]float64),
+		scoreExplanations:    make(map[string]map[string]*ScoreExplanation),
+		shadowSampleRates:    make(map[string]float64),
 		upstreamsMu:          &sync.RWMutex{},
 		networkMu:            &sync.Map{},
 		initializer:          util.NewInitializer(appCtx, &lg, nil),
@@ -204,6 +219,14 @@ func (u *UpstreamsRegistry) GetNetworkUpstreams(ctx context.Context, networkId s
 }
 
 func (u *UpstreamsRegistry) GetSortedUpstreams(ctx context.Context, networkId, method string) ([]*Upstream, error) {
+	return u.GetSortedUpstreamsForBlock(ctx, networkId, method, health.RequestBlock{})
+}
+
+// GetSortedUpstreamsForBlock is GetSortedUpstreams for a request reading the given block: the
+// upstreams for which the data is historical (see health.Tracker.IsHistoricalFor) are ranked with
+// their score free of any block head lag penalty, so that archive traffic does not concentrate on
+// the upstreams synced to the head.
+func (u *UpstreamsRegistry) GetSortedUpstreamsForBlock(ctx context.Context, networkId, method string, block health.RequestBlock) ([]*Upstream, error) {
 	_, span := common.StartDetailSpan(ctx, "UpstreamsRegistry.GetSortedUpstreams")
 	defer span.End()
 
@@ -267,10 +290,299 @@ func (u *UpstreamsRegistry) GetSortedUpstreams(ctx context.Context, networkId, m
 		}
 		u.upstreamsMu.Unlock()
 
-		return methodUpsList, nil
+		return u.demote(networkId, method, methodUpsList), nil
+	}
+
+	return u.demote(networkId, method, u.rankForBlock(networkId, method, block, upsList)), nil
+}
+
+// rankForBlock re-sorts a copy of upsList when the block read by the request is historical for some
+// of the upstreams, each of them being ranked with its historical score instead, see
+// UpstreamScoreExplanation.HistoricalScore.
+func (u *UpstreamsRegistry) rankForBlock(networkId, method string, block health.RequestBlock, upsList []*Upstream) []*Upstream {
+	if block.IsZero() || block.Unknown || len(upsList) < 2 {
+		return upsList
+	}
+	u.upstreamsMu.RLock()
+	exp := u.scoreExplanations[networkId][method]
+	scores := make(map[string]float64, len(upsList))
+	for _, ups := range upsList {
+		scores[ups.Config().Id] = u.upstreamScores[ups.Config().Id][networkId][method]
+	}
+	u.upstreamsMu.RUnlock()
+	if exp == nil {
+		return upsList
+	}
+
+	historical := false
+	for _, ue := range exp.Upstreams {
+		if _, ok := scores[ue.UpstreamId]; ok && u.metricsTracker.IsHistoricalFor(ue.UpstreamId, networkId, block) {
+			scores[ue.UpstreamId] = ue.HistoricalScore
+			historical = true
+		}
+	}
+	if !historical {
+		return upsList
+	}
+
+	res := make([]*Upstream, len(upsList))
+	copy(res, upsList)
+	sort.SliceStable(res, func(i, j int) bool {
+		return max(scores[res[i].Config().Id], 0) > max(scores[res[j].Config().Id], 0)
+	})
+	return res
+}
+
+// demote breaks score ties, leaves out the shadow upstreams and applies every demotion on top of
+// the score order of upsList, the last one applied having the final say on what goes last.
+func (u *UpstreamsRegistry) demote(networkId, method string, upsList []*Upstream) []*Upstream {
+	upsList = u.breakTies(networkId, method, upsList)
+	upsList = u.withoutShadows(upsList)
+	upsList = u.demoteWarmingUp(networkId, upsList)
+	upsList = u.demoteSaturated(networkId, method, upsList)
+	upsList = u.demoteCoolingDown(networkId, method, upsList)
+	return u.demoteUnfitForWrites(networkId, method, upsList)
+}
+
+// demoteWarmingUp moves each upstream in a warmup ramp (see health.Tracker.SetWarmupRamp) to the
+// end of a copy of upsList with a probability of one minus its traffic share cap, so that it is
+// tried first for about that fraction of the requests it would otherwise get.
+func (u *UpstreamsRegistry) demoteWarmingUp(networkId string, upsList []*Upstream) []*Upstream {
+	var warming []*Upstream
+	ready := make([]*Upstream, 0, len(upsList))
+	for _, ups := range upsList {
+		shareCap := u.metricsTracker.TrafficShareCap(ups.Config().Id, networkId)
+		if shareCap < 1 && rand.Float64() >= shareCap {
+			warming = append(warming, ups)
+		} else {
+			ready = append(ready, ups)
+		}
+	}
+	if len(warming) == 0 {
+		return upsList
+	}
+	return append(ready, warming...)
+}
+
+// demoteSaturated moves the upstreams at their concurrency limit (see
+// health.Tracker.SetConcurrencyLimit) to the end of a copy of upsList, keeping them as a last
+// resort rather than excluding them.
+func (u *UpstreamsRegistry) demoteSaturated(networkId, method string, upsList []*Upstream) []*Upstream {
+	var saturated []*Upstream
+	ready := make([]*Upstream, 0, len(upsList))
+	for _, ups := range upsList {
+		if u.metricsTracker.ShouldAdmit(ups.Config().Id, networkId, method) {
+			ready = append(ready, ups)
+		} else {
+			saturated = append(saturated, ups)
+		}
+	}
+	if len(saturated) == 0 {
+		return upsList
+	}
+	return append(ready, saturated...)
+}
+
+// breakTies reorders in a copy of upsList each run of upstreams with the same positive score,
+// with the tie-break mode of the tracker (see health.Tracker.SetTieBreak). Upstreams without a
+// score are left in the random order they fall back to.
+func (u *UpstreamsRegistry) breakTies(networkId, method string, upsList []*Upstream) []*Upstream {
+	if len(upsList) < 2 {
+		return upsList
+	}
+	scores := make([]float64, len(upsList))
+	u.upstreamsMu.RLock()
+	for i, ups := range upsList {
+		scores[i] = u.upstreamScores[ups.Config().Id][networkId][method]
+	}
+	u.upstreamsMu.RUnlock()
+
+	var res []*Upstream
+	for start, end := 0, 1; start < len(upsList); start, end = end, end+1 {
+		for end < len(upsList) && scores[end] == scores[start] {
+			end++
+		}
+		if end-start < 2 || scores[start] <= 0 {
+			continue
+		}
+		if res == nil {
+			res = make([]*Upstream, len(upsList))
+			copy(res, upsList)
+		}
+		byId := make(map[string]*Upstream, end-start)
+		ids := make([]string, 0, end-start)
+		for _, ups := range upsList[start:end] {
+			byId[ups.Config().Id] = ups
+			ids = append(ids, ups.Config().Id)
+		}
+		u.metricsTracker.OrderTies(networkId, method, ids)
+		for i, id := range ids {
+			res[start+i] = byId[id]
+		}
+	}
+	if res == nil {
+		return upsList
+	}
+	return res
+}
+
+// SetShadowUpstream makes an upstream a shadow: selection never returns it, and the network
+// mirrors to it a sampleRate fraction of its requests, whose responses are discarded, so that it
+// can be evaluated on real traffic before going into production. It keeps being scored like the
+// other upstreams. Zero makes it a regular upstream again.
+func (u *UpstreamsRegistry) SetShadowUpstream(upsId string, sampleRate float64) {
+	u.shadowMu.Lock()
+	if sampleRate <= 0 {
+		delete(u.shadowSampleRates, upsId)
+	} else {
+		u.shadowSampleRates[upsId] = min(sampleRate, 1)
+	}
+	u.shadowMu.Unlock()
+	u.metricsTracker.SetUpstreamShadow(upsId, sampleRate > 0)
+}
+
+// ShadowSampleRate returns the fraction of requests to mirror to an upstream, zero unless it is a
+// shadow, see SetShadowUpstream.
+func (u *UpstreamsRegistry) ShadowSampleRate(upsId string) float64 {
+	u.shadowMu.RLock()
+	defer u.shadowMu.RUnlock()
+	return u.shadowSampleRates[upsId]
+}
+
+// withoutShadows removes the shadow upstreams from a copy of upsList.
+func (u *UpstreamsRegistry) withoutShadows(upsList []*Upstream) []*Upstream {
+	u.shadowMu.RLock()
+	defer u.shadowMu.RUnlock()
+	if len(u.shadowSampleRates) == 0 {
+		return upsList
+	}
+	res := make([]*Upstream, 0, len(upsList))
+	for _, ups := range upsList {
+		if _, ok := u.shadowSampleRates[ups.Config().Id]; !ok {
+			res = append(res, ups)
+		}
+	}
+	return res
+}
+
+// SetFailureCooldown makes selection avoid an upstream for d after it failed a request for a
+// method, even while its score is still fine, so that a retry does not immediately land on a
+// momentarily broken node. Zero disables it. It must be set before serving requests.
+func (u *UpstreamsRegistry) SetFailureCooldown(d time.Duration) {
+	u.failureCooldown = d
+}
+
+// demoteCoolingDown moves the upstreams in failure cooldown to the end of a copy of upsList,
+// keeping them as a last resort rather than excluding them.
+func (u *UpstreamsRegistry) demoteCoolingDown(networkId, method string, upsList []*Upstream) []*Upstream {
+	if u.failureCooldown <= 0 {
+		return upsList
+	}
+	var cooling []*Upstream
+	ready := make([]*Upstream, 0, len(upsList))
+	for _, ups := range upsList {
+		if u.metricsTracker.InCooldown(ups.Config().Id, networkId, method, u.failureCooldown) {
+			cooling = append(cooling, ups)
+		} else {
+			ready = append(ready, ups)
+		}
+	}
+	if len(cooling) == 0 {
+		return upsList
 	}
+	return append(ready, cooling...)
+}
+
+// SetWriteHealthBar holds upstreams to a stricter bar for write methods (see
+// health.MethodClassWrite) than their score: upstreams whose writes error rate exceeds
+// maxErrorRate, or which lag more than maxBlockHeadLag blocks, are tried last for writes. Zero
+// disables either check. It must be set before serving requests.
+func (u *UpstreamsRegistry) SetWriteHealthBar(maxErrorRate float64, maxBlockHeadLag int64) {
+	u.writeMaxErrorRate = maxErrorRate
+	u.writeMaxBlockHeadLag = maxBlockHeadLag
+}
 
-	return upsList, nil
+// demoteUnfitForWrites moves the upstreams under the write health bar to the end of a copy of
+// upsList when method is a write, keeping them as a last resort rather than excluding them.
+func (u *UpstreamsRegistry) demoteUnfitForWrites(networkId, method string, upsList []*Upstream) []*Upstream {
+	if u.writeMaxErrorRate <= 0 && u.writeMaxBlockHeadLag <= 0 {
+		return upsList
+	}
+	if u.metricsTracker.MethodClassOf(method) != health.MethodClassWrite {
+		return upsList
+	}
+	var unfit []*Upstream
+	fit := make([]*Upstream, 0, len(upsList))
+	for _, ups := range upsList {
+		view := u.metricsTracker.ClassSelectionView(ups.Config().Id, networkId, health.MethodClassWrite)
+		if (u.writeMaxErrorRate > 0 && view.ErrorRate > u.writeMaxErrorRate) ||
+			(u.writeMaxBlockHeadLag > 0 && view.BlockHeadLag > u.writeMaxBlockHeadLag) {
+			unfit = append(unfit, ups)
+		} else {
+			fit = append(fit, ups)
+		}
+	}
+	if len(unfit) == 0 {
+		return upsList
+	}
+	return append(fit, unfit...)
+}
+
+// BroadcastChecker checks the visibility of transactions through the upstreams of the network
+// other than the one which accepted them, to be set with health.Tracker.EnableBroadcastChecks. They
+// are queried concurrently until ctx is done: a transaction seen by any of them is visible, it is
+// not when all the ones which answered missed it.
+func (u *UpstreamsRegistry) BroadcastChecker() health.BroadcastChecker {
+	type visibility struct {
+		visible bool
+		err     error
+	}
+	return func(ctx context.Context, network, txHash, acceptedBy string) (bool, error) {
+		var others []*Upstream
+		for _, ups := range u.GetNetworkUpstreams(ctx, network) {
+			if ups.Config().Id != acceptedBy {
+				others = append(others, ups)
+			}
+		}
+		if len(others) == 0 {
+			return false, fmt.Errorf("no other upstream to check transaction %s on network %s", txHash, network)
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		results := make(chan visibility, len(others))
+		for _, ups := range others {
+			go func(ups *Upstream) {
+				visible, err := ups.EvmIsTransactionVisible(ctx, txHash)
+				results <- visibility{visible, err}
+			}(ups)
+		}
+
+		var answered bool
+		var lastErr error
+	waiting:
+		for range others {
+			select {
+			case r := <-results:
+				if r.err != nil {
+					lastErr = r.err
+					continue
+				}
+				if r.visible {
+					return true, nil
+				}
+				answered = true
+			case <-ctx.Done():
+				// The upstreams yet to answer do not tell
+				lastErr = ctx.Err()
+				break waiting
+			}
+		}
+		if answered {
+			return false, nil
+		}
+		return false, lastErr
+	}
 }
 
 func (u *UpstreamsRegistry) RLockUpstreams() {
@@ -486,6 +798,26 @@ func (u *UpstreamsRegistry) doRegisterBootstrappedUpstream(ups *Upstream) {
 	networkId := ups.NetworkId()
 	cfg := ups.Config()
 
+	if cfg.Shadow != nil {
+		// Marked before being registered, so that it is never selected
+		u.SetShadowUpstream(cfg.Id, cfg.Shadow.SampleRate)
+	}
+	if cfg.VendorName != "" {
+		// Incidents of a provider are rolled up across its upstreams
+		u.metricsTracker.SetUpstreamGroup(cfg.Id, cfg.VendorName)
+	}
+	u.metricsTracker.SetConcurrencyLimit(cfg.Id, networkId, cfg.MaxConcurrency)
+	if b := cfg.ErrorBudget; b != nil {
+		if err := u.metricsTracker.SetErrorBudgetConfig(cfg.Id, networkId, &health.ErrorBudgetConfig{
+			Fraction:  b.Fraction,
+			MinErrors: b.MinErrors,
+			Penalize:  b.Penalize,
+			Cordon:    b.Cordon,
+		}); err != nil {
+			u.logger.Error().Err(err).Str("upstreamId", cfg.Id).Msg("failed to set error budget of upstream")
+		}
+	}
+
 	u.upstreamsMu.Lock()
 	defer u.upstreamsMu.Unlock()
 
@@ -624,16 +956,43 @@ func (u *UpstreamsRegistry) updateScoresAndSort(ctx context.Context, networkId,
 	_, span := common.StartDetailSpan(ctx, "UpstreamsRegistry.UpdateScoresAndSort")
 	defer span.End()
 
-	var p90Latencies, errorRates, totalRequests, throttledRates, blockHeadLags, finalizationLags []float64
+	var p90Latencies, errorRates, totalRequests, throttledRates, blockHeadLags, finalizationLags, partialResponseRates []float64
 
-	for _, ups := range upsList {
-		metrics := u.metricsTracker.GetUpstreamMethodMetrics(ups.Config().Id, networkId, method)
-		p90Latencies = append(p90Latencies, metrics.ResponseQuantiles.GetQuantile(0.90).Seconds())
-		blockHeadLags = append(blockHeadLags, float64(metrics.BlockHeadLag.Load()))
-		finalizationLags = append(finalizationLags, float64(metrics.FinalizationLag.Load()))
-		errorRates = append(errorRates, metrics.ErrorRate())
-		throttledRates = append(throttledRates, metrics.ThrottledRate())
-		totalRequests = append(totalRequests, float64(metrics.RequestsTotal.Load()))
+	// Across methods, compare latencies relative to the cost of the methods each upstream serves
+	// rather than raw, when configured for the network
+	normalizedLatency := method == "*" && u.metricsTracker.ScoresNormalizedLatency(networkId)
+
+	var noLatencyData []int
+	for i, ups := range upsList {
+		view := u.metricsTracker.SelectionView(ups.Config().Id, networkId, method)
+		latency, hasLatency := view.P90Latency.Seconds(), view.HasLatency
+		if normalizedLatency {
+			latency, hasLatency = u.metricsTracker.NormalizedLatencyScore(ups.Config().Id, networkId)
+		}
+		if !hasLatency {
+			noLatencyData = append(noLatencyData, i)
+		}
+		p90Latencies = append(p90Latencies, latency)
+		blockHeadLags = append(blockHeadLags, float64(view.BlockHeadLag))
+		finalizationLags = append(finalizationLags, float64(view.FinalizationLag))
+		errorRates = append(errorRates, view.ErrorRate)
+		throttledRates = append(throttledRates, view.ThrottledRate)
+		totalRequests = append(totalRequests, float64(view.RequestsTotal))
+		partialResponseRates = append(partialResponseRates, view.PartialResponseRate)
+	}
+
+	// When configured to treat idle upstreams as unknown (not zero) latency, do not let
+	// them look faster than every upstream that actually served traffic.
+	if u.metricsTracker.NoDataBehavior() == health.NoDataNaN && len(noLatencyData) > 0 && len(noLatencyData) < len(upsList) {
+		maxP90 := 0.0
+		for _, p90 := range p90Latencies {
+			if p90 > maxP90 {
+				maxP90 = p90
+			}
+		}
+		for _, i := range noLatencyData {
+			p90Latencies[i] = maxP90
+		}
 	}
 
 	normP90Latencies := normalizeValues(p90Latencies)
@@ -642,9 +1001,11 @@ func (u *UpstreamsRegistry) updateScoresAndSort(ctx context.Context, networkId,
 	normTotalRequests := normalizeValues(totalRequests)
 	normBlockHeadLags := normalizeValues(blockHeadLags)
 	normFinalizationLags := normalizeValues(finalizationLags)
+	normPartialResponseRates := normalizeValues(partialResponseRates)
+	explained := make([]*UpstreamScoreExplanation, 0, len(upsList))
 	for i, ups := range upsList {
 		upsId := ups.Config().Id
-		score := u.calculateScore(
+		healthScore := u.calculateScore(
 			ups,
 			networkId,
 			method,
@@ -654,7 +1015,33 @@ func (u *UpstreamsRegistry) updateScoresAndSort(ctx context.Context, networkId,
 			normThrottledRates[i],
 			normBlockHeadLags[i],
 			normFinalizationLags[i],
+			normPartialResponseRates[i],
 		)
+		score := u.metricsTracker.EffectiveWeight(upsId, networkId, healthScore)
+		// Broken down for the score explanation and the historical score
+		factors, overall := u.scoreFactors(
+			ups,
+			networkId,
+			method,
+			normTotalRequests[i],
+			normP90Latencies[i],
+			normErrorRates[i],
+			normThrottledRates[i],
+			normBlockHeadLags[i],
+			normFinalizationLags[i],
+			normPartialResponseRates[i],
+		)
+		for j, raw := range []float64{totalRequests[i], p90Latencies[i], errorRates[i], throttledRates[i], blockHeadLags[i], finalizationLags[i], partialResponseRates[i]} {
+			factors[j].Raw = raw
+		}
+		// As if the upstream was at the head, see rankForBlock
+		historicalHealthScore := healthScore
+		for _, f := range factors {
+			if f.Name == "blockHeadLag" && f.Weight > 0 {
+				historicalHealthScore += (f.Weight - f.Contribution) * overall
+			}
+		}
+		historicalScore := u.metricsTracker.EffectiveWeight(upsId, networkId, historicalHealthScore)
 		// Upstream might not have scores initialized yet (especially when networkId is *)
 		// TODO add a test case to send request to network A when network B is defined in config but no requests sent yet
 		if upsc, ok := u.upstreamScores[upsId]; ok {
@@ -663,10 +1050,44 @@ func (u *UpstreamsRegistry) updateScoresAndSort(ctx context.Context, networkId,
 			}
 		}
 		telemetry.MetricUpstreamScoreOverall.WithLabelValues(u.prjId, networkId, upsId, method).Set(score)
+		explained = append(explained, &UpstreamScoreExplanation{
+			UpstreamId:      upsId,
+			Factors:         factors,
+			Overall:         overall,
+			HealthScore:     healthScore,
+			Score:           score,
+			HistoricalScore: historicalScore,
+		})
 	}
 
 	upsList = u.sortAndFilterUpstreams(networkId, method, upsList)
 	u.sortedUpstreams[networkId][method] = upsList
+	u.storeScoreExplanation(networkId, method, explained, upsList)
+	if method == "*" && networkId != "*" {
+		u.reportIntendedShares(networkId, upsList)
+	}
+}
+
+// reportIntendedShares reports the traffic distribution selection intends on a network, so that
+// the tracker can compare it with the realized one. Selection is an ordered failover, so the first
+// upstream kept is intended to serve all the traffic but the share its warmup ramp caps, which
+// falls through to the next ones (see demoteWarmingUp). Shadow upstreams serve no traffic.
+func (u *UpstreamsRegistry) reportIntendedShares(networkId string, kept []*Upstream) {
+	kept = u.withoutShadows(kept)
+	if len(kept) == 0 {
+		return
+	}
+	weights := make(map[string]float64, len(kept))
+	remaining := 1.0
+	for _, ups := range kept {
+		upsId := ups.Config().Id
+		share := remaining * max(min(u.metricsTracker.TrafficShareCap(upsId, networkId), 1), 0)
+		weights[upsId] = share
+		remaining -= share
+	}
+	// Whatever the ramps leave over still goes to the first upstream once all of them were tried
+	weights[kept[0].Config().Id] += remaining
+	u.metricsTracker.ReportIntendedShares(networkId, weights)
 }
 
 func (u *UpstreamsRegistry) calculateScore(
@@ -678,43 +1099,60 @@ func (u *UpstreamsRegistry) calculateScore(
 	normErrorRate,
 	normThrottledRate,
 	normBlockHeadLag,
-	normFinalizationLag float64,
+	normFinalizationLag,
+	normPartialResponseRate float64,
 ) float64 {
-	mul := ups.getScoreMultipliers(networkId, method)
-
-	score := 0.0
-
-	// Higher score for lower total requests (to balance the load)
-	if mul.TotalRequests > 0 {
-		score += expCurve(1-normTotalRequests) * mul.TotalRequests
-	}
+	factors, overall := u.scoreFactors(ups, networkId, method, normTotalRequests, normP90Latency, normErrorRate, normThrottledRate, normBlockHeadLag, normFinalizationLag, normPartialResponseRate)
+	return sumContributions(factors) * overall
+}
 
-	// Higher score for lower p90 latency
-	if mul.P90Latency > 0 {
-		score += expCurve(1-normP90Latency) * mul.P90Latency
-	}
+// scoreFactors returns the contribution of each normalized signal to the score of an upstream,
+// along with the overall multiplier of their sum. Raw values are left for the caller to fill.
+func (u *UpstreamsRegistry) scoreFactors(
+	ups *Upstream,
+	networkId,
+	method string,
+	normTotalRequests,
+	normP90Latency,
+	normErrorRate,
+	normThrottledRate,
+	normBlockHeadLag,
+	normFinalizationLag,
+	normPartialResponseRate float64,
+) ([]ScoreFactor, float64) {
+	mul := ups.getScoreMultipliers(networkId, method)
 
-	// Higher score for lower error rate
-	if mul.ErrorRate > 0 {
-		score += expCurve(1-normErrorRate) * mul.ErrorRate
+	factors := []ScoreFactor{
+		// Higher score for lower total requests (to balance the load)
+		{Name: "totalRequests", Normalized: normTotalRequests, Weight: mul.TotalRequests},
+		// Higher score for lower p90 latency
+		{Name: "p90Latency", Normalized: normP90Latency, Weight: mul.P90Latency},
+		// Higher score for lower error rate
+		{Name: "errorRate", Normalized: normErrorRate, Weight: mul.ErrorRate},
+		// Higher score for lower throttled rate
+		{Name: "throttledRate", Normalized: normThrottledRate, Weight: mul.ThrottledRate},
+		// Higher score for lower block head lag
+		{Name: "blockHeadLag", Normalized: normBlockHeadLag, Weight: mul.BlockHeadLag},
+		// Higher score for lower finalization lag
+		{Name: "finalizationLag", Normalized: normFinalizationLag, Weight: mul.FinalizationLag},
+		// Higher score for fewer incomplete responses
+		{Name: "partialResponseRate", Normalized: normPartialResponseRate, Weight: mul.PartialResponseRate},
 	}
-
-	// Higher score for lower throttled rate
-	if mul.ThrottledRate > 0 {
-		score += expCurve(1-normThrottledRate) * mul.ThrottledRate
+	for i := range factors {
+		if factors[i].Weight > 0 {
+			factors[i].Contribution = expCurve(1-factors[i].Normalized) * factors[i].Weight
+		}
 	}
 
-	// Higher score for lower block head lag
-	if mul.BlockHeadLag > 0 {
-		score += expCurve(1-normBlockHeadLag) * mul.BlockHeadLag
-	}
+	return factors, mul.Overall
+}
 
-	// Higher score for lower finalization lag
-	if mul.FinalizationLag > 0 {
-		score += expCurve(1-normFinalizationLag) * mul.FinalizationLag
+func sumContributions(factors []ScoreFactor) float64 {
+	score := 0.0
+	for _, f := range factors {
+		score += f.Contribution
 	}
-
-	return score * mul.Overall
+	return score
 }
 
 func expCurve(x float64) float64 {
@@ -776,13 +1214,30 @@ func (u *UpstreamsRegistry) GetUpstreamsHealth() (*UpstreamsHealth, error) {
 		}
 	}
 
+	var shadowUpstreams map[string]float64
+	u.shadowMu.RLock()
+	if len(u.shadowSampleRates) > 0 {
+		shadowUpstreams = make(map[string]float64, len(u.shadowSampleRates))
+		for upsId, rate := range u.shadowSampleRates {
+			shadowUpstreams[upsId] = rate
+		}
+	}
+	u.shadowMu.RUnlock()
+
+	eligibleUpstreams := make(map[string]int, len(u.networkUpstreams))
+	for nw := range u.networkUpstreams {
+		eligibleUpstreams[nw] = u.metricsTracker.EligibleUpstreams(nw)
+	}
+
 	return &UpstreamsHealth{
-		Upstreams:       u.allUpstreams,
-		SortedUpstreams: sortedUpstreams,
-		UpstreamScores:  upstreamScores,
+		Upstreams:         u.allUpstreams,
+		SortedUpstreams:   sortedUpstreams,
+		UpstreamScores:    upstreamScores,
+		ShadowUpstreams:   shadowUpstreams,
+		EligibleUpstreams: eligibleUpstreams,
 	}, nil
 }
 
-func (u *UpstreamsRegistry) GetMetricsTracker() *health.Tracker {
+func (u *UpstreamsRegistry) GetMetricsTracker() health.MetricsTracker {
 	return u.metricsTracker
 }
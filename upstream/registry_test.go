@@ -3,6 +3,10 @@ package upstream
 import (
 	"context"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -44,6 +48,325 @@ func TestUpstreamsRegistry_Ordering(t *testing.T) {
 		checkUpstreamScoreOrder(t, registry, networkID, method, expectedOrder)
 	})
 
+	t.Run("ExplainScoresOfLastRefresh", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		registry, metricsTracker := createTestRegistry(ctx, projectID, &logger, 10*time.Hour)
+		_, _ = registry.GetSortedUpstreams(ctx, networkID, method)
+		_, ok := registry.ExplainScores(networkID, method)
+		assert.False(t, ok)
+
+		simulateRequests(metricsTracker, networkID, "upstream-a", method, 100, 20)
+		simulateRequests(metricsTracker, networkID, "upstream-b", method, 100, 40)
+		simulateRequests(metricsTracker, networkID, "upstream-c", method, 100, 10)
+		metricsTracker.Cordon("upstream-b", networkID, method, "too many errors")
+		registry.RefreshUpstreamNetworkMethodScores()
+
+		exp, ok := registry.ExplainScores(networkID, method)
+		assert.True(t, ok)
+		assert.Len(t, exp.Upstreams, 3)
+		byId := map[string]*UpstreamScoreExplanation{}
+		for _, ue := range exp.Upstreams {
+			byId[ue.UpstreamId] = ue
+			// The breakdown adds up to the very score selection sorts with
+			total := 0.0
+			for _, f := range ue.Factors {
+				total += f.Contribution
+			}
+			assert.InDelta(t, ue.HealthScore, total*ue.Overall, 1e-9)
+			assert.Equal(t, registry.upstreamScores[ue.UpstreamId][networkID][method], ue.Score)
+		}
+		assert.Equal(t, 1, byId["upstream-c"].Rank)
+		assert.Equal(t, 2, byId["upstream-a"].Rank)
+		assert.Equal(t, 0, byId["upstream-b"].Rank)
+		assert.Equal(t, []string{"cordoned (manual): too many errors"}, byId["upstream-b"].ExclusionReasons)
+		for _, f := range byId["upstream-b"].Factors {
+			if f.Name == "errorRate" {
+				assert.InDelta(t, 0.4, f.Raw, 1e-9)
+				assert.InDelta(t, 1, f.Normalized, 1e-9)
+			}
+		}
+
+		// It stays the snapshot of the last refresh until the next one
+		simulateRequests(metricsTracker, networkID, "upstream-c", method, 100, 100)
+		again, _ := registry.ExplainScores(networkID, method)
+		assert.Same(t, exp, again)
+	})
+
+	t.Run("ExplainDemotions", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		registry, metricsTracker := createTestRegistry(ctx, projectID, &logger, 10*time.Hour)
+		registry.SetFailureCooldown(time.Hour)
+		_, _ = registry.GetSortedUpstreams(ctx, networkID, method)
+
+		simulateRequests(metricsTracker, networkID, "upstream-a", method, 100, 1)
+		simulateRequests(metricsTracker, networkID, "upstream-b", method, 100, 0)
+		simulateRequests(metricsTracker, networkID, "upstream-c", method, 100, 0)
+		metricsTracker.SetConcurrencyLimit("upstream-b", networkID, 1)
+		timer := metricsTracker.RecordUpstreamDurationStart("upstream-b", networkID, method, "")
+		defer timer.Release()
+		registry.RefreshUpstreamNetworkMethodScores()
+
+		exp, ok := registry.ExplainScores(networkID, method)
+		assert.True(t, ok)
+		byId := map[string]*UpstreamScoreExplanation{}
+		for _, ue := range exp.Upstreams {
+			byId[ue.UpstreamId] = ue
+		}
+		assert.Equal(t, []string{"failed less than 1h0m0s ago"}, byId["upstream-a"].ExclusionReasons)
+		assert.Equal(t, []string{"at concurrency limit"}, byId["upstream-b"].ExclusionReasons)
+		assert.Empty(t, byId["upstream-c"].ExclusionReasons)
+		// Explaining does not count as a denied request
+		assert.Zero(t, metricsTracker.GetUpstreamMethodMetrics("upstream-b", networkID, method).ConcurrencyDeniedTotal.Load())
+	})
+
+	t.Run("HistoricalBlocksIgnoreHeadLag", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		registry, metricsTracker := createTestRegistry(ctx, projectID, &logger, 10*time.Hour)
+		_, _ = registry.GetSortedUpstreams(ctx, networkID, method)
+
+		simulateRequests(metricsTracker, networkID, "upstream-a", method, 100, 10)
+		simulateRequests(metricsTracker, networkID, "upstream-b", method, 100, 15)
+		simulateRequests(metricsTracker, networkID, "upstream-c", method, 100, 40)
+		metricsTracker.SetLatestBlockNumber("upstream-a", networkID, 9_500)
+		metricsTracker.SetLatestBlockNumber("upstream-b", networkID, 10_000)
+		metricsTracker.SetLatestBlockNumber("upstream-c", networkID, 10_000)
+		registry.RefreshUpstreamNetworkMethodScores()
+
+		ids := func(block health.RequestBlock) []string {
+			upsList, err := registry.GetSortedUpstreamsForBlock(ctx, networkID, method, block)
+			assert.NoError(t, err)
+			res := make([]string, len(upsList))
+			for i, ups := range upsList {
+				res[i] = ups.Config().Id
+			}
+			return res
+		}
+
+		// The lag of upstream-a costs it the first place for recent data only
+		assert.Equal(t, []string{"upstream-b", "upstream-a", "upstream-c"}, ids(health.RequestBlock{}))
+		assert.Equal(t, []string{"upstream-b", "upstream-a", "upstream-c"}, ids(health.RequestBlock{Tag: "latest"}))
+		assert.Equal(t, []string{"upstream-b", "upstream-a", "upstream-c"}, ids(health.RequestBlock{Number: 9_800}))
+		assert.Equal(t, []string{"upstream-a", "upstream-b", "upstream-c"}, ids(health.RequestBlock{Number: 1_000}))
+		assert.Equal(t, []string{"upstream-b", "upstream-a", "upstream-c"}, ids(health.RequestBlock{Number: 1_000, Tag: "latest"}))
+	})
+
+	t.Run("FailureCooldownDemotesUpstream", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		registry, metricsTracker := createTestRegistry(ctx, projectID, &logger, 10*time.Hour)
+		registry.SetFailureCooldown(time.Hour)
+		_, _ = registry.GetSortedUpstreams(ctx, networkID, method)
+
+		simulateRequests(metricsTracker, networkID, "upstream-a", method, 100, 0)
+		simulateRequests(metricsTracker, networkID, "upstream-b", method, 100, 0)
+		simulateRequests(metricsTracker, networkID, "upstream-c", method, 100, 0)
+		registry.RefreshUpstreamNetworkMethodScores()
+
+		metricsTracker.RecordUpstreamFailure("upstream-a", networkID, method)
+		upsList, err := registry.GetSortedUpstreams(ctx, networkID, method)
+		assert.NoError(t, err)
+		assert.Len(t, upsList, 3)
+		assert.Equal(t, "upstream-a", upsList[2].Config().Id)
+
+		// Without cooldown the sorted list is served as is
+		registry.SetFailureCooldown(0)
+		upsList, err = registry.GetSortedUpstreams(ctx, networkID, method)
+		assert.NoError(t, err)
+		assert.Equal(t, registry.sortedUpstreams[networkID][method], upsList)
+	})
+
+	t.Run("WarmupRampDemotesUpstream", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		registry, metricsTracker := createTestRegistry(ctx, projectID, &logger, 10*time.Hour)
+		assert.NoError(t, metricsTracker.SetWarmupRamp(&health.WarmupRampConfig{Duration: time.Hour, InitialShare: 1e-9}))
+		_, _ = registry.GetSortedUpstreams(ctx, networkID, method)
+
+		simulateRequests(metricsTracker, networkID, "upstream-a", method, 100, 0)
+		simulateRequests(metricsTracker, networkID, "upstream-b", method, 100, 0)
+		simulateRequests(metricsTracker, networkID, "upstream-c", method, 100, 0)
+		registry.RefreshUpstreamNetworkMethodScores()
+
+		metricsTracker.Cordon("upstream-a", networkID, "*", "maintenance")
+		metricsTracker.Uncordon("upstream-a", networkID, "*")
+		upsList, err := registry.GetSortedUpstreams(ctx, networkID, method)
+		assert.NoError(t, err)
+		assert.Len(t, upsList, 3)
+		assert.Equal(t, "upstream-a", upsList[2].Config().Id)
+
+		assert.NoError(t, metricsTracker.SetWarmupRamp(nil))
+		upsList, err = registry.GetSortedUpstreams(ctx, networkID, method)
+		assert.NoError(t, err)
+		assert.Equal(t, registry.sortedUpstreams[networkID][method], upsList)
+	})
+
+	t.Run("ReportsFailoverIntendedShares", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		registry, metricsTracker := createTestRegistry(ctx, projectID, &logger, 10*time.Hour)
+		_, _ = registry.GetSortedUpstreams(ctx, networkID, "*")
+
+		simulateRequests(metricsTracker, networkID, "upstream-a", method, 100, 20)
+		simulateRequests(metricsTracker, networkID, "upstream-b", method, 100, 30)
+		simulateRequests(metricsTracker, networkID, "upstream-c", method, 100, 10)
+		registry.RefreshUpstreamNetworkMethodScores()
+		checkUpstreamScoreOrder(t, registry, networkID, "*", []string{"upstream-c", "upstream-a", "upstream-b"})
+		assert.Equal(t, map[string]float64{"upstream-c": 1, "upstream-a": 0, "upstream-b": 0}, metricsTracker.GetTrafficShares(networkID).Intended)
+
+		// A warming up first upstream leaves the rest of the traffic to the next one
+		assert.NoError(t, metricsTracker.SetWarmupRamp(&health.WarmupRampConfig{Duration: time.Hour, InitialShare: 0.25}))
+		metricsTracker.Cordon("upstream-c", networkID, "*", "maintenance")
+		metricsTracker.Uncordon("upstream-c", networkID, "*")
+		registry.RefreshUpstreamNetworkMethodScores()
+		intended := metricsTracker.GetTrafficShares(networkID).Intended
+		assert.InDelta(t, 0.25, intended["upstream-c"], 0.01)
+		assert.InDelta(t, 0.75, intended["upstream-a"], 0.01)
+		assert.Zero(t, intended["upstream-b"])
+	})
+
+	t.Run("ConcurrencyLimitDemotesUpstream", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		registry, metricsTracker := createTestRegistry(ctx, projectID, &logger, 10*time.Hour)
+		_, _ = registry.GetSortedUpstreams(ctx, networkID, method)
+
+		simulateRequests(metricsTracker, networkID, "upstream-a", method, 100, 0)
+		simulateRequests(metricsTracker, networkID, "upstream-b", method, 100, 0)
+		simulateRequests(metricsTracker, networkID, "upstream-c", method, 100, 0)
+		registry.RefreshUpstreamNetworkMethodScores()
+
+		metricsTracker.SetConcurrencyLimit("upstream-a", networkID, 1)
+		timer := metricsTracker.RecordUpstreamDurationStart("upstream-a", networkID, method, "")
+		assert.Equal(t, int64(1), metricsTracker.InFlight("upstream-a", networkID))
+		upsList, err := registry.GetSortedUpstreams(ctx, networkID, method)
+		assert.NoError(t, err)
+		assert.Len(t, upsList, 3)
+		assert.Equal(t, "upstream-a", upsList[2].Config().Id)
+
+		timer.ObserveDuration()
+		assert.Equal(t, int64(0), metricsTracker.InFlight("upstream-a", networkID))
+		upsList, err = registry.GetSortedUpstreams(ctx, networkID, method)
+		assert.NoError(t, err)
+		assert.Equal(t, registry.sortedUpstreams[networkID][method], upsList)
+	})
+
+	t.Run("RoundRobinTieBreakRotatesTiedUpstreams", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		registry, metricsTracker := createTestRegistry(ctx, projectID, &logger, 10*time.Hour)
+		_, _ = registry.GetSortedUpstreams(ctx, networkID, method)
+		metricsTracker.SetTieBreak(health.TieBreakRoundRobin)
+
+		simulateRequests(metricsTracker, networkID, "upstream-a", method, 100, 0)
+		simulateRequests(metricsTracker, networkID, "upstream-b", method, 100, 0)
+		simulateRequests(metricsTracker, networkID, "upstream-c", method, 100, 50)
+		registry.RefreshUpstreamNetworkMethodScores()
+
+		firsts := map[string]int{}
+		for i := 0; i < 10; i++ {
+			upsList, err := registry.GetSortedUpstreams(ctx, networkID, method)
+			assert.NoError(t, err)
+			assert.Len(t, upsList, 3)
+			assert.Equal(t, "upstream-c", upsList[2].Config().Id)
+			firsts[upsList[0].Config().Id]++
+		}
+		assert.Equal(t, map[string]int{"upstream-a": 5, "upstream-b": 5}, firsts)
+	})
+
+	t.Run("UpstreamNearRateLimitIsDeprioritized", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		registry, metricsTracker := createTestRegistry(ctx, projectID, &logger, 10*time.Hour)
+		_, _ = registry.GetSortedUpstreams(ctx, networkID, method)
+		metricsTracker.SetUpstreamRateLimitHeadroom("upstream-a", networkID, 10, 1000)
+		metricsTracker.SetUpstreamRateLimitHeadroom("upstream-b", networkID, 900, 1000)
+
+		simulateRequests(metricsTracker, networkID, "upstream-a", method, 100, 0)
+		simulateRequests(metricsTracker, networkID, "upstream-b", method, 100, 0)
+		simulateRequests(metricsTracker, networkID, "upstream-c", method, 100, 0)
+		registry.RefreshUpstreamNetworkMethodScores()
+
+		upsList, err := registry.GetSortedUpstreams(ctx, networkID, method)
+		assert.NoError(t, err)
+		assert.Len(t, upsList, 3)
+		assert.Equal(t, "upstream-a", upsList[2].Config().Id)
+
+		metricsTracker.SetUpstreamRateLimitHeadroom("upstream-a", networkID, 1000, 1000)
+		registry.RefreshUpstreamNetworkMethodScores()
+		upsList, err = registry.GetSortedUpstreams(ctx, networkID, method)
+		assert.NoError(t, err)
+		assert.Equal(t, "upstream-a", upsList[0].Config().Id)
+	})
+
+	t.Run("ShadowUpstreamIsNeverSelected", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		registry, metricsTracker := createTestRegistry(ctx, projectID, &logger, 10*time.Hour)
+		_, _ = registry.GetSortedUpstreams(ctx, networkID, method)
+		registry.SetShadowUpstream("upstream-a", 0.5)
+
+		simulateRequests(metricsTracker, networkID, "upstream-a", method, 100, 0)
+		simulateRequests(metricsTracker, networkID, "upstream-b", method, 100, 20)
+		simulateRequests(metricsTracker, networkID, "upstream-c", method, 100, 40)
+		registry.RefreshUpstreamNetworkMethodScores()
+
+		upsList, err := registry.GetSortedUpstreams(ctx, networkID, method)
+		assert.NoError(t, err)
+		assert.Len(t, upsList, 2)
+		assert.Equal(t, "upstream-b", upsList[0].Config().Id)
+		// Mirrored traffic stays out of the network aggregate
+		assert.Equal(t, int64(200), metricsTracker.GetNetworkMethodMetrics(networkID, method).RequestsTotal.Load())
+
+		// The shadow keeps its would-be rank and score for comparison
+		h, err := registry.GetUpstreamsHealth()
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]float64{"upstream-a": 0.5}, h.ShadowUpstreams)
+		assert.Equal(t, "upstream-a", h.SortedUpstreams[networkID][method][0])
+		assert.Greater(t, h.UpstreamScores["upstream-a"][networkID][method], h.UpstreamScores["upstream-b"][networkID][method])
+		assert.True(t, metricsTracker.GetNetworkUpstreamsMetrics(networkID, method)["upstream-a"].Shadow)
+
+		registry.SetShadowUpstream("upstream-a", 0)
+		upsList, err = registry.GetSortedUpstreams(ctx, networkID, method)
+		assert.NoError(t, err)
+		assert.Len(t, upsList, 3)
+		assert.Zero(t, registry.ShadowSampleRate("upstream-a"))
+	})
+
+	t.Run("WriteHealthBarDemotesUpstreamForWrites", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		registry, metricsTracker := createTestRegistry(ctx, projectID, &logger, 10*time.Hour)
+		registry.SetWriteHealthBar(0.2, 0)
+		_, _ = registry.GetSortedUpstreams(ctx, networkID, "eth_sendRawTransaction")
+		_, _ = registry.GetSortedUpstreams(ctx, networkID, method)
+
+		// upstream-a ranks first for sending transactions, but its other writes all fail
+		simulateRequests(metricsTracker, networkID, "upstream-a", "eth_sendRawTransaction", 10, 0)
+		simulateRequests(metricsTracker, networkID, "upstream-a", "eth_createAccessList", 10, 10)
+		simulateRequests(metricsTracker, networkID, "upstream-b", "eth_sendRawTransaction", 10, 1)
+		simulateRequests(metricsTracker, networkID, "upstream-c", "eth_sendRawTransaction", 10, 1)
+		registry.RefreshUpstreamNetworkMethodScores()
+		assert.Equal(t, "upstream-a", registry.sortedUpstreams[networkID]["eth_sendRawTransaction"][0].Config().Id)
+
+		upsList, err := registry.GetSortedUpstreams(ctx, networkID, "eth_sendRawTransaction")
+		assert.NoError(t, err)
+		assert.Len(t, upsList, 3)
+		assert.Equal(t, "upstream-a", upsList[2].Config().Id)
+
+		// Reads are not held to the bar
+		upsList, err = registry.GetSortedUpstreams(ctx, networkID, method)
+		assert.NoError(t, err)
+		assert.Equal(t, registry.sortedUpstreams[networkID][method], upsList)
+
+		registry.SetWriteHealthBar(0, 0)
+		upsList, err = registry.GetSortedUpstreams(ctx, networkID, "eth_sendRawTransaction")
+		assert.NoError(t, err)
+		assert.Equal(t, "upstream-a", upsList[0].Config().Id)
+	})
+
 	t.Run("CorrectOrderForLatency", func(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
@@ -60,6 +383,30 @@ func TestUpstreamsRegistry_Ordering(t *testing.T) {
 		checkUpstreamScoreOrder(t, registry, networkID, method, expectedOrder)
 	})
 
+	t.Run("IdleUpstreamLatencyFollowsNoDataBehavior", func(t *testing.T) {
+		for _, b := range []health.NoDataBehavior{health.NoDataZero, health.NoDataNaN} {
+			ctx, cancel := context.WithCancel(context.Background())
+			registry, metricsTracker := createTestRegistry(ctx, projectID, &logger, 10*time.Hour)
+			metricsTracker.SetNoDataBehavior(b)
+			_, _ = registry.GetSortedUpstreams(ctx, networkID, method)
+
+			simulateRequestsWithLatency(metricsTracker, networkID, "upstream-a", method, 10, 0.20)
+			simulateRequestsWithLatency(metricsTracker, networkID, "upstream-b", method, 10, 0.70)
+			simulateRequests(metricsTracker, networkID, "upstream-c", method, 10, 0)
+
+			if b == health.NoDataZero {
+				// Legacy behavior, the idle upstream looks like the fastest one
+				checkUpstreamScoreOrder(t, registry, networkID, method, []string{"upstream-c", "upstream-a", "upstream-b"})
+			} else {
+				// The idle upstream ranks like the slowest one
+				registry.RefreshUpstreamNetworkMethodScores()
+				scores := registry.upstreamScores
+				assert.Greater(t, scores["upstream-a"][networkID][method], scores["upstream-c"][networkID][method])
+			}
+			cancel()
+		}
+	})
+
 	t.Run("CorrectOrderForErrorRate", func(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
@@ -463,6 +810,7 @@ func TestUpstreamsRegistry_DynamicScenarios(t *testing.T) {
 					ups.throttledRate,
 					ups.blockHeadLag,
 					ups.finalizationLag,
+					0,
 				)
 				scores[i] = float64(score)
 				totalScore += float64(score)
@@ -726,6 +1074,7 @@ func TestUpstreamsRegistry_Multiplier(t *testing.T) {
 					ups.metrics.throttledRate,
 					ups.metrics.blockHeadLag,
 					ups.metrics.finalizationLag,
+					0,
 				)
 				scores[i] = float64(score)
 				totalScore += float64(score)
@@ -744,14 +1093,107 @@ func TestUpstreamsRegistry_Multiplier(t *testing.T) {
 	}
 }
 
+func TestUpstreamsRegistry_PartialResponseRate(t *testing.T) {
+	registry := &UpstreamsRegistry{
+		scoreRefreshInterval: time.Second,
+		logger:               &log.Logger,
+	}
+	newUpstream := func(partialResponseRate float64) *Upstream {
+		mul := *common.DefaultScoreMultiplier
+		mul.PartialResponseRate = partialResponseRate
+		return &Upstream{config: &common.UpstreamConfig{
+			Id:      "rpc1",
+			Routing: &common.RoutingConfig{ScoreMultipliers: []*common.ScoreMultiplierConfig{&mul}},
+		}}
+	}
+	score := func(ups *Upstream, normPartialResponseRate float64) float64 {
+		return registry.calculateScore(ups, "evm:1", "eth_getLogs", 0.5, 0.5, 0, 0, 0, 0, normPartialResponseRate)
+	}
+
+	// Disabled by default
+	assert.Equal(t, score(newUpstream(0), 0), score(newUpstream(0), 1))
+
+	ups := newUpstream(2)
+	assert.Greater(t, score(ups, 0), score(ups, 0.5))
+	assert.Greater(t, score(ups, 0.5), score(ups, 1))
+}
+
+func TestUpstreamsRegistry_BroadcastChecker(t *testing.T) {
+	// newServer answers eth_getTransactionByHash with txResult after delay, and the requests of
+	// the state poller right away
+	newServer := func(txResult string, delay time.Duration) *httptest.Server {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			result := `"0x1"`
+			switch {
+			case strings.Contains(string(body), "eth_getTransactionByHash"):
+				select {
+				case <-time.After(delay):
+				case <-r.Context().Done():
+					return
+				}
+				result = txResult
+			case strings.Contains(string(body), "eth_chainId"):
+				result = `"0x7b"`
+			}
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":75413,"result":%s}`, result)
+		}))
+		t.Cleanup(srv.Close)
+		return srv
+	}
+
+	t.Run("AnyOtherUpstreamSeeingTheTransactionIsEnough", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		registry, metricsTracker := createTestRegistryWithEndpoints(ctx, "test-project", &log.Logger, time.Minute,
+			newServer("null", 0).URL,
+			newServer("null", 10*time.Second).URL,
+			newServer(`{"hash":"0xabc"}`, 0).URL,
+		)
+
+		started := time.Now()
+		visible, err := registry.BroadcastChecker()(ctx, "evm:123", "0xabc", "upstream-a")
+		assert.NoError(t, err)
+		assert.True(t, visible)
+		assert.Less(t, time.Since(started), 5*time.Second, "not waiting for the slow upstream")
+		// The probe does not count in the health of the upstreams
+		assert.Zero(t, metricsTracker.GetUpstreamMethodMetrics("upstream-c", "evm:123", "eth_getTransactionByHash").RequestsTotal.Load())
+	})
+
+	t.Run("UpstreamsNotAnsweringInTimeDoNotTell", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		registry, _ := createTestRegistryWithEndpoints(ctx, "test-project", &log.Logger, time.Minute,
+			newServer("null", 0).URL,
+			newServer("null", 10*time.Second).URL,
+			newServer("null", 0).URL,
+		)
+
+		checkCtx, checkCancel := context.WithTimeout(ctx, 500*time.Millisecond)
+		defer checkCancel()
+		visible, err := registry.BroadcastChecker()(checkCtx, "evm:123", "0xabc", "upstream-a")
+		assert.NoError(t, err)
+		assert.False(t, visible)
+	})
+}
+
 func createTestRegistry(ctx context.Context, projectID string, logger *zerolog.Logger, windowSize time.Duration) (*UpstreamsRegistry, *health.Tracker) {
+	return createTestRegistryWithEndpoints(ctx, projectID, logger, windowSize,
+		"http://upstream-a.localhost",
+		"http://upstream-b.localhost",
+		"http://upstream-c.localhost",
+	)
+}
+
+// createTestRegistryWithEndpoints creates upstream-a, upstream-b and upstream-c on the given endpoints.
+func createTestRegistryWithEndpoints(ctx context.Context, projectID string, logger *zerolog.Logger, windowSize time.Duration, endpointA, endpointB, endpointC string) (*UpstreamsRegistry, *health.Tracker) {
 	metricsTracker := health.NewTracker(logger, projectID, windowSize)
 	metricsTracker.Bootstrap(ctx)
 
 	upstreamConfigs := []*common.UpstreamConfig{
-		{Id: "upstream-a", Endpoint: "http://upstream-a.localhost", Type: common.UpstreamTypeEvm, Evm: &common.EvmUpstreamConfig{ChainId: 123}},
-		{Id: "upstream-b", Endpoint: "http://upstream-b.localhost", Type: common.UpstreamTypeEvm, Evm: &common.EvmUpstreamConfig{ChainId: 123}},
-		{Id: "upstream-c", Endpoint: "http://upstream-c.localhost", Type: common.UpstreamTypeEvm, Evm: &common.EvmUpstreamConfig{ChainId: 123}},
+		{Id: "upstream-a", Endpoint: endpointA, Type: common.UpstreamTypeEvm, Evm: &common.EvmUpstreamConfig{ChainId: 123}},
+		{Id: "upstream-b", Endpoint: endpointB, Type: common.UpstreamTypeEvm, Evm: &common.EvmUpstreamConfig{ChainId: 123}},
+		{Id: "upstream-c", Endpoint: endpointC, Type: common.UpstreamTypeEvm, Evm: &common.EvmUpstreamConfig{ChainId: 123}},
 	}
 
 	vr := thirdparty.NewVendorsRegistry()
@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -35,15 +37,21 @@ type Upstream struct {
 
 	networkId            string
 	supportedMethods     sync.Map
-	metricsTracker       *health.Tracker
+	metricsTracker       health.MetricsTracker
 	sharedStateRegistry  data.SharedStateRegistry
 	timeoutDuration      *time.Duration
 	failsafeExecutor     failsafe.Executor[*common.NormalizedResponse]
 	rateLimitersRegistry *RateLimitersRegistry
 	rateLimiterAutoTuner *RateLimitAutoTuner
 	evmStatePoller       common.EvmStatePoller
+	clientVersionOnce    sync.Once
+	certificateOnce      sync.Once
 }
 
+// clientVersionRefreshInterval is how often the node client version is probed again, mainly to
+// notice upgrades.
+const clientVersionRefreshInterval = time.Hour
+
 func NewUpstream(
 	appCtx context.Context,
 	projectId string,
@@ -52,12 +60,12 @@ func NewUpstream(
 	rlr *RateLimitersRegistry,
 	vr *thirdparty.VendorsRegistry,
 	logger *zerolog.Logger,
-	mt *health.Tracker,
+	mt health.MetricsTracker,
 	ssr data.SharedStateRegistry,
 ) (*Upstream, error) {
 	lg := logger.With().Str("upstreamId", cfg.Id).Logger()
 
-	policiesMap, err := CreateFailSafePolicies(&lg, common.ScopeUpstream, cfg.Id, cfg.Failsafe)
+	policiesMap, err := CreateFailSafePolicies(&lg, common.ScopeUpstream, cfg.Id, cfg.Failsafe, mt)
 	if err != nil {
 		return nil, err
 	}
@@ -115,7 +123,26 @@ func (u *Upstream) Bootstrap(ctx context.Context) error {
 		return err
 	}
 
+	// Called on every bootstrap so that a vendor change on config reload relabels the health metrics
+	attrs := map[string]string{}
+	if u.vendor != nil {
+		attrs[health.AttributeVendor] = u.vendor.Name()
+	}
+	u.metricsTracker.SetUpstreamAttributes(u.config.Id, u.networkId, attrs)
+
+	if u.checksCertificate() {
+		u.certificateOnce.Do(func() {
+			go u.refreshCertificateExpiry(u.appCtx)
+		})
+	}
+
 	if u.config.Type == common.UpstreamTypeEvm {
+		// Like the state, the client version is only probed in the background if polling is enabled
+		if u.config.Evm != nil && u.config.Evm.StatePollerInterval > 0 {
+			u.clientVersionOnce.Do(func() {
+				go u.refreshClientVersion(u.appCtx)
+			})
+		}
 		u.evmStatePoller = evm.NewEvmStatePoller(u.ProjectId, u.appCtx, u.logger, u, u.metricsTracker, u.sharedStateRegistry)
 	}
 
@@ -207,11 +234,15 @@ func (u *Upstream) Forward(ctx context.Context, req *common.NormalizedRequest, b
 
 	clientType := u.Client.GetType()
 
+	// Requests mirrored to a shadow upstream are recorded by the tracker under their composite
+	// type, but stay out of the upstream-level rate limits, counters and circuit breaker
+	shadow := req.CompositeType() == common.CompositeTypeShadow
+
 	//
 	// Apply rate limits
 	//
 	var limitersBudget *RateLimiterBudget
-	if cfg.RateLimitBudget != "" {
+	if cfg.RateLimitBudget != "" && !shadow {
 		var errLimiters error
 		limitersBudget, errLimiters = u.rateLimitersRegistry.GetBudget(cfg.RateLimitBudget)
 		if errLimiters != nil {
@@ -233,11 +264,7 @@ func (u *Upstream) Forward(ctx context.Context, req *common.NormalizedRequest, b
 			for _, rule := range rules {
 				if !rule.Limiter.TryAcquirePermit() {
 					lg.Debug().Str("budget", cfg.RateLimitBudget).Msgf("upstream-level rate limit '%v' exceeded", rule.Config)
-					u.metricsTracker.RecordUpstreamSelfRateLimited(
-						cfg.Id,
-						u.networkId,
-						method,
-					)
+					u.metricsTracker.RecordOutcome(cfg.Id, u.networkId, method, health.Outcome{Kind: health.OutcomeSelfRateLimited})
 					err = common.NewErrUpstreamRateLimitRuleExceeded(
 						cfg.Id,
 						cfg.RateLimitBudget,
@@ -265,6 +292,8 @@ func (u *Upstream) Forward(ctx context.Context, req *common.NormalizedRequest, b
 	//
 	// Send the request based on client type
 	//
+	finality := u.requestFinality(ctx, req)
+
 	switch clientType {
 	case clients.ClientTypeHttpJsonRpc:
 		jsonRpcClient, okClient := u.Client.(clients.HttpJsonRpcClient)
@@ -287,22 +316,44 @@ func (u *Upstream) Forward(ctx context.Context, req *common.NormalizedRequest, b
 			ctx context.Context,
 			exec failsafe.Execution[*common.NormalizedResponse],
 		) (*common.NormalizedResponse, error) {
-			u.metricsTracker.RecordUpstreamRequest(
-				cfg.Id,
-				u.networkId,
-				method,
-			)
-			telemetry.MetricUpstreamRequestTotal.WithLabelValues(u.ProjectId, u.networkId, cfg.Id, method, strconv.Itoa(exec.Attempts()), req.CompositeType()).Inc()
+			if !shadow {
+				telemetry.MetricUpstreamRequestTotal.WithLabelValues(u.ProjectId, u.networkId, cfg.Id, method, strconv.Itoa(exec.Attempts()), req.CompositeType()).Inc()
+			}
 			timer := u.metricsTracker.RecordUpstreamDurationStart(cfg.Id, u.networkId, method, req.CompositeType())
-			defer timer.ObserveDuration()
+			defer timer.Release()
+			timer.SetFinality(finality)
+			timer.SetAttempt(exec.Attempts())
+			timer.SetClientId(req.ClientId())
 
+			attemptStart := time.Now()
 			resp, errCall := jsonRpcClient.SendRequest(ctx, req)
+			u.recordTraceSample(ctx, cfg.Id, method, time.Since(attemptStart), errCall)
+
+			timer.ObserveOutcome(callOutcome(ctx, resp, errCall))
+			if errCall != nil && ctx.Err() == nil &&
+				(finality == common.DataFinalityStateUnfinalized || finality == common.DataFinalityStateRealtime) {
+				// Only errors on recent blocks tell the upstream is behind, on older ones they
+				// rather tell missing data
+				u.metricsTracker.RecordUpstreamBehindHeadError(cfg.Id, u.networkId, method, errCall)
+			}
+			if kind, ok := malformedResponseKind(errCall); ok {
+				u.metricsTracker.RecordUpstreamMalformedResponse(cfg.Id, u.networkId, method, kind)
+				if payload := malformedPayload(resp, errCall); len(payload) > 0 {
+					u.metricsTracker.RecordUpstreamMalformedSample(cfg.Id, u.networkId, method, kind, payload)
+				}
+			}
 			if resp != nil {
 				jrr, _ := resp.JsonRpcResponse()
 				if jrr != nil && jrr.Error == nil {
 					resp.SetUpstream(u)
 					req.SetLastValidResponse(resp)
 					req.SetLastUpstream(u)
+					if method == "eth_sendRawTransaction" && errCall == nil {
+						u.recordBroadcastAccepted(jrr)
+					}
+					if method == "eth_chainId" && errCall == nil {
+						u.recordChainId(jrr)
+					}
 				}
 				if lg.GetLevel() == zerolog.TraceLevel {
 					lg.Debug().Err(errCall).Object("response", resp).Msgf("upstream request ended with response")
@@ -321,24 +372,21 @@ func (u *Upstream) Forward(ctx context.Context, req *common.NormalizedRequest, b
 				}
 			}
 			if errCall != nil {
-				if common.HasErrorCode(errCall, common.ErrCodeUpstreamRequestSkipped) {
+				if shadow {
+					// Mirrored traffic is only recorded by the tracker
+				} else if common.HasErrorCode(errCall, common.ErrCodeUpstreamRequestSkipped) {
 					telemetry.MetricUpstreamSkippedTotal.WithLabelValues(u.ProjectId, u.networkId, cfg.Id, method).Inc()
 				} else if common.HasErrorCode(errCall, common.ErrCodeEndpointMissingData) {
 					telemetry.MetricUpstreamMissingDataErrorTotal.WithLabelValues(u.ProjectId, u.networkId, cfg.Id, method).Inc()
 				} else {
 					if common.HasErrorCode(errCall, common.ErrCodeEndpointCapacityExceeded) {
-						u.recordRemoteRateLimit(u.networkId, method)
+						u.recordRemoteRateLimit(method)
 					}
-					severity := common.ClassifySeverity(errCall)
-					if severity == common.SeverityCritical {
-						// We only consider a subset of errors in metrics tracker (which is used for score calculation)
-						// so that we only penalize upstreams for internal issues (not rate limits, or client-side, or method support issues, etc.)
-						u.metricsTracker.RecordUpstreamFailure(
-							cfg.Id,
-							u.networkId,
-							method,
-						)
+					if method == "eth_getLogs" && common.HasErrorCode(errCall, common.ErrCodeEndpointRequestTooLarge) {
+						// The upstream would not return the complete logs, they are split and fetched again
+						u.metricsTracker.RecordUpstreamPartialResponse(cfg.Id, u.networkId, method)
 					}
+					severity := common.ClassifySeverity(errCall)
 					telemetry.MetricUpstreamErrorTotal.WithLabelValues(u.ProjectId, u.networkId, cfg.Id, method, common.ErrorFingerprint(errCall), string(severity), req.CompositeType()).Inc()
 				}
 
@@ -365,18 +413,21 @@ func (u *Upstream) Forward(ctx context.Context, req *common.NormalizedRequest, b
 						0,
 					)
 				}
-			} else {
+			} else if !shadow {
 				if resp.IsResultEmptyish() {
 					telemetry.MetricUpstreamEmptyResponseTotal.WithLabelValues(u.ProjectId, u.networkId, cfg.Id, method).Inc()
 				}
+				u.recordRequestSuccess(method)
 			}
 
-			u.recordRequestSuccess(method)
-
 			return resp, nil
 		}
 
 		executor := u.failsafeExecutor
+		if shadow {
+			// Sent once, out of the retries, hedges and circuit breaker of the upstream
+			executor = failsafe.NewExecutor[*common.NormalizedResponse]()
+		}
 		resp, execErr := executor.
 			WithContext(ctx).
 			GetWithExecution(func(exec failsafe.Execution[*common.NormalizedResponse]) (*common.NormalizedResponse, error) {
@@ -477,21 +528,113 @@ func (u *Upstream) EvmGetChainId(ctx context.Context) (string, error) {
 	if jrr.Error != nil {
 		return "", jrr.Error
 	}
-	var chainId string
-	err = common.SonicCfg.Unmarshal(jrr.Result, &chainId)
+	dec, err := parseEvmChainId(jrr)
 	if err != nil {
 		return "", err
 	}
+
+	return strconv.FormatUint(dec, 10), nil
+}
+
+func parseEvmChainId(jrr *common.JsonRpcResponse) (uint64, error) {
+	var chainId string
+	err := common.SonicCfg.Unmarshal(jrr.Result, &chainId)
+	if err != nil {
+		return 0, err
+	}
 	hex, err := common.NormalizeHex(chainId)
+	if err != nil {
+		return 0, err
+	}
+	return common.HexToUint64(hex)
+}
+
+// EvmIsTransactionVisible tells whether the upstream knows the transaction, pending or mined. Like
+// EvmGetClientVersion the request is sent with the client directly, so that the probe neither
+// consumes the rate limit budget of the upstream nor counts in its health.
+func (u *Upstream) EvmIsTransactionVisible(ctx context.Context, txHash string) (bool, error) {
+	pr := common.NewNormalizedRequest([]byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":75413,"method":"eth_getTransactionByHash","params":[%q]}`, txHash)))
+
+	err := u.prepareRequest(ctx, pr)
+	if err != nil {
+		return false, err
+	}
+	jsonRpcClient, ok := u.Client.(clients.HttpJsonRpcClient)
+	if !ok {
+		return false, fmt.Errorf("unsupported client type %s for upstream %s", u.Client.GetType(), u.Config().Id)
+	}
+	resp, err := jsonRpcClient.SendRequest(ctx, pr)
+	if err != nil {
+		return false, err
+	}
+
+	jrr, err := resp.JsonRpcResponse()
+	if err != nil {
+		return false, err
+	}
+	if jrr.Error != nil {
+		return false, jrr.Error
+	}
+
+	return !resp.IsResultEmptyish(), nil
+}
+
+// EvmGetClientVersion returns the node client version reported by web3_clientVersion. The request
+// is sent with the client directly, so that the probe does not count in the health of the upstream
+// (e.g. when its provider does not support the method).
+func (u *Upstream) EvmGetClientVersion(ctx context.Context) (string, error) {
+	pr := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","id":75414,"method":"web3_clientVersion","params":[]}`))
+
+	err := u.prepareRequest(ctx, pr)
 	if err != nil {
 		return "", err
 	}
-	dec, err := common.HexToUint64(hex)
+	jsonRpcClient, ok := u.Client.(clients.HttpJsonRpcClient)
+	if !ok {
+		return "", fmt.Errorf("unsupported client type %s for upstream %s", u.Client.GetType(), u.Config().Id)
+	}
+	resp, err := jsonRpcClient.SendRequest(ctx, pr)
 	if err != nil {
 		return "", err
 	}
 
-	return strconv.FormatUint(dec, 10), nil
+	jrr, err := resp.JsonRpcResponse()
+	if err != nil {
+		return "", err
+	}
+	if jrr.Error != nil {
+		return "", jrr.Error
+	}
+	var version string
+	err = common.SonicCfg.Unmarshal(jrr.Result, &version)
+	if err != nil {
+		return "", err
+	}
+
+	return version, nil
+}
+
+// refreshClientVersion reports the node client version to the metrics tracker right away and then
+// every clientVersionRefreshInterval, until ctx is done. Failures are only logged since many
+// providers do not expose web3_clientVersion.
+func (u *Upstream) refreshClientVersion(ctx context.Context) {
+	ticker := time.NewTicker(clientVersionRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		version, err := u.EvmGetClientVersion(ctx)
+		if err != nil {
+			u.logger.Debug().Err(err).Msg("could not get client version of upstream")
+		} else {
+			u.metricsTracker.RecordUpstreamClientVersion(u.Config().Id, u.networkId, version)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
 }
 
 // TODO move to evm package
@@ -619,13 +762,134 @@ func (u *Upstream) recordRequestSuccess(method string) {
 	}
 }
 
-func (u *Upstream) recordRemoteRateLimit(netId, method string) {
-	u.metricsTracker.RecordUpstreamRemoteRateLimited(
-		u.config.Id,
-		netId,
-		method,
-	)
+// callOutcome classifies the result of a request sent to the upstream for the metrics tracker.
+// Only a subset of errors count as failures (which are used for score calculation) so that upstreams
+// are only penalized for internal issues (not rate limits, client-side or method support issues, etc.)
+func callOutcome(ctx context.Context, resp *common.NormalizedResponse, errCall error) health.Outcome {
+	var o health.Outcome
+	if resp != nil {
+		if jrr, _ := resp.JsonRpcResponse(); jrr != nil {
+			o.Bytes = int64(len(jrr.Result))
+		}
+	}
+	if errCall == nil {
+		return o
+	}
+
+	o.Err = errCall
+	if se, ok := errCall.(common.StandardError); ok {
+		if code, ok := se.DeepSearch("normalizedCode").(common.JsonRpcErrorNumber); ok {
+			o.JsonRpcCode = int(code)
+		}
+		if status, ok := se.DeepSearch("statusCode").(int); ok {
+			o.HttpStatus = status
+		}
+	}
+	switch {
+	case ctx.Err() != nil:
+		// A cancelled request says nothing about the upstream latency or health. Deadlines are
+		// attributed here, other cancellations by the network which knows if a hedge or the client
+		// gave up on the request.
+		o.Kind = health.OutcomeCancelled
+		if cause := health.CancelCauseOf(ctx); cause == health.CancelCauseDeadline {
+			o.CancelCause = cause
+		}
+	case common.HasErrorCode(errCall, common.ErrCodeEndpointCapacityExceeded):
+		o.Kind = health.OutcomeRemoteRateLimited
+	case common.HasErrorCode(errCall, common.ErrCodeEndpointUnsupported):
+		o.Kind = health.OutcomeUnsupportedMethod
+	case common.ClassifySeverity(errCall) == common.SeverityCritical:
+		o.Kind = health.OutcomeFailure
+	default:
+		o.Kind = health.OutcomeNonCriticalError
+	}
+	return o
+}
+
+// malformedResponseKind tells if errCall is about a response which could not be used as a JSON-RPC
+// response, and of which kind (see health.MalformedInvalidJson and siblings).
+func malformedResponseKind(errCall error) (string, bool) {
+	if errCall == nil {
+		return "", false
+	}
+	if common.HasErrorCode(errCall, common.ErrCodeUpstreamMalformedResponse) {
+		if se, ok := errCall.(common.StandardError); ok && se.DeepSearch("responseIds") != nil {
+			return health.MalformedIdMismatch, true
+		}
+		return health.MalformedNotJsonRpc, true
+	}
+	se, ok := errCall.(common.StandardError)
+	if !ok || !se.HasCode(common.ErrCodeJsonRpcExceptionInternal) {
+		return "", false
+	}
+	if code, ok := se.DeepSearch("normalizedCode").(common.JsonRpcErrorNumber); !ok || code != common.JsonRpcErrorParseException {
+		return "", false
+	}
+	// Only the client attaches the response headers to parse failures of the response body
+	headers, ok := se.DeepSearch("headers").(http.Header)
+	if !ok {
+		return "", false
+	}
+	if ct := headers.Get("Content-Type"); ct != "" && !strings.Contains(strings.ToLower(ct), "json") {
+		return health.MalformedWrongContentType, true
+	}
+	return health.MalformedInvalidJson, true
+}
+
+// malformedPayload returns the first bytes of a malformed response, kept either by the response
+// when its body could not be parsed or by the error of a batch.
+func malformedPayload(resp *common.NormalizedResponse, errCall error) []byte {
+	if payload := resp.UnparsedBody(); len(payload) > 0 {
+		return payload
+	}
+	if se, ok := errCall.(common.StandardError); ok {
+		if payload, ok := se.DeepSearch("payload").(string); ok {
+			return []byte(payload)
+		}
+	}
+	return nil
+}
 
+func (u *Upstream) recordTraceSample(ctx context.Context, upsId, method string, duration time.Duration, err error) {
+	rec := health.RequestRecord{
+		Upstream: upsId,
+		Network:  u.networkId,
+		Method:   method,
+		Duration: duration,
+		Success:  err == nil,
+		Error:    err,
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		rec.TraceId = sc.TraceID().String()
+	}
+	u.metricsTracker.RecordUpstreamTraceSample(rec)
+}
+
+// recordBroadcastAccepted lets the metrics tracker check that a transaction this upstream accepted
+// becomes visible through the others.
+func (u *Upstream) recordBroadcastAccepted(jrr *common.JsonRpcResponse) {
+	var txHash string
+	if err := common.SonicCfg.Unmarshal(jrr.Result, &txHash); err != nil {
+		return
+	}
+	u.metricsTracker.RecordBroadcastAccepted(u.Config().Id, u.networkId, txHash)
+}
+
+// recordChainId lets the metrics tracker check the chain id this upstream reports against the
+// expected one of its network. Responses received before the network is known (i.e. during the
+// chain id detection) are recorded by detectFeatures instead.
+func (u *Upstream) recordChainId(jrr *common.JsonRpcResponse) {
+	if u.networkId == "" {
+		return
+	}
+	chainId, err := parseEvmChainId(jrr)
+	if err != nil {
+		return
+	}
+	u.metricsTracker.RecordUpstreamChainId(u.Config().Id, u.networkId, int64(chainId))
+}
+
+func (u *Upstream) recordRemoteRateLimit(method string) {
 	if u.rateLimiterAutoTuner != nil {
 		u.rateLimiterAutoTuner.RecordError(method)
 	}
@@ -712,6 +976,7 @@ func (u *Upstream) detectFeatures(ctx context.Context) error {
 			}
 		}
 		u.networkId = util.EvmNetworkId(cfg.Evm.ChainId)
+		u.metricsTracker.RecordUpstreamChainId(cfg.Id, u.networkId, cfg.Evm.ChainId)
 
 		if cfg.Evm.MaxAvailableRecentBlocks == 0 && cfg.Evm.NodeType == common.EvmNodeTypeFull {
 			cfg.Evm.MaxAvailableRecentBlocks = 128
@@ -726,6 +991,47 @@ func (u *Upstream) detectFeatures(ctx context.Context) error {
 	return nil
 }
 
+// requestFinality classifies a request by its block reference, used to segment duration tracking.
+// The classification is kept on the request so that retries, hedges and the other upstreams of the
+// network reuse it.
+func (u *Upstream) requestFinality(ctx context.Context, req *common.NormalizedRequest) common.DataFinalityState {
+	if finality, ok := req.Finality(); ok {
+		return finality
+	}
+	finality := u.classifyFinality(ctx, req)
+	req.SetFinality(finality)
+	return finality
+}
+
+func (u *Upstream) classifyFinality(ctx context.Context, req *common.NormalizedRequest) common.DataFinalityState {
+	if u.config.Evm == nil {
+		return common.DataFinalityStateUnknown
+	}
+	blockRef, blockNumber, _ := evm.ExtractBlockReferenceFromRequest(ctx, req)
+	if blockRef == "" {
+		return common.DataFinalityStateUnknown
+	}
+	if blockRef == "*" {
+		return common.DataFinalityStateUnfinalized
+	}
+	if blockRef == "finalized" || blockRef == "safe" {
+		return common.DataFinalityStateFinalized
+	}
+	if blockRef[0] < '0' || blockRef[0] > '9' {
+		// Tags such as "latest" or "pending"
+		return common.DataFinalityStateRealtime
+	}
+	if blockNumber > 0 {
+		if isFinalized, err := u.EvmIsBlockFinalized(blockNumber); err == nil {
+			if isFinalized {
+				return common.DataFinalityStateFinalized
+			}
+			return common.DataFinalityStateUnfinalized
+		}
+	}
+	return common.DataFinalityStateUnknown
+}
+
 func (u *Upstream) shouldSkip(ctx context.Context, req *common.NormalizedRequest) (reason error, skip bool) {
 	method, _ := req.Method()
 
@@ -743,6 +1049,10 @@ func (u *Upstream) shouldSkip(ctx context.Context, req *common.NormalizedRequest
 		u.logger.Debug().Str("method", method).Msg("method not allowed or ignored by upstread")
 		return common.NewErrUpstreamMethodIgnored(method, u.config.Id), true
 	}
+	if !u.metricsTracker.IsMethodAllowed(u.config.Id, u.networkId, method) {
+		u.logger.Debug().Str("method", method).Msg("method denied by upstream method policy")
+		return common.NewErrUpstreamMethodIgnored(method, u.config.Id), true
+	}
 
 	dirs := req.Directives()
 	if dirs.UseUpstream != "" {
@@ -796,12 +1106,12 @@ func (u *Upstream) getScoreMultipliers(networkId, method string) *common.ScoreMu
 
 func (u *Upstream) MarshalJSON() ([]byte, error) {
 	type upstreamPublic struct {
-		Id        string                            `json:"id"`
-		Metrics   map[string]*health.TrackedMetrics `json:"metrics"`
-		NetworkId string                            `json:"networkId"`
+		Id        string                                    `json:"id"`
+		Metrics   map[string]*health.TrackedMetricsSnapshot `json:"metrics"`
+		NetworkId string                                    `json:"networkId"`
 	}
 
-	metrics := u.metricsTracker.GetUpstreamMetrics(u.config.Id)
+	metrics := u.metricsTracker.GetUpstreamMetricsSnapshot(u.config.Id)
 
 	uppub := upstreamPublic{
 		Id:        u.config.Id,
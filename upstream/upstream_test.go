@@ -2,9 +2,15 @@ package upstream
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/health"
+	"github.com/erpc/erpc/health/healthtest"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 )
@@ -16,7 +22,8 @@ func TestUpstream_SkipLogic(t *testing.T) {
 				Id:            "test",
 				IgnoreMethods: []string{"eth_getBalance"},
 			},
-			logger: &zerolog.Logger{},
+			logger:         &zerolog.Logger{},
+			metricsTracker: health.NewNoopTracker(),
 		}
 
 		reason, skip := upstream.shouldSkip(context.TODO(), common.NewNormalizedRequest([]byte(`{"method":"eth_getBalance"}`)))
@@ -34,7 +41,8 @@ func TestUpstream_SkipLogic(t *testing.T) {
 				Id:            "test",
 				IgnoreMethods: []string{"eth_*"},
 			},
-			logger: &zerolog.Logger{},
+			logger:         &zerolog.Logger{},
+			metricsTracker: health.NewNoopTracker(),
 		}
 
 		reason, skip := upstream.shouldSkip(context.TODO(), common.NewNormalizedRequest([]byte(`{"method":"eth_getBalance"}`)))
@@ -52,7 +60,8 @@ func TestUpstream_SkipLogic(t *testing.T) {
 				Id:            "test",
 				IgnoreMethods: []string{"eth_getBalance", "eth_getBlockByNumber"},
 			},
-			logger: &zerolog.Logger{},
+			logger:         &zerolog.Logger{},
+			metricsTracker: health.NewNoopTracker(),
 		}
 
 		reason, skip := upstream.shouldSkip(context.TODO(), common.NewNormalizedRequest([]byte(`{"method":"eth_getBalance"}`)))
@@ -74,7 +83,8 @@ func TestUpstream_SkipLogic(t *testing.T) {
 				Id:            "test",
 				IgnoreMethods: []string{"eth_*", "net_version"},
 			},
-			logger: &zerolog.Logger{},
+			logger:         &zerolog.Logger{},
+			metricsTracker: health.NewNoopTracker(),
 		}
 
 		reason, skip := upstream.shouldSkip(context.TODO(), common.NewNormalizedRequest([]byte(`{"method":"eth_getBalance"}`)))
@@ -96,13 +106,15 @@ func TestUpstream_SkipLogic(t *testing.T) {
 				Id:            "test1",
 				IgnoreMethods: []string{"eth_getBalance"},
 			},
-			logger: &zerolog.Logger{},
+			logger:         &zerolog.Logger{},
+			metricsTracker: health.NewNoopTracker(),
 		}
 		upstream2 := &Upstream{
 			config: &common.UpstreamConfig{
 				Id: "test2",
 			},
-			logger: &zerolog.Logger{},
+			logger:         &zerolog.Logger{},
+			metricsTracker: health.NewNoopTracker(),
 		}
 
 		reason, skip := upstream1.shouldSkip(context.TODO(), common.NewNormalizedRequest([]byte(`{"method":"eth_getBalance"}`)))
@@ -120,14 +132,16 @@ func TestUpstream_SkipLogic(t *testing.T) {
 				Id:            "test1",
 				IgnoreMethods: []string{"eth_getBalance"},
 			},
-			logger: &zerolog.Logger{},
+			logger:         &zerolog.Logger{},
+			metricsTracker: health.NewNoopTracker(),
 		}
 		upstream2 := &Upstream{
 			config: &common.UpstreamConfig{
 				Id:            "test2",
 				IgnoreMethods: []string{"eth_getBlockByNumber"},
 			},
-			logger: &zerolog.Logger{},
+			logger:         &zerolog.Logger{},
+			metricsTracker: health.NewNoopTracker(),
 		}
 
 		reason, skip := upstream1.shouldSkip(context.TODO(), common.NewNormalizedRequest([]byte(`{"method":"eth_getBalance"}`)))
@@ -153,14 +167,16 @@ func TestUpstream_SkipLogic(t *testing.T) {
 				Id:            "test1",
 				IgnoreMethods: []string{"eth_getBalance"},
 			},
-			logger: &zerolog.Logger{},
+			logger:         &zerolog.Logger{},
+			metricsTracker: health.NewNoopTracker(),
 		}
 		upstream2 := &Upstream{
 			config: &common.UpstreamConfig{
 				Id:            "test2",
 				IgnoreMethods: []string{"eth_*"},
 			},
-			logger: &zerolog.Logger{},
+			logger:         &zerolog.Logger{},
+			metricsTracker: health.NewNoopTracker(),
 		}
 
 		reason, skip := upstream1.shouldSkip(context.TODO(), common.NewNormalizedRequest([]byte(`{"method":"eth_getBalance"}`)))
@@ -177,7 +193,8 @@ func TestUpstream_SkipLogic(t *testing.T) {
 			config: &common.UpstreamConfig{
 				Id: "test",
 			},
-			logger: &zerolog.Logger{},
+			logger:         &zerolog.Logger{},
+			metricsTracker: health.NewNoopTracker(),
 		}
 
 		reason, skip := upstream.shouldSkip(context.TODO(), common.NewNormalizedRequest([]byte(`{"method":"eth_getBalance"}`)))
@@ -194,13 +211,15 @@ func TestUpstream_SkipLogic(t *testing.T) {
 			config: &common.UpstreamConfig{
 				Id: "test1",
 			},
-			logger: &zerolog.Logger{},
+			logger:         &zerolog.Logger{},
+			metricsTracker: health.NewNoopTracker(),
 		}
 		upstream2 := &Upstream{
 			config: &common.UpstreamConfig{
 				Id: "test2",
 			},
-			logger: &zerolog.Logger{},
+			logger:         &zerolog.Logger{},
+			metricsTracker: health.NewNoopTracker(),
 		}
 
 		reason, skip := upstream1.shouldSkip(context.TODO(), common.NewNormalizedRequest([]byte(`{"method":"eth_getBalance"}`)))
@@ -226,7 +245,8 @@ func TestUpstream_SkipLogic(t *testing.T) {
 				Id:            "test",
 				IgnoreMethods: []string{"eth_*", "net_version", "web3_clientVersion"},
 			},
-			logger: &zerolog.Logger{},
+			logger:         &zerolog.Logger{},
+			metricsTracker: health.NewNoopTracker(),
 		}
 
 		reason, skip := upstream.shouldSkip(context.TODO(), common.NewNormalizedRequest([]byte(`{"method":"eth_getBalance"}`)))
@@ -252,7 +272,8 @@ func TestUpstream_SkipLogic(t *testing.T) {
 				Id:            "test",
 				IgnoreMethods: []string{"eth_*_*"},
 			},
-			logger: &zerolog.Logger{},
+			logger:         &zerolog.Logger{},
+			metricsTracker: health.NewNoopTracker(),
 		}
 
 		reason, skip := upstream.shouldSkip(context.TODO(), common.NewNormalizedRequest([]byte(`{"method":"eth_get_balance"}`)))
@@ -268,3 +289,130 @@ func TestUpstream_SkipLogic(t *testing.T) {
 		assert.ErrorIs(t, reason, common.NewErrUpstreamMethodIgnored("eth_get_block_by_number", "test"))
 	})
 }
+
+func TestUpstream_CallOutcome(t *testing.T) {
+	ctx := context.Background()
+	resp := common.NewNormalizedResponse().WithJsonRpcResponse(&common.JsonRpcResponse{Result: []byte(`"0x1"`)})
+
+	o := callOutcome(ctx, resp, nil)
+	assert.Equal(t, health.OutcomeSuccess, o.Kind)
+	assert.Equal(t, int64(5), o.Bytes)
+
+	o = callOutcome(ctx, nil, common.NewErrEndpointServerSideException(
+		common.NewErrJsonRpcExceptionInternal(-32603, common.JsonRpcErrorServerSideException, "boom", nil, nil),
+		map[string]interface{}{"statusCode": 503},
+	))
+	assert.Equal(t, health.OutcomeFailure, o.Kind)
+	assert.Equal(t, int(common.JsonRpcErrorServerSideException), o.JsonRpcCode)
+	assert.Equal(t, 503, o.HttpStatus)
+	assert.Error(t, o.Err)
+
+	assert.Equal(t, health.OutcomeRemoteRateLimited, callOutcome(ctx, nil, common.NewErrEndpointCapacityExceeded(nil)).Kind)
+	assert.Equal(t, health.OutcomeNonCriticalError, callOutcome(ctx, nil, common.NewErrEndpointMissingData(nil)).Kind)
+	assert.Equal(t, health.OutcomeUnsupportedMethod, callOutcome(ctx, nil, common.NewErrEndpointUnsupported(nil)).Kind)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	o = callOutcome(cancelled, nil, context.Canceled)
+	assert.Equal(t, health.OutcomeCancelled, o.Kind)
+	assert.Empty(t, o.CancelCause)
+
+	expired, cancel := context.WithDeadline(ctx, time.Now().Add(-time.Second))
+	defer cancel()
+	o = callOutcome(expired, nil, context.DeadlineExceeded)
+	assert.Equal(t, health.OutcomeCancelled, o.Kind)
+	assert.Equal(t, health.CancelCauseDeadline, o.CancelCause)
+}
+
+func TestUpstream_MalformedResponseKind(t *testing.T) {
+	parseErr := func(contentType string) error {
+		return common.NewErrJsonRpcExceptionInternal(
+			0,
+			common.JsonRpcErrorParseException,
+			"could not parse json rpc response from upstream",
+			errors.New("invalid char"),
+			map[string]interface{}{"headers": http.Header{"Content-Type": []string{contentType}}},
+		)
+	}
+	cases := []struct {
+		name string
+		err  error
+		kind string
+		ok   bool
+	}{
+		{"NoError", nil, "", false},
+		{"NotJsonRpc", common.NewErrUpstreamMalformedResponse(errors.New("unexpected response type"), "a"), health.MalformedNotJsonRpc, true},
+		{"IdMismatch", common.NewErrUpstreamResponseIdMismatch(errors.New("no response received for request ID: 1"), "a", []interface{}{int64(2)}), health.MalformedIdMismatch, true},
+		{"HtmlPage", parseErr("text/html; charset=utf-8"), health.MalformedWrongContentType, true},
+		{"InvalidJson", parseErr("application/json"), health.MalformedInvalidJson, true},
+		{"ParseErrorWithoutHeaders", common.NewErrJsonRpcExceptionInternal(0, common.JsonRpcErrorParseException, "parse error", nil, nil), "", false},
+		{"ServerError", common.NewErrEndpointServerSideException(nil, nil), "", false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			kind, ok := malformedResponseKind(tc.err)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.kind, kind)
+		})
+	}
+}
+
+func TestUpstream_MalformedPayload(t *testing.T) {
+	page := []byte("<html>" + strings.Repeat("x", 1024) + "</html>")
+	err := common.NewErrUpstreamMalformedPayload(errors.New("unexpected response type"), "a", page)
+	assert.Equal(t, page[:512], malformedPayload(nil, err))
+	assert.Nil(t, malformedPayload(nil, common.NewErrUpstreamMalformedResponse(errors.New("unexpected response type"), "a")))
+}
+
+func TestUpstream_RequestFinality(t *testing.T) {
+	ups := &Upstream{
+		config: &common.UpstreamConfig{Id: "test", Evm: &common.EvmUpstreamConfig{}},
+		logger: &zerolog.Logger{},
+	}
+	cases := map[string]common.DataFinalityState{
+		"finalized": common.DataFinalityStateFinalized,
+		"safe":      common.DataFinalityStateFinalized,
+		"latest":    common.DataFinalityStateRealtime,
+		"pending":   common.DataFinalityStateRealtime,
+	}
+	for tag, finality := range cases {
+		t.Run(tag, func(t *testing.T) {
+			req := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","id":1,"method":"eth_getBalance","params":["0x0000000000000000000000000000000000000000","` + tag + `"]}`))
+			assert.Equal(t, finality, ups.requestFinality(context.TODO(), req))
+		})
+	}
+
+	t.Run("ClassifiedOncePerRequest", func(t *testing.T) {
+		req := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","id":1,"method":"eth_getBalance","params":["0x0000000000000000000000000000000000000000","latest"]}`))
+		req.SetFinality(common.DataFinalityStateFinalized)
+		assert.Equal(t, common.DataFinalityStateFinalized, ups.requestFinality(context.TODO(), req))
+
+		req = common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","id":1,"method":"eth_getBalance","params":["0x0000000000000000000000000000000000000000","latest"]}`))
+		ups.requestFinality(context.TODO(), req)
+		finality, ok := req.Finality()
+		assert.True(t, ok)
+		assert.Equal(t, common.DataFinalityStateRealtime, finality)
+	})
+}
+
+func TestUpstream_RecordChainId(t *testing.T) {
+	recorder := healthtest.NewRecorder(nil)
+	ups := &Upstream{
+		config:         &common.UpstreamConfig{Id: "test"},
+		logger:         &zerolog.Logger{},
+		metricsTracker: recorder,
+	}
+	jrr, err := common.NewJsonRpcResponseFromBytes([]byte("1"), []byte(`"0x89"`), nil)
+	assert.NoError(t, err)
+
+	// Not recorded before the network is detected
+	ups.recordChainId(jrr)
+	assert.Empty(t, recorder.CallsTo("RecordUpstreamChainId"))
+
+	ups.networkId = "evm:1"
+	ups.recordChainId(jrr)
+	calls := recorder.CallsTo("RecordUpstreamChainId")
+	if assert.Len(t, calls, 1) {
+		assert.Equal(t, []any{"test", "evm:1", int64(137)}, calls[0].Args)
+	}
+}
@@ -39,7 +39,7 @@ func ConfigureTestLogger() {
 }
 
 const (
-	EvmBlockTrackerMocks = 6
+	EvmBlockTrackerMocks = 8
 )
 
 func SetupMocksForEvmStatePoller() {
@@ -72,6 +72,14 @@ func SetupMocksForEvmStatePoller() {
 		}).
 		Reply(200).
 		JSON([]byte(`{"result":false,"_note":"evm state poller expected mock for eth_syncing"}`))
+	gock.New("http://rpc1.localhost").
+		Post("").
+		Persist().
+		Filter(func(request *http.Request) bool {
+			return strings.Contains(SafeReadBody(request), "web3_clientVersion")
+		}).
+		Reply(200).
+		JSON([]byte(`{"result":"Geth/v1.14.0-stable/linux-amd64/go1.22.0","_note":"client version expected mock for web3_clientVersion"}`))
 	gock.New("http://rpc2.localhost").
 		Post("").
 		Persist().
@@ -98,6 +106,14 @@ func SetupMocksForEvmStatePoller() {
 		}).
 		Reply(200).
 		JSON([]byte(`{"result":false,"_note":"evm state poller expected mock for eth_syncing"}`))
+	gock.New("http://rpc2.localhost").
+		Post("").
+		Persist().
+		Filter(func(request *http.Request) bool {
+			return strings.Contains(SafeReadBody(request), "web3_clientVersion")
+		}).
+		Reply(200).
+		JSON([]byte(`{"result":"Geth/v1.14.0-stable/linux-amd64/go1.22.0","_note":"client version expected mock for web3_clientVersion"}`))
 }
 
 func ResetGock() {